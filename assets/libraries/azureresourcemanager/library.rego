@@ -0,0 +1,27 @@
+package generic.azureresourcemanager
+
+# Checks if the document is an ARM deployment template
+isTemplate(document) {
+	contains(document["$schema"], "deploymentTemplate.json")
+}
+
+# Checks if a value references a template parameter or variable instead of being a literal
+isExpression(value) {
+	is_string(value)
+	startswith(value, "[")
+	endswith(value, "]")
+}
+
+# Checks if a parameter is declared as a secure type
+isSecureParameter(parameter) {
+	lower(parameter.type) == {"securestring", "secureobject"}[_]
+}
+
+# Returns the resources of the given type declared in the template, including child resources
+getResourcesByType(document, resourceType) = resources {
+	resources := [resource |
+		walk(document.resources, [_, resource])
+		is_object(resource)
+		lower(resource.type) == lower(resourceType)
+	]
+}
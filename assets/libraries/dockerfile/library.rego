@@ -28,3 +28,18 @@ withVersion(pack) {
 arrayContains(array, list) {
 	contains(array[_], list[_])
 }
+
+# isFinalStage returns true if the stage is the final stage of the Dockerfile, the one that produces the image
+isFinalStage(document, name) {
+	stage := document.stages[_]
+	stage.Name == name
+	stage.Final == true
+}
+
+# finalStageCommands returns the commands of the final stage and of the stages it builds from (FROM <stage>)
+finalStageCommands(document) = commands {
+	stage := document.stages[_]
+	stage.Final == true
+	names := array.concat([stage.Name], stage.Parents)
+	commands := [cmd | cmd := document.command[names[_]][_]]
+}
@@ -0,0 +1,20 @@
+package generic.googledeploymentmanager
+
+# Returns the resources of the given type declared in a configuration or rendered template
+getResourcesByType(document, resourceType) = resources {
+	resources := [resource |
+		resource := document.resources[_]
+		resource.type == resourceType
+	]
+}
+
+# Checks if a value was not resolved while rendering the template
+isUnresolved(value) {
+	is_string(value)
+	startswith(value, "${")
+}
+
+# Checks if a compute instance network interface has an external IP address
+hasExternalIP(networkInterface) {
+	count(networkInterface.accessConfigs) > 0
+}
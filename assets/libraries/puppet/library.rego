@@ -0,0 +1,29 @@
+package generic.puppet
+
+# Checks if a file mode grants write permission to others
+isWorldWritable(mode) {
+	is_string(mode)
+	digits := trim_left(mode, "0")
+	count(digits) > 0
+	other := substring(digits, count(digits) - 1, 1)
+	other == {"2", "3", "6", "7"}[_]
+}
+
+# Checks if a value is a plaintext string instead of a variable, function call or lookup
+isPlaintext(value) {
+	is_string(value)
+	count(value) > 0
+	not startswith(value, "$")
+	not contains(value, "(")
+}
+
+# Checks if a repository url is not served over TLS
+isInsecureURL(url) {
+	is_string(url)
+	startswith(lower(url), "http://")
+}
+
+# Returns the searchKey prefix of a resource
+resourceKey(resource) = key {
+	key := sprintf("%s={{%s}}", [resource.type, resource.title])
+}
@@ -0,0 +1,27 @@
+package generic.saltstack
+
+# Returns the arguments of a state function merged in a single object
+# supports both 'module.function: [args]' and 'module: [function, args]' syntaxes
+getArgs(state, module, function) = args {
+	list := state[sprintf("%s.%s", [module, function])]
+	args := {key: value | arg := list[_]; is_object(arg); value := arg[key]}
+} else = args {
+	list := state[module]
+	list[_] == function
+	args := {key: value | arg := list[_]; is_object(arg); value := arg[key]}
+}
+
+# Checks if a state declares the given module function
+hasFunction(state, module, function) {
+	_ = state[sprintf("%s.%s", [module, function])]
+}
+
+hasFunction(state, module, function) {
+	state[module][_] == function
+}
+
+# Checks if a value was left unrendered by the jinja renderer
+isTemplated(value) {
+	is_string(value)
+	contains(value, "${")
+}
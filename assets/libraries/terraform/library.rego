@@ -70,3 +70,18 @@ anyPrincipal(statement) {
 	some i
 	contains(statement.Principal.AWS[i], "*")
 }
+
+# Gets the region of the provider configuration used by a resource
+getResourceRegion(resource) = region {
+	region := resource._kics_provider.region
+}
+
+# Checks if a resource uses a provider configuration with an alias
+usesProviderAlias(resource) {
+	resource._kics_provider.alias != ""
+}
+
+# Gets the type and the configuration of the state backend of a document (ex: s3)
+getBackend(document) = [backendType, config] {
+	config := document.terraform.backend[backendType]
+}
@@ -1,21 +1,25 @@
 package Cx
 
+import data.generic.dockerfile as dockerLib
+
 CxPolicy[result] {
-	resource := input.document[i].command[name]
+	document := input.document[i]
+	document.command[name]
 
-  not name == "scratch"
-  not hasUserInstruction(resource)
+	not name == "scratch"
+	dockerLib.isFinalStage(document, name)
+	not hasUserInstruction(dockerLib.finalStageCommands(document))
 
 	result := {
-		"documentId": input.document[i].id,
+		"documentId": document.id,
 		"searchKey": sprintf("FROM={{%s}}", [name]),
 		"issueType": "Missing Attribute",
-		"keyExpectedValue": "The 'Dockerfile' contains the 'USER' instruction",
-		"keyActualValue": "The 'Dockerfile' does not contain any 'USER' instruction",
+		"keyExpectedValue": "The final stage of the 'Dockerfile' contains the 'USER' instruction",
+		"keyActualValue": "The final stage of the 'Dockerfile' does not contain any 'USER' instruction",
 	}
 }
 
-hasUserInstruction(resource) {
+hasUserInstruction(commands) {
 	some j
-	resource[j].Cmd == "user"
+	commands[j].Cmd == "user"
 }
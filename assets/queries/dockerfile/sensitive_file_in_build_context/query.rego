@@ -0,0 +1,13 @@
+package Cx
+
+CxPolicy[result] {
+	sensitiveCopy := input.document[i].buildContext.SensitiveCopies[_]
+
+	result := {
+		"documentId": input.document[i].id,
+		"searchKey": sprintf("FROM={{%s}}.{{%s}}", [sensitiveCopy.Stage, sensitiveCopy.Original]),
+		"issueType": "IncorrectValue",
+		"keyExpectedValue": "Sensitive files of the build context are not copied into the image",
+		"keyActualValue": sprintf("Sensitive files of the build context are copied into the image: %s", [concat(", ", sensitiveCopy.Files)]),
+	}
+}
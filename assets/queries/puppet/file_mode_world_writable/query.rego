@@ -0,0 +1,18 @@
+package Cx
+
+import data.generic.puppet as puppetLib
+
+CxPolicy[result] {
+	resource := input.document[i].resource[_]
+	resource.type == "file"
+	mode := resource.attributes.mode
+	puppetLib.isWorldWritable(mode)
+
+	result := {
+		"documentId": input.document[i].id,
+		"searchKey": sprintf("%s.mode", [puppetLib.resourceKey(resource)]),
+		"issueType": "IncorrectValue",
+		"keyExpectedValue": sprintf("file[%s].mode does not grant write permission to others", [resource.title]),
+		"keyActualValue": sprintf("file[%s].mode is '%s'", [resource.title, mode]),
+	}
+}
@@ -0,0 +1,9 @@
+file { '/etc/app.conf':
+  ensure => file,
+  mode   => '0777',
+}
+
+file { '/var/log/app':
+  ensure => directory,
+  mode   => '0662',
+}
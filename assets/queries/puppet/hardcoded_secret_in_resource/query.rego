@@ -0,0 +1,25 @@
+package Cx
+
+import data.generic.puppet as puppetLib
+
+secretNames := {"password", "passwd", "secret", "token"}
+
+CxPolicy[result] {
+	resource := input.document[i].resource[_]
+	value := resource.attributes[name]
+	isSecretName(name)
+	puppetLib.isPlaintext(value)
+
+	result := {
+		"documentId": input.document[i].id,
+		"searchKey": sprintf("%s.%s", [puppetLib.resourceKey(resource), name]),
+		"issueType": "IncorrectValue",
+		"keyExpectedValue": sprintf("%s[%s].%s is not written in plaintext", [resource.type, resource.title, name]),
+		"keyActualValue": sprintf("%s[%s].%s is written in plaintext", [resource.type, resource.title, name]),
+	}
+}
+
+isSecretName(name) {
+	contains(lower(name), secretNames[_])
+	not contains(lower(name), "hash")
+}
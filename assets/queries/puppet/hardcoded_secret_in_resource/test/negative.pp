@@ -0,0 +1,9 @@
+user { 'deploy':
+  ensure   => present,
+  password => Sensitive(lookup('deploy::password')),
+}
+
+class { 'app::db':
+  db_password   => $db_password,
+  password_hash => '*6C8989366EAF75BB670AD8EA7A7FC1176A95CEF4',
+}
@@ -0,0 +1,8 @@
+user { 'deploy':
+  ensure   => present,
+  password => 'P@ssw0rd',
+}
+
+class { 'app::db':
+  db_password => 'Sup3rS3cret',
+}
@@ -0,0 +1,23 @@
+package Cx
+
+import data.generic.puppet as puppetLib
+
+repositoryURLs := {
+	"yumrepo": {"baseurl", "mirrorlist"},
+	"apt::source": {"location"},
+}
+
+CxPolicy[result] {
+	resource := input.document[i].resource[_]
+	name := repositoryURLs[resource.type][_]
+	url := resource.attributes[name]
+	puppetLib.isInsecureURL(url)
+
+	result := {
+		"documentId": input.document[i].id,
+		"searchKey": sprintf("%s.%s", [puppetLib.resourceKey(resource), name]),
+		"issueType": "IncorrectValue",
+		"keyExpectedValue": sprintf("%s[%s].%s uses HTTPS", [resource.type, resource.title, name]),
+		"keyActualValue": sprintf("%s[%s].%s is '%s'", [resource.type, resource.title, name, url]),
+	}
+}
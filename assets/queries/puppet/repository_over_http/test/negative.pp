@@ -0,0 +1,10 @@
+yumrepo { 'epel':
+  baseurl  => 'https://download.fedoraproject.org/pub/epel/7/x86_64',
+  gpgcheck => true,
+}
+
+apt::source { 'nginx':
+  location => 'https://nginx.org/packages/ubuntu',
+  release  => 'focal',
+  repos    => 'nginx',
+}
@@ -0,0 +1,10 @@
+yumrepo { 'epel':
+  baseurl  => 'http://download.fedoraproject.org/pub/epel/7/x86_64',
+  gpgcheck => true,
+}
+
+apt::source { 'nginx':
+  location => 'http://nginx.org/packages/ubuntu',
+  release  => 'focal',
+  repos    => 'nginx',
+}
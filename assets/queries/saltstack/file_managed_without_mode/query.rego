@@ -0,0 +1,18 @@
+package Cx
+
+import data.generic.saltstack as saltLib
+
+CxPolicy[result] {
+	state := input.document[i][id]
+	saltLib.hasFunction(state, "file", "managed")
+	args := saltLib.getArgs(state, "file", "managed")
+	not args.mode
+
+	result := {
+		"documentId": input.document[i].id,
+		"searchKey": sprintf("{{%s}}", [id]),
+		"issueType": "MissingAttribute",
+		"keyExpectedValue": sprintf("%s sets the mode of the file", [id]),
+		"keyActualValue": sprintf("%s does not set the mode of the file", [id]),
+	}
+}
@@ -0,0 +1,22 @@
+package Cx
+
+import data.generic.saltstack as saltLib
+
+userModules := {"mysql_user", "postgres_user", "rabbitmq_user", "mongodb_user"}
+
+CxPolicy[result] {
+	state := input.document[i][id]
+	args := saltLib.getArgs(state, userModules[_], "present")
+	value := args[name]
+	contains(lower(name), "password")
+	is_string(value)
+	not saltLib.isTemplated(value)
+
+	result := {
+		"documentId": input.document[i].id,
+		"searchKey": sprintf("{{%s}}.%s", [id, name]),
+		"issueType": "IncorrectValue",
+		"keyExpectedValue": sprintf("%s.%s is read from the pillar", [id, name]),
+		"keyActualValue": sprintf("%s.%s is written in plaintext", [id, name]),
+	}
+}
@@ -0,0 +1,18 @@
+package Cx
+
+import data.generic.saltstack as saltLib
+
+CxPolicy[result] {
+	state := input.document[i][id]
+	args := saltLib.getArgs(state, "cmd", "run")
+	command := args.name
+	regex.match(`(curl|wget)\s[^|]*\|\s*(sudo\s+)?(ba|da|z)?sh\b`, command)
+
+	result := {
+		"documentId": input.document[i].id,
+		"searchKey": sprintf("{{%s}}.name", [id]),
+		"issueType": "IncorrectValue",
+		"keyExpectedValue": sprintf("%s does not pipe a downloaded script to a shell", [id]),
+		"keyActualValue": sprintf("%s runs '%s'", [id, command]),
+	}
+}
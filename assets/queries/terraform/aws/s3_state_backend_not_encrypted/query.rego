@@ -0,0 +1,31 @@
+package Cx
+
+import data.generic.terraform as terraLib
+
+CxPolicy[result] {
+	[backendType, backend] := terraLib.getBackend(input.document[i])
+	backendType == "s3"
+	object.get(backend, "encrypt", "undefined") == "undefined"
+
+	result := {
+		"documentId": input.document[i].id,
+		"searchKey": "terraform.backend.s3",
+		"issueType": "MissingAttribute",
+		"keyExpectedValue": "'terraform.backend.s3.encrypt' is 'true'",
+		"keyActualValue": "'terraform.backend.s3.encrypt' is undefined",
+	}
+}
+
+CxPolicy[result] {
+	[backendType, backend] := terraLib.getBackend(input.document[i])
+	backendType == "s3"
+	backend.encrypt == false
+
+	result := {
+		"documentId": input.document[i].id,
+		"searchKey": "terraform.backend.s3.encrypt",
+		"issueType": "IncorrectValue",
+		"keyExpectedValue": "'terraform.backend.s3.encrypt' is 'true'",
+		"keyActualValue": "'terraform.backend.s3.encrypt' is 'false'",
+	}
+}
@@ -0,0 +1,12 @@
+terraform {
+  required_version = ">= 1.0"
+}
+
+terraform {
+  backend "s3" {
+    bucket  = "terraform-state"
+    key     = "network/terraform.tfstate"
+    region  = "eu-west-1"
+    encrypt = false
+  }
+}
@@ -0,0 +1,27 @@
+package Cx
+
+CxPolicy[result] {
+	requirement := input.document[i].terraform.required_providers[name]
+	object.get(requirement, "version", "undefined") == "undefined"
+
+	result := {
+		"documentId": input.document[i].id,
+		"searchKey": sprintf("terraform.required_providers.%s", [name]),
+		"issueType": "MissingAttribute",
+		"keyExpectedValue": sprintf("'terraform.required_providers.%s.version' is defined", [name]),
+		"keyActualValue": sprintf("'terraform.required_providers.%s.version' is undefined", [name]),
+	}
+}
+
+CxPolicy[result] {
+	requirement := input.document[i].terraform.required_providers[name]
+	regex.match(`^\s*>=?\s*[0-9][0-9.]*\s*$`, requirement.version)
+
+	result := {
+		"documentId": input.document[i].id,
+		"searchKey": sprintf("terraform.required_providers.%s", [name]),
+		"issueType": "IncorrectValue",
+		"keyExpectedValue": sprintf("'terraform.required_providers.%s.version' has an upper bound", [name]),
+		"keyActualValue": sprintf("'terraform.required_providers.%s.version' is '%s'", [name, requirement.version]),
+	}
+}
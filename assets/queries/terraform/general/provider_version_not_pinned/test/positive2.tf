@@ -0,0 +1,5 @@
+terraform {
+  required_providers {
+    google = ">= 3.5"
+  }
+}
@@ -0,0 +1,105 @@
+package console
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Checkmarx/kics/internal/constants"
+	"github.com/Checkmarx/kics/pkg/attestation"
+	"github.com/Checkmarx/kics/pkg/engine/source"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const kicsURI = "https://www.kics.io/"
+
+var (
+	attestationPath   string
+	attestationKey    string
+	attestationCommit string
+)
+
+// initAttestationFlags adds the flags of the signed attestation of the results
+func initAttestationFlags() {
+	scanCmd.Flags().StringVarP(
+		&attestationPath,
+		"attestation",
+		"",
+		"",
+		"file path where a signed in-toto attestation of the results, bound to the scanned commit, is written",
+	)
+	scanCmd.Flags().StringVarP(
+		&attestationKey,
+		"attestation-key",
+		"",
+		"",
+		"PEM private key (ECDSA, Ed25519 or RSA) signing the attestation",
+	)
+	scanCmd.Flags().StringVarP(
+		&attestationCommit,
+		"attestation-commit",
+		"",
+		"",
+		"commit digest the attestation is bound to (defaults to the git HEAD of the scanned path)",
+	)
+}
+
+// writeAttestation writes the DSSE envelope of the attestation of the results of the scanned commit,
+// signed with the attestation key, when an attestation path is given
+func writeAttestation(summary *model.Summary, scanStartTime time.Time) error {
+	if attestationPath == "" {
+		return nil
+	}
+	if attestationKey == "" {
+		return errors.New("an attestation key is required to sign the attestation")
+	}
+	signer, err := attestation.LoadSigner(attestationKey)
+	if err != nil {
+		return err
+	}
+
+	dir := path
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		dir = filepath.Dir(path)
+	}
+	commit := attestationCommit
+	if commit == "" {
+		if commit, err = source.RunGit(dir, nil, "rev-parse", "HEAD"); err != nil {
+			return errors.Wrap(err, "failed to get the commit of the scanned path, use --attestation-commit")
+		}
+	}
+	name, err := source.RunGit(dir, nil, "config", "--get", "remote.origin.url")
+	if err != nil {
+		name, _ = filepath.Abs(dir)
+	}
+
+	statement := attestation.NewStatement(name, commit, &attestation.Predicate{
+		// the parameters are not attested since they may include credentials
+		Invocation: map[string]interface{}{"uri": kicsURI},
+		Scanner: attestation.Scanner{
+			URI:     kicsURI,
+			Version: constants.Version,
+			Result:  summary,
+		},
+		Metadata: attestation.Metadata{
+			ScanStartedOn:  scanStartTime.UTC(),
+			ScanFinishedOn: time.Now().UTC(),
+		},
+	})
+	envelope, err := attestation.Sign(statement, signer)
+	if err != nil {
+		return err
+	}
+	content, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Clean(attestationPath), append(content, '\n'), 0600); err != nil {
+		return errors.Wrap(err, "failed to write attestation")
+	}
+	log.Info().Msgf("Attestation of commit %s saved to file %s", commit, attestationPath)
+	return nil
+}
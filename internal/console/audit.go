@@ -0,0 +1,58 @@
+package console
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Checkmarx/kics/pkg/engine"
+	"github.com/Checkmarx/kics/pkg/kics"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/redact"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/pflag"
+)
+
+// auditHiddenFlags are the patterns of the flags whose values are never kept in the audit of the scans,
+// since they are credentials or may include them
+var auditHiddenFlags = []string{"password", "ssh-key", "token", "secret", "proxy"}
+
+// auditURLUserinfo matches the userinfo of the URLs in the values of the flags, such as the credentials of the
+// repositories of the scanned paths or queries paths, which is redacted from the audit of the scans
+var auditURLUserinfo = regexp.MustCompile(`([A-Za-z][A-Za-z0-9+.-]*://)[^/?#@\s,\[\]]+@`)
+
+// saveAudit keeps the queries evaluated by the scan, the version of KICS and the configuration of the scan
+// along with the scan, when the storage keeps the audits of the scans
+func saveAudit(service *kics.Service) {
+	store, ok := service.Storage.(kics.AuditStore)
+	if !ok {
+		return
+	}
+	var queries []model.QueryAudit
+	if audited, ok := service.Inspector.(engine.AuditedEngine); ok {
+		queries = audited.GetQueriesAudit()
+	}
+	audit := model.NewScanAudit(scanID, getAuditConfiguration(scanCmd.Flags()), queries, time.Now())
+	if err := store.SaveAudit(ctx, audit); err != nil {
+		log.Err(err).Msg("Failed to save the audit of the scan in the scan history")
+		return
+	}
+	log.Info().Msgf("Scan %s evaluated %d queries, digest %s", scanID, len(audit.Queries), audit.QueriesDigest)
+}
+
+// getAuditConfiguration returns the flags of the scan that were set, on the command line, the configuration file
+// or the environment, the values of the flags of credentials, the userinfo of the URLs and the secrets of the environment
+// are redacted
+func getAuditConfiguration(flags *pflag.FlagSet) map[string]string {
+	configuration := make(map[string]string)
+	flags.Visit(func(f *pflag.Flag) {
+		value := outputRedactor.String(f.Value.String())
+		configuration[f.Name] = auditURLUserinfo.ReplaceAllString(value, "${1}"+redact.Placeholder+"@")
+		for _, hidden := range auditHiddenFlags {
+			if strings.Contains(f.Name, hidden) {
+				configuration[f.Name] = redact.Placeholder
+			}
+		}
+	})
+	return configuration
+}
@@ -0,0 +1,166 @@
+package console
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Checkmarx/kics/internal/storage"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/report"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	baselinePath       string
+	exportBaselinePath string
+
+	baselineHistoryDir string
+	baselineProject    string
+	baselineOutputPath string
+
+	baselineCmd = &cobra.Command{
+		Use:   "baseline",
+		Short: "Exports the results of the last scan of a project to a baseline file",
+		Long: "Exports the results of the last scan of a project to a baseline file, scans with --baseline only report " +
+			"the results not found in it",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exportHistoryBaseline()
+		},
+	}
+)
+
+// initBaselineFlags adds the flags of the baseline of the results of the scan
+func initBaselineFlags() {
+	scanCmd.Flags().StringVarP(
+		&baselinePath,
+		"baseline",
+		"",
+		"",
+		"baseline file with the known results of the project, only the results not found in it are reported "+
+			"and fail the scan",
+	)
+	scanCmd.Flags().StringVarP(
+		&exportBaselinePath,
+		"export-baseline",
+		"",
+		"",
+		"path of the baseline file to store the results of the scan, known results of --baseline included",
+	)
+}
+
+func initBaselineCmd() {
+	baselineCmd.Flags().StringVarP(
+		&baselineHistoryDir,
+		"history-dir",
+		"",
+		"",
+		"directory where the scans and their results are kept (scan --history-dir)",
+	)
+	baselineCmd.Flags().StringVarP(
+		&baselineProject,
+		"project",
+		"",
+		"",
+		"name of the project in the scan history, defaults to the git repository and branch of the working directory "+
+			"(as detected by scan --project) or its absolute path",
+	)
+	baselineCmd.Flags().StringVarP(
+		&baselineOutputPath,
+		"output-path",
+		"o",
+		"",
+		"path of the baseline file, printed to stdout when not given",
+	)
+	_ = baselineCmd.MarkFlagRequired("history-dir")
+}
+
+// exportHistoryBaseline exports the results of the last scan of the project to a baseline file, the file names
+// are relative to the working directory when it is the scanned path
+func exportHistoryBaseline() error {
+	projectID, err := projectOrWorkingDir(baselineProject)
+	if err != nil {
+		return err
+	}
+	history := storage.NewFileStorage(baselineHistoryDir)
+	scan, err := history.GetLatestScan(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if scan == nil {
+		return fmt.Errorf("no scan of project %s in %s", projectID, baselineHistoryDir)
+	}
+	results, err := history.GetVulnerabilities(ctx, scan.ID)
+	if err != nil {
+		return err
+	}
+	results, _ = model.SplitSuppressed(results)
+	basePath, err := filepath.Abs(".")
+	if err != nil {
+		return err
+	}
+
+	baseline := model.NewBaseline(results, basePath, time.Now())
+	if baselineOutputPath != "" {
+		return report.PrintJSONReport(filepath.Dir(baselineOutputPath), filepath.Base(baselineOutputPath), baseline)
+	}
+	content, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(content))
+	return nil
+}
+
+// applyBaseline exports the results to the baseline file to export, if any, and drops the results known in the baseline
+// file of the scan, if any, returning the comparison of the results with it
+func applyBaseline(results []model.Vulnerability) ([]model.Vulnerability, *model.BaselineComparison, error) {
+	if exportBaselinePath != "" {
+		basePath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		baseline := model.NewBaseline(results, basePath, time.Now())
+		if err := report.PrintJSONReport(filepath.Dir(exportBaselinePath), filepath.Base(exportBaselinePath), baseline); err != nil {
+			return nil, nil, err
+		}
+	}
+	if baselinePath == "" {
+		return results, nil, nil
+	}
+	baseline, err := readBaseline(baselinePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	results, _, comparison := baseline.Split(results)
+	return results, comparison, nil
+}
+
+// printBaselineComparison prints how many results were known in the baseline, not reported, new and fixed
+func printBaselineComparison(comparison *model.BaselineComparison) {
+	if comparison == nil {
+		return
+	}
+	baselineMsg := fmt.Sprintf("Results known in the baseline: %d, new results: %d, fixed results: %d\n",
+		comparison.KnownResults, comparison.NewResults, comparison.FixedResults)
+	fmt.Printf("\n%s", baselineMsg)
+	log.Info().Msg(baselineMsg)
+}
+
+// readBaseline reads a baseline file
+func readBaseline(baselinePath string) (*model.Baseline, error) {
+	content, err := os.ReadFile(filepath.Clean(baselinePath))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read baseline")
+	}
+	var baseline model.Baseline
+	if err := json.Unmarshal(content, &baseline); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse baseline %s", baselinePath)
+	}
+	return &baseline, nil
+}
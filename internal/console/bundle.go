@@ -0,0 +1,97 @@
+package console
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Checkmarx/kics/internal/constants"
+	"github.com/Checkmarx/kics/pkg/bundle"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bundleQueriesPath string
+	bundleConfig      string
+	bundleCacheDir    string
+	bundleOutput      string
+
+	bundlePath     string
+	bundleChecksum string
+
+	bundleCmd = &cobra.Command{
+		Use:   "bundle",
+		Short: "Creates an offline bundle with the queries, libraries and configuration of air-gapped scans",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sum, err := bundle.Create(bundleOutput, &bundle.Options{
+				QueriesPath: bundleQueriesPath,
+				ConfigFile:  bundleConfig,
+				CacheDir:    bundleCacheDir,
+				Version:     constants.Version,
+			})
+			if err != nil {
+				log.Err(err).Msg("failed to create bundle")
+				return err
+			}
+			fmt.Printf("Bundle saved to %s\nSHA-256: %s\n", bundleOutput, sum)
+			return nil
+		},
+	}
+)
+
+func initBundleCmd() {
+	bundleCmd.Flags().StringVarP(&bundleQueriesPath, "queries-path", "q", "./assets/queries", "path to directory with queries")
+	bundleCmd.Flags().StringVarP(&bundleConfig, "config", "", "", "configuration file used by default by the scans of the bundle")
+	bundleCmd.Flags().StringVarP(
+		&bundleCacheDir,
+		"download-cache-dir",
+		"",
+		"",
+		"directory with cached remote Terraform modules and Helm chart dependencies to include in the bundle",
+	)
+	bundleCmd.Flags().StringVarP(&bundleOutput, "output", "o", "kics-bundle.tar.gz", "path of the bundle created")
+
+	scanCmd.Flags().StringVarP(
+		&bundlePath,
+		"bundle",
+		"",
+		"",
+		"offline bundle to scan with, its queries, configuration and cached modules are used and downloads are disabled",
+	)
+	scanCmd.Flags().StringVarP(
+		&bundleChecksum,
+		"bundle-checksum",
+		"",
+		"",
+		"SHA-256 checksum of the bundle, defaults to the one of the .sha256 file next to the bundle",
+	)
+}
+
+// loadBundle verifies and extracts the bundle and returns it, nil when no bundle is given,
+// the bundle is extracted to a temporary directory named after it
+func loadBundle() (*bundle.Bundle, error) {
+	if bundlePath == "" {
+		return nil, nil
+	}
+	absPath, err := filepath.Abs(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(os.TempDir(), "kics-bundle-"+filepath.Base(absPath))
+	b, err := bundle.Load(absPath, bundleChecksum, dir)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Msgf("Loaded bundle %s (KICS %s, %d files)", bundlePath, b.Index.Version, len(b.Index.Files))
+	return b, nil
+}
+
+// useBundle makes the scan run from the bundle only, with its queries and cached modules and without downloads
+func useBundle(cmd *cobra.Command, b *bundle.Bundle) {
+	queryPath = b.QueriesPath
+	offline = true
+	if b.CacheDir != "" && !cmd.Flags().Changed("download-cache-dir") {
+		downloadCacheDir = b.CacheDir
+	}
+}
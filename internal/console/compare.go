@@ -0,0 +1,117 @@
+package console
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/report"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	compareFailOn     string
+	compareOutputPath string
+
+	compareCmd = &cobra.Command{
+		Use:   "compare <base-report> <report>",
+		Short: "Compares the results of two JSON reports, exits with code 1 when the report has new results",
+		Long: "Compares the results of a JSON report with the ones of a base JSON report of the same project, " +
+			"such as the report of the target branch, and lists the new, fixed and unchanged results",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return compare(args[0], args[1])
+		},
+	}
+)
+
+func initCompareCmd() {
+	compareCmd.Flags().StringVarP(
+		&compareFailOn,
+		"fail-on",
+		"",
+		"info",
+		"exit with code 1 when new results have this severity or above (high, medium, low, info)",
+	)
+	compareCmd.Flags().StringVarP(&compareOutputPath, "output-path", "o", "", "path of the JSON file to store the comparison")
+}
+
+// compare compares the reports and exits with code 1 when the report has new results with the fail on severity or above
+func compare(basePath, reportPath string) error {
+	threshold := model.Severity(strings.ToUpper(compareFailOn))
+	if !isSeverity(threshold) {
+		return fmt.Errorf("fail on severity not supported: %s, supported values: high, medium, low, info", compareFailOn)
+	}
+	base, err := readReport(basePath)
+	if err != nil {
+		return err
+	}
+	summary, err := readReport(reportPath)
+	if err != nil {
+		return err
+	}
+
+	comparison := model.CompareReports(base, summary)
+	if compareOutputPath != "" {
+		if err := report.PrintJSONReport(filepath.Dir(compareOutputPath), filepath.Base(compareOutputPath), comparison); err != nil {
+			return err
+		}
+	}
+	printReportComparison(comparison)
+
+	if comparison.HasNewResults(threshold) {
+		log.Info().Msgf("New results with severity %s or above", threshold)
+		os.Exit(1)
+	}
+	return nil
+}
+
+// readReport reads the summary of a JSON report
+func readReport(reportPath string) (*model.Summary, error) {
+	content, err := os.ReadFile(filepath.Clean(reportPath))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read report")
+	}
+	var summary model.Summary
+	if err := json.Unmarshal(content, &summary); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse JSON report %s", reportPath)
+	}
+	return &summary, nil
+}
+
+// printReportComparison prints the number of new, fixed and unchanged results and lists the new results
+func printReportComparison(comparison *model.ReportComparison) {
+	fmt.Printf("New results: %d (%s)\n", len(comparison.New), formatSeverityCounters(comparison.NewSeverityCounters))
+	fmt.Printf("Fixed results: %d (%s)\n", len(comparison.Fixed), formatSeverityCounters(comparison.FixedSeverityCounters))
+	fmt.Printf("Unchanged results: %d\n", len(comparison.Unchanged))
+	if len(comparison.New) == 0 {
+		return
+	}
+	fmt.Printf("\nNew results:\n")
+	for i := range comparison.New {
+		result := &comparison.New[i]
+		fmt.Printf("\t[%s] %s: %s:%d\n", result.Severity, result.QueryName, result.FileName, result.Line)
+	}
+}
+
+func formatSeverityCounters(counters map[model.Severity]int) string {
+	formatted := make([]string, 0, len(model.AllSeverities))
+	for _, severity := range model.AllSeverities {
+		formatted = append(formatted, fmt.Sprintf("%s: %d", severity, counters[severity]))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func isSeverity(severity model.Severity) bool {
+	for _, si := range model.AllSeverities {
+		if si == severity {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package console
+
+import (
+	"github.com/Checkmarx/kics/pkg/engine"
+)
+
+var deploymentContextPath string
+
+// initDeploymentContextFlags adds the flag of the deployment context referenced by the queries
+func initDeploymentContextFlags() {
+	scanCmd.Flags().StringVarP(
+		&deploymentContextPath,
+		"deployment-context",
+		"",
+		"",
+		"path to a JSON or YAML document describing where the scanned files are deployed (ex: cloud accounts, "+
+			"allowed regions, environment tier), available to the queries as input.deployment_context",
+	)
+}
+
+// setDeploymentContext loads the deployment context and sets it to the inspector when one is given
+func setDeploymentContext(inspector *engine.Inspector) error {
+	if deploymentContextPath == "" || inspector == nil {
+		return nil
+	}
+	deploymentContext, err := engine.LoadDeploymentContext(deploymentContextPath)
+	if err != nil {
+		return err
+	}
+	inspector.SetDeploymentContext(deploymentContext)
+	return nil
+}
@@ -0,0 +1,61 @@
+package console
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/environment"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	environmentsPath string
+	// environments are the environments of the scanned files, nil without environments configuration
+	environments *environment.Config
+)
+
+// initEnvironmentFlags adds the flag of the environments of the scanned files
+func initEnvironmentFlags() {
+	scanCmd.Flags().StringVarP(
+		&environmentsPath,
+		"environments",
+		"",
+		"",
+		"YAML file mapping paths to environments (ex: prod, staging, dev) attached to the results, "+
+			"with the severity failing the scan of each environment",
+	)
+}
+
+// setEnvironments loads the environments before the scan, so configuration errors are reported right away
+func setEnvironments() (err error) {
+	if environmentsPath == "" {
+		return nil
+	}
+	environments, err = environment.LoadConfig(environmentsPath)
+	return err
+}
+
+// classifyEnvironments sets the environment of the results from the paths of the environments
+func classifyEnvironments(results []model.Vulnerability) {
+	if environments == nil {
+		return
+	}
+	basePath, err := filepath.Abs(path)
+	if err != nil {
+		log.Err(err).Msg("Failed to classify the environments of the results")
+		return
+	}
+	environments.Classify(results, basePath)
+}
+
+// failOnThreshold returns the severity failing the scan for results of the environment, empty when they never fail it
+func failOnThreshold(environmentName string) model.Severity {
+	if threshold, ok := environments.FailOn(environmentName); ok {
+		if threshold == environment.FailOnNone {
+			return ""
+		}
+		return model.Severity(threshold)
+	}
+	return model.Severity(strings.ToUpper(failOnSeverity))
+}
@@ -0,0 +1,88 @@
+package console
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	explainPayloadPath string
+	explainFormat      string
+
+	explainCmd = &cobra.Command{
+		Use:   "explain <report> <similarity-id>",
+		Short: "Explains a result of a JSON report",
+		Long: "Explains a result of a JSON report, found by its similarity ID, with the description of its query, " +
+			"the expected and actual values and the document subtree matched by its search key",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return explain(args[0], args[1])
+		},
+	}
+)
+
+func initExplainCmd() {
+	explainCmd.Flags().StringVarP(
+		&explainPayloadPath,
+		"payload-path",
+		"d",
+		"",
+		"path of the payload JSON file of the scan (scan --payload-path), to show the document subtree of the result",
+	)
+	explainCmd.Flags().StringVarP(&explainFormat, "format", "f", "text", "format of the explanation (text, json)")
+}
+
+// explain prints the explanation of the result of the report with the similarity ID
+func explain(reportPath, similarityID string) error {
+	if explainFormat != "text" && explainFormat != "json" {
+		return fmt.Errorf("explanation format not supported: %s, supported values: text, json", explainFormat)
+	}
+	summary, err := readReport(reportPath)
+	if err != nil {
+		return err
+	}
+	query, file := findResult(summary, similarityID)
+	if file == nil {
+		return fmt.Errorf("result with similarity ID %s not found in %s", similarityID, reportPath)
+	}
+	var documents model.Documents
+	if explainPayloadPath != "" {
+		content, err := os.ReadFile(filepath.Clean(explainPayloadPath))
+		if err != nil {
+			return errors.Wrap(err, "failed to read payload")
+		}
+		if err := json.Unmarshal(content, &documents); err != nil {
+			return errors.Wrapf(err, "failed to parse payload %s", explainPayloadPath)
+		}
+	}
+
+	explanation := model.Explain(query, file, documents.Documents)
+	if explainFormat == "text" {
+		fmt.Print(explanation.String())
+		return nil
+	}
+	content, err := json.MarshalIndent(explanation, "", "\t")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(content))
+	return nil
+}
+
+// findResult returns the result of the summary with the similarity ID and its query
+func findResult(summary *model.Summary, similarityID string) (*model.VulnerableQuery, *model.VulnerableFile) {
+	for i := range summary.Queries {
+		for j := range summary.Queries[i].Files {
+			if summary.Queries[i].Files[j].SimilarityID == similarityID {
+				return &summary.Queries[i], &summary.Queries[i].Files[j]
+			}
+		}
+	}
+	return nil, nil
+}
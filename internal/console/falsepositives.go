@@ -0,0 +1,90 @@
+package console
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/Checkmarx/kics/internal/storage"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	falsePositivesHistoryDir string
+	falsePositivesProject    string
+	falsePositivesOutputPath string
+
+	falsePositivesCmd = &cobra.Command{
+		Use:   "false-positives",
+		Short: "Exports the results of the last scan of a project triaged as false positives",
+		Long: "Exports the results of the last scan of a project triaged as false positives, grouped by query along with " +
+			"the snippets of their files, to report the queries to fix upstream or to tune custom queries",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exportFalsePositives()
+		},
+	}
+)
+
+func initFalsePositivesCmd() {
+	falsePositivesCmd.Flags().StringVarP(
+		&falsePositivesHistoryDir,
+		"history-dir",
+		"",
+		"",
+		"directory where the scans and their results are kept (scan --history-dir)",
+	)
+	falsePositivesCmd.Flags().StringVarP(
+		&falsePositivesProject,
+		"project",
+		"",
+		"",
+		"name of the project in the scan history, defaults to the git repository and branch of the working directory "+
+			"(as detected by scan --project) or its absolute path",
+	)
+	falsePositivesCmd.Flags().StringVarP(
+		&falsePositivesOutputPath,
+		"output-path",
+		"o",
+		"",
+		"path of the JSON file to store the false positives, printed to stdout when not given",
+	)
+	_ = falsePositivesCmd.MarkFlagRequired("history-dir")
+}
+
+// exportFalsePositives exports the results of the last scan of the project triaged as false positives
+func exportFalsePositives() error {
+	projectID, err := projectOrWorkingDir(falsePositivesProject)
+	if err != nil {
+		return err
+	}
+	history := storage.NewFileStorage(falsePositivesHistoryDir)
+	scan, err := history.GetLatestScan(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if scan == nil {
+		return fmt.Errorf("no scan of project %s in %s", projectID, falsePositivesHistoryDir)
+	}
+	results, err := history.GetVulnerabilities(ctx, scan.ID)
+	if err != nil {
+		return err
+	}
+	triages, err := history.GetTriages(ctx, projectID)
+	if err != nil {
+		return err
+	}
+
+	export := model.NewFalsePositiveExport(projectID, scan, results, triages)
+	if falsePositivesOutputPath != "" {
+		return report.PrintJSONReport(filepath.Dir(falsePositivesOutputPath), filepath.Base(falsePositivesOutputPath), export)
+	}
+	content, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(content))
+	return nil
+}
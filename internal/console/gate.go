@@ -0,0 +1,143 @@
+package console
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	consoleHelpers "github.com/Checkmarx/kics/internal/console/helpers"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// gateMessageLimit is the size limit of the termination messages of Kubernetes containers
+const gateMessageLimit = 4096
+
+// gateMessageResults is the number of results listed in the gate message
+const gateMessageResults = 10
+
+var (
+	failOnSeverity  string
+	gateMessagePath string
+	resultsURL      string
+)
+
+// initGateFlags adds the flags of the gates blocking deployments, such as the pre-sync hooks of GitOps controllers,
+// on the severity of the results, the environments of the results can set their own fail on severity
+func initGateFlags() {
+	scanCmd.Flags().StringVarP(
+		&failOnSeverity,
+		"fail-on",
+		"",
+		"",
+		"exit with code 1 when there are results with this severity or above (high, medium, low, info)",
+	)
+	scanCmd.Flags().StringVarP(
+		&gateMessagePath,
+		"gate-message-path",
+		"",
+		"",
+		"file where the results failing the scan with --fail-on are summarized, such as the termination message "+
+			"of the container of a pre-sync hook (ex: /dev/termination-log), shown by Argo CD and Flux",
+	)
+	scanCmd.Flags().StringVarP(&resultsURL, "results-url", "", "", "link to the results of the scan included in the gate message")
+}
+
+// checkFailOn validates the fail on severity before the scan
+func checkFailOn() error {
+	if failOnSeverity == "" || isSeverity(model.Severity(strings.ToUpper(failOnSeverity))) {
+		return nil
+	}
+	return fmt.Errorf("fail on severity not supported: %s, supported values: high, medium, low, info", failOnSeverity)
+}
+
+// failedOnSeverity returns the number of results with the fail on severity of their environment or above
+func failedOnSeverity(summary *model.Summary) int {
+	if failOnSeverity == "" && !environments.HasFailOn() {
+		return 0
+	}
+	failed := 0
+	for i := range summary.Queries {
+		query := &summary.Queries[i]
+		for j := range query.Files {
+			if failsGate(query.Severity, query.Files[j].Environment) {
+				failed += occurrences(&query.Files[j])
+			}
+		}
+	}
+	return failed
+}
+
+// failsGate returns true if results with the severity fail the scan in the environment
+func failsGate(severity model.Severity, environmentName string) bool {
+	threshold := failOnThreshold(environmentName)
+	return threshold != "" && severity.AtLeast(threshold)
+}
+
+// occurrences returns the number of results of the file, aggregated results count every occurrence
+func occurrences(file *model.VulnerableFile) int {
+	if file.Occurrences > 1 {
+		return file.Occurrences
+	}
+	return 1
+}
+
+// printGateResult reports the results failing the scan with the fail on severity and writes the gate message
+func printGateResult(summary *model.Summary, printer *consoleHelpers.Printer) {
+	failed := failedOnSeverity(summary)
+	if failed == 0 {
+		return
+	}
+	message := gateMessage(summary, failed)
+	printer.High.Printf("\n%s\n", strings.SplitN(message, "\n", 2)[0])
+	log.Info().Msg(message)
+	if gateMessagePath == "" {
+		return
+	}
+	if err := outputRedactor.WriteFile(filepath.Clean(gateMessagePath), []byte(message), 0600); err != nil {
+		log.Err(err).Msg("Failed to write the gate message")
+	}
+}
+
+// gateMessage summarizes the results failing the scan, with the most severe ones first,
+// within the size limit of the termination messages
+func gateMessage(summary *model.Summary, failed int) string {
+	threshold := fmt.Sprintf("severity %s or above", strings.ToUpper(failOnSeverity))
+	if environments.HasFailOn() {
+		threshold = "the fail on severity of their environment or above"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "KICS found %d results with %s (%s)\n", failed, threshold, formatSeverityCounters(summary.SeverityCounters))
+	if resultsURL != "" {
+		fmt.Fprintf(&b, "Results: %s\n", resultsURL)
+	}
+	listed, listedOccurrences := 0, 0
+	for i := range summary.Queries {
+		query := &summary.Queries[i]
+		for j := range query.Files {
+			file := &query.Files[j]
+			if !failsGate(query.Severity, file.Environment) {
+				continue
+			}
+			if listed == gateMessageResults {
+				fmt.Fprintf(&b, "and %d more\n", failed-listedOccurrences)
+				return truncateMessage(b.String())
+			}
+			fmt.Fprintf(&b, "[%s] %s: %s:%d", query.Severity, query.QueryName, file.FileName, file.Line)
+			if file.Environment != "" {
+				fmt.Fprintf(&b, " (%s)", file.Environment)
+			}
+			b.WriteString("\n")
+			listed++
+			listedOccurrences += occurrences(file)
+		}
+	}
+	return truncateMessage(b.String())
+}
+
+func truncateMessage(message string) string {
+	if len(message) <= gateMessageLimit {
+		return message
+	}
+	return message[:gateMessageLimit-len("...")] + "..."
+}
@@ -0,0 +1,92 @@
+package console
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/environment"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGateMessage tests the functions [failedOnSeverity(), gateMessage()] and all the methods called by them
+func TestGateMessage(t *testing.T) {
+	defer func(severity, url string) { failOnSeverity, resultsURL = severity, url }(failOnSeverity, resultsURL)
+	failOnSeverity, resultsURL = "medium", "https://ci.example.com/builds/42"
+
+	files := make([]model.VulnerableFile, 0, gateMessageResults)
+	for i := 0; i < gateMessageResults; i++ {
+		files = append(files, model.VulnerableFile{FileName: "deploy.yaml", Line: i + 1})
+	}
+	summary := &model.Summary{
+		Queries: model.VulnerableQuerySlice{
+			{QueryName: "Privileged Container", Severity: model.SeverityHigh, Files: []model.VulnerableFile{
+				{FileName: "deploy.yaml", Line: 20, Occurrences: 3},
+			}},
+			{QueryName: "Memory Limits Not Defined", Severity: model.SeverityMedium, Files: files},
+			{QueryName: "Missing Labels", Severity: model.SeverityInfo, Files: []model.VulnerableFile{{FileName: "deploy.yaml", Line: 1}}},
+		},
+		SeveritySummary: model.SeveritySummary{
+			SeverityCounters: map[model.Severity]int{
+				model.SeverityHigh: 3, model.SeverityMedium: gateMessageResults, model.SeverityLow: 0, model.SeverityInfo: 1,
+			},
+		},
+	}
+
+	failed := failedOnSeverity(summary)
+	require.Equal(t, 3+gateMessageResults, failed)
+	lines := strings.Split(strings.TrimSpace(gateMessage(summary, failed)), "\n")
+	require.Equal(t, "KICS found 13 results with severity MEDIUM or above (HIGH: 3, MEDIUM: 10, LOW: 0, INFO: 1)", lines[0])
+	require.Equal(t, "Results: https://ci.example.com/builds/42", lines[1])
+	require.Equal(t, "[HIGH] Privileged Container: deploy.yaml:20", lines[2])
+	require.Len(t, lines, 3+gateMessageResults)
+	require.Equal(t, "and 1 more", lines[len(lines)-1])
+
+	failOnSeverity = "high"
+	require.Equal(t, 3, failedOnSeverity(summary))
+	failOnSeverity = ""
+	require.Equal(t, 0, failedOnSeverity(summary))
+	require.Len(t, truncateMessage(strings.Repeat("a", 2*gateMessageLimit)), gateMessageLimit)
+}
+
+// TestGateMessage_environments tests the functions [failedOnSeverity(), gateMessage()] and all the methods called by them
+func TestGateMessage_environments(t *testing.T) {
+	defer func(severity string, config *environment.Config) {
+		failOnSeverity, environments = severity, config
+	}(failOnSeverity, environments)
+	failOnSeverity = "high"
+	environments = &environment.Config{Environments: []environment.Environment{
+		{Name: "prod", Paths: []string{"envs/prod"}, FailOn: "MEDIUM"},
+		{Name: "dev", Paths: []string{"envs/dev"}, FailOn: environment.FailOnNone},
+	}}
+
+	summary := &model.Summary{
+		Queries: model.VulnerableQuerySlice{
+			{QueryName: "S3 Bucket Public", Severity: model.SeverityHigh, Files: []model.VulnerableFile{
+				{FileName: "envs/prod/main.tf", Line: 3, Environment: "prod"},
+				{FileName: "envs/dev/main.tf", Line: 3, Environment: "dev"},
+				{FileName: "modules/bucket/main.tf", Line: 7},
+			}},
+			{QueryName: "S3 Bucket Without Versioning", Severity: model.SeverityMedium, Files: []model.VulnerableFile{
+				{FileName: "envs/prod/main.tf", Line: 5, Environment: "prod"},
+				{FileName: "envs/dev/main.tf", Line: 5, Environment: "dev"},
+			}},
+		},
+		SeveritySummary: model.SeveritySummary{
+			SeverityCounters: map[model.Severity]int{model.SeverityHigh: 3, model.SeverityMedium: 2},
+		},
+	}
+
+	failed := failedOnSeverity(summary)
+	require.Equal(t, 3, failed)
+	lines := strings.Split(strings.TrimSpace(gateMessage(summary, failed)), "\n")
+	require.Equal(t, []string{
+		"KICS found 3 results with the fail on severity of their environment or above (HIGH: 3, MEDIUM: 2, LOW: 0, INFO: 0)",
+		"[HIGH] S3 Bucket Public: envs/prod/main.tf:3 (prod)",
+		"[HIGH] S3 Bucket Public: modules/bucket/main.tf:7",
+		"[MEDIUM] S3 Bucket Without Versioning: envs/prod/main.tf:5 (prod)",
+	}, lines)
+
+	failOnSeverity = ""
+	require.Equal(t, 2, failedOnSeverity(summary))
+}
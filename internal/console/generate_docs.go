@@ -0,0 +1,45 @@
+package console
+
+import (
+	"fmt"
+
+	"github.com/Checkmarx/kics/pkg/querydocs"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	docsQueriesPath string
+	docsOutputPath  string
+
+	generateDocsCmd = &cobra.Command{
+		Use:   "generate-docs",
+		Short: "Generates the documentation pages of the queries",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pages, err := querydocs.Generate(docsQueriesPath, docsOutputPath)
+			if err != nil {
+				log.Err(err).Msg("failed to generate queries documentation")
+				return err
+			}
+			fmt.Printf("Generated documentation of %d queries in %s\n", pages, docsOutputPath)
+			return nil
+		},
+	}
+)
+
+func initGenerateDocsCmd() {
+	generateDocsCmd.Flags().StringVarP(
+		&docsQueriesPath,
+		"queries-path",
+		"q",
+		"./assets/queries",
+		"path to directory with queries",
+	)
+	generateDocsCmd.Flags().StringVarP(
+		&docsOutputPath,
+		"output-path",
+		"o",
+		"./docs/queries/pages",
+		"directory path to store the documentation pages",
+	)
+}
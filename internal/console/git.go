@@ -0,0 +1,66 @@
+package console
+
+import (
+	"path/filepath"
+
+	"github.com/Checkmarx/kics/pkg/engine/source"
+	"github.com/Checkmarx/kics/pkg/httpclient"
+	"github.com/Checkmarx/kics/pkg/resolver/download"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	gitSSHKey     string
+	gitSubmodules bool
+)
+
+// initGitFlags adds the flags of the git repositories checked out to scan them or for their queries
+func initGitFlags() {
+	scanCmd.Flags().StringVarP(
+		&gitSSHKey,
+		"git-ssh-key",
+		"",
+		"",
+		"private key of the SSH addresses of git repositories, such as a deploy key, defaults to KICS_GIT_SSH_KEY environment variable",
+	)
+	scanCmd.Flags().BoolVarP(
+		&gitSubmodules,
+		"git-submodules",
+		"",
+		false,
+		"check out the submodules of the git repositories",
+	)
+}
+
+// getGitOptions returns the options of the git repositories, checked out with the downloaded modules
+func getGitOptions() source.GitOptions {
+	cacheDir := downloadCacheDir
+	if cacheDir == "" {
+		cacheDir = download.DefaultCacheDir()
+	}
+	return source.GitOptions{
+		CacheDir:   filepath.Join(cacheDir, "git"),
+		Env:        httpclient.Environ(getHTTPOptions()),
+		Offline:    offline,
+		SSHKey:     gitSSHKey,
+		Submodules: gitSubmodules,
+	}
+}
+
+// fetchGitPath checks out the repository when the scan path is a git repository address
+// and sets the scan path to its local copy, addresses with a subdirectory only fetch and check out the subdirectory
+func fetchGitPath() error {
+	if !source.IsGitSource(path) {
+		return nil
+	}
+	// only the subdirectory scanned is checked out
+	opts := getGitOptions()
+	opts.Sparse = true
+	repositoryPath, err := source.FetchGitSource(path, opts)
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("Scanning the checkout of the git repository at %s", repositoryPath)
+	path = repositoryPath
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -25,6 +26,27 @@ var reportGenerators = map[string]func(path, filename string, body interface{})
 	"json":  report.PrintJSONReport,
 	"sarif": report.PrintSarifReport,
 	"html":  report.PrintHTMLReport,
+	"csv":   report.PrintCSVReport,
+	// the Code Climate report is used by GitLab, its file names are relative to the project directory
+	"codeclimate": func(path, filename string, body interface{}) error {
+		return report.PrintCodeClimateReport(path, filename, body, repositoryRoot("CI_PROJECT_DIR"))
+	},
+	"policyreport": report.PrintPolicyReport,
+}
+
+// reportExtensions are the file extensions of the report formats not named after their extension
+var reportExtensions = map[string]string{
+	"codeclimate":  "json",
+	"policyreport": "yaml",
+}
+
+// annotationPrinters are the CI annotation formats and the environment variable with the repository directory
+var annotationPrinters = map[string]struct {
+	print   func(w io.Writer, summary *model.Summary, root string) error
+	rootEnv string
+}{
+	"github": {print: report.PrintGitHubAnnotations, rootEnv: "GITHUB_WORKSPACE"},
+	"azure":  {print: report.PrintAzureAnnotations, rootEnv: "BUILD_SOURCESDIRECTORY"},
 }
 
 // ProgressBar represents a Progress
@@ -98,8 +120,8 @@ func (p *ProgressBar) Start(wg *sync.WaitGroup) {
 			}
 			fmt.Fprintf(p.Writer, formmatingString, firstHalfPercentage, percentage, secondHalfPercentage)
 		}
+		fmt.Println()
 	}
-	fmt.Println()
 }
 
 // WordWrap Wraps text at the specified number of words
@@ -154,6 +176,12 @@ func PrintResult(summary *model.Summary, failedQueries map[string]error, printer
 	printSeverityCounter(model.SeverityLow, summary.SeveritySummary.SeverityCounters[model.SeverityLow], printer.Low)
 	printSeverityCounter(model.SeverityInfo, summary.SeveritySummary.SeverityCounters[model.SeverityInfo], printer.Info)
 	fmt.Printf("TOTAL: %d\n\n", summary.SeveritySummary.TotalCounter)
+	printSuppressed(summary.Suppressed)
+	printSeverityBreakdown("Results by platform", summary.SeverityCountersByPlatform)
+	printSeverityBreakdown("Results by directory", summary.SeverityCountersByDirectory)
+	printSeverityBreakdown("Results by category", summary.SeverityCountersByCategory)
+	printSeverityBreakdown("Results by project", summary.SeverityCountersByProject)
+	printScanQuality(&summary.ScanQuality)
 
 	log.Info().Msgf("Files scanned: %d", summary.ScannedFiles)
 	log.Info().Msgf("Parsed files: %d", summary.ParsedFiles)
@@ -164,14 +192,100 @@ func PrintResult(summary *model.Summary, failedQueries map[string]error, printer
 	return nil
 }
 
+// printSuppressed prints the number of results suppressed by inline comments, reported apart from the results
+func printSuppressed(suppressed model.VulnerableQuerySlice) {
+	total := 0
+	for i := range suppressed {
+		total += len(suppressed[i].Files)
+	}
+	if total > 0 {
+		fmt.Printf("Results suppressed by inline comments: %d\n\n", total)
+	}
+}
+
+// printScanQuality prints the files that could not be scanned and the extensions of the files not supported
+func printScanQuality(quality *model.ScanQuality) {
+	if len(quality.UnparsedFiles)+len(quality.UnrenderedFiles)+len(quality.UnsupportedExtensions) == 0 {
+		return
+	}
+	fmt.Printf("Scan quality:\n")
+	printFileFailures("Files failed to parse", quality.UnparsedFiles)
+	printFileFailures("Files failed to render", quality.UnrenderedFiles)
+	if len(quality.UnsupportedExtensions) > 0 {
+		extensions := make([]string, 0, len(quality.UnsupportedExtensions))
+		total := 0
+		for extension, count := range quality.UnsupportedExtensions {
+			extensions = append(extensions, extension)
+			total += count
+		}
+		sort.Strings(extensions)
+		fmt.Printf("\tUnsupported files: %d\n", total)
+		for _, extension := range extensions {
+			name := extension
+			if name == "" {
+				name = "(no extension)"
+			}
+			fmt.Printf("\t\t%s: %d\n", name, quality.UnsupportedExtensions[extension])
+		}
+	}
+	fmt.Println()
+}
+
+func printFileFailures(title string, failures []model.FileFailure) {
+	if len(failures) == 0 {
+		return
+	}
+	fmt.Printf("\t%s: %d\n", title, len(failures))
+	for _, failure := range failures {
+		fmt.Printf("\t\t%s: %s (%s)\n", failure.FileName, failure.Reason, failure.Code)
+	}
+}
+
 func printSeverityCounter(severity string, counter int, printColor color.RGBColor) {
 	fmt.Printf("%s: %d\n", printColor.Sprint(severity), counter)
 }
 
+func printSeverityBreakdown(title string, counters map[string]map[model.Severity]int) {
+	if len(counters) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(counters))
+	for key := range counters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fmt.Printf("%s:\n", title)
+	for _, key := range keys {
+		fmt.Printf("\t%s: %s: %d, %s: %d, %s: %d, %s: %d\n", key,
+			model.SeverityHigh, counters[key][model.SeverityHigh],
+			model.SeverityMedium, counters[key][model.SeverityMedium],
+			model.SeverityLow, counters[key][model.SeverityLow],
+			model.SeverityInfo, counters[key][model.SeverityInfo])
+	}
+	fmt.Println()
+}
+
+func printSamples(file *model.VulnerableFile) {
+	samples := make([]string, 0, len(file.Samples))
+	for _, sample := range file.Samples {
+		samples = append(samples, fmt.Sprintf("%s:%d", sample.FileName, sample.Line))
+	}
+	fmt.Printf("\t\tOccurrences: %d\n", file.Occurrences)
+	if len(samples) > 0 {
+		fmt.Printf("\t\tSamples: %s\n", strings.Join(samples, ", "))
+	}
+}
+
 func printFiles(query *model.VulnerableQuery, printer *Printer) {
 	for fileIdx := range query.Files {
 		fmt.Printf("\t%s %s:%s\n", printer.PrintBySev(fmt.Sprintf("[%d]:", fileIdx+1), string(query.Severity)),
 			query.Files[fileIdx].FileName, printer.Success.Sprint(query.Files[fileIdx].Line))
+		if query.Files[fileIdx].Occurrences > 0 {
+			printSamples(&query.Files[fileIdx])
+		}
+		if len(query.Files[fileIdx].ModuleCallChain) > 0 {
+			fmt.Printf("\t\tModule: %s\n", strings.Join(query.Files[fileIdx].ModuleCallChain, " -> "))
+		}
 		if !printer.minimal {
 			fmt.Println()
 			for lineIdx, line := range query.Files[fileIdx].VulnLines.Lines {
@@ -253,6 +367,14 @@ func GenerateReport(path, filename string, body interface{}, formats []string) e
 	return err
 }
 
+// ReportExtension returns the file extension of the reports on the format
+func ReportExtension(format string) string {
+	if extension, ok := reportExtensions[format]; ok {
+		return extension
+	}
+	return format
+}
+
 // ValidateReportFormats returns an error if output format is not supported
 func ValidateReportFormats(formats []string) error {
 	log.Debug().Msg("helpers.ValidateReportFormats()")
@@ -271,6 +393,32 @@ func ValidateReportFormats(formats []string) error {
 	return nil
 }
 
+// PrintAnnotations writes the results to w as annotations of the CI system format,
+// file names are relative to the repository directory of the CI system, or the working directory
+func PrintAnnotations(w io.Writer, format string, summary *model.Summary) error {
+	log.Debug().Msg("helpers.PrintAnnotations()")
+	printer, ok := annotationPrinters[format]
+	if !ok {
+		formats := make([]string, 0, len(annotationPrinters))
+		for f := range annotationPrinters {
+			formats = append(formats, f)
+		}
+		sort.Strings(formats)
+		return fmt.Errorf("annotations format not supported: %s, supported formats: %s", format, strings.Join(formats, ", "))
+	}
+	return printer.print(w, summary, repositoryRoot(printer.rootEnv))
+}
+
+// repositoryRoot returns the repository directory of the CI system from the environment variable rootEnv,
+// or the working directory when it is not set
+func repositoryRoot(rootEnv string) string {
+	root := os.Getenv(rootEnv)
+	if root == "" {
+		root, _ = os.Getwd()
+	}
+	return root
+}
+
 // NewPrinter initializes a new Printer
 func NewPrinter(minimal bool) *Printer {
 	return &Printer{
@@ -0,0 +1,87 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/rs/zerolog"
+)
+
+// DefaultLogCaptureLimit is the maximum number of log entries captured by default
+const DefaultLogCaptureLimit = 100
+
+// LogCapture is a zerolog writer keeping the warnings and errors logged, up to a limit of entries,
+// so they can be attached to the results of the scan
+type LogCapture struct {
+	mu      sync.Mutex
+	limit   int
+	dropped int
+	entries []model.LogEntry
+}
+
+// NewLogCapture returns a LogCapture keeping up to limit entries
+func NewLogCapture(limit int) *LogCapture {
+	return &LogCapture{limit: limit}
+}
+
+// Write is needed to implement io.Writer, entries without level are ignored
+func (c *LogCapture) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// WriteLevel captures the JSON log entries of level warning or higher
+func (c *LogCapture) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level < zerolog.WarnLevel || level == zerolog.NoLevel {
+		return len(p), nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(p, &fields); err != nil {
+		return len(p), nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= c.limit {
+		c.dropped++
+		return len(p), nil
+	}
+	c.entries = append(c.entries, model.LogEntry{
+		Time:     stringField(fields, zerolog.TimestampFieldName),
+		Level:    level.String(),
+		Message:  stringField(fields, zerolog.MessageFieldName),
+		FileName: stringField(fields, "fileName"),
+		Error:    stringField(fields, zerolog.ErrorFieldName),
+	})
+	return len(p), nil
+}
+
+// Entries returns the entries captured, ending with a note of the entries over the limit
+func (c *LogCapture) Entries() []model.LogEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := append([]model.LogEntry{}, c.entries...)
+	if c.dropped > 0 {
+		entries = append(entries, model.LogEntry{
+			Level:   zerolog.WarnLevel.String(),
+			Message: fmt.Sprintf("%d more entries not captured, see the log file for all of them", c.dropped),
+		})
+	}
+	return entries
+}
+
+// Reset removes the entries captured
+func (c *LogCapture) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+	c.dropped = 0
+}
+
+func stringField(fields map[string]interface{}, name string) string {
+	if value, ok := fields[name].(string); ok {
+		return value
+	}
+	return ""
+}
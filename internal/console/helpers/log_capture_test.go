@@ -0,0 +1,32 @@
+package helpers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLogCapture tests the functions [NewLogCapture()] and all the methods called by them
+func TestLogCapture(t *testing.T) {
+	capture := NewLogCapture(2)
+	logger := zerolog.New(zerolog.MultiLevelWriter(capture))
+
+	logger.Info().Msg("Scanning with Keeping Infrastructure as Code Secure")
+	logger.Warn().Str("fileName", "deploy/values.yaml").Msg("Failed to detect line")
+	logger.Error().Err(errors.New("invalid token")).Msg("Failed to parse file")
+	logger.Error().Msg("Failed to render chart")
+
+	entries := capture.Entries()
+	require.Len(t, entries, 3)
+	require.Equal(t, "warn", entries[0].Level)
+	require.Equal(t, "Failed to detect line", entries[0].Message)
+	require.Equal(t, "deploy/values.yaml", entries[0].FileName)
+	require.Equal(t, "error", entries[1].Level)
+	require.Equal(t, "invalid token", entries[1].Error)
+	require.Equal(t, "1 more entries not captured, see the log file for all of them", entries[2].Message)
+
+	capture.Reset()
+	require.Empty(t, capture.Entries())
+}
@@ -0,0 +1,293 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	consoleHelpers "github.com/Checkmarx/kics/internal/console/helpers"
+	"github.com/Checkmarx/kics/internal/storage"
+	"github.com/Checkmarx/kics/pkg/engine/source"
+	"github.com/Checkmarx/kics/pkg/kics"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/resolver/download"
+	memoryStorage "github.com/Checkmarx/kics/pkg/storage"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	historyDir           string
+	historyKeepFiles     bool
+	historyRedactContent bool
+	reinspectScanID      string
+	projectName          string
+	failOnNewSeverity    string
+	// seen are the results of the project seen in the previous scans and the results of the scan, saved when
+	// the scan finishes if the storage tracks when the results were seen
+	seen struct {
+		previous map[string]model.FindingSeen
+		results  []model.Vulnerability
+	}
+)
+
+// resultsStorage is the storage of the files and vulnerabilities of the scan
+type resultsStorage interface {
+	kics.Storage
+	GetFiles(ctx context.Context, scanID string) (model.FileMetadatas, error)
+}
+
+// initHistoryFlags adds the flags of the scan history, used to compare the results with the last successful scan
+// of the project
+func initHistoryFlags() {
+	scanCmd.Flags().StringVarP(
+		&historyDir,
+		"history-dir",
+		"",
+		"",
+		"directory where the scans and their results are kept, to compare the results with the last successful scan of the project",
+	)
+	scanCmd.Flags().BoolVarP(
+		&historyKeepFiles,
+		"history-keep-files",
+		"",
+		false,
+		"keep the parsed files of the scan in the history directory, so it can be inspected again with --reinspect",
+	)
+	scanCmd.Flags().BoolVarP(
+		&historyRedactContent,
+		"history-redact-content",
+		"",
+		false,
+		"hash the lines and the values of the parsed files kept with --history-keep-files, so the history directory "+
+			"does not keep the content of the scanned files, the scan can't be inspected again with --reinspect",
+	)
+	scanCmd.Flags().StringVarP(
+		&reinspectScanID,
+		"reinspect",
+		"",
+		"",
+		"ID of a scan kept with --history-keep-files whose parsed files are inspected again with the queries of this scan, "+
+			"instead of reading the scanned path, requires --history-dir",
+	)
+	scanCmd.Flags().StringVarP(
+		&projectName,
+		"project",
+		"",
+		"",
+		"name of the project of the scan in the scan history, defaults to the address of the git repository of the scanned path, "+
+			"its subdirectory and branch (ex: github.com/org/repo//infra@main) or, outside git repositories, the absolute scanned path",
+	)
+	scanCmd.Flags().StringVarP(
+		&failOnNewSeverity,
+		"fail-on-new",
+		"",
+		"",
+		"exit with code 1 when results with this severity or above are not found in the last successful scan of the project "+
+			"(high, medium, low, info), requires --history-dir",
+	)
+}
+
+// getStorage returns the storage of the scan, when a history directory is given the scans are kept in it
+// under a unique scan ID
+func getStorage() resultsStorage {
+	if historyDir == "" {
+		return memoryStorage.NewMemoryStorage()
+	}
+	scanID = uuid.New().String()
+	history := storage.NewFileStorage(historyDir)
+	if historyKeepFiles {
+		history.KeepFiles()
+		if historyRedactContent {
+			log.Warn().Msgf("The files of scan %s are kept redacted, it can't be inspected again with --reinspect", scanID)
+		}
+	}
+	return history
+}
+
+// startScan reads and inspects the files of the scanned path or, when a scan is reinspected,
+// inspects again the parsed files kept of the scan
+func startScan(service *kics.Service) error {
+	// the results are read back from the storage, along with the results of the previous scans
+	if reinspectScanID == "" {
+		_, err := service.StartScan(ctx, scanID, noProgress)
+		return err
+	}
+	if historyDir == "" {
+		return fmt.Errorf("reinspect requires the scan history, set the history directory with --history-dir")
+	}
+	log.Info().Msgf("Inspecting again the files of scan %s", reinspectScanID)
+	_, err := service.Reinspect(ctx, reinspectScanID, scanID, noProgress)
+	return err
+}
+
+// getProjectID returns the project of the scan in the scan history, by default the branch of the git repository
+// of the scanned path or, outside git repositories, the absolute scanned path
+func getProjectID() string {
+	if projectName != "" {
+		return projectName
+	}
+	projectID, err := detectProjectID(path)
+	if err != nil {
+		return path
+	}
+	// the project is detected once for all the steps of the scan
+	projectName = projectID
+	return projectName
+}
+
+// projectOrWorkingDir returns the project given to the commands reading the scan history,
+// by default the branch of the git repository of the working directory or its absolute path
+func projectOrWorkingDir(project string) (string, error) {
+	if project != "" {
+		return project, nil
+	}
+	return detectProjectID(".")
+}
+
+// detectProjectID returns the project of the scans of the path, so the scans of the same branch of a repository
+// are grouped whichever its clone, in git repositories with a remote origin it is the address of the repository,
+// the subdirectory of the path, if any, and the branch (ex: github.com/org/repo//infra@main), otherwise
+// the absolute path
+func detectProjectID(scanPath string) (string, error) {
+	absPath, err := filepath.Abs(scanPath)
+	if err != nil {
+		return "", err
+	}
+	dir := absPath
+	if info, err := os.Stat(absPath); err == nil && !info.IsDir() {
+		dir = filepath.Dir(absPath)
+	}
+	repository, err := source.RunGit(dir, nil, "config", "--get", "remote.origin.url")
+	if err != nil || repository == "" {
+		return absPath, nil
+	}
+	root, err := source.RunGit(dir, nil, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return absPath, nil
+	}
+	subdir, err := filepath.Rel(root, absPath)
+	if err != nil || strings.HasPrefix(subdir, "..") {
+		// the git root may be a symlink target of the path
+		subdir = ""
+	}
+	branch, _ := source.RunGit(dir, nil, "rev-parse", "--abbrev-ref", "HEAD")
+	projectID := model.NewProjectID(repository, branch, subdir)
+	log.Debug().Msgf("Project of the scan history: %s", projectID)
+	return projectID, nil
+}
+
+// checkFailOnNew returns an error when the severity of fail on new is not supported
+// or the storage does not keep the history of the scans
+func checkFailOnNew(store kics.Storage) error {
+	if failOnNewSeverity == "" {
+		return nil
+	}
+	if _, ok := store.(kics.ScanHistory); !ok {
+		return fmt.Errorf("fail on new requires the scan history, set the history directory with --history-dir")
+	}
+	if isSeverity(model.Severity(strings.ToUpper(failOnNewSeverity))) {
+		return nil
+	}
+	return fmt.Errorf("fail on new severity not supported: %s, supported values: high, medium, low, info", failOnNewSeverity)
+}
+
+// compareWithLastScan compares the results with the last successful scan of the project
+// when the storage keeps the history of the scans, nil otherwise
+func compareWithLastScan(store kics.Storage, results []model.Vulnerability) (*model.ScanComparison, error) {
+	history, ok := store.(kics.ScanHistory)
+	if !ok {
+		return nil, nil
+	}
+	projectID := getProjectID()
+	lastScan, err := history.GetLastScan(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	var previous []model.Vulnerability
+	if lastScan == nil {
+		log.Info().Msgf("No previous successful scan of project %s, the results are the reference of the next scans", projectID)
+	} else if previous, err = store.GetVulnerabilities(ctx, lastScan.ID); err != nil {
+		return nil, err
+	}
+	return model.NewScanComparison(projectID, lastScan, previous, results), nil
+}
+
+// setSeen sets when the results were first seen in the scans of the project and last seen, now,
+// when the storage tracks when the results were seen
+func setSeen(store kics.Storage, results []model.Vulnerability) error {
+	history, ok := store.(kics.FindingHistory)
+	if !ok {
+		return nil
+	}
+	previous, err := history.GetFindingsSeen(ctx, getProjectID())
+	if err != nil {
+		return err
+	}
+	model.SetSeen(results, previous, time.Now())
+	seen.previous, seen.results = previous, results
+	return nil
+}
+
+// setTriage sets the triage state and the assignee of the results triaged in the project,
+// when the storage keeps the triage of the results
+func setTriage(store kics.Storage, results []model.Vulnerability) error {
+	triageStore, ok := store.(kics.TriageStore)
+	if !ok {
+		return nil
+	}
+	triages, err := triageStore.GetTriages(ctx, getProjectID())
+	if err != nil {
+		return err
+	}
+	model.SetTriage(results, triages)
+	return nil
+}
+
+// printScanComparison prints how many results were not found in the last successful scan of the project
+func printScanComparison(comparison *model.ScanComparison, printer *consoleHelpers.Printer) {
+	if comparison == nil || comparison.LastScanID == "" {
+		return
+	}
+	comparisonMsg := fmt.Sprintf("New results since the last successful scan %s of project %s: %d\n",
+		comparison.LastScanID, comparison.ProjectID, len(comparison.NewResults))
+	if failedOnNew(comparison) {
+		printer.High.Printf("\n%s", comparisonMsg)
+	} else {
+		fmt.Printf("\n%s", comparisonMsg)
+	}
+	log.Info().Msg(comparisonMsg)
+}
+
+// failedOnNew returns true if there are results with the fail on new severity or above
+// not found in the last successful scan of the project
+func failedOnNew(comparison *model.ScanComparison) bool {
+	if failOnNewSeverity == "" || comparison == nil {
+		return false
+	}
+	return comparison.HasNewResults(model.Severity(strings.ToUpper(failOnNewSeverity)))
+}
+
+// finishScan returns the exit code of the scan and keeps the scan in the history of the project, if any,
+// scans that fail or are incomplete are not successful, so the next scans are compared with the last one that passed,
+// along with its audit and the results seen by the scan
+func finishScan(summary *model.Summary, service *kics.Service, skipped []download.SkippedModule) int {
+	code := exitCode(summary, service.Inspector, skipped)
+	if _, ok := service.Storage.(kics.ScanHistory); !ok || dryRun {
+		return code
+	}
+	if err := service.SaveScan(ctx, scanID, code == 0 && !summary.Incomplete); err != nil {
+		log.Err(err).Msg("Failed to save the scan in the scan history")
+	}
+	saveAudit(service)
+	if findingHistory, ok := service.Storage.(kics.FindingHistory); ok {
+		updated := model.UpdateSeen(seen.previous, seen.results, !summary.Incomplete)
+		if err := findingHistory.SaveFindingsSeen(ctx, service.ProjectID, updated); err != nil {
+			log.Err(err).Msg("Failed to save the results seen in the scan history")
+		}
+	}
+	return code
+}
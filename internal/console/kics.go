@@ -20,19 +20,26 @@ import (
 )
 
 const (
-	scanID   = "console"
 	timeMult = 2
 )
 
 var (
 	ctx = context.Background()
-
-	verbose  bool
-	logFile  bool
-	logPath  string
-	logLevel string
-	noColor  bool
-	silent   bool
+	// scanID identifies the scan in the results, unique when the scans are kept in a history
+	scanID = "console"
+
+	verbose   bool
+	logFile   bool
+	logPath   string
+	logLevel  string
+	logFormat string
+	noColor   bool
+	silent    bool
+
+	// consoleOutput is where logs are written when verbose
+	consoleOutput io.Writer = os.Stdout
+	// logCapture keeps the warnings and errors logged to attach them to the results
+	logCapture = consoleHelpers.NewLogCapture(consoleHelpers.DefaultLogCaptureLimit)
 
 	warnings = make(map[string]bool)
 
@@ -47,6 +54,15 @@ func initialize() error {
 	rootCmd.AddCommand(generateIDCmd)
 	rootCmd.AddCommand(scanCmd)
 	rootCmd.AddCommand(listPlatformsCmd)
+	rootCmd.AddCommand(generateDocsCmd)
+	rootCmd.AddCommand(lspCmd)
+	rootCmd.AddCommand(bundleCmd)
+	rootCmd.AddCommand(compareCmd)
+	rootCmd.AddCommand(explainCmd)
+	rootCmd.AddCommand(slaCmd)
+	rootCmd.AddCommand(triageCmd)
+	rootCmd.AddCommand(falsePositivesCmd)
+	rootCmd.AddCommand(baselineCmd)
 	rootCmd.PersistentFlags().BoolVarP(&logFile,
 		"log-file",
 		"l",
@@ -62,18 +78,37 @@ func initialize() error {
 		"",
 		"INFO",
 		"determines log level (TRACE,DEBUG,INFO,WARN,ERROR,FATAL)")
+	rootCmd.PersistentFlags().StringVarP(&logFormat,
+		"log-format",
+		"",
+		"console",
+		"determines log format (console,json)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose",
 		"v",
 		false,
 		"write logs to stdout too (mutually exclusive with silent)")
 	rootCmd.PersistentFlags().BoolVarP(&silent, "silent", "s", false, "silence stdout messages (mutually exclusive with verbose)")
 	rootCmd.PersistentFlags().BoolVarP(&noColor, "no-color", "", false, "disable CLI color output")
+	rootCmd.PersistentFlags().BoolVarP(&disableTelemetry,
+		"disable-telemetry",
+		"",
+		false,
+		fmt.Sprintf("disable error reporting to Sentry, also disabled with %s=true", disableTelemetryEnv))
 
 	if err := viper.BindPFlags(rootCmd.PersistentFlags()); err != nil {
 		return err
 	}
 
 	initScanCmd()
+	initGenerateDocsCmd()
+	initLSPCmd()
+	initBundleCmd()
+	initCompareCmd()
+	initExplainCmd()
+	initSLACmd()
+	initTriageCmd()
+	initFalsePositivesCmd()
+	initBaselineCmd()
 	if insertScanCmd() {
 		warnings["DEPRECATION WARNING: for future versions use 'kics scan'"] = true
 		os.Args = append([]string{os.Args[0], "scan"}, os.Args[1:]...)
@@ -102,16 +137,22 @@ func setLogLevel() {
 }
 
 func setupLogs() error {
-	consoleLogger := zerolog.ConsoleWriter{Out: io.Discard}
-	fileLogger := zerolog.ConsoleWriter{Out: io.Discard}
+	initTelemetry()
+	var consoleLogger, fileLogger io.Writer = io.Discard, io.Discard
 	setLogLevel()
 
 	if verbose && silent {
 		return errors.New("can't provide 'silent' and 'verbose' flags simultaneously")
 	}
+	if logFormat != "console" && logFormat != "json" {
+		return fmt.Errorf("unknown log format: %s, supported formats: console, json", logFormat)
+	}
 
 	if verbose {
-		consoleLogger = zerolog.ConsoleWriter{Out: os.Stdout}
+		consoleLogger = consoleOutput
+		if logFormat == "console" {
+			consoleLogger = zerolog.ConsoleWriter{Out: consoleOutput}
+		}
 	}
 
 	if noColor {
@@ -131,7 +172,10 @@ func setupLogs() error {
 		if err != nil {
 			return err
 		}
-		fileLogger = consoleHelpers.CustomConsoleWriter(&zerolog.ConsoleWriter{Out: file, NoColor: true})
+		fileLogger = file
+		if logFormat == "console" {
+			fileLogger = consoleHelpers.CustomConsoleWriter(&zerolog.ConsoleWriter{Out: file, NoColor: true})
+		}
 	}
 
 	if silent {
@@ -139,8 +183,10 @@ func setupLogs() error {
 		os.Stdout = nil
 	}
 
-	mw := io.MultiWriter(consoleLogger, fileLogger)
+	mw := zerolog.MultiLevelWriter(outputRedactor.Writer(consoleLogger), outputRedactor.Writer(fileLogger),
+		outputRedactor.LevelWriter(logCapture))
 	log.Logger = log.Output(mw)
+	logCapture.Reset()
 
 	for warn := range warnings {
 		log.Warn().Msgf("%v", warn)
@@ -166,10 +212,7 @@ func Execute() error {
 	zerolog.SetGlobalLevel(zerolog.InfoLevel)
 	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stdout})
 
-	err := sentry.Init(sentry.ClientOptions{})
-	if err != nil {
-		log.Err(err).Msg("Failed to initialize sentry")
-	}
+	initTelemetry()
 	defer sentry.Flush(timeMult * time.Second)
 
 	if err := initialize(); err != nil {
@@ -0,0 +1,73 @@
+package console
+
+import (
+	"os"
+	"strings"
+
+	"github.com/Checkmarx/kics/internal/constants"
+	"github.com/Checkmarx/kics/internal/tracker"
+	"github.com/Checkmarx/kics/pkg/engine/source"
+	"github.com/Checkmarx/kics/pkg/kics"
+	"github.com/Checkmarx/kics/pkg/lsp"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Starts a Language Server Protocol server on stdin and stdout",
+	Long: "Starts a Language Server Protocol server on stdin and stdout, the documents opened in the editor are scanned\n" +
+		"when opened, changed and saved and their results are published as diagnostics",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runLSP()
+	},
+}
+
+func initLSPCmd() {
+	lspCmd.Flags().StringVarP(&queryPath, "queries-path", "q", "./assets/queries", "path to directory with queries or address of a git repository with queries")
+	lspCmd.Flags().StringSliceVarP(&types, "type", "t", []string{""}, "case insensitive list of platform types to scan\n"+
+		"("+strings.Join(source.ListSupportedPlatforms(), ", ")+")")
+	lspCmd.Flags().StringVarP(&queryOverrides, "query-overrides", "", "",
+		"YAML file overriding the severity, description, category or enablement of queries by query ID")
+	lspCmd.Flags().StringSliceVarP(&excludeIDs, "exclude-queries", "", []string{}, "exclude queries by providing the query ID")
+	lspCmd.Flags().StringSliceVarP(&excludeCategories, "exclude-categories", "", []string{},
+		"exclude categories by providing its name")
+}
+
+// runLSP serves the editor with a service scanning single files, stdout is reserved to the protocol
+// so logs are written to stderr when verbose
+func runLSP() error {
+	// silent mode discards os.Stdout
+	out := os.Stdout
+	consoleOutput = os.Stderr
+	if err := setupLogs(); err != nil {
+		return err
+	}
+
+	t, err := tracker.NewTracker(constants.MinimumPreviewLines)
+	if err != nil {
+		return err
+	}
+	querySource := source.NewFilesystemSource(queryPath, types)
+	inspector, err := createInspector(t, querySource)
+	if err != nil {
+		log.Err(err)
+		return err
+	}
+	policyEngine, err := createPolicyEngine(inspector, t, nil)
+	if err != nil {
+		log.Err(err)
+		return err
+	}
+	combinedParser, err := createParser(querySource.Types)
+	if err != nil {
+		log.Err(err)
+		return err
+	}
+
+	service := &kics.Service{
+		Parser:    combinedParser,
+		Inspector: policyEngine,
+	}
+	return lsp.NewServer(service, nil).Run(ctx, os.Stdin, out)
+}
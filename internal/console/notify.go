@@ -0,0 +1,62 @@
+package console
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Checkmarx/kics/pkg/httpclient"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/notify"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	notificationsPath string
+	// notifier sends the results to the notification routes, nil without notifications configuration
+	notifier *notify.Notifier
+)
+
+// initNotificationFlags adds the flags of the notifications of the results
+func initNotificationFlags() {
+	scanCmd.Flags().StringVarP(
+		&notificationsPath,
+		"notifications",
+		"",
+		"",
+		"YAML file with the routes sending the results to Slack channels, webhooks or emails by severity, path and query after the scan",
+	)
+}
+
+// setNotifier loads the notification routes before the scan, so configuration errors are reported right away
+func setNotifier() error {
+	if notificationsPath == "" {
+		return nil
+	}
+	config, err := notify.LoadConfig(notificationsPath)
+	if err != nil {
+		return err
+	}
+	client, err := httpclient.New(getHTTPOptions())
+	if err != nil {
+		return err
+	}
+	basePath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	notifier = notify.NewNotifier(config, client, basePath)
+	notifier.SetRedactor(outputRedactor)
+	return nil
+}
+
+// sendNotifications sends the results to the targets of the notification routes, failures are reported
+// without failing the scan
+func sendNotifications(results []model.Vulnerability) {
+	if notifier == nil {
+		return
+	}
+	if err := notifier.Notify(ctx, scanID, results); err != nil {
+		fmt.Printf("\n%s\n", err)
+		log.Err(err).Msg("Failed to send notifications")
+	}
+}
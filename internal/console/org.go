@@ -0,0 +1,136 @@
+package console
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	consoleHelpers "github.com/Checkmarx/kics/internal/console/helpers"
+	"github.com/Checkmarx/kics/pkg/engine/source"
+	"github.com/Checkmarx/kics/pkg/httpclient"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	orgTopics    []string
+	orgLanguages []string
+	orgAPIURL    string
+)
+
+// initOrgFlags adds the flags of the scans of the repositories of an organization
+func initOrgFlags() {
+	scanCmd.Flags().StringSliceVarP(
+		&orgTopics,
+		"org-topics",
+		"",
+		[]string{},
+		"only scan the repositories of the organization with one of these topics",
+	)
+	scanCmd.Flags().StringSliceVarP(
+		&orgLanguages,
+		"org-languages",
+		"",
+		[]string{},
+		"only scan the repositories of the organization written in one of these languages (ex: HCL,Smarty)",
+	)
+	scanCmd.Flags().StringVarP(
+		&orgAPIURL,
+		"org-api-url",
+		"",
+		"",
+		"URL of the API of the git host of the organization, for self-hosted servers (ex: https://github.example.com/api/v3)",
+	)
+}
+
+// scanOrg scans each repository of the organization of the scan path with the flags of the scan, the reports of each
+// repository are written to a directory of the output path named after it, along with a multi-project report with
+// the results of all of them, exits with the highest exit code of the scans, repositories that fail to be scanned
+// are reported and fail the scan
+func scanOrg(printer *consoleHelpers.Printer) error {
+	orgAddress, orgOutputPath, orgProjectName := path, outputPath, projectName
+	defer func() {
+		path, outputPath, projectName = orgAddress, orgOutputPath, orgProjectName
+	}()
+
+	client, err := httpclient.New(getHTTPOptions())
+	if err != nil {
+		return err
+	}
+	repositories, err := source.ListOrgRepositories(ctx, client, orgAddress, source.OrgOptions{
+		Topics:    orgTopics,
+		Languages: orgLanguages,
+		APIURL:    orgAPIURL,
+		Env:       httpclient.Environ(getHTTPOptions()),
+	})
+	if err != nil {
+		log.Err(err)
+		return err
+	}
+	orgMsg := fmt.Sprintf("Scanning %d repositories of %s\n", len(repositories), strings.TrimPrefix(orgAddress, "org::"))
+	fmt.Print(orgMsg)
+	log.Info().Msg(orgMsg)
+
+	orgSummary := model.NewMultiProjectSummary(orgAddress)
+	code := 0
+	for i := range repositories {
+		repository := &repositories[i]
+		project, repositoryCode := scanRepository(repository, orgOutputPath, orgProjectName, printer)
+		orgSummary.Add(&project)
+		if repositoryCode > code {
+			code = repositoryCode
+		}
+	}
+
+	if err := printOutput(orgOutputPath, "org-results", orgSummary, []string{"json"}); err != nil {
+		return err
+	}
+	printOrgSummary(orgSummary, printer)
+	if code != 0 {
+		exit(code)
+	}
+	return nil
+}
+
+// scanRepository scans a repository of the organization, its reports are written to a directory of the output path
+// named after it and it is kept in the scan history under its name, unless a project is given
+func scanRepository(repository *source.OrgRepository, orgOutputPath, orgProjectName string,
+	printer *consoleHelpers.Printer) (model.ProjectSummary, int) {
+	fmt.Printf("\nScanning %s\n", repository.Name)
+	path = "git::" + repository.Address
+	if repository.DefaultBranch != "" {
+		path += "?ref=" + repository.DefaultBranch
+	}
+	if orgOutputPath != "" {
+		outputPath = filepath.Join(orgOutputPath, filepath.FromSlash(repository.Name))
+	}
+	if orgProjectName == "" {
+		projectName = repository.Name
+	}
+	logCapture.Reset()
+
+	summary, code, err := runScan(printer)
+	if err != nil {
+		log.Err(err).Msgf("Failed to scan %s", repository.Name)
+		return model.ProjectSummary{Name: repository.Name, Source: repository.Address, ExitCode: 1, Error: err.Error()}, 1
+	}
+	project := model.NewProjectSummary(repository.Name, repository.Address, path, &summary)
+	project.ExitCode = code
+	return project, code
+}
+
+// printOrgSummary prints the results of each repository of the organization
+func printOrgSummary(orgSummary *model.MultiProjectSummary, printer *consoleHelpers.Printer) {
+	fmt.Printf("\nResults of %d repositories:\n\n", len(orgSummary.Projects))
+	for i := range orgSummary.Projects {
+		project := &orgSummary.Projects[i]
+		if project.Error != "" {
+			printer.High.Printf("\t%s: failed to scan: %s\n", project.Name, project.Error)
+			continue
+		}
+		fmt.Printf("\t%s: %d results (HIGH: %d, MEDIUM: %d, LOW: %d, INFO: %d)\n", project.Name, project.TotalCounter,
+			project.SeverityCounters[model.SeverityHigh], project.SeverityCounters[model.SeverityMedium],
+			project.SeverityCounters[model.SeverityLow], project.SeverityCounters[model.SeverityInfo])
+	}
+	fmt.Printf("\nTotal results: %d, repositories that failed to scan: %d\n", orgSummary.TotalCounter, orgSummary.FailedProjects)
+}
@@ -0,0 +1,38 @@
+package console
+
+import (
+	"path/filepath"
+
+	"github.com/Checkmarx/kics/pkg/policy"
+)
+
+var (
+	noDirectoryPolicies bool
+	// directoryPolicies are the policies of the directories of the scanned path, nil when they are disabled
+	directoryPolicies *policy.Hierarchy
+)
+
+// initDirectoryPolicyFlags adds the flags of the directory policies, the .kics.yaml files of the scanned path
+func initDirectoryPolicyFlags() {
+	scanCmd.Flags().BoolVarP(
+		&noDirectoryPolicies,
+		"no-directory-policies",
+		"",
+		false,
+		"ignore the "+policy.FileName+" files of the directories of the scanned path, "+
+			"with the exclude paths and query severities of their directories",
+	)
+}
+
+// setDirectoryPolicies loads the directory policies before the scan, so invalid policies are reported right away
+func setDirectoryPolicies() error {
+	if noDirectoryPolicies {
+		return nil
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	directoryPolicies, err = policy.Load(absPath)
+	return err
+}
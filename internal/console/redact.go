@@ -0,0 +1,80 @@
+package console
+
+import (
+	"os"
+
+	"github.com/Checkmarx/kics/pkg/redact"
+	"github.com/Checkmarx/kics/pkg/report"
+	"github.com/gookit/color"
+)
+
+var (
+	secretsEnvDenylist  []string
+	secretsFileDenylist []string
+
+	// outputRedactor redacts the secrets of the environment of the scan from its output
+	outputRedactor *redact.Redactor
+	// restoreStdout restores the stdout redirected to redact it and waits for its output
+	restoreStdout = func() {}
+)
+
+// initRedactFlags adds the flags of the secrets of the environment of the scan never written to its output
+func initRedactFlags() {
+	scanCmd.Flags().StringSliceVarP(
+		&secretsEnvDenylist,
+		"secrets-env-denylist",
+		"",
+		redact.DefaultEnvDenylist,
+		"patterns of the names of the environment variables whose values are replaced by <redacted> in the reports, "+
+			"the logs and the console output, case insensitive\n"+
+			"can be provided multiple times or as a comma separated string, an empty string disables it",
+	)
+	scanCmd.Flags().StringSliceVarP(
+		&secretsFileDenylist,
+		"secrets-file-denylist",
+		"",
+		[]string{},
+		"patterns of the files whose content and lines are replaced by <redacted> in the reports, the logs "+
+			"and the console output (ex: /run/secrets/*)\n"+
+			"can be provided multiple times or as a comma separated string",
+	)
+}
+
+// setupRedactor reads the secrets of the environment of the scan, they are redacted from the reports and the logs
+// and, unless silent, stdout is redirected to redact them from the console output too
+func setupRedactor() error {
+	var err error
+	if outputRedactor, err = redact.New(nonEmpty(secretsEnvDenylist), secretsFileDenylist); err != nil {
+		return err
+	}
+	report.SetRedactor(outputRedactor)
+	if outputRedactor == nil || silent {
+		return nil
+	}
+	restore, err := outputRedactor.Stdout()
+	if err != nil {
+		return err
+	}
+	color.SetOutput(os.Stdout)
+	restoreStdout = func() {
+		restore()
+		color.SetOutput(os.Stdout)
+	}
+	return nil
+}
+
+// exit restores stdout, so the redacted output is written, and exits with the code
+func exit(code int) {
+	restoreStdout()
+	os.Exit(code)
+}
+
+func nonEmpty(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
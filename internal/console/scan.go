@@ -10,20 +10,34 @@ import (
 
 	consoleHelpers "github.com/Checkmarx/kics/internal/console/helpers"
 	"github.com/Checkmarx/kics/internal/constants"
-	"github.com/Checkmarx/kics/internal/storage"
 	"github.com/Checkmarx/kics/internal/tracker"
+	"github.com/Checkmarx/kics/pkg/discovery"
 	"github.com/Checkmarx/kics/pkg/engine"
+	"github.com/Checkmarx/kics/pkg/engine/decisionlog"
 	"github.com/Checkmarx/kics/pkg/engine/provider"
 	"github.com/Checkmarx/kics/pkg/engine/source"
+	"github.com/Checkmarx/kics/pkg/httpclient"
 	"github.com/Checkmarx/kics/pkg/kics"
 	"github.com/Checkmarx/kics/pkg/model"
 	"github.com/Checkmarx/kics/pkg/parser"
+	bicepParser "github.com/Checkmarx/kics/pkg/parser/bicep"
+	"github.com/Checkmarx/kics/pkg/parser/cloudformation"
 	dockerParser "github.com/Checkmarx/kics/pkg/parser/docker"
+	gdmParser "github.com/Checkmarx/kics/pkg/parser/gdm"
 	jsonParser "github.com/Checkmarx/kics/pkg/parser/json"
+	puppetParser "github.com/Checkmarx/kics/pkg/parser/puppet"
+	saltParser "github.com/Checkmarx/kics/pkg/parser/salt"
 	terraformParser "github.com/Checkmarx/kics/pkg/parser/terraform"
+	tfstateParser "github.com/Checkmarx/kics/pkg/parser/terraform/state"
 	yamlParser "github.com/Checkmarx/kics/pkg/parser/yaml"
+	"github.com/Checkmarx/kics/pkg/report"
 	"github.com/Checkmarx/kics/pkg/resolver"
+	"github.com/Checkmarx/kics/pkg/resolver/arm"
+	"github.com/Checkmarx/kics/pkg/resolver/download"
 	"github.com/Checkmarx/kics/pkg/resolver/helm"
+	"github.com/Checkmarx/kics/pkg/resolver/kustomize"
+	tfResolver "github.com/Checkmarx/kics/pkg/resolver/terraform"
+	"github.com/Checkmarx/kics/pkg/resolver/terragrunt"
 	"github.com/getsentry/sentry-go"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -32,21 +46,56 @@ import (
 )
 
 var (
-	path              string
-	queryPath         string
-	outputPath        string
-	payloadPath       string
-	excludeCategories []string
-	excludePath       []string
-	excludeIDs        []string
-	excludeResults    []string
-	reportFormats     []string
-	cfgFile           string
-
-	noProgress   bool
-	types        []string
-	min          bool
-	previewLines int
+	path                 string
+	queryPath            string
+	celPoliciesPath      string
+	regexQueriesPath     string
+	failFastSeverity     string
+	queryOverrides       string
+	customCategories     string
+	scanTimeout          time.Duration
+	outputPath           string
+	payloadPath          string
+	excludeCategories    []string
+	excludePath          []string
+	excludeIDs           []string
+	excludeResults       []string
+	reportFormats        []string
+	csvColumns           []string
+	summaryBreakdown     []string
+	decisionLog          string
+	ciAnnotations        string
+	tfVarFiles           []string
+	tfWorkspace          string
+	cfgFile              string
+	cfnParameters        string
+	downloadCacheDir     string
+	proxy                string
+	caBundle             string
+	tlsMinVersion        string
+	helmPostRenderer     string
+	helmPostRendererArgs []string
+	helmValues           []string
+	registryUsername     string
+	registryPassword     string
+
+	noProgress           bool
+	types                []string
+	min                  bool
+	previewLines         int
+	parallel             int
+	batchSize            int
+	aggregateThreshold   int
+	aggregateSamples     int
+	cfnParameterDefaults bool
+	cfnMaskNoEcho        bool
+	tfState              bool
+	tfModules            bool
+	offline              bool
+	dryRun               bool
+	registryPlainHTTP    bool
+	baseImageMetadata    bool
+	strict               bool
 	//go:embed img/kics-console
 	banner string
 )
@@ -64,6 +113,17 @@ var scanCmd = &cobra.Command{
 
 func initializeConfig(cmd *cobra.Command) error {
 	log.Debug().Msg("console.initializeConfig()")
+	b, err := loadBundle()
+	if err != nil {
+		return err
+	}
+	if b != nil {
+		// the bundle takes precedence over the configuration
+		defer useBundle(cmd, b)
+		if cfgFile == "" {
+			cfgFile = b.ConfigFile
+		}
+	}
 	if cfgFile == "" {
 		configpath := path
 		info, err := os.Stat(path)
@@ -150,14 +210,23 @@ func setBoundFlags(flagName string, val interface{}, cmd *cobra.Command) {
 }
 
 func initScanCmd() {
-	scanCmd.Flags().StringVarP(&path, "path", "p", "", "path or directory path to scan")
+	scanCmd.Flags().StringVarP(
+		&path,
+		"path",
+		"p",
+		"",
+		"path or directory path to scan, archive (.zip, .tar, .tar.gz), reference of a Helm chart in an OCI registry "+
+			"address of a git repository (ex: git::https://dev.azure.com/org/project/_git/repository?ref=main) "+
+			"or organization of GitHub or group of GitLab to scan each of its repositories (ex: org::https://github.com/my-org)",
+	)
 	scanCmd.Flags().StringVarP(&cfgFile, "config", "", "", "path to configuration file")
 	scanCmd.Flags().StringVarP(
 		&queryPath,
 		"queries-path",
 		"q",
 		"./assets/queries",
-		"path to directory with queries",
+		"path to directory with queries or address of a git repository with queries "+
+			"(ex: git::https://example.com/policies.git//assets/queries?ref=v1.0.0)",
 	)
 	scanCmd.Flags().StringVarP(&outputPath, "output-path", "o", "", "directory path to store reports")
 	scanCmd.Flags().StringSliceVarP(
@@ -165,7 +234,7 @@ func initScanCmd() {
 		"report-formats",
 		"",
 		[]string{},
-		"formats in which the results will be exported (json, sarif, html)",
+		"formats in which the results will be exported (json, sarif, html, csv, codeclimate, policyreport)",
 	)
 	scanCmd.Flags().IntVarP(&previewLines, "preview-lines", "", 3, "number of lines to be display in CLI results (min: 1, max: 30)")
 	scanCmd.Flags().StringVarP(&payloadPath, "payload-path", "d", "", "path to store internal representation JSON file")
@@ -208,6 +277,23 @@ func initScanCmd() {
 			"can be provided multiple times or as a comma separated string\n"+
 			"example: 'Access control,Best practices'",
 	)
+	initPolicyFlags()
+	initResultsFlags()
+	initParserFlags()
+	initHelmFlags()
+	initDownloadFlags()
+	initAttestationFlags()
+	initWatchdogFlags()
+	initHistoryFlags()
+	initGateFlags()
+	initNotificationFlags()
+	initDirectoryPolicyFlags()
+	initDeploymentContextFlags()
+	initEnvironmentFlags()
+	initGitFlags()
+	initOrgFlags()
+	initBaselineFlags()
+	initRedactFlags()
 
 	if err := scanCmd.MarkFlagRequired("path"); err != nil {
 		sentry.CaptureException(err)
@@ -215,7 +301,282 @@ func initScanCmd() {
 	}
 }
 
-func getFileSystemSourceProvider() (*provider.FileSystemSourceProvider, error) {
+// initPolicyFlags adds the flags with the policies evaluated alongside the rego queries
+func initPolicyFlags() {
+	scanCmd.Flags().StringVarP(
+		&celPoliciesPath,
+		"cel-policies",
+		"",
+		"",
+		"path to a file or directory with CEL policies evaluated alongside the queries",
+	)
+	scanCmd.Flags().StringVarP(
+		&regexQueriesPath,
+		"regex-queries",
+		"",
+		"",
+		"path to a file or directory with regex queries matched against the raw content of files",
+	)
+	scanCmd.Flags().StringVarP(
+		&queryOverrides,
+		"query-overrides",
+		"",
+		"",
+		"YAML file overriding the severity, description, category or enablement of queries by query ID",
+	)
+	scanCmd.Flags().StringVarP(
+		&customCategories,
+		"custom-categories",
+		"",
+		"",
+		"YAML file with custom categories of queries, reported in addition to the built-in categories",
+	)
+	scanCmd.Flags().StringVarP(
+		&failFastSeverity,
+		"fail-fast",
+		"",
+		"",
+		"stop the scan at the first result with this severity or above and exit with code 1 (high, medium, low, info)",
+	)
+	scanCmd.Flags().DurationVarP(
+		&scanTimeout,
+		"scan-timeout",
+		"",
+		0,
+		"time limit of the scan (ex: 10m), once exceeded the current query finishes, the remaining ones are skipped "+
+			"and the results are reported as incomplete",
+	)
+	scanCmd.Flags().BoolVarP(
+		&strict,
+		"strict",
+		"",
+		false,
+		fmt.Sprintf("exit with code %d when files fail to parse or render, remote modules can't be downloaded "+
+			"or queries are skipped", constants.StrictExitCode),
+	)
+	scanCmd.Flags().BoolVarP(
+		&dryRun,
+		"dry-run",
+		"",
+		false,
+		"list the queries that would run against each file, according to its platform, without evaluating them",
+	)
+}
+
+// initResultsFlags adds the flags that change how results are summarized and audited
+func initResultsFlags() {
+	scanCmd.Flags().IntVarP(
+		&aggregateThreshold,
+		"aggregate-results",
+		"",
+		0,
+		"collapse the results of a query with the same issue into a single result when there are more than this number of them\n"+
+			"the aggregated result shows the number of occurrences and some of their locations (0 disables aggregation)",
+	)
+	scanCmd.Flags().IntVarP(
+		&aggregateSamples,
+		"aggregate-samples",
+		"",
+		5,
+		"number of locations kept in aggregated results",
+	)
+	scanCmd.Flags().StringSliceVarP(
+		&csvColumns,
+		"csv-columns",
+		"",
+		[]string{},
+		fmt.Sprintf("columns of the CSV report (%s), defaults to %s",
+			strings.Join(report.CSVColumns(), ", "), strings.Join(report.DefaultCSVColumns, ",")),
+	)
+	scanCmd.Flags().StringSliceVarP(
+		&summaryBreakdown,
+		"summary-breakdown",
+		"",
+		[]string{},
+		"break down the results summary by platform, top-level directory, category and/or project discovered in the scanned path, "+
+			"such as Terraform root modules and Helm charts (platform, directory, category, project)",
+	)
+	scanCmd.Flags().IntVarP(
+		&parallel,
+		"parallel",
+		"",
+		0,
+		"number of queries evaluated in parallel (0 uses the number of CPUs)",
+	)
+	scanCmd.Flags().IntVarP(
+		&batchSize,
+		"batch-size",
+		"",
+		0,
+		"minimum number of files inspected together while the next ones are parsed, limiting the memory of big scans, "+
+			"batches are cut between directories (0 inspects all the files together)",
+	)
+	scanCmd.Flags().StringVarP(
+		&decisionLog,
+		"decision-log",
+		"",
+		"",
+		"file path or HTTP(S) URL where OPA-style decision logs of each query evaluation are written",
+	)
+	scanCmd.Flags().StringVarP(
+		&ciAnnotations,
+		"ci-annotations",
+		"",
+		"",
+		"writes the results to stdout as annotations of the CI system running the scan (github, azure)",
+	)
+}
+
+// initParserFlags adds the flags used to resolve values in CloudFormation and Terraform files
+func initParserFlags() {
+	scanCmd.Flags().StringVarP(
+		&cfnParameters,
+		"cfn-parameters",
+		"",
+		"",
+		"path to a CloudFormation parameters JSON file used to resolve references to template parameters",
+	)
+	scanCmd.Flags().BoolVarP(
+		&cfnParameterDefaults,
+		"cfn-parameter-defaults",
+		"",
+		false,
+		"resolve references to CloudFormation template parameters using their default values",
+	)
+	scanCmd.Flags().BoolVarP(&cfnMaskNoEcho, "cfn-mask-noecho", "", false, "mask the values of CloudFormation NoEcho parameters")
+	scanCmd.Flags().StringSliceVarP(
+		&tfVarFiles,
+		"terraform-var-files",
+		"",
+		[]string{},
+		"Terraform variables files with the highest precedence, later files override earlier ones\n"+
+			"can be provided multiple times or as a comma separated string",
+	)
+	scanCmd.Flags().StringVarP(
+		&tfWorkspace,
+		"terraform-workspace",
+		"",
+		"",
+		"Terraform workspace used for 'terraform.workspace' and to load '<workspace>.tfvars' of each module",
+	)
+	scanCmd.Flags().BoolVarP(
+		&tfState,
+		"terraform-state",
+		"",
+		false,
+		"scan Terraform state files (.tfstate), sensitive attributes are masked",
+	)
+	scanCmd.Flags().BoolVarP(
+		&tfModules,
+		"terraform-modules",
+		"",
+		false,
+		"scan the modules called by Terraform configurations from registries, git repositories, archives\n"+
+			"and local paths outside the scan path, and the remote modules of Terragrunt configurations,\n"+
+			"results are reported on the module calls",
+	)
+}
+
+// initHelmFlags adds the flags used to render Helm charts and to pull them from OCI registries
+func initHelmFlags() {
+	scanCmd.Flags().StringVarP(
+		&helmPostRenderer,
+		"helm-post-renderer",
+		"",
+		"",
+		"path or name of an executable that modifies the rendered Helm manifests before scanning them (ex: kustomize patches)",
+	)
+	scanCmd.Flags().StringSliceVarP(
+		&helmPostRendererArgs,
+		"helm-post-renderer-args",
+		"",
+		[]string{},
+		"arguments passed to the Helm post-renderer\n"+
+			"can be provided multiple times or as a comma separated string",
+	)
+	scanCmd.Flags().StringSliceVarP(
+		&helmValues,
+		"helm-values",
+		"",
+		[]string{},
+		"values files merged over the values of the scanned Helm charts, later files override earlier ones\n"+
+			"can be provided multiple times or as a comma separated string",
+	)
+	scanCmd.Flags().StringVarP(
+		&registryUsername,
+		"helm-registry-username",
+		"",
+		"",
+		"username used to pull Helm charts from OCI registries (oci://registry/chart:tag scan paths)",
+	)
+	scanCmd.Flags().StringVarP(
+		&registryPassword,
+		"helm-registry-password",
+		"",
+		"",
+		"password or token used to pull Helm charts from OCI registries",
+	)
+	scanCmd.Flags().BoolVarP(
+		&registryPlainHTTP,
+		"helm-registry-plain-http",
+		"",
+		false,
+		"use HTTP instead of HTTPS to pull Helm charts from OCI registries",
+	)
+}
+
+// initDownloadFlags adds the flags used to download remote Terraform modules and Helm chart dependencies
+func initDownloadFlags() {
+	scanCmd.Flags().StringVarP(
+		&downloadCacheDir,
+		"download-cache-dir",
+		"",
+		download.DefaultCacheDir(),
+		"directory shared between scans to cache remote Terraform modules and Helm chart dependencies",
+	)
+	scanCmd.Flags().StringVarP(
+		&proxy,
+		"proxy",
+		"",
+		"",
+		"proxy URL of all outbound calls, defaults to HTTPS_PROXY/HTTP_PROXY environment variables, NO_PROXY is honored",
+	)
+	scanCmd.Flags().StringVarP(
+		&caBundle,
+		"ca-bundle",
+		"",
+		"",
+		"PEM file with additional CA certificates trusted by all outbound calls",
+	)
+	scanCmd.Flags().StringVarP(
+		&tlsMinVersion,
+		"tls-min-version",
+		"",
+		"1.2",
+		"minimum TLS version of all outbound calls (1.0, 1.1, 1.2, 1.3)",
+	)
+	scanCmd.Flags().BoolVarP(
+		&offline,
+		"offline",
+		"",
+		false,
+		"do not download remote modules, only cached modules are used and the others are reported as skipped",
+	)
+	scanCmd.Flags().BoolVarP(
+		&baseImageMetadata,
+		"base-image-metadata",
+		"",
+		false,
+		"look up the digest, creation date and architecture of the base images of Dockerfiles in their registries",
+	)
+}
+
+// getSourceProvider returns the provider of the files of the scan path, archives (.zip, .tar, .tar.gz) are provided
+// without extracting them and their excluded paths are patterns of the paths inside them
+func getSourceProvider() (provider.SourceProvider, error) {
+	if provider.IsArchive(path) {
+		return provider.NewArchiveSourceProvider(path, excludePath)
+	}
 	var excludePaths []string
 	if payloadPath != "" {
 		excludePaths = append(excludePaths, payloadPath)
@@ -245,131 +606,659 @@ func getExcludeResultsMap(excludeResults []string) map[string]bool {
 	return excludeResultsMap
 }
 
-func createInspector(t engine.Tracker, querySource source.QueriesSource) (*engine.Inspector, error) {
+// getCloudFormationParameters returns the parameters to resolve in CloudFormation templates
+// or nil if parameters should not be resolved
+func getCloudFormationParameters() (*cloudformation.Parameters, error) {
+	if cfnParameters == "" && !cfnParameterDefaults {
+		return nil, nil
+	}
+	values := make(map[string]string)
+	if cfnParameters != "" {
+		var err error
+		if values, err = cloudformation.LoadParameters(cfnParameters); err != nil {
+			return nil, err
+		}
+	}
+	return &cloudformation.Parameters{
+		Values:     values,
+		MaskNoEcho: cfnMaskNoEcho,
+	}, nil
+}
+
+// getHTTPOptions returns the options of the HTTP clients of all outbound calls
+func getHTTPOptions() httpclient.Options {
+	return httpclient.Options{Proxy: proxy, CABundle: caBundle, TLSMinVersion: tlsMinVersion}
+}
+
+// getDownloader returns the downloader of remote Terraform modules and Helm chart dependencies,
+// when the scan path references a chart in an OCI registry the chart is pulled and the scan path set to its archive
+// and when it is a git repository address the repository is checked out and the scan path set to its checkout
+func getDownloader() (*download.Downloader, error) {
+	httpOptions := getHTTPOptions()
+	downloader, err := download.NewDownloader(download.Options{
+		CacheDir:      downloadCacheDir,
+		Proxy:         httpOptions.Proxy,
+		CABundle:      httpOptions.CABundle,
+		TLSMinVersion: httpOptions.TLSMinVersion,
+		Offline:       offline,
+		Registry: download.RegistryOptions{
+			Username:  registryUsername,
+			Password:  registryPassword,
+			PlainHTTP: registryPlainHTTP,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := fetchGitPath(); err != nil {
+		return nil, err
+	}
+	if !download.IsOCIReference(path) {
+		return downloader, nil
+	}
+	chartPath, err := downloader.PullChart(path)
+	if err != nil {
+		return nil, err
+	}
+	log.Info().Msgf("Pulled %s to %s", path, chartPath)
+	path = chartPath
+	return downloader, nil
+}
+
+func printVersion() {
+	versionMsg := fmt.Sprintf("\nScanning with %s\n\n", getVersion())
+	fmt.Println(versionMsg)
+	log.Info().Msgf(strings.ReplaceAll(versionMsg, "\n", ""))
+}
+
+func createInspector(t engine.Tracker, querySource *source.FilesystemSource) (*engine.Inspector, error) {
+	if err := fetchGitQueries(querySource); err != nil {
+		return nil, err
+	}
+	overrides, err := source.LoadOverrides(queryOverrides)
+	if err != nil {
+		return nil, err
+	}
+	querySource.Overrides = overrides
+	if err := source.LoadCategories(customCategories); err != nil {
+		return nil, err
+	}
+	if err := querySource.CheckCompatibility(constants.Version); err != nil {
+		return nil, err
+	}
+
 	excludeResultsMap := getExcludeResultsMap(excludeResults)
 
-	excludeQueries := source.ExcludeQueries{
+	inspector, err := engine.NewInspector(ctx, querySource, engine.DefaultVulnerabilityBuilder, t, getExcludeQueries(), excludeResultsMap)
+	if err != nil {
+		return nil, err
+	}
+	return inspector, nil
+}
+
+// fetchGitQueries checks out the queries of the repository when the queries path is a git repository address
+// and sets the source to their local copy, cached with the downloaded modules
+func fetchGitQueries(querySource *source.FilesystemSource) error {
+	if !source.IsGitSource(queryPath) {
+		return nil
+	}
+	queriesPath, err := source.FetchGitSource(queryPath, getGitOptions())
+	if err != nil {
+		return err
+	}
+	querySource.Source = queriesPath
+	return nil
+}
+
+func getExcludeQueries() source.ExcludeQueries {
+	return source.ExcludeQueries{
 		ByIDs:        excludeIDs,
 		ByCategories: excludeCategories,
 	}
+}
 
-	inspector, err := engine.NewInspector(ctx, querySource, engine.DefaultVulnerabilityBuilder, t, excludeQueries, excludeResultsMap)
-	if err != nil {
+// createPolicyEngine returns the inspector combined with the CEL and regex inspectors when their policies are given
+func createPolicyEngine(
+	inspector *engine.Inspector,
+	t engine.Tracker,
+	filesSource provider.SourceProvider) (engine.PolicyEngine, error) {
+	policyEngines := engine.PolicyEngines{
+		inspector,
+		engine.NewParseQualityInspector(
+			engine.DefaultVulnerabilityBuilder,
+			t,
+			getExcludeQueries(),
+			getExcludeResultsMap(excludeResults),
+		),
+	}
+	if celPoliciesPath != "" {
+		celInspector, err := engine.NewCELInspector(
+			celPoliciesPath,
+			engine.DefaultVulnerabilityBuilder,
+			t,
+			getExcludeResultsMap(excludeResults),
+		)
+		if err != nil {
+			return nil, err
+		}
+		policyEngines = append(policyEngines, celInspector)
+	}
+	if regexQueriesPath != "" {
+		regexInspector, err := engine.NewRegexInspector(
+			regexQueriesPath,
+			filesSource,
+			engine.DefaultVulnerabilityBuilder,
+			t,
+			getExcludeQueries(),
+			getExcludeResultsMap(excludeResults),
+		)
+		if err != nil {
+			return nil, err
+		}
+		policyEngines = append(policyEngines, regexInspector)
+	}
+	if scanTimeout > 0 {
+		policyEngines.SetDeadline(time.Now().Add(scanTimeout))
+	}
+	if err := setFailFast(policyEngines); err != nil {
 		return nil, err
 	}
-	return inspector, nil
+	return wrapDryRun(policyEngines), nil
+}
+
+// wrapDryRun returns a policy engine that only plans the queries of policyEngine when running a dry run
+func wrapDryRun(policyEngine engine.PolicyEngine) engine.PolicyEngine {
+	if dryRun {
+		return engine.NewDryRun(policyEngine)
+	}
+	return policyEngine
+}
+
+// setFailFast sets the fail fast severity of the policy engine when one is given
+func setFailFast(policyEngine engine.FailFastEngine) error {
+	if failFastSeverity == "" {
+		return nil
+	}
+	severity := model.Severity(strings.ToUpper(failFastSeverity))
+	for _, si := range model.AllSeverities {
+		if si == severity {
+			policyEngine.SetFailFast(severity)
+			return nil
+		}
+	}
+	return fmt.Errorf("fail fast severity not supported: %s, supported values: high, medium, low, info", failFastSeverity)
+}
+
+// failedFast returns true if the policy engine stopped at a result with the fail fast severity or above
+func failedFast(policyEngine engine.PolicyEngine) bool {
+	f, ok := policyEngine.(engine.FailFastEngine)
+	return ok && f.FailedFast()
 }
 
 func createService(inspector *engine.Inspector,
-	t kics.Tracker,
+	t *tracker.CITracker,
 	store kics.Storage,
-	querySource source.FilesystemSource) (*kics.Service, error) {
-	filesSource, err := getFileSystemSourceProvider()
+	querySource source.FilesystemSource,
+	downloader *download.Downloader) (*kics.Service, error) {
+	if err := checkFailOnNew(store); err != nil {
+		return nil, err
+	}
+
+	if err := checkFailOn(); err != nil {
+		return nil, err
+	}
+
+	if err := setNotifier(); err != nil {
+		return nil, err
+	}
+
+	if err := setDirectoryPolicies(); err != nil {
+		return nil, err
+	}
+
+	if err := setDeploymentContext(inspector); err != nil {
+		return nil, err
+	}
+
+	if err := setEnvironments(); err != nil {
+		return nil, err
+	}
+
+	filesSource, err := getSourceProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	policyEngine, err := createPolicyEngine(inspector, t, filesSource)
 	if err != nil {
 		return nil, err
 	}
 
-	combinedParser, err := parser.NewBuilder().
-		Add(&jsonParser.Parser{}).
-		Add(&yamlParser.Parser{}).
-		Add(terraformParser.NewDefault()).
-		Add(&dockerParser.Parser{}).
-		Build(querySource.Types)
+	combinedParser, err := createParser(querySource.Types)
+	if err != nil {
+		return nil, err
+	}
+
+	helmResolver, err := getHelmResolver(downloader)
 	if err != nil {
 		return nil, err
 	}
 
 	// combinedResolver to be used to resolve files and templates
-	combinedResolver, err := resolver.NewBuilder().
-		Add(&helm.Resolver{}).
-		Build()
+	resolverBuilder := resolver.NewBuilder().
+		Add(helmResolver).
+		Add(&arm.Resolver{}).
+		Add(&kustomize.Resolver{})
+	// the modules of the Terragrunt configurations are resolved, remote modules only with the Terraform modules
+	terragruntResolver := &terragrunt.Resolver{}
+	if tfModules {
+		modulesResolver, err := getTerraformResolver(downloader)
+		if err != nil {
+			return nil, err
+		}
+		resolverBuilder.Add(modulesResolver)
+		terragruntResolver.Modules = modulesResolver
+	}
+	resolverBuilder.Add(terragruntResolver)
+	combinedResolver, err := resolverBuilder.Build()
 	if err != nil {
 		return nil, err
 	}
 
-	return &kics.Service{
-		SourceProvider: filesSource,
-		Storage:        store,
-		Parser:         combinedParser,
-		Inspector:      inspector,
-		Tracker:        t,
-		Resolver:       combinedResolver,
-	}, nil
+	var enrichers []kics.Enricher
+	if baseImageMetadata {
+		enrichers = append(enrichers, dockerParser.NewBaseImageEnricher(downloader))
+	}
+
+	w := getWatchdog()
+	if inspector != nil {
+		inspector.SetWatchdog(w)
+	}
+
+	return kics.NewService(
+		kics.WithSourceProvider(filesSource),
+		kics.WithStorage(store),
+		kics.WithParser(combinedParser),
+		kics.WithPolicyEngine(policyEngine),
+		kics.WithParallelism(parallel),
+		kics.WithTracker(t),
+		kics.WithResolver(combinedResolver),
+		kics.WithEnrichers(enrichers...),
+		kics.WithWatchdog(w),
+		kics.WithProjectID(getProjectID()),
+		kics.WithBatchSize(batchSize),
+		kics.WithRedactContent(historyRedactContent),
+	)
+}
+
+// createParser returns the parser of the files of the platforms types, the built-in parsers are registered before
+// the parsers of parser.DefaultRegistry, registered by embedders, which replace the built-in parsers with the same name
+// and take over their extensions
+func createParser(types []string) (*parser.Parser, error) {
+	cfnParams, err := getCloudFormationParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	type builtinParser struct {
+		name   string
+		parser parser.KindParser
+	}
+	builtins := []builtinParser{
+		{name: "json", parser: &jsonParser.Parser{CloudFormationParameters: cfnParams}},
+		{name: "yaml", parser: &yamlParser.Parser{CloudFormationParameters: cfnParams}},
+		{name: "terraform", parser: terraformParser.NewDefaultWithVariables(tfVarFiles, tfWorkspace)},
+		{name: "dockerfile", parser: &dockerParser.Parser{}},
+		{name: "puppet", parser: &puppetParser.Parser{}},
+		{name: "salt", parser: &saltParser.Parser{}},
+		{name: "gdm", parser: &gdmParser.Parser{}},
+		{name: "bicep", parser: &bicepParser.Parser{}},
+	}
+	if tfState {
+		builtins = append(builtins, builtinParser{name: "terraform-state", parser: &tfstateParser.Parser{}})
+	}
+
+	registry := parser.NewRegistry()
+	for _, builtin := range builtins {
+		kindParser := builtin.parser
+		if err := registry.Register(builtin.name, func() (parser.KindParser, error) { return kindParser, nil }); err != nil {
+			return nil, err
+		}
+	}
+	registry.Include(parser.DefaultRegistry)
+
+	return registry.Build(types)
+}
+
+// getHelmResolver returns the helm resolver, with the post-renderer set by the flags if any
+func getHelmResolver(downloader *download.Downloader) (*helm.Resolver, error) {
+	helmResolver := &helm.Resolver{Downloader: downloader, ValueFiles: helmValues}
+	if helmPostRenderer == "" {
+		return helmResolver, nil
+	}
+	postRenderer, err := helm.NewPostRenderer(helmPostRenderer, helmPostRendererArgs)
+	if err != nil {
+		return nil, err
+	}
+	helmResolver.PostRenderer = postRenderer
+	return helmResolver, nil
+}
+
+// getTerraformResolver returns the resolver of the modules called by Terraform configurations, the local modules
+// inside the scan path are not resolved since they are scanned directly
+func getTerraformResolver(downloader *download.Downloader) (*tfResolver.Resolver, error) {
+	basePath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	return &tfResolver.Resolver{Downloader: downloader, Git: getGitOptions(), BasePath: basePath}, nil
 }
 
 func scan() error {
 	log.Debug().Msg("console.scan()")
 
+	if err := setupRedactor(); err != nil {
+		return err
+	}
+	defer restoreStdout()
+
 	if errlog := setupLogs(); errlog != nil {
 		return errlog
 	}
+	log.Debug().Msgf("%d secrets of the environment redacted from the output", outputRedactor.Secrets())
 
 	printer := consoleHelpers.NewPrinter(min)
 	printer.Success.Printf("\n%s\n", banner)
 
-	versionMsg := fmt.Sprintf("\nScanning with %s\n\n", getVersion())
-	fmt.Println(versionMsg)
-	log.Info().Msgf(strings.ReplaceAll(versionMsg, "\n", ""))
+	printVersion()
+
+	if source.IsOrgSource(path) {
+		return scanOrg(printer)
+	}
+
+	_, code, err := runScan(printer)
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		exit(code)
+	}
+
+	return nil
+}
 
+// runScan scans the path and exports its results, returning the summary and the exit code of the scan
+func runScan(printer *consoleHelpers.Printer) (model.Summary, int, error) {
 	scanStartTime := time.Now()
 
 	t, err := tracker.NewTracker(previewLines)
 	if err != nil {
 		log.Err(err)
-		return err
+		return model.Summary{}, 0, err
 	}
 
 	querySource := source.NewFilesystemSource(queryPath, types)
-	store := storage.NewMemoryStorage()
+	store := getStorage()
 
 	inspector, err := createInspector(t, querySource)
 	if err != nil {
 		log.Err(err)
+		return model.Summary{}, 0, err
 	}
 
-	service, err := createService(inspector, t, store, *querySource)
+	downloader, err := getDownloader()
 	if err != nil {
 		log.Err(err)
+		return model.Summary{}, 0, err
 	}
 
-	if scanErr := service.StartScan(ctx, scanID, noProgress); scanErr != nil {
-		log.Err(scanErr)
-		return scanErr
+	breakdown, err := getSeverityBreakdown()
+	if err != nil {
+		log.Err(err)
+		return model.Summary{}, 0, err
 	}
 
-	results, err := store.GetVulnerabilities(ctx, scanID)
+	closeDecisionLog, err := setDecisionLogger(inspector)
 	if err != nil {
 		log.Err(err)
-		return err
+		return model.Summary{}, 0, err
 	}
 
-	files, err := store.GetFiles(ctx, scanID)
+	service, err := createService(inspector, t, store, *querySource, downloader)
 	if err != nil {
 		log.Err(err)
-		return err
+		return model.Summary{}, 0, err
 	}
 
-	elapsed := time.Since(scanStartTime)
+	scanErr := startScan(service)
+	closeDecisionLog()
+	if scanErr != nil {
+		log.Err(scanErr)
+		return model.Summary{}, 0, scanErr
+	}
 
-	summary := getSummary(t, results)
+	elapsed := time.Since(scanStartTime)
 
-	if err := resolveOutputs(&summary, files.Combine(), inspector.GetFailedQueries(), printer); err != nil {
+	summary, err := processResults(store, t, service, printer, breakdown, scanStartTime)
+	if err != nil {
 		log.Err(err)
-		return err
+		return model.Summary{}, 0, err
 	}
 
+	printSkippedModules(downloader.Skipped())
+
 	elapsedStrFormat := "Scan duration: %v\n"
 	fmt.Printf(elapsedStrFormat, elapsed)
 	log.Info().Msgf(elapsedStrFormat, elapsed)
 
-	if summary.FailedToExecuteQueries > 0 {
-		os.Exit(1)
+	return summary, finishScan(&summary, service, downloader.Skipped()), nil
+}
+
+// exitCode returns the exit code of the scan, in strict mode scans with files that failed to parse or render
+// or remote modules not downloaded fail with their own exit code, since not everything was analyzed,
+// scans with new results since the last successful scan of the project fail when fail on new is set
+// and scans with results with the fail on severity of their environment or above
+func exitCode(summary *model.Summary, policyEngine engine.PolicyEngine, skipped []download.SkippedModule) int {
+	if strict {
+		failures := len(summary.ScanQuality.UnparsedFiles) + len(summary.ScanQuality.UnrenderedFiles) + len(skipped) +
+			len(summary.ScanQuality.SkippedQueries)
+		if failures > 0 {
+			strictMsg := fmt.Sprintf("Strict mode: %d files, modules or queries could not be analyzed\n", failures)
+			fmt.Print(strictMsg)
+			log.Error().Msg(strictMsg)
+			return constants.StrictExitCode
+		}
+	}
+	if summary.FailedToExecuteQueries > 0 || failedFast(policyEngine) || failedOnNew(summary.SinceLastScan) ||
+		failedOnSeverity(summary) > 0 {
+		return 1
 	}
+	return 0
+}
 
-	return nil
+// processResults summarizes the results of the scan and exports them to the reports and the console
+func processResults(
+	store resultsStorage,
+	t *tracker.CITracker,
+	service *kics.Service,
+	printer *consoleHelpers.Printer,
+	breakdown model.SeverityBreakdown,
+	scanStartTime time.Time,
+) (model.Summary, error) {
+	policyEngine := service.Inspector
+	if dryRunEngine, ok := policyEngine.(*engine.DryRun); ok {
+		printDryRun(dryRunEngine.GetPlans())
+		return model.Summary{}, nil
+	}
+
+	results, err := store.GetVulnerabilities(ctx, scanID)
+	if err != nil {
+		return model.Summary{}, err
+	}
+	results = directoryPolicies.Apply(results)
+	results, suppressed := model.SplitSuppressed(results)
+	classifyEnvironments(results)
+	if err := setSeen(store, results); err != nil {
+		return model.Summary{}, err
+	}
+	if err := setTriage(store, results); err != nil {
+		return model.Summary{}, err
+	}
+
+	files, err := store.GetFiles(ctx, scanID)
+	if err != nil {
+		return model.Summary{}, err
+	}
+
+	results, baselineComparison, err := applyBaseline(results)
+	if err != nil {
+		return model.Summary{}, err
+	}
+
+	summary := getSummary(t, results, breakdown)
+	summary.Suppressed = model.NewSuppressedQueries(suppressed)
+	summary.Baseline = baselineComparison
+	summary.ScanQuality = getScanQuality(t, service.SourceProvider)
+	setIncomplete(&summary, policyEngine)
+	if summary.SinceLastScan, err = compareWithLastScan(store, results); err != nil {
+		return model.Summary{}, err
+	}
+
+	if err := resolveOutputs(&summary, files.Combine(), policyEngine.GetFailedQueries(), printer); err != nil {
+		return model.Summary{}, err
+	}
+
+	if err := writeAttestation(&summary, scanStartTime); err != nil {
+		return model.Summary{}, err
+	}
+
+	if failedFast(policyEngine) {
+		failFastMsg := fmt.Sprintf("Scan stopped at the first result with severity %s or above, results are partial\n",
+			strings.ToUpper(failFastSeverity))
+		printer.High.Printf("\n%s", failFastMsg)
+		log.Info().Msg(failFastMsg)
+	}
+	if skippedQueries := len(summary.ScanQuality.SkippedQueries); skippedQueries > 0 {
+		deadlineMsg := fmt.Sprintf("Scan timeout of %s exceeded, %d queries skipped, results are partial\n", scanTimeout, skippedQueries)
+		printer.High.Printf("\n%s", deadlineMsg)
+		log.Warn().Msg(deadlineMsg)
+	}
+	printScanComparison(summary.SinceLastScan, printer)
+	printBaselineComparison(summary.Baseline)
+	printGateResult(&summary, printer)
+	sendNotifications(results)
+	return summary, nil
+}
+
+// setIncomplete flags the summary as incomplete when the policy engine stopped at a fail fast result
+// or skipped queries after the scan deadline, the queries not evaluated did not fail
+func setIncomplete(summary *model.Summary, policyEngine engine.PolicyEngine) {
+	if d, ok := policyEngine.(engine.DeadlineEngine); ok {
+		summary.ScanQuality.SkippedQueries = d.GetSkippedQueries()
+	}
+	if failedFast(policyEngine) || len(summary.ScanQuality.SkippedQueries) > 0 {
+		summary.Incomplete = true
+		summary.FailedToExecuteQueries = len(policyEngine.GetFailedQueries())
+	}
 }
 
-func getSummary(t *tracker.CITracker, results []model.Vulnerability) model.Summary {
+// setDecisionLogger sets the decision logger of the inspector when a decision log target is given,
+// the returned function flushes and closes it
+func setDecisionLogger(inspector *engine.Inspector) (func(), error) {
+	if decisionLog == "" || inspector == nil {
+		return func() {}, nil
+	}
+	client, err := httpclient.New(getHTTPOptions())
+	if err != nil {
+		return nil, err
+	}
+	logger, err := decisionlog.NewLogger(decisionLog, client)
+	if err != nil {
+		return nil, err
+	}
+	inspector.SetDecisionLogger(logger)
+	return func() {
+		if err := logger.Close(); err != nil {
+			log.Err(err).Msg("Failed to write decision logs")
+		}
+	}, nil
+}
+
+// printSkippedModules reports the remote modules that were not downloaded and therefore not scanned
+func printSkippedModules(skipped []download.SkippedModule) {
+	if len(skipped) == 0 {
+		return
+	}
+	fmt.Printf("Skipped remote modules: %d\n", len(skipped))
+	for _, module := range skipped {
+		fmt.Printf("\t%s: %s\n", module.Source, module.Reason)
+	}
+	fmt.Println()
+}
+
+// printDryRun prints the queries that would run against each file
+func printDryRun(plans []engine.FilePlan) {
+	fmt.Printf("Dry run, queries that would run against each file:\n\n")
+	for _, plan := range plans {
+		platforms := "no platform detected, only common queries apply"
+		if len(plan.Platforms) > 0 {
+			platforms = strings.Join(plan.Platforms, ", ")
+		}
+		fmt.Printf("%s (%s, %s): %d queries\n", plan.FileName, plan.Kind, platforms, len(plan.Queries))
+		for _, query := range plan.Queries {
+			fmt.Printf("\t%s [%s] (%s)\n", query.Name, query.Platform, query.ID)
+		}
+		fmt.Println()
+	}
+}
+
+// getSeverityBreakdown returns how the summary severity counters should be broken down
+func getSeverityBreakdown() (model.SeverityBreakdown, error) {
+	breakdown := model.SeverityBreakdown{BasePath: path}
+	if absPath, err := filepath.Abs(path); err == nil {
+		breakdown.BasePath = absPath
+	}
+	for _, value := range summaryBreakdown {
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "platform":
+			breakdown.ByPlatform = true
+		case "directory":
+			breakdown.ByDirectory = true
+		case "category":
+			breakdown.ByCategory = true
+		case "project":
+			breakdown.ByProject = true
+		default:
+			return breakdown, fmt.Errorf("summary breakdown not supported: %s, supported values: platform, directory, category, project",
+				value)
+		}
+	}
+	if breakdown.ByProject {
+		projects, err := discovery.Discover(breakdown.BasePath)
+		if err != nil {
+			return breakdown, err
+		}
+		breakdown.Projects = projects
+	}
+	return breakdown, nil
+}
+
+// getScanQuality returns the files that failed to parse or render, the unsupported extensions found
+// and the warnings and errors logged
+func getScanQuality(t *tracker.CITracker, sourceProvider provider.SourceProvider) model.ScanQuality {
+	quality := model.ScanQuality{
+		UnparsedFiles:         append([]model.FileFailure{}, t.UnparsedFiles...),
+		UnrenderedFiles:       append([]model.FileFailure{}, t.UnrenderedFiles...),
+		UnsupportedExtensions: make(map[string]int),
+		Logs:                  logCapture.Entries(),
+	}
+	if p, ok := sourceProvider.(interface{ UnsupportedExtensions() map[string]int }); ok {
+		quality.UnsupportedExtensions = p.UnsupportedExtensions()
+	}
+	return quality
+}
+
+func getSummary(t *tracker.CITracker, results []model.Vulnerability, breakdown model.SeverityBreakdown) model.Summary {
 	counters := model.Counters{
 		ScannedFiles:           t.FoundFiles,
 		ParsedFiles:            t.ParsedFiles,
@@ -378,7 +1267,10 @@ func getSummary(t *tracker.CITracker, results []model.Vulnerability) model.Summa
 		FailedSimilarityID:     t.FailedSimilarityID,
 	}
 
-	return model.CreateSummary(counters, results, scanID)
+	summary := model.CreateSummary(counters, results, scanID)
+	summary.AddBreakdown(results, breakdown)
+	summary.AggregateResults(aggregateThreshold, aggregateSamples)
+	return summary
 }
 
 func resolveOutputs(
@@ -389,6 +1281,10 @@ func resolveOutputs(
 ) error {
 	log.Debug().Msg("console.resolveOutputs()")
 
+	if err := report.SetCSVColumns(csvColumns); err != nil {
+		return err
+	}
+
 	if err := printOutput(payloadPath, "payload", documents, []string{"json"}); err != nil {
 		return err
 	}
@@ -397,7 +1293,14 @@ func resolveOutputs(
 		return err
 	}
 
-	return consoleHelpers.PrintResult(summary, failedQueries, printer)
+	if err := consoleHelpers.PrintResult(summary, failedQueries, printer); err != nil {
+		return err
+	}
+
+	if ciAnnotations != "" {
+		return consoleHelpers.PrintAnnotations(os.Stdout, ciAnnotations, summary)
+	}
+	return nil
 }
 
 func printOutput(outputPath, filename string, body interface{}, formats []string) error {
@@ -410,7 +1313,7 @@ func printOutput(outputPath, filename string, body interface{}, formats []string
 		if len(formats) == 0 && filepath.Ext(outputPath) != "" {
 			formats = []string{filepath.Ext(outputPath)[1:]}
 		}
-		if len(formats) == 1 && strings.HasSuffix(outputPath, formats[0]) {
+		if len(formats) == 1 && strings.HasSuffix(outputPath, consoleHelpers.ReportExtension(formats[0])) {
 			filename = filepath.Base(outputPath)
 			outputPath = filepath.Dir(outputPath)
 		}
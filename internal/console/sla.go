@@ -0,0 +1,115 @@
+package console
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Checkmarx/kics/internal/storage"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/report"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	slaHistoryDir string
+	slaProject    string
+	slaPolicy     []string
+	slaOutputPath string
+
+	slaCmd = &cobra.Command{
+		Use:   "sla",
+		Short: "Evaluates the remediation SLAs of the results of the last scan of a project, exits with code 1 on violations",
+		Long: "Evaluates the remediation SLAs, the maximum age of the results of each severity, over the results of the last scan " +
+			"of a project kept in the scan history, and lists the results older than the maximum age of their severity",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return evaluateSLA()
+		},
+	}
+)
+
+func initSLACmd() {
+	slaCmd.Flags().StringVarP(
+		&slaHistoryDir,
+		"history-dir",
+		"",
+		"",
+		"directory where the scans and their results are kept (scan --history-dir)",
+	)
+	slaCmd.Flags().StringVarP(
+		&slaProject,
+		"project",
+		"",
+		"",
+		"name of the project in the scan history, defaults to the git repository and branch of the working directory "+
+			"(as detected by scan --project) or its absolute path",
+	)
+	slaCmd.Flags().StringSliceVarP(
+		&slaPolicy,
+		"sla",
+		"",
+		[]string{},
+		"maximum age in days of the results of each severity, as severity=days (ex: high=30,medium=90)\n"+
+			"can be provided multiple times or as a comma separated string",
+	)
+	slaCmd.Flags().StringVarP(&slaOutputPath, "output-path", "o", "", "path of the JSON file to store the violations")
+	_ = slaCmd.MarkFlagRequired("history-dir")
+	_ = slaCmd.MarkFlagRequired("sla")
+}
+
+// evaluateSLA evaluates the SLAs over the results of the last scan of the project and exits with code 1 on violations
+func evaluateSLA() error {
+	policy, err := model.ParseSLAPolicy(slaPolicy)
+	if err != nil {
+		return err
+	}
+	projectID, err := projectOrWorkingDir(slaProject)
+	if err != nil {
+		return err
+	}
+	history := storage.NewFileStorage(slaHistoryDir)
+	scan, err := history.GetLatestScan(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if scan == nil {
+		return fmt.Errorf("no scan of project %s in %s", projectID, slaHistoryDir)
+	}
+	results, err := history.GetVulnerabilities(ctx, scan.ID)
+	if err != nil {
+		return err
+	}
+	// the results are kept before the scan sets when they were seen, so they are set from the findings seen
+	findingsSeen, err := history.GetFindingsSeen(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	model.SetSeen(results, findingsSeen, scan.Time)
+
+	violations := policy.Evaluate(results, time.Now())
+	if slaOutputPath != "" {
+		if err := report.PrintJSONReport(filepath.Dir(slaOutputPath), filepath.Base(slaOutputPath), violations); err != nil {
+			return err
+		}
+	}
+	printSLAViolations(scan, violations)
+
+	if len(violations) > 0 {
+		log.Info().Msgf("%d results older than the SLA of their severity", len(violations))
+		os.Exit(1)
+	}
+	return nil
+}
+
+// printSLAViolations prints the results of the scan older than the SLA of their severity
+func printSLAViolations(scan *model.ScanRecord, violations []model.SLAViolation) {
+	fmt.Printf("SLA violations in scan %s of %s: %d\n", scan.ID, scan.Time.Format(time.RFC3339), len(violations))
+	for i := range violations {
+		violation := &violations[i]
+		fmt.Printf("\t[%s] %s: %s:%d, %d days old (SLA %d days)\n", violation.Severity, violation.QueryName,
+			violation.FileName, violation.Line, violation.AgeDays, violation.MaxAgeDays)
+	}
+}
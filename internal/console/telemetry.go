@@ -0,0 +1,68 @@
+package console
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/rs/zerolog/log"
+)
+
+const disableTelemetryEnv = "KICS_DISABLE_TELEMETRY"
+
+var (
+	disableTelemetry bool
+
+	// quoted values may be contents of the scanned files, such as the value of a key that failed to parse
+	quotedRegex = regexp.MustCompile("`[^`]*`|'[^']*'|\"[^\"]*\"")
+	// anything with a path separator, such as the path of a scanned file
+	pathRegex = regexp.MustCompile(`[^\s(),;=]*[/\\][^\s(),;=]*`)
+)
+
+// initTelemetry initializes the error reporting to Sentry, scrubbing the captured events from file paths
+// and contents, telemetry is disabled with the disable-telemetry flag or the KICS_DISABLE_TELEMETRY environment variable
+func initTelemetry() {
+	if telemetryDisabled() {
+		// captures are dropped without client
+		sentry.CurrentHub().BindClient(nil)
+		return
+	}
+	if sentry.CurrentHub().Client() != nil {
+		return
+	}
+	if err := sentry.Init(sentry.ClientOptions{BeforeSend: scrubEvent}); err != nil {
+		log.Err(err).Msg("Failed to initialize sentry")
+	}
+}
+
+func telemetryDisabled() bool {
+	if disableTelemetry {
+		return true
+	}
+	disabled, err := strconv.ParseBool(os.Getenv(disableTelemetryEnv))
+	return err == nil && disabled
+}
+
+// scrubEvent removes from the event everything that may identify the user, the scanned files or their contents
+func scrubEvent(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+	event.ServerName = ""
+	event.User = sentry.User{}
+	event.Request = nil
+	event.Extra = nil
+	event.Message = scrubText(event.Message)
+	for i := range event.Exception {
+		event.Exception[i].Value = scrubText(event.Exception[i].Value)
+	}
+	for _, breadcrumb := range event.Breadcrumbs {
+		breadcrumb.Message = scrubText(breadcrumb.Message)
+		breadcrumb.Data = nil
+	}
+	return event
+}
+
+// scrubText replaces the paths and quoted values of text
+func scrubText(text string) string {
+	text = quotedRegex.ReplaceAllString(text, "<redacted>")
+	return pathRegex.ReplaceAllString(text, "<path>")
+}
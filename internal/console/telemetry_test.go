@@ -0,0 +1,48 @@
+package console
+
+import (
+	"os"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/require"
+)
+
+// TestScrubEvent tests the functions [scrubEvent()] and all the methods called by them
+func TestScrubEvent(t *testing.T) {
+	event := &sentry.Event{
+		ServerName: "build-agent-01",
+		User:       sentry.User{Username: "jdoe"},
+		Message:    "Failed to parse file /home/jdoe/project/main.tf",
+		Extra:      map[string]interface{}{"file": "main.tf"},
+		Exception: []sentry.Exception{
+			{
+				Type:  "*errors.withStack",
+				Value: `failed to open C:\Users\jdoe\project\values.yaml: line 3: cannot unmarshal !!str ` + "`s3cr3t`" + ` into int`,
+			},
+		},
+		Breadcrumbs: []*sentry.Breadcrumb{
+			{Message: "scanning ./infrastructure/prod", Data: map[string]interface{}{"path": "./infrastructure/prod"}},
+		},
+	}
+
+	scrubbed := scrubEvent(event, nil)
+	require.Empty(t, scrubbed.ServerName)
+	require.Equal(t, sentry.User{}, scrubbed.User)
+	require.Nil(t, scrubbed.Extra)
+	require.Equal(t, "Failed to parse file <path>", scrubbed.Message)
+	require.Equal(t, "failed to open <path> line 3: cannot unmarshal !!str <redacted> into int", scrubbed.Exception[0].Value)
+	require.Equal(t, "scanning <path>", scrubbed.Breadcrumbs[0].Message)
+	require.Nil(t, scrubbed.Breadcrumbs[0].Data)
+}
+
+// TestTelemetryDisabled tests the functions [telemetryDisabled()] and all the methods called by them
+func TestTelemetryDisabled(t *testing.T) {
+	require.False(t, telemetryDisabled())
+
+	require.NoError(t, os.Setenv(disableTelemetryEnv, "true"))
+	defer func() {
+		require.NoError(t, os.Unsetenv(disableTelemetryEnv))
+	}()
+	require.True(t, telemetryDisabled())
+}
@@ -0,0 +1,99 @@
+package console
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Checkmarx/kics/internal/storage"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/spf13/cobra"
+)
+
+var (
+	triageHistoryDir string
+	triageProject    string
+	triageState      string
+	triageAssignee   string
+
+	triageCmd = &cobra.Command{
+		Use:   "triage <similarity-id>",
+		Short: "Sets the triage state and the assignee of a result of a project kept in the scan history",
+		Long: "Sets the triage state (open, acknowledged, false-positive, fixed) and the assignee of a result of a project, " +
+			"by similarity ID, kept in the scan history and set in the results of the next scans of the project",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return saveTriage(args[0], cmd.Flags().Changed("assignee"))
+		},
+	}
+)
+
+func initTriageCmd() {
+	triageCmd.Flags().StringVarP(
+		&triageHistoryDir,
+		"history-dir",
+		"",
+		"",
+		"directory where the scans and their results are kept (scan --history-dir)",
+	)
+	triageCmd.Flags().StringVarP(
+		&triageProject,
+		"project",
+		"",
+		"",
+		"name of the project in the scan history, defaults to the git repository and branch of the working directory "+
+			"(as detected by scan --project) or its absolute path",
+	)
+	triageCmd.Flags().StringVarP(
+		&triageState,
+		"state",
+		"s",
+		"",
+		"triage state of the result (open, acknowledged, false-positive, fixed), keeps the current state when not given",
+	)
+	triageCmd.Flags().StringVarP(
+		&triageAssignee,
+		"assignee",
+		"a",
+		"",
+		"who the result is assigned to, an empty assignee unassigns the result",
+	)
+	_ = triageCmd.MarkFlagRequired("history-dir")
+}
+
+// saveTriage updates the triage of the result of the project, results not triaged yet are open
+func saveTriage(similarityID string, assign bool) error {
+	if triageState == "" && !assign {
+		return fmt.Errorf("nothing to triage, set the state with --state or the assignee with --assignee")
+	}
+	projectID, err := projectOrWorkingDir(triageProject)
+	if err != nil {
+		return err
+	}
+	history := storage.NewFileStorage(triageHistoryDir)
+	triages, err := history.GetTriages(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	triage, ok := triages[similarityID]
+	if !ok {
+		triage.State = model.TriageOpen
+	}
+	if triageState != "" {
+		if triage.State, err = model.ParseTriageState(triageState); err != nil {
+			return err
+		}
+	}
+	if assign {
+		triage.Assignee = triageAssignee
+	}
+	triage.UpdatedAt = time.Now()
+	if err := history.SaveTriage(ctx, projectID, similarityID, triage); err != nil {
+		return err
+	}
+	fmt.Printf("Result %s of project %s: %s", similarityID, projectID, triage.State)
+	if triage.Assignee != "" {
+		fmt.Printf(", assigned to %s", triage.Assignee)
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,47 @@
+package console
+
+import (
+	"time"
+
+	"github.com/Checkmarx/kics/pkg/watchdog"
+)
+
+var (
+	heartbeatInterval time.Duration
+	watchdogThreshold time.Duration
+	watchdogSkip      bool
+)
+
+// initWatchdogFlags adds the flags of the heartbeats and of the watchdog of stuck files and queries
+func initWatchdogFlags() {
+	scanCmd.Flags().DurationVarP(
+		&heartbeatInterval,
+		"heartbeat-interval",
+		"",
+		0,
+		"interval of the heartbeats logging the files and queries in progress (ex: 30s), disabled by default",
+	)
+	scanCmd.Flags().DurationVarP(
+		&watchdogThreshold,
+		"watchdog-threshold",
+		"",
+		0,
+		"time after which a file or query in progress is logged as stuck (ex: 5m), disabled by default",
+	)
+	scanCmd.Flags().BoolVarP(
+		&watchdogSkip,
+		"watchdog-skip",
+		"",
+		false,
+		"skip the queries running longer than the watchdog threshold, they are reported as failed",
+	)
+}
+
+// getWatchdog returns the watchdog of the flags, nil when disabled
+func getWatchdog() *watchdog.Watchdog {
+	return watchdog.New(watchdog.Options{
+		HeartbeatInterval: heartbeatInterval,
+		Threshold:         watchdogThreshold,
+		Skip:              watchdogSkip,
+	})
+}
@@ -20,3 +20,6 @@ const MinimumPreviewLines = 1
 
 // MaximumPreviewLines - default maximum preview lines number
 const MaximumPreviewLines = 30
+
+// StrictExitCode - exit code of scans in strict mode with files or modules that could not be analyzed
+const StrictExitCode = 3
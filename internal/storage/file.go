@@ -0,0 +1,350 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	memoryStorage "github.com/Checkmarx/kics/pkg/storage"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	scansFileName    = "scans.json"
+	findingsFileName = "findings.json"
+	triageFileName   = "triage.json"
+	resultsDirName   = "results"
+	// historyLimit is the number of scans kept for each project, besides its last successful scan
+	historyLimit = 10
+)
+
+// FileStorage is a MemoryStorage that also keeps the scans of each project and their vulnerabilities in a directory,
+// so the results of a scan can be compared with the previous scans of the project, and, if enabled, their parsed files,
+// so the scans can be inspected again
+type FileStorage struct {
+	*memoryStorage.MemoryStorage
+	dir       string
+	keepFiles bool
+}
+
+// NewFileStorage creates a new FileStorage keeping the scans in the directory dir
+func NewFileStorage(dir string) *FileStorage {
+	log.Debug().Msg("storage.NewFileStorage()")
+	return &FileStorage{
+		MemoryStorage: memoryStorage.NewMemoryStorage(),
+		dir:           dir,
+	}
+}
+
+// GetVulnerabilities returns the vulnerabilities of a scan kept in the directory or, for the current scan,
+// the vulnerabilities saved on memory
+func (f *FileStorage) GetVulnerabilities(ctx context.Context, scanID string) ([]model.Vulnerability, error) {
+	if filepath.Base(scanID) != scanID {
+		return f.MemoryStorage.GetVulnerabilities(ctx, scanID)
+	}
+	content, err := os.ReadFile(f.resultsPath(scanID))
+	if os.IsNotExist(err) {
+		return f.MemoryStorage.GetVulnerabilities(ctx, scanID)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the results of scan %s", scanID)
+	}
+	var vulnerabilities []model.Vulnerability
+	if err := json.Unmarshal(content, &vulnerabilities); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the results of scan %s", scanID)
+	}
+	for i := range vulnerabilities {
+		vulnerabilities[i].ScanID = scanID
+	}
+	return vulnerabilities, nil
+}
+
+// KeepFiles enables keeping the parsed files of the scans along with their vulnerabilities
+func (f *FileStorage) KeepFiles() {
+	f.keepFiles = true
+}
+
+// GetFiles returns the parsed files of a scan kept in the directory or, for the current scan,
+// the files saved on memory with its ID
+func (f *FileStorage) GetFiles(ctx context.Context, scanID string) (model.FileMetadatas, error) {
+	if filepath.Base(scanID) != scanID {
+		return f.MemoryStorage.GetFiles(ctx, scanID)
+	}
+	content, err := os.ReadFile(f.filesPath(scanID))
+	if os.IsNotExist(err) {
+		return f.MemoryStorage.GetFiles(ctx, scanID)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the files of scan %s", scanID)
+	}
+	var files model.FileMetadatas
+	if err := json.Unmarshal(content, &files); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the files of scan %s", scanID)
+	}
+	return files, nil
+}
+
+// SaveScan keeps the scan and the vulnerabilities saved on memory with its ID in the directory, along with its files
+// when they are kept, only the last scans of each project and their last successful scan are kept
+func (f *FileStorage) SaveScan(ctx context.Context, scan model.ScanRecord) error {
+	if filepath.Base(scan.ID) != scan.ID {
+		return errors.Errorf("invalid scan ID %s", scan.ID)
+	}
+	if err := os.MkdirAll(filepath.Join(f.dir, resultsDirName), os.ModePerm); err != nil {
+		return errors.Wrap(err, "failed to create the scan history directory")
+	}
+	vulnerabilities, err := f.MemoryStorage.GetVulnerabilities(ctx, scan.ID)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(f.resultsPath(scan.ID), vulnerabilities); err != nil {
+		return errors.Wrapf(err, "failed to save the results of scan %s", scan.ID)
+	}
+	if err := f.saveFiles(ctx, scan.ID); err != nil {
+		return err
+	}
+
+	scans, err := f.getScans()
+	if err != nil {
+		return err
+	}
+	scans = f.prune(append(scans, scan))
+	return errors.Wrap(writeJSON(filepath.Join(f.dir, scansFileName), scans), "failed to save the scan history")
+}
+
+// GetLastScan returns the most recent successful scan of the project kept in the directory, nil if there is none
+func (f *FileStorage) GetLastScan(_ context.Context, projectID string) (*model.ScanRecord, error) {
+	scans, err := f.getScans()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(scans) - 1; i >= 0; i-- {
+		if scans[i].ProjectID == projectID && scans[i].Success {
+			return &scans[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// GetLatestScan returns the most recent scan of the project kept in the directory, successful or not, nil if there is none
+func (f *FileStorage) GetLatestScan(_ context.Context, projectID string) (*model.ScanRecord, error) {
+	scans, err := f.getScans()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(scans) - 1; i >= 0; i-- {
+		if scans[i].ProjectID == projectID {
+			return &scans[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// GetScans returns the scans of the project kept in the directory, from the oldest to the most recent
+func (f *FileStorage) GetScans(_ context.Context, projectID string) ([]model.ScanRecord, error) {
+	scans, err := f.getScans()
+	if err != nil {
+		return nil, err
+	}
+	projectScans := make([]model.ScanRecord, 0, len(scans))
+	for i := range scans {
+		if scans[i].ProjectID == projectID {
+			projectScans = append(projectScans, scans[i])
+		}
+	}
+	return projectScans, nil
+}
+
+// SaveAudit keeps the audit of the scan in the directory, along with its results
+func (f *FileStorage) SaveAudit(_ context.Context, audit *model.ScanAudit) error {
+	if filepath.Base(audit.ScanID) != audit.ScanID {
+		return errors.Errorf("invalid scan ID %s", audit.ScanID)
+	}
+	if err := os.MkdirAll(filepath.Join(f.dir, resultsDirName), os.ModePerm); err != nil {
+		return errors.Wrap(err, "failed to create the scan history directory")
+	}
+	return errors.Wrapf(writeJSON(f.auditPath(audit.ScanID), audit), "failed to save the audit of scan %s", audit.ScanID)
+}
+
+// GetAudit returns the audit of a scan kept in the directory, nil if there is none
+func (f *FileStorage) GetAudit(_ context.Context, scanID string) (*model.ScanAudit, error) {
+	if filepath.Base(scanID) != scanID {
+		return nil, errors.Errorf("invalid scan ID %s", scanID)
+	}
+	content, err := os.ReadFile(f.auditPath(scanID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the audit of scan %s", scanID)
+	}
+	var audit model.ScanAudit
+	if err := json.Unmarshal(content, &audit); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the audit of scan %s", scanID)
+	}
+	return &audit, nil
+}
+
+// GetFindingsSeen returns when the results of the project were first and last seen, by similarity ID
+func (f *FileStorage) GetFindingsSeen(_ context.Context, projectID string) (map[string]model.FindingSeen, error) {
+	findings, err := f.getFindings()
+	if err != nil {
+		return nil, err
+	}
+	return findings[projectID], nil
+}
+
+// SaveFindingsSeen replaces the results seen of the project kept in the directory
+func (f *FileStorage) SaveFindingsSeen(_ context.Context, projectID string, seen map[string]model.FindingSeen) error {
+	if err := os.MkdirAll(f.dir, os.ModePerm); err != nil {
+		return errors.Wrap(err, "failed to create the scan history directory")
+	}
+	findings, err := f.getFindings()
+	if err != nil {
+		return err
+	}
+	if findings == nil {
+		findings = make(map[string]map[string]model.FindingSeen)
+	}
+	findings[projectID] = seen
+	return errors.Wrap(writeJSON(filepath.Join(f.dir, findingsFileName), findings), "failed to save the results seen")
+}
+
+func (f *FileStorage) getFindings() (map[string]map[string]model.FindingSeen, error) {
+	content, err := os.ReadFile(filepath.Join(f.dir, findingsFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the results seen")
+	}
+	var findings map[string]map[string]model.FindingSeen
+	if err := json.Unmarshal(content, &findings); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the results seen")
+	}
+	return findings, nil
+}
+
+// GetTriages returns the triage of the results of the project, by similarity ID
+func (f *FileStorage) GetTriages(_ context.Context, projectID string) (map[string]model.Triage, error) {
+	triages, err := f.getTriages()
+	if err != nil {
+		return nil, err
+	}
+	return triages[projectID], nil
+}
+
+// SaveTriage replaces the triage of the result of the project kept in the directory
+func (f *FileStorage) SaveTriage(_ context.Context, projectID, similarityID string, triage model.Triage) error {
+	if err := os.MkdirAll(f.dir, os.ModePerm); err != nil {
+		return errors.Wrap(err, "failed to create the scan history directory")
+	}
+	triages, err := f.getTriages()
+	if err != nil {
+		return err
+	}
+	if triages == nil {
+		triages = make(map[string]map[string]model.Triage)
+	}
+	if triages[projectID] == nil {
+		triages[projectID] = make(map[string]model.Triage)
+	}
+	triages[projectID][similarityID] = triage
+	return errors.Wrap(writeJSON(filepath.Join(f.dir, triageFileName), triages), "failed to save the triage")
+}
+
+func (f *FileStorage) getTriages() (map[string]map[string]model.Triage, error) {
+	content, err := os.ReadFile(filepath.Join(f.dir, triageFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the triage")
+	}
+	var triages map[string]map[string]model.Triage
+	if err := json.Unmarshal(content, &triages); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the triage")
+	}
+	return triages, nil
+}
+
+func (f *FileStorage) getScans() ([]model.ScanRecord, error) {
+	content, err := os.ReadFile(filepath.Join(f.dir, scansFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the scan history")
+	}
+	var scans []model.ScanRecord
+	if err := json.Unmarshal(content, &scans); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the scan history")
+	}
+	return scans, nil
+}
+
+// prune removes the scans older than the last ones of each project, except its last successful scan,
+// along with their results, audits and files
+func (f *FileStorage) prune(scans []model.ScanRecord) []model.ScanRecord {
+	count := make(map[string]int)
+	successful := make(map[string]bool)
+	kept := make([]model.ScanRecord, 0, len(scans))
+	for i := len(scans) - 1; i >= 0; i-- {
+		scan := scans[i]
+		count[scan.ProjectID]++
+		lastSuccessful := scan.Success && !successful[scan.ProjectID]
+		if scan.Success {
+			successful[scan.ProjectID] = true
+		}
+		if count[scan.ProjectID] <= historyLimit || lastSuccessful {
+			kept = append([]model.ScanRecord{scan}, kept...)
+			continue
+		}
+		for _, fileName := range []string{f.resultsPath(scan.ID), f.auditPath(scan.ID), f.filesPath(scan.ID)} {
+			if err := os.Remove(fileName); err != nil && !os.IsNotExist(err) {
+				log.Warn().Msgf("Failed to remove the results of scan %s: %s", scan.ID, err)
+			}
+		}
+	}
+	return kept
+}
+
+func (f *FileStorage) resultsPath(scanID string) string {
+	return filepath.Join(f.dir, resultsDirName, scanID+".json")
+}
+
+// saveFiles keeps the files saved on memory with the ID of the scan in the directory, when files are kept
+func (f *FileStorage) saveFiles(ctx context.Context, scanID string) error {
+	if !f.keepFiles {
+		return nil
+	}
+	files, err := f.MemoryStorage.GetFiles(ctx, scanID)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(writeJSON(f.filesPath(scanID), files), "failed to save the files of scan %s", scanID)
+}
+
+func (f *FileStorage) filesPath(scanID string) string {
+	return filepath.Join(f.dir, resultsDirName, scanID+".files.json")
+}
+
+func (f *FileStorage) auditPath(scanID string) string {
+	return filepath.Join(f.dir, resultsDirName, scanID+".audit.json")
+}
+
+// writeJSON writes the value to a temporary file renamed to the file name, so readers never see partial content
+func writeJSON(fileName string, value interface{}) error {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	tmp := fileName + ".tmp"
+	if err := os.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fileName)
+}
@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFileStorage tests the functions [SaveScan(), GetLastScan(), GetLatestScan(), GetScans(), GetVulnerabilities()] and all the methods called by them
+func TestFileStorage(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	f := NewFileStorage(dir)
+	last, err := f.GetLastScan(ctx, "project")
+	require.NoError(t, err)
+	require.Nil(t, last)
+
+	vulnerability := model.Vulnerability{ScanID: "first", SimilarityID: "a1", QueryID: "query_id", Severity: model.SeverityHigh}
+	require.NoError(t, f.SaveVulnerabilities(ctx, []model.Vulnerability{vulnerability}))
+	require.NoError(t, f.SaveScan(ctx, model.ScanRecord{ID: "first", ProjectID: "project", Success: true}))
+
+	// the history is kept between runs
+	f = NewFileStorage(dir)
+	require.NoError(t, f.SaveScan(ctx, model.ScanRecord{ID: "second", ProjectID: "project", Success: false}))
+	require.NoError(t, f.SaveScan(ctx, model.ScanRecord{ID: "other", ProjectID: "other", Success: true}))
+	last, err = f.GetLastScan(ctx, "project")
+	require.NoError(t, err)
+	require.Equal(t, &model.ScanRecord{ID: "first", ProjectID: "project", Success: true}, last)
+	latest, err := f.GetLatestScan(ctx, "project")
+	require.NoError(t, err)
+	require.Equal(t, &model.ScanRecord{ID: "second", ProjectID: "project", Success: false}, latest)
+	latest, err = f.GetLatestScan(ctx, "missing")
+	require.NoError(t, err)
+	require.Nil(t, latest)
+	projectScans, err := f.GetScans(ctx, "project")
+	require.NoError(t, err)
+	require.Equal(t, []model.ScanRecord{
+		{ID: "first", ProjectID: "project", Success: true},
+		{ID: "second", ProjectID: "project", Success: false},
+	}, projectScans)
+
+	vulnerabilities, err := f.GetVulnerabilities(ctx, "first")
+	require.NoError(t, err)
+	require.Equal(t, []model.Vulnerability{vulnerability}, vulnerabilities)
+	vulnerabilities, err = f.GetVulnerabilities(ctx, "second")
+	require.NoError(t, err)
+	require.Empty(t, vulnerabilities)
+
+	// old scans are removed, except the last successful one
+	for i := 0; i < historyLimit; i++ {
+		require.NoError(t, f.SaveScan(ctx, model.ScanRecord{ID: fmt.Sprintf("failed-%d", i), ProjectID: "project"}))
+	}
+	scans, err := f.getScans()
+	require.NoError(t, err)
+	require.Len(t, scans, historyLimit+2)
+	require.Equal(t, "first", scans[0].ID)
+	_, err = os.Stat(filepath.Join(dir, resultsDirName, "second.json"))
+	require.True(t, os.IsNotExist(err))
+
+	require.Error(t, f.SaveScan(ctx, model.ScanRecord{ID: "../scan", ProjectID: "project"}))
+}
+
+// TestFileStorage_FindingsSeen tests the functions [GetFindingsSeen(), SaveFindingsSeen()] and all the methods called by them
+func TestFileStorage_FindingsSeen(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "history")
+
+	f := NewFileStorage(dir)
+	seen, err := f.GetFindingsSeen(ctx, "project")
+	require.NoError(t, err)
+	require.Empty(t, seen)
+
+	firstSeen := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	lastSeen := firstSeen.Add(72 * time.Hour)
+	projectSeen := map[string]model.FindingSeen{"a1": {FirstSeen: firstSeen, LastSeen: lastSeen}}
+	require.NoError(t, f.SaveFindingsSeen(ctx, "project", projectSeen))
+	require.NoError(t, f.SaveFindingsSeen(ctx, "other", map[string]model.FindingSeen{"b2": {FirstSeen: lastSeen, LastSeen: lastSeen}}))
+
+	// the results seen are kept between runs
+	f = NewFileStorage(dir)
+	seen, err = f.GetFindingsSeen(ctx, "project")
+	require.NoError(t, err)
+	require.Equal(t, projectSeen, seen)
+	seen, err = f.GetFindingsSeen(ctx, "other")
+	require.NoError(t, err)
+	require.Len(t, seen, 1)
+}
+
+// TestFileStorage_Triage tests the functions [GetTriages(), SaveTriage()] and all the methods called by them
+func TestFileStorage_Triage(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "history")
+
+	f := NewFileStorage(dir)
+	triages, err := f.GetTriages(ctx, "project")
+	require.NoError(t, err)
+	require.Empty(t, triages)
+
+	updatedAt := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	acknowledged := model.Triage{State: model.TriageAcknowledged, Assignee: "alice", UpdatedAt: updatedAt}
+	require.NoError(t, f.SaveTriage(ctx, "project", "a1", model.Triage{State: model.TriageOpen, UpdatedAt: updatedAt}))
+	require.NoError(t, f.SaveTriage(ctx, "project", "a1", acknowledged))
+	require.NoError(t, f.SaveTriage(ctx, "project", "a2", model.Triage{State: model.TriageFalsePositive, UpdatedAt: updatedAt}))
+	require.NoError(t, f.SaveTriage(ctx, "other", "b1", model.Triage{State: model.TriageFixed, UpdatedAt: updatedAt}))
+
+	// the triage is kept between runs
+	f = NewFileStorage(dir)
+	triages, err = f.GetTriages(ctx, "project")
+	require.NoError(t, err)
+	require.Len(t, triages, 2)
+	require.Equal(t, acknowledged, triages["a1"])
+	require.Equal(t, model.TriageFalsePositive, triages["a2"].State)
+	triages, err = f.GetTriages(ctx, "other")
+	require.NoError(t, err)
+	require.Len(t, triages, 1)
+}
+
+// TestFileStorage_Audit tests the functions [SaveAudit(), GetAudit()] and all the methods called by them
+func TestFileStorage_Audit(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "history")
+
+	f := NewFileStorage(dir)
+	audit, err := f.GetAudit(ctx, "first")
+	require.NoError(t, err)
+	require.Nil(t, audit)
+
+	saved := model.NewScanAudit("first", map[string]string{"fail-on": "high"}, []model.QueryAudit{
+		{ID: "a", Name: "Query A", Platform: "Terraform", Engine: "rego", Digest: "sha256:a"},
+	}, time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC))
+	require.NoError(t, f.SaveAudit(ctx, saved))
+	require.Error(t, f.SaveAudit(ctx, &model.ScanAudit{ScanID: "../scan"}))
+
+	// the audit is kept between runs and removed with its scan
+	f = NewFileStorage(dir)
+	audit, err = f.GetAudit(ctx, "first")
+	require.NoError(t, err)
+	require.Equal(t, saved, audit)
+	require.NoError(t, f.SaveScan(ctx, model.ScanRecord{ID: "first", ProjectID: "project"}))
+	for i := 0; i < historyLimit; i++ {
+		require.NoError(t, f.SaveScan(ctx, model.ScanRecord{ID: fmt.Sprintf("failed-%d", i), ProjectID: "project"}))
+	}
+	audit, err = f.GetAudit(ctx, "first")
+	require.NoError(t, err)
+	require.Nil(t, audit)
+}
+
+// TestFileStorage_KeepFiles tests the functions [KeepFiles(), GetFiles()] and all the methods called by them
+func TestFileStorage_KeepFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "history")
+
+	f := NewFileStorage(dir)
+	f.KeepFiles()
+	file := model.FileMetadata{ID: "file", ScanID: "first", FileName: "main.tf", Document: model.Document{"resource": "bucket"}}
+	require.NoError(t, f.SaveFile(ctx, &file))
+	require.NoError(t, f.SaveFile(ctx, &model.FileMetadata{ID: "other", ScanID: "second", FileName: "main.tf"}))
+	require.NoError(t, f.SaveScan(ctx, model.ScanRecord{ID: "first", ProjectID: "project"}))
+
+	// the files are kept between runs, files of other scans are not returned
+	f = NewFileStorage(dir)
+	files, err := f.GetFiles(ctx, "first")
+	require.NoError(t, err)
+	require.Equal(t, model.FileMetadatas{file}, files)
+	files, err = f.GetFiles(ctx, "second")
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	// files are not kept unless enabled
+	require.NoError(t, f.SaveFile(ctx, &model.FileMetadata{ID: "third", ScanID: "third", FileName: "main.tf"}))
+	require.NoError(t, f.SaveScan(ctx, model.ScanRecord{ID: "third", ProjectID: "project"}))
+	_, err = os.Stat(filepath.Join(dir, resultsDirName, "third.files.json"))
+	require.True(t, os.IsNotExist(err))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/Checkmarx/kics/internal/constants"
+	"github.com/Checkmarx/kics/pkg/model"
 )
 
 // CITracker contains information of how many queries were loaded and executed
@@ -14,6 +15,8 @@ type CITracker struct {
 	FoundFiles         int
 	ParsedFiles        int
 	FailedSimilarityID int
+	UnparsedFiles      []model.FileFailure
+	UnrenderedFiles    []model.FileFailure
 	lines              int
 }
 
@@ -54,6 +57,25 @@ func (c *CITracker) TrackFileParse() {
 	c.ParsedFiles++
 }
 
+// TrackFileParseFailure adds a file that failed to parse, with the reason code of the error, PARSE_ERROR by default
+func (c *CITracker) TrackFileParseFailure(fileName string, err error) {
+	c.UnparsedFiles = append(c.UnparsedFiles, model.FileFailure{
+		FileName: fileName,
+		Code:     model.ReasonCodeOf(err, model.ReasonParseError),
+		Reason:   err.Error(),
+	})
+}
+
+// TrackFileRenderFailure adds a file or directory that failed to render, with the reason code of the error,
+// RENDER_ERROR by default
+func (c *CITracker) TrackFileRenderFailure(fileName string, err error) {
+	c.UnrenderedFiles = append(c.UnrenderedFiles, model.FileFailure{
+		FileName: fileName,
+		Code:     model.ReasonCodeOf(err, model.ReasonRenderError),
+		Reason:   err.Error(),
+	})
+}
+
 // FailedDetectLine - queries that fail to detect line are counted as failed to execute queries
 func (c *CITracker) FailedDetectLine() {
 	c.ExecutedQueries--
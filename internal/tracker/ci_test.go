@@ -1,10 +1,13 @@
 package tracker
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
 
+	"github.com/Checkmarx/kics/pkg/model"
 	"github.com/Checkmarx/kics/test"
 	"github.com/stretchr/testify/require"
 )
@@ -132,3 +135,20 @@ func TestNewTracker(t *testing.T) {
 		})
 	}
 }
+
+// TestCITracker_TrackFileFailures tests the functions [TrackFileParseFailure(),TrackFileRenderFailure()]
+func TestCITracker_TrackFileFailures(t *testing.T) {
+	c := &CITracker{}
+	c.TrackFileParseFailure("broken.yaml", errors.New("invalid yaml"))
+	c.TrackFileParseFailure("large.json", model.NewFileError(model.ReasonFileTooLarge, errors.New("file size limit exceeded")))
+	c.TrackFileRenderFailure("chart", errors.New("missing template"))
+	c.TrackFileRenderFailure("slow-chart", fmt.Errorf("failed to render: %w", context.DeadlineExceeded))
+	require.Equal(t, []model.FileFailure{
+		{FileName: "broken.yaml", Code: model.ReasonParseError, Reason: "invalid yaml"},
+		{FileName: "large.json", Code: model.ReasonFileTooLarge, Reason: "file size limit exceeded"},
+	}, c.UnparsedFiles)
+	require.Equal(t, []model.FileFailure{
+		{FileName: "chart", Code: model.ReasonRenderError, Reason: "missing template"},
+		{FileName: "slow-chart", Code: model.ReasonTimeout, Reason: "failed to render: context deadline exceeded"},
+	}, c.UnrenderedFiles)
+}
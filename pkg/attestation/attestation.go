@@ -0,0 +1,187 @@
+// Package attestation builds in-toto attestations of scan results and signs them in DSSE envelopes,
+// the format of cosign attestations, so deploy gates can verify a scan ran on the commit being deployed
+package attestation
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// StatementType is the in-toto statement type
+	StatementType = "https://in-toto.io/Statement/v0.1"
+	// PredicateType is the cosign vulnerability scan predicate type, the results are the scanner result
+	PredicateType = "https://cosign.sigstore.dev/attestation/vuln/v1"
+	// PayloadType is the DSSE payload type of in-toto statements
+	PayloadType = "application/vnd.in-toto+json"
+)
+
+// Subject is the artifact attested, the scanned commit
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Scanner is the scanner that produced the result
+type Scanner struct {
+	URI     string      `json:"uri"`
+	Version string      `json:"version"`
+	Result  interface{} `json:"result"`
+}
+
+// Metadata holds when the scan ran
+type Metadata struct {
+	ScanStartedOn  time.Time `json:"scanStartedOn"`
+	ScanFinishedOn time.Time `json:"scanFinishedOn"`
+}
+
+// Predicate is the scan predicate
+type Predicate struct {
+	Invocation map[string]interface{} `json:"invocation"`
+	Scanner    Scanner                `json:"scanner"`
+	Metadata   Metadata               `json:"metadata"`
+}
+
+// Statement is an in-toto statement binding the predicate to its subjects
+type Statement struct {
+	Type          string    `json:"_type"`
+	PredicateType string    `json:"predicateType"`
+	Subject       []Subject `json:"subject"`
+	Predicate     Predicate `json:"predicate"`
+}
+
+// Signature is a DSSE signature
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// Envelope is a DSSE envelope, the payload is base64 encoded
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// NewStatement returns the statement of the scan of the commit, name identifies the scanned repository
+func NewStatement(name, commit string, predicate *Predicate) *Statement {
+	return &Statement{
+		Type:          StatementType,
+		PredicateType: PredicateType,
+		Subject: []Subject{
+			{
+				Name:   name,
+				Digest: map[string]string{"gitCommit": commit},
+			},
+		},
+		Predicate: *predicate,
+	}
+}
+
+// LoadSigner returns the signer of the PEM private key file, PKCS#8, EC and PKCS#1 keys are supported
+func LoadSigner(keyPath string) (crypto.Signer, error) {
+	content, err := os.ReadFile(filepath.Clean(keyPath))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read attestation key")
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("attestation key %s is not PEM encoded", keyPath)
+	}
+	var key interface{}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse attestation key")
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("attestation key %s can't sign", keyPath)
+	}
+	return signer, nil
+}
+
+// Sign returns the DSSE envelope of the statement signed by signer
+func Sign(statement *Statement, signer crypto.Signer) (*Envelope, error) {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal statement")
+	}
+	message := pae(PayloadType, payload)
+	var sig []byte
+	if _, ok := signer.(ed25519.PrivateKey); ok {
+		sig, err = signer.Sign(rand.Reader, message, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(message)
+		sig, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign statement")
+	}
+	return &Envelope{
+		PayloadType: PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []Signature{{Sig: base64.StdEncoding.EncodeToString(sig)}},
+	}, nil
+}
+
+// Verify returns the statement of the envelope if one of its signatures is from the public key
+func Verify(envelope *Envelope, publicKey crypto.PublicKey) (*Statement, error) {
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode payload")
+	}
+	message := pae(envelope.PayloadType, payload)
+	digest := sha256.Sum256(message)
+	verified := false
+	for _, signature := range envelope.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(signature.Sig)
+		if err != nil {
+			continue
+		}
+		switch key := publicKey.(type) {
+		case *ecdsa.PublicKey:
+			verified = ecdsa.VerifyASN1(key, digest[:], sig)
+		case *rsa.PublicKey:
+			verified = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) == nil
+		case ed25519.PublicKey:
+			verified = ed25519.Verify(key, message, sig)
+		}
+		if verified {
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("no valid signature of the public key")
+	}
+	statement := &Statement{}
+	if err := json.Unmarshal(payload, statement); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal statement")
+	}
+	return statement, nil
+}
+
+// pae is the DSSE pre-authentication encoding of the payload, the message signed
+func pae(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
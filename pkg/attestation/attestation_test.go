@@ -0,0 +1,82 @@
+package attestation
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeKey(t *testing.T, key interface{}) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+	return keyPath
+}
+
+// TestSign tests the functions [LoadSigner(), Sign(), Verify()] and all the methods called by them
+func TestSign(t *testing.T) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		key       crypto.Signer
+		publicKey crypto.PublicKey
+	}{
+		{name: "ecdsa", key: ecdsaKey, publicKey: ecdsaKey.Public()},
+		{name: "ed25519", key: ed25519Key, publicKey: ed25519Key.Public()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := LoadSigner(writeKey(t, tt.key))
+			require.NoError(t, err)
+
+			statement := NewStatement("git+https://github.com/Checkmarx/kics", "4fd4d2ed7a9ae0d1bb6d0a7c2ad5b7cd5af4e4b1",
+				&Predicate{Scanner: Scanner{URI: "https://github.com/Checkmarx/kics", Version: "1.3.0", Result: "results"}})
+			envelope, err := Sign(statement, signer)
+			require.NoError(t, err)
+			require.Equal(t, PayloadType, envelope.PayloadType)
+
+			verified, err := Verify(envelope, tt.publicKey)
+			require.NoError(t, err)
+			require.Equal(t, StatementType, verified.Type)
+			require.Equal(t, PredicateType, verified.PredicateType)
+			require.Equal(t, statement.Subject, verified.Subject)
+			require.Equal(t, "results", verified.Predicate.Scanner.Result)
+
+			_, err = Verify(envelope, otherKey.Public())
+			require.Error(t, err)
+
+			tampered := *envelope
+			tampered.Payload = base64.StdEncoding.EncodeToString([]byte(`{"_type":"tampered"}`))
+			_, err = Verify(&tampered, tt.publicKey)
+			require.Error(t, err)
+		})
+	}
+}
+
+// TestLoadSigner tests the functions [LoadSigner()] with invalid keys
+func TestLoadSigner(t *testing.T) {
+	_, err := LoadSigner(filepath.Join(t.TempDir(), "missing.pem"))
+	require.Error(t, err)
+
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(keyPath, []byte("not a key"), 0600))
+	_, err = LoadSigner(keyPath)
+	require.Error(t, err)
+}
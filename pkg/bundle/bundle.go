@@ -0,0 +1,279 @@
+// Package bundle creates and loads offline bundles, single archives with everything a scan needs
+// (queries, rego libraries, manifest, default configuration and cached remote modules) verified by checksum
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Checkmarx/kics/pkg/engine/source"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// IndexFileName is the name of the index of the bundle, with the checksums of its files
+	IndexFileName = "bundle.json"
+	// ChecksumExtension is the extension of the file with the checksum of the bundle, next to the bundle
+	ChecksumExtension = ".sha256"
+
+	queriesDir   = "assets/queries"
+	librariesDir = "assets/libraries"
+	manifestFile = "assets/" + source.ManifestFileName
+	configFile   = "config/kics.config"
+	cacheDir     = "cache"
+)
+
+// Options are the contents of a bundle, QueriesPath is required and the others are optional
+type Options struct {
+	QueriesPath string
+	ConfigFile  string
+	CacheDir    string
+	Version     string
+}
+
+// Index lists the files of a bundle with their SHA-256 checksums
+type Index struct {
+	Version   string            `json:"version"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Files     map[string]string `json:"files"`
+}
+
+// Bundle is a loaded bundle, the paths of its contents, empty when the bundle does not have them
+type Bundle struct {
+	Index       Index
+	QueriesPath string
+	ConfigFile  string
+	CacheDir    string
+}
+
+// Create writes the bundle of the options to bundlePath and the checksum of the bundle next to it,
+// the checksum is returned
+func Create(bundlePath string, opts *Options) (string, error) {
+	sources, err := bundleSources(opts)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.OpenFile(filepath.Clean(bundlePath), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create bundle")
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Err(err).Msgf("Failed to close file %s", bundlePath)
+		}
+	}()
+
+	hash := sha256.New()
+	gw := gzip.NewWriter(io.MultiWriter(f, hash))
+	tw := tar.NewWriter(gw)
+	index := Index{Version: opts.Version, CreatedAt: time.Now().UTC(), Files: make(map[string]string)}
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		content, err := os.ReadFile(filepath.Clean(sources[name]))
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read %s", sources[name])
+		}
+		if err := writeFile(tw, name, content); err != nil {
+			return "", err
+		}
+		index.Files[name] = checksum(content)
+	}
+	indexContent, err := json.MarshalIndent(index, "", "\t")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal bundle index")
+	}
+	if err := writeFile(tw, IndexFileName, indexContent); err != nil {
+		return "", err
+	}
+	if err := tw.Close(); err != nil {
+		return "", errors.Wrap(err, "failed to write bundle")
+	}
+	if err := gw.Close(); err != nil {
+		return "", errors.Wrap(err, "failed to write bundle")
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	// same format as sha256sum, so it can also be verified with sha256sum -c
+	checksumLine := fmt.Sprintf("%s  %s\n", sum, filepath.Base(bundlePath))
+	if err := os.WriteFile(bundlePath+ChecksumExtension, []byte(checksumLine), os.ModePerm); err != nil {
+		return "", errors.Wrap(err, "failed to write bundle checksum")
+	}
+	return sum, nil
+}
+
+// bundleSources returns the files of the bundle by their name in the bundle
+func bundleSources(opts *Options) (map[string]string, error) {
+	sources := make(map[string]string)
+	queriesPath := filepath.Clean(opts.QueriesPath)
+	// <assets>/libraries/common/library.rego
+	librariesPath := filepath.Dir(filepath.Dir(source.GetPathToLibrary("common", queriesPath)))
+	dirs := map[string]string{queriesDir: queriesPath, librariesDir: librariesPath}
+	if opts.CacheDir != "" {
+		dirs[cacheDir] = opts.CacheDir
+	}
+	for name, dir := range dirs {
+		if err := addDir(sources, name, dir); err != nil {
+			return nil, err
+		}
+	}
+	if manifest := filepath.Join(filepath.Dir(librariesPath), source.ManifestFileName); fileExists(manifest) {
+		sources[manifestFile] = manifest
+	}
+	if opts.ConfigFile != "" {
+		if !fileExists(opts.ConfigFile) {
+			return nil, fmt.Errorf("configuration file %s not found", opts.ConfigFile)
+		}
+		sources[configFile] = opts.ConfigFile
+	}
+	return sources, nil
+}
+
+func addDir(sources map[string]string, name, dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return errors.Wrapf(err, "failed to add %s to the bundle", dir)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		sources[name+"/"+filepath.ToSlash(rel)] = path
+		return nil
+	})
+}
+
+func writeFile(tw *tar.Writer, name string, content []byte) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+	if err := tw.WriteHeader(header); err != nil {
+		return errors.Wrapf(err, "failed to write %s to the bundle", name)
+	}
+	if _, err := tw.Write(content); err != nil {
+		return errors.Wrapf(err, "failed to write %s to the bundle", name)
+	}
+	return nil
+}
+
+// Load verifies the bundle and extracts it to dir, replacing its contents, the checksum of the bundle is the one given,
+// or the one of the checksum file next to it, and bundles without checksum are refused,
+// every file extracted must be in the index of the bundle with the same checksum
+func Load(bundlePath, expectedChecksum, dir string) (*Bundle, error) {
+	content, err := os.ReadFile(filepath.Clean(bundlePath))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read bundle")
+	}
+	if expectedChecksum == "" {
+		checksumContent, err := os.ReadFile(filepath.Clean(bundlePath + ChecksumExtension))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read the checksum of the bundle")
+		}
+		if fields := strings.Fields(string(checksumContent)); len(fields) > 0 {
+			expectedChecksum = fields[0]
+		}
+	}
+	if sum := checksum(content); !strings.EqualFold(sum, expectedChecksum) {
+		return nil, fmt.Errorf("bundle checksum mismatch: expected %s, got %s", expectedChecksum, sum)
+	}
+
+	files, err := readFiles(content)
+	if err != nil {
+		return nil, err
+	}
+	index := Index{}
+	if err := json.Unmarshal(files[IndexFileName], &index); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal bundle index")
+	}
+	delete(files, IndexFileName)
+	if len(files) != len(index.Files) {
+		return nil, fmt.Errorf("bundle has %d files, its index lists %d", len(files), len(index.Files))
+	}
+
+	for name, fileContent := range files {
+		if sum, ok := index.Files[name]; !ok || sum != checksum(fileContent) {
+			return nil, fmt.Errorf("bundle file %s does not match the bundle index", name)
+		}
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, errors.Wrap(err, "failed to clean bundle directory")
+	}
+	for name, fileContent := range files {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+			return nil, errors.Wrap(err, "failed to extract bundle")
+		}
+		if err := os.WriteFile(target, fileContent, 0600); err != nil {
+			return nil, errors.Wrap(err, "failed to extract bundle")
+		}
+	}
+
+	bundle := &Bundle{Index: index, QueriesPath: filepath.Join(dir, filepath.FromSlash(queriesDir))}
+	if _, ok := files[configFile]; ok {
+		bundle.ConfigFile = filepath.Join(dir, filepath.FromSlash(configFile))
+	}
+	for name := range files {
+		if strings.HasPrefix(name, cacheDir+"/") {
+			bundle.CacheDir = filepath.Join(dir, cacheDir)
+			break
+		}
+	}
+	return bundle, nil
+}
+
+// readFiles returns the contents of the files of the bundle archive by name,
+// names must be relative paths within the bundle
+func readFiles(content []byte) (map[string][]byte, error) {
+	gr, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read bundle")
+	}
+	tr := tar.NewReader(gr)
+	files := make(map[string][]byte)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read bundle")
+		}
+		name := header.Name
+		if header.Typeflag != tar.TypeReg || filepath.IsAbs(name) || strings.HasPrefix(filepath.Clean(name), "..") {
+			return nil, fmt.Errorf("bundle entry %s is not a file within the bundle", name)
+		}
+		fileContent, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read bundle")
+		}
+		files[name] = fileContent
+	}
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
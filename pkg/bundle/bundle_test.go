@@ -0,0 +1,114 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+}
+
+// TestBundle tests the functions [Create(), Load()] and all the methods called by them
+func TestBundle(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"assets/queries/dockerfile/query/query.rego":    "package Cx",
+		"assets/queries/dockerfile/query/metadata.json": "{}",
+		"assets/libraries/common/library.rego":          "package generic.common",
+		"assets/manifest.json":                          `{"name": "kics"}`,
+		"kics.config":                                   `{"verbose": true}`,
+		"cache/modules/module.tf":                       `resource "aws_s3_bucket" "b" {}`,
+	})
+	bundlePath := filepath.Join(dir, "kics-bundle.tar.gz")
+
+	sum, err := Create(bundlePath, &Options{
+		QueriesPath: filepath.Join(dir, "assets", "queries"),
+		ConfigFile:  filepath.Join(dir, "kics.config"),
+		CacheDir:    filepath.Join(dir, "cache"),
+		Version:     "1.3.0",
+	})
+	require.NoError(t, err)
+	checksumContent, err := os.ReadFile(bundlePath + ChecksumExtension)
+	require.NoError(t, err)
+	require.Equal(t, sum+"  kics-bundle.tar.gz\n", string(checksumContent))
+
+	extractDir := filepath.Join(dir, "extracted")
+	bundle, err := Load(bundlePath, "", extractDir)
+	require.NoError(t, err)
+	require.Equal(t, "1.3.0", bundle.Index.Version)
+	require.Len(t, bundle.Index.Files, 6)
+	require.Equal(t, filepath.Join(extractDir, "assets", "queries"), bundle.QueriesPath)
+	require.Equal(t, filepath.Join(extractDir, "config", "kics.config"), bundle.ConfigFile)
+	require.Equal(t, filepath.Join(extractDir, "cache"), bundle.CacheDir)
+	for _, file := range []string{
+		"assets/queries/dockerfile/query/query.rego",
+		"assets/libraries/common/library.rego",
+		"assets/manifest.json",
+		"cache/modules/module.tf",
+	} {
+		require.FileExists(t, filepath.Join(extractDir, filepath.FromSlash(file)))
+	}
+
+	_, err = Load(bundlePath, "0000", extractDir)
+	require.Error(t, err)
+}
+
+// TestLoad_Tampered tests the functions [Load()] with bundles whose files don't match their index
+func TestLoad_Tampered(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			name: "modified file",
+			files: map[string]string{
+				IndexFileName:               `{"files": {"assets/queries/query.rego": "0000"}}`,
+				"assets/queries/query.rego": "package Cx",
+			},
+		},
+		{
+			name: "file not in index",
+			files: map[string]string{
+				IndexFileName:               `{"files": {}}`,
+				"assets/queries/query.rego": "package Cx",
+			},
+		},
+		{
+			name: "file outside of the bundle",
+			files: map[string]string{
+				IndexFileName:   `{"files": {"../query.rego": "0000"}}`,
+				"../query.rego": "package Cx",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			gw := gzip.NewWriter(buf)
+			tw := tar.NewWriter(gw)
+			for name, content := range tt.files {
+				require.NoError(t, writeFile(tw, name, []byte(content)))
+			}
+			require.NoError(t, tw.Close())
+			require.NoError(t, gw.Close())
+
+			dir := t.TempDir()
+			bundlePath := filepath.Join(dir, "bundle.tar.gz")
+			require.NoError(t, os.WriteFile(bundlePath, buf.Bytes(), 0600))
+			_, err := Load(bundlePath, checksum(buf.Bytes()), filepath.Join(dir, "extracted"))
+			require.Error(t, err)
+			require.NoDirExists(t, filepath.Join(dir, "extracted"))
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// Package discovery finds the independent IaC projects of a tree, such as the root modules of Terraform, the roots
+// of Helm charts and the directories of stacks, so the results of a monorepo can be grouped by project
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// Kinds of the projects discovered
+const (
+	KindTerraform  = "terraform"
+	KindTerragrunt = "terragrunt"
+	KindHelm       = "helm"
+	KindKustomize  = "kustomize"
+	KindPulumi     = "pulumi"
+	KindServerless = "serverless"
+	KindCDK        = "cdk"
+	KindSAM        = "sam"
+)
+
+// stackFiles are the files marking the directory of a stack, by kind
+var stackFiles = map[string]string{
+	"terragrunt.hcl":  KindTerragrunt,
+	"Pulumi.yaml":     KindPulumi,
+	"Pulumi.yml":      KindPulumi,
+	"serverless.yml":  KindServerless,
+	"serverless.yaml": KindServerless,
+	"cdk.json":        KindCDK,
+	"samconfig.toml":  KindSAM,
+}
+
+var kustomizationFiles = map[string]bool{"kustomization.yaml": true, "kustomization.yml": true, "Kustomization": true}
+
+// localModuleRegex matches the local sources of the modules called by a Terraform module
+var localModuleRegex = regexp.MustCompile(`(?m)^\s*source\s*=\s*"(\.\.?/[^"]*)"`)
+
+// candidate is a directory that may be a project
+type candidate struct {
+	kind string
+}
+
+// Discover walks the root and returns its projects, sorted by path relative to the root: the directories of stacks,
+// the roots of Helm charts, with their subcharts, the directories of kustomizations and the directories with Terraform
+// files, Terraform modules and kustomizations used by others are not projects of their own, hidden directories are skipped
+func Discover(root string) ([]model.DiscoveredProject, error) {
+	root = filepath.Clean(root)
+	info, err := os.Stat(root)
+	if err != nil || !info.IsDir() {
+		return []model.DiscoveredProject{}, nil
+	}
+	candidates := make(map[string]*candidate)
+	referenced := make(map[string]bool)
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return walkDir(path, root, info, candidates)
+		}
+		dir := filepath.Dir(path)
+		if kind, ok := stackFiles[info.Name()]; ok {
+			candidates[dir] = &candidate{kind: kind}
+		}
+		switch {
+		case filepath.Ext(path) == ".tf":
+			if _, ok := candidates[dir]; !ok {
+				candidates[dir] = &candidate{kind: KindTerraform}
+			}
+			return addReferences(path, dir, localTerraformModules, referenced)
+		case kustomizationFiles[info.Name()]:
+			if c, ok := candidates[dir]; !ok || c.kind == KindTerraform {
+				candidates[dir] = &candidate{kind: KindKustomize}
+			}
+			return addReferences(path, dir, kustomizationResources, referenced)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to discover projects")
+	}
+
+	projects := make([]model.DiscoveredProject, 0, len(candidates))
+	for dir, c := range candidates {
+		if referenced[dir] && (c.kind == KindTerraform || c.kind == KindKustomize) {
+			continue
+		}
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			continue
+		}
+		projects = append(projects, model.DiscoveredProject{Path: filepath.ToSlash(rel), Kind: c.kind})
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].Path < projects[j].Path
+	})
+	log.Debug().Msgf("%d projects discovered in %s", len(projects), root)
+	return projects, nil
+}
+
+// walkDir skips hidden directories and the directories of dependencies, charts are projects with all their files
+func walkDir(path, root string, info os.FileInfo, candidates map[string]*candidate) error {
+	if path != root && (strings.HasPrefix(info.Name(), ".") || info.Name() == "node_modules") {
+		return filepath.SkipDir
+	}
+	if _, err := os.Stat(filepath.Join(path, "Chart.yaml")); err == nil {
+		candidates[path] = &candidate{kind: KindHelm}
+		return filepath.SkipDir
+	}
+	return nil
+}
+
+// addReferences marks the directories referenced by the file as referenced, relative to its directory
+func addReferences(path, dir string, references func(content []byte) []string, referenced map[string]bool) error {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	for _, reference := range references(content) {
+		referenced[filepath.Join(dir, filepath.FromSlash(reference))] = true
+	}
+	return nil
+}
+
+// localTerraformModules returns the local sources of the modules called by a Terraform file
+func localTerraformModules(content []byte) []string {
+	matches := localModuleRegex.FindAllSubmatch(content, -1)
+	sources := make([]string, 0, len(matches))
+	for _, match := range matches {
+		sources = append(sources, string(match[1]))
+	}
+	return sources
+}
+
+// kustomizationResources returns the local directories used by a kustomization, such as the bases of an overlay
+func kustomizationResources(content []byte) []string {
+	var kustomization struct {
+		Resources  []string `yaml:"resources"`
+		Bases      []string `yaml:"bases"`
+		Components []string `yaml:"components"`
+	}
+	if err := yaml.Unmarshal(content, &kustomization); err != nil {
+		return nil
+	}
+	resources := make([]string, 0)
+	for _, resource := range append(append(kustomization.Resources, kustomization.Bases...), kustomization.Components...) {
+		if !strings.Contains(resource, "://") && filepath.Ext(resource) == "" {
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
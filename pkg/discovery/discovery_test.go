@@ -0,0 +1,55 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDiscover tests the functions [Discover()] and all the methods called by them
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"envs/prod/main.tf":                        "module \"vpc\" {\n  source = \"../../modules/vpc\"\n}\n",
+		"envs/dev/main.tf":                         "module \"vpc\" {\n  source = \"../../modules/vpc\"\n}\n",
+		"modules/vpc/main.tf":                      "resource \"aws_vpc\" \"main\" {}\n",
+		"modules/unused/main.tf":                   "resource \"aws_s3_bucket\" \"b\" {}\n",
+		"charts/api/Chart.yaml":                    "name: api\n",
+		"charts/api/charts/redis/Chart.yaml":       "name: redis\n",
+		"charts/api/templates/deployment.yaml":     "kind: Deployment\n",
+		"k8s/base/kustomization.yaml":              "resources:\n  - deployment.yaml\n",
+		"k8s/overlays/prod/kustomization.yaml":     "resources:\n  - ../../base\n  - https://example.com/remote\n",
+		"live/prod/app/terragrunt.hcl":             "terraform {}\n",
+		"live/prod/app/main.tf":                    "",
+		"stacks/network/Pulumi.yaml":               "name: network\n",
+		".terraform/modules/remote/main.tf":        "",
+		"node_modules/package/serverless.yml":      "",
+		"services/payments/serverless.yml":         "service: payments\n",
+		"services/payments/resources/dynamodb.yml": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	projects, err := Discover(root)
+	require.NoError(t, err)
+	require.Equal(t, []model.DiscoveredProject{
+		{Path: "charts/api", Kind: KindHelm},
+		{Path: "envs/dev", Kind: KindTerraform},
+		{Path: "envs/prod", Kind: KindTerraform},
+		{Path: "k8s/overlays/prod", Kind: KindKustomize},
+		{Path: "live/prod/app", Kind: KindTerragrunt},
+		{Path: "modules/unused", Kind: KindTerraform},
+		{Path: "services/payments", Kind: KindServerless},
+		{Path: "stacks/network", Kind: KindPulumi},
+	}, projects)
+
+	projects, err = Discover(filepath.Join(root, "envs/prod/main.tf"))
+	require.NoError(t, err)
+	require.Empty(t, projects)
+}
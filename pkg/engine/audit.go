@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Checkmarx/kics/pkg/model"
+)
+
+// Engines of the queries audited
+const (
+	auditEngineRego  = "rego"
+	auditEngineRegex = "regex"
+	auditEngineCEL   = "cel"
+)
+
+// AuditedEngine wraps the method GetQueriesAudit of the policy engines whose queries are recorded with the scans,
+// so their results can be reproduced and audited
+// GetQueriesAudit returns the queries evaluated by the policy engine and their digests
+type AuditedEngine interface {
+	GetQueriesAudit() []model.QueryAudit
+}
+
+// GetQueriesAudit returns the queries evaluated by all policy engines that record them
+func (e PolicyEngines) GetQueriesAudit() []model.QueryAudit {
+	var queries []model.QueryAudit
+	for _, policyEngine := range e {
+		if audited, ok := policyEngine.(AuditedEngine); ok {
+			queries = append(queries, audited.GetQueriesAudit()...)
+		}
+	}
+	return queries
+}
+
+// GetQueriesAudit returns the rego queries of the inspector, their digests include the query libraries they use
+func (c *Inspector) GetQueriesAudit() []model.QueryAudit {
+	queries := make([]model.QueryAudit, 0, len(c.queries))
+	for _, query := range c.queries {
+		queries = append(queries, auditQuery(query, auditEngineRego, query.digest))
+	}
+	return queries
+}
+
+// GetQueriesAudit returns the regex queries of the inspector
+func (c *RegexInspector) GetQueriesAudit() []model.QueryAudit {
+	queries := make([]model.QueryAudit, 0, len(c.queries))
+	for _, query := range c.queries {
+		content, _ := json.Marshal(query.query)
+		queries = append(queries, auditQuery(query.prepared, auditEngineRegex, queryDigest(string(content))))
+	}
+	return queries
+}
+
+// GetQueriesAudit returns the CEL policies of the inspector
+func (c *CELInspector) GetQueriesAudit() []model.QueryAudit {
+	queries := make([]model.QueryAudit, 0, len(c.policies))
+	for _, policy := range c.policies {
+		content, _ := json.Marshal(policy.policy)
+		queries = append(queries, auditQuery(policy.query, auditEngineCEL, queryDigest(string(content))))
+	}
+	return queries
+}
+
+// auditQuery returns the audit of the query, the metadata of the query, as overridden, is added to the digest
+// of its content
+func auditQuery(query *preparedQuery, engine, contentDigest string) model.QueryAudit {
+	metadata, _ := json.Marshal(query.metadata.Metadata)
+	return model.QueryAudit{
+		ID:       metadataString(query.metadata.Metadata, "id", query.metadata.Query),
+		Name:     metadataString(query.metadata.Metadata, "queryName", ""),
+		Platform: query.metadata.Platform,
+		Engine:   engine,
+		Digest:   queryDigest(contentDigest, string(metadata)),
+	}
+}
+
+// queryDigest returns the SHA-256 of the parts of a query, each part is prefixed with its length,
+// so moving content between parts changes the digest
+func queryDigest(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		_, _ = fmt.Fprintf(hash, "%d:%s", len(part), part)
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+func metadataString(metadata map[string]interface{}, key, defaultValue string) string {
+	if value, ok := metadata[key].(string); ok && value != "" {
+		return value
+	}
+	return defaultValue
+}
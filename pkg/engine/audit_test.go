@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Checkmarx/kics/internal/tracker"
+	"github.com/Checkmarx/kics/pkg/engine/provider"
+	"github.com/Checkmarx/kics/pkg/engine/source"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/test"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPolicyEngines_GetQueriesAudit tests the functions [GetQueriesAudit()] and all the methods called by them
+func TestPolicyEngines_GetQueriesAudit(t *testing.T) {
+	if err := test.ChangeCurrentDir("kics"); err != nil {
+		t.Fatal(err)
+	}
+	sourceProvider, err := provider.NewFileSystemSourceProvider(filepath.FromSlash("test/fixtures/test_regex_queries/src"), []string{})
+	require.NoError(t, err)
+	newInspector := func() *RegexInspector {
+		inspector, err := NewRegexInspector(
+			filepath.FromSlash("test/fixtures/test_regex_queries/queries.yaml"),
+			sourceProvider,
+			DefaultVulnerabilityBuilder,
+			&tracker.CITracker{},
+			source.ExcludeQueries{},
+			map[string]bool{},
+		)
+		require.NoError(t, err)
+		return inspector
+	}
+
+	inspector := newInspector()
+	policyEngines := PolicyEngines{inspector, &DryRun{}}
+	queries := policyEngines.GetQueriesAudit()
+	require.Len(t, queries, 2)
+	for _, query := range queries {
+		require.Equal(t, auditEngineRegex, query.Engine)
+		require.NotEmpty(t, query.ID)
+		require.NotEmpty(t, query.Name)
+		require.True(t, strings.HasPrefix(query.Digest, "sha256:"))
+	}
+	require.Equal(t, queries, newInspector().GetQueriesAudit(), "the digests of the same queries are stable")
+
+	// overriding the metadata of a query changes its digest
+	inspector.queries[0].prepared.metadata.Metadata["severity"] = string(model.SeverityLow)
+	overridden := inspector.GetQueriesAudit()
+	require.NotEqual(t, queries[0].Digest, overridden[0].Digest)
+	require.Equal(t, queries[1].Digest, overridden[1].Digest)
+}
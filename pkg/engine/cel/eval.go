@@ -0,0 +1,665 @@
+package cel
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Program is a compiled CEL expression
+type Program struct {
+	expression string
+	root       node
+}
+
+// Compile parses a CEL expression, only the subset of CEL needed by attribute policies is supported:
+// literals, lists, maps, field selection, indexing, logical, relational and arithmetic operators,
+// the conditional operator, the has, all, exists, exists_one, filter and map macros and the functions
+// size, contains, startsWith, endsWith, matches, lowerAscii, upperAscii, int, double and string
+func Compile(expression string) (*Program, error) {
+	root, err := parse(expression)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to compile CEL expression %q", expression)
+	}
+	return &Program{expression: expression, root: root}, nil
+}
+
+// Eval evaluates the program using the variables given
+func (p *Program) Eval(vars map[string]interface{}) (interface{}, error) {
+	return eval(p.root, vars)
+}
+
+// EvalBool evaluates the program using the variables given, the result must be a bool
+func (p *Program) EvalBool(vars map[string]interface{}) (bool, error) {
+	value, err := p.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, errors.Errorf("expression %q returned %T instead of bool", p.expression, value)
+	}
+	return result, nil
+}
+
+func eval(n node, vars map[string]interface{}) (interface{}, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		return n.value, nil
+	case *identNode:
+		value, ok := vars[n.name]
+		if !ok {
+			return nil, errors.Errorf("undeclared reference to '%s'", n.name)
+		}
+		return normalize(value), nil
+	case *memberNode:
+		operand, err := eval(n.operand, vars)
+		if err != nil {
+			return nil, err
+		}
+		return selectField(operand, n.field)
+	case *indexNode:
+		return evalIndex(n, vars)
+	case *callNode:
+		return evalCall(n, vars)
+	case *unaryNode:
+		return evalUnary(n, vars)
+	case *binaryNode:
+		return evalBinary(n, vars)
+	case *conditionalNode:
+		condition, err := evalBoolNode(n.condition, vars)
+		if err != nil {
+			return nil, err
+		}
+		if condition {
+			return eval(n.then, vars)
+		}
+		return eval(n.otherwise, vars)
+	case *listNode:
+		list := make([]interface{}, 0, len(n.elements))
+		for _, element := range n.elements {
+			value, err := eval(element, vars)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return list, nil
+	case *mapNode:
+		return evalMap(n, vars)
+	default:
+		return nil, errors.Errorf("unsupported expression %T", n)
+	}
+}
+
+func evalBoolNode(n node, vars map[string]interface{}) (bool, error) {
+	value, err := eval(n, vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, errors.Errorf("no matching overload, expected bool but found %s", typeName(value))
+	}
+	return b, nil
+}
+
+func evalMap(n *mapNode, vars map[string]interface{}) (interface{}, error) {
+	m := make(map[string]interface{}, len(n.keys))
+	for i := range n.keys {
+		key, err := eval(n.keys[i], vars)
+		if err != nil {
+			return nil, err
+		}
+		keyString, ok := key.(string)
+		if !ok {
+			return nil, errors.Errorf("unsupported map key type %s", typeName(key))
+		}
+		value, err := eval(n.values[i], vars)
+		if err != nil {
+			return nil, err
+		}
+		m[keyString] = value
+	}
+	return m, nil
+}
+
+// normalize converts the values of documents to the types used during the evaluation:
+// float64 for numbers, []interface{} for lists and map[string]interface{} for objects
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, bool, string, float64, []interface{}, map[string]interface{}:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case float32:
+		return float64(v)
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return value
+		}
+		m := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = iter.Value().Interface()
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		list := make([]interface{}, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			list[i] = rv.Index(i).Interface()
+		}
+		return list
+	case reflect.String:
+		return rv.String()
+	default:
+		return value
+	}
+}
+
+func selectField(operand interface{}, field string) (interface{}, error) {
+	m, ok := operand.(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("type '%s' does not support field selection", typeName(operand))
+	}
+	value, ok := m[field]
+	if !ok {
+		return nil, errors.Errorf("no such key: %s", field)
+	}
+	return normalize(value), nil
+}
+
+func evalIndex(n *indexNode, vars map[string]interface{}) (interface{}, error) {
+	operand, err := eval(n.operand, vars)
+	if err != nil {
+		return nil, err
+	}
+	index, err := eval(n.index, vars)
+	if err != nil {
+		return nil, err
+	}
+	switch o := operand.(type) {
+	case []interface{}:
+		i, ok := index.(float64)
+		if !ok || i != math.Trunc(i) {
+			return nil, errors.Errorf("invalid list index %v", index)
+		}
+		if i < 0 || int(i) >= len(o) {
+			return nil, errors.Errorf("index out of range: %v", index)
+		}
+		return normalize(o[int(i)]), nil
+	case map[string]interface{}:
+		key, ok := index.(string)
+		if !ok {
+			return nil, errors.Errorf("invalid map key %v", index)
+		}
+		return selectField(o, key)
+	default:
+		return nil, errors.Errorf("type '%s' does not support indexing", typeName(operand))
+	}
+}
+
+func evalUnary(n *unaryNode, vars map[string]interface{}) (interface{}, error) {
+	operand, err := eval(n.operand, vars)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "!":
+		if b, ok := operand.(bool); ok {
+			return !b, nil
+		}
+	case "-":
+		if f, ok := operand.(float64); ok {
+			return -f, nil
+		}
+	}
+	return nil, errors.Errorf("no matching overload for '%s' applied to %s", n.op, typeName(operand))
+}
+
+func evalBinary(n *binaryNode, vars map[string]interface{}) (interface{}, error) {
+	if n.op == "&&" || n.op == "||" {
+		return evalLogical(n, vars)
+	}
+	left, err := eval(n.left, vars)
+	if err != nil {
+		return nil, err
+	}
+	right, err := eval(n.right, vars)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return equals(left, right), nil
+	case "!=":
+		return !equals(left, right), nil
+	case "in":
+		return evalIn(left, right)
+	case "<", "<=", ">", ">=":
+		return compare(n.op, left, right)
+	default:
+		return arithmetic(n.op, left, right)
+	}
+}
+
+// evalLogical evaluates && and || commutatively as CEL does, an error on one side is ignored
+// when the other side alone decides the result
+func evalLogical(n *binaryNode, vars map[string]interface{}) (interface{}, error) {
+	decisive := n.op == "||"
+	left, leftErr := evalBoolNode(n.left, vars)
+	if leftErr == nil && left == decisive {
+		return decisive, nil
+	}
+	right, rightErr := evalBoolNode(n.right, vars)
+	if rightErr == nil && right == decisive {
+		return decisive, nil
+	}
+	if leftErr != nil {
+		return nil, leftErr
+	}
+	if rightErr != nil {
+		return nil, rightErr
+	}
+	return !decisive, nil
+}
+
+func evalIn(element, container interface{}) (interface{}, error) {
+	switch c := container.(type) {
+	case []interface{}:
+		for _, item := range c {
+			if equals(element, normalize(item)) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]interface{}:
+		key, ok := element.(string)
+		if !ok {
+			return false, nil
+		}
+		_, found := c[key]
+		return found, nil
+	default:
+		return nil, errors.Errorf("no matching overload for 'in' applied to %s", typeName(container))
+	}
+}
+
+func equals(left, right interface{}) bool {
+	left, right = normalize(left), normalize(right)
+	switch l := left.(type) {
+	case []interface{}:
+		r, ok := right.([]interface{})
+		if !ok || len(l) != len(r) {
+			return false
+		}
+		for i := range l {
+			if !equals(l[i], r[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		r, ok := right.(map[string]interface{})
+		if !ok || len(l) != len(r) {
+			return false
+		}
+		for key, value := range l {
+			other, found := r[key]
+			if !found || !equals(value, other) {
+				return false
+			}
+		}
+		return true
+	default:
+		return left == right
+	}
+}
+
+func compare(op string, left, right interface{}) (interface{}, error) {
+	var cmp int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return nil, errors.Errorf("no matching overload for '%s' applied to (%s, %s)", op, typeName(left), typeName(right))
+		}
+		cmp = compareFloats(l, r)
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return nil, errors.Errorf("no matching overload for '%s' applied to (%s, %s)", op, typeName(left), typeName(right))
+		}
+		cmp = strings.Compare(l, r)
+	default:
+		return nil, errors.Errorf("no matching overload for '%s' applied to (%s, %s)", op, typeName(left), typeName(right))
+	}
+	switch op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+func compareFloats(l, r float64) int {
+	switch {
+	case l < r:
+		return -1
+	case l > r:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func arithmetic(op string, left, right interface{}) (interface{}, error) {
+	switch l := left.(type) {
+	case float64:
+		if r, ok := right.(float64); ok {
+			return arithmeticFloats(op, l, r)
+		}
+	case string:
+		if r, ok := right.(string); ok && op == "+" {
+			return l + r, nil
+		}
+	case []interface{}:
+		if r, ok := right.([]interface{}); ok && op == "+" {
+			return append(append(make([]interface{}, 0, len(l)+len(r)), l...), r...), nil
+		}
+	}
+	return nil, errors.Errorf("no matching overload for '%s' applied to (%s, %s)", op, typeName(left), typeName(right))
+}
+
+func arithmeticFloats(op string, l, r float64) (interface{}, error) {
+	switch op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return l / r, nil
+	default:
+		if r == 0 {
+			return nil, errors.New("modulus by zero")
+		}
+		return math.Mod(l, r), nil
+	}
+}
+
+func evalCall(n *callNode, vars map[string]interface{}) (interface{}, error) {
+	if n.target == nil && n.function == "has" {
+		return evalHas(n, vars)
+	}
+	if n.target != nil && isMacro(n.function) {
+		return evalMacro(n, vars)
+	}
+
+	args := make([]interface{}, 0, len(n.args)+1)
+	if n.target != nil {
+		target, err := eval(n.target, vars)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, target)
+	}
+	for _, arg := range n.args {
+		value, err := eval(arg, vars)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+	return callFunction(n.function, args)
+}
+
+// evalHas evaluates the has macro, which tests if a field is present without failing when it is not
+func evalHas(n *callNode, vars map[string]interface{}) (interface{}, error) {
+	if len(n.args) != 1 {
+		return nil, errors.New("has() requires a single field selection argument")
+	}
+	member, ok := n.args[0].(*memberNode)
+	if !ok {
+		return nil, errors.New("has() requires a field selection argument")
+	}
+	operand, err := eval(member.operand, vars)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := operand.(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("invalid has() on type '%s' in %v", typeName(operand), member)
+	}
+	_, found := m[member.field]
+	return found, nil
+}
+
+func isMacro(function string) bool {
+	switch function {
+	case "all", "exists", "exists_one", "filter", "map":
+		return true
+	default:
+		return false
+	}
+}
+
+// evalMacro evaluates the comprehension macros over the elements of a list or the keys of a map
+func evalMacro(n *callNode, vars map[string]interface{}) (interface{}, error) {
+	if len(n.args) != 2 {
+		return nil, errors.Errorf("%s() requires a variable and an expression", n.function)
+	}
+	variable, ok := n.args[0].(*identNode)
+	if !ok {
+		return nil, errors.Errorf("%s() requires a variable name as first argument", n.function)
+	}
+	target, err := eval(n.target, vars)
+	if err != nil {
+		return nil, err
+	}
+	elements, err := iterationElements(target)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := make(map[string]interface{}, len(vars)+1)
+	for name, value := range vars {
+		scope[name] = value
+	}
+	results := make([]interface{}, 0, len(elements))
+	matches := 0
+	for _, element := range elements {
+		scope[variable.name] = element
+		value, err := eval(n.args[1], scope)
+		if err != nil {
+			return nil, err
+		}
+		if n.function == "map" {
+			results = append(results, value)
+			continue
+		}
+		b, ok := value.(bool)
+		if !ok {
+			return nil, errors.Errorf("%s() predicate returned %s instead of bool", n.function, typeName(value))
+		}
+		if b {
+			matches++
+			results = append(results, element)
+		}
+	}
+
+	switch n.function {
+	case "all":
+		return matches == len(elements), nil
+	case "exists":
+		return matches > 0, nil
+	case "exists_one":
+		return matches == 1, nil
+	default:
+		return results, nil
+	}
+}
+
+func iterationElements(target interface{}) ([]interface{}, error) {
+	switch t := target.(type) {
+	case []interface{}:
+		elements := make([]interface{}, len(t))
+		for i := range t {
+			elements[i] = normalize(t[i])
+		}
+		return elements, nil
+	case map[string]interface{}:
+		elements := make([]interface{}, 0, len(t))
+		for key := range t {
+			elements = append(elements, key)
+		}
+		return elements, nil
+	default:
+		return nil, errors.Errorf("type '%s' does not support iteration", typeName(target))
+	}
+}
+
+func callFunction(function string, args []interface{}) (interface{}, error) {
+	switch function {
+	case "size":
+		if len(args) == 1 {
+			return size(args[0])
+		}
+	case "contains", "startsWith", "endsWith", "matches":
+		if len(args) == 2 {
+			return stringPredicate(function, args[0], args[1])
+		}
+	case "lowerAscii", "upperAscii":
+		if s, ok := firstString(args); ok && len(args) == 1 {
+			if function == "lowerAscii" {
+				return strings.ToLower(s), nil
+			}
+			return strings.ToUpper(s), nil
+		}
+	case "int", "double", "string":
+		if len(args) == 1 {
+			return convert(function, args[0])
+		}
+	default:
+		return nil, errors.Errorf("undeclared reference to function '%s'", function)
+	}
+	return nil, errors.Errorf("no matching overload for '%s'", function)
+}
+
+func firstString(args []interface{}) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+	s, ok := args[0].(string)
+	return s, ok
+}
+
+func size(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return float64(utf8.RuneCountInString(v)), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	case map[string]interface{}:
+		return float64(len(v)), nil
+	default:
+		return nil, errors.Errorf("no matching overload for 'size' applied to %s", typeName(value))
+	}
+}
+
+func stringPredicate(function string, target, arg interface{}) (interface{}, error) {
+	s, ok := target.(string)
+	if !ok {
+		return nil, errors.Errorf("no matching overload for '%s' applied to %s", function, typeName(target))
+	}
+	a, ok := arg.(string)
+	if !ok {
+		return nil, errors.Errorf("no matching overload for '%s' with argument %s", function, typeName(arg))
+	}
+	switch function {
+	case "contains":
+		return strings.Contains(s, a), nil
+	case "startsWith":
+		return strings.HasPrefix(s, a), nil
+	case "endsWith":
+		return strings.HasSuffix(s, a), nil
+	default:
+		re, err := regexp.Compile(a)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid regular expression %q", a)
+		}
+		return re.MatchString(s), nil
+	}
+}
+
+func convert(function string, value interface{}) (interface{}, error) {
+	switch function {
+	case "string":
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+	case "int", "double":
+		switch v := value.(type) {
+		case float64:
+			if function == "int" {
+				return math.Trunc(v), nil
+			}
+			return v, nil
+		case string:
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, errors.Errorf("%s() failed to convert %q", function, v)
+			}
+			if function == "int" {
+				return math.Trunc(f), nil
+			}
+			return f, nil
+		}
+	}
+	return nil, errors.Errorf("no matching overload for '%s' applied to %s", function, typeName(value))
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null_type"
+	case bool:
+		return "bool"
+	case float64:
+		return "double"
+	case string:
+		return "string"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "map"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -0,0 +1,122 @@
+package cel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestProgram_Eval tests the functions [Compile(), Eval()] and all the methods called by them
+func TestProgram_Eval(t *testing.T) { //nolint
+	type document map[string]interface{}
+	vars := map[string]interface{}{
+		"object": document{
+			"kind": "Deployment",
+			"metadata": map[string]interface{}{
+				"name":   "web",
+				"labels": map[string]interface{}{"app": "web", "team": "payments"},
+			},
+			"spec": map[string]interface{}{
+				"replicas": 3,
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{"name": "web", "image": "nginx:1.19", "ports": []interface{}{80.0, 443.0}},
+							map[string]interface{}{"name": "sidecar", "image": "envoy:latest"},
+						},
+					},
+				},
+			},
+		},
+		"names": []string{"web", "api"},
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		want       interface{}
+		wantErr    bool
+	}{
+		{name: "equality", expression: `object.kind == "Deployment"`, want: true},
+		{name: "single_quotes", expression: `object.metadata.name != 'api'`, want: true},
+		{name: "relational", expression: `object.spec.replicas >= 2 && object.spec.replicas < 5`, want: true},
+		{name: "arithmetic", expression: `object.spec.replicas * 2 + 1 - 4 / 2 % 3`, want: 5.0},
+		{name: "unary", expression: `!(object.spec.replicas > 1) || -object.spec.replicas == -3`, want: true},
+		{name: "index", expression: `object.spec.template.spec.containers[1].name`, want: "sidecar"},
+		{name: "map_index", expression: `object.metadata.labels["team"]`, want: "payments"},
+		{name: "has", expression: `has(object.metadata.labels) && !has(object.metadata.annotations)`, want: true},
+		{name: "in_list", expression: `object.metadata.name in names`, want: true},
+		{name: "in_map", expression: `"app" in object.metadata.labels`, want: true},
+		{name: "size", expression: `size(object.spec.template.spec.containers) == 2 && names.size() == 2`, want: true},
+		{
+			name:       "all",
+			expression: `object.spec.template.spec.containers.all(c, !c.image.endsWith(":latest"))`,
+			want:       false,
+		},
+		{
+			name:       "exists",
+			expression: `object.spec.template.spec.containers.exists(c, has(c.ports) && 443 in c.ports)`,
+			want:       true,
+		},
+		{name: "exists_one", expression: `object.metadata.labels.exists_one(k, k.startsWith("te"))`, want: true},
+		{name: "filter", expression: `object.spec.template.spec.containers.filter(c, c.name == "web").size()`, want: 1.0},
+		{name: "map", expression: `object.spec.template.spec.containers.map(c, c.name)`, want: []interface{}{"web", "sidecar"}},
+		{name: "matches", expression: `object.spec.template.spec.containers[0].image.matches("^nginx:[0-9.]+$")`, want: true},
+		{name: "string_functions", expression: `object.kind.lowerAscii().contains("deploy")`, want: true},
+		{name: "conversions", expression: `string(object.spec.replicas) + "/" + string(int("4.7"))`, want: "3/4"},
+		{name: "conditional", expression: `object.spec.replicas > 5 ? "large" : "small"`, want: "small"},
+		{name: "list_literal", expression: `[1, 2] + [3] == [1, 2, 3]`, want: true},
+		{name: "map_literal", expression: `{"app": "web", "team": "payments"} == object.metadata.labels`, want: true},
+		{name: "or_absorbs_error", expression: `object.metadata.missing == "x" || true`, want: true},
+		{name: "and_absorbs_error", expression: `false && object.metadata.missing == "x"`, want: false},
+		{name: "null", expression: `object.kind != null`, want: true},
+		{name: "missing_field", expression: `object.metadata.missing == "x"`, wantErr: true},
+		{name: "undeclared_variable", expression: `other.kind`, wantErr: true},
+		{name: "unknown_function", expression: `object.kind.reverse()`, wantErr: true},
+		{name: "invalid_comparison", expression: `object.kind > 1`, wantErr: true},
+		{name: "index_out_of_range", expression: `names[2]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.expression)
+			require.NoError(t, err)
+			got, err := program.Eval(vars)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestCompile tests the functions [Compile()] with invalid expressions
+func TestCompile(t *testing.T) {
+	for _, expression := range []string{
+		`object.kind ==`,
+		`object.kind == "Deployment`,
+		`(object.kind == "Deployment"`,
+		`object. == 1`,
+		`object.kind # 1`,
+		`a ? b`,
+	} {
+		_, err := Compile(expression)
+		require.Error(t, err, expression)
+	}
+}
+
+// TestProgram_EvalBool tests the functions [EvalBool()]
+func TestProgram_EvalBool(t *testing.T) {
+	program, err := Compile(`size(name) > 3`)
+	require.NoError(t, err)
+
+	got, err := program.EvalBool(map[string]interface{}{"name": "bucket"})
+	require.NoError(t, err)
+	require.True(t, got)
+
+	program, err = Compile(`name`)
+	require.NoError(t, err)
+	_, err = program.EvalBool(map[string]interface{}{"name": "bucket"})
+	require.Error(t, err)
+}
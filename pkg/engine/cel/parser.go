@@ -0,0 +1,458 @@
+package cel
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// operators are sorted so that longer operators are matched first
+var operators = []string{
+	"==", "!=", "<=", ">=", "&&", "||",
+	"<", ">", "!", "+", "-", "*", "/", "%", "?", ":", ".", ",", "(", ")", "[", "]", "{", "}",
+}
+
+func tokenize(expression string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: string(runes[start:i]), pos: start})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == 'e' || runes[i] == 'E') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: string(runes[start:i]), pos: start})
+		case r == '"' || r == '\'':
+			value, end, err := readString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value, pos: i})
+			i = end
+		default:
+			op := matchOperator(string(runes[i:]))
+			if op == "" {
+				return nil, errors.Errorf("unexpected character %q at position %d", r, i)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, value: op, pos: i})
+			i += len([]rune(op))
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+func matchOperator(rest string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(rest, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// readString reads a quoted string starting at position start and returns its value and the position after it
+func readString(runes []rune, start int) (value string, end int, err error) {
+	quote := runes[start]
+	var sb strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case quote:
+			return sb.String(), i + 1, nil
+		case '\\':
+			i++
+			if i == len(runes) {
+				break
+			}
+			switch runes[i] {
+			case 'n':
+				sb.WriteRune('\n')
+			case 't':
+				sb.WriteRune('\t')
+			case 'r':
+				sb.WriteRune('\r')
+			default:
+				sb.WriteRune(runes[i])
+			}
+		default:
+			sb.WriteRune(runes[i])
+		}
+	}
+	return "", 0, errors.Errorf("unterminated string at position %d", start)
+}
+
+type node interface{}
+
+type literalNode struct {
+	value interface{}
+}
+
+type identNode struct {
+	name string
+}
+
+type memberNode struct {
+	operand node
+	field   string
+}
+
+type indexNode struct {
+	operand node
+	index   node
+}
+
+type callNode struct {
+	target   node
+	function string
+	args     []node
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+type conditionalNode struct {
+	condition, then, otherwise node
+}
+
+type listNode struct {
+	elements []node
+}
+
+type mapNode struct {
+	keys, values []node
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func parse(expression string) (node, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.unexpected()
+	}
+	return root, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOperator(values ...string) bool {
+	t := p.peek()
+	if t.kind != tokenOperator {
+		return false
+	}
+	for _, value := range values {
+		if t.value == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.isOperator(op) {
+		return errors.Errorf("expected %q at position %d", op, p.peek().pos)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	t := p.peek()
+	if t.kind == tokenEOF {
+		return errors.New("unexpected end of expression")
+	}
+	return errors.Errorf("unexpected %q at position %d", t.value, t.pos)
+}
+
+func (p *parser) parseConditional() (node, error) {
+	condition, err := p.parseOr()
+	if err != nil || !p.isOperator("?") {
+		return condition, err
+	}
+	p.next()
+	then, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	return &conditionalNode{condition: condition, then: then, otherwise: otherwise}, nil
+}
+
+// parseBinary parses a left associative binary expression with the operators of one precedence level
+func (p *parser) parseBinary(operand func() (node, error), ops ...string) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator(ops...) || (contains(ops, "in") && p.peek().kind == tokenIdent && p.peek().value == "in") {
+		op := p.next().value
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseOr() (node, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *parser) parseAnd() (node, error) {
+	return p.parseBinary(p.parseRelation, "&&")
+}
+
+func (p *parser) parseRelation() (node, error) {
+	return p.parseBinary(p.parseAddition, "==", "!=", "<", "<=", ">", ">=", "in")
+}
+
+func (p *parser) parseAddition() (node, error) {
+	return p.parseBinary(p.parseMultiplication, "+", "-")
+}
+
+func (p *parser) parseMultiplication() (node, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.isOperator("!", "-") {
+		op := p.next().value
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parseMember()
+}
+
+func (p *parser) parseMember() (node, error) {
+	operand, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.isOperator("."):
+			p.next()
+			field := p.next()
+			if field.kind != tokenIdent {
+				return nil, errors.Errorf("expected field name at position %d", field.pos)
+			}
+			if !p.isOperator("(") {
+				operand = &memberNode{operand: operand, field: field.value}
+				continue
+			}
+			args, err := p.parseArguments()
+			if err != nil {
+				return nil, err
+			}
+			operand = &callNode{target: operand, function: field.value, args: args}
+		case p.isOperator("["):
+			p.next()
+			index, err := p.parseConditional()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			operand = &indexNode{operand: operand, index: index}
+		default:
+			return operand, nil
+		}
+	}
+}
+
+func (p *parser) parseArguments() ([]node, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	args := make([]node, 0)
+	for !p.isOperator(")") {
+		arg, err := p.parseConditional()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if !p.isOperator(",") {
+			break
+		}
+		p.next()
+	}
+	return args, p.expect(")")
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokenNumber:
+		p.next()
+		value, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid number %s at position %d", t.value, t.pos)
+		}
+		return &literalNode{value: value}, nil
+	case tokenString:
+		p.next()
+		return &literalNode{value: t.value}, nil
+	case tokenIdent:
+		return p.parseIdent()
+	case tokenOperator:
+		switch t.value {
+		case "(":
+			p.next()
+			inner, err := p.parseConditional()
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expect(")")
+		case "[":
+			return p.parseList()
+		case "{":
+			return p.parseMap()
+		}
+	}
+	return nil, p.unexpected()
+}
+
+func (p *parser) parseIdent() (node, error) {
+	t := p.next()
+	switch t.value {
+	case "true":
+		return &literalNode{value: true}, nil
+	case "false":
+		return &literalNode{value: false}, nil
+	case "null":
+		return &literalNode{value: nil}, nil
+	}
+	if !p.isOperator("(") {
+		return &identNode{name: t.value}, nil
+	}
+	args, err := p.parseArguments()
+	if err != nil {
+		return nil, err
+	}
+	return &callNode{function: t.value, args: args}, nil
+}
+
+func (p *parser) parseList() (node, error) {
+	p.next()
+	list := &listNode{elements: make([]node, 0)}
+	for !p.isOperator("]") {
+		element, err := p.parseConditional()
+		if err != nil {
+			return nil, err
+		}
+		list.elements = append(list.elements, element)
+		if !p.isOperator(",") {
+			break
+		}
+		p.next()
+	}
+	return list, p.expect("]")
+}
+
+func (p *parser) parseMap() (node, error) {
+	p.next()
+	m := &mapNode{keys: make([]node, 0), values: make([]node, 0)}
+	for !p.isOperator("}") {
+		key, err := p.parseConditional()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		value, err := p.parseConditional()
+		if err != nil {
+			return nil, err
+		}
+		m.keys = append(m.keys, key)
+		m.values = append(m.values, value)
+		if !p.isOperator(",") {
+			break
+		}
+		p.next()
+	}
+	return m, p.expect("}")
+}
+
+func (n *identNode) String() string {
+	return n.name
+}
+
+func (n *memberNode) String() string {
+	return fmt.Sprintf("%v.%s", n.operand, n.field)
+}
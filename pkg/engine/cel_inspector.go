@@ -0,0 +1,410 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/engine/cel"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/getsentry/sentry-go"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// CEL policy platforms
+const (
+	celPlatformTerraform      = "terraform"
+	celPlatformKubernetes     = "k8s"
+	celPlatformCloudFormation = "cloudformation"
+)
+
+// CELPolicy is a policy written as a CEL expression that must hold for every matched resource
+// Match is an optional CEL expression that selects the resources the policy applies to
+// Validation is the CEL expression evaluated on every selected resource, a resource fails the policy
+// when Validation evaluates to false
+type CELPolicy struct {
+	ID              string `yaml:"id" json:"id"`
+	QueryName       string `yaml:"queryName" json:"queryName"`
+	Severity        string `yaml:"severity" json:"severity"`
+	Category        string `yaml:"category" json:"category"`
+	DescriptionText string `yaml:"descriptionText" json:"descriptionText"`
+	DescriptionURL  string `yaml:"descriptionUrl" json:"descriptionUrl"`
+	Platform        string `yaml:"platform" json:"platform"`
+	ResourceType    string `yaml:"resourceType" json:"resourceType"`
+	Match           string `yaml:"match" json:"match"`
+	Validation      string `yaml:"validation" json:"validation"`
+	Message         string `yaml:"message" json:"message"`
+}
+
+type celPolicies struct {
+	Policies []CELPolicy `yaml:"policies" json:"policies"`
+}
+
+type preparedCELPolicy struct {
+	policy     CELPolicy
+	match      *cel.Program
+	validation *cel.Program
+	query      *preparedQuery
+}
+
+// celResource is a resource extracted from a document that CEL policies are evaluated against
+type celResource struct {
+	resourceType string
+	name         string
+	searchKey    string
+	object       interface{}
+}
+
+// CELInspector evaluates CEL policies against the resources of the scanned files
+type CELInspector struct {
+	policies       []*preparedCELPolicy
+	vb             VulnerabilityBuilder
+	tracker        Tracker
+	failedQueries  map[string]error
+	excludeResults map[string]bool
+
+	failFast
+	deadline
+}
+
+// NewCELInspector initializes a CEL inspector, loading and compiling the policies found in policiesPath
+// policiesPath can be a policy file or a directory containing policy files (.yaml, .yml or .json)
+func NewCELInspector(
+	policiesPath string,
+	vb VulnerabilityBuilder,
+	tracker Tracker,
+	excludeResults map[string]bool) (*CELInspector, error) {
+	log.Debug().Msg("engine.NewCELInspector()")
+
+	policies, err := loadCELPolicies(policiesPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load CEL policies")
+	}
+
+	prepared := make([]*preparedCELPolicy, 0, len(policies))
+	for i := range policies {
+		policy, err := prepareCELPolicy(&policies[i])
+		if err != nil {
+			sentry.CaptureException(err)
+			log.Err(err).
+				Msgf("CEL inspector failed to prepare policy, policy=%s", policies[i].ID)
+
+			continue
+		}
+		tracker.TrackQueryLoad(1)
+		prepared = append(prepared, policy)
+	}
+
+	log.Info().
+		Msgf("CEL inspector initialized, number of policies=%d", len(prepared))
+
+	return &CELInspector{
+		policies:       prepared,
+		vb:             vb,
+		tracker:        tracker,
+		failedQueries:  make(map[string]error),
+		excludeResults: excludeResults,
+	}, nil
+}
+
+func loadCELPolicies(policiesPath string) ([]CELPolicy, error) {
+	paths, err := listPolicyFiles(policiesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]CELPolicy, 0)
+	for _, path := range paths {
+		content, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return nil, err
+		}
+		var file celPolicies
+		// JSON is a subset of YAML so both formats are decoded by the YAML decoder
+		if err := yaml.Unmarshal(content, &file); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode CEL policies file %s", path)
+		}
+		policies = append(policies, file.Policies...)
+	}
+	return policies, nil
+}
+
+// listPolicyFiles returns policiesPath when it is a file or the YAML and JSON files found in it when it is a directory
+func listPolicyFiles(policiesPath string) ([]string, error) {
+	info, err := os.Stat(policiesPath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{policiesPath}, nil
+	}
+	paths := make([]string, 0)
+	err = filepath.Walk(policiesPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".json":
+			if !info.IsDir() {
+				paths = append(paths, path)
+			}
+		}
+		return nil
+	})
+	return paths, err
+}
+
+func prepareCELPolicy(policy *CELPolicy) (*preparedCELPolicy, error) {
+	if policy.ID == "" || policy.Validation == "" {
+		return nil, errors.New("CEL policy must define an id and a validation")
+	}
+	switch policy.platform() {
+	case celPlatformTerraform, celPlatformKubernetes, celPlatformCloudFormation:
+	default:
+		return nil, errors.Errorf("unsupported CEL policy platform %q", policy.Platform)
+	}
+
+	validation, err := cel.Compile(policy.Validation)
+	if err != nil {
+		return nil, err
+	}
+	var match *cel.Program
+	if policy.Match != "" {
+		if match, err = cel.Compile(policy.Match); err != nil {
+			return nil, err
+		}
+	}
+
+	return &preparedCELPolicy{
+		policy:     *policy,
+		match:      match,
+		validation: validation,
+		query: &preparedQuery{
+			metadata: model.QueryMetadata{
+				Query: policy.ID,
+				Metadata: map[string]interface{}{
+					"id":              policy.ID,
+					"queryName":       policy.QueryName,
+					"severity":        policy.Severity,
+					"category":        policy.Category,
+					"descriptionText": policy.DescriptionText,
+					"descriptionUrl":  policy.DescriptionURL,
+					"platform":        policy.Platform,
+				},
+				Platform:    policy.platform(),
+				Aggregation: 1,
+			},
+		},
+	}, nil
+}
+
+func (p *CELPolicy) platform() string {
+	platform := strings.ToLower(p.Platform)
+	if platform == "kubernetes" {
+		return celPlatformKubernetes
+	}
+	return platform
+}
+
+// Inspect evaluates the CEL policies against the files and returns the vulnerabilities found
+func (c *CELInspector) Inspect(
+	ctx context.Context,
+	scanID string,
+	files model.FileMetadatas,
+	hideProgress bool,
+	baseScanPath string) ([]model.Vulnerability, error) {
+	log.Debug().Msg("engine.CELInspector.Inspect()")
+
+	vulnerabilities := make([]model.Vulnerability, 0)
+	filesMap := files.ToMap()
+	for _, policy := range c.policies {
+		if c.skipQuery(policy.query.metadata.Query) {
+			continue
+		}
+		queryCtx := &QueryContext{
+			ctx:          ctx,
+			scanID:       scanID,
+			files:        filesMap,
+			query:        policy.query,
+			baseScanPath: baseScanPath,
+		}
+		for i := range files {
+			vulns := c.inspectFile(queryCtx, policy, &files[i])
+			vulnerabilities = append(vulnerabilities, vulns...)
+			if c.shouldStop(vulns) {
+				return vulnerabilities, nil
+			}
+		}
+		c.tracker.TrackQueryExecution(1)
+	}
+	return vulnerabilities, nil
+}
+
+// GetFailedQueries returns a map of failed policies and the associated error
+func (c *CELInspector) GetFailedQueries() map[string]error {
+	return c.failedQueries
+}
+
+func (c *CELInspector) inspectFile(ctx *QueryContext, policy *preparedCELPolicy, file *model.FileMetadata) []model.Vulnerability {
+	vulnerabilities := make([]model.Vulnerability, 0)
+	for _, resource := range extractCELResources(policy.policy.platform(), file) {
+		if policy.policy.ResourceType != "" && policy.policy.ResourceType != resource.resourceType {
+			continue
+		}
+		vars := map[string]interface{}{
+			"object": resource.object,
+			"type":   resource.resourceType,
+			"name":   resource.name,
+		}
+		if policy.match != nil {
+			matched, err := policy.match.EvalBool(vars)
+			if err != nil || !matched {
+				logCELEvalError(err, policy, file, &resource)
+				continue
+			}
+		}
+		valid, err := policy.validation.EvalBool(vars)
+		if err != nil {
+			logCELEvalError(err, policy, file, &resource)
+			continue
+		}
+		if valid {
+			continue
+		}
+
+		vulnerability, err := c.vb(ctx, c.tracker, policy.result(file, &resource))
+		if err != nil {
+			sentry.CaptureException(err)
+			log.Err(err).
+				Msgf("CEL inspector can't save vulnerability, policy=%s", policy.policy.ID)
+
+			if _, ok := c.failedQueries[policy.policy.ID]; !ok {
+				c.failedQueries[policy.policy.ID] = err
+			}
+
+			continue
+		}
+
+		if _, ok := c.excludeResults[vulnerability.SimilarityID]; ok {
+			log.Debug().
+				Msgf("Excluding result SimilarityID: %s", vulnerability.SimilarityID)
+		} else {
+			vulnerabilities = append(vulnerabilities, vulnerability)
+		}
+	}
+	return vulnerabilities
+}
+
+func logCELEvalError(err error, policy *preparedCELPolicy, file *model.FileMetadata, resource *celResource) {
+	if err == nil {
+		return
+	}
+	log.Debug().
+		Msgf("CEL policy %s skipped resource %s in %s: %s", policy.policy.ID, resource.searchKey, file.FileName, err)
+}
+
+// result builds the query result of a resource that failed the policy, in the same format of the rego queries
+func (p *preparedCELPolicy) result(file *model.FileMetadata, resource *celResource) map[string]interface{} {
+	actual := p.policy.Message
+	if actual == "" {
+		actual = fmt.Sprintf("'%s' is false", p.policy.Validation)
+	}
+	return map[string]interface{}{
+		"documentId":       file.ID,
+		"searchKey":        resource.searchKey,
+		"issueType":        string(model.IssueTypeIncorrectValue),
+		"keyExpectedValue": fmt.Sprintf("'%s' is true", p.policy.Validation),
+		"keyActualValue":   actual,
+	}
+}
+
+func extractCELResources(platform string, file *model.FileMetadata) []celResource {
+	switch platform {
+	case celPlatformTerraform:
+		if file.Kind != model.KindTerraform && file.Kind != model.KindTerragrunt {
+			return nil
+		}
+		return extractTerraformResources(file.Document)
+	case celPlatformKubernetes:
+		return extractKubernetesResources(file.Document)
+	case celPlatformCloudFormation:
+		return extractCloudFormationResources(file.Document)
+	}
+	return nil
+}
+
+func extractTerraformResources(document model.Document) []celResource {
+	resources := make([]celResource, 0)
+	types, ok := document["resource"].(map[string]interface{})
+	if !ok {
+		return resources
+	}
+	for _, resourceType := range sortedKeys(types) {
+		names, ok := types[resourceType].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, name := range sortedKeys(names) {
+			resources = append(resources, celResource{
+				resourceType: resourceType,
+				name:         name,
+				searchKey:    fmt.Sprintf("%s[%s]", resourceType, name),
+				object:       names[name],
+			})
+		}
+	}
+	return resources
+}
+
+func extractKubernetesResources(document model.Document) []celResource {
+	kind, ok := document["kind"].(string)
+	if _, hasAPIVersion := document["apiVersion"]; !ok || !hasAPIVersion {
+		return nil
+	}
+	name := ""
+	if metadata, ok := document["metadata"].(map[string]interface{}); ok {
+		name, _ = metadata["name"].(string)
+	}
+	return []celResource{{
+		resourceType: kind,
+		name:         name,
+		searchKey:    fmt.Sprintf("metadata.name={{%s}}", name),
+		object:       map[string]interface{}(document),
+	}}
+}
+
+func extractCloudFormationResources(document model.Document) []celResource {
+	resources := make([]celResource, 0)
+	cfnResources, ok := document["Resources"].(map[string]interface{})
+	if !ok {
+		return resources
+	}
+	for _, name := range sortedKeys(cfnResources) {
+		resource, ok := cfnResources[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		resourceType, _ := resource["Type"].(string)
+		resources = append(resources, celResource{
+			resourceType: resourceType,
+			name:         name,
+			searchKey:    fmt.Sprintf("Resources.%s", name),
+			object:       resource,
+		})
+	}
+	return resources
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
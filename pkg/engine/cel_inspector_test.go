@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Checkmarx/kics/internal/tracker"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/test"
+)
+
+// TestCELInspector_Inspect tests the functions [NewCELInspector(), Inspect()] and all the methods called by them
+func TestCELInspector_Inspect(t *testing.T) {
+	if err := test.ChangeCurrentDir("kics"); err != nil {
+		t.Fatal(err)
+	}
+
+	track := &tracker.CITracker{}
+	inspector, err := NewCELInspector(
+		filepath.FromSlash("test/fixtures/test_cel_policies"),
+		DefaultVulnerabilityBuilder,
+		track,
+		map[string]bool{},
+	)
+	require.NoError(t, err)
+	require.Len(t, inspector.policies, 2)
+	require.Equal(t, 2, track.LoadedQueries)
+
+	files := model.FileMetadatas{
+		{
+			ID:       "tf",
+			FileName: "main.tf",
+			Kind:     model.KindTerraform,
+			OriginalData: `resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+}
+
+resource "aws_s3_bucket" "data" {
+  bucket = "data"
+  versioning {
+    enabled = true
+  }
+}
+`,
+			Document: model.Document{
+				"resource": map[string]interface{}{
+					"aws_s3_bucket": map[string]interface{}{
+						"logs": map[string]interface{}{"bucket": "logs"},
+						"data": map[string]interface{}{
+							"bucket":     "data",
+							"versioning": map[string]interface{}{"enabled": true},
+						},
+					},
+				},
+			},
+		},
+		{
+			ID:       "k8s",
+			FileName: "pod.yaml",
+			Kind:     model.KindYAML,
+			OriginalData: `apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+    - name: web
+      image: nginx:latest
+`,
+			Document: model.Document{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"metadata":   map[string]interface{}{"name": "web"},
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "web", "image": "nginx:latest"},
+					},
+				},
+			},
+		},
+	}
+
+	vulnerabilities, err := inspector.Inspect(context.Background(), "console", files, true, "")
+	require.NoError(t, err)
+	require.Len(t, vulnerabilities, 2)
+	require.Empty(t, inspector.GetFailedQueries())
+	require.Equal(t, 2, track.ExecutedQueries)
+
+	require.Equal(t, "S3 Bucket Without Versioning", vulnerabilities[0].QueryName)
+	require.Equal(t, "aws_s3_bucket[logs]", vulnerabilities[0].SearchKey)
+	require.Equal(t, "'versioning' is undefined or disabled", vulnerabilities[0].KeyActualValue)
+	require.Equal(t, model.Severity(model.SeverityMedium), vulnerabilities[0].Severity)
+	require.Equal(t, 1, vulnerabilities[0].Line)
+
+	require.Equal(t, "Container Image With Latest Tag", vulnerabilities[1].QueryName)
+	require.Equal(t, "metadata.name={{web}}", vulnerabilities[1].SearchKey)
+	require.Equal(t, "pod.yaml", vulnerabilities[1].FileName)
+
+	excluded, err := NewCELInspector(
+		filepath.FromSlash("test/fixtures/test_cel_policies/policies.yaml"),
+		DefaultVulnerabilityBuilder,
+		&tracker.CITracker{},
+		map[string]bool{vulnerabilities[0].SimilarityID: true},
+	)
+	require.NoError(t, err)
+	vulnerabilities, err = excluded.Inspect(context.Background(), "console", files, true, "")
+	require.NoError(t, err)
+	require.Len(t, vulnerabilities, 1)
+}
+
+// TestNewCELInspector tests the functions [NewCELInspector()] with a path that does not exist
+func TestNewCELInspector(t *testing.T) {
+	_, err := NewCELInspector("not-found", DefaultVulnerabilityBuilder, &tracker.CITracker{}, map[string]bool{})
+	require.Error(t, err)
+}
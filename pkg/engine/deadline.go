@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DeadlineEngine wraps the methods of a policy engine that stops evaluating queries once a deadline is exceeded,
+// the query being evaluated at the deadline is finished
+// SetDeadline sets the deadline
+// GetSkippedQueries returns the queries not evaluated because the deadline was exceeded
+type DeadlineEngine interface {
+	SetDeadline(deadline time.Time)
+	GetSkippedQueries() []string
+}
+
+// deadline keeps the deadline of a policy engine and the queries it skipped
+type deadline struct {
+	at             time.Time
+	skippedQueries []string
+	skipped        map[string]bool
+}
+
+// SetDeadline sets the deadline of the evaluation, a zero time disables it
+func (d *deadline) SetDeadline(t time.Time) {
+	d.at = t
+}
+
+// GetSkippedQueries returns the queries not evaluated because the deadline was exceeded
+func (d *deadline) GetSkippedQueries() []string {
+	return d.skippedQueries
+}
+
+// skipQuery returns true, recording the query as skipped once for all the batches of files, if the deadline is exceeded
+func (d *deadline) skipQuery(query string) bool {
+	if d.at.IsZero() || time.Now().Before(d.at) {
+		return false
+	}
+	if len(d.skippedQueries) == 0 {
+		log.Warn().Msgf("Scan deadline exceeded, skipping the remaining queries from query %s", query)
+	}
+	if d.skipped == nil {
+		d.skipped = make(map[string]bool)
+	}
+	if !d.skipped[query] {
+		d.skipped[query] = true
+		d.skippedQueries = append(d.skippedQueries, query)
+	}
+	return true
+}
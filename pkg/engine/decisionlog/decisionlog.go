@@ -0,0 +1,156 @@
+package decisionlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// EvalMetric is the metric with the time spent evaluating the query, in nanoseconds
+	EvalMetric = "timer_rego_query_eval_ns"
+
+	httpBatchSize = 100
+	httpTimeout   = 30 * time.Second
+)
+
+// DecisionLog is an OPA-style decision log of the evaluation of a query
+type DecisionLog struct {
+	DecisionID  string           `json:"decision_id"`
+	Timestamp   time.Time        `json:"timestamp"`
+	ScanID      string           `json:"scan_id"`
+	Path        string           `json:"path"`
+	Query       string           `json:"query"`
+	QueryID     string           `json:"query_id"`
+	InputDigest string           `json:"input_digest"`
+	Result      interface{}      `json:"result,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	Metrics     map[string]int64 `json:"metrics"`
+}
+
+// Logger receives the decision log of each query evaluation
+// Close should flush the pending decision logs
+type Logger interface {
+	Log(decision *DecisionLog) error
+	Close() error
+}
+
+// NewLogger returns a Logger that posts decision logs with the client to the target if it is an HTTP(S) URL
+// or writes them to the target file otherwise, a default client is used when client is nil
+func NewLogger(target string, client *http.Client) (Logger, error) {
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		if client == nil {
+			client = &http.Client{Timeout: httpTimeout}
+		}
+		return NewHTTPLogger(target, client), nil
+	}
+	return NewFileLogger(target)
+}
+
+// FileLogger writes decision logs to a file, one JSON document per line
+type FileLogger struct {
+	file   *os.File
+	writer *bufio.Writer
+	mutex  sync.Mutex
+}
+
+// NewFileLogger creates a FileLogger writing to the file in path, replacing it if it exists
+func NewFileLogger(path string) (*FileLogger, error) {
+	file, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create decision log file")
+	}
+	return &FileLogger{
+		file:   file,
+		writer: bufio.NewWriter(file),
+	}, nil
+}
+
+// Log writes a decision log to the file
+func (l *FileLogger) Log(decision *DecisionLog) error {
+	content, err := json.Marshal(decision)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal decision log")
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if _, err := l.writer.Write(append(content, '\n')); err != nil {
+		return errors.Wrap(err, "failed to write decision log")
+	}
+	return nil
+}
+
+// Close flushes the decision logs and closes the file
+func (l *FileLogger) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if err := l.writer.Flush(); err != nil {
+		return errors.Wrap(err, "failed to write decision log")
+	}
+	return l.file.Close()
+}
+
+// HTTPLogger posts decision logs in batches to an HTTP endpoint, each batch is a JSON array
+// like the ones sent by OPA decision log plugin
+type HTTPLogger struct {
+	url    string
+	client *http.Client
+	batch  []*DecisionLog
+	mutex  sync.Mutex
+}
+
+// NewHTTPLogger creates an HTTPLogger posting to url with the given client
+func NewHTTPLogger(url string, client *http.Client) *HTTPLogger {
+	return &HTTPLogger{
+		url:    url,
+		client: client,
+		batch:  make([]*DecisionLog, 0, httpBatchSize),
+	}
+}
+
+// Log adds a decision log to the current batch, which is posted when it is full
+func (l *HTTPLogger) Log(decision *DecisionLog) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.batch = append(l.batch, decision)
+	if len(l.batch) < httpBatchSize {
+		return nil
+	}
+	return l.flush()
+}
+
+// Close posts the pending decision logs
+func (l *HTTPLogger) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.flush()
+}
+
+func (l *HTTPLogger) flush() error {
+	if len(l.batch) == 0 {
+		return nil
+	}
+	content, err := json.Marshal(l.batch)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal decision logs")
+	}
+	l.batch = make([]*DecisionLog, 0, httpBatchSize)
+
+	resp, err := l.client.Post(l.url, "application/json", bytes.NewReader(content))
+	if err != nil {
+		return errors.Wrap(err, "failed to post decision logs")
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.Errorf("failed to post decision logs: %s", resp.Status)
+	}
+	return nil
+}
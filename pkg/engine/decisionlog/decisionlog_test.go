@@ -0,0 +1,92 @@
+package decisionlog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newDecision(query string) *DecisionLog {
+	return &DecisionLog{
+		DecisionID:  "c9a4e5b2-6f0e-4e0a-8f5c-0b1b6a1d6f3e",
+		Timestamp:   time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC),
+		ScanID:      "console",
+		Path:        "Cx/CxPolicy",
+		Query:       query,
+		QueryID:     "00000000-0000-0000-0000-000000000000",
+		InputDigest: "sha256:digest",
+		Result:      []interface{}{},
+		Metrics:     map[string]int64{EvalMetric: 10},
+	}
+}
+
+// TestFileLogger tests the functions [NewLogger()] with a file target and all the methods called by them
+func TestFileLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.log")
+	logger, err := NewLogger(path, nil)
+	require.NoError(t, err)
+	require.IsType(t, &FileLogger{}, logger)
+
+	require.NoError(t, logger.Log(newDecision("first")))
+	require.NoError(t, logger.Log(newDecision("second")))
+	require.NoError(t, logger.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+
+	var decision DecisionLog
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &decision))
+	require.Equal(t, "second", decision.Query)
+	require.Equal(t, int64(10), decision.Metrics[EvalMetric])
+}
+
+// TestHTTPLogger tests the functions [NewLogger()] with an HTTP target and all the methods called by them
+func TestHTTPLogger(t *testing.T) {
+	batches := make([][]DecisionLog, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch []DecisionLog
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		batches = append(batches, batch)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	logger, err := NewLogger(server.URL, server.Client())
+	require.NoError(t, err)
+	require.IsType(t, &HTTPLogger{}, logger)
+
+	for i := 0; i < httpBatchSize+1; i++ {
+		require.NoError(t, logger.Log(newDecision("query")))
+	}
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0], httpBatchSize)
+
+	require.NoError(t, logger.Close())
+	require.Len(t, batches, 2)
+	require.Len(t, batches[1], 1)
+
+	// nothing left to post
+	require.NoError(t, logger.Close())
+	require.Len(t, batches, 2)
+}
+
+// TestHTTPLogger_Error tests the functions [Close()] when the endpoint rejects the decision logs
+func TestHTTPLogger_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	logger := NewHTTPLogger(server.URL, server.Client())
+	require.NoError(t, logger.Log(newDecision("query")))
+	require.Error(t, logger.Close())
+}
@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// queryInput is the input of the queries when a deployment context is set, the documents scanned and the
+// deployment context, available to the queries as input.deployment_context
+type queryInput struct {
+	Documents         []model.Document       `json:"document"`
+	DeploymentContext map[string]interface{} `json:"deployment_context"`
+}
+
+// LoadDeploymentContext reads the deployment context document, a JSON or YAML object describing where the scanned
+// files are deployed, such as the cloud accounts, the allowed regions and the environment tier
+func LoadDeploymentContext(path string) (map[string]interface{}, error) {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read deployment context")
+	}
+	deploymentContext := make(map[string]interface{})
+	if err := yaml.Unmarshal(content, &deploymentContext); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse deployment context %s", path)
+	}
+	return deploymentContext, nil
+}
+
+// SetDeploymentContext sets the deployment context of the scan, referenced by the queries as input.deployment_context
+func (c *Inspector) SetDeploymentContext(deploymentContext map[string]interface{}) {
+	c.deploymentContext = deploymentContext
+}
+
+// input returns the input of the queries, the documents scanned and the deployment context, if any
+func (c *Inspector) input(payload model.Documents) interface{} {
+	if c.deploymentContext == nil {
+		return payload
+	}
+	return queryInput{Documents: payload.Documents, DeploymentContext: c.deploymentContext}
+}
@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoadDeploymentContext tests the functions [LoadDeploymentContext()] and all the methods called by them
+func TestLoadDeploymentContext(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "context.yaml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte("tier: production\nallowed_regions:\n  - eu-west-1\n"), 0600))
+	jsonPath := filepath.Join(dir, "context.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"tier": "staging"}`), 0600))
+	listPath := filepath.Join(dir, "list.yaml")
+	require.NoError(t, os.WriteFile(listPath, []byte("- eu-west-1\n"), 0600))
+
+	deploymentContext, err := LoadDeploymentContext(yamlPath)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"tier":            "production",
+		"allowed_regions": []interface{}{"eu-west-1"},
+	}, deploymentContext)
+
+	deploymentContext, err = LoadDeploymentContext(jsonPath)
+	require.NoError(t, err)
+	require.Equal(t, "staging", deploymentContext["tier"])
+
+	_, err = LoadDeploymentContext(listPath)
+	require.Error(t, err)
+	_, err = LoadDeploymentContext(filepath.Join(dir, "missing.yaml"))
+	require.Error(t, err)
+}
+
+// TestInspector_input tests the functions [input()] and all the methods called by them
+func TestInspector_input(t *testing.T) {
+	payload := model.Documents{Documents: []model.Document{{"file": "main.tf"}}}
+	inspector := &Inspector{}
+	require.Equal(t, payload, inspector.input(payload))
+
+	inspector.SetDeploymentContext(map[string]interface{}{"tier": "production"})
+	require.Equal(t, queryInput{
+		Documents:         payload.Documents,
+		DeploymentContext: map[string]interface{}{"tier": "production"},
+	}, inspector.input(payload))
+}
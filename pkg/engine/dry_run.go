@@ -0,0 +1,189 @@
+package engine
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// commonPlatform is the platform of the queries that run against files of any platform
+const commonPlatform = "Common"
+
+// QueryLister wraps the Queries method of a policy engine, which returns the metadata of the queries it evaluates
+type QueryLister interface {
+	Queries() []model.QueryMetadata
+}
+
+// PlannedQuery is a query that would run against a file
+type PlannedQuery struct {
+	ID       string
+	Name     string
+	Platform string
+}
+
+// FilePlan lists the queries that would run against a file, according to the platforms detected in its documents
+type FilePlan struct {
+	FileName  string
+	Kind      model.FileKind
+	Platforms []string
+	Queries   []PlannedQuery
+}
+
+// DryRun is a policy engine that, instead of evaluating the queries of its policy engine, plans which
+// queries would run against each file, matching the platforms of the queries with the platforms of the files
+type DryRun struct {
+	policyEngine PolicyEngine
+	plans        []FilePlan
+}
+
+// NewDryRun returns a policy engine that plans the queries of policyEngine without evaluating them
+func NewDryRun(policyEngine PolicyEngine) *DryRun {
+	return &DryRun{policyEngine: policyEngine}
+}
+
+// Inspect plans the queries that would run against each file and returns no vulnerabilities
+func (d *DryRun) Inspect(
+	ctx context.Context,
+	scanID string,
+	files model.FileMetadatas,
+	hideProgress bool,
+	baseScanPath string) ([]model.Vulnerability, error) {
+	log.Debug().Msg("engine.DryRun.Inspect()")
+	d.plans = planQueries(listQueries(d.policyEngine), files)
+	return []model.Vulnerability{}, nil
+}
+
+// GetFailedQueries returns the failed queries of the policy engine, no query is evaluated in a dry run
+func (d *DryRun) GetFailedQueries() map[string]error {
+	return d.policyEngine.GetFailedQueries()
+}
+
+// GetPlans returns the queries that would run against each file, sorted by file name
+func (d *DryRun) GetPlans() []FilePlan {
+	return d.plans
+}
+
+// Queries returns the metadata of the queries evaluated by the inspector
+func (c *Inspector) Queries() []model.QueryMetadata {
+	queries := make([]model.QueryMetadata, 0, len(c.queries))
+	for _, query := range c.queries {
+		queries = append(queries, query.metadata)
+	}
+	return queries
+}
+
+// Queries returns the metadata of the CEL policies evaluated by the CEL inspector
+func (c *CELInspector) Queries() []model.QueryMetadata {
+	queries := make([]model.QueryMetadata, 0, len(c.policies))
+	for _, policy := range c.policies {
+		metadata := policy.query.metadata
+		metadata.Platform = policy.policy.Platform
+		queries = append(queries, metadata)
+	}
+	return queries
+}
+
+// Queries returns the metadata of the queries of all policy engines that list them
+func (e PolicyEngines) Queries() []model.QueryMetadata {
+	queries := make([]model.QueryMetadata, 0)
+	for _, policyEngine := range e {
+		queries = append(queries, listQueries(policyEngine)...)
+	}
+	return queries
+}
+
+func listQueries(policyEngine PolicyEngine) []model.QueryMetadata {
+	if lister, ok := policyEngine.(QueryLister); ok {
+		return lister.Queries()
+	}
+	return []model.QueryMetadata{}
+}
+
+func planQueries(queries []model.QueryMetadata, files model.FileMetadatas) []FilePlan {
+	plans := make(map[string]*FilePlan)
+	for i := range files {
+		plan, ok := plans[files[i].FileName]
+		if !ok {
+			plan = &FilePlan{FileName: files[i].FileName, Kind: files[i].Kind, Platforms: []string{}}
+			plans[files[i].FileName] = plan
+		}
+		if platform := DetectPlatform(&files[i]); platform != "" && !containsFold(plan.Platforms, platform) {
+			plan.Platforms = append(plan.Platforms, platform)
+		}
+	}
+
+	result := make([]FilePlan, 0, len(plans))
+	for _, plan := range plans {
+		plan.Queries = make([]PlannedQuery, 0)
+		for _, query := range queries {
+			if !strings.EqualFold(query.Platform, commonPlatform) && !containsFold(plan.Platforms, query.Platform) {
+				continue
+			}
+			plan.Queries = append(plan.Queries, PlannedQuery{
+				ID:       stringMetadata(query.Metadata, "id"),
+				Name:     stringMetadata(query.Metadata, "queryName"),
+				Platform: query.Platform,
+			})
+		}
+		sort.Slice(plan.Queries, func(i, j int) bool {
+			return plan.Queries[i].Name < plan.Queries[j].Name
+		})
+		result = append(result, *plan)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].FileName < result[j].FileName
+	})
+	return result
+}
+
+// DetectPlatform returns the platform of the queries that apply to the document of a file,
+// an empty string is returned when only the common queries apply
+func DetectPlatform(file *model.FileMetadata) string {
+	switch file.Kind {
+	case model.KindTerraform, model.KindTerragrunt:
+		return "Terraform"
+	case model.KindDOCKER:
+		return "Dockerfile"
+	case model.KindHELM, model.KindKustomize:
+		return "Kubernetes"
+	case model.KindARM, model.KindBICEP:
+		return "AzureResourceManager"
+	case model.KindGDM:
+		return "GoogleDeploymentManager"
+	case model.KindPUPPET:
+		return "Puppet"
+	case model.KindSALT:
+		return "SaltStack"
+	}
+	if _, ok := file.Document["playbooks"]; ok {
+		return "Ansible"
+	}
+	if _, ok := file.Document["Resources"]; ok {
+		return "CloudFormation"
+	}
+	if _, ok := file.Document["AWSTemplateFormatVersion"]; ok {
+		return "CloudFormation"
+	}
+	_, hasAPIVersion := file.Document["apiVersion"]
+	if _, hasKind := file.Document["kind"]; hasAPIVersion && hasKind {
+		return "Kubernetes"
+	}
+	return ""
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func stringMetadata(metadata map[string]interface{}, key string) string {
+	value, _ := metadata[key].(string)
+	return value
+}
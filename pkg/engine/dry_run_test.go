@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Checkmarx/kics/pkg/model"
+)
+
+func newQueryMetadata(id, name, platform string) model.QueryMetadata {
+	return model.QueryMetadata{
+		Query:    name,
+		Metadata: map[string]interface{}{"id": id, "queryName": name},
+		Platform: platform,
+	}
+}
+
+// TestDryRun_Inspect tests the functions [NewDryRun(), Inspect(), GetPlans()] and all the methods called by them
+func TestDryRun_Inspect(t *testing.T) {
+	inspector := &Inspector{
+		queries: []*preparedQuery{
+			{metadata: newQueryMetadata("1", "S3 Bucket ACL Allows Read", "Terraform")},
+			{metadata: newQueryMetadata("2", "Container Is Privileged", "Kubernetes")},
+			{metadata: newQueryMetadata("3", "Passwords And Secrets In Infrastructure Code", "Common")},
+			{metadata: newQueryMetadata("4", "IAM Policy Grants Full Permissions", "cloudformation")},
+		},
+		failedQueries: map[string]error{},
+	}
+	celInspector := &CELInspector{
+		policies: []*preparedCELPolicy{
+			{
+				policy: CELPolicy{Platform: "Kubernetes"},
+				query:  &preparedQuery{metadata: newQueryMetadata("5", "Container Image With Latest Tag", "k8s")},
+			},
+		},
+		failedQueries: map[string]error{},
+	}
+	dryRun := NewDryRun(PolicyEngines{inspector, celInspector})
+
+	files := model.FileMetadatas{
+		{ID: "1", FileName: "main.tf", Kind: model.KindTerraform, Document: model.Document{}},
+		{ID: "2", FileName: "all.yaml", Kind: model.KindYAML, Document: model.Document{"apiVersion": "v1", "kind": "Pod"}},
+		{ID: "3", FileName: "all.yaml", Kind: model.KindYAML, Document: model.Document{"Resources": map[string]interface{}{}}},
+		{ID: "4", FileName: "values.yaml", Kind: model.KindYAML, Document: model.Document{"replicas": 1}},
+	}
+
+	vulnerabilities, err := dryRun.Inspect(context.Background(), "console", files, true, "")
+	require.NoError(t, err)
+	require.Empty(t, vulnerabilities)
+	require.Empty(t, dryRun.GetFailedQueries())
+
+	plans := dryRun.GetPlans()
+	require.Len(t, plans, 3)
+
+	require.Equal(t, "all.yaml", plans[0].FileName)
+	require.Equal(t, []string{"Kubernetes", "CloudFormation"}, plans[0].Platforms)
+	require.Equal(t, []PlannedQuery{
+		{ID: "5", Name: "Container Image With Latest Tag", Platform: "Kubernetes"},
+		{ID: "2", Name: "Container Is Privileged", Platform: "Kubernetes"},
+		{ID: "4", Name: "IAM Policy Grants Full Permissions", Platform: "cloudformation"},
+		{ID: "3", Name: "Passwords And Secrets In Infrastructure Code", Platform: "Common"},
+	}, plans[0].Queries)
+
+	require.Equal(t, "main.tf", plans[1].FileName)
+	require.Len(t, plans[1].Queries, 2)
+
+	require.Equal(t, "values.yaml", plans[2].FileName)
+	require.Empty(t, plans[2].Platforms)
+	require.Equal(t, []PlannedQuery{
+		{ID: "3", Name: "Passwords And Secrets In Infrastructure Code", Platform: "Common"},
+	}, plans[2].Queries)
+}
@@ -0,0 +1,47 @@
+package engine
+
+import (
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// FailFastEngine wraps the methods of a policy engine that can stop its evaluation as soon as
+// a result at or above a severity is found
+// SetFailFast sets the severity that stops the evaluation
+// FailedFast returns true if the evaluation was stopped
+type FailFastEngine interface {
+	SetFailFast(severity model.Severity)
+	FailedFast() bool
+}
+
+// failFast keeps the fail fast severity of a policy engine and if its evaluation was stopped
+type failFast struct {
+	failFastSeverity model.Severity
+	stopped          bool
+}
+
+// SetFailFast sets the severity that stops the evaluation, an empty severity disables fail fast
+func (f *failFast) SetFailFast(severity model.Severity) {
+	f.failFastSeverity = severity
+}
+
+// FailedFast returns true if the evaluation was stopped by a result at or above the fail fast severity
+func (f *failFast) FailedFast() bool {
+	return f.stopped
+}
+
+// shouldStop returns true if any of the vulnerabilities is at or above the fail fast severity
+func (f *failFast) shouldStop(vulnerabilities []model.Vulnerability) bool {
+	if f.failFastSeverity == "" {
+		return false
+	}
+	for i := range vulnerabilities {
+		if vulnerabilities[i].Severity.AtLeast(f.failFastSeverity) {
+			log.Info().
+				Msgf("Stopping evaluation, found %s result of query %s", vulnerabilities[i].Severity, vulnerabilities[i].QueryName)
+			f.stopped = true
+			return true
+		}
+	}
+	return false
+}
@@ -2,16 +2,22 @@ package engine
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"runtime"
 	"sync"
 	"time"
 
 	consoleHelpers "github.com/Checkmarx/kics/internal/console/helpers"
+	"github.com/Checkmarx/kics/pkg/engine/decisionlog"
 	"github.com/Checkmarx/kics/pkg/engine/source"
 	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/watchdog"
 	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
 	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/cover"
 	"github.com/open-policy-agent/opa/rego"
@@ -30,6 +36,7 @@ const (
 	DefaultIssueType            = model.IssueTypeIncorrectValue
 
 	regoQuery      = `result = data.Cx.CxPolicy`
+	regoPolicyPath = "Cx/CxPolicy"
 	executeTimeout = 60 * time.Second
 )
 
@@ -55,9 +62,47 @@ type Tracker interface {
 	GetOutputLines() int
 }
 
+// syncTracker serializes the calls to a tracker from the queries evaluated in parallel
+type syncTracker struct {
+	mutex   sync.Mutex
+	tracker Tracker
+}
+
+func (t *syncTracker) TrackQueryLoad(queryAggregation int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.tracker.TrackQueryLoad(queryAggregation)
+}
+
+func (t *syncTracker) TrackQueryExecution(queryAggregation int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.tracker.TrackQueryExecution(queryAggregation)
+}
+
+func (t *syncTracker) FailedDetectLine() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.tracker.FailedDetectLine()
+}
+
+func (t *syncTracker) FailedComputeSimilarityID() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.tracker.FailedComputeSimilarityID()
+}
+
+func (t *syncTracker) GetOutputLines() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.tracker.GetOutputLines()
+}
+
 type preparedQuery struct {
 	opaQuery rego.PreparedEvalQuery
 	metadata model.QueryMetadata
+	// digest is the digest of the content of the query and of the libraries it uses
+	digest string
 }
 
 // Inspector represents a list of compiled queries, a builder for vulnerabilities, an information tracker
@@ -71,6 +116,17 @@ type Inspector struct {
 
 	enableCoverageReport bool
 	coverageReport       cover.Report
+
+	decisionLogger    decisionlog.Logger
+	watchdog          *watchdog.Watchdog
+	deploymentContext map[string]interface{}
+	errorReporter     func(err error)
+
+	parallelism int
+	mutex       sync.Mutex
+
+	failFast
+	deadline
 }
 
 // QueryContext contains the context where the query is executed, which scan it belongs, basic information of query,
@@ -82,6 +138,7 @@ type QueryContext struct {
 	query        *preparedQuery
 	payload      model.Documents
 	baseScanPath string
+	inputDigest  string
 }
 
 var (
@@ -147,6 +204,7 @@ func NewInspector(
 			opaQueries = append(opaQueries, &preparedQuery{
 				opaQuery: opaQuery,
 				metadata: metadata,
+				digest:   queryDigest(metadata.Content, commonGeneralQuery, platformGeneralQuery),
 			})
 		}
 	}
@@ -183,7 +241,16 @@ func startProgressBar(hideProgress bool, total int, wg *sync.WaitGroup, progress
 	go progressBar.Start(wg)
 }
 
-// Inspect scan files and return the a list of vulnerabilities found on the process
+// queryRun is the evaluation of a query by a worker of the inspector, idx is the position of the query
+type queryRun struct {
+	idx             int
+	query           *preparedQuery
+	vulnerabilities []model.Vulnerability
+	err             error
+}
+
+// Inspect scan files and return the a list of vulnerabilities found on the process, queries are evaluated
+// in parallel by the workers of the inspector and the vulnerabilities are returned in the order of the queries
 func (c *Inspector) Inspect(
 	ctx context.Context,
 	scanID string,
@@ -193,50 +260,129 @@ func (c *Inspector) Inspect(
 	log.Debug().Msg("engine.Inspect()")
 	combinedFiles := files.Combine()
 
-	_, err := json.Marshal(combinedFiles)
+	payload, err := json.Marshal(c.input(combinedFiles))
 	if err != nil {
 		return nil, err
 	}
+	digest := sha256.Sum256(payload)
+	inputDigest := "sha256:" + hex.EncodeToString(digest[:])
 
-	var vulnerabilities []model.Vulnerability
-	vulnerabilities = make([]model.Vulnerability, 0)
 	currentQuery := make(chan float64, 1)
 	var wg sync.WaitGroup
 	startProgressBar(hideProgress, len(c.queries), &wg, currentQuery)
-	for idx, query := range c.queries {
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	filesMap := files.ToMap()
+	tracker := &syncTracker{tracker: c.tracker}
+	pending := make(chan queryRun)
+	done := make(chan queryRun)
+	var workers sync.WaitGroup
+	for i := 0; i < c.workers(); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for run := range pending {
+				run.vulnerabilities, run.err = c.doRun(&QueryContext{
+					ctx:          runCtx,
+					scanID:       scanID,
+					files:        filesMap,
+					query:        run.query,
+					payload:      combinedFiles,
+					baseScanPath: baseScanPath,
+					inputDigest:  inputDigest,
+				}, tracker)
+				done <- run
+			}
+		}()
+	}
+	go func() {
+		defer close(pending)
+		for idx, query := range c.queries {
+			if runCtx.Err() != nil || c.skipQuery(query.metadata.Query) {
+				continue
+			}
+			select {
+			case pending <- queryRun{idx: idx, query: query}:
+			case <-runCtx.Done():
+			}
+		}
+	}()
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+
+	runs := make([]*queryRun, len(c.queries))
+	executed := 0
+	for run := range done {
+		run := run
+		executed++
 		if !hideProgress {
-			currentQuery <- float64(idx)
+			currentQuery <- float64(executed)
 		}
-
-		vuls, err := c.doRun(&QueryContext{
-			ctx:          ctx,
-			scanID:       scanID,
-			files:        files.ToMap(),
-			query:        query,
-			payload:      combinedFiles,
-			baseScanPath: baseScanPath,
-		})
-		if err != nil {
-			sentry.CaptureException(err)
-			log.Err(err).
+		if run.err != nil {
+			if runCtx.Err() != nil && ctx.Err() == nil {
+				// evaluations canceled by fail fast are not failures
+				continue
+			}
+			c.reportError(run.err)
+			log.Err(run.err).
 				Str("scanID", scanID).
-				Msgf("Inspector. query executed with error, query=%s", query.metadata.Query)
+				Msgf("Inspector. query executed with error, query=%s", run.query.metadata.Query)
 
-			c.failedQueries[query.metadata.Query] = err
+			c.setFailedQuery(run.query.metadata.Query, run.err, true)
 
 			continue
 		}
+		runs[run.idx] = &run
 
-		vulnerabilities = append(vulnerabilities, vuls...)
+		tracker.TrackQueryExecution(run.query.metadata.Aggregation)
 
-		c.tracker.TrackQueryExecution(query.metadata.Aggregation)
+		if c.shouldStop(run.vulnerabilities) {
+			cancel()
+		}
 	}
 	close(currentQuery)
 	wg.Wait()
-	fmt.Println("\r")
+	if !hideProgress {
+		fmt.Println("\r")
+	}
+	if ctx.Err() != nil {
+		return nil, errors.Wrap(ctx.Err(), "scan canceled")
+	}
+
+	vulnerabilities := make([]model.Vulnerability, 0)
+	for _, run := range runs {
+		if run != nil {
+			vulnerabilities = append(vulnerabilities, run.vulnerabilities...)
+		}
+	}
 	return vulnerabilities, nil
 }
 
+// workers returns the number of queries evaluated in parallel, the number of CPUs by default
+func (c *Inspector) workers() int {
+	if c.parallelism > 0 {
+		return c.parallelism
+	}
+	return runtime.NumCPU()
+}
+
+// SetParallelism sets the number of queries evaluated in parallel, the number of CPUs when zero or less
+func (c *Inspector) SetParallelism(parallelism int) {
+	c.parallelism = parallelism
+}
+
+// setFailedQuery records the error of a failed query, replacing the error already recorded, if any, when replace is set
+func (c *Inspector) setFailedQuery(query string, err error, replace bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, ok := c.failedQueries[query]; !ok || replace {
+		c.failedQueries[query] = err
+	}
+}
+
 // EnableCoverageReport enables the flag to create a coverage report
 func (c *Inspector) EnableCoverageReport() {
 	c.enableCoverageReport = true
@@ -247,16 +393,41 @@ func (c *Inspector) GetCoverageReport() cover.Report {
 	return c.coverageReport
 }
 
+// SetDecisionLogger sets the logger that receives the decision log of each query evaluation
+func (c *Inspector) SetDecisionLogger(logger decisionlog.Logger) {
+	c.decisionLogger = logger
+}
+
+// SetWatchdog sets the watchdog that tracks the evaluation of each query
+func (c *Inspector) SetWatchdog(w *watchdog.Watchdog) {
+	c.watchdog = w
+}
+
+// SetErrorReporter sets the reporter of the unexpected errors of the evaluations, sentry by default
+func (c *Inspector) SetErrorReporter(reporter func(err error)) {
+	c.errorReporter = reporter
+}
+
+// reportError reports an unexpected error of an evaluation to the error reporter of the inspector, or sentry by default
+func (c *Inspector) reportError(err error) {
+	if c.errorReporter != nil {
+		c.errorReporter(err)
+		return
+	}
+	sentry.CaptureException(err)
+}
+
 // GetFailedQueries returns a map of failed queries and the associated error
 func (c *Inspector) GetFailedQueries() map[string]error {
 	return c.failedQueries
 }
 
-func (c *Inspector) doRun(ctx *QueryContext) ([]model.Vulnerability, error) {
-	timeoutCtx, cancel := context.WithTimeout(ctx.ctx, executeTimeout)
+func (c *Inspector) doRun(ctx *QueryContext, tracker Tracker) ([]model.Vulnerability, error) {
+	unitCtx, unit := c.watchdog.Begin(ctx.ctx, "query", ctx.query.metadata.Query)
+	timeoutCtx, cancel := context.WithTimeout(unitCtx, executeTimeout)
 	defer cancel()
 
-	options := []rego.EvalOption{rego.EvalInput(ctx.payload)}
+	options := []rego.EvalOption{rego.EvalInput(c.input(ctx.payload))}
 
 	var cov *cover.Cover
 	if c.enableCoverageReport {
@@ -264,7 +435,12 @@ func (c *Inspector) doRun(ctx *QueryContext) ([]model.Vulnerability, error) {
 		options = append(options, rego.EvalQueryTracer(cov))
 	}
 
+	start := time.Now()
 	results, err := ctx.query.opaQuery.Eval(timeoutCtx, options...)
+	c.logDecision(ctx, results, err, time.Since(start))
+	if c.watchdog.End(unit) {
+		return nil, errors.New("query skipped by the watchdog for running longer than the threshold")
+	}
 	if err != nil {
 		if topdown.IsCancel(err) {
 			return nil, errors.Wrap(err, "query executing timeout exited")
@@ -278,19 +454,46 @@ func (c *Inspector) doRun(ctx *QueryContext) ([]model.Vulnerability, error) {
 			return nil, errors.Wrap(parseErr, "failed to parse coverage module")
 		}
 
+		c.mutex.Lock()
 		c.coverageReport = cov.Report(map[string]*ast.Module{
 			ctx.query.metadata.Query: module,
 		})
+		c.mutex.Unlock()
 	}
 
 	log.Trace().
 		Str("scanID", ctx.scanID).
 		Msgf("Inspector executed with result %+v, query=%s", results, ctx.query.metadata.Query)
 
-	return c.decodeQueryResults(ctx, results)
+	return c.decodeQueryResults(ctx, tracker, results)
 }
 
-func (c *Inspector) decodeQueryResults(ctx *QueryContext, results rego.ResultSet) ([]model.Vulnerability, error) {
+// logDecision sends the decision log of a query evaluation to the decision logger, if any
+func (c *Inspector) logDecision(ctx *QueryContext, results rego.ResultSet, evalErr error, elapsed time.Duration) {
+	if c.decisionLogger == nil {
+		return
+	}
+	decision := &decisionlog.DecisionLog{
+		DecisionID:  uuid.New().String(),
+		Timestamp:   time.Now().UTC(),
+		ScanID:      ctx.scanID,
+		Path:        regoPolicyPath,
+		Query:       ctx.query.metadata.Query,
+		QueryID:     fmt.Sprintf("%v", ctx.query.metadata.Metadata["id"]),
+		InputDigest: ctx.inputDigest,
+		Metrics:     map[string]int64{decisionlog.EvalMetric: elapsed.Nanoseconds()},
+	}
+	if evalErr != nil {
+		decision.Error = evalErr.Error()
+	} else if len(results) > 0 {
+		decision.Result = results[0].Bindings["result"]
+	}
+	if err := c.decisionLogger.Log(decision); err != nil {
+		log.Err(err).Msgf("Inspector failed to log decision, query=%s", ctx.query.metadata.Query)
+	}
+}
+
+func (c *Inspector) decodeQueryResults(ctx *QueryContext, tracker Tracker, results rego.ResultSet) ([]model.Vulnerability, error) {
 	if len(results) == 0 {
 		return nil, ErrNoResult
 	}
@@ -310,15 +513,13 @@ func (c *Inspector) decodeQueryResults(ctx *QueryContext, results rego.ResultSet
 	vulnerabilities := make([]model.Vulnerability, 0, len(queryResultItems))
 	failedDetectLine := false
 	for _, queryResultItem := range queryResultItems {
-		vulnerability, err := c.vb(ctx, c.tracker, queryResultItem)
+		vulnerability, err := c.vb(ctx, tracker, queryResultItem)
 		if err != nil {
-			sentry.CaptureException(err)
+			c.reportError(err)
 			log.Err(err).
 				Msgf("Inspector can't save vulnerability, query=%s", ctx.query.metadata.Query)
 
-			if _, ok := c.failedQueries[ctx.query.metadata.Query]; !ok {
-				c.failedQueries[ctx.query.metadata.Query] = err
-			}
+			c.setFailedQuery(ctx.query.metadata.Query, err, false)
 
 			continue
 		}
@@ -336,7 +537,7 @@ func (c *Inspector) decodeQueryResults(ctx *QueryContext, results rego.ResultSet
 	}
 
 	if failedDetectLine {
-		c.tracker.FailedDetectLine()
+		tracker.FailedDetectLine()
 	}
 
 	return vulnerabilities, nil
@@ -2,15 +2,19 @@ package engine
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/Checkmarx/kics/internal/tracker"
 
+	"github.com/Checkmarx/kics/pkg/engine/decisionlog"
 	"github.com/Checkmarx/kics/pkg/engine/source"
 	"github.com/Checkmarx/kics/pkg/model"
 	"github.com/Checkmarx/kics/test"
@@ -420,3 +424,87 @@ func (m *mockSource) GetQueryLibrary(platform string) (string, error) {
 
 	return string(content), err
 }
+
+type memoryDecisionLogger struct {
+	decisions []*decisionlog.DecisionLog
+}
+
+func (l *memoryDecisionLogger) Log(decision *decisionlog.DecisionLog) error {
+	l.decisions = append(l.decisions, decision)
+	return nil
+}
+
+func (l *memoryDecisionLogger) Close() error {
+	return nil
+}
+
+// TestInspector_SetDecisionLogger tests the functions [SetDecisionLogger()] and all the methods called by them
+func TestInspector_SetDecisionLogger(t *testing.T) {
+	logger := &memoryDecisionLogger{}
+	inspector := &Inspector{}
+	ctx := &QueryContext{
+		scanID:      "console",
+		inputDigest: "sha256:digest",
+		query: &preparedQuery{
+			metadata: model.QueryMetadata{
+				Query:    "alb_protocol_is_http",
+				Metadata: map[string]interface{}{"id": "de7f5e83-da88-4046-871f-ea18504b1d43"},
+			},
+		},
+	}
+
+	inspector.logDecision(ctx, rego.ResultSet{}, nil, time.Millisecond)
+	require.Empty(t, logger.decisions)
+
+	inspector.SetDecisionLogger(logger)
+	inspector.logDecision(ctx, rego.ResultSet{rego.Result{Bindings: rego.Vars{"result": []interface{}{}}}}, nil, time.Millisecond)
+	inspector.logDecision(ctx, nil, errors.New("query executing timeout exited"), time.Second)
+
+	require.Len(t, logger.decisions, 2)
+	require.Equal(t, "alb_protocol_is_http", logger.decisions[0].Query)
+	require.Equal(t, "de7f5e83-da88-4046-871f-ea18504b1d43", logger.decisions[0].QueryID)
+	require.Equal(t, "sha256:digest", logger.decisions[0].InputDigest)
+	require.Equal(t, []interface{}{}, logger.decisions[0].Result)
+	require.Equal(t, time.Millisecond.Nanoseconds(), logger.decisions[0].Metrics[decisionlog.EvalMetric])
+	require.Equal(t, "query executing timeout exited", logger.decisions[1].Error)
+	require.Nil(t, logger.decisions[1].Result)
+}
+
+// TestInspector_Parallel tests the functions [Inspect()] evaluating the queries in parallel
+func TestInspector_Parallel(t *testing.T) {
+	ctx := context.Background()
+	queries := make([]*preparedQuery, 0, 5)
+	for i := 0; i < 5; i++ {
+		name := fmt.Sprintf("query_%d", i)
+		content := fmt.Sprintf(`package Cx
+
+		CxPolicy [ result ] {
+			result := {"documentId": input.document[_].id, "searchKey": %q}
+		}`, name)
+		opaQuery, err := rego.New(rego.Query(regoQuery), rego.Module(name, content)).PrepareForEval(ctx)
+		require.NoError(t, err)
+		queries = append(queries, &preparedQuery{opaQuery: opaQuery, metadata: model.QueryMetadata{Query: name, Content: content}})
+	}
+	vb := func(ctx *QueryContext, _ Tracker, _ interface{}) (model.Vulnerability, error) {
+		return model.Vulnerability{QueryName: ctx.query.metadata.Query}, nil
+	}
+	files := model.FileMetadatas{{ID: "file", Document: model.Document{"id": "file"}, FileName: "main.tf"}}
+
+	inspector := &Inspector{queries: queries, vb: vb, tracker: &tracker.CITracker{}, failedQueries: map[string]error{}}
+	inspector.SetParallelism(3)
+	require.Equal(t, 3, inspector.workers())
+
+	vulnerabilities, err := inspector.Inspect(ctx, "scanID", files, true, ".")
+	require.NoError(t, err)
+	names := make([]string, 0, len(vulnerabilities))
+	for i := range vulnerabilities {
+		names = append(names, vulnerabilities[i].QueryName)
+	}
+	require.Equal(t, []string{"query_0", "query_1", "query_2", "query_3", "query_4"}, names, "results keep the order of the queries")
+	require.Equal(t, 5, inspector.tracker.(*tracker.CITracker).ExecutedQueries)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = inspector.Inspect(canceled, "scanID", files, true, ".")
+	require.Error(t, err)
+}
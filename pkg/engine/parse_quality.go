@@ -0,0 +1,282 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/engine/source"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/getsentry/sentry-go"
+	"github.com/rs/zerolog/log"
+)
+
+// templateLeftoverRegex matches the template syntax left in rendered documents: actions, Jinja statements,
+// missing values and formatting errors of nil values
+var templateLeftoverRegex = regexp.MustCompile(`\{\{.*?\}\}|\{%.*?%\}|<no value>|%!\w\(`)
+
+// parseQualityCheck is a check of the parsed documents of the file kinds given, returning the results found
+type parseQualityCheck struct {
+	query *preparedQuery
+	kinds []model.FileKind
+	check func(file *model.FileMetadata) []parseQualityResult
+}
+
+// parseQualityResult is a query result, in the same format of the rego queries, and its one based line
+// when it is known by the check, otherwise the line is detected from the search key
+type parseQualityResult struct {
+	result map[string]interface{}
+	line   int
+}
+
+// ParseQualityInspector reports, as INFO results, the problems of the parsed documents that make the scan inaccurate,
+// such as template syntax left by incomplete rendering
+type ParseQualityInspector struct {
+	checks         []*parseQualityCheck
+	vb             VulnerabilityBuilder
+	tracker        Tracker
+	failedQueries  map[string]error
+	excludeResults map[string]bool
+
+	failFast
+	deadline
+}
+
+// NewParseQualityInspector initializes a parse quality inspector with the checks not excluded by excludeQueries
+func NewParseQualityInspector(
+	vb VulnerabilityBuilder,
+	tracker Tracker,
+	excludeQueries source.ExcludeQueries,
+	excludeResults map[string]bool) *ParseQualityInspector {
+	log.Debug().Msg("engine.NewParseQualityInspector()")
+
+	checks := make([]*parseQualityCheck, 0)
+	for _, check := range parseQualityChecks() {
+		id := stringMetadata(check.query.metadata.Metadata, "id")
+		if isExcludedQuery(id, stringMetadata(check.query.metadata.Metadata, "category"), excludeQueries) {
+			log.Debug().
+				Msgf("Excluding query ID: %s", id)
+			continue
+		}
+		tracker.TrackQueryLoad(1)
+		checks = append(checks, check)
+	}
+
+	return &ParseQualityInspector{
+		checks:         checks,
+		vb:             vb,
+		tracker:        tracker,
+		failedQueries:  make(map[string]error),
+		excludeResults: excludeResults,
+	}
+}
+
+func parseQualityChecks() []*parseQualityCheck {
+	return []*parseQualityCheck{
+		{
+			query: parseQualityQuery(
+				"2c7ad8a3-471e-4e41-9f64-3399c8c5b2b4",
+				"Unrendered Template Syntax",
+				"Kubernetes",
+				"Rendered documents should not contain template syntax, it is left by failed includes or missing values "+
+					"and the values that should have been rendered are not scanned",
+				"https://helm.sh/docs/chart_template_guide/debugging/",
+			),
+			kinds: []model.FileKind{model.KindHELM},
+			check: checkTemplateLeftovers,
+		},
+		{
+			query: parseQualityQuery(
+				"302603f6-0292-4824-a264-2b56085d978d",
+				"Duplicate Key",
+				commonPlatform,
+				"Keys should be defined once in each object, duplicates silently override the earlier definitions, "+
+					"which may be the security relevant ones",
+				"https://yaml.org/spec/1.2.2/#mapping",
+			),
+			kinds: []model.FileKind{model.KindYAML, model.KindJSON},
+			check: checkDuplicateKeys,
+		},
+	}
+}
+
+func parseQualityQuery(id, name, platform, description, descriptionURL string) *preparedQuery {
+	return &preparedQuery{
+		metadata: model.QueryMetadata{
+			Query: id,
+			Metadata: map[string]interface{}{
+				"id":              id,
+				"queryName":       name,
+				"severity":        string(model.SeverityInfo),
+				"category":        "Best Practices",
+				"descriptionText": description,
+				"descriptionUrl":  descriptionURL,
+				"platform":        platform,
+			},
+			Platform:    platform,
+			Aggregation: 1,
+		},
+	}
+}
+
+// Inspect runs the checks against the files of their kinds and returns the vulnerabilities found
+func (c *ParseQualityInspector) Inspect(
+	ctx context.Context,
+	scanID string,
+	files model.FileMetadatas,
+	hideProgress bool,
+	baseScanPath string) ([]model.Vulnerability, error) {
+	log.Debug().Msg("engine.ParseQualityInspector.Inspect()")
+
+	vulnerabilities := make([]model.Vulnerability, 0)
+	filesMap := files.ToMap()
+	for _, check := range c.checks {
+		if c.skipQuery(check.query.metadata.Query) {
+			continue
+		}
+		queryCtx := &QueryContext{
+			ctx:          ctx,
+			scanID:       scanID,
+			files:        filesMap,
+			query:        check.query,
+			baseScanPath: baseScanPath,
+		}
+		for i := range files {
+			if !check.appliesTo(files[i].Kind) {
+				continue
+			}
+			vulns := c.inspectFile(queryCtx, check, &files[i])
+			vulnerabilities = append(vulnerabilities, vulns...)
+			if c.shouldStop(vulns) {
+				return vulnerabilities, nil
+			}
+		}
+		c.tracker.TrackQueryExecution(1)
+	}
+	return vulnerabilities, nil
+}
+
+// GetFailedQueries returns a map of failed queries and the associated error
+func (c *ParseQualityInspector) GetFailedQueries() map[string]error {
+	return c.failedQueries
+}
+
+// Queries returns the metadata of the checks of the parse quality inspector
+func (c *ParseQualityInspector) Queries() []model.QueryMetadata {
+	queries := make([]model.QueryMetadata, 0, len(c.checks))
+	for _, check := range c.checks {
+		queries = append(queries, check.query.metadata)
+	}
+	return queries
+}
+
+func (c *ParseQualityInspector) inspectFile(
+	ctx *QueryContext,
+	check *parseQualityCheck,
+	file *model.FileMetadata) []model.Vulnerability {
+	vulnerabilities := make([]model.Vulnerability, 0)
+	var lines []string
+	for _, result := range check.check(file) {
+		vulnerability, err := c.vb(ctx, c.tracker, result.result)
+		if err != nil {
+			sentry.CaptureException(err)
+			id := stringMetadata(check.query.metadata.Metadata, "id")
+			log.Err(err).
+				Msgf("Parse quality inspector can't save vulnerability, query=%s", id)
+
+			if _, ok := c.failedQueries[id]; !ok {
+				c.failedQueries[id] = err
+			}
+
+			continue
+		}
+
+		if result.line > 0 {
+			if lines == nil {
+				lines = strings.Split(strings.ReplaceAll(file.OriginalData, "\r", ""), "\n")
+			}
+			vulnerability.Line = result.line
+			vulnerability.VulnLines = getAdjacentLines(result.line-1, c.tracker.GetOutputLines(), lines)
+		}
+
+		if _, ok := c.excludeResults[vulnerability.SimilarityID]; ok {
+			log.Debug().
+				Msgf("Excluding result SimilarityID: %s", vulnerability.SimilarityID)
+		} else {
+			vulnerabilities = append(vulnerabilities, vulnerability)
+		}
+	}
+	return vulnerabilities
+}
+
+func (p *parseQualityCheck) appliesTo(kind model.FileKind) bool {
+	for _, k := range p.kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTemplateLeftovers reports the values of the document that still contain template syntax after rendering
+func checkTemplateLeftovers(file *model.FileMetadata) []parseQualityResult {
+	results := make([]parseQualityResult, 0)
+	walkDocumentStrings(file.Document, "", func(searchKey, value string) {
+		leftover := templateLeftoverRegex.FindString(value)
+		if leftover == "" {
+			return
+		}
+		results = append(results, parseQualityResult{
+			result: map[string]interface{}{
+				"documentId":       file.ID,
+				"searchKey":        searchKey,
+				"searchValue":      leftover,
+				"issueType":        string(model.IssueTypeIncorrectValue),
+				"keyExpectedValue": fmt.Sprintf("'%s' should be rendered", searchKey),
+				"keyActualValue":   fmt.Sprintf("'%s' has unrendered template syntax '%s'", searchKey, leftover),
+			},
+		})
+	})
+	return results
+}
+
+// checkDuplicateKeys reports the keys the parser found defined more than once in the same object of the document
+func checkDuplicateKeys(file *model.FileMetadata) []parseQualityResult {
+	duplicates, _ := file.Document[model.DuplicateKeysKey].([]model.DuplicateKey)
+	results := make([]parseQualityResult, 0, len(duplicates))
+	for _, duplicate := range duplicates {
+		results = append(results, parseQualityResult{
+			result: map[string]interface{}{
+				"documentId":       file.ID,
+				"searchKey":        duplicate.SearchKey,
+				"issueType":        string(model.IssueTypeRedundantAttribute),
+				"keyExpectedValue": fmt.Sprintf("'%s' should be defined once", duplicate.SearchKey),
+				"keyActualValue":   fmt.Sprintf("'%s' is defined more than once, the last definition overrides the others", duplicate.SearchKey),
+			},
+			line: duplicate.Line,
+		})
+	}
+	return results
+}
+
+// walkDocumentStrings calls fn with the search key (keys joined by dots) and the value of each string in value,
+// the search key of the items of a list is the search key of the list
+func walkDocumentStrings(value interface{}, searchKey string, fn func(searchKey, value string)) {
+	switch v := value.(type) {
+	case model.Document:
+		walkDocumentStrings(map[string]interface{}(v), searchKey, fn)
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			walkDocumentStrings(v[key], strings.TrimPrefix(searchKey+"."+key, "."), fn)
+		}
+	case []interface{}:
+		for _, item := range v {
+			walkDocumentStrings(item, searchKey, fn)
+		}
+	case string:
+		if searchKey != "" {
+			fn(searchKey, v)
+		}
+	}
+}
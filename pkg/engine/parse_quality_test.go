@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Checkmarx/kics/internal/tracker"
+	"github.com/Checkmarx/kics/pkg/engine/source"
+	"github.com/Checkmarx/kics/pkg/model"
+)
+
+// TestParseQualityInspector_Inspect tests the functions [NewParseQualityInspector(), Inspect()] and all the methods called by them
+func TestParseQualityInspector_Inspect(t *testing.T) {
+	document := model.Document{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name": "test",
+			"annotations": map[string]interface{}{
+				"note": "{{ .Values.note }}",
+			},
+		},
+	}
+	files := model.FileMetadatas{
+		{
+			ID:       "helm",
+			ScanID:   "console",
+			Document: document,
+			OriginalData: "# KICS_HELM_ID_0:\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\n" +
+				"  annotations:\n    note: {{ tpl .Values.note . }}\n",
+			Kind:     model.KindHELM,
+			FileName: "chart/templates/configmap.yaml",
+			Origin: &model.Origin{
+				SplitID: "# KICS_HELM_ID_0:",
+				Lines:   map[int]interface{}{0: map[int]int{0: 0, 1: 1, 2: 2, 3: 3, 4: 4, 5: 5, 6: 6, 7: 7}},
+			},
+		},
+		{
+			ID:     "yaml",
+			ScanID: "console",
+			Document: model.Document{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"spec":       map[string]interface{}{"hostNetwork": false},
+				model.DuplicateKeysKey: []model.DuplicateKey{
+					{SearchKey: "spec.hostNetwork", Line: 5},
+				},
+			},
+			OriginalData: "apiVersion: v1\nkind: Pod\nspec:\n  hostNetwork: true\n  hostNetwork: false\n",
+			Kind:         model.KindYAML,
+			FileName:     "pod.yaml",
+		},
+	}
+
+	track := &tracker.CITracker{}
+	inspector := NewParseQualityInspector(DefaultVulnerabilityBuilder, track, source.ExcludeQueries{}, map[string]bool{})
+	require.Len(t, inspector.Queries(), 2)
+	require.Equal(t, 2, track.LoadedQueries)
+
+	vulnerabilities, err := inspector.Inspect(context.Background(), "console", files, true, "")
+	require.NoError(t, err)
+	require.Len(t, vulnerabilities, 2)
+	require.Empty(t, inspector.GetFailedQueries())
+	require.Equal(t, 2, track.ExecutedQueries)
+
+	require.Equal(t, "Unrendered Template Syntax", vulnerabilities[0].QueryName)
+	require.Equal(t, "chart/templates/configmap.yaml", vulnerabilities[0].FileName)
+	require.Equal(t, model.Severity(model.SeverityInfo), vulnerabilities[0].Severity)
+	require.Equal(t, 6, vulnerabilities[0].Line)
+	require.Equal(t, "'metadata.annotations.note' has unrendered template syntax '{{ .Values.note }}'",
+		vulnerabilities[0].KeyActualValue)
+
+	require.Equal(t, "Duplicate Key", vulnerabilities[1].QueryName)
+	require.Equal(t, "pod.yaml", vulnerabilities[1].FileName)
+	require.Equal(t, 5, vulnerabilities[1].Line)
+	require.Equal(t, model.IssueTypeRedundantAttribute, vulnerabilities[1].IssueType)
+	require.Equal(t, "'spec.hostNetwork' should be defined once", vulnerabilities[1].KeyExpectedValue)
+
+	excluded := NewParseQualityInspector(
+		DefaultVulnerabilityBuilder,
+		&tracker.CITracker{},
+		source.ExcludeQueries{ByCategories: []string{"Best Practices"}},
+		map[string]bool{},
+	)
+	require.Empty(t, excluded.Queries())
+}
@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/Checkmarx/kics/pkg/model"
+)
+
+// PolicyEngine wraps the methods of a backend that evaluates policies against the files of a scan
+// Inspect returns the vulnerabilities found in the files
+// GetFailedQueries returns the policies that failed to execute and the associated error
+type PolicyEngine interface {
+	Inspect(ctx context.Context, scanID string, files model.FileMetadatas,
+		hideProgress bool, baseScanPath string) ([]model.Vulnerability, error)
+	GetFailedQueries() map[string]error
+}
+
+// ParallelEngine wraps the method SetParallelism of the policy engines that evaluate queries in parallel
+// SetParallelism sets the number of queries evaluated in parallel, the number of CPUs when zero or less
+type ParallelEngine interface {
+	SetParallelism(parallelism int)
+}
+
+// ReportingEngine wraps the method SetErrorReporter of the policy engines that report the unexpected errors of
+// their evaluations
+// SetErrorReporter sets the reporter of the unexpected errors, sentry by default
+type ReportingEngine interface {
+	SetErrorReporter(reporter func(err error))
+}
+
+// PolicyEngines is a list of policy engines evaluated one after the other as a single engine
+type PolicyEngines []PolicyEngine
+
+// Inspect returns the vulnerabilities found by all policy engines
+func (e PolicyEngines) Inspect(
+	ctx context.Context,
+	scanID string,
+	files model.FileMetadatas,
+	hideProgress bool,
+	baseScanPath string) ([]model.Vulnerability, error) {
+	vulnerabilities := make([]model.Vulnerability, 0)
+	for _, policyEngine := range e {
+		vulns, err := policyEngine.Inspect(ctx, scanID, files, hideProgress, baseScanPath)
+		if err != nil {
+			return nil, err
+		}
+		vulnerabilities = append(vulnerabilities, vulns...)
+		if f, ok := policyEngine.(FailFastEngine); ok && f.FailedFast() {
+			break
+		}
+	}
+	return vulnerabilities, nil
+}
+
+// GetFailedQueries returns the failed queries of all policy engines
+func (e PolicyEngines) GetFailedQueries() map[string]error {
+	failedQueries := make(map[string]error)
+	for _, policyEngine := range e {
+		for query, err := range policyEngine.GetFailedQueries() {
+			failedQueries[query] = err
+		}
+	}
+	return failedQueries
+}
+
+// SetFailFast sets the fail fast severity of all policy engines that support it
+func (e PolicyEngines) SetFailFast(severity model.Severity) {
+	for _, policyEngine := range e {
+		if f, ok := policyEngine.(FailFastEngine); ok {
+			f.SetFailFast(severity)
+		}
+	}
+}
+
+// FailedFast returns true if the evaluation of any of the policy engines was stopped
+func (e PolicyEngines) FailedFast() bool {
+	for _, policyEngine := range e {
+		if f, ok := policyEngine.(FailFastEngine); ok && f.FailedFast() {
+			return true
+		}
+	}
+	return false
+}
+
+// SetParallelism sets the number of queries evaluated in parallel by all policy engines that support it
+func (e PolicyEngines) SetParallelism(parallelism int) {
+	for _, policyEngine := range e {
+		if p, ok := policyEngine.(ParallelEngine); ok {
+			p.SetParallelism(parallelism)
+		}
+	}
+}
+
+// SetErrorReporter sets the reporter of the unexpected errors of all policy engines that support it
+func (e PolicyEngines) SetErrorReporter(reporter func(err error)) {
+	for _, policyEngine := range e {
+		if r, ok := policyEngine.(ReportingEngine); ok {
+			r.SetErrorReporter(reporter)
+		}
+	}
+}
+
+// SetDeadline sets the deadline of all policy engines that support it
+func (e PolicyEngines) SetDeadline(t time.Time) {
+	for _, policyEngine := range e {
+		if d, ok := policyEngine.(DeadlineEngine); ok {
+			d.SetDeadline(t)
+		}
+	}
+}
+
+// GetSkippedQueries returns the queries skipped by all policy engines because the deadline was exceeded
+func (e PolicyEngines) GetSkippedQueries() []string {
+	skippedQueries := make([]string, 0)
+	for _, policyEngine := range e {
+		if d, ok := policyEngine.(DeadlineEngine); ok {
+			skippedQueries = append(skippedQueries, d.GetSkippedQueries()...)
+		}
+	}
+	return skippedQueries
+}
@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Checkmarx/kics/pkg/model"
+)
+
+type fakePolicyEngine struct {
+	failFast
+	deadline
+	vulnerabilities []model.Vulnerability
+	failedQueries   map[string]error
+	inspected       bool
+}
+
+func (e *fakePolicyEngine) Inspect(
+	ctx context.Context,
+	scanID string,
+	files model.FileMetadatas,
+	hideProgress bool,
+	baseScanPath string) ([]model.Vulnerability, error) {
+	if e.skipQuery(e.vulnerabilities[0].QueryName) {
+		return []model.Vulnerability{}, nil
+	}
+	e.inspected = true
+	e.shouldStop(e.vulnerabilities)
+	return e.vulnerabilities, nil
+}
+
+func (e *fakePolicyEngine) GetFailedQueries() map[string]error {
+	return e.failedQueries
+}
+
+// TestPolicyEngines tests the functions [Inspect(), GetFailedQueries(), SetFailFast(), FailedFast(), SetDeadline(),
+// GetSkippedQueries()]
+func TestPolicyEngines(t *testing.T) {
+	newEngines := func() (first, second *fakePolicyEngine, engines PolicyEngines) {
+		first = &fakePolicyEngine{
+			vulnerabilities: []model.Vulnerability{{QueryName: "first", Severity: model.SeverityMedium}},
+			failedQueries:   map[string]error{"first": context.Canceled},
+		}
+		second = &fakePolicyEngine{
+			vulnerabilities: []model.Vulnerability{{QueryName: "second", Severity: model.SeverityHigh}},
+			failedQueries:   map[string]error{"second": context.DeadlineExceeded},
+		}
+		return first, second, PolicyEngines{first, second}
+	}
+
+	t.Run("combined", func(t *testing.T) {
+		_, _, engines := newEngines()
+		vulnerabilities, err := engines.Inspect(context.Background(), "console", model.FileMetadatas{}, true, "")
+		require.NoError(t, err)
+		require.Len(t, vulnerabilities, 2)
+		require.Len(t, engines.GetFailedQueries(), 2)
+		require.False(t, engines.FailedFast())
+	})
+
+	t.Run("fail_fast", func(t *testing.T) {
+		_, second, engines := newEngines()
+		engines.SetFailFast(model.SeverityMedium)
+		vulnerabilities, err := engines.Inspect(context.Background(), "console", model.FileMetadatas{}, true, "")
+		require.NoError(t, err)
+		require.Len(t, vulnerabilities, 1)
+		require.True(t, engines.FailedFast())
+		require.False(t, second.inspected)
+	})
+
+	t.Run("fail_fast_not_reached", func(t *testing.T) {
+		_, second, engines := newEngines()
+		engines.SetFailFast(model.SeverityHigh)
+		vulnerabilities, err := engines.Inspect(context.Background(), "console", model.FileMetadatas{}, true, "")
+		require.NoError(t, err)
+		require.Len(t, vulnerabilities, 2)
+		require.True(t, engines.FailedFast())
+		require.True(t, second.inspected)
+	})
+	t.Run("deadline", func(t *testing.T) {
+		first, second, engines := newEngines()
+		engines.SetDeadline(time.Now().Add(-time.Second))
+		vulnerabilities, err := engines.Inspect(context.Background(), "console", model.FileMetadatas{}, true, "")
+		require.NoError(t, err)
+		require.Empty(t, vulnerabilities)
+		require.False(t, first.inspected)
+		require.False(t, second.inspected)
+		require.Equal(t, []string{"first", "second"}, engines.GetSkippedQueries())
+
+		// the queries skipped again for the next batches of files are recorded once
+		_, err = engines.Inspect(context.Background(), "console", model.FileMetadatas{}, true, "")
+		require.NoError(t, err)
+		require.Equal(t, []string{"first", "second"}, engines.GetSkippedQueries())
+	})
+
+	t.Run("deadline_not_reached", func(t *testing.T) {
+		_, _, engines := newEngines()
+		engines.SetDeadline(time.Now().Add(time.Hour))
+		vulnerabilities, err := engines.Inspect(context.Background(), "console", model.FileMetadatas{}, true, "")
+		require.NoError(t, err)
+		require.Len(t, vulnerabilities, 2)
+		require.Empty(t, engines.GetSkippedQueries())
+	})
+}
@@ -0,0 +1,238 @@
+package provider
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/getsentry/sentry-go"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	archiveZip   = ".zip"
+	archiveTar   = ".tar"
+	archiveTarGz = ".tar.gz"
+)
+
+// ArchiveSourceProvider provides the files of a zip, tar or tar.gz archive to be scanned without extracting it,
+// the files are named after the path of the archive followed by their path inside it (ex: bundle.zip/terraform/main.tf)
+type ArchiveSourceProvider struct {
+	path        string
+	format      string
+	content     []byte
+	excludes    []string
+	unsupported map[string]int
+}
+
+// IsArchive returns true if the file is an archive provided by the ArchiveSourceProvider (.zip, .tar or .tar.gz)
+func IsArchive(fileName string) bool {
+	return archiveFormat(fileName) != ""
+}
+
+// NewArchiveSourceProvider initializes an ArchiveSourceProvider with the path of the archive and the patterns
+// of the paths inside it that will be ignored (ex: test/*)
+func NewArchiveSourceProvider(archivePath string, excludes []string) (*ArchiveSourceProvider, error) {
+	log.Debug().Msgf("provider.NewArchiveSourceProvider()")
+	format := archiveFormat(archivePath)
+	if format == "" {
+		return nil, errors.Errorf("archive format not supported: %s, supported formats: .zip, .tar, .tar.gz", archivePath)
+	}
+	return &ArchiveSourceProvider{
+		path:     filepath.FromSlash(archivePath),
+		format:   format,
+		excludes: excludes,
+	}, nil
+}
+
+// NewArchiveSourceProviderFromReader initializes an ArchiveSourceProvider with the content of the archive read from
+// the reader, such as an uploaded bundle, the name of the archive gives its format and prefixes the files inside it,
+// the content is kept on memory so the archive can be provided more than once
+func NewArchiveSourceProviderFromReader(name string, r io.Reader, excludes []string) (*ArchiveSourceProvider, error) {
+	s, err := NewArchiveSourceProvider(name, excludes)
+	if err != nil {
+		return nil, err
+	}
+	if s.content, err = io.ReadAll(r); err != nil {
+		return nil, errors.Wrapf(err, "failed to read archive %s", name)
+	}
+	return s, nil
+}
+
+// GetBasePath returns the path of the archive
+func (s *ArchiveSourceProvider) GetBasePath() string {
+	return s.path
+}
+
+// UnsupportedExtensions returns how many files of each unsupported extension were found in the archive
+// the last time it was provided, files without extension are counted under an empty extension
+func (s *ArchiveSourceProvider) UnsupportedExtensions() map[string]int {
+	return s.unsupported
+}
+
+// GetSources executes the sink function on the files of the archive with the supported extensions, directories and
+// archives inside the archive can't be resolved without extracting them, so the resolver sink is not used
+func (s *ArchiveSourceProvider) GetSources(ctx context.Context,
+	extensions model.Extensions, sink Sink, _ ResolverSink) error {
+	s.unsupported = make(map[string]int)
+	if s.format == archiveZip {
+		return s.getZipSources(ctx, extensions, sink)
+	}
+	return s.getTarSources(ctx, extensions, sink)
+}
+
+func (s *ArchiveSourceProvider) getZipSources(ctx context.Context, extensions model.Extensions, sink Sink) error {
+	var reader *zip.Reader
+	if s.content != nil {
+		r, err := zip.NewReader(bytes.NewReader(s.content), int64(len(s.content)))
+		if err != nil {
+			return errors.Wrap(err, "failed to open archive")
+		}
+		reader = r
+	} else {
+		r, err := zip.OpenReader(s.path)
+		if err != nil {
+			return errors.Wrap(err, "failed to open archive")
+		}
+		defer closeArchive(r, s.path)
+		reader = &r.Reader
+	}
+	for _, file := range reader.File {
+		if err := s.sinkEntry(ctx, extensions, sink, file.FileInfo(), file.Name, file.Open); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ArchiveSourceProvider) getTarSources(ctx context.Context, extensions model.Extensions, sink Sink) error {
+	var r io.Reader = bytes.NewReader(s.content)
+	if s.content == nil {
+		f, err := os.Open(s.path)
+		if err != nil {
+			return errors.Wrap(err, "failed to open archive")
+		}
+		defer closeArchive(f, s.path)
+		r = f
+	}
+	if s.format == archiveTarGz {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return errors.Wrap(err, "failed to open archive")
+		}
+		defer closeArchive(gz, s.path)
+		r = gz
+	}
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read archive")
+		}
+		open := func() (io.ReadCloser, error) {
+			return io.NopCloser(reader), nil
+		}
+		if err := s.sinkEntry(ctx, extensions, sink, header.FileInfo(), header.Name, open); err != nil {
+			return err
+		}
+	}
+}
+
+// sinkEntry executes the sink function on the entry of the archive when it is a file to be scanned
+func (s *ArchiveSourceProvider) sinkEntry(ctx context.Context, extensions model.Extensions, sink Sink,
+	info os.FileInfo, name string, open func() (io.ReadCloser, error)) error {
+	// entries are named relative to the archive, even with absolute or parent paths
+	name = strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+	if condition, _ := s.checkConditions(info, extensions, name); condition.skip || condition.isDir {
+		return nil
+	}
+	rc, err := open()
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s in archive", name)
+	}
+	defer closeArchive(rc, name)
+
+	if err := sink(ctx, path.Join(filepath.ToSlash(s.path), name), rc); err != nil {
+		sentry.CaptureException(err)
+		log.Err(err).
+			Msgf("Archive files provider couldn't parse file, file=%s", name)
+	}
+	return nil
+}
+
+func (s *ArchiveSourceProvider) checkConditions(info os.FileInfo, extensions model.Extensions, name string) (checkCondition, error) {
+	if info.IsDir() {
+		return checkCondition{
+			skip:  true,
+			isDir: true,
+		}, nil
+	}
+	if !info.Mode().IsRegular() || s.excluded(name) {
+		log.Info().Msgf("File ignored: %s", name)
+		return checkCondition{
+			skip:  true,
+			isDir: false,
+		}, nil
+	}
+	if archiveExtensions.Include(filepath.Ext(name)) {
+		log.Info().Msgf("Archive inside archive ignored: %s", name)
+		return checkCondition{
+			skip:    true,
+			isDir:   false,
+			archive: true,
+		}, nil
+	}
+	if !extensions.Include(filepath.Ext(name)) && !extensions.Include(path.Base(name)) {
+		s.unsupported[filepath.Ext(name)]++
+		return checkCondition{
+			skip:  true,
+			isDir: false,
+		}, nil
+	}
+	return checkCondition{
+		skip:  false,
+		isDir: false,
+	}, nil
+}
+
+// excluded returns true if the file or one of its directories matches the patterns of the paths ignored
+func (s *ArchiveSourceProvider) excluded(name string) bool {
+	for _, exclude := range s.excludes {
+		exclude = strings.Trim(filepath.ToSlash(exclude), "/")
+		for dir := name; dir != "." && dir != "/"; dir = path.Dir(dir) {
+			if matched, err := path.Match(exclude, dir); err == nil && matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func archiveFormat(fileName string) string {
+	fileName = strings.ToLower(fileName)
+	for _, format := range []string{archiveTarGz, archiveTar, archiveZip} {
+		if strings.HasSuffix(fileName, format) {
+			return format
+		}
+	}
+	return ""
+}
+
+func closeArchive(closer io.Closer, name string) {
+	if err := closer.Close(); err != nil {
+		log.Err(err).
+			Msgf("Archive files provider couldn't close file, file=%s", name)
+	}
+}
@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+var archiveFiles = []struct {
+	name    string
+	content string
+}{
+	{name: "terraform/main.tf", content: `resource "aws_s3_bucket" "b" {}`},
+	{name: "k8s/pod.yaml", content: "kind: Pod"},
+	{name: "README.md", content: "# bundle"},
+	{name: "test/positive.tf", content: `resource "aws_s3_bucket" "test" {}`},
+	{name: "charts/nginx-1.0.0.tgz", content: "chart"},
+	{name: "../docker/Dockerfile", content: "FROM alpine"},
+}
+
+var archiveExtensionsSupported = model.Extensions{".tf": struct{}{}, ".yaml": struct{}{}, "Dockerfile": struct{}{}}
+
+// TestArchiveSourceProvider_GetSources tests the functions [GetSources()] and all the methods called by them
+func TestArchiveSourceProvider_GetSources(t *testing.T) {
+	dir := t.TempDir()
+	archives := map[string][]byte{
+		"bundle.zip":    zipArchive(t),
+		"bundle.tar":    tarArchive(t),
+		"bundle.tar.gz": tarGzArchive(t),
+	}
+	want := map[string]string{
+		"terraform/main.tf": `resource "aws_s3_bucket" "b" {}`,
+		"k8s/pod.yaml":      "kind: Pod",
+		"docker/Dockerfile": "FROM alpine",
+	}
+	for name, content := range archives {
+		archivePath := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(archivePath, content, 0600))
+		s, err := NewArchiveSourceProvider(archivePath, []string{"test"})
+		require.NoError(t, err, name)
+
+		got := getArchiveSources(t, s)
+		require.Len(t, got, len(want), name)
+		for fileName, fileContent := range want {
+			require.Equal(t, fileContent, got[filepath.ToSlash(archivePath)+"/"+fileName], name)
+		}
+		require.Equal(t, map[string]int{".md": 1}, s.UnsupportedExtensions(), name)
+
+		// archives are provided again, such as for the regex queries
+		require.Len(t, getArchiveSources(t, s), len(want), name)
+	}
+}
+
+// TestNewArchiveSourceProviderFromReader tests the functions [NewArchiveSourceProviderFromReader()] and all the methods called by them
+func TestNewArchiveSourceProviderFromReader(t *testing.T) {
+	s, err := NewArchiveSourceProviderFromReader("upload.tar.gz", bytes.NewReader(tarGzArchive(t)), nil)
+	require.NoError(t, err)
+	require.Equal(t, "upload.tar.gz", s.GetBasePath())
+	got := getArchiveSources(t, s)
+	require.Len(t, got, 4)
+	require.Equal(t, `resource "aws_s3_bucket" "test" {}`, got["upload.tar.gz/test/positive.tf"])
+
+	_, err = NewArchiveSourceProviderFromReader("upload.rar", bytes.NewReader(nil), nil)
+	require.Error(t, err)
+	require.True(t, IsArchive("build/artifact.TAR.GZ"))
+	require.False(t, IsArchive("charts/nginx-1.0.0.tgz"))
+}
+
+func getArchiveSources(t *testing.T, s *ArchiveSourceProvider) map[string]string {
+	got := make(map[string]string)
+	err := s.GetSources(context.Background(), archiveExtensionsSupported,
+		func(ctx context.Context, filename string, rc io.ReadCloser) error {
+			content, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			got[filename] = string(content)
+			return nil
+		},
+		func(ctx context.Context, filename string) error {
+			t.Errorf("unexpected resolver sink of %s", filename)
+			return nil
+		})
+	require.NoError(t, err)
+	return got
+}
+
+func zipArchive(t *testing.T) []byte {
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	_, err := w.Create("terraform/")
+	require.NoError(t, err)
+	for _, file := range archiveFiles {
+		f, err := w.Create(file.name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(file.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return b.Bytes()
+}
+
+func tarArchive(t *testing.T) []byte {
+	var b bytes.Buffer
+	w := tar.NewWriter(&b)
+	require.NoError(t, w.WriteHeader(&tar.Header{Name: "terraform/", Typeflag: tar.TypeDir, Mode: 0755}))
+	require.NoError(t, w.WriteHeader(&tar.Header{Name: "link.tf", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}))
+	for _, file := range archiveFiles {
+		header := &tar.Header{Name: file.name, Typeflag: tar.TypeReg, Mode: 0600, Size: int64(len(file.content))}
+		require.NoError(t, w.WriteHeader(header))
+		_, err := w.Write([]byte(file.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return b.Bytes()
+}
+
+func tarGzArchive(t *testing.T) []byte {
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	_, err := w.Write(tarArchive(t))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return b.Bytes()
+}
@@ -13,21 +13,29 @@ import (
 )
 
 // FileSystemSourceProvider provides a path to be scanned
-// and a list of files which will not be scanned
+// and a list of files which will not be scanned, along with the ignore file of the path, if any
 type FileSystemSourceProvider struct {
-	path     string
-	excludes map[string][]os.FileInfo
+	path        string
+	excludes    map[string][]os.FileInfo
+	ignore      *IgnoreFile
+	unsupported map[string]int
 }
 
 type checkCondition struct {
-	skip  bool
-	isDir bool
+	skip    bool
+	isDir   bool
+	archive bool
 }
 
+// archiveExtensions are the extensions of the archives given to the resolver sink instead of the sink,
+// since they can only be scanned once resolved (ex: packaged helm charts)
+var archiveExtensions = model.Extensions{".tgz": struct{}{}}
+
 // ErrNotSupportedFile - error representing when a file format is not supported by KICS
 var ErrNotSupportedFile = errors.New("invalid file format")
 
-// NewFileSystemSourceProvider initializes a FileSystemSourceProvider with path and files that will be ignored
+// NewFileSystemSourceProvider initializes a FileSystemSourceProvider with path and files that will be ignored,
+// the files excluded by the ignore file of the path are ignored too
 func NewFileSystemSourceProvider(path string, excludes []string) (*FileSystemSourceProvider, error) {
 	log.Debug().Msgf("provider.NewFileSystemSourceProvider()")
 	ex := make(map[string][]os.FileInfo, len(excludes))
@@ -51,9 +59,15 @@ func NewFileSystemSourceProvider(path string, excludes []string) (*FileSystemSou
 		}
 	}
 
+	ignore, err := LoadIgnoreFile(path)
+	if err != nil {
+		return nil, err
+	}
+
 	return &FileSystemSourceProvider{
 		path:     filepath.FromSlash(path),
 		excludes: ex,
+		ignore:   ignore,
 	}, nil
 }
 
@@ -73,15 +87,37 @@ func (s *FileSystemSourceProvider) GetBasePath() string {
 	return s.path
 }
 
+// ExcludedQuery returns true if the results of the query in the file are excluded by the ignore file of the path
+func (s *FileSystemSourceProvider) ExcludedQuery(fileName, queryID string) bool {
+	if s.ignore == nil {
+		return false
+	}
+	relPath, err := filepath.Rel(s.path, filepath.FromSlash(fileName))
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return false
+	}
+	return s.ignore.ExcludedQuery(relPath, queryID)
+}
+
+// UnsupportedExtensions returns how many files of each unsupported extension were found in the last walk
+// of the path, files without extension are counted under an empty extension
+func (s *FileSystemSourceProvider) UnsupportedExtensions() map[string]int {
+	return s.unsupported
+}
+
 // GetSources tries to open file or directory and execute sink function on it
 func (s *FileSystemSourceProvider) GetSources(ctx context.Context,
 	extensions model.Extensions, sink Sink, resolverSink ResolverSink) error {
+	s.unsupported = make(map[string]int)
 	fileInfo, err := os.Stat(s.path)
 	if err != nil {
 		return errors.Wrap(err, "failed to open path")
 	}
 
 	if !fileInfo.IsDir() {
+		if archiveExtensions.Include(filepath.Ext(s.path)) {
+			return resolverSink(ctx, strings.ReplaceAll(s.path, "\\", "/"))
+		}
 		if !extensions.Include(filepath.Ext(s.path)) && !extensions.Include(filepath.Base(s.path)) {
 			return ErrNotSupportedFile
 		}
@@ -101,12 +137,12 @@ func (s *FileSystemSourceProvider) GetSources(ctx context.Context,
 
 		if shouldSkip, skipFolder := s.checkConditions(info, extensions, path); shouldSkip.skip || shouldSkip.isDir {
 			// ------------------ resolver --------------------------------
-			if shouldSkip.isDir && !shouldSkip.skip {
+			if shouldSkip.isDir && !shouldSkip.skip || shouldSkip.archive {
 				err = resolverSink(ctx, strings.ReplaceAll(path, "\\", "/"))
 				if err != nil {
 					sentry.CaptureException(err)
 					log.Err(err).
-						Msgf("Filesystem files provider couldn't Resolve Directory, file=%s", info.Name())
+						Msgf("Filesystem files provider couldn't Resolve %s, file=%s", resolvedName(shouldSkip), info.Name())
 				}
 				return nil
 				// ------------------------------------------------------------
@@ -142,7 +178,7 @@ func closeFile(file *os.File, info os.FileInfo) {
 
 func (s *FileSystemSourceProvider) checkConditions(info os.FileInfo, extensions model.Extensions, path string) (checkCondition, error) {
 	if info.IsDir() {
-		if f, ok := s.excludes[info.Name()]; ok && containsFile(f, info) {
+		if f, ok := s.excludes[info.Name()]; (ok && containsFile(f, info)) || s.ignored(path, true) {
 			log.Info().Msgf("Directory ignored: %s", path)
 			return checkCondition{
 				skip:  true,
@@ -154,14 +190,24 @@ func (s *FileSystemSourceProvider) checkConditions(info os.FileInfo, extensions
 			isDir: true,
 		}, nil
 	}
-	if f, ok := s.excludes[info.Name()]; ok && containsFile(f, info) {
+	if f, ok := s.excludes[info.Name()]; (ok && containsFile(f, info)) || s.ignored(path, false) {
 		log.Info().Msgf("File ignored: %s", path)
 		return checkCondition{
 			skip:  true,
 			isDir: false,
 		}, nil
 	}
+	if archiveExtensions.Include(filepath.Ext(path)) {
+		return checkCondition{
+			skip:    true,
+			isDir:   false,
+			archive: true,
+		}, nil
+	}
 	if !extensions.Include(filepath.Ext(path)) && !extensions.Include(filepath.Base(path)) {
+		if s.unsupported != nil {
+			s.unsupported[filepath.Ext(path)]++
+		}
 		return checkCondition{
 			skip:  true,
 			isDir: false,
@@ -173,6 +219,25 @@ func (s *FileSystemSourceProvider) checkConditions(info os.FileInfo, extensions
 	}, nil
 }
 
+// ignored returns true if the path is excluded by the ignore file, the root is never excluded
+func (s *FileSystemSourceProvider) ignored(path string, isDir bool) bool {
+	if s.ignore == nil {
+		return false
+	}
+	relPath, err := filepath.Rel(s.path, path)
+	if err != nil || relPath == "." || strings.HasPrefix(relPath, "..") {
+		return false
+	}
+	return s.ignore.Excluded(relPath, isDir)
+}
+
+func resolvedName(condition checkCondition) string {
+	if condition.archive {
+		return "Archive"
+	}
+	return "Directory"
+}
+
 func containsFile(fileList []os.FileInfo, target os.FileInfo) bool {
 	for _, file := range fileList {
 		if os.SameFile(file, target) {
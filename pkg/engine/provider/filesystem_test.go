@@ -121,6 +121,22 @@ func TestFileSystemSourceProvider_GetSources(t *testing.T) { //nolint
 			},
 			wantErr: false,
 		},
+		{
+			name: "get_sources_archive",
+			fields: fields{
+				path:     "../../../test/fixtures/test_helm_package/test_helm-0.1.0.tgz",
+				excludes: map[string][]os.FileInfo{},
+			},
+			args: args{
+				ctx: nil,
+				extensions: model.Extensions{
+					".dockerfile": dockerParser.Parser{},
+				},
+				sink:         mockErrSink,
+				resolverSink: mockResolverSink,
+			},
+			wantErr: false,
+		},
 		{
 			name: "error_not_suported_extension",
 			fields: fields{
@@ -257,3 +273,26 @@ var mockResolverSink = func(ctx context.Context, filename string) error {
 var mockErrResolverSink = func(ctx context.Context, filename string) error {
 	return errors.New("")
 }
+
+// TestFileSystemSourceProvider_UnsupportedExtensions tests the functions [UnsupportedExtensions()] and all the methods called by them
+func TestFileSystemSourceProvider_UnsupportedExtensions(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"main.tf", "README.md", "docs.md", "Makefile", "Dockerfile"} {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte{}, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s, err := NewFileSystemSourceProvider(dir, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	extensions := model.Extensions{".tf": struct{}{}, "Dockerfile": struct{}{}}
+	if err := s.GetSources(context.Background(), extensions, mockSink, mockResolverSink); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{".md": 2, "": 1}
+	if got := s.UnsupportedExtensions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("FileSystemSourceProvider.UnsupportedExtensions() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// IgnoreFileName is the name of the ignore file read from the root of the scanned path
+const IgnoreFileName = ".kicsignore"
+
+// IgnoreFile has the rules of an ignore file, with the syntax of .gitignore: patterns without a slash match
+// files and directories at any depth, patterns with a slash are relative to the root, a trailing slash only
+// matches directories, ** matches any number of directories and ! includes again what a previous pattern excluded,
+// the last matching pattern wins, patterns followed by query IDs only exclude the results of these queries
+type IgnoreFile struct {
+	paths   []ignoreRule
+	queries []ignoreRule
+}
+
+type ignoreRule struct {
+	pattern  *regexp.Regexp
+	negate   bool
+	dirOnly  bool
+	queryIDs map[string]bool
+}
+
+// LoadIgnoreFile reads the ignore file of the root directory, nil when the root is not a directory
+// or it has no ignore file
+func LoadIgnoreFile(root string) (*IgnoreFile, error) {
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return nil, nil
+	}
+	f, err := os.Open(filepath.Join(filepath.Clean(root), IgnoreFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to open ignore file")
+	}
+	defer f.Close()
+
+	ignore := &IgnoreFile{}
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		if err := ignore.add(scanner.Text()); err != nil {
+			return nil, errors.Wrapf(err, "invalid line %d of %s", lineNumber, IgnoreFileName)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read ignore file")
+	}
+	log.Debug().Msgf("Ignore file loaded from %s", root)
+	return ignore, nil
+}
+
+func (i *IgnoreFile) add(line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+	fields := strings.Fields(line)
+	rule := ignoreRule{}
+	pattern := fields[0]
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	}
+	pattern = strings.TrimPrefix(pattern, "\\")
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	if pattern == "" {
+		return fmt.Errorf("empty pattern: %s", line)
+	}
+	var err error
+	if rule.pattern, err = compileIgnorePattern(pattern); err != nil {
+		return err
+	}
+	if len(fields) == 1 {
+		i.paths = append(i.paths, rule)
+		return nil
+	}
+	if rule.negate {
+		return fmt.Errorf("patterns with query IDs can not be negated: %s", line)
+	}
+	rule.queryIDs = make(map[string]bool, len(fields)-1)
+	for _, id := range fields[1:] {
+		for _, queryID := range strings.Split(id, ",") {
+			if queryID != "" {
+				rule.queryIDs[queryID] = true
+			}
+		}
+	}
+	i.queries = append(i.queries, rule)
+	return nil
+}
+
+// compileIgnorePattern returns the regular expression of a pattern, matching the paths relative to the root
+func compileIgnorePattern(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	var expr strings.Builder
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character class in pattern %s", pattern)
+			}
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	compiled, err := regexp.Compile("^" + expr.String() + "$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %s: %s", pattern, err)
+	}
+	return compiled, nil
+}
+
+// Excluded returns true if the path, relative to the root, is excluded by the ignore file,
+// the files of excluded directories are always excluded
+func (i *IgnoreFile) Excluded(relPath string, isDir bool) bool {
+	if i == nil {
+		return false
+	}
+	relPath = filepath.ToSlash(relPath)
+	parents := strings.Split(relPath, "/")
+	for j := 1; j < len(parents); j++ {
+		if i.match(strings.Join(parents[:j], "/"), true) {
+			return true
+		}
+	}
+	return i.match(relPath, isDir)
+}
+
+func (i *IgnoreFile) match(relPath string, isDir bool) bool {
+	excluded := false
+	for _, rule := range i.paths {
+		if (!rule.dirOnly || isDir) && rule.pattern.MatchString(relPath) {
+			excluded = !rule.negate
+		}
+	}
+	return excluded
+}
+
+// ExcludedQuery returns true if the results of the query in the file, relative to the root,
+// are excluded by the ignore file
+func (i *IgnoreFile) ExcludedQuery(relPath, queryID string) bool {
+	if i == nil {
+		return false
+	}
+	relPath = filepath.ToSlash(relPath)
+	parents := strings.Split(relPath, "/")
+	for _, rule := range i.queries {
+		if !rule.queryIDs[queryID] {
+			continue
+		}
+		for j := 1; j <= len(parents); j++ {
+			isDir := j < len(parents)
+			if (!rule.dirOnly || isDir) && rule.pattern.MatchString(strings.Join(parents[:j], "/")) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIgnoreFile tests the functions [LoadIgnoreFile()], [IgnoreFile.Excluded()], [IgnoreFile.ExcludedQuery()]
+// and all the methods called by them
+func TestIgnoreFile(t *testing.T) {
+	root := t.TempDir()
+	content := `# generated files
+*.generated.tf
+/vendor/
+modules/**/examples
+!modules/keep/examples
+build/
+terraform/legacy/** 4728cd65-a20c-49da-8b31-9c08b423e4db,e38a8e0a-b88b-4902-b3fe-b0fcb17d5c10
+`
+	require.NoError(t, os.WriteFile(filepath.Join(root, IgnoreFileName), []byte(content), 0600))
+
+	ignore, err := LoadIgnoreFile(root)
+	require.NoError(t, err)
+
+	excluded := map[string]bool{
+		"main.tf":                              false,
+		"network/main.generated.tf":            true,
+		"vendor":                               true,
+		"vendor/module/main.tf":                true,
+		"modules/vendor/main.tf":               false,
+		"modules/vpc/examples/main.tf":         true,
+		"modules/vpc/nested/examples/main.tf":  true,
+		"modules/keep/examples/main.tf":        false,
+		"app/build/deployment.yaml":            true,
+		"terraform/legacy/main.tf":             false,
+		"terraform/legacy/build.tf/nested.tf":  false,
+		"terraform/legacy/build/deployment.tf": true,
+	}
+	for path, want := range excluded {
+		require.Equal(t, want, ignore.Excluded(path, false), path)
+	}
+	require.False(t, ignore.Excluded("build", false), "patterns with a trailing slash only match directories")
+
+	require.True(t, ignore.ExcludedQuery("terraform/legacy/main.tf", "4728cd65-a20c-49da-8b31-9c08b423e4db"))
+	require.True(t, ignore.ExcludedQuery("terraform/legacy/db/main.tf", "e38a8e0a-b88b-4902-b3fe-b0fcb17d5c10"))
+	require.False(t, ignore.ExcludedQuery("terraform/legacy/main.tf", "other"))
+	require.False(t, ignore.ExcludedQuery("terraform/main.tf", "4728cd65-a20c-49da-8b31-9c08b423e4db"))
+
+	missing, err := LoadIgnoreFile(filepath.Join(root, "main.tf"))
+	require.NoError(t, err)
+	require.Nil(t, missing)
+	require.False(t, missing.Excluded("main.tf", false))
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, IgnoreFileName), []byte("!legacy/ query-id\n"), 0600))
+	_, err = LoadIgnoreFile(root)
+	require.Error(t, err)
+}
+
+// TestFileSystemSourceProvider_IgnoreFile tests the files skipped with the ignore file of the path
+func TestFileSystemSourceProvider_IgnoreFile(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{"main.tf", "legacy/main.tf", "network/vpc.tf", "network/vpc_test.tf"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(root, file)), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(root, file), []byte(""), 0600))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(root, IgnoreFileName), []byte("legacy/\n*_test.tf\nnetwork/ q1\n"), 0600))
+
+	p, err := NewFileSystemSourceProvider(root, []string{})
+	require.NoError(t, err)
+	var found []string
+	err = p.GetSources(context.Background(), model.Extensions{".tf": struct{}{}},
+		func(ctx context.Context, filename string, rc io.ReadCloser) error {
+			found = append(found, strings.TrimPrefix(filename, filepath.ToSlash(root)+"/"))
+			return nil
+		},
+		func(ctx context.Context, filename string) error {
+			return nil
+		})
+	require.NoError(t, err)
+	sort.Strings(found)
+	require.Equal(t, []string{"main.tf", "network/vpc.tf"}, found)
+
+	require.True(t, p.ExcludedQuery(filepath.ToSlash(filepath.Join(root, "network/vpc.tf")), "q1"))
+	require.False(t, p.ExcludedQuery(filepath.ToSlash(filepath.Join(root, "main.tf")), "q1"))
+}
@@ -0,0 +1,343 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/engine/provider"
+	"github.com/Checkmarx/kics/pkg/engine/source"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// maxRegexFileSize is the size limit of the files read by the regex inspector
+const maxRegexFileSize = 5 * 1048576
+
+// RegexQuery is a query that matches a regular expression against the raw content of files
+// Files lists the extensions (".properties") or file names ("Jenkinsfile") the query applies to
+// Message is the actual value of the results, it can reference the capture groups of Pattern as
+// $1 or ${name}, the same for Expected
+type RegexQuery struct {
+	ID              string   `yaml:"id" json:"id"`
+	QueryName       string   `yaml:"queryName" json:"queryName"`
+	Severity        string   `yaml:"severity" json:"severity"`
+	Category        string   `yaml:"category" json:"category"`
+	DescriptionText string   `yaml:"descriptionText" json:"descriptionText"`
+	DescriptionURL  string   `yaml:"descriptionUrl" json:"descriptionUrl"`
+	Platform        string   `yaml:"platform" json:"platform"`
+	Files           []string `yaml:"files" json:"files"`
+	Pattern         string   `yaml:"pattern" json:"pattern"`
+	Expected        string   `yaml:"expected" json:"expected"`
+	Message         string   `yaml:"message" json:"message"`
+}
+
+type regexQueries struct {
+	Queries []RegexQuery `yaml:"queries" json:"queries"`
+}
+
+type preparedRegexQuery struct {
+	query      RegexQuery
+	pattern    *regexp.Regexp
+	extensions model.Extensions
+	prepared   *preparedQuery
+}
+
+// RegexInspector evaluates regex queries against the raw content of the files given by its source provider,
+// including files in formats KICS can't parse
+type RegexInspector struct {
+	queries        []*preparedRegexQuery
+	sourceProvider provider.SourceProvider
+	vb             VulnerabilityBuilder
+	tracker        Tracker
+	failedQueries  map[string]error
+	excludeResults map[string]bool
+
+	failFast
+	deadline
+}
+
+// NewRegexInspector initializes a regex inspector, loading and compiling the queries found in queriesPath
+// queriesPath can be a queries file or a directory containing queries files (.yaml, .yml or .json)
+func NewRegexInspector(
+	queriesPath string,
+	sourceProvider provider.SourceProvider,
+	vb VulnerabilityBuilder,
+	tracker Tracker,
+	excludeQueries source.ExcludeQueries,
+	excludeResults map[string]bool) (*RegexInspector, error) {
+	log.Debug().Msg("engine.NewRegexInspector()")
+
+	queries, err := loadRegexQueries(queriesPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load regex queries")
+	}
+
+	prepared := make([]*preparedRegexQuery, 0, len(queries))
+	for i := range queries {
+		if isExcludedQuery(queries[i].ID, queries[i].Category, excludeQueries) {
+			log.Debug().
+				Msgf("Excluding query ID: %s category: %s", queries[i].ID, queries[i].Category)
+			continue
+		}
+		query, err := prepareRegexQuery(&queries[i])
+		if err != nil {
+			sentry.CaptureException(err)
+			log.Err(err).
+				Msgf("Regex inspector failed to prepare query, query=%s", queries[i].ID)
+
+			continue
+		}
+		tracker.TrackQueryLoad(1)
+		prepared = append(prepared, query)
+	}
+
+	log.Info().
+		Msgf("Regex inspector initialized, number of queries=%d", len(prepared))
+
+	return &RegexInspector{
+		queries:        prepared,
+		sourceProvider: sourceProvider,
+		vb:             vb,
+		tracker:        tracker,
+		failedQueries:  make(map[string]error),
+		excludeResults: excludeResults,
+	}, nil
+}
+
+func loadRegexQueries(queriesPath string) ([]RegexQuery, error) {
+	paths, err := listPolicyFiles(queriesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := make([]RegexQuery, 0)
+	for _, path := range paths {
+		content, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return nil, err
+		}
+		var file regexQueries
+		if err := yaml.Unmarshal(content, &file); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode regex queries file %s", path)
+		}
+		queries = append(queries, file.Queries...)
+	}
+	return queries, nil
+}
+
+func isExcludedQuery(queryID, queryCategory string, excludeQueries source.ExcludeQueries) bool {
+	for _, id := range excludeQueries.ByIDs {
+		if queryID == id {
+			return true
+		}
+	}
+	for _, category := range excludeQueries.ByCategories {
+		if queryCategory == category {
+			return true
+		}
+	}
+	return false
+}
+
+func prepareRegexQuery(query *RegexQuery) (*preparedRegexQuery, error) {
+	if query.ID == "" || query.Pattern == "" || len(query.Files) == 0 {
+		return nil, errors.New("regex query must define an id, a pattern and the files it applies to")
+	}
+	pattern, err := regexp.Compile(query.Pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to compile pattern %q", query.Pattern)
+	}
+	extensions := make(model.Extensions, len(query.Files))
+	for _, file := range query.Files {
+		extensions[file] = struct{}{}
+	}
+	platform := query.Platform
+	if platform == "" {
+		platform = "Common"
+	}
+
+	return &preparedRegexQuery{
+		query:      *query,
+		pattern:    pattern,
+		extensions: extensions,
+		prepared: &preparedQuery{
+			metadata: model.QueryMetadata{
+				Query: query.ID,
+				Metadata: map[string]interface{}{
+					"id":              query.ID,
+					"queryName":       query.QueryName,
+					"severity":        query.Severity,
+					"category":        query.Category,
+					"descriptionText": query.DescriptionText,
+					"descriptionUrl":  query.DescriptionURL,
+					"platform":        platform,
+				},
+				Platform:    platform,
+				Aggregation: 1,
+			},
+		},
+	}, nil
+}
+
+// Inspect reads the files of the source provider matched by the regex queries and returns the vulnerabilities found
+func (c *RegexInspector) Inspect(
+	ctx context.Context,
+	scanID string,
+	files model.FileMetadatas,
+	hideProgress bool,
+	baseScanPath string) ([]model.Vulnerability, error) {
+	log.Debug().Msg("engine.RegexInspector.Inspect()")
+
+	vulnerabilities := make([]model.Vulnerability, 0)
+	if len(c.queries) == 0 {
+		return vulnerabilities, nil
+	}
+
+	rawFiles, err := c.getRawFiles(ctx, scanID)
+	if err != nil {
+		return nil, err
+	}
+	filesMap := rawFiles.ToMap()
+	for _, query := range c.queries {
+		if c.skipQuery(query.prepared.metadata.Query) {
+			continue
+		}
+		queryCtx := &QueryContext{
+			ctx:          ctx,
+			scanID:       scanID,
+			files:        filesMap,
+			query:        query.prepared,
+			baseScanPath: baseScanPath,
+		}
+		for i := range rawFiles {
+			if !query.extensions.Include(filepath.Ext(rawFiles[i].FileName)) &&
+				!query.extensions.Include(filepath.Base(rawFiles[i].FileName)) {
+				continue
+			}
+			vulns := c.inspectFile(queryCtx, query, &rawFiles[i])
+			vulnerabilities = append(vulnerabilities, vulns...)
+			if c.shouldStop(vulns) {
+				return vulnerabilities, nil
+			}
+		}
+		c.tracker.TrackQueryExecution(1)
+	}
+	return vulnerabilities, nil
+}
+
+// GetFailedQueries returns a map of failed queries and the associated error
+func (c *RegexInspector) GetFailedQueries() map[string]error {
+	return c.failedQueries
+}
+
+// getRawFiles reads the files matched by any of the regex queries, without parsing them
+func (c *RegexInspector) getRawFiles(ctx context.Context, scanID string) (model.FileMetadatas, error) {
+	extensions := make(model.Extensions)
+	for _, query := range c.queries {
+		for extension := range query.extensions {
+			extensions[extension] = struct{}{}
+		}
+	}
+
+	files := make(model.FileMetadatas, 0)
+	err := c.sourceProvider.GetSources(
+		ctx,
+		extensions,
+		func(ctx context.Context, filename string, rc io.ReadCloser) error {
+			content, err := io.ReadAll(io.LimitReader(rc, maxRegexFileSize+1))
+			if err != nil {
+				return errors.Wrapf(err, "failed to get file content: %s", filename)
+			}
+			if len(content) > maxRegexFileSize {
+				return errors.Errorf("file size limit exceeded: %s", filename)
+			}
+			files = append(files, model.FileMetadata{
+				ID:           uuid.New().String(),
+				ScanID:       scanID,
+				Document:     model.Document{},
+				OriginalData: string(content),
+				Kind:         model.KindCOMMON,
+				FileName:     filename,
+			})
+			return nil
+		},
+		func(ctx context.Context, filename string) error {
+			return nil
+		},
+	)
+	if err != nil && !errors.Is(err, provider.ErrNotSupportedFile) {
+		return nil, errors.Wrap(err, "failed to read sources")
+	}
+	return files, nil
+}
+
+func (c *RegexInspector) inspectFile(ctx *QueryContext, query *preparedRegexQuery, file *model.FileMetadata) []model.Vulnerability {
+	vulnerabilities := make([]model.Vulnerability, 0)
+	lines := strings.Split(strings.ReplaceAll(file.OriginalData, "\r", ""), "\n")
+	occurrences := make(map[string]int)
+	for _, match := range query.pattern.FindAllStringSubmatchIndex(file.OriginalData, -1) {
+		matched := strings.SplitN(file.OriginalData[match[0]:match[1]], "\n", 2)[0]
+		occurrences[matched]++
+
+		vulnerability, err := c.vb(ctx, c.tracker, query.result(file, match, matched, occurrences[matched]))
+		if err != nil {
+			sentry.CaptureException(err)
+			log.Err(err).
+				Msgf("Regex inspector can't save vulnerability, query=%s", query.query.ID)
+
+			if _, ok := c.failedQueries[query.query.ID]; !ok {
+				c.failedQueries[query.query.ID] = err
+			}
+
+			continue
+		}
+
+		// the line of the match is known, so it replaces the line detected from the search key
+		line := strings.Count(file.OriginalData[:match[0]], "\n")
+		vulnerability.Line = line + 1
+		vulnerability.VulnLines = getAdjacentLines(line, c.tracker.GetOutputLines(), lines)
+
+		if _, ok := c.excludeResults[vulnerability.SimilarityID]; ok {
+			log.Debug().
+				Msgf("Excluding result SimilarityID: %s", vulnerability.SimilarityID)
+		} else {
+			vulnerabilities = append(vulnerabilities, vulnerability)
+		}
+	}
+	return vulnerabilities
+}
+
+// result builds the query result of a match, in the same format of the rego queries
+// the search value is the occurrence of the matched text in the file, so identical matches have different similarity IDs
+func (q *preparedRegexQuery) result(file *model.FileMetadata, match []int, matched string, occurrence int) map[string]interface{} {
+	expected := fmt.Sprintf("'%s' should not match", q.query.Pattern)
+	if q.query.Expected != "" {
+		expected = q.expand(q.query.Expected, file.OriginalData, match)
+	}
+	actual := fmt.Sprintf("'%s' matches", matched)
+	if q.query.Message != "" {
+		actual = q.expand(q.query.Message, file.OriginalData, match)
+	}
+	return map[string]interface{}{
+		"documentId":       file.ID,
+		"searchKey":        fmt.Sprintf("{{%s}}", matched),
+		"searchValue":      strconv.Itoa(occurrence),
+		"issueType":        string(model.IssueTypeIncorrectValue),
+		"keyExpectedValue": expected,
+		"keyActualValue":   actual,
+	}
+}
+
+func (q *preparedRegexQuery) expand(template, content string, match []int) string {
+	return string(q.pattern.ExpandString(nil, template, content, match))
+}
@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Checkmarx/kics/internal/tracker"
+	"github.com/Checkmarx/kics/pkg/engine/provider"
+	"github.com/Checkmarx/kics/pkg/engine/source"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/test"
+)
+
+// TestRegexInspector_Inspect tests the functions [NewRegexInspector(), Inspect()] and all the methods called by them
+func TestRegexInspector_Inspect(t *testing.T) {
+	if err := test.ChangeCurrentDir("kics"); err != nil {
+		t.Fatal(err)
+	}
+
+	sourceProvider, err := provider.NewFileSystemSourceProvider(filepath.FromSlash("test/fixtures/test_regex_queries/src"), []string{})
+	require.NoError(t, err)
+
+	track := &tracker.CITracker{}
+	inspector, err := NewRegexInspector(
+		filepath.FromSlash("test/fixtures/test_regex_queries/queries.yaml"),
+		sourceProvider,
+		DefaultVulnerabilityBuilder,
+		track,
+		source.ExcludeQueries{},
+		map[string]bool{},
+	)
+	require.NoError(t, err)
+	require.Len(t, inspector.queries, 2)
+	require.Equal(t, 2, track.LoadedQueries)
+
+	vulnerabilities, err := inspector.Inspect(context.Background(), "console", model.FileMetadatas{}, true, "")
+	require.NoError(t, err)
+	require.Len(t, vulnerabilities, 3)
+	require.Empty(t, inspector.GetFailedQueries())
+	require.Equal(t, 2, track.ExecutedQueries)
+
+	require.Equal(t, "Hardcoded Password In Properties", vulnerabilities[0].QueryName)
+	require.Equal(t, 3, vulnerabilities[0].Line)
+	require.Equal(t, "'spring.datasource.password' should reference a secret", vulnerabilities[0].KeyExpectedValue)
+	require.Equal(t, "'spring.datasource.password' is set to 's3cr3t'", vulnerabilities[0].KeyActualValue)
+	require.Equal(t, model.Severity(model.SeverityHigh), vulnerabilities[0].Severity)
+	require.Equal(t, "Common", vulnerabilities[0].Platform)
+	require.Equal(t, 4, vulnerabilities[1].Line)
+	require.NotEqual(t, vulnerabilities[0].SimilarityID, vulnerabilities[1].SimilarityID)
+
+	require.Equal(t, "Jenkins Agent Any", vulnerabilities[2].QueryName)
+	require.Equal(t, 2, vulnerabilities[2].Line)
+	require.Equal(t, "'agent any' matches", vulnerabilities[2].KeyActualValue)
+	require.Equal(t, "Jenkins", vulnerabilities[2].Platform)
+
+	excluded, err := NewRegexInspector(
+		filepath.FromSlash("test/fixtures/test_regex_queries"),
+		sourceProvider,
+		DefaultVulnerabilityBuilder,
+		&tracker.CITracker{},
+		source.ExcludeQueries{ByIDs: []string{"7a6b5c4d-3e2f-4a1b-9c8d-7e6f5a4b3c2d"}},
+		map[string]bool{vulnerabilities[0].SimilarityID: true},
+	)
+	require.NoError(t, err)
+	require.Len(t, excluded.queries, 1)
+	vulnerabilities, err = excluded.Inspect(context.Background(), "console", model.FileMetadatas{}, true, "")
+	require.NoError(t, err)
+	require.Len(t, vulnerabilities, 1)
+	require.Equal(t, 4, vulnerabilities[0].Line)
+}
@@ -0,0 +1,60 @@
+package source
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+type categoriesFile struct {
+	Categories []model.Category `yaml:"categories"`
+}
+
+// LoadCategories reads the custom categories of a YAML file and adds them to the built-in categories,
+// an empty path adds no category
+func LoadCategories(categoriesPath string) error {
+	if categoriesPath == "" {
+		return nil
+	}
+	f, err := os.Open(filepath.Clean(categoriesPath))
+	if err != nil {
+		return errors.Wrap(err, "failed to open custom categories")
+	}
+	defer f.Close()
+
+	var file categoriesFile
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && err != io.EOF {
+		return errors.Wrapf(err, "failed to parse custom categories %s", categoriesPath)
+	}
+	return errors.Wrapf(model.AddCategories(file.Categories), "invalid custom categories %s", categoriesPath)
+}
+
+// warnUnknownCategories logs the categories of the queries that are neither built-in nor custom categories
+func warnUnknownCategories(queries []model.QueryMetadata) {
+	unknown := make(map[string]int)
+	for i := range queries {
+		category, ok := queries[i].Metadata["category"].(string)
+		if ok && !model.IsCategory(category) {
+			unknown[category]++
+		}
+	}
+	if len(unknown) == 0 {
+		return
+	}
+	categories := make([]string, 0, len(unknown))
+	for category, count := range unknown {
+		categories = append(categories, category+" ("+strconv.Itoa(count)+" queries)")
+	}
+	sort.Strings(categories)
+	log.Warn().Msgf("Queries with unknown categories, add them to the custom categories: %s", strings.Join(categories, ", "))
+}
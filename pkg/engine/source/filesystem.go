@@ -17,9 +17,11 @@ import (
 // FilesystemSource this type defines a struct with a path to a filesystem source of queries
 // Source is the path to the queries
 // Types are the types given by the flag --type for query selection mechanism
+// Overrides are the overrides of the queries metadata
 type FilesystemSource struct {
-	Source string
-	Types  []string
+	Source    string
+	Types     []string
+	Overrides Overrides
 }
 
 const (
@@ -35,11 +37,15 @@ const (
 
 var (
 	supportedPlatforms = map[string]string{
-		"Ansible":        "ansible",
-		"CloudFormation": "cloudformation",
-		"Dockerfile":     "dockerfile",
-		"Kubernetes":     "k8s",
-		"Terraform":      "terraform",
+		"Ansible":                 "ansible",
+		"AzureResourceManager":    "azureresourcemanager",
+		"CloudFormation":          "cloudformation",
+		"Dockerfile":              "dockerfile",
+		"GoogleDeploymentManager": "googledeploymentmanager",
+		"Kubernetes":              "k8s",
+		"Puppet":                  "puppet",
+		"SaltStack":               "saltstack",
+		"Terraform":               "terraform",
 	}
 )
 
@@ -150,6 +156,7 @@ func (s *FilesystemSource) GetQueries(excludeQueries ExcludeQueries) ([]model.Qu
 	}
 
 	queries := make([]model.QueryMetadata, 0, len(queryDirs))
+	overridden := make(map[string]bool, len(s.Overrides))
 	for _, queryDir := range queryDirs {
 		query, errRQ := ReadQuery(queryDir)
 		if errRQ != nil {
@@ -160,9 +167,15 @@ func (s *FilesystemSource) GetQueries(excludeQueries ExcludeQueries) ([]model.Qu
 			continue
 		}
 
+		if id, ok := query.Metadata["id"].(string); ok {
+			overridden[id] = true
+		}
 		if !s.CheckType(query.Metadata["platform"]) {
 			continue
 		}
+		if !s.Overrides.apply(&query) {
+			continue
+		}
 		if checkQueryExclude(query.Metadata["id"], excludeQueries.ByIDs) ||
 			checkQueryExclude(query.Metadata["category"], excludeQueries.ByCategories) {
 			log.Debug().
@@ -172,6 +185,12 @@ func (s *FilesystemSource) GetQueries(excludeQueries ExcludeQueries) ([]model.Qu
 
 		queries = append(queries, query)
 	}
+	for id := range s.Overrides {
+		if !overridden[id] {
+			log.Warn().Msgf("Query overrides reference unknown query %s", id)
+		}
+	}
+	warnUnknownCategories(queries)
 
 	return queries, err
 }
@@ -237,12 +256,20 @@ func getPlatform(queryPath string) string {
 		return "common"
 	} else if strings.Contains(queryPath, "ansible") {
 		return "ansible"
+	} else if strings.Contains(queryPath, "azureResourceManager") {
+		return "azureResourceManager"
 	} else if strings.Contains(queryPath, "cloudFormation") {
 		return "cloudFormation"
 	} else if strings.Contains(queryPath, "dockerfile") {
 		return "dockerfile"
+	} else if strings.Contains(queryPath, "googleDeploymentManager") {
+		return "googleDeploymentManager"
 	} else if strings.Contains(queryPath, "k8s") {
 		return "k8s"
+	} else if strings.Contains(queryPath, "puppet") {
+		return "puppet"
+	} else if strings.Contains(queryPath, "saltstack") {
+		return "saltstack"
 	} else if strings.Contains(queryPath, "terraform") {
 		return "terraform"
 	}
@@ -303,6 +303,13 @@ func Test_getPlatform(t *testing.T) {
 			},
 			want: "ansible",
 		},
+		{
+			name: "get_platform_azure_resource_manager",
+			args: args{
+				queryPath: "../test/azureResourceManager/test",
+			},
+			want: "azureResourceManager",
+		},
 		{
 			name: "get_platform_cloudFormation",
 			args: args{
@@ -317,6 +324,13 @@ func Test_getPlatform(t *testing.T) {
 			},
 			want: "dockerfile",
 		},
+		{
+			name: "get_platform_google_deployment_manager",
+			args: args{
+				queryPath: "../test/googleDeploymentManager/test",
+			},
+			want: "googleDeploymentManager",
+		},
 		{
 			name: "get_platform_k8s",
 			args: args{
@@ -324,6 +338,20 @@ func Test_getPlatform(t *testing.T) {
 			},
 			want: "k8s",
 		},
+		{
+			name: "get_platform_puppet",
+			args: args{
+				queryPath: "../test/puppet/test",
+			},
+			want: "puppet",
+		},
+		{
+			name: "get_platform_saltstack",
+			args: args{
+				queryPath: "../test/saltstack/test",
+			},
+			want: "saltstack",
+		},
 		{
 			name: "get_platform_terraform",
 			args: args{
@@ -345,9 +373,13 @@ func Test_getPlatform(t *testing.T) {
 func TestListSupportedPlatforms(t *testing.T) {
 	expected := []string{
 		"Ansible",
+		"AzureResourceManager",
 		"CloudFormation",
 		"Dockerfile",
+		"GoogleDeploymentManager",
 		"Kubernetes",
+		"Puppet",
+		"SaltStack",
 		"Terraform",
 	}
 	actual := ListSupportedPlatforms()
@@ -0,0 +1,214 @@
+package source
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const gitForcedGetter = "git::"
+
+// commitRegex matches full commit hashes, refs that never change once fetched
+var commitRegex = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// GitOptions configures how queries are fetched from git repositories
+// CacheDir is the directory where the repositories are checked out, Env is the environment of the git commands,
+// such as the proxy and the tokens of the git hosts, Offline only uses the repositories already checked out,
+// SSHKey is the private key of SSH addresses, such as a deploy key, Submodules also checks out the submodules
+// and Sparse only checks out the subdirectory of the address, with a partial clone fetching only the files checked out
+type GitOptions struct {
+	CacheDir   string
+	Env        []string
+	Offline    bool
+	SSHKey     string
+	Submodules bool
+	Sparse     bool
+}
+
+// gitAddress is a git repository address split in the repository URL, the subdirectory and the ref to check out
+type gitAddress struct {
+	repository string
+	subdir     string
+	ref        string
+}
+
+// IsGitSource returns true if the queries path is the address of a git repository: addresses with the git:: prefix,
+// SSH addresses, HTTP(S) URLs of repositories ending with .git and URLs of Azure Repos
+func IsGitSource(queriesPath string) bool {
+	if strings.HasPrefix(queriesPath, gitForcedGetter) || strings.HasPrefix(queriesPath, "git@") ||
+		strings.HasPrefix(queriesPath, "ssh://") {
+		return true
+	}
+	if !strings.HasPrefix(queriesPath, "https://") && !strings.HasPrefix(queriesPath, "http://") {
+		return false
+	}
+	address, err := parseGitAddress(queriesPath)
+	return err == nil && (strings.HasSuffix(address.repository, ".git") || strings.Contains(address.repository, "/_git/"))
+}
+
+// FetchGitSource checks out the ref of the git repository of the address and returns the local path of its subdirectory,
+// addresses follow the go-getter syntax of Terraform module sources (ex: git::https://example.com/policies.git//queries?ref=v1.0.0)
+// and default to the HEAD of the repository when no ref is given, refs other than commit hashes are fetched at each call
+// and the previous checkout is used when they can't be fetched
+func FetchGitSource(queriesPath string, opts GitOptions) (string, error) {
+	address, err := parseGitAddress(queriesPath)
+	if err != nil {
+		return "", err
+	}
+	key := address.repository + "?ref=" + address.ref
+	if opts.Submodules {
+		key += "&submodules"
+	}
+	if opts.Sparse && address.subdir != "" {
+		key += "&sparse"
+	}
+	sum := sha256.Sum256([]byte(key))
+	dir := filepath.Join(opts.CacheDir, hex.EncodeToString(sum[:]))
+	repository := redactURL(address.repository)
+
+	head, cached := gitHead(dir, opts.Env)
+	switch {
+	case cached && (opts.Offline || (commitRegex.MatchString(address.ref) && head == address.ref)):
+		log.Info().Msgf("Using %s at commit %s from cache", repository, head)
+	case opts.Offline:
+		return "", errors.Errorf("%s not available in cache while in offline mode", repository)
+	default:
+		if err := gitFetch(dir, address, opts); err != nil {
+			if !cached {
+				return "", errors.Wrapf(err, "failed to fetch %s", repository)
+			}
+			log.Warn().Msgf("Failed to fetch %s, using cached commit %s: %s", repository, head, err)
+			break
+		}
+		head, _ = gitHead(dir, opts.Env)
+		log.Info().Msgf("Fetched %s at commit %s", repository, head)
+	}
+	return filepath.Join(dir, filepath.FromSlash(address.subdir)), nil
+}
+
+// parseGitAddress splits a git address in the repository URL, the subdirectory after // and the ref parameter
+func parseGitAddress(queriesPath string) (*gitAddress, error) {
+	address := &gitAddress{}
+	repository := strings.TrimPrefix(queriesPath, gitForcedGetter)
+	if i := strings.LastIndex(repository, "?"); i >= 0 {
+		query, err := url.ParseQuery(repository[i+1:])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid git address %s", redactURL(queriesPath))
+		}
+		address.ref = query.Get("ref")
+		repository = repository[:i]
+	}
+
+	schemeEnd := 0
+	if i := strings.Index(repository, "://"); i >= 0 {
+		schemeEnd = i + len("://")
+	}
+	if i := strings.Index(repository[schemeEnd:], "//"); i >= 0 {
+		address.subdir = strings.Trim(repository[schemeEnd+i+len("//"):], "/")
+		repository = repository[:schemeEnd+i]
+	}
+	if address.subdir != "" && (filepath.IsAbs(address.subdir) || strings.Contains(address.subdir, "..")) {
+		return nil, errors.Errorf("invalid subdirectory %s in git address %s", address.subdir, redactURL(queriesPath))
+	}
+	if repository == "" || strings.HasPrefix(address.ref, "-") {
+		return nil, errors.Errorf("invalid git address %s", redactURL(queriesPath))
+	}
+	address.repository = repository
+	return address, nil
+}
+
+// gitFetch fetches the ref of the repository into dir and checks it out, along with its submodules when enabled
+func gitFetch(dir string, address *gitAddress, opts GitOptions) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	ref := address.ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	commands := [][]string{
+		{"init", "--quiet"},
+		{"fetch", "--quiet", "--depth", "1", "--", address.repository, ref},
+		{"checkout", "--quiet", "--force", "FETCH_HEAD"},
+	}
+	env := gitAuthEnv(address.repository, opts)
+	if opts.Sparse && address.subdir != "" {
+		commands = sparseCommands(address, ref)
+	}
+	if repository := redactURL(address.repository); opts.Sparse && repository != address.repository {
+		// the credentials of the URL are not kept in the configuration of the checkout
+		defer func() {
+			if _, err := RunGit(dir, env, "config", "remote.origin.url", repository); err != nil {
+				log.Warn().Msgf("Failed to remove the credentials of %s from its checkout: %s", repository, err)
+			}
+		}()
+	}
+	if opts.Submodules {
+		commands = append(commands, []string{"submodule", "update", "--quiet", "--init", "--recursive", "--depth", "1"})
+	}
+	for _, args := range commands {
+		if _, err := RunGit(dir, env, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sparseCommands returns the commands checking out only the subdirectory of the address, the repository is fetched
+// as a partial clone without the files, fetched by the checkout of the subdirectory, so the repository is kept
+// as its promisor remote
+func sparseCommands(address *gitAddress, ref string) [][]string {
+	return [][]string{
+		{"init", "--quiet"},
+		{"config", "remote.origin.url", address.repository},
+		{"config", "remote.origin.promisor", "true"},
+		{"config", "remote.origin.partialclonefilter", "blob:none"},
+		{"sparse-checkout", "set", "--cone", "--", address.subdir},
+		{"fetch", "--quiet", "--depth", "1", "--filter=blob:none", "origin", ref},
+		{"checkout", "--quiet", "--force", "FETCH_HEAD"},
+	}
+}
+
+// gitHead returns the commit checked out in dir, if any
+func gitHead(dir string, env []string) (string, bool) {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return "", false
+	}
+	head, err := RunGit(dir, env, "rev-parse", "HEAD")
+	return head, err == nil
+}
+
+// RunGit runs git in dir with env, the environment of the process when nil, and returns its trimmed output,
+// the credentials of the URLs of its errors are redacted
+func RunGit(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...) //nolint:gosec
+	if env == nil {
+		env = os.Environ()
+	}
+	// never wait for credentials in a scan
+	cmd.Env = append(append([]string{}, env...), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", errors.Errorf("git %s: %s", args[0], redactURL(strings.TrimSpace(stderr.String())))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// credentialsRegex matches the user information of URLs
+var credentialsRegex = regexp.MustCompile(`://[^/@\s]+@`)
+
+// redactURL removes the credentials of the URLs of text
+func redactURL(text string) string {
+	return credentialsRegex.ReplaceAllString(text, "://")
+}
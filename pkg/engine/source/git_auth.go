@@ -0,0 +1,164 @@
+package source
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// gitHostToken is a git host whose token is read from the environment, sent with basic authentication
+// with the user given, the user is read from the user variable when it is set
+type gitHostToken struct {
+	urls     []string
+	tokenEnv []string
+	user     string
+	userEnv  string
+}
+
+// gitHostTokens are the git hosts with tokens from the environment, such as the access token of Azure Pipelines,
+// their tokens are only sent to the URLs of the host
+var gitHostTokens = []gitHostToken{
+	{
+		urls:     []string{"https://dev.azure.com/", "https://*.visualstudio.com/"},
+		tokenEnv: []string{"AZURE_DEVOPS_TOKEN", "SYSTEM_ACCESSTOKEN"},
+		user:     "kics",
+	},
+	{urls: []string{"https://bitbucket.org/"}, tokenEnv: []string{"BITBUCKET_TOKEN"}, user: "x-token-auth", userEnv: "BITBUCKET_USERNAME"},
+	{urls: []string{"https://github.com/"}, tokenEnv: []string{"GITHUB_TOKEN"}, user: "x-access-token"},
+	{urls: []string{"https://gitlab.com/"}, tokenEnv: []string{"GITLAB_TOKEN"}, user: "oauth2"},
+}
+
+const (
+	// gitTokenEnv is the token sent to the host of the repository, for hosts without a token of their own
+	gitTokenEnv    = "KICS_GIT_TOKEN"
+	gitUsernameEnv = "KICS_GIT_USERNAME"
+	// gitSSHKeyEnv is the private key used with SSH addresses when no key is given
+	gitSSHKeyEnv = "KICS_GIT_SSH_KEY"
+)
+
+// gitAuthEnv returns the environment of the git commands with the credentials of the repository: the tokens of the hosts
+// are sent as an authorization header of their URLs, set through the environment so they never show in the arguments
+// of the commands, and the SSH key is used by the SSH commands, repositories with credentials in their URL are kept as they are
+func gitAuthEnv(repository string, opts GitOptions) []string {
+	env := opts.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	env = append([]string{}, env...)
+
+	u, err := url.Parse(repository)
+	if err != nil || u.User == nil {
+		env = appendGitConfig(env, gitHeaders(env, u))
+	}
+
+	sshKey := opts.SSHKey
+	if sshKey == "" {
+		sshKey = lookupEnv(env, gitSSHKeyEnv)
+	}
+	if sshKey != "" && lookupEnv(env, "GIT_SSH_COMMAND") == "" {
+		env = append(env, fmt.Sprintf("GIT_SSH_COMMAND=ssh -i %s -o IdentitiesOnly=yes", shellQuote(sshKey)))
+	}
+	return env
+}
+
+// gitHeaders returns the authorization headers of the URLs of the hosts with a token in the environment,
+// the token of KICS is sent to the host of the repository when it has no token of its own
+func gitHeaders(env []string, repository *url.URL) map[string]string {
+	headers := make(map[string]string)
+	for _, host := range gitHostTokens {
+		token := lookupEnv(env, host.tokenEnv...)
+		if token == "" {
+			continue
+		}
+		user := host.user
+		if value := lookupEnv(env, host.userEnv); value != "" {
+			user = value
+		}
+		for _, hostURL := range host.urls {
+			headers[hostURL] = basicAuthorization(user, token)
+		}
+	}
+	if repository == nil || repository.Scheme != "https" || repository.Host == "" {
+		return headers
+	}
+	repositoryURL := fmt.Sprintf("https://%s/", repository.Host)
+	if token := lookupEnv(env, gitTokenEnv); token != "" && !hasHostToken(headers, repository.Host) {
+		user := lookupEnv(env, gitUsernameEnv)
+		if user == "" {
+			user = "kics"
+		}
+		headers[repositoryURL] = basicAuthorization(user, token)
+	}
+	return headers
+}
+
+// hasHostToken returns true if the host matches the URL of a header, such as https://*.visualstudio.com/
+func hasHostToken(headers map[string]string, host string) bool {
+	for hostURL := range headers {
+		pattern := strings.TrimSuffix(strings.TrimPrefix(hostURL, "https://"), "/")
+		if pattern == host || (strings.HasPrefix(pattern, "*.") && strings.HasSuffix(host, pattern[1:])) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendGitConfig sets the authorization headers of the URLs as git configuration in the environment,
+// after the configuration already set in the environment
+func appendGitConfig(env []string, headers map[string]string) []string {
+	if len(headers) == 0 {
+		return env
+	}
+	count, err := strconv.Atoi(lookupEnv(env, "GIT_CONFIG_COUNT"))
+	if err != nil {
+		count = 0
+	}
+	for _, host := range gitHostTokens {
+		for _, hostURL := range host.urls {
+			if header, ok := headers[hostURL]; ok {
+				env = appendGitConfigEntry(env, count, hostURL, header)
+				delete(headers, hostURL)
+				count++
+			}
+		}
+	}
+	// the URL of the repository, for the token of KICS
+	for hostURL, header := range headers {
+		env = appendGitConfigEntry(env, count, hostURL, header)
+		count++
+	}
+	return append(env, fmt.Sprintf("GIT_CONFIG_COUNT=%d", count))
+}
+
+func appendGitConfigEntry(env []string, index int, hostURL, header string) []string {
+	return append(env,
+		fmt.Sprintf("GIT_CONFIG_KEY_%d=http.%s.extraHeader", index, hostURL),
+		fmt.Sprintf("GIT_CONFIG_VALUE_%d=Authorization: %s", index, header))
+}
+
+func basicAuthorization(user, token string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+token))
+}
+
+// lookupEnv returns the value of the first variable of the names set in the environment, the last value of each variable is used
+func lookupEnv(env []string, names ...string) string {
+	for _, name := range names {
+		for i := len(env) - 1; i >= 0; i-- {
+			if value := strings.TrimPrefix(env[i], name+"="); value != env[i] {
+				if value != "" {
+					return value
+				}
+				break
+			}
+		}
+	}
+	return ""
+}
+
+// shellQuote quotes the value for the shell running the SSH command of git
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
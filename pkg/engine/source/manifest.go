@@ -0,0 +1,179 @@
+package source
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// ManifestFileName is the name of the manifest of a queries bundle or of a query pack
+const ManifestFileName = "manifest.json"
+
+// Manifest describes the versions of a queries bundle (queries and rego libraries) or of an external query pack
+// LibraryVersion is the version of the rego libraries shipped with the bundle
+// RequiredEngineVersion is the constraint on the KICS version able to run the queries (ex: ">=1.2.0, <2.0.0")
+// RequiredLibraryVersion is the constraint on the version of the rego libraries the queries use
+type Manifest struct {
+	Name                   string `json:"name,omitempty"`
+	LibraryVersion         string `json:"libraryVersion,omitempty"`
+	RequiredEngineVersion  string `json:"requiredEngineVersion,omitempty"`
+	RequiredLibraryVersion string `json:"requiredLibraryVersion,omitempty"`
+}
+
+// ReadManifest reads the manifest in dir, a nil manifest is returned when dir has no manifest
+func ReadManifest(dir string) (*Manifest, error) {
+	content, err := os.ReadFile(filepath.Clean(filepath.Join(dir, ManifestFileName)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read manifest in %s", dir)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal manifest in %s", dir)
+	}
+	return &manifest, nil
+}
+
+// CheckCompatibility verifies that the queries and the rego libraries can run with the engine version given,
+// reading the manifest of the query pack in the queries path and the manifest of the bundle the libraries belong to
+// all mismatches are reported in the error returned
+func (s *FilesystemSource) CheckCompatibility(engineVersion string) error {
+	// <bundle>/libraries/common/library.rego
+	bundleDir := filepath.Dir(filepath.Dir(filepath.Dir(GetPathToLibrary("common", s.Source))))
+	bundle, err := ReadManifest(bundleDir)
+	if err != nil {
+		return err
+	}
+	pack, err := ReadManifest(s.Source)
+	if err != nil {
+		return err
+	}
+
+	mismatches := make([]string, 0)
+	libraryVersion := ""
+	if bundle != nil {
+		libraryVersion = bundle.LibraryVersion
+		mismatches = append(mismatches, checkEngineVersion(bundle, bundleDir, engineVersion)...)
+	}
+	if pack != nil && filepath.Clean(s.Source) != filepath.Clean(bundleDir) {
+		mismatches = append(mismatches, checkEngineVersion(pack, s.Source, engineVersion)...)
+		if pack.RequiredLibraryVersion != "" {
+			if libraryVersion == "" {
+				log.Warn().Msgf("Query pack %s requires rego libraries %s but the libraries version is unknown",
+					s.Source, pack.RequiredLibraryVersion)
+			} else if ok, err := matchesConstraint(libraryVersion, pack.RequiredLibraryVersion); err != nil || !ok {
+				mismatches = append(mismatches, constraintMismatch(err,
+					fmt.Sprintf("query pack %s requires rego libraries %s, libraries version is %s",
+						s.Source, pack.RequiredLibraryVersion, libraryVersion)))
+			}
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return errors.Errorf("incompatible queries: %s", strings.Join(mismatches, "; "))
+	}
+	return nil
+}
+
+func checkEngineVersion(manifest *Manifest, dir, engineVersion string) []string {
+	if manifest.RequiredEngineVersion == "" {
+		return nil
+	}
+	if _, err := parseVersion(engineVersion); err != nil {
+		log.Debug().Msgf("Skipping engine version check of %s, engine version %s is not a release", dir, engineVersion)
+		return nil
+	}
+	ok, err := matchesConstraint(engineVersion, manifest.RequiredEngineVersion)
+	if err == nil && ok {
+		return nil
+	}
+	return []string{constraintMismatch(err,
+		fmt.Sprintf("%s requires KICS %s, running %s", dir, manifest.RequiredEngineVersion, engineVersion))}
+}
+
+func constraintMismatch(err error, msg string) string {
+	if err != nil {
+		return fmt.Sprintf("%s (%s)", msg, err)
+	}
+	return msg
+}
+
+// matchesConstraint returns true if version satisfies all comma separated conditions of the constraint,
+// each condition is a version optionally prefixed by one of the operators =, !=, >, >=, < and <=
+func matchesConstraint(version, constraint string) (bool, error) {
+	v, err := parseVersion(version)
+	if err != nil {
+		return false, err
+	}
+	for _, condition := range strings.Split(constraint, ",") {
+		condition = strings.TrimSpace(condition)
+		op := strings.TrimRight(condition, "0123456789.v ")
+		target, err := parseVersion(strings.TrimSpace(strings.TrimPrefix(condition, op)))
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid version constraint %q", constraint)
+		}
+		cmp := compareVersions(v, target)
+		var ok bool
+		switch op {
+		case "", "=", "==":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		default:
+			return false, errors.Errorf("invalid operator %q in version constraint %q", op, constraint)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// parseVersion parses a major.minor.patch version, optionally prefixed by v, ignoring pre-release and build suffixes
+func parseVersion(version string) ([3]int, error) {
+	var parsed [3]int
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if idx := strings.IndexAny(version, "-+"); idx >= 0 {
+		version = version[:idx]
+	}
+	parts := strings.Split(version, ".")
+	if len(parts) > len(parsed) {
+		return parsed, errors.Errorf("invalid version %q", version)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return parsed, errors.Errorf("invalid version %q", version)
+		}
+		parsed[i] = n
+	}
+	return parsed, nil
+}
+
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
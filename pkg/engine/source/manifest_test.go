@@ -0,0 +1,83 @@
+package source
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeManifest(t *testing.T, dir, content string) {
+	require.NoError(t, os.MkdirAll(dir, os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ManifestFileName), []byte(content), 0600))
+}
+
+// TestFilesystemSource_CheckCompatibility tests the functions [CheckCompatibility()] and all the methods called by them
+func TestFilesystemSource_CheckCompatibility(t *testing.T) {
+	bundle := t.TempDir()
+	queries := filepath.Join(bundle, "queries")
+	writeManifest(t, bundle, `{"libraryVersion": "1.2.1", "requiredEngineVersion": ">=1.2.0"}`)
+	require.NoError(t, os.MkdirAll(filepath.Join(bundle, "libraries", "common"), os.ModePerm))
+
+	tests := []struct {
+		name          string
+		pack          string
+		engineVersion string
+		wantErr       string
+	}{
+		{name: "compatible", engineVersion: "1.3.0"},
+		{name: "development_engine", engineVersion: "dev"},
+		{name: "engine_too_old", engineVersion: "v1.1.9", wantErr: "requires KICS >=1.2.0, running v1.1.9"},
+		{
+			name:          "pack_compatible",
+			pack:          `{"requiredEngineVersion": ">=1.2.0, <2.0.0", "requiredLibraryVersion": "1.2.1"}`,
+			engineVersion: "1.2.0",
+		},
+		{
+			name:          "pack_incompatible",
+			pack:          `{"requiredEngineVersion": "<1.2.0", "requiredLibraryVersion": ">1.2.1"}`,
+			engineVersion: "1.2.0",
+			wantErr:       "requires rego libraries >1.2.1, libraries version is 1.2.1",
+		},
+		{
+			name:          "invalid_constraint",
+			pack:          `{"requiredLibraryVersion": "~>1.2"}`,
+			engineVersion: "1.2.0",
+			wantErr:       "invalid operator",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, os.RemoveAll(queries))
+			require.NoError(t, os.MkdirAll(queries, os.ModePerm))
+			if tt.pack != "" {
+				writeManifest(t, queries, tt.pack)
+			}
+			err := NewFilesystemSource(queries, []string{""}).CheckCompatibility(tt.engineVersion)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+// TestReadManifest tests the functions [ReadManifest()]
+func TestReadManifest(t *testing.T) {
+	dir := t.TempDir()
+	manifest, err := ReadManifest(dir)
+	require.NoError(t, err)
+	require.Nil(t, manifest)
+
+	writeManifest(t, dir, `{"name": "pack", "requiredEngineVersion": ">=1.2.0"}`)
+	manifest, err = ReadManifest(dir)
+	require.NoError(t, err)
+	require.Equal(t, &Manifest{Name: "pack", RequiredEngineVersion: ">=1.2.0"}, manifest)
+
+	writeManifest(t, dir, `{`)
+	_, err = ReadManifest(dir)
+	require.Error(t, err)
+}
@@ -0,0 +1,266 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	orgPrefix = "org::"
+	// orgPageSize is the number of repositories requested in each page of the APIs of the git hosts
+	orgPageSize = 100
+)
+
+// OrgOptions configures how the repositories of an organization are listed
+// Topics and Languages keep only the repositories with one of the topics and written in one of the languages,
+// case insensitive, APIURL overrides the API of the git host, such as the one of a GitHub Enterprise server,
+// and Env is the environment with the tokens of the git hosts, GITHUB_TOKEN and GITLAB_TOKEN
+type OrgOptions struct {
+	Topics    []string
+	Languages []string
+	APIURL    string
+	Env       []string
+}
+
+// OrgRepository is a repository of an organization, Address is its git address to scan it
+type OrgRepository struct {
+	Name          string   `json:"name"`
+	Address       string   `json:"address"`
+	DefaultBranch string   `json:"default_branch,omitempty"`
+	Language      string   `json:"language,omitempty"`
+	Topics        []string `json:"topics,omitempty"`
+}
+
+// orgHost lists the repositories of an organization or group of a git host
+type orgHost interface {
+	listPage(ctx context.Context, client *http.Client, page int) (repositories []OrgRepository, last bool, err error)
+	languages(ctx context.Context, client *http.Client, repository *OrgRepository) ([]string, error)
+}
+
+// IsOrgSource returns true if the scan path is the address of an organization of GitHub or a group of GitLab,
+// with the org:: prefix (ex: org::https://github.com/my-org)
+func IsOrgSource(scanPath string) bool {
+	return strings.HasPrefix(scanPath, orgPrefix)
+}
+
+// ListOrgRepositories returns the repositories of the organization of the address with one of the topics
+// and languages of the options, archived repositories and forks are never listed, GitHub organizations
+// are listed with GITHUB_TOKEN and GitLab groups, along with their subgroups, with GITLAB_TOKEN
+func ListOrgRepositories(ctx context.Context, client *http.Client, address string, opts OrgOptions) ([]OrgRepository, error) {
+	host, err := newOrgHost(address, opts)
+	if err != nil {
+		return nil, err
+	}
+	repositories := make([]OrgRepository, 0)
+	for page := 1; ; page++ {
+		listed, last, err := host.listPage(ctx, client, page)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list the repositories of %s", address)
+		}
+		for i := range listed {
+			if !hasAny(listed[i].Topics, opts.Topics) {
+				continue
+			}
+			if len(opts.Languages) > 0 {
+				languages, err := host.languages(ctx, client, &listed[i])
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to get the languages of %s", listed[i].Name)
+				}
+				if !hasAny(languages, opts.Languages) {
+					continue
+				}
+			}
+			repositories = append(repositories, listed[i])
+		}
+		if last {
+			return repositories, nil
+		}
+	}
+}
+
+// newOrgHost returns the git host of the organization address, GitHub or GitLab, the API is the one of the public
+// host unless given by the options, self-hosted servers need the API URL
+func newOrgHost(address string, opts OrgOptions) (orgHost, error) {
+	u, err := url.Parse(strings.TrimPrefix(address, orgPrefix))
+	if err != nil || u.Host == "" {
+		return nil, errors.Errorf("invalid organization address %s", address)
+	}
+	org := strings.Trim(u.Path, "/")
+	if org == "" {
+		return nil, errors.Errorf("organization missing in address %s", address)
+	}
+	env := opts.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	apiURL := strings.TrimSuffix(opts.APIURL, "/")
+	switch {
+	case strings.Contains(u.Host, "github"):
+		if apiURL == "" {
+			apiURL = "https://api.github.com"
+		}
+		return &githubOrg{apiURL: apiURL, org: org, token: lookupEnv(env, "GITHUB_TOKEN")}, nil
+	case strings.Contains(u.Host, "gitlab"):
+		if apiURL == "" {
+			apiURL = fmt.Sprintf("%s://%s/api/v4", u.Scheme, u.Host)
+		}
+		return &gitlabGroup{apiURL: apiURL, group: org, token: lookupEnv(env, "GITLAB_TOKEN")}, nil
+	default:
+		return nil, errors.Errorf("git host of %s not supported, supported hosts: GitHub, GitLab", address)
+	}
+}
+
+type githubOrg struct {
+	apiURL string
+	org    string
+	token  string
+}
+
+type githubRepository struct {
+	FullName      string   `json:"full_name"`
+	CloneURL      string   `json:"clone_url"`
+	DefaultBranch string   `json:"default_branch"`
+	Language      string   `json:"language"`
+	Topics        []string `json:"topics"`
+	Archived      bool     `json:"archived"`
+	Fork          bool     `json:"fork"`
+}
+
+func (g *githubOrg) listPage(ctx context.Context, client *http.Client, page int) ([]OrgRepository, bool, error) {
+	var listed []githubRepository
+	requestURL := fmt.Sprintf("%s/orgs/%s/repos?type=all&per_page=%d&page=%d", g.apiURL, url.PathEscape(g.org), orgPageSize, page)
+	if err := getJSON(ctx, client, requestURL, g.header(), &listed); err != nil {
+		return nil, false, err
+	}
+	repositories := make([]OrgRepository, 0, len(listed))
+	for _, repository := range listed {
+		if repository.Archived || repository.Fork {
+			continue
+		}
+		repositories = append(repositories, OrgRepository{
+			Name:          repository.FullName,
+			Address:       repository.CloneURL,
+			DefaultBranch: repository.DefaultBranch,
+			Language:      repository.Language,
+			Topics:        repository.Topics,
+		})
+	}
+	return repositories, len(listed) < orgPageSize, nil
+}
+
+// languages returns the main language of the repository, the one shown by GitHub
+func (g *githubOrg) languages(_ context.Context, _ *http.Client, repository *OrgRepository) ([]string, error) {
+	return []string{repository.Language}, nil
+}
+
+func (g *githubOrg) header() http.Header {
+	header := http.Header{"Accept": []string{"application/vnd.github+json"}}
+	if g.token != "" {
+		header.Set("Authorization", "Bearer "+g.token)
+	}
+	return header
+}
+
+type gitlabGroup struct {
+	apiURL string
+	group  string
+	token  string
+}
+
+type gitlabProject struct {
+	PathWithNamespace string   `json:"path_with_namespace"`
+	HTTPURLToRepo     string   `json:"http_url_to_repo"`
+	DefaultBranch     string   `json:"default_branch"`
+	Topics            []string `json:"topics"`
+	Archived          bool     `json:"archived"`
+	ForkedFrom        *struct {
+		ID int `json:"id"`
+	} `json:"forked_from_project"`
+}
+
+func (g *gitlabGroup) listPage(ctx context.Context, client *http.Client, page int) ([]OrgRepository, bool, error) {
+	var listed []gitlabProject
+	requestURL := fmt.Sprintf("%s/groups/%s/projects?include_subgroups=true&archived=false&per_page=%d&page=%d",
+		g.apiURL, url.PathEscape(g.group), orgPageSize, page)
+	if err := getJSON(ctx, client, requestURL, g.header(), &listed); err != nil {
+		return nil, false, err
+	}
+	repositories := make([]OrgRepository, 0, len(listed))
+	for _, project := range listed {
+		if project.Archived || project.ForkedFrom != nil {
+			continue
+		}
+		repositories = append(repositories, OrgRepository{
+			Name:          project.PathWithNamespace,
+			Address:       project.HTTPURLToRepo,
+			DefaultBranch: project.DefaultBranch,
+			Topics:        project.Topics,
+		})
+	}
+	return repositories, len(listed) < orgPageSize, nil
+}
+
+// languages returns the languages of the project, GitLab does not list them with the projects of the group,
+// the main language is kept in the repository
+func (g *gitlabGroup) languages(ctx context.Context, client *http.Client, repository *OrgRepository) ([]string, error) {
+	var percentages map[string]float64
+	requestURL := fmt.Sprintf("%s/projects/%s/languages", g.apiURL, url.PathEscape(repository.Name))
+	if err := getJSON(ctx, client, requestURL, g.header(), &percentages); err != nil {
+		return nil, err
+	}
+	languages := make([]string, 0, len(percentages))
+	for language, percentage := range percentages {
+		languages = append(languages, language)
+		if repository.Language == "" || percentage > percentages[repository.Language] {
+			repository.Language = language
+		}
+	}
+	return languages, nil
+}
+
+func (g *gitlabGroup) header() http.Header {
+	header := http.Header{}
+	if g.token != "" {
+		header.Set("Authorization", "Bearer "+g.token)
+	}
+	return header
+}
+
+func getJSON(ctx context.Context, client *http.Client, requestURL string, header http.Header, body interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, http.NoBody)
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("%s returned %s", redactURL(requestURL), resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(body)
+}
+
+// hasAny returns true if the values have one of the wanted values, case insensitive, or no values are wanted
+func hasAny(values, wanted []string) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	for _, value := range values {
+		for _, w := range wanted {
+			if strings.EqualFold(value, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestListOrgRepositories tests the functions [ListOrgRepositories()] and all the methods called by them
+func TestListOrgRepositories(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/orgs/my-org/repos":
+			fmt.Fprint(w, `[
+				{"full_name": "my-org/infra", "clone_url": "https://github.com/my-org/infra.git", "default_branch": "main",
+				 "language": "HCL", "topics": ["terraform", "aws"]},
+				{"full_name": "my-org/charts", "clone_url": "https://github.com/my-org/charts.git", "language": "Smarty",
+				 "topics": ["helm"]},
+				{"full_name": "my-org/old", "clone_url": "https://github.com/my-org/old.git", "language": "HCL", "archived": true},
+				{"full_name": "my-org/fork", "clone_url": "https://github.com/my-org/fork.git", "language": "HCL", "fork": true}
+			]`)
+		case "/groups/my-group/projects":
+			fmt.Fprint(w, `[
+				{"path_with_namespace": "my-group/infra", "http_url_to_repo": "https://gitlab.com/my-group/infra.git",
+				 "topics": ["terraform"]},
+				{"path_with_namespace": "my-group/app", "http_url_to_repo": "https://gitlab.com/my-group/app.git",
+				 "topics": ["terraform"], "forked_from_project": {"id": 1}}
+			]`)
+		case "/projects/my-group%2Finfra/languages", "/projects/my-group/infra/languages":
+			fmt.Fprint(w, `{"HCL": 80.5, "Shell": 19.5}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	repositories, err := ListOrgRepositories(context.Background(), server.Client(), "org::https://github.com/my-org",
+		OrgOptions{APIURL: server.URL, Env: []string{"GITHUB_TOKEN=secret"}})
+	require.NoError(t, err)
+	require.Equal(t, []OrgRepository{
+		{
+			Name:          "my-org/infra",
+			Address:       "https://github.com/my-org/infra.git",
+			DefaultBranch: "main",
+			Language:      "HCL",
+			Topics:        []string{"terraform", "aws"},
+		},
+		{Name: "my-org/charts", Address: "https://github.com/my-org/charts.git", Language: "Smarty", Topics: []string{"helm"}},
+	}, repositories)
+	require.Equal(t, "Bearer secret", authorization)
+
+	repositories, err = ListOrgRepositories(context.Background(), server.Client(), "org::https://github.com/my-org",
+		OrgOptions{APIURL: server.URL, Topics: []string{"Terraform"}, Languages: []string{"hcl"}, Env: []string{}})
+	require.NoError(t, err)
+	require.Len(t, repositories, 1)
+	require.Equal(t, "my-org/infra", repositories[0].Name)
+	require.Empty(t, authorization)
+
+	repositories, err = ListOrgRepositories(context.Background(), server.Client(), "org::https://gitlab.com/my-group",
+		OrgOptions{APIURL: server.URL, Languages: []string{"shell"}, Env: []string{}})
+	require.NoError(t, err)
+	require.Equal(t, []OrgRepository{{
+		Name:     "my-group/infra",
+		Address:  "https://gitlab.com/my-group/infra.git",
+		Language: "HCL",
+		Topics:   []string{"terraform"},
+	}}, repositories)
+
+	_, err = ListOrgRepositories(context.Background(), server.Client(), "org::https://github.com/missing",
+		OrgOptions{APIURL: server.URL, Env: []string{}})
+	require.Error(t, err)
+
+	_, err = ListOrgRepositories(context.Background(), server.Client(), "org::https://bitbucket.org/my-org", OrgOptions{})
+	require.EqualError(t, err, "git host of org::https://bitbucket.org/my-org not supported, supported hosts: GitHub, GitLab")
+
+	require.True(t, IsOrgSource("org::https://github.com/my-org"))
+	require.False(t, IsOrgSource("https://github.com/my-org/infra.git"))
+}
@@ -0,0 +1,96 @@
+package source
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// QueryOverride patches the metadata of a query, empty fields keep the metadata of the query
+// and Enabled set to false excludes the query
+type QueryOverride struct {
+	Severity    string `yaml:"severity"`
+	Description string `yaml:"description"`
+	Category    string `yaml:"category"`
+	Enabled     *bool  `yaml:"enabled"`
+}
+
+// Overrides maps query IDs to the overrides of their metadata
+type Overrides map[string]QueryOverride
+
+type overridesFile struct {
+	Queries Overrides `yaml:"queries"`
+}
+
+// LoadOverrides reads the overrides of the queries metadata of a YAML file, an empty path returns no overrides
+func LoadOverrides(overridesPath string) (Overrides, error) {
+	if overridesPath == "" {
+		return nil, nil
+	}
+	f, err := os.Open(filepath.Clean(overridesPath))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open query overrides")
+	}
+	defer f.Close()
+
+	var file overridesFile
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && err != io.EOF {
+		return nil, errors.Wrapf(err, "failed to parse query overrides %s", overridesPath)
+	}
+	for id, override := range file.Queries {
+		if override.Severity == "" {
+			continue
+		}
+		override.Severity = strings.ToUpper(override.Severity)
+		if !isSeverity(override.Severity) {
+			return nil, fmt.Errorf("invalid severity %s of query %s in query overrides, supported values: high, medium, low, info",
+				override.Severity, id)
+		}
+		file.Queries[id] = override
+	}
+	return file.Queries, nil
+}
+
+// apply patches the metadata of the query and returns false if the query is disabled
+func (o Overrides) apply(query *model.QueryMetadata) bool {
+	id, ok := query.Metadata["id"].(string)
+	if !ok {
+		return true
+	}
+	override, ok := o[id]
+	if !ok {
+		return true
+	}
+	if override.Enabled != nil && !*override.Enabled {
+		log.Debug().Msgf("Query %s disabled by query overrides", id)
+		return false
+	}
+	if override.Severity != "" {
+		query.Metadata["severity"] = override.Severity
+	}
+	if override.Description != "" {
+		query.Metadata["descriptionText"] = override.Description
+	}
+	if override.Category != "" {
+		query.Metadata["category"] = override.Category
+	}
+	return true
+}
+
+func isSeverity(severity string) bool {
+	for _, s := range model.AllSeverities {
+		if string(s) == severity {
+			return true
+		}
+	}
+	return false
+}
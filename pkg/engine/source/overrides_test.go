@@ -0,0 +1,80 @@
+package source
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeQuery(t *testing.T, dir, metadata string) {
+	require.NoError(t, os.MkdirAll(dir, os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, QueryFileName), []byte("package Cx"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, MetadataFileName), []byte(metadata), 0600))
+}
+
+// TestLoadOverrides tests the functions [LoadOverrides()] and all the methods called by them
+func TestLoadOverrides(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		want    Overrides
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			content: "queries:\n  a227ec01-f97a-4084-91a4-47b350c1db54:\n    severity: low\n    category: Compliance\n",
+			want:    Overrides{"a227ec01-f97a-4084-91a4-47b350c1db54": {Severity: "LOW", Category: "Compliance"}},
+		},
+		{name: "empty", content: "", want: nil},
+		{name: "invalid_severity", content: "queries:\n  a227ec01:\n    severity: urgent\n", wantErr: true},
+		{name: "unknown_field", content: "queries:\n  a227ec01:\n    severty: low\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overridesPath := filepath.Join(dir, tt.name+".yaml")
+			require.NoError(t, os.WriteFile(overridesPath, []byte(tt.content), 0600))
+			got, err := LoadOverrides(overridesPath)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	overrides, err := LoadOverrides("")
+	require.NoError(t, err)
+	require.Nil(t, overrides)
+	_, err = LoadOverrides(filepath.Join(dir, "missing.yaml"))
+	require.Error(t, err)
+}
+
+// TestFilesystemSource_GetQueries_Overrides tests the functions [GetQueries()] applying the query overrides
+func TestFilesystemSource_GetQueries_Overrides(t *testing.T) {
+	queries := filepath.Join(t.TempDir(), "queries")
+	writeQuery(t, filepath.Join(queries, "terraform", "aws", "s3_bucket_logging_disabled"),
+		`{"id": "f861041c-8c9f-4156-acfc-5e6e524f5884", "severity": "MEDIUM", "category": "Observability", "descriptionText": "S3 Bucket should have logging enabled", "platform": "Terraform"}`)
+	writeQuery(t, filepath.Join(queries, "terraform", "aws", "s3_bucket_without_versioning"),
+		`{"id": "568a4d22-3517-44a6-a7ad-6a7eed88722c", "severity": "MEDIUM", "category": "Backup", "platform": "Terraform"}`)
+	disabled := false
+
+	querySource := NewFilesystemSource(queries, []string{""})
+	querySource.Overrides = Overrides{
+		"f861041c-8c9f-4156-acfc-5e6e524f5884": {Severity: "HIGH", Description: "Logging is required by policy SEC-12", Category: "Compliance"},
+		"568a4d22-3517-44a6-a7ad-6a7eed88722c": {Enabled: &disabled},
+	}
+	got, err := querySource.GetQueries(ExcludeQueries{ByCategories: []string{"Compliance"}})
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	got, err = querySource.GetQueries(ExcludeQueries{})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.Equal(t, "HIGH", got[0].Metadata["severity"])
+	require.Equal(t, "Logging is required by policy SEC-12", got[0].Metadata["descriptionText"])
+	require.Equal(t, "Compliance", got[0].Metadata["category"])
+}
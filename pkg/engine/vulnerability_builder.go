@@ -102,8 +102,18 @@ var DefaultVulnerabilityBuilder = func(ctx *QueryContext, tracker Tracker, v int
 			linesVulne = detectDockerLine(&file, searchKey, &logWithFields, tracker.GetOutputLines())
 		case model.KindHELM:
 			// Update search key to make use of the auxiliary lines
-			tempSearchKey := fmt.Sprintf("%s.%s", strings.TrimRight(strings.TrimLeft(file.HelmID, "# "), ":"), searchKey)
+			tempSearchKey := fmt.Sprintf("%s.%s", strings.TrimRight(strings.TrimLeft(file.Origin.GetSplitID(), "# "), ":"), searchKey)
 			linesVulne = detectHelmLine(&file, tempSearchKey, &logWithFields, tracker.GetOutputLines())
+		case model.KindKustomize:
+			linesVulne = detectKustomizeLine(&file, searchKey, &logWithFields, tracker.GetOutputLines())
+		case model.KindBICEP:
+			linesVulne = detectBicepLine(&file, searchKey, &logWithFields, tracker.GetOutputLines())
+		case model.KindTerraform, model.KindTerragrunt:
+			linesVulne = detectTerraformLine(&file, searchKey, &logWithFields, tracker.GetOutputLines())
+			// the resources of the modules resolved are identified by the address of their module
+			if address := getModuleAddress(&file); address != "" {
+				searchKey = fmt.Sprintf("%s.%s", address, searchKey)
+			}
 		default:
 			linesVulne = detectLine(&file, searchKey, &logWithFields, tracker.GetOutputLines())
 		}
@@ -153,30 +163,116 @@ var DefaultVulnerabilityBuilder = func(ctx *QueryContext, tracker Tracker, v int
 	}
 
 	return model.Vulnerability{
-		ID:               0,
-		SimilarityID:     ptrStringToString(similarityID),
-		ScanID:           ctx.scanID,
-		FileID:           file.ID,
-		FileName:         file.FileName,
-		QueryName:        getStringFromMap("queryName", DefaultQueryName, vObj, &logWithFields),
-		QueryID:          queryID,
-		QueryURI:         getStringFromMap("descriptionUrl", DefaultQueryURI, vObj, &logWithFields),
-		Category:         getStringFromMap("category", "", vObj, &logWithFields),
-		Description:      getStringFromMap("descriptionText", "", vObj, &logWithFields),
-		Severity:         severity,
-		Platform:         getStringFromMap("platform", "", vObj, &logWithFields),
-		Line:             linesVulne.line,
-		VulnLines:        linesVulne.vulnLine,
-		IssueType:        issueType,
-		SearchKey:        searchKey,
-		SearchValue:      searchValue,
-		KeyExpectedValue: ptrStringToString(mustMapKeyToString(vObj, "keyExpectedValue")),
-		KeyActualValue:   ptrStringToString(mustMapKeyToString(vObj, "keyActualValue")),
-		Value:            mustMapKeyToString(vObj, "value"),
-		Output:           string(output),
+		ID:                 0,
+		SimilarityID:       ptrStringToString(similarityID),
+		ScanID:             ctx.scanID,
+		FileID:             file.ID,
+		FileName:           file.FileName,
+		QueryName:          getStringFromMap("queryName", DefaultQueryName, vObj, &logWithFields),
+		QueryID:            queryID,
+		QueryURI:           getStringFromMap("descriptionUrl", DefaultQueryURI, vObj, &logWithFields),
+		Category:           getStringFromMap("category", "", vObj, &logWithFields),
+		Description:        getStringFromMap("descriptionText", "", vObj, &logWithFields),
+		Severity:           severity,
+		Platform:           getStringFromMap("platform", "", vObj, &logWithFields),
+		Line:               linesVulne.line,
+		VulnLines:          linesVulne.vulnLine,
+		OriginChain:        file.Origin.GetChain(),
+		IssueType:          issueType,
+		SearchKey:          searchKey,
+		SearchValue:        searchValue,
+		KeyExpectedValue:   ptrStringToString(mustMapKeyToString(vObj, "keyExpectedValue")),
+		KeyActualValue:     ptrStringToString(mustMapKeyToString(vObj, "keyActualValue")),
+		Value:              mustMapKeyToString(vObj, "value"),
+		ModuleCallChain:    getModuleCallChain(&file),
+		KubernetesResource: getKubernetesResource(&file),
+		Output:             string(output),
 	}, nil
 }
 
+// getModuleCallChain returns the Terraform module call chain added by the parser to the file document, if any,
+// followed by the modules of the address of the module resolved the document comes from
+func getModuleCallChain(file *model.FileMetadata) []string {
+	var callChain []string
+	switch chain := file.Document[model.ModuleCallChainKey].(type) {
+	case []string:
+		callChain = append(callChain, chain...)
+	case []interface{}:
+		callChain = make([]string, 0, len(chain))
+		for _, module := range chain {
+			if name, ok := module.(string); ok {
+				callChain = append(callChain, name)
+			}
+		}
+	}
+	address := getModuleAddress(file)
+	if address == "" {
+		return callChain
+	}
+	if len(callChain) == 0 {
+		callChain = append(callChain, "root")
+	}
+	for _, name := range strings.Split(strings.TrimPrefix(address, model.ModuleAddressPrefix), "."+model.ModuleAddressPrefix) {
+		callChain = append(callChain, model.ModuleAddressPrefix+name)
+	}
+	return callChain
+}
+
+// getModuleAddress returns the address of the Terraform module resolved the document comes from (ex: module.vpc),
+// empty for the documents of the files scanned
+func getModuleAddress(file *model.FileMetadata) string {
+	if address := file.Origin.GetSplitID(); strings.HasPrefix(address, model.ModuleAddressPrefix) {
+		return address
+	}
+	return ""
+}
+
+// getModuleCallSearchKey returns the search key of the module call the document of a Terraform module resolved is
+// attributed to (ex: module[vpc]), so lines are detected in the file calling the module, or the search key otherwise
+func getModuleCallSearchKey(file *model.FileMetadata, searchKey string) string {
+	address := getModuleAddress(file)
+	if address == "" {
+		return searchKey
+	}
+	name := strings.SplitN(strings.TrimPrefix(address, model.ModuleAddressPrefix), ".", 2)[0]
+	return fmt.Sprintf("module[%s]", name)
+}
+
+// terragruntSearchKeys are the blocks of the Terragrunt configurations the results of the modules they deploy are reported
+// on: the terraform block setting the source of the module or, when it is set by a configuration included, the include
+var terragruntSearchKeys = []string{"terraform", "include"}
+
+// detectTerraformLine detects the line of the result of a Terraform file, the results of the modules resolved
+// are detected on the module calls of the file, and the results of the modules deployed by a Terragrunt configuration
+// on the configuration, since their resources are not in the configuration
+func detectTerraformLine(file *model.FileMetadata, searchKey string, logWithFields *zerolog.Logger,
+	outputLines int) vulnerabilityLines {
+	if file.Kind != model.KindTerragrunt {
+		return detectLine(file, getModuleCallSearchKey(file, searchKey), logWithFields, outputLines)
+	}
+	nopLogger := zerolog.Nop()
+	for _, key := range terragruntSearchKeys {
+		if lines := detectLine(file, key, &nopLogger, outputLines); lines.line != UndetectedVulnerabilityLine {
+			return lines
+		}
+	}
+	logWithFields.Warn().Msgf("Failed to detect line, query response %s", searchKey)
+	return vulnerabilityLines{
+		line:     UndetectedVulnerabilityLine,
+		vulnLine: model.VulnLines{},
+	}
+}
+
+// getKubernetesResource returns the Kubernetes resource declared by the file document, if any
+func getKubernetesResource(file *model.FileMetadata) *model.KubernetesResource {
+	switch file.Kind {
+	case model.KindYAML, model.KindJSON, model.KindHELM, model.KindKustomize:
+		return model.NewKubernetesResource(file.Document)
+	default:
+		return nil
+	}
+}
+
 func mergeWithMetadata(base, additional map[string]interface{}) map[string]interface{} {
 	for k, v := range additional {
 		if _, ok := base[k]; ok {
@@ -209,7 +305,7 @@ func detectHelmLine(file *model.FileMetadata, searchKey string, logWithFields *z
 		sanitizedSubstring = strings.Replace(sanitizedSubstring, str[0], `{{`+strconv.Itoa(idx)+`}}`, -1)
 	}
 
-	helmID, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(file.HelmID, "# KICS_HELM_ID_"), ":"))
+	helmID, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(file.Origin.GetSplitID(), "# KICS_HELM_ID_"), ":"))
 	if err != nil {
 		helmID = -1
 	}
@@ -217,7 +313,7 @@ func detectHelmLine(file *model.FileMetadata, searchKey string, logWithFields *z
 	// Since we are only looking at keys we can ignore the second value passed through '=' and '[]'
 	for _, key := range strings.Split(sanitizedSubstring, ".") {
 		substr1, _ := generateSubstrings(key, extractedString)
-		curLineRes = curLineRes.detectCurrentLine(lines, fmt.Sprintf("%s:", substr1), "", true, file.IDInfo, helmID)
+		curLineRes = curLineRes.detectCurrentLine(lines, fmt.Sprintf("%s:", substr1), "", true, file.Origin.GetLines(), helmID)
 
 		if curLineRes.breakRes {
 			break
@@ -319,11 +415,19 @@ func detectDockerLine(file *model.FileMetadata, searchKey string, logWithFields
 }
 
 func detectLine(file *model.FileMetadata, searchKey string, logWithFields *zerolog.Logger, outputLines int) vulnerabilityLines {
+	return detectDocumentLine(file, searchKey, logWithFields, outputLines, false)
+}
+
+// detectDocumentLine detects the line of the search key inside the document of the file, when keyFallback is set
+// the keys whose value is not found are detected by key only
+func detectDocumentLine(file *model.FileMetadata, searchKey string, logWithFields *zerolog.Logger,
+	outputLines int, keyFallback bool) vulnerabilityLines {
 	text := strings.ReplaceAll(file.OriginalData, "\r", "")
 	lines := strings.Split(text, "\n")
+	start, end := getDocumentLines(file, len(lines))
 	curLineRes := detectCurlLine{
 		foundRes: false,
-		lineRes:  0,
+		lineRes:  start,
 		breakRes: false,
 	}
 	var extractedString [][]string
@@ -336,7 +440,11 @@ func detectLine(file *model.FileMetadata, searchKey string, logWithFields *zerol
 	for _, key := range strings.Split(sanitizedSubstring, ".") {
 		substr1, substr2 := generateSubstrings(key, extractedString)
 
-		curLineRes = curLineRes.detectCurrentLine(lines, substr1, substr2, false, nil, -1)
+		next := curLineRes.detectCurrentLine(lines[:end], substr1, substr2, false, nil, -1)
+		if next.breakRes && keyFallback && substr2 != "" {
+			next = curLineRes.detectCurrentLine(lines[:end], fmt.Sprintf("%s:", substr1), "", true, nil, -1)
+		}
+		curLineRes = next
 
 		if curLineRes.breakRes {
 			break
@@ -359,6 +467,149 @@ func detectLine(file *model.FileMetadata, searchKey string, logWithFields *zerol
 	}
 }
 
+// detectKustomizeLine detects the line of a resource rendered by kustomize in the document of the file declaring it,
+// the values changed by the kustomization (ex: the prefixes of the names) are not in the file, so the keys whose value
+// is not found are detected by key only, as the keys of the Helm templates
+func detectKustomizeLine(file *model.FileMetadata, searchKey string, logWithFields *zerolog.Logger,
+	outputLines int) vulnerabilityLines {
+	return detectDocumentLine(file, searchKey, logWithFields, outputLines, true)
+}
+
+// detectBicepLine detects the line of a result of a Bicep file, the search keys of the queries of ARM templates
+// are paths of the template the file is converted to, so the line of the deepest element of the search key whose line
+// was recorded by the parser is reported, the lines are detected in the file as for the other kinds otherwise
+func detectBicepLine(file *model.FileMetadata, searchKey string, logWithFields *zerolog.Logger,
+	outputLines int) vulnerabilityLines {
+	lines := strings.Split(strings.ReplaceAll(file.OriginalData, "\r", ""), "\n")
+	if line := getSourceLine(file.Document, searchKey); line > 0 && line <= len(lines) {
+		return vulnerabilityLines{
+			line:                 line,
+			vulnLine:             getAdjacentLines(line-1, outputLines, lines),
+			lineWithVulnerabilty: lines[line-1],
+		}
+	}
+	return detectLine(file, searchKey, logWithFields, outputLines)
+}
+
+// getSourceLine follows the search key in the document and returns the line in the source file of the deepest element
+// found, the elements of arrays are selected by index or by the value of one of their keys (ex: name={{storage}}),
+// zero when the document has no source lines or none of the elements is found
+func getSourceLine(document model.Document, searchKey string) int {
+	sourceLines := getSourceLines(document)
+	if len(sourceLines) == 0 {
+		return 0
+	}
+	var current interface{} = map[string]interface{}(document)
+	path, line := "", 0
+	for _, key := range splitSearchKey(searchKey) {
+		next, segment, ok := getSearchKeyElement(current, key)
+		if !ok {
+			break
+		}
+		current = next
+		if path != "" {
+			path += "."
+		}
+		path += segment
+		if l, ok := sourceLines[path]; ok {
+			line = l
+		}
+	}
+	return line
+}
+
+// getSourceLines returns the lines of the paths of the document added by the parser, also once the document
+// was serialized
+func getSourceLines(document model.Document) map[string]int {
+	switch lines := document[model.SourceLinesKey].(type) {
+	case map[string]int:
+		return lines
+	case map[string]interface{}:
+		sourceLines := make(map[string]int, len(lines))
+		for path, line := range lines {
+			if n, ok := line.(float64); ok {
+				sourceLines[path] = int(n)
+			}
+		}
+		return sourceLines
+	}
+	return nil
+}
+
+// splitSearchKey splits a search key in its keys, the dots between double braces (ex: name={{a.b}}) are part of the key
+func splitSearchKey(searchKey string) []string {
+	keys := make([]string, 0)
+	depth, start := 0, 0
+	for i := 0; i < len(searchKey); i++ {
+		switch {
+		case strings.HasPrefix(searchKey[i:], "{{"):
+			depth++
+			i++
+		case strings.HasPrefix(searchKey[i:], "}}") && depth > 0:
+			depth--
+			i++
+		case searchKey[i] == '.' && depth == 0:
+			keys = append(keys, searchKey[start:i])
+			start = i + 1
+		}
+	}
+	return append(keys, searchKey[start:])
+}
+
+// getSearchKeyElement returns the element of the key of a search key inside an object or an array,
+// with the segment of its path in the document
+func getSearchKeyElement(current interface{}, key string) (element interface{}, segment string, found bool) {
+	switch value := current.(type) {
+	case map[string]interface{}:
+		if element, ok := value[key]; ok {
+			return element, key, true
+		}
+		// the keys of ARM templates are case insensitive
+		for name, element := range value {
+			if strings.EqualFold(name, key) {
+				return element, name, true
+			}
+		}
+	case []interface{}:
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(value) {
+			return value[i], key, true
+		}
+		parts := strings.SplitN(key, "=", 2)
+		if len(parts) != 2 {
+			return nil, "", false
+		}
+		expected := strings.TrimSuffix(strings.TrimPrefix(parts[1], "{{"), "}}")
+		for i, item := range value {
+			if object, ok := item.(map[string]interface{}); ok && fmt.Sprint(object[parts[0]]) == expected {
+				return item, strconv.Itoa(i), true
+			}
+		}
+	}
+	return nil, "", false
+}
+
+// getDocumentLines returns the range of lines [start, end) of the file document, set by the resolver or added
+// by the parser, or all lines when the file has a single document
+func getDocumentLines(file *model.FileMetadata, total int) (start, end int) {
+	bounds := file.Origin.GetDocumentLines()
+	if bounds == nil {
+		switch lines := file.Document[model.DocumentLinesKey].(type) {
+		case []int:
+			bounds = lines
+		case []interface{}:
+			for _, line := range lines {
+				if n, ok := line.(float64); ok {
+					bounds = append(bounds, int(n))
+				}
+			}
+		}
+	}
+	if len(bounds) != 2 || bounds[0] < 0 || bounds[0] >= bounds[1] || bounds[1] > total {
+		return 0, total
+	}
+	return bounds[0], bounds[1]
+}
+
 // getAdjacent is used to get the lines adjecent to the line that contains the vulnerability
 // adj is the amount of lines wanted
 func getAdjacentLines(idx, adj int, lines []string) model.VulnLines {
@@ -352,6 +352,36 @@ func Test_detectLine(t *testing.T) { //nolint
 				lineWithVulnerabilty: "\t\t\t\t\t\t  Environment = \"Dev.123\"",
 			},
 		},
+		{
+			name: "detect_line_document_lines",
+			args: args{
+				ctx: &QueryContext{
+					scanID: "scanID",
+				},
+				file: &model.FileMetadata{
+					ScanID: "scanID",
+					ID:     "Test",
+					Kind:   model.KindYAML,
+					Document: model.Document{
+						model.DocumentLinesKey: []int{4, 8},
+					},
+					OriginalData: "kind: Pod\nspec:\n  hostNetwork: false\n---\nkind: Pod\nspec:\n  hostNetwork: true\n",
+				},
+				searchKey: "spec.hostNetwork",
+			},
+			want: vulnerabilityLines{
+				line: 7,
+				vulnLine: model.VulnLines{
+					Positions: []int{6, 7, 8},
+					Lines: []string{
+						"spec:",
+						"  hostNetwork: true",
+						"",
+					},
+				},
+				lineWithVulnerabilty: "  hostNetwork: true",
+			},
+		},
 		{
 			name: "detect_line_error",
 			args: args{
@@ -466,6 +496,116 @@ func Test_ptrStringToString(t *testing.T) {
 	}
 }
 
+// Test_getModuleCallChain tests the functions [getModuleCallChain()] and all the methods called by them
+func Test_getModuleCallChain(t *testing.T) {
+	tests := []struct {
+		name     string
+		document model.Document
+		origin   *model.Origin
+		want     []string
+	}{
+		{
+			name:     "without_call_chain",
+			document: model.Document{},
+			want:     nil,
+		},
+		{
+			name:     "string_slice",
+			document: model.Document{model.ModuleCallChainKey: []string{"root", "module.vpc"}},
+			want:     []string{"root", "module.vpc"},
+		},
+		{
+			name:     "interface_slice",
+			document: model.Document{model.ModuleCallChainKey: []interface{}{"root", "module.vpc", "module.subnets"}},
+			want:     []string{"root", "module.vpc", "module.subnets"},
+		},
+		{
+			name:     "module_resolved",
+			document: model.Document{},
+			origin:   &model.Origin{SplitID: "module.vpc.module.subnets"},
+			want:     []string{"root", "module.vpc", "module.subnets"},
+		},
+		{
+			name:     "module_resolved_in_installed_module",
+			document: model.Document{model.ModuleCallChainKey: []interface{}{"root", "module.network"}},
+			origin:   &model.Origin{SplitID: "module.vpc"},
+			want:     []string{"root", "module.network", "module.vpc"},
+		},
+		{
+			name:     "helm_split",
+			document: model.Document{},
+			origin:   &model.Origin{SplitID: "# KICS_HELM_ID_0:"},
+			want:     nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getModuleCallChain(&model.FileMetadata{Document: tt.document, Origin: tt.origin})
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// Test_detectLine_module tests the functions [getModuleCallSearchKey()] and [detectLine()] for the documents of
+// Terraform modules resolved, whose lines are detected on the module call of the file calling them
+func Test_detectLine_module(t *testing.T) {
+	file := &model.FileMetadata{
+		Kind:         model.KindTerraform,
+		FileName:     "main.tf",
+		Document:     model.Document{},
+		OriginalData: "module \"network\" {\n  source = \"../network\"\n}\n\nmodule \"vpc\" {\n  source = \"../vpc\"\n}\n",
+		Origin:       &model.Origin{SplitID: "module.vpc.module.subnets"},
+	}
+	searchKey := getModuleCallSearchKey(file, "aws_subnet[public].map_public_ip_on_launch")
+	require.Equal(t, "module[vpc]", searchKey)
+	require.Equal(t, 5, detectLine(file, searchKey, &zerolog.Logger{}, 1).line)
+
+	file.Origin = nil
+	require.Equal(t, "aws_subnet[public]", getModuleCallSearchKey(file, "aws_subnet[public]"))
+}
+
+// Test_detectKustomizeLine tests the functions [detectKustomizeLine()] and all the methods called by them
+func Test_detectKustomizeLine(t *testing.T) {
+	file := &model.FileMetadata{
+		Kind:     model.KindKustomize,
+		FileName: "service.yaml",
+		Document: model.Document{},
+		OriginalData: "apiVersion: v1\nkind: Service\nmetadata:\n  name: app\nspec:\n  type: ClusterIP\n---\n" +
+			"apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: app\n",
+		Origin: &model.Origin{DocumentLines: []int{7, 12}},
+	}
+	// the name prefixed by the kustomization is not in the file, the key is detected in the document of the resource
+	require.Equal(t, 11, detectKustomizeLine(file, "metadata.name={{dev-app}}", &zerolog.Logger{}, 1).line)
+	require.Equal(t, 10, detectLine(file, "metadata.name={{dev-app}}", &zerolog.Logger{}, 1).line)
+}
+
+func Test_detectBicepLine(t *testing.T) {
+	file := &model.FileMetadata{
+		Kind:     model.KindBICEP,
+		FileName: "main.bicep",
+		Document: model.Document{
+			"resources": []interface{}{
+				map[string]interface{}{"name": "logs", "properties": map[string]interface{}{}},
+				map[string]interface{}{"name": "data.store", "properties": map[string]interface{}{"supportsHttpsTrafficOnly": false}},
+			},
+			model.SourceLinesKey: map[string]interface{}{
+				"resources.0":            float64(1),
+				"resources.1":            float64(4),
+				"resources.1.properties": float64(6),
+				"resources.1.properties.supportsHttpsTrafficOnly": float64(7),
+			},
+		},
+		OriginalData: "resource logs 'Microsoft.Storage/storageAccounts@2021-02-01' = {\n  name: 'logs'\n}\n" +
+			"resource data 'Microsoft.Storage/storageAccounts@2021-02-01' = {\n  name: 'data.store'\n  properties: {\n" +
+			"    supportsHttpsTrafficOnly: false\n  }\n}\n",
+	}
+	require.Equal(t, 7, detectBicepLine(file, "resources.name={{data.store}}.properties.supportsHttpsTrafficOnly",
+		&zerolog.Logger{}, 1).line)
+	// the keys missing from the template are reported on the deepest element found
+	require.Equal(t, 6, detectBicepLine(file, "resources.name={{data.store}}.properties.minimumTlsVersion", &zerolog.Logger{}, 1).line)
+	require.Equal(t, 1, detectBicepLine(file, "resources.name={{logs}}.properties.minimumTlsVersion", &zerolog.Logger{}, 1).line)
+}
+
 // TestDefaultVulnerabilityBuilder tests the functions [DefaultVulnerabilityBuilder] and all the methods called by them
 func TestDefaultVulnerabilityBuilder(t *testing.T) {
 	type args struct {
@@ -809,7 +949,7 @@ func TestEngine_detectHelmLine(t *testing.T) { //nolint
 					Document: model.Document{},
 					Kind:     model.KindHELM,
 					FileName: "test-connection.yaml",
-					HelmID:   "# KICS_HELM_ID_0",
+					Origin:   &model.Origin{SplitID: "# KICS_HELM_ID_0"},
 					OriginalData: `# KICS_HELM_ID_0:
 apiVersion: v1
 kind: Pod
@@ -851,10 +991,12 @@ spec:
 					Document: model.Document{},
 					Kind:     model.KindHELM,
 					FileName: "test-dup_values.yaml",
-					IDInfo: map[int]interface{}{0: map[int]int{0: 0, 1: 1, 2: 2, 3: 3, 4: 4,
-						5: 5, 6: 6, 7: 7, 8: 8, 9: 9, 10: 10, 11: 11, 12: 12, 13: 13, 14: 14, 15: 15, 16: 16, 17: 17,
-						18: 18, 19: 19, 21: 21, 22: 22}},
-					HelmID: "# KICS_HELM_ID_0",
+					Origin: &model.Origin{
+						SplitID: "# KICS_HELM_ID_0",
+						Lines: map[int]interface{}{0: map[int]int{0: 0, 1: 1, 2: 2, 3: 3, 4: 4,
+							5: 5, 6: 6, 7: 7, 8: 8, 9: 9, 10: 10, 11: 11, 12: 12, 13: 13, 14: 14, 15: 15, 16: 16, 17: 17,
+							18: 18, 19: 19, 21: 21, 22: 22}},
+					},
 					OriginalData: `# KICS_HELM_ID_0:
 apiVersion: v1
 kind: Pod
@@ -902,7 +1044,7 @@ spec:
 					Document: model.Document{},
 					Kind:     model.KindHELM,
 					FileName: "test-dups.yaml",
-					HelmID:   "# KICS_HELM_ID_1",
+					Origin:   &model.Origin{SplitID: "# KICS_HELM_ID_1"},
 					OriginalData: `# KICS_HELM_ID_0:
 apiVersion: v1
 kind: Pod
@@ -0,0 +1,167 @@
+package environment
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// FailOnNone is the fail on severity of the environments whose results never fail the scan
+const FailOnNone = "NONE"
+
+// Config is the environments the scanned files are deployed to
+type Config struct {
+	Environments []Environment `yaml:"environments"`
+}
+
+// Environment labels the results in files matching one of its paths, paths are relative to the scanned path and match
+// a file or one of its directories with the syntax of filepath.Match, results with the FailOn severity or above
+// fail the scan, the fail on severity of the scan is used when it is empty
+type Environment struct {
+	Name   string   `yaml:"name"`
+	Paths  []string `yaml:"paths"`
+	FailOn string   `yaml:"fail-on"`
+}
+
+// LoadConfig reads the environments of a YAML file
+func LoadConfig(configPath string) (*Config, error) {
+	f, err := os.Open(filepath.Clean(configPath))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open environments configuration")
+	}
+	defer f.Close()
+
+	var config Config
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && err != io.EOF {
+		return nil, errors.Wrapf(err, "failed to parse environments configuration %s", configPath)
+	}
+	if err := config.validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid environments configuration %s", configPath)
+	}
+	return &config, nil
+}
+
+func (c *Config) validate() error {
+	names := make(map[string]bool, len(c.Environments))
+	for i := range c.Environments {
+		if err := c.Environments[i].validate(); err != nil {
+			return err
+		}
+		if names[c.Environments[i].Name] {
+			return fmt.Errorf("environment %s defined more than once", c.Environments[i].Name)
+		}
+		names[c.Environments[i].Name] = true
+	}
+	return nil
+}
+
+func (e *Environment) validate() error {
+	if e.Name == "" {
+		return fmt.Errorf("environment without name")
+	}
+	if len(e.Paths) == 0 {
+		return fmt.Errorf("environment %s without paths", e.Name)
+	}
+	for i, pattern := range e.Paths {
+		pattern = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(pattern), "./"), "/")
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return errors.Wrapf(err, "invalid path %s of environment %s", e.Paths[i], e.Name)
+		}
+		e.Paths[i] = pattern
+	}
+	if e.FailOn != "" {
+		e.FailOn = strings.ToUpper(e.FailOn)
+		if e.FailOn != FailOnNone && !isSeverity(model.Severity(e.FailOn)) {
+			return fmt.Errorf("invalid fail on severity %s of environment %s, supported values: high, medium, low, info, none",
+				e.FailOn, e.Name)
+		}
+	}
+	return nil
+}
+
+// Classify sets the environment of the results, the first environment with a path matching the file of the result,
+// the file names are made relative to the scanned path basePath
+func (c *Config) Classify(results []model.Vulnerability, basePath string) {
+	if c == nil {
+		return
+	}
+	for i := range results {
+		fileName := filepath.ToSlash(results[i].FileName)
+		if rel, err := filepath.Rel(basePath, results[i].FileName); err == nil && !strings.HasPrefix(filepath.ToSlash(rel), "../") {
+			fileName = filepath.ToSlash(rel)
+		}
+		for j := range c.Environments {
+			if c.Environments[j].matches(fileName) {
+				results[i].Environment = c.Environments[j].Name
+				break
+			}
+		}
+	}
+}
+
+// FailOn returns the fail on severity of the environment, false when the environment does not set one
+func (c *Config) FailOn(environment string) (string, bool) {
+	if c == nil || environment == "" {
+		return "", false
+	}
+	for i := range c.Environments {
+		if c.Environments[i].Name == environment && c.Environments[i].FailOn != "" {
+			return c.Environments[i].FailOn, true
+		}
+	}
+	return "", false
+}
+
+// HasFailOn returns true if an environment sets a fail on severity
+func (c *Config) HasFailOn() bool {
+	if c == nil {
+		return false
+	}
+	for i := range c.Environments {
+		if c.Environments[i].FailOn != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Environment) matches(fileName string) bool {
+	for _, pattern := range e.Paths {
+		if matchPath(pattern, fileName) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath returns true if the pattern matches the file name or one of its parent directories,
+// patterns without a slash match the names of files and directories at any depth
+func matchPath(pattern, fileName string) bool {
+	for path := fileName; path != "." && path != "/" && path != ""; path = filepath.ToSlash(filepath.Dir(path)) {
+		name := path
+		if !strings.Contains(pattern, "/") {
+			name = filepath.Base(path)
+		}
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func isSeverity(severity model.Severity) bool {
+	for _, s := range model.AllSeverities {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
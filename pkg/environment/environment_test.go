@@ -0,0 +1,92 @@
+package environment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoadConfig tests the functions [LoadConfig()] and all the methods called by them
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			content: "environments:\n  - name: prod\n    paths: [./envs/prod/**]\n    fail-on: medium\n  - name: dev\n    paths: [envs/dev]\n",
+		},
+		{name: "empty", content: ""},
+		{name: "without name", content: "environments:\n  - paths: [envs/prod]\n", wantErr: true},
+		{name: "without paths", content: "environments:\n  - name: prod\n", wantErr: true},
+		{name: "duplicated", content: "environments:\n  - name: prod\n    paths: [a]\n  - name: prod\n    paths: [b]\n", wantErr: true},
+		{name: "invalid path", content: "environments:\n  - name: prod\n    paths: ['[']\n", wantErr: true},
+		{name: "invalid severity", content: "environments:\n  - name: prod\n    paths: [a]\n    fail-on: critical\n", wantErr: true},
+		{name: "unknown field", content: "environments:\n  - name: prod\n    paths: [a]\n    tier: 1\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(dir, "environments.yaml")
+			require.NoError(t, os.WriteFile(configPath, []byte(tt.content), 0600))
+			config, err := LoadConfig(configPath)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, config)
+		})
+	}
+
+	_, err := LoadConfig(filepath.Join(dir, "missing.yaml"))
+	require.Error(t, err)
+}
+
+// TestConfig_Classify tests the functions [Classify(), FailOn(), HasFailOn()] and all the methods called by them
+func TestConfig_Classify(t *testing.T) {
+	config := &Config{Environments: []Environment{
+		{Name: "prod", Paths: []string{"./envs/prod/**"}, FailOn: "medium"},
+		{Name: "dev", Paths: []string{"envs/dev", "sandbox"}, FailOn: "none"},
+		{Name: "staging", Paths: []string{"envs/staging"}},
+	}}
+	require.NoError(t, config.validate())
+
+	basePath := filepath.Join("/src", "infra")
+	results := []model.Vulnerability{
+		{FileName: filepath.Join(basePath, "envs", "prod", "main.tf")},
+		{FileName: filepath.Join(basePath, "envs", "prod", "network", "vpc.tf")},
+		{FileName: filepath.Join(basePath, "envs", "dev", "main.tf")},
+		{FileName: filepath.Join(basePath, "modules", "sandbox", "main.tf")},
+		{FileName: filepath.Join(basePath, "modules", "bucket", "main.tf")},
+		{FileName: filepath.Join("/other", "envs", "prod", "main.tf")},
+	}
+	config.Classify(results, basePath)
+	environments := make([]string, 0, len(results))
+	for i := range results {
+		environments = append(environments, results[i].Environment)
+	}
+	require.Equal(t, []string{"prod", "prod", "dev", "dev", "", ""}, environments)
+
+	failOn, ok := config.FailOn("prod")
+	require.True(t, ok)
+	require.Equal(t, "MEDIUM", failOn)
+	failOn, ok = config.FailOn("dev")
+	require.True(t, ok)
+	require.Equal(t, FailOnNone, failOn)
+	_, ok = config.FailOn("staging")
+	require.False(t, ok)
+	_, ok = config.FailOn("")
+	require.False(t, ok)
+	require.True(t, config.HasFailOn())
+
+	var none *Config
+	none.Classify(results, basePath)
+	require.False(t, none.HasFailOn())
+	_, ok = none.FailOn("prod")
+	require.False(t, ok)
+}
@@ -0,0 +1,164 @@
+// Package httpclient builds the HTTP clients of every outbound call of KICS (remote modules, registries,
+// Helm repositories, decision logs and integrations) so they share the same proxy and TLS configuration
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultTimeout is the timeout of the requests of clients without timeout
+const DefaultTimeout = 2 * time.Minute
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Options configures the HTTP clients
+// Proxy overrides the HTTPS_PROXY/HTTP_PROXY environment variables, hosts of NO_PROXY are still reached directly,
+// CABundle is a PEM file with certificates trusted in addition to the system ones
+// and TLSMinVersion is the minimum TLS version accepted (1.0, 1.1, 1.2 or 1.3), 1.2 by default
+type Options struct {
+	Proxy         string
+	CABundle      string
+	TLSMinVersion string
+	Timeout       time.Duration
+}
+
+// New returns an HTTP client with the options
+func New(opts Options) (*http.Client, error) {
+	transport, err := NewTransport(opts)
+	if err != nil {
+		return nil, err
+	}
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// NewTransport returns an HTTP transport with the proxy and TLS options
+func NewTransport(opts Options) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.Proxy != "" {
+		proxyURL, err := url.Parse(opts.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy %s", opts.Proxy)
+		}
+		noProxy := getEnv("NO_PROXY", "no_proxy")
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			if useProxy(req.URL.Hostname(), noProxy) {
+				return proxyURL, nil
+			}
+			return nil, nil
+		}
+	}
+
+	minVersion, err := ParseTLSVersion(opts.TLSMinVersion)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{MinVersion: minVersion} //nolint:gosec
+	if opts.CABundle != "" {
+		if tlsConfig.RootCAs, err = loadCABundle(opts.CABundle); err != nil {
+			return nil, err
+		}
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// ParseTLSVersion returns the TLS version of its name (1.0, 1.1, 1.2 or 1.3), TLS 1.2 when empty
+func ParseTLSVersion(version string) (uint16, error) {
+	if version == "" {
+		return tls.VersionTLS12, nil
+	}
+	tlsVersion, ok := tlsVersions[strings.TrimPrefix(strings.ToLower(version), "tls")]
+	if !ok {
+		return 0, fmt.Errorf("TLS version not supported: %s, supported versions: 1.0, 1.1, 1.2, 1.3", version)
+	}
+	return tlsVersion, nil
+}
+
+// Environ returns the environment of child processes making their own outbound calls, such as git,
+// with the proxy and CA bundle of the options
+func Environ(opts Options) []string {
+	env := os.Environ()
+	if opts.Proxy != "" {
+		env = append(env, "HTTPS_PROXY="+opts.Proxy, "HTTP_PROXY="+opts.Proxy, "https_proxy="+opts.Proxy, "http_proxy="+opts.Proxy)
+	}
+	if opts.CABundle != "" {
+		env = append(env, "GIT_SSL_CAINFO="+opts.CABundle)
+	}
+	return env
+}
+
+func loadCABundle(path string) (*x509.CertPool, error) {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read CA bundle")
+	}
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil || rootCAs == nil {
+		rootCAs = x509.NewCertPool()
+	}
+	if !rootCAs.AppendCertsFromPEM(content) {
+		return nil, errors.Errorf("no certificates found in CA bundle %s", path)
+	}
+	return rootCAs, nil
+}
+
+// useProxy returns false when the host matches the NO_PROXY list: "*", IP addresses, CIDR ranges,
+// domains and their subdomains
+func useProxy(host, noProxy string) bool {
+	ip := net.ParseIP(host)
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		switch {
+		case entry == "":
+			continue
+		case entry == "*":
+			return false
+		case ip != nil:
+			if _, cidr, err := net.ParseCIDR(entry); err == nil && cidr.Contains(ip) {
+				return false
+			}
+			if entry == host {
+				return false
+			}
+		default:
+			domain := strings.TrimPrefix(entry, "*")
+			host = strings.ToLower(host)
+			if host == strings.TrimPrefix(domain, ".") || strings.HasSuffix(host, "."+strings.TrimPrefix(domain, ".")) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func getEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNew tests the functions [New()] and all the methods called by them
+func TestNew(t *testing.T) {
+	client, err := New(Options{})
+	require.NoError(t, err)
+	require.Equal(t, DefaultTimeout, client.Timeout)
+	transport := client.Transport.(*http.Transport)
+	require.Equal(t, uint16(tls.VersionTLS12), transport.TLSClientConfig.MinVersion)
+
+	client, err = New(Options{TLSMinVersion: "1.3"})
+	require.NoError(t, err)
+	require.Equal(t, uint16(tls.VersionTLS13), client.Transport.(*http.Transport).TLSClientConfig.MinVersion)
+
+	_, err = New(Options{TLSMinVersion: "1.4"})
+	require.Error(t, err)
+	_, err = New(Options{Proxy: "://invalid"})
+	require.Error(t, err)
+	_, err = New(Options{CABundle: filepath.Join(t.TempDir(), "missing.pem")})
+	require.Error(t, err)
+}
+
+// TestNew_CABundle tests the functions [New()] trusting the certificates of the CA bundle
+func TestNew_CABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := New(Options{})
+	require.NoError(t, err)
+	_, err = client.Get(server.URL)
+	require.Error(t, err)
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caBundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600))
+	client, err = New(Options{CABundle: caBundle})
+	require.NoError(t, err)
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+// TestNewTransport_Proxy tests the functions [NewTransport()] with a proxy and the hosts of NO_PROXY
+func TestNewTransport_Proxy(t *testing.T) {
+	require.NoError(t, os.Setenv("NO_PROXY", "localhost,.internal.example.com,10.0.0.0/8"))
+	defer func() {
+		require.NoError(t, os.Unsetenv("NO_PROXY"))
+	}()
+	transport, err := NewTransport(Options{Proxy: "http://proxy.example.com:3128"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		url   string
+		proxy bool
+	}{
+		{url: "https://registry.terraform.io/v1/modules", proxy: true},
+		{url: "https://localhost:5000/v2/", proxy: false},
+		{url: "https://charts.internal.example.com/index.yaml", proxy: false},
+		{url: "https://internal.example.com/index.yaml", proxy: false},
+		{url: "https://10.1.2.3/index.yaml", proxy: false},
+		{url: "https://192.168.1.1/index.yaml", proxy: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			reqURL, err := url.Parse(tt.url)
+			require.NoError(t, err)
+			proxyURL, err := transport.Proxy(&http.Request{URL: reqURL})
+			require.NoError(t, err)
+			if tt.proxy {
+				require.Equal(t, "proxy.example.com:3128", proxyURL.Host)
+			} else {
+				require.Nil(t, proxyURL)
+			}
+		})
+	}
+}
+
+// TestEnviron tests the functions [Environ()] and all the methods called by them
+func TestEnviron(t *testing.T) {
+	env := Environ(Options{Proxy: "http://proxy.example.com:3128", CABundle: "/etc/ssl/ca.pem"})
+	require.Contains(t, env, "HTTPS_PROXY=http://proxy.example.com:3128")
+	require.Contains(t, env, "GIT_SSL_CAINFO=/etc/ssl/ca.pem")
+}
@@ -0,0 +1,84 @@
+package kics
+
+import (
+	"path/filepath"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// resultKey identifies a result by the file its document originates from, rendered documents originate
+// from the templates they were rendered from
+type resultKey struct {
+	queryID     string
+	fileName    string
+	searchKey   string
+	searchValue string
+}
+
+// deduplicateRendered reports once the results found both in a document rendered by a resolver and in the file it
+// originates from scanned directly (ex: a static Helm template), the result of the rendered document is kept, since
+// its values are resolved, with the locations of both results
+func deduplicateRendered(vulnerabilities []model.Vulnerability, files model.FileMetadatas) []model.Vulnerability {
+	rendered := make(map[string]bool)
+	for i := range files {
+		if files[i].Origin != nil {
+			rendered[files[i].ID] = true
+		}
+	}
+	if len(rendered) == 0 {
+		return vulnerabilities
+	}
+
+	renderedResults := make(map[resultKey][]int)
+	for i := range vulnerabilities {
+		if rendered[vulnerabilities[i].FileID] {
+			key := newResultKey(&vulnerabilities[i])
+			renderedResults[key] = append(renderedResults[key], i)
+		}
+	}
+
+	duplicated := make(map[int]bool)
+	for i := range vulnerabilities {
+		if rendered[vulnerabilities[i].FileID] {
+			continue
+		}
+		for _, j := range renderedResults[newResultKey(&vulnerabilities[i])] {
+			if len(vulnerabilities[j].Locations) == 0 {
+				vulnerabilities[j].Locations = []model.Location{newLocation(&vulnerabilities[j], true)}
+			}
+			vulnerabilities[j].Locations = append(vulnerabilities[j].Locations, newLocation(&vulnerabilities[i], false))
+			duplicated[i] = true
+		}
+	}
+	if len(duplicated) == 0 {
+		return vulnerabilities
+	}
+	log.Debug().Msgf("%d results of original files found in rendered documents", len(duplicated))
+
+	deduplicated := make([]model.Vulnerability, 0, len(vulnerabilities)-len(duplicated))
+	for i := range vulnerabilities {
+		if !duplicated[i] {
+			deduplicated = append(deduplicated, vulnerabilities[i])
+		}
+	}
+	return deduplicated
+}
+
+func newResultKey(vulnerability *model.Vulnerability) resultKey {
+	return resultKey{
+		queryID:     vulnerability.QueryID,
+		fileName:    filepath.ToSlash(filepath.Clean(vulnerability.FileName)),
+		searchKey:   vulnerability.SearchKey,
+		searchValue: vulnerability.SearchValue,
+	}
+}
+
+func newLocation(vulnerability *model.Vulnerability, rendered bool) model.Location {
+	return model.Location{
+		FileName:  vulnerability.FileName,
+		Line:      vulnerability.Line,
+		SearchKey: vulnerability.SearchKey,
+		Rendered:  rendered,
+	}
+}
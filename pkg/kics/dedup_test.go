@@ -0,0 +1,36 @@
+package kics
+
+import (
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDeduplicateRendered tests the functions [deduplicateRendered()] and all the methods called by them
+func TestDeduplicateRendered(t *testing.T) {
+	files := model.FileMetadatas{
+		{ID: "rendered", FileName: "/chart/templates/service.yaml", Origin: &model.Origin{SplitID: "# KICS_HELM_ID_0:"}},
+		{ID: "original", FileName: "/chart/templates/service.yaml"},
+		{ID: "other", FileName: "/deploy/service.yaml"},
+	}
+	vulnerabilities := []model.Vulnerability{
+		{FileID: "rendered", QueryID: "q1", FileName: "/chart/templates/service.yaml", Line: 4, SearchKey: "metadata.name"},
+		{FileID: "rendered", QueryID: "q2", FileName: "/chart/templates/service.yaml", Line: 8, SearchKey: "spec.type"},
+		{FileID: "original", QueryID: "q1", FileName: "/chart/templates/service.yaml", Line: 5, SearchKey: "metadata.name"},
+		{FileID: "original", QueryID: "q3", FileName: "/chart/templates/service.yaml", Line: 9, SearchKey: "spec.ports"},
+		{FileID: "other", QueryID: "q1", FileName: "/deploy/service.yaml", Line: 4, SearchKey: "metadata.name"},
+	}
+
+	got := deduplicateRendered(vulnerabilities, files)
+	require.Len(t, got, 4)
+	require.Equal(t, []model.Location{
+		{FileName: "/chart/templates/service.yaml", Line: 4, SearchKey: "metadata.name", Rendered: true},
+		{FileName: "/chart/templates/service.yaml", Line: 5, SearchKey: "metadata.name"},
+	}, got[0].Locations)
+	require.Empty(t, got[1].Locations)
+	require.Equal(t, "q3", got[2].QueryID)
+	require.Equal(t, "/deploy/service.yaml", got[3].FileName)
+
+	require.Equal(t, vulnerabilities[4:], deduplicateRendered(vulnerabilities[4:], files[2:]))
+}
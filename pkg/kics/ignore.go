@@ -0,0 +1,30 @@
+package kics
+
+import (
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// queryExcluder is implemented by the source providers excluding the results of queries in some of their files,
+// such as the filesystem provider with the query IDs of its ignore file
+type queryExcluder interface {
+	ExcludedQuery(fileName, queryID string) bool
+}
+
+// excludeIgnoredQueries drops the vulnerabilities of the queries excluded by the source provider in their files
+func excludeIgnoredQueries(vulnerabilities []model.Vulnerability, sourceProvider interface{}) []model.Vulnerability {
+	excluder, ok := sourceProvider.(queryExcluder)
+	if !ok {
+		return vulnerabilities
+	}
+	kept := make([]model.Vulnerability, 0, len(vulnerabilities))
+	for i := range vulnerabilities {
+		if !excluder.ExcludedQuery(vulnerabilities[i].FileName, vulnerabilities[i].QueryID) {
+			kept = append(kept, vulnerabilities[i])
+		}
+	}
+	if excluded := len(vulnerabilities) - len(kept); excluded > 0 {
+		log.Info().Msgf("%d results excluded by the ignore file", excluded)
+	}
+	return kept
+}
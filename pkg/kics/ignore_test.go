@@ -0,0 +1,28 @@
+package kics
+
+import (
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+type excluder map[string]string
+
+func (e excluder) ExcludedQuery(fileName, queryID string) bool {
+	return e[fileName] == queryID
+}
+
+// TestExcludeIgnoredQueries tests the functions [excludeIgnoredQueries()] and all the methods called by them
+func TestExcludeIgnoredQueries(t *testing.T) {
+	vulnerabilities := []model.Vulnerability{
+		{FileName: "/infra/legacy/main.tf", QueryID: "q1"},
+		{FileName: "/infra/legacy/main.tf", QueryID: "q2"},
+		{FileName: "/infra/main.tf", QueryID: "q1"},
+	}
+
+	kept := excludeIgnoredQueries(vulnerabilities, excluder{"/infra/legacy/main.tf": "q1"})
+	require.Equal(t, vulnerabilities[1:], kept)
+
+	require.Equal(t, vulnerabilities, excludeIgnoredQueries(vulnerabilities, nil))
+}
@@ -0,0 +1,184 @@
+package kics
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+)
+
+const (
+	dockerfileName = "dockerfile"
+	imagesKey      = "images"
+)
+
+// correlateImages adds to each Dockerfile document the images referenced by the other documents of the scan
+// (Kubernetes manifests, Helm charts, docker-compose files) that are built from the Dockerfile, so queries can
+// check the Dockerfile of the images deployed, images are built from a Dockerfile when docker-compose builds them
+// from it or when their repository name is the name of the Dockerfile (api.Dockerfile, Dockerfile.api)
+// or of its directory (api/Dockerfile)
+func correlateImages(files model.FileMetadatas) {
+	c := newImageCorrelator()
+	c.collect(files)
+	c.correlate(files)
+}
+
+// imageCorrelator correlates the Dockerfiles of a scan inspected in batches with the images referenced by the documents
+// of all the batches, the Dockerfiles are held back until the images of the last batch were collected, only the images
+// referenced are kept for the other documents
+type imageCorrelator struct {
+	dockerfiles model.FileMetadatas
+	// builds are the images built by docker-compose from each Dockerfile, by path
+	builds map[string]map[string]bool
+	images map[string]bool
+}
+
+func newImageCorrelator() *imageCorrelator {
+	return &imageCorrelator{
+		builds: make(map[string]map[string]bool),
+		images: make(map[string]bool),
+	}
+}
+
+// hold collects the images referenced by the documents of a batch and holds its Dockerfiles back,
+// it returns the other documents of the batch
+func (c *imageCorrelator) hold(files model.FileMetadatas) model.FileMetadatas {
+	c.collect(files)
+	others := make(model.FileMetadatas, 0, len(files))
+	for i := range files {
+		if files[i].Kind == model.KindDOCKER {
+			c.dockerfiles = append(c.dockerfiles, files[i])
+			continue
+		}
+		others = append(others, files[i])
+	}
+	return others
+}
+
+// release returns the Dockerfiles held back with the images collected that are built from them
+func (c *imageCorrelator) release() model.FileMetadatas {
+	dockerfiles := c.dockerfiles
+	c.dockerfiles = nil
+	c.correlate(dockerfiles)
+	return dockerfiles
+}
+
+// collect collects the images referenced by the documents that are not Dockerfiles
+func (c *imageCorrelator) collect(files model.FileMetadatas) {
+	for i := range files {
+		if files[i].Kind == model.KindDOCKER {
+			continue
+		}
+		for path, image := range composeBuilds(&files[i]) {
+			if c.builds[path] == nil {
+				c.builds[path] = make(map[string]bool)
+			}
+			c.builds[path][image] = true
+		}
+		walkImages(map[string]interface{}(files[i].Document), func(image string) {
+			c.images[image] = true
+		})
+	}
+}
+
+// correlate adds to each Dockerfile document the images collected that are built from it
+func (c *imageCorrelator) correlate(files model.FileMetadatas) {
+	for i := range files {
+		if files[i].Kind != model.KindDOCKER || files[i].Document == nil {
+			continue
+		}
+		path := filepath.Clean(files[i].FileName)
+		images := make(map[string]bool)
+		for image := range c.builds[path] {
+			images[image] = true
+		}
+		name := dockerfileImageName(path)
+		for image := range c.images {
+			if strings.EqualFold(imageRepositoryName(image), name) {
+				images[image] = true
+			}
+		}
+		referenced := make([]string, 0, len(images))
+		for image := range images {
+			referenced = append(referenced, image)
+		}
+		sort.Strings(referenced)
+		files[i].Document[imagesKey] = referenced
+	}
+}
+
+// walkImages calls fn with the value of each image key of value
+func walkImages(value interface{}, fn func(image string)) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if image, ok := child.(string); ok && key == "image" && image != "" {
+				fn(image)
+				continue
+			}
+			walkImages(child, fn)
+		}
+	case []interface{}:
+		for _, item := range v {
+			walkImages(item, fn)
+		}
+	}
+}
+
+// composeBuilds returns the Dockerfiles built by the services of a docker-compose file with the image they are tagged
+func composeBuilds(file *model.FileMetadata) map[string]string {
+	builds := make(map[string]string)
+	services, _ := file.Document["services"].(map[string]interface{})
+	for _, s := range services {
+		service, _ := s.(map[string]interface{})
+		image, _ := service["image"].(string)
+		if image == "" {
+			continue
+		}
+		buildContext, dockerfile := ".", "Dockerfile"
+		switch build := service["build"].(type) {
+		case string:
+			buildContext = build
+		case map[string]interface{}:
+			if c, ok := build["context"].(string); ok {
+				buildContext = c
+			}
+			if d, ok := build["dockerfile"].(string); ok {
+				dockerfile = d
+			}
+		default:
+			continue
+		}
+		builds[filepath.Join(filepath.Dir(file.FileName), buildContext, dockerfile)] = image
+	}
+	return builds
+}
+
+// imageRepositoryName returns the last component of the repository of the image, without registry, tag and digest
+func imageRepositoryName(image string) string {
+	if idx := strings.Index(image, "@"); idx >= 0 {
+		image = image[:idx]
+	}
+	if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+		image = image[:idx]
+	}
+	return image[strings.LastIndex(image, "/")+1:]
+}
+
+// dockerfileImageName returns the name of the image built by the Dockerfile according to its name
+// (api.Dockerfile, Dockerfile.api) or, for files named Dockerfile, its directory
+func dockerfileImageName(path string) string {
+	name := filepath.Base(path)
+	lower := strings.ToLower(name)
+	switch {
+	case lower == dockerfileName:
+		return filepath.Base(filepath.Dir(path))
+	case strings.HasPrefix(lower, dockerfileName+"."):
+		return name[len(dockerfileName)+1:]
+	case strings.HasSuffix(lower, "."+dockerfileName):
+		return name[:len(name)-len(dockerfileName)-1]
+	default:
+		return name
+	}
+}
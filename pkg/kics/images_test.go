@@ -0,0 +1,62 @@
+package kics
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCorrelateImages tests the functions [correlateImages()] and all the methods called by them
+func TestCorrelateImages(t *testing.T) {
+	root := filepath.FromSlash("/repo")
+	files := model.FileMetadatas{
+		{Kind: model.KindDOCKER, FileName: filepath.Join(root, "api", "Dockerfile"), Document: model.Document{}},
+		{Kind: model.KindDOCKER, FileName: filepath.Join(root, "worker.Dockerfile"), Document: model.Document{}},
+		{Kind: model.KindDOCKER, FileName: filepath.Join(root, "web", "Dockerfile.prod"), Document: model.Document{}},
+		{Kind: model.KindDOCKER, FileName: filepath.Join(root, "unused", "Dockerfile"), Document: model.Document{}},
+		{
+			Kind:     model.KindYAML,
+			FileName: filepath.Join(root, "deploy", "api.yaml"),
+			Document: model.Document{
+				"kind": "Deployment",
+				"spec": map[string]interface{}{
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"containers": []interface{}{
+								map[string]interface{}{"name": "api", "image": "registry.io/org/api:1.0"},
+								map[string]interface{}{"name": "worker", "image": "localhost:5000/worker@sha256:abc"},
+								map[string]interface{}{"name": "proxy", "image": "nginx:1.21"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Kind:     model.KindYAML,
+			FileName: filepath.Join(root, "docker-compose.yml"),
+			Document: model.Document{
+				"services": map[string]interface{}{
+					"web": map[string]interface{}{
+						"image": "org/frontend:latest",
+						"build": map[string]interface{}{"context": "web", "dockerfile": "Dockerfile.prod"},
+					},
+					"api": map[string]interface{}{
+						"image": "org/backend",
+						"build": "./api",
+					},
+				},
+			},
+		},
+	}
+
+	correlateImages(files)
+
+	require.Equal(t, []string{"org/backend", "registry.io/org/api:1.0"}, files[0].Document["images"])
+	require.Equal(t, []string{"localhost:5000/worker@sha256:abc"}, files[1].Document["images"])
+	require.Equal(t, []string{"org/frontend:latest"}, files[2].Document["images"])
+	require.Equal(t, []string{}, files[3].Document["images"])
+	require.NotContains(t, files[4].Document, "images")
+}
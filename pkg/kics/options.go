@@ -0,0 +1,273 @@
+package kics
+
+import (
+	"context"
+
+	"github.com/Checkmarx/kics/pkg/engine"
+	"github.com/Checkmarx/kics/pkg/engine/provider"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/parser"
+	"github.com/Checkmarx/kics/pkg/resolver"
+	"github.com/Checkmarx/kics/pkg/watchdog"
+	"github.com/getsentry/sentry-go"
+	"github.com/pkg/errors"
+)
+
+// DefaultMaxFileSize is the size limit, in bytes, of the files scanned by the services without a limit set
+const DefaultMaxFileSize = 5 * 1024 * 1024
+
+// Option configures a Service created with NewService, it returns an error when its value is not valid
+type Option func(s *Service) error
+
+// ErrorReporter reports the unexpected errors of the scans, such as documents that can't be encoded, to an error tracker
+type ErrorReporter func(err error)
+
+// Hooks are called as the scans progress, they must not modify the files and vulnerabilities they are given
+// FileParsed is called with each document parsed, before it is saved to the storage
+// BatchInspected is called with the vulnerabilities of each batch of files inspected, before they are saved to the storage,
+// it is called while the next files are parsed, so the hooks must be safe for concurrent use
+type Hooks struct {
+	FileParsed     func(ctx context.Context, file *model.FileMetadata)
+	BatchInspected func(ctx context.Context, files model.FileMetadatas, vulnerabilities []model.Vulnerability)
+}
+
+// Progress is the progress of a scan, the documents parsed, the documents inspected and the vulnerabilities found
+// in the documents inspected
+type Progress struct {
+	DocumentsParsed    int
+	DocumentsInspected int
+	Vulnerabilities    int
+}
+
+// ProgressSink is the interface that wraps the method Progress, which receives the progress of a scan each time
+// a document is parsed or a batch of documents is inspected, such as to update a UI
+type ProgressSink interface {
+	Progress(ctx context.Context, scanID string, progress Progress)
+}
+
+// NewService creates a Service with the options, the source provider, the parser, the policy engine and the tracker
+// are required, the services without storage only return the results of their scans and the services without resolver
+// don't resolve any file, the options are validated once all of them are applied
+func NewService(opts ...Option) (*Service, error) {
+	s := &Service{
+		MaxFileSize: DefaultMaxFileSize,
+		SavePolicy:  SavePolicy{ChunkSize: DefaultSaveChunkSize},
+	}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case s.SourceProvider == nil:
+		return nil, errors.New("service requires a source provider")
+	case s.Parser == nil:
+		return nil, errors.New("service requires a parser")
+	case s.Inspector == nil:
+		return nil, errors.New("service requires a policy engine")
+	case s.Tracker == nil:
+		return nil, errors.New("service requires a tracker")
+	}
+	if s.Resolver == nil {
+		combinedResolver, err := resolver.NewBuilder().Build()
+		if err != nil {
+			return nil, err
+		}
+		s.Resolver = combinedResolver
+	}
+	if s.parallelism != nil {
+		parallelEngine, ok := s.Inspector.(engine.ParallelEngine)
+		if !ok {
+			return nil, errors.New("the policy engine does not evaluate queries in parallel")
+		}
+		parallelEngine.SetParallelism(*s.parallelism)
+	}
+	if reportingEngine, ok := s.Inspector.(engine.ReportingEngine); ok {
+		reportingEngine.SetErrorReporter(s.reportError)
+	}
+	return s, nil
+}
+
+// WithSourceProvider sets the provider of the files scanned
+func WithSourceProvider(sourceProvider provider.SourceProvider) Option {
+	return func(s *Service) error {
+		s.SourceProvider = sourceProvider
+		return nil
+	}
+}
+
+// WithStorage sets the storage of the files and vulnerabilities of the scans, the services without storage don't keep them
+func WithStorage(storage Storage) Option {
+	return func(s *Service) error {
+		s.Storage = storage
+		return nil
+	}
+}
+
+// WithParser sets the parser of the files scanned
+func WithParser(p *parser.Parser) Option {
+	return func(s *Service) error {
+		s.Parser = p
+		return nil
+	}
+}
+
+// WithPolicyEngine sets the policy engine that inspects the files parsed
+func WithPolicyEngine(policyEngine engine.PolicyEngine) Option {
+	return func(s *Service) error {
+		s.Inspector = policyEngine
+		return nil
+	}
+}
+
+// WithTracker sets the tracker of the files scanned
+func WithTracker(tracker Tracker) Option {
+	return func(s *Service) error {
+		s.Tracker = tracker
+		return nil
+	}
+}
+
+// WithResolver sets the resolver of the files and templates rendered before they are parsed (ex: Helm charts)
+func WithResolver(r *resolver.Resolver) Option {
+	return func(s *Service) error {
+		s.Resolver = r
+		return nil
+	}
+}
+
+// WithEnrichers adds enrichers of the documents parsed
+func WithEnrichers(enrichers ...Enricher) Option {
+	return func(s *Service) error {
+		for _, enricher := range enrichers {
+			if enricher == nil {
+				return errors.New("enrichers can't be nil")
+			}
+		}
+		s.Enrichers = append(s.Enrichers, enrichers...)
+		return nil
+	}
+}
+
+// WithWatchdog sets the watchdog tracking the files in progress
+func WithWatchdog(w *watchdog.Watchdog) Option {
+	return func(s *Service) error {
+		s.Watchdog = w
+		return nil
+	}
+}
+
+// WithProjectID sets the project the scans are grouped by in the storages keeping the history of the scans
+func WithProjectID(projectID string) Option {
+	return func(s *Service) error {
+		s.ProjectID = projectID
+		return nil
+	}
+}
+
+// WithBatchSize sets the minimum number of files inspected together while the next ones are parsed, the batches are cut
+// between directories, 0 inspects all the files of a scan together
+func WithBatchSize(batchSize int) Option {
+	return func(s *Service) error {
+		if batchSize < 0 {
+			return errors.Errorf("invalid batch size %d, it can't be negative", batchSize)
+		}
+		s.BatchSize = batchSize
+		return nil
+	}
+}
+
+// WithRedactContent saves the files to the storage with each line of their content hashed
+func WithRedactContent(redact bool) Option {
+	return func(s *Service) error {
+		s.RedactContent = redact
+		return nil
+	}
+}
+
+// WithMaxFileSize sets the size limit, in bytes, of the files scanned, larger files fail with FILE_TOO_LARGE
+func WithMaxFileSize(maxFileSize int64) Option {
+	return func(s *Service) error {
+		if maxFileSize <= 0 {
+			return errors.Errorf("invalid max file size %d, it must be positive", maxFileSize)
+		}
+		s.MaxFileSize = maxFileSize
+		return nil
+	}
+}
+
+// WithSavePolicy sets how the vulnerabilities of the scans are saved to the storage, in chunks of
+// DefaultSaveChunkSize vulnerabilities without retries by default
+func WithSavePolicy(policy SavePolicy) Option {
+	return func(s *Service) error {
+		switch {
+		case policy.ChunkSize < 0:
+			return errors.Errorf("invalid save chunk size %d, it can't be negative", policy.ChunkSize)
+		case policy.Retries < 0:
+			return errors.Errorf("invalid save retries %d, it can't be negative", policy.Retries)
+		case policy.Backoff < 0:
+			return errors.Errorf("invalid save backoff %v, it can't be negative", policy.Backoff)
+		}
+		s.SavePolicy = policy
+		return nil
+	}
+}
+
+// WithParallelism sets the number of queries evaluated in parallel by the policy engines that support it,
+// the number of CPUs when zero, NewService applies it to the policy engine once all the options are applied
+func WithParallelism(parallelism int) Option {
+	return func(s *Service) error {
+		if parallelism < 0 {
+			return errors.Errorf("invalid parallelism %d, it can't be negative", parallelism)
+		}
+		s.parallelism = &parallelism
+		return nil
+	}
+}
+
+// WithHooks sets the hooks called as the scans progress
+func WithHooks(hooks Hooks) Option {
+	return func(s *Service) error {
+		s.Hooks = hooks
+		return nil
+	}
+}
+
+// WithErrorReporter sets the reporter of the unexpected errors of the scans, sentry by default
+func WithErrorReporter(reporter ErrorReporter) Option {
+	return func(s *Service) error {
+		if reporter == nil {
+			return errors.New("error reporter can't be nil")
+		}
+		s.ErrorReporter = reporter
+		return nil
+	}
+}
+
+// WithProgressSink sets the sink of the progress of the scans
+func WithProgressSink(sink ProgressSink) Option {
+	return func(s *Service) error {
+		if sink == nil {
+			return errors.New("progress sink can't be nil")
+		}
+		s.ProgressSink = sink
+		return nil
+	}
+}
+
+// reportError reports an unexpected error of a scan to the error reporter of the service, or sentry by default
+func (s *Service) reportError(err error) {
+	if s.ErrorReporter != nil {
+		s.ErrorReporter(err)
+		return
+	}
+	sentry.CaptureException(err)
+}
+
+// getMaxFileSize returns the size limit of the files scanned, services created without NewService use the default limit
+func (s *Service) getMaxFileSize() int64 {
+	if s.MaxFileSize <= 0 {
+		return DefaultMaxFileSize
+	}
+	return s.MaxFileSize
+}
@@ -0,0 +1,150 @@
+package kics
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Checkmarx/kics/internal/tracker"
+	"github.com/Checkmarx/kics/pkg/engine"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProgressSink struct {
+	mutex    sync.Mutex
+	progress []Progress
+}
+
+func (f *fakeProgressSink) Progress(ctx context.Context, scanID string, progress Progress) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.progress = append(f.progress, progress)
+}
+
+type fakeParallelEngine struct {
+	fakePolicyEngine
+	parallelism   int
+	errorReporter func(err error)
+}
+
+func (f *fakeParallelEngine) SetParallelism(parallelism int) {
+	f.parallelism = parallelism
+}
+
+func (f *fakeParallelEngine) SetErrorReporter(reporter func(err error)) {
+	f.errorReporter = reporter
+}
+
+// TestNewService tests the functions [NewService()] and all the options
+func TestNewService(t *testing.T) {
+	mockParser, mockFilesSource := createParserSourceProvider("../../assets/queries/template")
+	required := []Option{
+		WithSourceProvider(mockFilesSource),
+		WithParser(mockParser),
+		WithPolicyEngine(&fakePolicyEngine{}),
+		WithTracker(&tracker.CITracker{}),
+	}
+
+	s, err := NewService(required...)
+	require.NoError(t, err)
+	require.NotNil(t, s.Resolver)
+	require.Nil(t, s.Storage)
+	require.Equal(t, int64(DefaultMaxFileSize), s.MaxFileSize)
+	require.Equal(t, SavePolicy{ChunkSize: DefaultSaveChunkSize}, s.SavePolicy)
+
+	for i := range required {
+		missing := append(append([]Option{}, required[:i]...), required[i+1:]...)
+		_, err = NewService(missing...)
+		require.Error(t, err)
+	}
+
+	invalid := []Option{
+		WithBatchSize(-1),
+		WithMaxFileSize(0),
+		WithSavePolicy(SavePolicy{ChunkSize: -1}),
+		WithSavePolicy(SavePolicy{Retries: -1}),
+		WithParallelism(-1),
+		// the fake policy engine does not evaluate queries in parallel
+		WithParallelism(2),
+		WithErrorReporter(nil),
+		WithProgressSink(nil),
+		WithEnrichers(nil),
+	}
+	for _, option := range invalid {
+		_, err = NewService(append(append([]Option{}, required...), option)...)
+		require.Error(t, err)
+	}
+
+	// the parallelism is applied to the policy engine set last, whatever the order of the options
+	policyEngine := &fakeParallelEngine{}
+	var reported []error
+	s, err = NewService(append(append([]Option{}, required...),
+		WithParallelism(4),
+		WithErrorReporter(func(err error) { reported = append(reported, err) }),
+		WithPolicyEngine(engine.PolicyEngines{policyEngine}),
+		WithBatchSize(2),
+		WithMaxFileSize(1024),
+		WithProjectID("project"),
+	)...)
+	require.NoError(t, err)
+	require.Equal(t, 2, s.BatchSize)
+	require.Equal(t, int64(1024), s.MaxFileSize)
+	require.Equal(t, "project", s.ProjectID)
+	require.Equal(t, 4, policyEngine.parallelism)
+	// the errors of the evaluations are reported to the error reporter of the service
+	policyEngine.errorReporter(errors.New("evaluation failed"))
+	require.Len(t, reported, 1)
+}
+
+// TestService_Options tests the functions [StartScan()] with the hooks, the progress sink and the file size limit
+// of the service and all the methods called by them
+func TestService_Options(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.yaml":   "kind: Pod\n",
+		"b.yaml":   "kind: Service\n",
+		"big.yaml": "kind: ConfigMap\ndata:\n  value: " + strings.Repeat("a", 64) + "\n",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	mockParser, mockFilesSource := createParserSourceProvider(dir)
+	tr := &tracker.CITracker{}
+	sink := &fakeProgressSink{}
+	var parsed []string
+	var inspected int
+	s, err := NewService(
+		WithSourceProvider(mockFilesSource),
+		WithStorage(storage.NewMemoryStorage()),
+		WithParser(mockParser),
+		WithPolicyEngine(&fakePolicyEngine{}),
+		WithTracker(tr),
+		WithBatchSize(1),
+		WithMaxFileSize(32),
+		WithHooks(Hooks{
+			FileParsed: func(ctx context.Context, file *model.FileMetadata) {
+				parsed = append(parsed, filepath.Base(file.FileName))
+			},
+			BatchInspected: func(ctx context.Context, files model.FileMetadatas, vulnerabilities []model.Vulnerability) {
+				inspected += len(vulnerabilities)
+			},
+		}),
+		WithProgressSink(sink),
+	)
+	require.NoError(t, err)
+	_, err = s.StartScan(context.Background(), "scanID", true)
+	require.NoError(t, err)
+
+	require.ElementsMatch(t, []string{"a.yaml", "b.yaml"}, parsed)
+	require.Equal(t, 2, inspected)
+	require.Len(t, tr.UnparsedFiles, 1)
+	require.Equal(t, model.ReasonFileTooLarge, tr.UnparsedFiles[0].Code)
+
+	require.Len(t, sink.progress, 4)
+	require.Equal(t, Progress{DocumentsParsed: 2, DocumentsInspected: 2, Vulnerabilities: 2}, sink.progress[3])
+}
@@ -0,0 +1,241 @@
+package kics
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Checkmarx/kics/pkg/engine"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// pipeline inspects the batches of files of a scan while the next files are parsed, so only the files of the batches
+// in progress are kept in memory, a batch is sent once it has the batch size of the service and the next file is
+// in another directory, so the files correlated by the queries (ex: the files of a Terraform module) are inspected
+// together, the files under a directory resolved (ex: the templates of a Helm chart) stay with the documents rendered
+// from it, all the files of the scan are a single batch when the batch size is 0, the Dockerfiles are held back
+// to the last batch, so they are correlated with the images of all the batches, the vulnerabilities of the batches
+// are added to the result of the scan
+type pipeline struct {
+	service      *Service
+	scanID       string
+	hideProgress bool
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	files   model.FileMetadatas
+	batches chan model.FileMetadatas
+	done    chan struct{}
+	// err is the first error of the inspection of the batches, read once the pipeline is closed
+	err error
+	// result is updated by the inspection of the batches and read once the pipeline is closed
+	result *ScanResult
+	// counters are the files found and the documents parsed, updated as the files are parsed
+	counters model.Counters
+	// full is set once the batch in progress has the batch size, it is sent before the next file that is neither
+	// in lastDir, the directory of the last file added, nor under resolved, the last directory resolved in the batch
+	full     bool
+	lastDir  string
+	resolved string
+	// resolving is the directory whose rendered files are being added
+	resolving string
+	// batch is the number of batches inspected
+	batch  int
+	images *imageCorrelator
+
+	// progressMutex guards progress, which is updated by the parsing of the files and the inspection of the batches
+	progressMutex sync.Mutex
+	progress      Progress
+}
+
+// startPipeline starts the inspection of the batches of files of the scan
+func (s *Service) startPipeline(ctx context.Context, scanID string, hideProgress bool) *pipeline {
+	ctx, cancel := context.WithCancel(ctx)
+	p := &pipeline{
+		service:      s,
+		scanID:       scanID,
+		hideProgress: hideProgress,
+		ctx:          ctx,
+		cancel:       cancel,
+		// a batch is parsed while the previous one is inspected
+		batches: make(chan model.FileMetadatas, 1),
+		done:    make(chan struct{}),
+		result:  newScanResult(scanID),
+		images:  newImageCorrelator(),
+	}
+	go p.run()
+	return p
+}
+
+// stopped returns true if the inspection failed or the scan was canceled, the next files are not parsed
+func (p *pipeline) stopped() bool {
+	return p.ctx.Err() != nil
+}
+
+// found tracks a file found in the sources of the scan
+func (p *pipeline) found() {
+	p.service.Tracker.TrackFileFound()
+	p.counters.ScannedFiles++
+}
+
+// failed tracks a file that failed to parse, or a file or directory that failed to render when rendered is set,
+// they are skipped by the scan
+func (p *pipeline) failed(fileName string, err error, rendered bool) {
+	if rendered {
+		p.service.Tracker.TrackFileRenderFailure(fileName, err)
+	} else {
+		p.service.Tracker.TrackFileParseFailure(fileName, err)
+	}
+	p.result.addFileFailure(fileName, err, rendered)
+}
+
+// add saves the file to the storage and adds it to the batch in progress
+func (p *pipeline) add(file *model.FileMetadata) {
+	if p.service.Hooks.FileParsed != nil {
+		p.service.Hooks.FileParsed(p.ctx, file)
+	}
+	if p.full && !p.together(file.FileName) {
+		p.send()
+	}
+	// the next documents of the file, or files rendered, are added to the same batch until the next flush
+	p.full = false
+	p.lastDir = filepath.Dir(file.FileName)
+	if p.resolving != "" {
+		p.resolved = p.resolving
+	}
+	saved := len(p.files)
+	p.files = p.service.saveToFile(p.ctx, file, p.files)
+	p.counters.ParsedFiles += len(p.files) - saved
+	p.report(func(progress *Progress) {
+		progress.DocumentsParsed++
+	})
+}
+
+// report updates the progress of the scan and sends it to the progress sink of the service, if any
+func (p *pipeline) report(update func(progress *Progress)) {
+	if p.service.ProgressSink == nil {
+		return
+	}
+	p.progressMutex.Lock()
+	defer p.progressMutex.Unlock()
+	update(&p.progress)
+	p.service.ProgressSink.Progress(p.ctx, p.scanID, p.progress)
+}
+
+// resolve marks the files added until the next flush as rendered from the directory, the next files under it are kept
+// in their batch
+func (p *pipeline) resolve(dir string) {
+	p.resolving = dir
+}
+
+// flush marks the batch in progress to be sent once it has the batch size, it is called once all the documents
+// of a file, or all the files rendered from a directory, were added, so they are inspected together
+func (p *pipeline) flush() {
+	p.resolving = ""
+	p.full = p.service.BatchSize > 0 && len(p.files) >= p.service.BatchSize
+}
+
+// together returns true if the file is in the directory of the last file added to the batch in progress,
+// or under the last directory resolved in the batch
+func (p *pipeline) together(fileName string) bool {
+	if filepath.Dir(fileName) == p.lastDir {
+		return true
+	}
+	if p.resolved == "" {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.FromSlash(p.resolved), filepath.FromSlash(fileName))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (p *pipeline) send() {
+	files := p.files
+	p.files = nil
+	p.full = false
+	p.resolved = ""
+	select {
+	case p.batches <- files:
+	case <-p.ctx.Done():
+	}
+}
+
+// close sends the last batch and waits for the inspection of all the batches, returning the result of the scan
+// or the first error of the inspection
+func (p *pipeline) close() (*ScanResult, error) {
+	if len(p.files) > 0 || p.service.BatchSize <= 0 {
+		p.send()
+	}
+	close(p.batches)
+	<-p.done
+	if p.err == nil && p.ctx.Err() != nil {
+		p.err = errors.Wrap(p.ctx.Err(), "scan canceled")
+	}
+	p.cancel()
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.result.finish(p.service.Inspector, p.counters)
+	return p.result, nil
+}
+
+func (p *pipeline) run() {
+	defer close(p.done)
+	for files := range p.batches {
+		p.inspectBatch(p.images.hold(files))
+	}
+	// the Dockerfiles are inspected once the images referenced by all the batches are known
+	p.inspectBatch(p.images.release())
+}
+
+func (p *pipeline) inspectBatch(files model.FileMetadatas) {
+	if len(files) == 0 || p.err != nil || p.ctx.Err() != nil {
+		return
+	}
+	if failFast, ok := p.service.Inspector.(engine.FailFastEngine); ok && failFast.FailedFast() {
+		// the inspection was stopped by a result of a previous batch
+		return
+	}
+	p.batch++
+	log.Debug().Msgf("Inspecting batch %d of %d files", p.batch, len(files))
+	start := time.Now()
+	vulnerabilities, err := p.service.inspect(p.ctx, p.scanID, files, p.hideProgress)
+	if err != nil {
+		p.err = err
+		p.cancel()
+		return
+	}
+	p.result.addVulnerabilities(vulnerabilities, time.Since(start))
+	p.report(func(progress *Progress) {
+		progress.DocumentsInspected += len(files)
+		progress.Vulnerabilities += len(vulnerabilities)
+	})
+}
+
+// inspect inspects a batch of files of the scan and saves its vulnerabilities, the results of the documents rendered
+// are only deduplicated with the results of the files of the same batch, it returns the vulnerabilities of the batch,
+// which are only saved by the services with storage
+func (s *Service) inspect(ctx context.Context, scanID string, files model.FileMetadatas,
+	hideProgress bool) ([]model.Vulnerability, error) {
+	vulnerabilities, err := s.Inspector.Inspect(ctx, scanID, files, hideProgress, s.SourceProvider.GetBasePath())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to inspect files")
+	}
+	vulnerabilities = deduplicateRendered(vulnerabilities, files)
+	vulnerabilities = excludeIgnoredQueries(vulnerabilities, s.SourceProvider)
+	suppressInline(vulnerabilities, files)
+
+	if s.Hooks.BatchInspected != nil {
+		s.Hooks.BatchInspected(ctx, files, vulnerabilities)
+	}
+	if s.Storage == nil {
+		return vulnerabilities, nil
+	}
+	if err := s.saveVulnerabilities(ctx, vulnerabilities); err != nil {
+		return nil, err
+	}
+	return vulnerabilities, nil
+}
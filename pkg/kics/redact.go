@@ -0,0 +1,75 @@
+package kics
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+)
+
+// lineHashLength is the length of the hashes of the lines and values of the files redacted in the storage
+const lineHashLength = 16
+
+// redactContent returns a copy of the file whose original data and rendered content have each line replaced by a
+// hash of its content, the lines are kept so the stored files can still be compared line by line with their source,
+// and whose document has each scalar value replaced by a hash of its value, the keys are kept, the copy is marked
+// as redacted so it is not inspected again
+func redactContent(file *model.FileMetadata) *model.FileMetadata {
+	redacted := *file
+	redacted.Redacted = true
+	redacted.OriginalData = hashLines(file.OriginalData)
+	redacted.Content = hashLines(file.Content)
+	if file.Document != nil {
+		redacted.Document = redactValue(map[string]interface{}(file.Document)).(map[string]interface{})
+	}
+	return &redacted
+}
+
+// redactValue returns a copy of the value of a document with each scalar replaced by a hash of its value, the values
+// of other types, such as the lines of the documents, are hashed as a whole
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case model.Document:
+		return redactValue(map[string]interface{}(v))
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			redacted[key] = redactValue(item)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = redactValue(item)
+		}
+		return redacted
+	default:
+		return hash(fmt.Sprint(v))
+	}
+}
+
+// hashLines replaces each line of the text by a hash of its content, empty lines are kept empty
+func hashLines(text string) string {
+	if text == "" {
+		return ""
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+			continue
+		}
+		lines[i] = hash(strings.TrimSuffix(line, "\r"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// hash returns the prefix of the SHA-256 hash of the text that replaces it in the files redacted
+func hash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return "sha256:" + hex.EncodeToString(sum[:])[:lineHashLength]
+}
@@ -0,0 +1,85 @@
+package kics
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRedactContent tests the functions [redactContent()] and all the methods called by them
+func TestRedactContent(t *testing.T) {
+	file := &model.FileMetadata{
+		ID:           "file",
+		FileName:     "main.tf",
+		OriginalData: "resource \"aws_db_instance\" \"db\" {\n  password = \"s3cr3t\"\n\n}\r\n",
+		Document: model.Document{
+			"id": "file",
+			"resource": map[string]interface{}{
+				"aws_db_instance": map[string]interface{}{
+					"db": map[string]interface{}{
+						"password":             "s3cr3t",
+						"port":                 5432,
+						"allowed_cidrs":        []interface{}{"10.0.0.0/8", nil},
+						model.DocumentLinesKey: map[string]int{"password": 2},
+					},
+				},
+			},
+		},
+	}
+
+	redacted := redactContent(file)
+	require.Equal(t, "main.tf", redacted.FileName)
+	require.True(t, redacted.Redacted)
+	require.False(t, file.Redacted)
+	db := getDB(redacted.Document)
+	require.Equal(t, []string{model.DocumentLinesKey, "allowed_cidrs", "password", "port"}, sortedKeys(db), "the keys are kept")
+	require.Nil(t, db["allowed_cidrs"].([]interface{})[1])
+	requireRedacted(t, map[string]interface{}(redacted.Document))
+	document, err := json.Marshal(redacted.Document)
+	require.NoError(t, err)
+	for _, raw := range []string{"s3cr3t", "5432", "10.0.0.0/8"} {
+		require.NotContains(t, string(document), raw)
+	}
+	require.Equal(t, "", redacted.Content)
+	require.NotContains(t, redacted.OriginalData, "s3cr3t")
+	require.Len(t, redacted.OriginalData, len("sha256:0123456789abcdef\n")*3+1, "the lines are kept, empty lines stay empty")
+	require.Equal(t, hashLines("}\r"), hashLines("}"))
+	require.Contains(t, file.OriginalData, "s3cr3t", "the file scanned is not changed")
+	require.Equal(t, "s3cr3t", getDB(file.Document)["password"])
+}
+
+func getDB(document model.Document) map[string]interface{} {
+	instances := document["resource"].(map[string]interface{})["aws_db_instance"].(map[string]interface{})
+	return instances["db"].(map[string]interface{})
+}
+
+// requireRedacted requires each scalar value of the document to be a hash
+func requireRedacted(t *testing.T, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+	case map[string]interface{}:
+		for _, item := range v {
+			requireRedacted(t, item)
+		}
+	case []interface{}:
+		for _, item := range v {
+			requireRedacted(t, item)
+		}
+	default:
+		require.IsType(t, "", v)
+		require.True(t, strings.HasPrefix(v.(string), "sha256:"), "raw value %v", v)
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
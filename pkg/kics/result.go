@@ -0,0 +1,102 @@
+package kics
+
+import (
+	"time"
+
+	"github.com/Checkmarx/kics/pkg/engine"
+	"github.com/Checkmarx/kics/pkg/model"
+)
+
+// ScanResult is everything a scan found, returned by StartScan and Reinspect so the services without storage,
+// or that don't read it back, get the results of a scan in one call
+// Vulnerabilities are the vulnerabilities of all the batches, including the ones suppressed by inline comments
+// Summary groups the vulnerabilities not suppressed by query and severity, with the suppressed ones, the files counted
+// and, in its scan quality, the files skipped because they failed to parse or render and the queries skipped
+// Queries are the statistics of the queries of the scan
+// Timings are the times the scan started and ended and how long the batches were inspected
+type ScanResult struct {
+	ScanID          string
+	Vulnerabilities []model.Vulnerability
+	Summary         model.Summary
+	Queries         QueryStats
+	Timings         ScanTimings
+}
+
+// QueryStats are the statistics of the queries of a scan, the queries that failed to execute with their error,
+// the queries skipped once the deadline of the policy engine was exceeded and the number of vulnerabilities
+// of each query, by query ID
+type QueryStats struct {
+	Failed          map[string]error
+	Skipped         []string
+	Vulnerabilities map[string]int
+}
+
+// ScanTimings are the times a scan started and ended and the time spent inspecting its batches, which are inspected
+// while the next files are parsed
+type ScanTimings struct {
+	Start      time.Time
+	End        time.Time
+	Inspection time.Duration
+}
+
+// Duration returns how long the scan took
+func (t ScanTimings) Duration() time.Duration {
+	return t.End.Sub(t.Start)
+}
+
+// newScanResult starts the result of a scan
+func newScanResult(scanID string) *ScanResult {
+	return &ScanResult{
+		ScanID:          scanID,
+		Vulnerabilities: make([]model.Vulnerability, 0),
+		Queries: QueryStats{
+			Vulnerabilities: make(map[string]int),
+		},
+		Timings: ScanTimings{
+			Start: time.Now(),
+		},
+	}
+}
+
+// addVulnerabilities adds the vulnerabilities of a batch inspected to the result
+func (r *ScanResult) addVulnerabilities(vulnerabilities []model.Vulnerability, elapsed time.Duration) {
+	r.Vulnerabilities = append(r.Vulnerabilities, vulnerabilities...)
+	for i := range vulnerabilities {
+		r.Queries.Vulnerabilities[vulnerabilities[i].QueryID]++
+	}
+	r.Timings.Inspection += elapsed
+}
+
+// addFileFailure adds to the scan quality of the result a file that failed to parse, or to render when rendered is set
+func (r *ScanResult) addFileFailure(fileName string, err error, rendered bool) {
+	if rendered {
+		r.Summary.ScanQuality.UnrenderedFiles = append(r.Summary.ScanQuality.UnrenderedFiles, model.FileFailure{
+			FileName: fileName,
+			Code:     model.ReasonCodeOf(err, model.ReasonRenderError),
+			Reason:   err.Error(),
+		})
+		return
+	}
+	r.Summary.ScanQuality.UnparsedFiles = append(r.Summary.ScanQuality.UnparsedFiles, model.FileFailure{
+		FileName: fileName,
+		Code:     model.ReasonCodeOf(err, model.ReasonParseError),
+		Reason:   err.Error(),
+	})
+}
+
+// finish summarizes the result once all the batches were inspected, with the counters of the files of the scan
+func (r *ScanResult) finish(policyEngine engine.PolicyEngine, counters model.Counters) {
+	r.Timings.End = time.Now()
+	r.Queries.Failed = policyEngine.GetFailedQueries()
+	if deadlineEngine, ok := policyEngine.(engine.DeadlineEngine); ok {
+		r.Queries.Skipped = deadlineEngine.GetSkippedQueries()
+	}
+
+	quality := r.Summary.ScanQuality
+	quality.SkippedQueries = r.Queries.Skipped
+	counters.FailedToExecuteQueries = len(r.Queries.Failed)
+	active, suppressed := model.SplitSuppressed(r.Vulnerabilities)
+	r.Summary = model.CreateSummary(counters, active, r.ScanID)
+	r.Summary.Suppressed = model.NewSuppressedQueries(suppressed)
+	r.Summary.ScanQuality = quality
+}
@@ -0,0 +1,50 @@
+package kics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/internal/tracker"
+	"github.com/stretchr/testify/require"
+)
+
+// TestService_StartScanResult tests the functions [StartScan()] without storage and all the methods called by them
+func TestService_StartScanResult(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("kind: Pod\n---\nkind: Service\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.yaml"), []byte("kind: [\n"), 0600))
+	mockParser, mockFilesSource := createParserSourceProvider(dir)
+	tr := &tracker.CITracker{}
+	s, err := NewService(
+		WithSourceProvider(mockFilesSource),
+		WithParser(mockParser),
+		WithPolicyEngine(&fakePolicyEngine{}),
+		WithTracker(tr),
+		WithBatchSize(1),
+	)
+	require.NoError(t, err)
+
+	result, err := s.StartScan(context.Background(), "scanID", true)
+	require.NoError(t, err)
+	require.Equal(t, "scanID", result.ScanID)
+	require.Len(t, result.Vulnerabilities, 2)
+	require.Equal(t, 2, result.Queries.Vulnerabilities[""])
+	require.Empty(t, result.Queries.Failed)
+
+	require.Equal(t, "scanID", result.Summary.ScanID)
+	require.Equal(t, tr.FoundFiles, result.Summary.ScannedFiles)
+	require.Equal(t, 2, result.Summary.ParsedFiles)
+	require.Equal(t, tr.ParsedFiles, result.Summary.ParsedFiles)
+	require.Equal(t, 2, result.Summary.TotalCounter)
+	require.Len(t, result.Summary.ScanQuality.UnparsedFiles, 1)
+	require.Equal(t, filepath.Join(dir, "b.yaml"), result.Summary.ScanQuality.UnparsedFiles[0].FileName)
+	require.Equal(t, tr.UnparsedFiles, result.Summary.ScanQuality.UnparsedFiles)
+	require.False(t, result.Timings.End.Before(result.Timings.Start))
+	require.True(t, result.Timings.Inspection <= result.Timings.Duration())
+
+	// the services without storage do not keep the results of their scans
+	_, err = s.GetVulnerabilities(context.Background(), "scanID")
+	require.Error(t, err)
+}
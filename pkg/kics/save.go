@@ -0,0 +1,67 @@
+package kics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultSaveChunkSize is the number of vulnerabilities saved together by the services created with NewService
+// without a save policy set
+const DefaultSaveChunkSize = 1000
+
+// SavePolicy is how the vulnerabilities of the scans are saved to the storage, in chunks of ChunkSize vulnerabilities,
+// or all together when 0, each chunk is saved again up to Retries times when the storage fails, waiting Backoff
+// before the first retry and twice as long before each next one, the scan waits for each chunk to be saved
+// before inspecting the next files, so slow storages slow the scan down instead of piling up vulnerabilities
+type SavePolicy struct {
+	ChunkSize int
+	Retries   int
+	Backoff   time.Duration
+}
+
+// saveVulnerabilities saves the vulnerabilities to the storage of the service in chunks, following its save policy,
+// it stops at the first chunk that can't be saved or when the context is done
+func (s *Service) saveVulnerabilities(ctx context.Context, vulnerabilities []model.Vulnerability) error {
+	chunkSize := s.SavePolicy.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = len(vulnerabilities)
+	}
+	for start := 0; start < len(vulnerabilities); start += chunkSize {
+		end := start + chunkSize
+		if end > len(vulnerabilities) {
+			end = len(vulnerabilities)
+		}
+		if err := s.saveChunk(ctx, vulnerabilities[start:end:end]); err != nil {
+			return errors.Wrapf(err, "failed to save vulnerabilities %d to %d of %d", start+1, end, len(vulnerabilities))
+		}
+	}
+	return nil
+}
+
+// saveChunk saves a chunk of vulnerabilities to the storage of the service, retrying with the backoff of the save
+// policy while the storage fails and the context is not done
+func (s *Service) saveChunk(ctx context.Context, chunk []model.Vulnerability) error {
+	backoff := s.SavePolicy.Backoff
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := s.Storage.SaveVulnerabilities(ctx, chunk)
+		if err == nil || attempt == s.SavePolicy.Retries {
+			return err
+		}
+		log.Warn().Err(err).Msgf("Failed to save %d vulnerabilities, retrying in %v", len(chunk), backoff)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,87 @@
+package kics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	memoryStorage "github.com/Checkmarx/kics/pkg/storage"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyStorage is a memory storage whose saves of vulnerabilities fail the first failures times
+type flakyStorage struct {
+	*memoryStorage.MemoryStorage
+	failures int
+	chunks   []int
+}
+
+func (f *flakyStorage) SaveVulnerabilities(ctx context.Context, vulnerabilities []model.Vulnerability) error {
+	f.chunks = append(f.chunks, len(vulnerabilities))
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("transaction too large")
+	}
+	return f.MemoryStorage.SaveVulnerabilities(ctx, vulnerabilities)
+}
+
+// TestService_saveVulnerabilities tests the functions [saveVulnerabilities()] with the chunks and retries of the save policy
+func TestService_saveVulnerabilities(t *testing.T) {
+	vulnerabilities := make([]model.Vulnerability, 5)
+	tests := []struct {
+		name       string
+		policy     SavePolicy
+		failures   int
+		wantChunks []int
+		wantSaved  int
+		wantErr    bool
+	}{
+		{
+			name:       "all_together",
+			policy:     SavePolicy{},
+			wantChunks: []int{5},
+			wantSaved:  5,
+		},
+		{
+			name:       "chunks",
+			policy:     SavePolicy{ChunkSize: 2},
+			wantChunks: []int{2, 2, 1},
+			wantSaved:  5,
+		},
+		{
+			name:       "retries",
+			policy:     SavePolicy{ChunkSize: 2, Retries: 2, Backoff: time.Millisecond},
+			failures:   2,
+			wantChunks: []int{2, 2, 2, 2, 1},
+			wantSaved:  5,
+		},
+		{
+			name:       "retries_exhausted",
+			policy:     SavePolicy{ChunkSize: 2, Retries: 1, Backoff: time.Millisecond},
+			failures:   2,
+			wantChunks: []int{2, 2},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &flakyStorage{MemoryStorage: memoryStorage.NewMemoryStorage(), failures: tt.failures}
+			s := &Service{Storage: store, SavePolicy: tt.policy}
+			err := s.saveVulnerabilities(context.Background(), vulnerabilities)
+			require.Equal(t, tt.wantErr, err != nil)
+			require.Equal(t, tt.wantChunks, store.chunks)
+			require.Len(t, store.Snapshot().Vulnerabilities, tt.wantSaved)
+		})
+	}
+
+	// the retries stop once the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	store := &flakyStorage{MemoryStorage: memoryStorage.NewMemoryStorage(), failures: 1}
+	s := &Service{Storage: store, SavePolicy: SavePolicy{ChunkSize: 2, Retries: 3, Backoff: time.Hour}}
+	time.AfterFunc(10*time.Millisecond, cancel)
+	err := s.saveVulnerabilities(ctx, vulnerabilities)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, []int{2}, store.chunks)
+}
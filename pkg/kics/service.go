@@ -4,13 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"sync"
+	"time"
 
 	"github.com/Checkmarx/kics/pkg/engine"
 	"github.com/Checkmarx/kics/pkg/engine/provider"
 	"github.com/Checkmarx/kics/pkg/model"
 	"github.com/Checkmarx/kics/pkg/parser"
 	"github.com/Checkmarx/kics/pkg/resolver"
-	"github.com/getsentry/sentry-go"
+	"github.com/Checkmarx/kics/pkg/watchdog"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -18,7 +20,8 @@ import (
 
 // Storage is the interface that wraps following basic methods: SaveFile, SaveVulnerability, GetVulnerability and GetScanSummary
 // SaveFile should append metadata to a file
-// SaveVulnerabilities should append vulnerabilities list to current storage
+// SaveVulnerabilities should append vulnerabilities list to current storage, it is called with chunks of the vulnerabilities
+// of each batch, again when it fails if the save policy of the service retries, so it should save each chunk atomically
 // GetVulnerabilities should returns all vulnerabilities associated to a scan ID
 // GetScanSummary should return a list of summaries based on their scan IDs
 type Storage interface {
@@ -28,50 +31,151 @@ type Storage interface {
 	GetScanSummary(ctx context.Context, scanIDs []string) ([]model.SeveritySummary, error)
 }
 
-// Tracker is the interface that wraps the basic methods: TrackFileFound and TrackFileParse
+// ScanHistory is the interface implemented by storages that keep the scans of previous runs grouped by project,
+// such as a branch of a repository, it wraps the methods SaveScan, GetLastScan and GetScans
+// SaveScan should keep the scan of a project along with the vulnerabilities saved with its ID
+// GetLastScan should return the most recent successful scan of a project, nil if there is none
+// GetScans should return the scans of a project kept, from the oldest to the most recent
+type ScanHistory interface {
+	SaveScan(ctx context.Context, scan model.ScanRecord) error
+	GetLastScan(ctx context.Context, projectID string) (*model.ScanRecord, error)
+	GetScans(ctx context.Context, projectID string) ([]model.ScanRecord, error)
+}
+
+// FindingHistory is the interface implemented by storages that track when the results of each project were seen,
+// it wraps the methods GetFindingsSeen and SaveFindingsSeen
+// GetFindingsSeen should return when the results of a project were first and last seen, by similarity ID
+// SaveFindingsSeen should replace the results seen of a project
+type FindingHistory interface {
+	GetFindingsSeen(ctx context.Context, projectID string) (map[string]model.FindingSeen, error)
+	SaveFindingsSeen(ctx context.Context, projectID string, seen map[string]model.FindingSeen) error
+}
+
+// TriageStore is the interface implemented by storages that keep the triage of the results of each project,
+// it wraps the methods GetTriages and SaveTriage
+// GetTriages should return the triage of the results of a project, by similarity ID
+// SaveTriage should replace the triage of a result of a project
+type TriageStore interface {
+	GetTriages(ctx context.Context, projectID string) (map[string]model.Triage, error)
+	SaveTriage(ctx context.Context, projectID, similarityID string, triage model.Triage) error
+}
+
+// AuditStore is the interface implemented by storages that keep how each scan was executed, it wraps the methods
+// SaveAudit and GetAudit
+// SaveAudit should keep the audit of a scan along with the scan
+// GetAudit should return the audit of a scan, nil if there is none
+type AuditStore interface {
+	SaveAudit(ctx context.Context, audit *model.ScanAudit) error
+	GetAudit(ctx context.Context, scanID string) (*model.ScanAudit, error)
+}
+
+// FileStore is the interface implemented by storages that keep the parsed files of the scans, it wraps the method GetFiles
+// GetFiles should return the files saved with a scan ID
+type FileStore interface {
+	GetFiles(ctx context.Context, scanID string) (model.FileMetadatas, error)
+}
+
+// Tracker is the interface that wraps the basic methods: TrackFileFound, TrackFileParse, TrackFileParseFailure
+// and TrackFileRenderFailure
 // TrackFileFound should increment the number of files to be scanned
 // TrackFileParse should increment the number of files parsed successfully to be scanned
+// TrackFileParseFailure should record a file that failed to parse and the error
+// TrackFileRenderFailure should record a file or directory that failed to render and the error
 type Tracker interface {
 	TrackFileFound()
 	TrackFileParse()
+	TrackFileParseFailure(fileName string, err error)
+	TrackFileRenderFailure(fileName string, err error)
+}
+
+// Enricher is the interface that wraps the basic method Enrich, which adds to the parsed documents data
+// from external sources, such as registries, for the queries to use
+type Enricher interface {
+	Enrich(ctx context.Context, kind model.FileKind, document model.Document)
 }
 
+// errNoStorage is the error of the methods reading the storage of the services without storage
+var errNoStorage = errors.New("the service has no storage, the results of the scans are returned by StartScan")
+
 // Service is a struct that contains a SourceProvider to receive sources, a storage to save and retrieve scanning informations
 // a parser to parse and provide files in format that KICS understand, a inspector that runs the scanning and a tracker to
-// update scanning numbers
+// update scanning numbers, services should be created with NewService, which validates their options
 type Service struct {
 	SourceProvider provider.SourceProvider
-	Storage        Storage
-	Parser         *parser.Parser
-	Inspector      *engine.Inspector
-	Tracker        Tracker
-	Resolver       *resolver.Resolver
+	// Storage, if any, keeps the files and vulnerabilities of the scans, the services without storage only return them
+	Storage   Storage
+	Parser    *parser.Parser
+	Inspector engine.PolicyEngine
+	Tracker   Tracker
+	Resolver  *resolver.Resolver
+	Enrichers []Enricher
+	// Watchdog, if any, tracks the files in progress
+	Watchdog *watchdog.Watchdog
+	// RedactContent saves the files to the storage with each line of their content hashed, for storages that must not
+	// keep it, the lines of the results are still detected since the files are inspected as read
+	RedactContent bool
+	// ProjectID, if any, groups the scans of the same project, such as a branch of a repository, in the storages keeping
+	// the history of the scans
+	ProjectID string
+	// BatchSize, if any, is the minimum number of files inspected together while the next ones are parsed, so big scans
+	// don't keep all their parsed files in memory, the batches are cut between directories and the Dockerfiles are
+	// inspected last with the images of all the batches, other queries spanning several directories only see the files
+	// of the same batch
+	BatchSize int
+	// SavePolicy is how the vulnerabilities are saved to the storage, in chunks retried when the storage fails
+	SavePolicy SavePolicy
+	// MaxFileSize is the size limit of the files scanned
+	MaxFileSize int64
+	// Hooks, ErrorReporter and ProgressSink, if any, are notified as the scans progress
+	Hooks         Hooks
+	ErrorReporter ErrorReporter
+	ProgressSink  ProgressSink
+	scanFileMutex sync.Mutex
+	// parallelism is set by WithParallelism and applied to the policy engine by NewService
+	parallelism *int
 }
 
-// StartScan executes scan over the context, using the scanID as reference
-func (s *Service) StartScan(ctx context.Context, scanID string, hideProgress bool) error {
+// StartScan executes scan over the context, using the scanID as reference, the files parsed are inspected in batches
+// of at least BatchSize files, cut between directories, while the next ones are parsed, or all together when BatchSize
+// is 0, it returns the result of the scan, the files and the vulnerabilities are also saved to the storage
+// of the service, if any
+func (s *Service) StartScan(ctx context.Context, scanID string, hideProgress bool) (*ScanResult, error) {
 	log.Debug().Msg("service.StartScan()")
-	var files model.FileMetadatas
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	s.Watchdog.Start()
+	defer s.Watchdog.Stop()
+	p := s.startPipeline(ctx, scanID, hideProgress)
 	if err := s.SourceProvider.GetSources(
 		ctx,
 		s.Parser.SupportedExtensions(),
 		func(ctx context.Context, filename string, rc io.ReadCloser) error {
-			s.Tracker.TrackFileFound()
+			if p.stopped() {
+				return nil
+			}
+			p.found()
+			ctx, unit := s.Watchdog.Begin(ctx, "file", filename)
+			defer s.Watchdog.End(unit)
 
-			content, err := getContent(rc)
+			content, err := getContent(rc, s.getMaxFileSize())
 			if err != nil {
+				p.failed(filename, err, false)
 				return errors.Wrapf(err, "failed to get file content: %s", filename)
 			}
 
 			documents, kind, err := s.Parser.Parse(filename, *content)
 			if err != nil {
+				p.failed(filename, err, false)
 				return errors.Wrap(err, "failed to parse file content")
 			}
 			for _, document := range documents {
+				s.enrich(ctx, kind, document)
 				_, err = json.Marshal(document)
 				if err != nil {
-					sentry.CaptureException(err)
+					s.reportError(err)
 					log.Err(err).Msgf("failed to marshal content in file: %s", filename)
+					p.failed(filename, model.NewFileError(model.ReasonMarshalError, err), false)
 					continue
 				}
 
@@ -83,31 +187,44 @@ func (s *Service) StartScan(ctx context.Context, scanID string, hideProgress boo
 					Kind:         kind,
 					FileName:     filename,
 				}
-				files = s.saveToFile(ctx, &file, files)
+				p.add(&file)
 			}
+			p.flush()
 
 			return errors.Wrap(err, "failed to save file content")
 		},
 		func(ctx context.Context, filename string) error { // Sink used for resolver files and templates
-			s.Tracker.TrackFileFound()
+			if p.stopped() {
+				return nil
+			}
+			p.found()
 			kind := s.Resolver.GetType(filename)
 			if kind == model.KindCOMMON {
 				return nil
 			}
+			ctx, unit := s.Watchdog.Begin(ctx, "render", filename)
+			defer s.Watchdog.End(unit)
 			resFiles, err := s.Resolver.Resolve(filename, kind)
 			if err != nil {
+				p.failed(filename, err, true)
 				return errors.Wrap(err, "failed to render file content")
 			}
+			// the files rendered are inspected in the same batch, even if some fail to parse, with the files under filename
+			p.resolve(filename)
+			defer p.flush()
 			for _, rfile := range resFiles.File {
 				documents, _, err := s.Parser.Parse(rfile.FileName, rfile.Content)
 				if err != nil {
+					p.failed(rfile.FileName, err, false)
 					return errors.Wrap(err, "failed to parse file content")
 				}
 				for _, document := range documents {
+					s.enrich(ctx, kind, document)
 					_, err = json.Marshal(document)
 					if err != nil {
-						sentry.CaptureException(err)
+						s.reportError(err)
 						log.Err(err).Msgf("failed to marshal content in file: %s", rfile.FileName)
+						p.failed(rfile.FileName, model.NewFileError(model.ReasonMarshalError, err), false)
 						continue
 					}
 
@@ -119,66 +236,206 @@ func (s *Service) StartScan(ctx context.Context, scanID string, hideProgress boo
 						Kind:         kind,
 						FileName:     rfile.FileName,
 						Content:      string(rfile.Content),
-						HelmID:       rfile.SplitID,
-						IDInfo:       rfile.IDInfo,
+						Origin:       rfile.Origin,
 					}
-					files = s.saveToFile(ctx, &file, files)
+					p.add(&file)
 				}
 			}
 			return nil
 		},
 	); err != nil {
-		return errors.Wrap(err, "failed to read sources")
+		p.cancel()
+		_, _ = p.close()
+		return nil, errors.Wrap(err, "failed to read sources")
+	}
+
+	return p.close()
+}
+
+// Reinspect inspects again the parsed files of a past scan kept by the storage, using the scanID as reference of the new
+// scan, so the results of updated queries can be assessed on past scans without reading their sources again, the files
+// are saved to the storage with the new scan, the parse quality checks of the files are not evaluated again,
+// the files saved redacted can't be inspected again, it returns the result of the new scan
+func (s *Service) Reinspect(ctx context.Context, storedScanID, scanID string, hideProgress bool) (*ScanResult, error) {
+	log.Debug().Msg("service.Reinspect()")
+	store, ok := s.Storage.(FileStore)
+	if !ok {
+		return nil, errors.New("the storage does not keep the files of the scans")
+	}
+	stored, err := store.GetFiles(ctx, storedScanID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the files of scan %s", storedScanID)
+	}
+	if len(stored) == 0 {
+		return nil, errors.Errorf("no files kept of scan %s", storedScanID)
+	}
+	for i := range stored {
+		if stored[i].Redacted {
+			return nil, errors.Errorf("the files of scan %s were kept redacted, they can't be inspected again", storedScanID)
+		}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	p := s.startPipeline(ctx, scanID, hideProgress)
+	for i := range stored {
+		if p.stopped() {
+			break
+		}
+		file := stored[i]
+		if i == 0 || stored[i-1].FileName != file.FileName {
+			p.found()
+		}
+		file.ID = uuid.New().String()
+		file.ScanID = scanID
+		p.add(&file)
+		// the documents of a file are inspected in the same batch
+		if i == len(stored)-1 || stored[i+1].FileName != file.FileName {
+			p.flush()
+		}
 	}
+	return p.close()
+}
 
-	vulnerabilities, err := s.Inspector.Inspect(ctx, scanID, files, hideProgress, s.SourceProvider.GetBasePath())
+// ScanFile scans the content of a single file, such as an editor buffer, and returns its vulnerabilities directly,
+// the file and the vulnerabilities are not saved to the storage and the source provider is only used for the base path,
+// files that can only be scanned once resolved with other files (ex: Helm templates) are not supported
+func (s *Service) ScanFile(ctx context.Context, filename string, content []byte) ([]model.Vulnerability, error) {
+	log.Debug().Msgf("service.ScanFile(%s)", filename)
+	documents, kind, err := s.Parser.Parse(filename, content)
 	if err != nil {
-		return errors.Wrap(err, "failed to inspect files")
+		return nil, errors.Wrap(err, "failed to parse file content")
 	}
 
-	err = s.Storage.SaveVulnerabilities(ctx, vulnerabilities)
+	scanID := uuid.New().String()
+	files := make(model.FileMetadatas, 0, len(documents))
+	for _, document := range documents {
+		s.enrich(ctx, kind, document)
+		files = append(files, model.FileMetadata{
+			ID:           uuid.New().String(),
+			ScanID:       scanID,
+			Document:     document,
+			OriginalData: string(content),
+			Kind:         kind,
+			FileName:     filename,
+		})
+	}
 
-	return errors.Wrap(err, "failed to save vulnerabilities")
+	basePath := ""
+	if s.SourceProvider != nil {
+		basePath = s.SourceProvider.GetBasePath()
+	}
+	// the policy engines keep state of each evaluation, such as the failed queries
+	s.scanFileMutex.Lock()
+	defer s.scanFileMutex.Unlock()
+	vulnerabilities, err := s.Inspector.Inspect(ctx, scanID, files, true, basePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to inspect file")
+	}
+	suppressInline(vulnerabilities, files)
+	return vulnerabilities, nil
 }
 
 /*
    getContent will read the passed file 1MB at a time
-   to prevent resource exhaustion and return its content
+   to prevent resource exhaustion and return its content,
+   files larger than maxSize bytes fail with FILE_TOO_LARGE
 */
-func getContent(rc io.Reader) (*[]byte, error) {
-	maxSizeMB := 5 // Max size of file in MBs
+func getContent(rc io.Reader, maxSize int64) (*[]byte, error) {
 	var content []byte
 	data := make([]byte, 1048576)
 	for {
-		if maxSizeMB < 0 {
-			return &[]byte{}, errors.New("file size limit exceeded")
-		}
 		data = data[:cap(data)]
 		n, err := rc.Read(data)
+		content = append(content, data[:n]...)
+		if int64(len(content)) > maxSize {
+			return &[]byte{}, model.NewFileError(model.ReasonFileTooLarge, errors.New("file size limit exceeded"))
+		}
 		if err != nil {
 			if err == io.EOF {
 				break
 			}
 			return &[]byte{}, err
 		}
-		content = append(content, data[:n]...)
-		maxSizeMB--
 	}
 	return &content, nil
 }
 
 // GetVulnerabilities returns a list of scan detected vulnerabilities
 func (s *Service) GetVulnerabilities(ctx context.Context, scanID string) ([]model.Vulnerability, error) {
+	if s.Storage == nil {
+		return nil, errNoStorage
+	}
 	return s.Storage.GetVulnerabilities(ctx, scanID)
 }
 
 // GetScanSummary returns how many vulnerabilities of each severity was found
 func (s *Service) GetScanSummary(ctx context.Context, scanIDs []string) ([]model.SeveritySummary, error) {
+	if s.Storage == nil {
+		return nil, errNoStorage
+	}
 	return s.Storage.GetScanSummary(ctx, scanIDs)
 }
 
+// GetScanSeverityBreakdown returns the summaries of the scans with their severity counters broken down by platform,
+// top-level directory, category and project as selected by breakdown, from the vulnerabilities of each scan
+func (s *Service) GetScanSeverityBreakdown(ctx context.Context, scanIDs []string,
+	breakdown model.SeverityBreakdown) ([]model.SeveritySummary, error) {
+	summaries, err := s.GetScanSummary(ctx, scanIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range summaries {
+		vulnerabilities, err := s.Storage.GetVulnerabilities(ctx, summaries[i].ScanID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the vulnerabilities of scan %s", summaries[i].ScanID)
+		}
+		summaries[i].AddBreakdown(vulnerabilities, breakdown)
+	}
+	return summaries, nil
+}
+
+// SaveScan keeps the scan in the history of the project of the service, when the storage keeps the history of the scans,
+// scans that fail or are incomplete should not be successful, so the next scans are compared with the last one that passed
+func (s *Service) SaveScan(ctx context.Context, scanID string, success bool) error {
+	history, ok := s.Storage.(ScanHistory)
+	if !ok {
+		return nil
+	}
+	return history.SaveScan(ctx, model.ScanRecord{
+		ID:        scanID,
+		ProjectID: s.ProjectID,
+		Time:      time.Now(),
+		Success:   success,
+	})
+}
+
+// GetScans returns the scans of the project of the service kept by the storage, from the oldest to the most recent,
+// nil when the storage does not keep the history of the scans
+func (s *Service) GetScans(ctx context.Context) ([]model.ScanRecord, error) {
+	history, ok := s.Storage.(ScanHistory)
+	if !ok {
+		return nil, nil
+	}
+	return history.GetScans(ctx, s.ProjectID)
+}
+
+func (s *Service) enrich(ctx context.Context, kind model.FileKind, document model.Document) {
+	for _, enricher := range s.Enrichers {
+		enricher.Enrich(ctx, kind, document)
+	}
+}
+
 func (s *Service) saveToFile(ctx context.Context, file *model.FileMetadata, files model.FileMetadatas) model.FileMetadatas {
-	err := s.Storage.SaveFile(ctx, file)
+	if s.Storage == nil {
+		s.Tracker.TrackFileParse()
+		return append(files, *file)
+	}
+	stored := file
+	if s.RedactContent {
+		stored = redactContent(file)
+	}
+	err := s.Storage.SaveFile(ctx, stored)
 	if err == nil {
 		files = append(files, *file)
 		s.Tracker.TrackFileParse()
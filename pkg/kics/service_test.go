@@ -3,6 +3,8 @@ package kics
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -16,6 +18,7 @@ import (
 	jsonParser "github.com/Checkmarx/kics/pkg/parser/json"
 	terraformParser "github.com/Checkmarx/kics/pkg/parser/terraform"
 	yamlParser "github.com/Checkmarx/kics/pkg/parser/yaml"
+	memoryStorage "github.com/Checkmarx/kics/pkg/storage"
 )
 
 // TestService tests the functions [GetVulnerabilities(), GetScanSummary(),StartScan()] and all the methods called by them
@@ -51,7 +54,7 @@ func TestService(t *testing.T) {
 				Inspector:      &engine.Inspector{},
 				Parser:         mockParser,
 				Tracker:        &tracker.CITracker{},
-				Storage:        storage.NewMemoryStorage(),
+				Storage:        memoryStorage.NewMemoryStorage(),
 				SourceProvider: mockFilesSource,
 			},
 			args: args{
@@ -95,7 +98,7 @@ func TestService(t *testing.T) {
 			}
 		})
 		t.Run(fmt.Sprintf(tt.name+"_start_scan"), func(t *testing.T) {
-			if err := s.StartScan(tt.args.ctx, tt.args.scanID, true); (err != nil) != tt.wantErr {
+			if _, err := s.StartScan(tt.args.ctx, tt.args.scanID, true); (err != nil) != tt.wantErr {
 				t.Errorf("Service.StartScan() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -114,3 +117,264 @@ func createParserSourceProvider(path string) (*parser.Parser, *provider.FileSyst
 
 	return mockParser, mockFilesSource
 }
+
+type fakePolicyEngine struct {
+	files   model.FileMetadatas
+	batches []int
+}
+
+func (f *fakePolicyEngine) Inspect(ctx context.Context, scanID string, files model.FileMetadatas,
+	hideProgress bool, baseScanPath string) ([]model.Vulnerability, error) {
+	f.files = files
+	f.batches = append(f.batches, len(files))
+	vulnerabilities := make([]model.Vulnerability, 0, len(files))
+	for i := range files {
+		vulnerabilities = append(vulnerabilities, model.Vulnerability{ScanID: scanID, FileID: files[i].ID, FileName: files[i].FileName})
+	}
+	return vulnerabilities, nil
+}
+
+func (f *fakePolicyEngine) GetFailedQueries() map[string]error {
+	return map[string]error{}
+}
+
+// TestService_GetScanSeverityBreakdown tests the functions [GetScanSeverityBreakdown()] and all the methods called by them
+func TestService_GetScanSeverityBreakdown(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStorage()
+	if err := store.SaveVulnerabilities(ctx, []model.Vulnerability{
+		{ScanID: "scanID", FileName: "main.tf", Platform: "Terraform", Severity: model.SeverityHigh},
+		{ScanID: "otherScanID", FileName: "pod.yaml", Platform: "Kubernetes", Severity: model.SeverityLow},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	s := &Service{Storage: store}
+	got, err := s.GetScanSeverityBreakdown(ctx, []string{"scanID"}, model.SeverityBreakdown{ByPlatform: true})
+	if err != nil {
+		t.Fatalf("Service.GetScanSeverityBreakdown() error = %v", err)
+	}
+	want := map[string]map[model.Severity]int{
+		"Terraform": {model.SeverityHigh: 1, model.SeverityMedium: 0, model.SeverityLow: 0, model.SeverityInfo: 0},
+	}
+	if len(got) != 1 || !reflect.DeepEqual(got[0].SeverityCountersByPlatform, want) {
+		t.Errorf("Service.GetScanSeverityBreakdown() = %v, want the platforms of the scan", got)
+	}
+}
+
+// TestService_SaveScan tests the functions [SaveScan(), GetScans()] and all the methods called by them
+func TestService_SaveScan(t *testing.T) {
+	ctx := context.Background()
+	s := &Service{Storage: storage.NewFileStorage(t.TempDir()), ProjectID: "github.com/Checkmarx/kics@master"}
+	if err := s.SaveScan(ctx, "first", true); err != nil {
+		t.Fatalf("Service.SaveScan() error = %v", err)
+	}
+	other := &Service{Storage: s.Storage, ProjectID: "github.com/Checkmarx/kics@feature"}
+	if err := other.SaveScan(ctx, "other", false); err != nil {
+		t.Fatalf("Service.SaveScan() error = %v", err)
+	}
+	scans, err := s.GetScans(ctx)
+	if err != nil {
+		t.Fatalf("Service.GetScans() error = %v", err)
+	}
+	if len(scans) != 1 || scans[0].ID != "first" || !scans[0].Success || scans[0].ProjectID != s.ProjectID {
+		t.Errorf("Service.GetScans() = %v, want the scans of the project", scans)
+	}
+
+	// storages without history of the scans don't keep them
+	s = &Service{Storage: memoryStorage.NewMemoryStorage(), ProjectID: "project"}
+	if err := s.SaveScan(ctx, "first", true); err != nil {
+		t.Fatalf("Service.SaveScan() error = %v", err)
+	}
+	if scans, err := s.GetScans(ctx); err != nil || scans != nil {
+		t.Errorf("Service.GetScans() = %v, %v, want no scans", scans, err)
+	}
+}
+
+// TestService_StartScanBatches tests the functions [StartScan()] with a batch size and all the methods called by them
+func TestService_StartScanBatches(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a/a.yaml", "b/b.yaml", "b/c.yaml", "d/d.yaml"} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte("kind: Pod\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mockParser, mockFilesSource := createParserSourceProvider(dir)
+	tests := []struct {
+		name      string
+		batchSize int
+		want      []int
+	}{
+		{name: "all_files", batchSize: 0, want: []int{4}},
+		{name: "batches", batchSize: 2, want: []int{3, 1}},
+		{name: "batch_per_directory", batchSize: 1, want: []int{1, 2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policyEngine := &fakePolicyEngine{}
+			store := memoryStorage.NewMemoryStorage()
+			s := &Service{
+				SourceProvider: mockFilesSource,
+				Storage:        store,
+				Parser:         mockParser,
+				Inspector:      policyEngine,
+				Tracker:        &tracker.CITracker{},
+				BatchSize:      tt.batchSize,
+			}
+			if _, err := s.StartScan(context.Background(), "scanID", true); err != nil {
+				t.Fatalf("Service.StartScan() error = %v", err)
+			}
+			if !reflect.DeepEqual(policyEngine.batches, tt.want) {
+				t.Errorf("Service.StartScan() batches = %v, want %v", policyEngine.batches, tt.want)
+			}
+			vulnerabilities, err := store.GetVulnerabilities(context.Background(), "scanID")
+			if err != nil || len(vulnerabilities) != 4 {
+				t.Errorf("Service.StartScan() vulnerabilities = %v, %v, want the vulnerabilities of all the batches", vulnerabilities, err)
+			}
+		})
+	}
+}
+
+// TestService_StartScanBatchesImages tests the functions [StartScan()] with a Dockerfile and the manifest of its image
+// in different batches
+func TestService_StartScanBatchesImages(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"api/Dockerfile":  "FROM alpine:3.14\n",
+		"deploy/pod.yaml": "kind: Pod\nspec:\n  containers:\n    - name: api\n      image: registry.io/org/api:1.0\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mockParser, mockFilesSource := createParserSourceProvider(dir)
+	policyEngine := &fakePolicyEngine{}
+	s := &Service{
+		SourceProvider: mockFilesSource,
+		Parser:         mockParser,
+		Inspector:      policyEngine,
+		Tracker:        &tracker.CITracker{},
+		BatchSize:      1,
+	}
+	result, err := s.StartScan(context.Background(), "scanID", true)
+	if err != nil {
+		t.Fatalf("Service.StartScan() error = %v", err)
+	}
+	// the Dockerfile, found first, is inspected last with the image of the manifest
+	if !reflect.DeepEqual(policyEngine.batches, []int{1, 1}) || policyEngine.files[0].Kind != model.KindDOCKER {
+		t.Fatalf("Service.StartScan() batches = %v, want the Dockerfile in the last batch", policyEngine.batches)
+	}
+	if images := policyEngine.files[0].Document["images"]; !reflect.DeepEqual(images, []string{"registry.io/org/api:1.0"}) {
+		t.Errorf("Service.StartScan() Dockerfile images = %v, want the image of the manifest", images)
+	}
+	if len(result.Vulnerabilities) != 2 {
+		t.Errorf("Service.StartScan() vulnerabilities = %v, want the vulnerabilities of both batches", result.Vulnerabilities)
+	}
+}
+
+// TestService_Reinspect tests the functions [Reinspect()] and all the methods called by them
+func TestService_Reinspect(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	for _, name := range []string{"a/a.yaml", "b/b.yaml"} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte("---\nkind: Pod\n---\nkind: Service\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	historyDir := t.TempDir()
+	mockParser, mockFilesSource := createParserSourceProvider(dir)
+	history := storage.NewFileStorage(historyDir)
+	history.KeepFiles()
+	s := &Service{
+		SourceProvider: mockFilesSource,
+		Storage:        history,
+		Parser:         mockParser,
+		Inspector:      &fakePolicyEngine{},
+		Tracker:        &tracker.CITracker{},
+		ProjectID:      "project",
+	}
+	if _, err := s.StartScan(ctx, "first", true); err != nil {
+		t.Fatalf("Service.StartScan() error = %v", err)
+	}
+	if err := s.SaveScan(ctx, "first", true); err != nil {
+		t.Fatalf("Service.SaveScan() error = %v", err)
+	}
+
+	// the files of the scan are inspected again in a later run, with a batch per directory
+	policyEngine := &fakePolicyEngine{}
+	s.Storage = storage.NewFileStorage(historyDir)
+	s.Inspector = policyEngine
+	s.BatchSize = 1
+	if _, err := s.Reinspect(ctx, "first", "second", true); err != nil {
+		t.Fatalf("Service.Reinspect() error = %v", err)
+	}
+	if !reflect.DeepEqual(policyEngine.batches, []int{2, 2}) {
+		t.Errorf("Service.Reinspect() batches = %v, want the documents of each file together", policyEngine.batches)
+	}
+	for i := range policyEngine.files {
+		if policyEngine.files[i].ScanID != "second" || policyEngine.files[i].Document == nil {
+			t.Errorf("Service.Reinspect() files = %v, want the documents of the new scan", policyEngine.files)
+		}
+	}
+	vulnerabilities, err := s.GetVulnerabilities(ctx, "second")
+	if err != nil || len(vulnerabilities) != 4 {
+		t.Errorf("Service.Reinspect() vulnerabilities = %v, %v, want the vulnerabilities of all the files", vulnerabilities, err)
+	}
+
+	if _, err := s.Reinspect(ctx, "missing", "third", true); err == nil {
+		t.Errorf("Service.Reinspect() expected error for a scan without files kept")
+	}
+
+	// the files kept redacted are not inspected again
+	history = storage.NewFileStorage(historyDir)
+	history.KeepFiles()
+	s.Storage = history
+	s.RedactContent = true
+	if _, err := s.StartScan(ctx, "redacted", true); err != nil {
+		t.Fatalf("Service.StartScan() error = %v", err)
+	}
+	if err := s.SaveScan(ctx, "redacted", true); err != nil {
+		t.Fatalf("Service.SaveScan() error = %v", err)
+	}
+	s.Storage = storage.NewFileStorage(historyDir)
+	if _, err := s.Reinspect(ctx, "redacted", "fourth", true); err == nil {
+		t.Errorf("Service.Reinspect() expected error for a scan whose files were kept redacted")
+	}
+}
+
+// TestService_ScanFile tests the functions [ScanFile()] and all the methods called by them
+func TestService_ScanFile(t *testing.T) {
+	mockParser, mockFilesSource := createParserSourceProvider("../../assets/queries/template")
+	policyEngine := &fakePolicyEngine{}
+	// no storage nor tracker, single file scans don't use them
+	s := &Service{
+		SourceProvider: mockFilesSource,
+		Parser:         mockParser,
+		Inspector:      policyEngine,
+	}
+
+	got, err := s.ScanFile(context.Background(), "positive.yaml", []byte("---\nkind: Pod\n---\nkind: Service\n"))
+	if err != nil {
+		t.Fatalf("Service.ScanFile() error = %v", err)
+	}
+	if len(got) != 2 || len(policyEngine.files) != 2 {
+		t.Fatalf("Service.ScanFile() = %v, want a vulnerability of each document", got)
+	}
+	if policyEngine.files[0].Kind != model.KindYAML || policyEngine.files[0].OriginalData == "" ||
+		got[0].FileName != "positive.yaml" || got[0].ScanID == "" {
+		t.Errorf("Service.ScanFile() files = %v", policyEngine.files)
+	}
+
+	if _, err := s.ScanFile(context.Background(), "README.md", []byte("# readme")); err == nil {
+		t.Errorf("Service.ScanFile() expected error for unsupported file")
+	}
+}
@@ -0,0 +1,31 @@
+package kics
+
+import (
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// suppressInline marks the vulnerabilities suppressed by the inline comments (kics-scan) of the files they were found
+// in, suppressed vulnerabilities are kept to be reported separately
+func suppressInline(vulnerabilities []model.Vulnerability, files model.FileMetadatas) {
+	suppressions := make(map[string]*model.Suppressions)
+	for i := range files {
+		if fileSuppressions := model.ParseSuppressions(files[i].OriginalData); fileSuppressions != nil {
+			suppressions[files[i].ID] = fileSuppressions
+		}
+	}
+	if len(suppressions) == 0 {
+		return
+	}
+
+	suppressed := 0
+	for i := range vulnerabilities {
+		if suppression := suppressions[vulnerabilities[i].FileID].Match(&vulnerabilities[i]); suppression != nil {
+			vulnerabilities[i].Suppression = suppression
+			suppressed++
+		}
+	}
+	if suppressed > 0 {
+		log.Info().Msgf("%d results suppressed by inline comments", suppressed)
+	}
+}
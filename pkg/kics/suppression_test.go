@@ -0,0 +1,26 @@
+package kics
+
+import (
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSuppressInline tests the functions [suppressInline()] and all the methods called by them
+func TestSuppressInline(t *testing.T) {
+	files := model.FileMetadatas{
+		{ID: "suppressed", FileName: "/deploy/pod.yaml", OriginalData: "kind: Pod\nspec:\n  hostNetwork: true # kics-scan ignore-line\n"},
+		{ID: "other", FileName: "/deploy/service.yaml", OriginalData: "kind: Service\n"},
+	}
+	vulnerabilities := []model.Vulnerability{
+		{FileID: "suppressed", QueryID: "q1", Line: 3},
+		{FileID: "suppressed", QueryID: "q2", Line: 2},
+		{FileID: "other", QueryID: "q1", Line: 1},
+	}
+
+	suppressInline(vulnerabilities, files)
+	require.Equal(t, &model.Suppression{Marker: model.SuppressIgnoreLine, Line: 3}, vulnerabilities[0].Suppression)
+	require.Nil(t, vulnerabilities[1].Suppression)
+	require.Nil(t, vulnerabilities[2].Suppression)
+}
@@ -0,0 +1,77 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// conn reads and writes JSON-RPC messages framed by the Content-Length header of the base protocol
+type conn struct {
+	reader *textproto.Reader
+	writer io.Writer
+}
+
+func newConn(in io.Reader, out io.Writer) *conn {
+	return &conn{
+		reader: textproto.NewReader(bufio.NewReader(in)),
+		writer: out,
+	}
+}
+
+// read returns the next message, io.EOF when the client closed the stream
+func (c *conn) read() (*message, error) {
+	header, err := c.reader.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length header: %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.reader.R, body); err != nil {
+		return nil, errors.Wrap(err, "failed to read message")
+	}
+	msg := &message{}
+	if err := json.Unmarshal(body, msg); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal message")
+	}
+	return msg, nil
+}
+
+func (c *conn) write(msg *message) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal message")
+	}
+	if _, err := fmt.Fprintf(c.writer, "Content-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		return errors.Wrap(err, "failed to write message")
+	}
+	return nil
+}
+
+func (c *conn) reply(id *json.RawMessage, result interface{}) error {
+	if result == nil {
+		result = json.RawMessage("null")
+	}
+	return c.write(&message{ID: id, Result: result})
+}
+
+func (c *conn) replyError(id *json.RawMessage, code int, err error) error {
+	return c.write(&message{ID: id, Error: &responseError{Code: code, Message: err.Error()}})
+}
+
+func (c *conn) notify(method string, params interface{}) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal params")
+	}
+	return c.write(&message{Method: method, Params: raw})
+}
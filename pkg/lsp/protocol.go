@@ -0,0 +1,124 @@
+package lsp
+
+import "encoding/json"
+
+// Language Server Protocol diagnostic severities
+const (
+	diagnosticError       = 1
+	diagnosticWarning     = 2
+	diagnosticInformation = 3
+	diagnosticHint        = 4
+)
+
+// textDocumentSyncFull is the LSP text document sync kind where clients send the full content on each change
+const textDocumentSyncFull = 1
+
+const quickFixKind = "quickfix"
+
+// Position is a zero-based line and character offset in a text document
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a range in a text document, End is exclusive
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// TextEdit is a textual edit applicable to a text document
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// CodeDescription links a diagnostic to its documentation
+type CodeDescription struct {
+	Href string `json:"href"`
+}
+
+// Diagnostic is a finding published to the client, Data holds the similarity ID of the vulnerability
+type Diagnostic struct {
+	Range           Range            `json:"range"`
+	Severity        int              `json:"severity"`
+	Code            string           `json:"code"`
+	CodeDescription *CodeDescription `json:"codeDescription,omitempty"`
+	Source          string           `json:"source"`
+	Message         string           `json:"message"`
+	Data            string           `json:"data,omitempty"`
+}
+
+// WorkspaceEdit are the edits of a code action by document URI
+type WorkspaceEdit struct {
+	Changes map[string][]TextEdit `json:"changes"`
+}
+
+// CodeAction is a quick fix of a diagnostic
+type CodeAction struct {
+	Title       string         `json:"title"`
+	Kind        string         `json:"kind"`
+	Diagnostics []Diagnostic   `json:"diagnostics"`
+	Edit        *WorkspaceEdit `json:"edit"`
+}
+
+type textDocumentItem struct {
+	URI  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type didOpenParams struct {
+	TextDocument textDocumentItem `json:"textDocument"`
+}
+
+type didChangeParams struct {
+	TextDocument   textDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type didSaveParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Text         *string                `json:"text"`
+}
+
+type didCloseParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+type codeActionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+}
+
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// message is a JSON-RPC 2.0 request, response or notification, requests and responses have an ID
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  interface{}      `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// JSON-RPC error codes
+const (
+	methodNotFound = -32601
+	invalidParams  = -32602
+	internalError  = -32603
+)
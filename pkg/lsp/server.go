@@ -0,0 +1,275 @@
+// Package lsp implements a Language Server Protocol server publishing the vulnerabilities of the documents
+// opened in an editor as diagnostics
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const diagnosticSource = "kics"
+
+// Scanner scans the content of a single file, kics.Service implements it
+type Scanner interface {
+	ScanFile(ctx context.Context, filename string, content []byte) ([]model.Vulnerability, error)
+}
+
+// Fix is a remediation of a vulnerability, applied to the document with Edits
+type Fix struct {
+	Title string
+	Edits []TextEdit
+}
+
+// Remediator returns the fixes of a vulnerability of the document content, they are offered as quick fixes
+type Remediator interface {
+	Fixes(content []byte, vulnerability *model.Vulnerability) []Fix
+}
+
+// Server is a Language Server Protocol server, the documents are scanned when opened, changed and saved
+// and their vulnerabilities are published as diagnostics, Remediator is optional
+type Server struct {
+	Scanner    Scanner
+	Remediator Remediator
+
+	conn      *conn
+	documents map[string]*document
+	shutdown  bool
+}
+
+// document is an open document with the vulnerabilities of its last scan
+type document struct {
+	content         []byte
+	vulnerabilities []model.Vulnerability
+	diagnostics     []Diagnostic
+}
+
+// NewServer returns a server scanning the documents with scanner
+func NewServer(scanner Scanner, remediator Remediator) *Server {
+	return &Server{
+		Scanner:    scanner,
+		Remediator: remediator,
+	}
+}
+
+// Run serves the client until it exits or closes in, it returns an error if the client exits without shutdown
+func (s *Server) Run(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.conn = newConn(in, out)
+	s.documents = make(map[string]*document)
+	for {
+		msg, err := s.conn.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exit without shutdown")
+			}
+			return nil
+		}
+		if err := s.handle(ctx, msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handle handles a request or notification, only errors writing to the client are returned
+func (s *Server) handle(ctx context.Context, msg *message) error {
+	log.Debug().Msgf("lsp.handle(%s)", msg.Method)
+	result, err := s.dispatch(ctx, msg)
+	if msg.ID == nil {
+		if err != nil {
+			log.Err(err).Msgf("Failed to handle %s", msg.Method)
+		}
+		return nil
+	}
+	if err != nil {
+		code := internalError
+		if rpcErr, ok := err.(*responseError); ok {
+			code = rpcErr.Code
+		}
+		return s.conn.replyError(msg.ID, code, err)
+	}
+	return s.conn.reply(msg.ID, result)
+}
+
+func (s *Server) dispatch(ctx context.Context, msg *message) (interface{}, error) {
+	switch msg.Method {
+	case "initialize":
+		return s.initialize(), nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		params := didOpenParams{}
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.scan(ctx, params.TextDocument.URI, []byte(params.TextDocument.Text))
+	case "textDocument/didChange":
+		params := didChangeParams{}
+		if err := unmarshalParams(msg, &params); err != nil || len(params.ContentChanges) == 0 {
+			return nil, err
+		}
+		// full sync, the last change has the whole content
+		text := params.ContentChanges[len(params.ContentChanges)-1].Text
+		return nil, s.scan(ctx, params.TextDocument.URI, []byte(text))
+	case "textDocument/didSave":
+		params := didSaveParams{}
+		if err := unmarshalParams(msg, &params); err != nil || params.Text == nil {
+			return nil, err
+		}
+		return nil, s.scan(ctx, params.TextDocument.URI, []byte(*params.Text))
+	case "textDocument/didClose":
+		params := didCloseParams{}
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		delete(s.documents, params.TextDocument.URI)
+		return nil, s.publish(params.TextDocument.URI, []Diagnostic{})
+	case "textDocument/codeAction":
+		params := codeActionParams{}
+		if err := unmarshalParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return s.codeActions(params.TextDocument.URI, params.Range), nil
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil, nil
+	default:
+		return nil, &responseError{Code: methodNotFound, Message: "method not found: " + msg.Method}
+	}
+}
+
+func (s *Server) initialize() interface{} {
+	capabilities := map[string]interface{}{
+		"textDocumentSync": map[string]interface{}{
+			"openClose": true,
+			"change":    textDocumentSyncFull,
+			"save":      map[string]interface{}{"includeText": true},
+		},
+	}
+	if s.Remediator != nil {
+		capabilities["codeActionProvider"] = map[string]interface{}{"codeActionKinds": []string{quickFixKind}}
+	}
+	return map[string]interface{}{
+		"capabilities": capabilities,
+		"serverInfo":   map[string]interface{}{"name": diagnosticSource},
+	}
+}
+
+// scan scans the document and publishes its diagnostics, documents that can't be scanned, such as files
+// of unsupported platforms, have no diagnostics
+func (s *Server) scan(ctx context.Context, uri string, content []byte) error {
+	doc := &document{content: content, diagnostics: []Diagnostic{}}
+	s.documents[uri] = doc
+
+	vulnerabilities, err := s.Scanner.ScanFile(ctx, uriToFileName(uri), content)
+	if err != nil {
+		log.Debug().Msgf("Failed to scan %s: %s", uri, err)
+	}
+	lines := strings.Split(string(content), "\n")
+	for i := range vulnerabilities {
+		doc.vulnerabilities = append(doc.vulnerabilities, vulnerabilities[i])
+		doc.diagnostics = append(doc.diagnostics, toDiagnostic(&vulnerabilities[i], lines))
+	}
+	return s.publish(uri, doc.diagnostics)
+}
+
+func (s *Server) publish(uri string, diagnostics []Diagnostic) error {
+	return s.conn.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: diagnostics,
+	})
+}
+
+// codeActions returns the quick fixes of the diagnostics of the document within the range
+func (s *Server) codeActions(uri string, r Range) []CodeAction {
+	actions := make([]CodeAction, 0)
+	doc, ok := s.documents[uri]
+	if !ok || s.Remediator == nil {
+		return actions
+	}
+	for i := range doc.vulnerabilities {
+		diagnostic := doc.diagnostics[i]
+		if diagnostic.Range.End.Line < r.Start.Line || diagnostic.Range.Start.Line > r.End.Line {
+			continue
+		}
+		for _, fix := range s.Remediator.Fixes(doc.content, &doc.vulnerabilities[i]) {
+			actions = append(actions, CodeAction{
+				Title:       fix.Title,
+				Kind:        quickFixKind,
+				Diagnostics: []Diagnostic{diagnostic},
+				Edit:        &WorkspaceEdit{Changes: map[string][]TextEdit{uri: fix.Edits}},
+			})
+		}
+	}
+	return actions
+}
+
+// toDiagnostic returns the diagnostic of the vulnerability, its range is the line of the vulnerability
+// without indentation, vulnerabilities without line are reported on the first line
+func toDiagnostic(vulnerability *model.Vulnerability, lines []string) Diagnostic {
+	line := vulnerability.Line - 1
+	if line < 0 || line >= len(lines) {
+		line = 0
+	}
+	text := strings.TrimRight(lines[line], "\r")
+	diagnostic := Diagnostic{
+		Range: Range{
+			Start: Position{Line: line, Character: len(text) - len(strings.TrimLeft(text, " \t"))},
+			End:   Position{Line: line, Character: len(text)},
+		},
+		Severity: diagnosticSeverity(vulnerability.Severity),
+		Code:     vulnerability.QueryID,
+		Source:   diagnosticSource,
+		Message:  vulnerability.QueryName + ": " + vulnerability.KeyActualValue,
+		Data:     vulnerability.SimilarityID,
+	}
+	if vulnerability.QueryURI != "" {
+		diagnostic.CodeDescription = &CodeDescription{Href: vulnerability.QueryURI}
+	}
+	return diagnostic
+}
+
+func diagnosticSeverity(severity model.Severity) int {
+	switch severity {
+	case model.SeverityHigh:
+		return diagnosticError
+	case model.SeverityMedium:
+		return diagnosticWarning
+	case model.SeverityLow:
+		return diagnosticInformation
+	default:
+		return diagnosticHint
+	}
+}
+
+// uriToFileName returns the path of file URIs, other URIs are returned as they are
+func uriToFileName(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func unmarshalParams(msg *message, params interface{}) error {
+	if err := json.Unmarshal(msg.Params, params); err != nil {
+		return &responseError{Code: invalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func (e *responseError) Error() string {
+	return e.Message
+}
@@ -0,0 +1,147 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+type mockScanner struct {
+	fileNames []string
+}
+
+func (m *mockScanner) ScanFile(ctx context.Context, filename string, content []byte) ([]model.Vulnerability, error) {
+	m.fileNames = append(m.fileNames, filename)
+	if !strings.HasSuffix(filename, "Dockerfile") {
+		return nil, fmt.Errorf("unsupported file %s", filename)
+	}
+	return []model.Vulnerability{
+		{
+			QueryID:        "fd54f200-402c-4333-a5a4-36ef6709af2f",
+			QueryName:      "Missing User Instruction",
+			QueryURI:       "https://docs.docker.com/engine/reference/builder/#user",
+			Severity:       model.Severity(model.SeverityHigh),
+			Line:           2,
+			SimilarityID:   "similarity",
+			KeyActualValue: "The 'Dockerfile' does not contain any 'USER' instruction",
+		},
+	}, nil
+}
+
+type mockRemediator struct{}
+
+func (m *mockRemediator) Fixes(content []byte, vulnerability *model.Vulnerability) []Fix {
+	return []Fix{
+		{
+			Title: "Add USER instruction",
+			Edits: []TextEdit{{Range: Range{Start: Position{Line: 2}, End: Position{Line: 2}}, NewText: "USER app\n"}},
+		},
+	}
+}
+
+func request(id int, method string, params interface{}) string {
+	raw, _ := json.Marshal(params)
+	body := fmt.Sprintf(`{"jsonrpc":"2.0","method":%q,"params":%s}`, method, raw)
+	if id > 0 {
+		body = fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":%q,"params":%s}`, id, method, raw)
+	}
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func readMessages(t *testing.T, out *bytes.Buffer) []message {
+	c := newConn(out, nil)
+	messages := make([]message, 0)
+	for {
+		msg, err := c.read()
+		if err == io.EOF {
+			return messages
+		}
+		require.NoError(t, err)
+		messages = append(messages, *msg)
+	}
+}
+
+// TestServer_Run tests the functions [Run()] and all the methods called by them
+func TestServer_Run(t *testing.T) {
+	uri := "file:///project/Dockerfile"
+	content := "FROM alpine:3.14\n  RUN apk add curl\n"
+	in := strings.Join([]string{
+		request(1, "initialize", map[string]interface{}{}),
+		request(0, "initialized", map[string]interface{}{}),
+		request(0, "textDocument/didOpen", map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri, "languageId": "dockerfile", "version": 1, "text": content},
+		}),
+		request(0, "textDocument/didOpen", map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": "file:///project/README.md", "version": 1, "text": "# readme"},
+		}),
+		request(2, "textDocument/codeAction", map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri},
+			"range":        Range{Start: Position{Line: 1}, End: Position{Line: 1, Character: 3}},
+		}),
+		request(3, "unknown/method", map[string]interface{}{}),
+		request(0, "textDocument/didClose", map[string]interface{}{"textDocument": map[string]interface{}{"uri": uri}}),
+		request(4, "shutdown", nil),
+		request(0, "exit", nil),
+	}, "")
+
+	scanner := &mockScanner{}
+	out := &bytes.Buffer{}
+	err := NewServer(scanner, &mockRemediator{}).Run(context.Background(), strings.NewReader(in), out)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/project/Dockerfile", "/project/README.md"}, scanner.fileNames)
+
+	messages := readMessages(t, out)
+	require.Len(t, messages, 7)
+
+	initialize, _ := json.Marshal(messages[0].Result)
+	require.Contains(t, string(initialize), `"codeActionProvider"`)
+
+	require.Equal(t, "textDocument/publishDiagnostics", messages[1].Method)
+	published := publishDiagnosticsParams{}
+	require.NoError(t, json.Unmarshal(messages[1].Params, &published))
+	require.Equal(t, uri, published.URI)
+	require.Equal(t, []Diagnostic{
+		{
+			Range:           Range{Start: Position{Line: 1, Character: 2}, End: Position{Line: 1, Character: 18}},
+			Severity:        diagnosticError,
+			Code:            "fd54f200-402c-4333-a5a4-36ef6709af2f",
+			CodeDescription: &CodeDescription{Href: "https://docs.docker.com/engine/reference/builder/#user"},
+			Source:          "kics",
+			Message:         "Missing User Instruction: The 'Dockerfile' does not contain any 'USER' instruction",
+			Data:            "similarity",
+		},
+	}, published.Diagnostics)
+
+	require.NoError(t, json.Unmarshal(messages[2].Params, &published))
+	require.Empty(t, published.Diagnostics)
+
+	actions, _ := json.Marshal(messages[3].Result)
+	codeActions := []CodeAction{}
+	require.NoError(t, json.Unmarshal(actions, &codeActions))
+	require.Len(t, codeActions, 1)
+	require.Equal(t, "Add USER instruction", codeActions[0].Title)
+	require.Equal(t, "USER app\n", codeActions[0].Edit.Changes[uri][0].NewText)
+
+	require.NotNil(t, messages[4].Error)
+	require.Equal(t, methodNotFound, messages[4].Error.Code)
+
+	require.NoError(t, json.Unmarshal(messages[5].Params, &published))
+	require.Equal(t, uri, published.URI)
+	require.Empty(t, published.Diagnostics)
+
+	require.Nil(t, messages[6].Error)
+}
+
+// TestServer_RunExitWithoutShutdown tests the functions [Run()] when the client exits without shutdown
+func TestServer_RunExitWithoutShutdown(t *testing.T) {
+	out := &bytes.Buffer{}
+	err := NewServer(&mockScanner{}, nil).Run(context.Background(), strings.NewReader(request(0, "exit", nil)), out)
+	require.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	"github.com/Checkmarx/kics/internal/constants"
+)
+
+// QueryAudit identifies a query evaluated by a scan, Digest is the SHA-256 of everything the query evaluates,
+// its content, the libraries it uses and its metadata as overridden, so any change to the query changes it
+type QueryAudit struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Platform string `json:"platform"`
+	Engine   string `json:"engine"`
+	Digest   string `json:"digest"`
+}
+
+// ScanAudit records how a scan was executed so its results can be reproduced and audited, the version of KICS,
+// the configuration of the scan and the queries evaluated, QueriesDigest is the digest of the digests of all
+// the queries, so two scans evaluated the same queries when their queries digests are equal
+type ScanAudit struct {
+	ScanID        string            `json:"scan_id"`
+	Time          time.Time         `json:"time"`
+	KICSVersion   string            `json:"kics_version"`
+	Configuration map[string]string `json:"configuration"`
+	QueriesDigest string            `json:"queries_digest"`
+	Queries       []QueryAudit      `json:"queries"`
+}
+
+// NewScanAudit returns the audit of a scan, the queries are sorted by engine and ID
+func NewScanAudit(scanID string, configuration map[string]string, queries []QueryAudit, now time.Time) *ScanAudit {
+	sorted := make([]QueryAudit, len(queries))
+	copy(sorted, queries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Engine != sorted[j].Engine {
+			return sorted[i].Engine < sorted[j].Engine
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	hash := sha256.New()
+	for i := range sorted {
+		_, _ = hash.Write([]byte(sorted[i].Engine + " " + sorted[i].ID + " " + sorted[i].Digest + "\n"))
+	}
+	if configuration == nil {
+		configuration = map[string]string{}
+	}
+	return &ScanAudit{
+		ScanID:        scanID,
+		Time:          now,
+		KICSVersion:   constants.Version,
+		Configuration: configuration,
+		QueriesDigest: "sha256:" + hex.EncodeToString(hash.Sum(nil)),
+		Queries:       sorted,
+	}
+}
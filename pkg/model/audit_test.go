@@ -0,0 +1,33 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewScanAudit tests the functions [NewScanAudit()] and all the methods called by them
+func TestNewScanAudit(t *testing.T) {
+	now := time.Date(2021, 7, 16, 10, 0, 0, 0, time.UTC)
+	queries := []QueryAudit{
+		{ID: "b", Name: "Query B", Platform: "Terraform", Engine: "rego", Digest: "sha256:b"},
+		{ID: "c", Name: "Query C", Platform: "Common", Engine: "regex", Digest: "sha256:c"},
+		{ID: "a", Name: "Query A", Platform: "Kubernetes", Engine: "rego", Digest: "sha256:a"},
+	}
+
+	audit := NewScanAudit("scan", map[string]string{"fail-on": "high"}, queries, now)
+	require.Equal(t, "scan", audit.ScanID)
+	require.Equal(t, now, audit.Time)
+	require.NotEmpty(t, audit.KICSVersion)
+	// the queries are sorted by engine and then by ID, so the regex query comes first
+	require.Equal(t, []string{"c", "a", "b"}, []string{audit.Queries[0].ID, audit.Queries[1].ID, audit.Queries[2].ID})
+	require.Equal(t, "b", queries[0].ID, "the queries given are not sorted")
+
+	// the digest of the queries does not depend on their order but on their content
+	reordered := NewScanAudit("other", nil, []QueryAudit{queries[2], queries[1], queries[0]}, now)
+	require.Equal(t, audit.QueriesDigest, reordered.QueriesDigest)
+	require.Empty(t, reordered.Configuration)
+	queries[1].Digest = "sha256:changed"
+	require.NotEqual(t, audit.QueriesDigest, NewScanAudit("scan", nil, queries, now).QueriesDigest)
+}
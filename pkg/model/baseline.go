@@ -0,0 +1,93 @@
+package model
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Baseline is the set of results known in a project, such as the results of the default branch, so the scans
+// comparing their results with it only report the new ones, results are matched by similarity ID, which does not
+// change when their lines shift
+type Baseline struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	Results     []BaselineResult `json:"results"`
+}
+
+// BaselineResult is a result known in the baseline, FileName is relative to the scanned path
+type BaselineResult struct {
+	SimilarityID string   `json:"similarity_id"`
+	QueryID      string   `json:"query_id"`
+	QueryName    string   `json:"query_name"`
+	Severity     Severity `json:"severity"`
+	FileName     string   `json:"file_name"`
+	Line         int      `json:"line"`
+}
+
+// BaselineComparison compares the results of a scan with a baseline, KnownResults are the results of the scan found
+// in the baseline, not reported, and FixedResults the results of the baseline no longer found
+type BaselineComparison struct {
+	KnownResults int `json:"known_results"`
+	NewResults   int `json:"new_results"`
+	FixedResults int `json:"fixed_results"`
+}
+
+// NewBaseline returns the baseline of the vulnerabilities, the file names under the base path are made relative to it,
+// vulnerabilities without similarity ID can not be matched and are left out, results are sorted by file name and line
+func NewBaseline(vulnerabilities []Vulnerability, basePath string, generatedAt time.Time) *Baseline {
+	baseline := &Baseline{GeneratedAt: generatedAt, Results: make([]BaselineResult, 0, len(vulnerabilities))}
+	added := make(map[string]bool, len(vulnerabilities))
+	for i := range vulnerabilities {
+		vulnerability := &vulnerabilities[i]
+		if vulnerability.SimilarityID == "" || added[vulnerability.SimilarityID] {
+			continue
+		}
+		added[vulnerability.SimilarityID] = true
+		fileName := filepath.ToSlash(vulnerability.FileName)
+		if rel, err := filepath.Rel(basePath, vulnerability.FileName); basePath != "" && err == nil && !strings.HasPrefix(rel, "..") {
+			fileName = filepath.ToSlash(rel)
+		}
+		baseline.Results = append(baseline.Results, BaselineResult{
+			SimilarityID: vulnerability.SimilarityID,
+			QueryID:      vulnerability.QueryID,
+			QueryName:    vulnerability.QueryName,
+			Severity:     vulnerability.Severity,
+			FileName:     fileName,
+			Line:         vulnerability.Line,
+		})
+	}
+	sort.Slice(baseline.Results, func(i, j int) bool {
+		if baseline.Results[i].FileName != baseline.Results[j].FileName {
+			return baseline.Results[i].FileName < baseline.Results[j].FileName
+		}
+		if baseline.Results[i].Line != baseline.Results[j].Line {
+			return baseline.Results[i].Line < baseline.Results[j].Line
+		}
+		return baseline.Results[i].SimilarityID < baseline.Results[j].SimilarityID
+	})
+	return baseline
+}
+
+// Split splits the vulnerabilities in the new ones and the ones known in the baseline, and compares them with it
+func (b *Baseline) Split(vulnerabilities []Vulnerability) (newResults, known []Vulnerability, comparison *BaselineComparison) {
+	baseline := make(map[string]bool, len(b.Results))
+	for i := range b.Results {
+		baseline[b.Results[i].SimilarityID] = true
+	}
+	newResults = make([]Vulnerability, 0, len(vulnerabilities))
+	found := make(map[string]bool, len(vulnerabilities))
+	for i := range vulnerabilities {
+		if similarityID := vulnerabilities[i].SimilarityID; similarityID != "" && baseline[similarityID] {
+			known = append(known, vulnerabilities[i])
+			found[similarityID] = true
+			continue
+		}
+		newResults = append(newResults, vulnerabilities[i])
+	}
+	return newResults, known, &BaselineComparison{
+		KnownResults: len(known),
+		NewResults:   len(newResults),
+		FixedResults: len(baseline) - len(found),
+	}
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestBaseline tests the functions [NewBaseline()], [Baseline.Split()] and all the methods called by them
+func TestBaseline(t *testing.T) {
+	generatedAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	baseline := NewBaseline([]Vulnerability{
+		{SimilarityID: "b", QueryID: "q2", QueryName: "query 2", Severity: SeverityLow, FileName: "/repo/main.tf", Line: 9},
+		{SimilarityID: "a", QueryID: "q1", QueryName: "query 1", Severity: SeverityHigh, FileName: "/repo/main.tf", Line: 3},
+		{SimilarityID: "a", QueryID: "q1", QueryName: "query 1", Severity: SeverityHigh, FileName: "/repo/main.tf", Line: 3},
+		{SimilarityID: "", QueryID: "q3", FileName: "/repo/main.tf", Line: 1},
+		{SimilarityID: "c", QueryID: "q1", QueryName: "query 1", Severity: SeverityHigh, FileName: "/other/vpc.tf", Line: 1},
+	}, "/repo", generatedAt)
+	require.Equal(t, &Baseline{
+		GeneratedAt: generatedAt,
+		Results: []BaselineResult{
+			{SimilarityID: "c", QueryID: "q1", QueryName: "query 1", Severity: SeverityHigh, FileName: "/other/vpc.tf", Line: 1},
+			{SimilarityID: "a", QueryID: "q1", QueryName: "query 1", Severity: SeverityHigh, FileName: "main.tf", Line: 3},
+			{SimilarityID: "b", QueryID: "q2", QueryName: "query 2", Severity: SeverityLow, FileName: "main.tf", Line: 9},
+		},
+	}, baseline)
+
+	// the lines of the known results shifted, their similarity IDs did not
+	current := []Vulnerability{
+		{SimilarityID: "a", QueryID: "q1", FileName: "/repo/main.tf", Line: 12},
+		{SimilarityID: "d", QueryID: "q4", FileName: "/repo/main.tf", Line: 2},
+		{SimilarityID: "", QueryID: "q3", FileName: "/repo/main.tf", Line: 1},
+	}
+	newResults, known, comparison := baseline.Split(current)
+	require.Equal(t, []Vulnerability{current[1], current[2]}, newResults)
+	require.Equal(t, []Vulnerability{current[0]}, known)
+	require.Equal(t, &BaselineComparison{KnownResults: 1, NewResults: 2, FixedResults: 2}, comparison)
+}
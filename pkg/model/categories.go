@@ -0,0 +1,53 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var nonAlphanumericRegex = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// Category is a custom category of queries, the ID identifies it in the reports and defaults to one derived from its name
+type Category struct {
+	ID          string `json:"id" yaml:"id"`
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+}
+
+// AddCategories extends the built-in categories with custom categories, reported like the built-in ones
+func AddCategories(custom []Category) error {
+	ids := make(map[string]string, len(categories)+len(custom))
+	for name := range categories {
+		ids[categories[name].DefinitionID] = name
+	}
+	for _, category := range custom {
+		if category.Name == "" {
+			return fmt.Errorf("custom category without name")
+		}
+		if _, ok := categories[category.Name]; ok {
+			return fmt.Errorf("category %s already exists", category.Name)
+		}
+		id := category.ID
+		if id == "" {
+			id = categoryIdentifier + "-" + strings.ToUpper(strings.Trim(nonAlphanumericRegex.ReplaceAllString(category.Name, "-"), "-"))
+		}
+		if name, ok := ids[id]; ok {
+			return fmt.Errorf("ID %s of category %s is already used by category %s", id, category.Name, name)
+		}
+		ids[id] = category.Name
+		categories[category.Name] = sarifTaxanomyDefinition{
+			DefinitionID:               id,
+			DefinitionName:             category.Name,
+			DefinitionShortDescription: sarifMessage{Text: category.Description},
+			DefinitionFullDescription:  sarifMessage{Text: category.Description},
+		}
+	}
+	return nil
+}
+
+// IsCategory returns true if the category is a built-in or custom category
+func IsCategory(name string) bool {
+	_, ok := categories[name]
+	return ok
+}
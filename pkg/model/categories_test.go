@@ -0,0 +1,32 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestAddCategories tests the functions [AddCategories()] and all the methods called by them
+func TestAddCategories(t *testing.T) {
+	defer func() {
+		delete(categories, "Internal Policy")
+		delete(categories, "Data Residency")
+	}()
+
+	require.False(t, IsCategory("Internal Policy"))
+	require.NoError(t, AddCategories([]Category{
+		{Name: "Internal Policy", Description: "Policies of the security team"},
+		{ID: "CAT101", Name: "Data Residency", Description: "Location of the stored data"},
+	}))
+	require.True(t, IsCategory("Internal Policy"))
+	require.True(t, IsCategory("Access Control"))
+	require.Equal(t, "CAT-INTERNAL-POLICY", categories["Internal Policy"].DefinitionID)
+
+	require.Error(t, AddCategories([]Category{{Name: "Backup"}}))
+	require.Error(t, AddCategories([]Category{{ID: "CAT101", Name: "Sovereignty"}}))
+	require.Error(t, AddCategories([]Category{{Description: "no name"}}))
+
+	sarif := NewSarifReport().(*sarifReport)
+	target := sarif.buildCategory("Data Residency")
+	require.Equal(t, "CAT101", target.ReferenceID)
+}
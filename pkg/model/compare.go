@@ -0,0 +1,97 @@
+package model
+
+// ReportResult is a result of a report compared with another report
+type ReportResult struct {
+	QueryID      string   `json:"query_id"`
+	QueryName    string   `json:"query_name"`
+	Severity     Severity `json:"severity"`
+	FileName     string   `json:"file_name"`
+	Line         int      `json:"line"`
+	SearchKey    string   `json:"search_key"`
+	SimilarityID string   `json:"similarity_id"`
+}
+
+// ReportComparison compares the results of a report with the ones of a base report of the same project,
+// New results are only in the report, Fixed results only in the base report and Unchanged results in both
+type ReportComparison struct {
+	New                   []ReportResult   `json:"new"`
+	Fixed                 []ReportResult   `json:"fixed"`
+	Unchanged             []ReportResult   `json:"unchanged"`
+	NewSeverityCounters   map[Severity]int `json:"new_severity_counters"`
+	FixedSeverityCounters map[Severity]int `json:"fixed_severity_counters"`
+}
+
+// CompareReports compares the results of the summary of a report with the ones of the summary of the base report,
+// results are matched by similarity ID, or by query, file and search key when they have no similarity ID
+func CompareReports(base, summary *Summary) *ReportComparison {
+	comparison := &ReportComparison{
+		New:                   []ReportResult{},
+		Fixed:                 []ReportResult{},
+		Unchanged:             []ReportResult{},
+		NewSeverityCounters:   newSeverityCounters(),
+		FixedSeverityCounters: newSeverityCounters(),
+	}
+	baseResults := make(map[string][]ReportResult)
+	for _, result := range reportResults(base) {
+		key := result.key()
+		baseResults[key] = append(baseResults[key], result)
+	}
+	for _, result := range reportResults(summary) {
+		key := result.key()
+		if len(baseResults[key]) == 0 {
+			comparison.New = append(comparison.New, result)
+			comparison.NewSeverityCounters[result.Severity]++
+			continue
+		}
+		baseResults[key] = baseResults[key][1:]
+		comparison.Unchanged = append(comparison.Unchanged, result)
+	}
+	// the fixed results keep the order of the base report
+	for _, result := range reportResults(base) {
+		key := result.key()
+		if len(baseResults[key]) == 0 {
+			continue
+		}
+		baseResults[key] = baseResults[key][1:]
+		comparison.Fixed = append(comparison.Fixed, result)
+		comparison.FixedSeverityCounters[result.Severity]++
+	}
+	return comparison
+}
+
+// HasNewResults returns true if there are new results with the severity given or above
+func (c *ReportComparison) HasNewResults(threshold Severity) bool {
+	for severity, count := range c.NewSeverityCounters {
+		if count > 0 && severity.AtLeast(threshold) {
+			return true
+		}
+	}
+	return false
+}
+
+func reportResults(summary *Summary) []ReportResult {
+	results := make([]ReportResult, 0)
+	for i := range summary.Queries {
+		query := &summary.Queries[i]
+		for j := range query.Files {
+			file := &query.Files[j]
+			results = append(results, ReportResult{
+				QueryID:      query.QueryID,
+				QueryName:    query.QueryName,
+				Severity:     query.Severity,
+				FileName:     file.FileName,
+				Line:         file.Line,
+				SearchKey:    file.SearchKey,
+				SimilarityID: file.SimilarityID,
+			})
+		}
+	}
+	return results
+}
+
+func (r *ReportResult) key() string {
+	if r.SimilarityID != "" {
+		return r.SimilarityID
+	}
+	return r.QueryID + "\x00" + r.FileName + "\x00" + r.SearchKey
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompareReports tests the functions [CompareReports()] and all the methods called by them
+func TestCompareReports(t *testing.T) {
+	base := &Summary{Queries: VulnerableQuerySlice{
+		{QueryID: "q1", QueryName: "Query 1", Severity: SeverityHigh, Files: []VulnerableFile{
+			{FileName: "main.tf", Line: 3, SimilarityID: "a1"},
+			{FileName: "main.tf", Line: 9, SimilarityID: "b2"},
+		}},
+		{QueryID: "q2", QueryName: "Query 2", Severity: SeverityLow, Files: []VulnerableFile{
+			{FileName: "vpc.tf", Line: 1, SearchKey: "resource.vpc"},
+			{FileName: "vpc.tf", Line: 1, SearchKey: "resource.vpc"},
+		}},
+	}}
+	summary := &Summary{Queries: VulnerableQuerySlice{
+		{QueryID: "q1", QueryName: "Query 1", Severity: SeverityHigh, Files: []VulnerableFile{
+			{FileName: "main.tf", Line: 4, SimilarityID: "a1"},
+		}},
+		{QueryID: "q2", QueryName: "Query 2", Severity: SeverityLow, Files: []VulnerableFile{
+			{FileName: "vpc.tf", Line: 1, SearchKey: "resource.vpc"},
+		}},
+		{QueryID: "q3", QueryName: "Query 3", Severity: SeverityMedium, Files: []VulnerableFile{
+			{FileName: "s3.tf", Line: 7, SimilarityID: "c3"},
+		}},
+	}}
+
+	comparison := CompareReports(base, summary)
+	require.Equal(t, []ReportResult{
+		{QueryID: "q3", QueryName: "Query 3", Severity: SeverityMedium, FileName: "s3.tf", Line: 7, SimilarityID: "c3"},
+	}, comparison.New)
+	require.Equal(t, []ReportResult{
+		{QueryID: "q1", QueryName: "Query 1", Severity: SeverityHigh, FileName: "main.tf", Line: 9, SimilarityID: "b2"},
+		{QueryID: "q2", QueryName: "Query 2", Severity: SeverityLow, FileName: "vpc.tf", Line: 1, SearchKey: "resource.vpc"},
+	}, comparison.Fixed)
+	require.Len(t, comparison.Unchanged, 2)
+	require.Equal(t, 4, comparison.Unchanged[0].Line)
+	require.Equal(t, map[Severity]int{SeverityHigh: 0, SeverityMedium: 1, SeverityLow: 0, SeverityInfo: 0}, comparison.NewSeverityCounters)
+	require.Equal(t, map[Severity]int{SeverityHigh: 1, SeverityMedium: 0, SeverityLow: 1, SeverityInfo: 0}, comparison.FixedSeverityCounters)
+	require.True(t, comparison.HasNewResults(SeverityMedium))
+	require.False(t, comparison.HasNewResults(SeverityHigh))
+
+	comparison = CompareReports(summary, summary)
+	require.Empty(t, comparison.New)
+	require.Empty(t, comparison.Fixed)
+	require.False(t, comparison.HasNewResults(SeverityInfo))
+}
@@ -0,0 +1,229 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	searchKeyFilterRegex = regexp.MustCompile(`^([^=]+)=\{\{(.*)}}$`)
+	searchKeyNameRegex   = regexp.MustCompile(`^(.+)\[(.+)]$`)
+)
+
+// Explanation is a result explained with the metadata of its query and the document subtree matched by its search key,
+// the subtree is nil when the document of the result is not given
+type Explanation struct {
+	QueryID       string      `json:"query_id"`
+	QueryName     string      `json:"query_name"`
+	QueryURI      string      `json:"query_url"`
+	Severity      Severity    `json:"severity"`
+	Platform      string      `json:"platform"`
+	Category      string      `json:"category"`
+	Description   string      `json:"description"`
+	FileName      string      `json:"file_name"`
+	Line          int         `json:"line"`
+	IssueType     IssueType   `json:"issue_type"`
+	SearchKey     string      `json:"search_key"`
+	ExpectedValue string      `json:"expected_value"`
+	ActualValue   string      `json:"actual_value"`
+	MatchedKey    string      `json:"matched_key,omitempty"`
+	Subtree       interface{} `json:"subtree,omitempty"`
+}
+
+// Explain explains the result of the file with the metadata of its query and the subtree of the documents of the file
+// matched by its search key, the document deepest matched by the search key is used
+func Explain(query *VulnerableQuery, file *VulnerableFile, documents []Document) *Explanation {
+	explanation := &Explanation{
+		QueryID:       query.QueryID,
+		QueryName:     query.QueryName,
+		QueryURI:      query.QueryURI,
+		Severity:      query.Severity,
+		Platform:      query.Platform,
+		Category:      query.Category,
+		Description:   query.Description,
+		FileName:      file.FileName,
+		Line:          file.Line,
+		IssueType:     file.IssueType,
+		SearchKey:     file.SearchKey,
+		ExpectedValue: file.KeyExpectedValue,
+		ActualValue:   file.KeyActualValue,
+	}
+	matched := -1
+	for _, document := range documents {
+		if fileName, _ := document["file"].(string); fileName != file.FileName {
+			continue
+		}
+		keys, subtree := MatchSearchKey(document, file.SearchKey)
+		if len(keys) > matched {
+			matched = len(keys)
+			explanation.MatchedKey = strings.Join(keys, ".")
+			explanation.Subtree = subtree
+		}
+	}
+	if document, ok := explanation.Subtree.(Document); ok {
+		explanation.Subtree = withoutInternalKeys(document)
+	}
+	return explanation
+}
+
+// String formats the explanation for the terminal
+func (e *Explanation) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s (%s)\n", e.Severity, e.QueryName, e.QueryID)
+	fmt.Fprintf(&b, "Platform: %s, Category: %s\n\n", e.Platform, e.Category)
+	fmt.Fprintf(&b, "%s\n\n", e.Description)
+	fmt.Fprintf(&b, "File: %s:%d\n", e.FileName, e.Line)
+	fmt.Fprintf(&b, "Resource: %s\n", e.SearchKey)
+	fmt.Fprintf(&b, "Issue type: %s\n", e.IssueType)
+	fmt.Fprintf(&b, "Expected: %s\n", e.ExpectedValue)
+	fmt.Fprintf(&b, "Actual: %s\n", e.ActualValue)
+	if e.Subtree != nil {
+		matchedKey := e.MatchedKey
+		if matchedKey == "" {
+			matchedKey = "document"
+		}
+		subtree, err := json.MarshalIndent(e.Subtree, "\t", "  ")
+		if err == nil {
+			fmt.Fprintf(&b, "\nMatched %s:\n\t%s\n", matchedKey, subtree)
+		}
+	}
+	if e.QueryURI != "" {
+		fmt.Fprintf(&b, "\nMore information: %s\n", e.QueryURI)
+	}
+	return b.String()
+}
+
+// MatchSearchKey walks the document along the search key and returns the keys of the search key matched and the
+// subtree found at the last of them, the walk stops at the first key not found in the document
+// keys with a filter (ex: name={{app}}) select the element of a list or check the object of the previous key,
+// keys with a name (ex: aws_s3_bucket[bucket]) select the object of the name and keys with a value (ex: acl=private)
+// select the attribute
+func MatchSearchKey(document Document, searchKey string) (keys []string, subtree interface{}) {
+	keys = make([]string, 0)
+	var parent interface{}
+	current := interface{}(document)
+	for i, key := range splitSearchKey(searchKey) {
+		next, nextParent, ok := matchKey(current, parent, key)
+		if !ok && i == 0 {
+			next, nextParent, ok = matchTopLevelKey(document, key)
+		}
+		if !ok {
+			break
+		}
+		keys = append(keys, key)
+		current, parent = next, nextParent
+	}
+	return keys, current
+}
+
+// matchTopLevelKey matches the key in the objects of the document, for search keys without the first key of the
+// document (ex: the Terraform search keys without resource)
+func matchTopLevelKey(document Document, key string) (next, nextParent interface{}, ok bool) {
+	topLevelKeys := make([]string, 0, len(document))
+	for topLevelKey := range document {
+		topLevelKeys = append(topLevelKeys, topLevelKey)
+	}
+	sort.Strings(topLevelKeys)
+	for _, topLevelKey := range topLevelKeys {
+		if object, isObject := asObject(document[topLevelKey]); isObject {
+			if next, nextParent, ok = matchKey(object, document, key); ok {
+				return next, nextParent, true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// matchKey returns the value matched by the key in the current value, along with its parent
+func matchKey(current, parent interface{}, key string) (next, nextParent interface{}, ok bool) {
+	if parts := searchKeyFilterRegex.FindStringSubmatch(key); parts != nil {
+		field, value := parts[1], parts[2]
+		switch v := current.(type) {
+		case []interface{}:
+			for _, element := range v {
+				if object, isObject := asObject(element); isObject && fmt.Sprint(object[field]) == value {
+					return element, current, true
+				}
+			}
+		case map[string]interface{}, Document:
+			// the filter checks the object of the previous key, the next keys are keys of its parent
+			if object, _ := asObject(current); fmt.Sprint(object[field]) == value && parent != nil {
+				return parent, nil, true
+			}
+		}
+		return nil, nil, false
+	}
+	if parts := searchKeyNameRegex.FindStringSubmatch(key); parts != nil {
+		object, _, found := matchKey(current, parent, parts[1])
+		if !found {
+			return nil, nil, false
+		}
+		return matchKey(object, current, "{{"+strings.Trim(parts[2], "{}")+"}}")
+	}
+	key = strings.TrimSuffix(strings.TrimPrefix(key, "{{"), "}}")
+	if object, isObject := asObject(current); isObject {
+		value, found := object[key]
+		if !found && strings.Contains(key, "=") {
+			// keys with the value of the attribute (ex: acl=public-read)
+			value, found = object[strings.SplitN(key, "=", 2)[0]]
+		}
+		return value, current, found
+	}
+	if list, isList := current.([]interface{}); isList {
+		for _, element := range list {
+			if object, isObject := asObject(element); isObject {
+				if value, found := object[key]; found {
+					return value, element, true
+				}
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// splitSearchKey splits the search key by its dots, except the dots inside {{ }} and [ ]
+func splitSearchKey(searchKey string) []string {
+	keys := make([]string, 0)
+	depth, start := 0, 0
+	for i := 0; i < len(searchKey); i++ {
+		switch searchKey[i] {
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				keys = append(keys, searchKey[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if start < len(searchKey) {
+		keys = append(keys, searchKey[start:])
+	}
+	return keys
+}
+
+func asObject(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case Document:
+		return v, true
+	}
+	return nil, false
+}
+
+// withoutInternalKeys returns a copy of the document without the keys added by KICS
+func withoutInternalKeys(document Document) Document {
+	copied := make(Document, len(document))
+	for key, value := range document {
+		if key != "id" && key != "file" && key != DocumentLinesKey {
+			copied[key] = value
+		}
+	}
+	return copied
+}
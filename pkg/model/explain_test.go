@@ -0,0 +1,147 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestMatchSearchKey tests the functions [MatchSearchKey()] and all the methods called by them
+func TestMatchSearchKey(t *testing.T) {
+	terraform := Document{
+		"resource": map[string]interface{}{
+			"aws_s3_bucket": map[string]interface{}{
+				"logs": map[string]interface{}{"acl": "public-read", "bucket": "logs"},
+			},
+		},
+	}
+	containers := []interface{}{
+		map[string]interface{}{"name": "sidecar"},
+		map[string]interface{}{"name": "app", "securityContext": map[string]interface{}{"privileged": true}},
+	}
+	kubernetes := Document{
+		"kind":     "Deployment",
+		"metadata": map[string]interface{}{"name": "web"},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": containers,
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name        string
+		document    Document
+		searchKey   string
+		wantKeys    string
+		wantSubtree interface{}
+	}{
+		{
+			name:        "terraform resource",
+			document:    terraform,
+			searchKey:   "resource.aws_s3_bucket[logs].acl",
+			wantKeys:    "resource.aws_s3_bucket[logs].acl",
+			wantSubtree: "public-read",
+		},
+		{
+			name:        "terraform resource with braces",
+			document:    terraform,
+			searchKey:   "resource.aws_s3_bucket[{{logs}}]",
+			wantKeys:    "resource.aws_s3_bucket[{{logs}}]",
+			wantSubtree: map[string]interface{}{"acl": "public-read", "bucket": "logs"},
+		},
+		{
+			name:        "terraform resource without resource key",
+			document:    terraform,
+			searchKey:   "aws_s3_bucket[logs].acl=public-read",
+			wantKeys:    "aws_s3_bucket[logs].acl=public-read",
+			wantSubtree: "public-read",
+		},
+		{
+			name:        "no match",
+			document:    terraform,
+			searchKey:   "aws_iam_role[admin]",
+			wantKeys:    "",
+			wantSubtree: terraform,
+		},
+		{
+			name:        "kubernetes filters",
+			document:    kubernetes,
+			searchKey:   "metadata.name={{web}}.spec.template.spec.containers.name={{app}}.securityContext",
+			wantKeys:    "metadata.name={{web}}.spec.template.spec.containers.name={{app}}.securityContext",
+			wantSubtree: map[string]interface{}{"privileged": true},
+		},
+		{
+			name:        "partial match",
+			document:    kubernetes,
+			searchKey:   "metadata.name={{web}}.spec.template.spec.containers.name={{db}}.securityContext",
+			wantKeys:    "metadata.name={{web}}.spec.template.spec.containers",
+			wantSubtree: containers,
+		},
+		{
+			name:        "filter of another object",
+			document:    kubernetes,
+			searchKey:   "metadata.name={{api}}",
+			wantKeys:    "metadata",
+			wantSubtree: map[string]interface{}{"name": "web"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, subtree := MatchSearchKey(tt.document, tt.searchKey)
+			require.Equal(t, tt.wantKeys, strings.Join(keys, "."))
+			require.Equal(t, tt.wantSubtree, subtree)
+		})
+	}
+}
+
+// TestExplain tests the functions [Explain(), String()] and all the methods called by them
+func TestExplain(t *testing.T) {
+	query := &VulnerableQuery{
+		QueryName:   "S3 Bucket ACL Allows Read Or Write to All Users",
+		QueryID:     "38c5ee0d-7f22-4260-ab72-5073048df100",
+		QueryURI:    "https://registry.terraform.io/providers/hashicorp/aws/latest/docs/resources/s3_bucket",
+		Severity:    SeverityHigh,
+		Platform:    "Terraform",
+		Category:    "Access Control",
+		Description: "S3 Buckets should not be readable and writable to all users",
+	}
+	file := &VulnerableFile{
+		FileName:         "main.tf",
+		Line:             3,
+		IssueType:        IssueTypeIncorrectValue,
+		SearchKey:        "resource.aws_s3_bucket[logs].acl",
+		KeyExpectedValue: "'acl' is equal 'private'",
+		KeyActualValue:   "'acl' is equal 'public-read'",
+	}
+	documents := []Document{
+		{"file": "other.tf", "resource": map[string]interface{}{}},
+		{"file": "main.tf", "id": "1", "resource": map[string]interface{}{
+			"aws_s3_bucket": map[string]interface{}{"logs": map[string]interface{}{"acl": "public-read"}},
+		}},
+	}
+
+	explanation := Explain(query, file, documents)
+	require.Equal(t, "resource.aws_s3_bucket[logs].acl", explanation.MatchedKey)
+	require.Equal(t, "public-read", explanation.Subtree)
+	require.Equal(t, Severity(SeverityHigh), explanation.Severity)
+	require.Equal(t, "'acl' is equal 'private'", explanation.ExpectedValue)
+
+	text := explanation.String()
+	require.Contains(t, text, "[HIGH] S3 Bucket ACL Allows Read Or Write to All Users (38c5ee0d-7f22-4260-ab72-5073048df100)\n")
+	require.Contains(t, text, "File: main.tf:3\n")
+	require.Contains(t, text, "Matched resource.aws_s3_bucket[logs].acl:\n\t\"public-read\"\n")
+	require.Contains(t, text, "More information: https://registry.terraform.io/")
+
+	file.SearchKey = "aws_iam_role[admin]"
+	explanation = Explain(query, file, documents)
+	require.Empty(t, explanation.MatchedKey)
+	require.Equal(t, Document{"resource": documents[1]["resource"]}, explanation.Subtree)
+	require.Contains(t, explanation.String(), "Matched document:\n")
+
+	explanation = Explain(query, file, nil)
+	require.Nil(t, explanation.Subtree)
+	require.NotContains(t, explanation.String(), "Matched")
+}
@@ -0,0 +1,120 @@
+package model
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Checkmarx/kics/internal/constants"
+)
+
+// FalsePositiveExport is a bundle of the results of the last scan of a project triaged as false positives,
+// grouped by query, to report the queries to fix upstream or to tune custom queries
+type FalsePositiveExport struct {
+	ProjectID   string               `json:"project_id"`
+	ScanID      string               `json:"scan_id"`
+	ScanTime    time.Time            `json:"scan_time"`
+	GeneratedAt time.Time            `json:"generated_at"`
+	KICSVersion string               `json:"kics_version"`
+	Total       int                  `json:"total"`
+	Queries     []FalsePositiveQuery `json:"queries"`
+}
+
+// FalsePositiveQuery is a query along with its results triaged as false positives
+type FalsePositiveQuery struct {
+	QueryID   string                `json:"query_id"`
+	QueryName string                `json:"query_name"`
+	Platform  string                `json:"platform"`
+	Category  string                `json:"category"`
+	Severity  Severity              `json:"severity"`
+	Results   []FalsePositiveResult `json:"results"`
+}
+
+// FalsePositiveResult is a result triaged as false positive along with the snippet of its file
+type FalsePositiveResult struct {
+	SimilarityID  string        `json:"similarity_id"`
+	FileName      string        `json:"file_name"`
+	Line          int           `json:"line"`
+	IssueType     IssueType     `json:"issue_type"`
+	SearchKey     string        `json:"search_key"`
+	ExpectedValue string        `json:"expected_value"`
+	ActualValue   string        `json:"actual_value"`
+	Snippet       []SnippetLine `json:"snippet"`
+	Assignee      string        `json:"assignee,omitempty"`
+	TriagedAt     time.Time     `json:"triaged_at"`
+}
+
+// SnippetLine is a line of the snippet of the file of a result
+type SnippetLine struct {
+	Line int    `json:"line"`
+	Code string `json:"code"`
+}
+
+// NewFalsePositiveExport returns the results of the scan triaged as false positives, the queries are sorted by ID
+// and their results by file name and line
+func NewFalsePositiveExport(projectID string, scan *ScanRecord, vulnerabilities []Vulnerability,
+	triages map[string]Triage) *FalsePositiveExport {
+	export := &FalsePositiveExport{
+		ProjectID:   projectID,
+		ScanID:      scan.ID,
+		ScanTime:    scan.Time,
+		GeneratedAt: time.Now(),
+		KICSVersion: constants.Version,
+		Queries:     []FalsePositiveQuery{},
+	}
+	queries := make(map[string]*FalsePositiveQuery)
+	for i := range vulnerabilities {
+		vulnerability := &vulnerabilities[i]
+		triage, ok := triages[vulnerability.SimilarityID]
+		if !ok || vulnerability.SimilarityID == "" || triage.State != TriageFalsePositive {
+			continue
+		}
+		query, ok := queries[vulnerability.QueryID]
+		if !ok {
+			query = &FalsePositiveQuery{
+				QueryID:   vulnerability.QueryID,
+				QueryName: vulnerability.QueryName,
+				Platform:  vulnerability.Platform,
+				Category:  vulnerability.Category,
+				Severity:  vulnerability.Severity,
+			}
+			queries[vulnerability.QueryID] = query
+		}
+		query.Results = append(query.Results, FalsePositiveResult{
+			SimilarityID:  vulnerability.SimilarityID,
+			FileName:      vulnerability.FileName,
+			Line:          vulnerability.Line,
+			IssueType:     vulnerability.IssueType,
+			SearchKey:     vulnerability.SearchKey,
+			ExpectedValue: vulnerability.KeyExpectedValue,
+			ActualValue:   vulnerability.KeyActualValue,
+			Snippet:       snippet(vulnerability.VulnLines),
+			Assignee:      triage.Assignee,
+			TriagedAt:     triage.UpdatedAt,
+		})
+		export.Total++
+	}
+	for _, query := range queries {
+		sort.Slice(query.Results, func(i, j int) bool {
+			if query.Results[i].FileName != query.Results[j].FileName {
+				return query.Results[i].FileName < query.Results[j].FileName
+			}
+			return query.Results[i].Line < query.Results[j].Line
+		})
+		export.Queries = append(export.Queries, *query)
+	}
+	sort.Slice(export.Queries, func(i, j int) bool {
+		return export.Queries[i].QueryID < export.Queries[j].QueryID
+	})
+	return export
+}
+
+// snippet returns the lines of the file adjacent to the line of a result
+func snippet(vulnLines VulnLines) []SnippetLine {
+	lines := make([]SnippetLine, 0, len(vulnLines.Lines))
+	for i, code := range vulnLines.Lines {
+		if i < len(vulnLines.Positions) {
+			lines = append(lines, SnippetLine{Line: vulnLines.Positions[i], Code: code})
+		}
+	}
+	return lines
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewFalsePositiveExport tests the functions [NewFalsePositiveExport()] and all the methods called by them
+func TestNewFalsePositiveExport(t *testing.T) {
+	scanTime := time.Date(2021, 7, 16, 10, 0, 0, 0, time.UTC)
+	triagedAt := scanTime.Add(time.Hour)
+	vulnerabilities := []Vulnerability{
+		{
+			SimilarityID: "b", QueryID: "q2", QueryName: "S3 Bucket Public", Platform: "Terraform", Severity: SeverityHigh,
+			FileName: "s3.tf", Line: 9, SearchKey: "aws_s3_bucket[b].acl",
+		},
+		{
+			SimilarityID: "a", QueryID: "q2", QueryName: "S3 Bucket Public", Platform: "Terraform", Severity: SeverityHigh,
+			FileName: "s3.tf", Line: 3, SearchKey: "aws_s3_bucket[a].acl", KeyExpectedValue: "private", KeyActualValue: "public-read",
+			VulnLines: VulnLines{Positions: []int{2, 3, 4}, Lines: []string{`resource "aws_s3_bucket" "a" {`, `  acl = "public-read"`, "}"}},
+		},
+		{SimilarityID: "c", QueryID: "q1", QueryName: "Privileged Container", FileName: "pod.yaml", Line: 5},
+		{SimilarityID: "acknowledged", QueryID: "q3", FileName: "main.tf"},
+		{SimilarityID: "untriaged", QueryID: "q4", FileName: "main.tf"},
+	}
+	triages := map[string]Triage{
+		"a":            {State: TriageFalsePositive, Assignee: "alice", UpdatedAt: triagedAt},
+		"b":            {State: TriageFalsePositive, UpdatedAt: triagedAt},
+		"c":            {State: TriageFalsePositive, UpdatedAt: triagedAt},
+		"acknowledged": {State: TriageAcknowledged, UpdatedAt: triagedAt},
+	}
+
+	export := NewFalsePositiveExport("project", &ScanRecord{ID: "scan", Time: scanTime}, vulnerabilities, triages)
+	require.Equal(t, "project", export.ProjectID)
+	require.Equal(t, "scan", export.ScanID)
+	require.Equal(t, scanTime, export.ScanTime)
+	require.Equal(t, 3, export.Total)
+	require.Len(t, export.Queries, 2)
+	require.Equal(t, "q1", export.Queries[0].QueryID)
+	query := export.Queries[1]
+	require.Equal(t, "q2", query.QueryID)
+	require.Equal(t, Severity(SeverityHigh), query.Severity)
+	require.Len(t, query.Results, 2)
+	require.Equal(t, FalsePositiveResult{
+		SimilarityID:  "a",
+		FileName:      "s3.tf",
+		Line:          3,
+		SearchKey:     "aws_s3_bucket[a].acl",
+		ExpectedValue: "private",
+		ActualValue:   "public-read",
+		Snippet: []SnippetLine{
+			{Line: 2, Code: `resource "aws_s3_bucket" "a" {`},
+			{Line: 3, Code: `  acl = "public-read"`},
+			{Line: 4, Code: "}"},
+		},
+		Assignee:  "alice",
+		TriagedAt: triagedAt,
+	}, query.Results[0])
+	require.Equal(t, "b", query.Results[1].SimilarityID)
+	require.Empty(t, query.Results[1].Snippet)
+
+	export = NewFalsePositiveExport("project", &ScanRecord{ID: "scan"}, vulnerabilities, nil)
+	require.Equal(t, 0, export.Total)
+	require.Empty(t, export.Queries)
+}
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ScanRecord is a scan kept by the storages with the history of the scans of each project
+// scans that failed or are incomplete are not successful and are not compared with the next scans
+type ScanRecord struct {
+	ID        string    `json:"id"`
+	ProjectID string    `json:"project_id"`
+	Time      time.Time `json:"time"`
+	Success   bool      `json:"success"`
+}
+
+// ScanComparison compares the results of a scan with the ones of the last successful scan of the project,
+// NewResults are the similarity IDs of the results not found in the last scan
+type ScanComparison struct {
+	ProjectID           string           `json:"project_id"`
+	LastScanID          string           `json:"last_scan_id,omitempty"`
+	NewResults          []string         `json:"new_results"`
+	NewSeverityCounters map[Severity]int `json:"new_severity_counters"`
+}
+
+// NewScanComparison compares the vulnerabilities of a scan with the previous ones of the last successful scan
+// of the project, without last scan no result is new and results without similarity ID are never new
+func NewScanComparison(projectID string, lastScan *ScanRecord, previous, current []Vulnerability) *ScanComparison {
+	comparison := &ScanComparison{
+		ProjectID:           projectID,
+		NewResults:          []string{},
+		NewSeverityCounters: newSeverityCounters(),
+	}
+	if lastScan == nil {
+		return comparison
+	}
+	comparison.LastScanID = lastScan.ID
+	known := make(map[string]bool, len(previous))
+	for i := range previous {
+		known[previous[i].SimilarityID] = true
+	}
+	for i := range current {
+		similarityID := current[i].SimilarityID
+		if similarityID == "" || known[similarityID] {
+			continue
+		}
+		known[similarityID] = true
+		comparison.NewResults = append(comparison.NewResults, similarityID)
+		comparison.NewSeverityCounters[current[i].Severity]++
+	}
+	return comparison
+}
+
+// HasNewResults returns true if there are new results with the severity given or above
+func (c *ScanComparison) HasNewResults(threshold Severity) bool {
+	for severity, count := range c.NewSeverityCounters {
+		if count > 0 && severity.AtLeast(threshold) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewProjectID returns the project of the scans of a subdirectory of a branch of a git repository, so they are
+// grouped whichever the clone or the address of the repository (ex: github.com/org/repo//infra@main), the repository
+// is the address of its remote without scheme, credentials and .git suffix, without branch, such as in a detached HEAD,
+// the scans of all the branches are grouped
+func NewProjectID(repository, branch, subdir string) string {
+	repository = strings.TrimSpace(repository)
+	if i := strings.Index(repository, "://"); i >= 0 {
+		repository = repository[i+3:]
+	} else if i := strings.Index(repository, ":"); i >= 0 && !strings.Contains(repository[:i], "/") {
+		// scp-like address, such as git@github.com:org/repo.git
+		repository = repository[:i] + "/" + repository[i+1:]
+	}
+	if i := strings.LastIndex(repository, "@"); i >= 0 && i < strings.Index(repository+"/", "/") {
+		repository = repository[i+1:]
+	}
+	repository = strings.TrimSuffix(strings.TrimSuffix(repository, "/"), ".git")
+	if host := strings.Index(repository+"/", "/"); host >= 0 {
+		repository = strings.ToLower(repository[:host]) + repository[host:]
+	}
+	projectID := repository
+	if subdir = strings.Trim(filepath.ToSlash(subdir), "/"); subdir != "" && subdir != "." {
+		projectID += "//" + subdir
+	}
+	if branch != "" && branch != "HEAD" {
+		projectID += "@" + branch
+	}
+	return projectID
+}
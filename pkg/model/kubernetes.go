@@ -0,0 +1,33 @@
+package model
+
+// KubernetesResource is the Kubernetes resource declared by a document, the namespace is empty
+// when the document does not set it
+type KubernetesResource struct {
+	APIVersion string `json:"api_version"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+}
+
+// NewKubernetesResource returns the Kubernetes resource declared by the document, nil when the document is not
+// a Kubernetes resource, lists of resources are not a resource
+func NewKubernetesResource(document Document) *KubernetesResource {
+	apiVersion, ok := document["apiVersion"].(string)
+	if !ok {
+		return nil
+	}
+	kind, ok := document["kind"].(string)
+	if !ok || kind == "" || kind == "List" {
+		return nil
+	}
+	metadata, ok := document["metadata"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	name, ok := metadata["name"].(string)
+	if !ok {
+		return nil
+	}
+	namespace, _ := metadata["namespace"].(string)
+	return &KubernetesResource{APIVersion: apiVersion, Kind: kind, Name: name, Namespace: namespace}
+}
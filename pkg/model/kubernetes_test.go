@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewKubernetesResource tests the functions [NewKubernetesResource()] and all the methods called by them
+func TestNewKubernetesResource(t *testing.T) {
+	tests := []struct {
+		name     string
+		document Document
+		want     *KubernetesResource
+	}{
+		{
+			name: "namespaced",
+			document: Document{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]interface{}{"name": "api", "namespace": "payments"},
+			},
+			want: &KubernetesResource{APIVersion: "apps/v1", Kind: "Deployment", Name: "api", Namespace: "payments"},
+		},
+		{
+			name:     "without_namespace",
+			document: Document{"apiVersion": "v1", "kind": "Namespace", "metadata": map[string]interface{}{"name": "payments"}},
+			want:     &KubernetesResource{APIVersion: "v1", Kind: "Namespace", Name: "payments"},
+		},
+		{
+			name:     "list",
+			document: Document{"apiVersion": "v1", "kind": "List", "metadata": map[string]interface{}{"name": "items"}},
+		},
+		{
+			name:     "not_kubernetes",
+			document: Document{"resource": map[string]interface{}{"aws_s3_bucket": map[string]interface{}{}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, NewKubernetesResource(tt.document))
+		})
+	}
+}
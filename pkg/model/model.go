@@ -4,20 +4,47 @@ package model
 import (
 	"sort"
 	"strings"
+	"time"
 
 	_ "github.com/mailru/easyjson/gen" //nolint
 )
 
 // Constants to describe what kind of file refers
 const (
-	KindTerraform FileKind = "TF"
-	KindJSON      FileKind = "JSON"
-	KindYAML      FileKind = "YAML"
-	KindDOCKER    FileKind = "DOCKERFILE"
-	KindCOMMON    FileKind = "*"
-	KindHELM      FileKind = "HELM"
+	KindTerraform  FileKind = "TF"
+	KindJSON       FileKind = "JSON"
+	KindYAML       FileKind = "YAML"
+	KindDOCKER     FileKind = "DOCKERFILE"
+	KindCOMMON     FileKind = "*"
+	KindHELM       FileKind = "HELM"
+	KindPUPPET     FileKind = "PUPPET"
+	KindSALT       FileKind = "SALT"
+	KindARM        FileKind = "ARM"
+	KindGDM        FileKind = "GDM"
+	KindKustomize  FileKind = "KUSTOMIZE"
+	KindTerragrunt FileKind = "TERRAGRUNT"
+	KindBICEP      FileKind = "BICEP"
 )
 
+// ModuleCallChainKey is the document key holding the module call chain (root, module.a, module.b)
+// of files that belong to a Terraform module installed under .terraform/modules
+const ModuleCallChainKey = "_kics_module_call_chain"
+
+// ModuleAddressPrefix prefixes each module of the address of a Terraform module (ex: module.vpc.module.subnets)
+const ModuleAddressPrefix = "module."
+
+// DocumentLinesKey is the document key holding the range of lines [start, end) of a document, zero based,
+// in files with several documents, so lines are only detected inside the document
+const DocumentLinesKey = "_kics_lines"
+
+// SourceLinesKey is the document key holding the lines, one based, of the paths of a document converted from another
+// format (ex: resources.0.properties for the ARM templates of Bicep files), so lines are detected in the source file
+const SourceLinesKey = "_kics_source_lines"
+
+// DuplicateKeysKey is the document key holding the keys defined more than once in the same object of the document,
+// the last definition is the one kept in the document
+const DuplicateKeysKey = "_kics_duplicate_keys"
+
 // Constants to describe vulnerability's severity
 const (
 	SeverityHigh   = "HIGH"
@@ -55,6 +82,19 @@ type FileKind string
 // Severity of the vulnerability
 type Severity string
 
+// AtLeast returns true if the severity is the same or more severe than the threshold given
+func (s Severity) AtLeast(threshold Severity) bool {
+	rank := func(severity Severity) int {
+		for i, si := range AllSeverities {
+			if si == severity {
+				return i
+			}
+		}
+		return len(AllSeverities)
+	}
+	return rank(s) <= rank(threshold)
+}
+
 // IssueType is the issue's type string representation
 type IssueType string
 
@@ -64,7 +104,8 @@ type VulnLines struct {
 	Lines     []string
 }
 
-// FileMetadata is a representation of basic information and content of a file
+// FileMetadata is a representation of basic information and content of a file, Redacted is set on the files saved
+// with their content and document hashed, which can't be inspected again
 type FileMetadata struct {
 	ID           string `db:"id"`
 	ScanID       string `db:"scan_id"`
@@ -73,8 +114,15 @@ type FileMetadata struct {
 	Kind         FileKind `db:"kind"`
 	FileName     string   `db:"file_name"`
 	Content      string
-	HelmID       string
-	IDInfo       map[int]interface{}
+	Origin       *Origin
+	Redacted     bool
+}
+
+// DuplicateKey is a key defined more than once in the same object of a document
+// SearchKey is the path of the key (keys joined by dots) and Line is the one based line of its last definition
+type DuplicateKey struct {
+	SearchKey string `json:"searchKey"`
+	Line      int    `json:"line"`
 }
 
 // QueryMetadata is a representation of general information about a query
@@ -91,27 +139,46 @@ type QueryMetadata struct {
 // Vulnerability is a representation of a detected vulnerability in scanned files
 // after running a query
 type Vulnerability struct {
-	ID               int       `json:"id"`
-	ScanID           string    `db:"scan_id" json:"-"`
-	SimilarityID     string    `db:"similarity_id" json:"similarityID"`
-	FileID           string    `db:"file_id" json:"-"`
-	FileName         string    `db:"file_name" json:"fileName"`
-	QueryID          string    `db:"query_id" json:"queryID"`
-	QueryName        string    `db:"query_name" json:"queryName"`
-	QueryURI         string    `json:"-"`
-	Category         string    `json:"category"`
-	Description      string    `json:"description"`
-	Platform         string    `db:"platform" json:"platform"`
-	Severity         Severity  `json:"severity"`
-	Line             int       `json:"line"`
-	VulnLines        VulnLines `json:"vulnLines"`
-	IssueType        IssueType `db:"issue_type" json:"issueType"`
-	SearchKey        string    `db:"search_key" json:"searchKey"`
-	SearchValue      string    `db:"search_value" json:"searchValue"`
-	KeyExpectedValue string    `db:"key_expected_value" json:"expectedValue"`
-	KeyActualValue   string    `db:"key_actual_value" json:"actualValue"`
-	Value            *string   `db:"value" json:"value"`
-	Output           string    `json:"-"`
+	ID                 int                 `json:"id"`
+	ScanID             string              `db:"scan_id" json:"-"`
+	SimilarityID       string              `db:"similarity_id" json:"similarityID"`
+	FileID             string              `db:"file_id" json:"-"`
+	FileName           string              `db:"file_name" json:"fileName"`
+	QueryID            string              `db:"query_id" json:"queryID"`
+	QueryName          string              `db:"query_name" json:"queryName"`
+	QueryURI           string              `json:"-"`
+	Category           string              `json:"category"`
+	Description        string              `json:"description"`
+	Platform           string              `db:"platform" json:"platform"`
+	Severity           Severity            `json:"severity"`
+	Line               int                 `json:"line"`
+	VulnLines          VulnLines           `json:"vulnLines"`
+	IssueType          IssueType           `db:"issue_type" json:"issueType"`
+	SearchKey          string              `db:"search_key" json:"searchKey"`
+	SearchValue        string              `db:"search_value" json:"searchValue"`
+	KeyExpectedValue   string              `db:"key_expected_value" json:"expectedValue"`
+	KeyActualValue     string              `db:"key_actual_value" json:"actualValue"`
+	Value              *string             `db:"value" json:"value"`
+	ModuleCallChain    []string            `db:"module_call_chain" json:"moduleCallChain,omitempty"`
+	Locations          []Location          `json:"locations,omitempty"`
+	OriginChain        []OriginStep        `json:"originChain,omitempty"`
+	KubernetesResource *KubernetesResource `json:"kubernetesResource,omitempty"`
+	Environment        string              `json:"environment,omitempty"`
+	FirstSeen          *time.Time          `json:"firstSeen,omitempty"`
+	LastSeen           *time.Time          `json:"lastSeen,omitempty"`
+	TriageState        TriageState         `json:"triageState,omitempty"`
+	Assignee           string              `json:"assignee,omitempty"`
+	Suppression        *Suppression        `json:"suppression,omitempty"`
+	Output             string              `json:"-"`
+}
+
+// Location is a location a result was found at, results found both in a document rendered by a resolver and in
+// the original file scanned directly are reported once with the location of each
+type Location struct {
+	FileName  string `json:"file_name"`
+	Line      int    `json:"line"`
+	SearchKey string `json:"search_key"`
+	Rendered  bool   `json:"rendered"`
 }
 
 // QueryConfig is a struct that contains the fileKind and platform of the rego query
@@ -130,8 +197,7 @@ type ResolvedFile struct {
 	FileName     string
 	Content      []byte
 	OriginalData []byte
-	SplitID      string
-	IDInfo       map[int]interface{}
+	Origin       *Origin
 }
 
 // Extensions represents a list of supported extensions
@@ -75,3 +75,11 @@ func TestFileMetadatas(t *testing.T) {
 		require.Equal(t, Documents{Documents: []Document{}}, result)
 	})
 }
+
+// TestSeverity_AtLeast tests the functions [AtLeast()]
+func TestSeverity_AtLeast(t *testing.T) {
+	require.True(t, Severity(SeverityHigh).AtLeast(SeverityMedium))
+	require.True(t, Severity(SeverityMedium).AtLeast(SeverityMedium))
+	require.False(t, Severity(SeverityLow).AtLeast(SeverityMedium))
+	require.False(t, Severity("UNKNOWN").AtLeast(SeverityInfo))
+}
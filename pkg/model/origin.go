@@ -0,0 +1,79 @@
+package model
+
+// OriginKind is the kind of a step of the origin chain of a resolved document
+type OriginKind string
+
+// Constants to describe the steps of the origin chains
+const (
+	OriginChart          OriginKind = "chart"
+	OriginTemplate       OriginKind = "template"
+	OriginPostRenderer   OriginKind = "post_renderer"
+	OriginNestedTemplate OriginKind = "nested_template"
+	OriginLinkedTemplate OriginKind = "linked_template"
+	OriginOverlay        OriginKind = "overlay"
+	OriginBase           OriginKind = "base"
+	OriginModuleCall     OriginKind = "module_call"
+	OriginModule         OriginKind = "module"
+	OriginTerragrunt     OriginKind = "terragrunt"
+)
+
+// OriginStep is a file a resolved document comes from
+type OriginStep struct {
+	Kind     OriginKind `json:"kind"`
+	FileName string     `json:"file_name"`
+}
+
+// Origin is where a document resolved by a resolver comes from, Chain goes from the file resolved (ex: a Helm chart)
+// to the file the document is attributed to (ex: a template of the chart)
+// SplitID and Lines map the lines of a document split from a rendered output to the lines of the original file
+// (ex: the auxiliary "# KICS_HELM_ID_" comments of Helm templates), they are empty when the lines are not mapped,
+// for the files of Terraform modules resolved SplitID is the address of the module (ex: module.vpc.module.subnets),
+// empty for the module deployed by a Terragrunt configuration, and for the resources rendered by kustomize it is
+// the ID of the resource (ex: Deployment|dev|dev-app)
+// DocumentLines is the range of lines [start, end) of the document in the original file, zero based, when the file
+// has several documents (ex: the resources of a kustomization), so lines are only detected inside the document
+type Origin struct {
+	Chain         []OriginStep
+	SplitID       string
+	Lines         map[int]interface{}
+	DocumentLines []int
+}
+
+// ExtendChain returns a copy of the chain with the step appended, so the chains of sibling documents do not share steps
+func ExtendChain(chain []OriginStep, kind OriginKind, fileName string) []OriginStep {
+	extended := make([]OriginStep, len(chain), len(chain)+1)
+	copy(extended, chain)
+	return append(extended, OriginStep{Kind: kind, FileName: fileName})
+}
+
+// GetChain returns the origin chain, nil for documents that were not resolved
+func (o *Origin) GetChain() []OriginStep {
+	if o == nil {
+		return nil
+	}
+	return o.Chain
+}
+
+// GetSplitID returns the ID of the document split from a rendered output, empty when the lines are not mapped
+func (o *Origin) GetSplitID() string {
+	if o == nil {
+		return ""
+	}
+	return o.SplitID
+}
+
+// GetLines returns the lines of the original file by split ID, nil when the lines are not mapped
+func (o *Origin) GetLines() map[int]interface{} {
+	if o == nil {
+		return nil
+	}
+	return o.Lines
+}
+
+// GetDocumentLines returns the range of lines of the document in the original file, nil when it is not known
+func (o *Origin) GetDocumentLines() []int {
+	if o == nil {
+		return nil
+	}
+	return o.DocumentLines
+}
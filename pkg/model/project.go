@@ -0,0 +1,113 @@
+package model
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// noProject is the project of the files that are not in a project discovered
+const noProject = "(none)"
+
+// DiscoveredProject is an independent IaC project found in the scanned path, such as a Terraform root module
+// or a Helm chart, Path is relative to the scanned path
+type DiscoveredProject struct {
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+}
+
+// ProjectSummary is the summary of the scan of a project of a multi-project scan, such as a repository of an organization,
+// the file names of its results are relative to the project and prefixed with its name, Error is why the project
+// could not be scanned, if so
+type ProjectSummary struct {
+	Name   string `json:"name"`
+	Source string `json:"source,omitempty"`
+	Counters
+	SeveritySummary
+	Queries    VulnerableQuerySlice `json:"queries"`
+	Incomplete bool                 `json:"incomplete,omitempty"`
+	ExitCode   int                  `json:"exit_code"`
+	Error      string               `json:"error,omitempty"`
+}
+
+// MultiProjectSummary is the report of a multi-project scan, with the summary of each project
+// and the severity counters of the results of all of them
+type MultiProjectSummary struct {
+	Source           string           `json:"source"`
+	Projects         []ProjectSummary `json:"projects"`
+	SeverityCounters map[Severity]int `json:"severity_counters"`
+	TotalCounter     int              `json:"total_counter"`
+	FailedProjects   int              `json:"failed_projects"`
+}
+
+// NewMultiProjectSummary creates the report of the multi-project scan of the source
+func NewMultiProjectSummary(source string) *MultiProjectSummary {
+	return &MultiProjectSummary{
+		Source:           source,
+		Projects:         make([]ProjectSummary, 0),
+		SeverityCounters: newSeverityCounters(),
+	}
+}
+
+// NewProjectSummary creates the summary of a project from the summary of its scan, the file names of the results
+// under the base path, where the project was scanned, are made relative to it and prefixed with the name of the project
+func NewProjectSummary(name, source, basePath string, summary *Summary) ProjectSummary {
+	queries := make(VulnerableQuerySlice, 0, len(summary.Queries))
+	for i := range summary.Queries {
+		query := summary.Queries[i]
+		query.Files = make([]VulnerableFile, len(summary.Queries[i].Files))
+		for j := range summary.Queries[i].Files {
+			query.Files[j] = summary.Queries[i].Files[j]
+			query.Files[j].FileName = projectFileName(name, basePath, query.Files[j].FileName)
+		}
+		queries = append(queries, query)
+	}
+	return ProjectSummary{
+		Name:            name,
+		Source:          source,
+		Counters:        summary.Counters,
+		SeveritySummary: summary.SeveritySummary,
+		Queries:         queries,
+		Incomplete:      summary.Incomplete,
+	}
+}
+
+// Add adds the summary of a project to the report
+func (s *MultiProjectSummary) Add(project *ProjectSummary) {
+	if project.Error != "" {
+		s.FailedProjects++
+	}
+	for severity, counter := range project.SeverityCounters {
+		s.SeverityCounters[severity] += counter
+	}
+	s.TotalCounter += project.TotalCounter
+	s.Projects = append(s.Projects, *project)
+}
+
+func projectFileName(name, basePath, fileName string) string {
+	rel, err := filepath.Rel(basePath, fileName)
+	if basePath == "" || err != nil || strings.HasPrefix(rel, "..") {
+		return fileName
+	}
+	return filepath.ToSlash(filepath.Join(name, rel))
+}
+
+// projectOf returns the path of the deepest project containing the file, (none) for files of no project
+func projectOf(basePath, fileName string, projects []DiscoveredProject) string {
+	relative := filepath.ToSlash(fileName)
+	if basePath != "" {
+		if rel, err := filepath.Rel(basePath, fileName); err == nil && !strings.HasPrefix(filepath.ToSlash(rel), "../") {
+			relative = filepath.ToSlash(rel)
+		}
+	}
+	project := noProject
+	for i := range projects {
+		path := projects[i].Path
+		if path == "." && project == noProject {
+			project = path
+		}
+		if strings.HasPrefix(relative, path+"/") && (project == noProject || project == "." || len(path) > len(project)) {
+			project = path
+		}
+	}
+	return project
+}
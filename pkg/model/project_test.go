@@ -0,0 +1,58 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestMultiProjectSummary tests the functions [NewProjectSummary()], [MultiProjectSummary.Add()] and all the methods called by them
+func TestMultiProjectSummary(t *testing.T) {
+	summary := CreateSummary(Counters{ScannedFiles: 2}, []Vulnerability{
+		{QueryID: "q1", QueryName: "query 1", Severity: SeverityHigh, FileName: "/cache/git/abc/main.tf", Line: 3},
+		{QueryID: "q2", QueryName: "query 2", Severity: SeverityLow, FileName: "/other/main.tf", Line: 5},
+	}, "scan")
+
+	project := NewProjectSummary("my-org/infra", "https://github.com/my-org/infra.git", "/cache/git/abc", &summary)
+	require.Equal(t, "my-org/infra/main.tf", project.Queries[0].Files[0].FileName)
+	require.Equal(t, "/other/main.tf", project.Queries[1].Files[0].FileName, "files outside the project are kept as they are")
+	require.Equal(t, "/cache/git/abc/main.tf", summary.Queries[0].Files[0].FileName, "the summary of the scan is not changed")
+	require.Equal(t, 2, project.ScannedFiles)
+
+	report := NewMultiProjectSummary("org::https://github.com/my-org")
+	report.Add(&project)
+	report.Add(&ProjectSummary{Name: "my-org/broken", Error: "failed to fetch", ExitCode: 1})
+	report.Add(&project)
+	require.Len(t, report.Projects, 3)
+	require.Equal(t, 4, report.TotalCounter)
+	require.Equal(t, 2, report.SeverityCounters[SeverityHigh])
+	require.Equal(t, 0, report.SeverityCounters[SeverityMedium])
+	require.Equal(t, 1, report.FailedProjects)
+}
+
+// TestSeveritySummary_AddBreakdownByProject tests the function [SeveritySummary.AddBreakdown()] by project
+func TestSeveritySummary_AddBreakdownByProject(t *testing.T) {
+	vulnerabilities := []Vulnerability{
+		{FileName: "/repo/envs/prod/main.tf", Severity: SeverityHigh},
+		{FileName: "/repo/envs/prod/network/vpc.tf", Severity: SeverityLow},
+		{FileName: "/repo/charts/api/templates/deployment.yaml", Severity: SeverityHigh},
+		{FileName: "/repo/scripts/Dockerfile", Severity: SeverityMedium},
+	}
+	breakdown := SeverityBreakdown{
+		ByProject: true,
+		BasePath:  "/repo",
+		Projects:  []DiscoveredProject{{Path: "charts/api", Kind: "helm"}, {Path: "envs/prod", Kind: "terraform"}},
+	}
+
+	summary := NewSeveritySummary("scan", vulnerabilities, breakdown)
+	require.Equal(t, breakdown.Projects, summary.Projects)
+	require.Equal(t, 1, summary.SeverityCountersByProject["envs/prod"][SeverityHigh])
+	require.Equal(t, 1, summary.SeverityCountersByProject["envs/prod"][SeverityLow])
+	require.Equal(t, 1, summary.SeverityCountersByProject["charts/api"][SeverityHigh])
+	require.Equal(t, 1, summary.SeverityCountersByProject["(none)"][SeverityMedium])
+
+	breakdown.Projects = append(breakdown.Projects, DiscoveredProject{Path: ".", Kind: "terraform"})
+	summary = NewSeveritySummary("scan", vulnerabilities, breakdown)
+	require.Equal(t, 1, summary.SeverityCountersByProject["."][SeverityMedium])
+	require.Equal(t, 2, summary.SeverityCountersByProject["envs/prod"][SeverityHigh]+summary.SeverityCountersByProject["envs/prod"][SeverityLow])
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+)
+
+// ReasonCode is the machine-readable reason of a file that could not be scanned
+type ReasonCode string
+
+// Constants to describe the reason codes of the files that could not be scanned
+const (
+	ReasonFileTooLarge ReasonCode = "FILE_TOO_LARGE"
+	ReasonParseError   ReasonCode = "PARSE_ERROR"
+	ReasonRenderError  ReasonCode = "RENDER_ERROR"
+	ReasonTimeout      ReasonCode = "TIMEOUT"
+	ReasonMarshalError ReasonCode = "MARSHAL_ERROR"
+)
+
+// FileError is an error of a file with the reason code of the failure
+type FileError struct {
+	Code ReasonCode
+	Err  error
+}
+
+// NewFileError returns the error err of a file with the reason code
+func NewFileError(code ReasonCode, err error) *FileError {
+	return &FileError{Code: code, Err: err}
+}
+
+func (e *FileError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error of the file
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// ReasonCodeOf returns the reason code of the error of a file, the code of the FileError it wraps, TIMEOUT for
+// the errors of deadlines exceeded, MARSHAL_ERROR for the errors of JSON encoding, or fallback otherwise
+func ReasonCodeOf(err error, fallback ReasonCode) ReasonCode {
+	var fileError *FileError
+	if errors.As(err, &fileError) {
+		return fileError.Code
+	}
+	if errors.Is(err, context.DeadlineExceeded) || os.IsTimeout(err) {
+		return ReasonTimeout
+	}
+	var marshalerError *json.MarshalerError
+	var unsupportedTypeError *json.UnsupportedTypeError
+	var unsupportedValueError *json.UnsupportedValueError
+	if errors.As(err, &marshalerError) || errors.As(err, &unsupportedTypeError) || errors.As(err, &unsupportedValueError) {
+		return ReasonMarshalError
+	}
+	return fallback
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestReasonCodeOf tests the functions [ReasonCodeOf()] and all the methods called by them
+func TestReasonCodeOf(t *testing.T) {
+	_, marshalErr := json.Marshal(map[string]interface{}{"value": func() {}})
+	tests := []struct {
+		name string
+		err  error
+		want ReasonCode
+	}{
+		{
+			name: "file error",
+			err:  fmt.Errorf("failed to get file content: %w", NewFileError(ReasonFileTooLarge, errors.New("file size limit exceeded"))),
+			want: ReasonFileTooLarge,
+		},
+		{name: "deadline exceeded", err: fmt.Errorf("failed to render: %w", context.DeadlineExceeded), want: ReasonTimeout},
+		{name: "marshal error", err: marshalErr, want: ReasonMarshalError},
+		{name: "other error", err: errors.New("invalid yaml"), want: ReasonParseError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ReasonCodeOf(tt.err, ReasonParseError))
+		})
+	}
+	require.Equal(t, "file size limit exceeded", NewFileError(ReasonFileTooLarge, errors.New("file size limit exceeded")).Error())
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"path/filepath"
 
 	"github.com/Checkmarx/kics/internal/constants"
@@ -30,6 +31,7 @@ type ruleMetadata struct {
 	queryDescription string
 	queryURI         string
 	queryCategory    string
+	queryPlatform    string
 	severity         Severity
 }
 
@@ -37,6 +39,15 @@ type sarifMessage struct {
 	Text string `json:"text"`
 }
 
+type sarifMultiformatMessage struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown"`
+}
+
+type sarifRuleProperties struct {
+	Tags []string `json:"tags"`
+}
+
 type sarifComponentReference struct {
 	ComponentReferenceName  string `json:"name"`
 	ComponentReferenceGUID  string `json:"guid"`
@@ -63,8 +74,10 @@ type sarifRule struct {
 	RuleShortDescription sarifMessage                  `json:"shortDescription"`
 	RuleFullDescription  sarifMessage                  `json:"fullDescription"`
 	DefaultConfiguration sarifConfiguration            `json:"defaultConfiguration"`
+	Help                 sarifMultiformatMessage       `json:"help"`
 	HelpURI              string                        `json:"helpUri"`
 	RuleRelationships    []sarifDescriptorRelationship `json:"relationships"`
+	Properties           sarifRuleProperties           `json:"properties"`
 }
 
 type sarifDriver struct {
@@ -96,12 +109,18 @@ type sarifLocation struct {
 	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
 }
 
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
 type sarifResult struct {
-	ResultRuleID    string          `json:"ruleId"`
-	ResultRuleIndex int             `json:"ruleIndex"`
-	ResultKind      string          `json:"kind"`
-	ResultMessage   sarifMessage    `json:"message"`
-	ResultLocations []sarifLocation `json:"locations"`
+	ResultRuleID       string             `json:"ruleId"`
+	ResultRuleIndex    int                `json:"ruleIndex"`
+	ResultKind         string             `json:"kind"`
+	ResultMessage      sarifMessage       `json:"message"`
+	ResultLocations    []sarifLocation    `json:"locations"`
+	ResultSuppressions []sarifSuppression `json:"suppressions,omitempty"`
 }
 
 type sarifTaxanomyDefinition struct {
@@ -238,7 +257,9 @@ func (sr *sarifReport) buildRule(queryMetadata *ruleMetadata) int {
 			RuleFullDescription:  sarifMessage{Text: queryMetadata.queryDescription},
 			DefaultConfiguration: sarifConfiguration{Level: severityLevelEquivalence[queryMetadata.severity]},
 			RuleRelationships:    []sarifDescriptorRelationship{{Target: sr.buildCategory(queryMetadata.queryCategory)}},
+			Help:                 buildHelp(queryMetadata, helpURI),
 			HelpURI:              helpURI,
+			Properties:           sarifRuleProperties{Tags: buildTags(queryMetadata)},
 		}
 
 		sr.Runs[0].Tool.Driver.Rules = append(sr.Runs[0].Tool.Driver.Rules, rule)
@@ -247,6 +268,28 @@ func (sr *sarifReport) buildRule(queryMetadata *ruleMetadata) int {
 	return index
 }
 
+// buildHelp returns the help of the rule, with the description, severity, category and platform of the query
+func buildHelp(queryMetadata *ruleMetadata, helpURI string) sarifMultiformatMessage {
+	return sarifMultiformatMessage{
+		Text: fmt.Sprintf("%s\n\nSeverity: %s\nCategory: %s\nPlatform: %s\nMore information: %s",
+			queryMetadata.queryDescription, queryMetadata.severity, queryMetadata.queryCategory, queryMetadata.queryPlatform, helpURI),
+		Markdown: fmt.Sprintf("%s\n\n**Severity:** %s  \n**Category:** %s  \n**Platform:** %s\n\n[More information](%s)",
+			queryMetadata.queryDescription, queryMetadata.severity, queryMetadata.queryCategory, queryMetadata.queryPlatform, helpURI),
+	}
+}
+
+// buildTags returns the tags of the rule, the category and the platform of the query, used by SARIF consumers
+// such as GitHub Code Scanning to filter the results
+func buildTags(queryMetadata *ruleMetadata) []string {
+	tags := []string{"security"}
+	for _, tag := range []string{queryMetadata.queryCategory, queryMetadata.queryPlatform} {
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
 // BuildIssue creates a new entries in Results (one for each file) and new entry in Rules and Taxonomy if necessary
 func (sr *sarifReport) BuildIssue(issue *VulnerableQuery) {
 	if len(issue.Files) > 0 {
@@ -260,6 +303,7 @@ func (sr *sarifReport) BuildIssue(issue *VulnerableQuery) {
 			queryDescription: issue.Description,
 			queryURI:         issue.QueryURI,
 			queryCategory:    issue.Category,
+			queryPlatform:    issue.Platform,
 			severity:         issue.Severity,
 		}
 		ruleIndex := sr.buildRule(&metadata)
@@ -282,8 +326,22 @@ func (sr *sarifReport) BuildIssue(issue *VulnerableQuery) {
 						},
 					},
 				},
+				ResultSuppressions: buildSuppressions(issue.Files[idx].Suppression),
 			}
 			sr.Runs[0].Results = append(sr.Runs[0].Results, result)
 		}
 	}
 }
+
+// buildSuppressions returns the in source suppression of a result suppressed by an inline comment
+func buildSuppressions(suppression *Suppression) []sarifSuppression {
+	if suppression == nil {
+		return nil
+	}
+	return []sarifSuppression{
+		{
+			Kind:          "inSource",
+			Justification: fmt.Sprintf("kics-scan %s comment at line %d", suppression.Marker, suppression.Line),
+		},
+	}
+}
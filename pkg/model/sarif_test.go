@@ -69,6 +69,12 @@ var tests = []test{
 									DefaultConfiguration: sarifConfiguration{
 										Level: "error",
 									},
+									Help: sarifMultiformatMessage{
+										Text: "test description\n\n" +
+											"Severity: HIGH\nCategory: \nPlatform: \nMore information: https://www.test.com",
+										Markdown: "test description\n\n" +
+											"**Severity:** HIGH  \n**Category:**   \n**Platform:** \n\n[More information](https://www.test.com)",
+									},
 									HelpURI: "https://www.test.com",
 									RuleRelationships: []sarifDescriptorRelationship{
 										{
@@ -79,6 +85,7 @@ var tests = []test{
 											},
 										},
 									},
+									Properties: sarifRuleProperties{Tags: []string{"security"}},
 								},
 							},
 						},
@@ -112,6 +119,7 @@ var tests = []test{
 				Description: "test description",
 				QueryURI:    "https://www.test.com",
 				Category:    "test",
+				Platform:    "Terraform",
 				Severity:    SeverityHigh,
 				Files: []VulnerableFile{
 					{KeyActualValue: "test", FileName: "", Line: 1},
@@ -123,6 +131,7 @@ var tests = []test{
 				Description: "test description",
 				QueryURI:    "https://www.test.com",
 				Category:    "test",
+				Platform:    "Terraform",
 				Severity:    SeverityHigh,
 				Files: []VulnerableFile{
 					{KeyActualValue: "test", FileName: "", Line: 1},
@@ -134,6 +143,7 @@ var tests = []test{
 				Description: "test description",
 				QueryURI:    "https://www.test.com",
 				Category:    "test",
+				Platform:    "Terraform",
 				Severity:    SeverityInfo,
 				Files: []VulnerableFile{
 					{KeyActualValue: "test", FileName: "", Line: 1},
@@ -154,6 +164,12 @@ var tests = []test{
 									DefaultConfiguration: sarifConfiguration{
 										Level: "error",
 									},
+									Help: sarifMultiformatMessage{
+										Text: "test description\n\n" +
+											"Severity: HIGH\nCategory: test\nPlatform: Terraform\nMore information: https://www.test.com",
+										Markdown: "test description\n\n" +
+											"**Severity:** HIGH  \n**Category:** test  \n**Platform:** Terraform\n\n[More information](https://www.test.com)",
+									},
 									HelpURI: "https://www.test.com",
 									RuleRelationships: []sarifDescriptorRelationship{
 										{
@@ -164,6 +180,7 @@ var tests = []test{
 											},
 										},
 									},
+									Properties: sarifRuleProperties{Tags: []string{"security", "test", "Terraform"}},
 								},
 								{
 									RuleID:               "2",
@@ -173,6 +190,12 @@ var tests = []test{
 									DefaultConfiguration: sarifConfiguration{
 										Level: "none",
 									},
+									Help: sarifMultiformatMessage{
+										Text: "test description\n\n" +
+											"Severity: INFO\nCategory: test\nPlatform: Terraform\nMore information: https://www.test.com",
+										Markdown: "test description\n\n" +
+											"**Severity:** INFO  \n**Category:** test  \n**Platform:** Terraform\n\n[More information](https://www.test.com)",
+									},
 									HelpURI: "https://www.test.com",
 									RuleRelationships: []sarifDescriptorRelationship{
 										{
@@ -183,6 +206,7 @@ var tests = []test{
 											},
 										},
 									},
+									Properties: sarifRuleProperties{Tags: []string{"security", "test", "Terraform"}},
 								},
 							},
 						},
@@ -253,3 +277,11 @@ func TestBuildIssue(t *testing.T) {
 		})
 	}
 }
+
+// TestBuildSuppressions tests the functions [buildSuppressions()] and all the methods called by them
+func TestBuildSuppressions(t *testing.T) {
+	require.Nil(t, buildSuppressions(nil))
+	require.Equal(t, []sarifSuppression{
+		{Kind: "inSource", Justification: "kics-scan ignore-line comment at line 3"},
+	}, buildSuppressions(&Suppression{Marker: SuppressIgnoreLine, Line: 3}))
+}
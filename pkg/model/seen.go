@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+// FindingSeen is when a result of a project, by similarity ID, was first and last seen in its scans
+type FindingSeen struct {
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// SetSeen sets when the vulnerabilities were first seen, from the results seen in the previous scans of the project,
+// and last seen, at the time of the scan, vulnerabilities without similarity ID are not tracked
+func SetSeen(vulnerabilities []Vulnerability, seen map[string]FindingSeen, scanTime time.Time) {
+	for i := range vulnerabilities {
+		if vulnerabilities[i].SimilarityID == "" {
+			continue
+		}
+		firstSeen, lastSeen := scanTime, scanTime
+		if previous, ok := seen[vulnerabilities[i].SimilarityID]; ok && previous.FirstSeen.Before(scanTime) {
+			firstSeen = previous.FirstSeen
+		}
+		vulnerabilities[i].FirstSeen = &firstSeen
+		vulnerabilities[i].LastSeen = &lastSeen
+	}
+}
+
+// UpdateSeen returns the results seen in the scans of the project after a scan with the vulnerabilities,
+// results not found by a complete scan are fixed and forgotten, so they are new again if they come back,
+// while incomplete scans keep them
+func UpdateSeen(seen map[string]FindingSeen, vulnerabilities []Vulnerability, complete bool) map[string]FindingSeen {
+	updated := make(map[string]FindingSeen, len(vulnerabilities))
+	if !complete {
+		for similarityID, finding := range seen {
+			updated[similarityID] = finding
+		}
+	}
+	for i := range vulnerabilities {
+		if vulnerabilities[i].FirstSeen == nil || vulnerabilities[i].LastSeen == nil {
+			continue
+		}
+		updated[vulnerabilities[i].SimilarityID] = FindingSeen{
+			FirstSeen: *vulnerabilities[i].FirstSeen,
+			LastSeen:  *vulnerabilities[i].LastSeen,
+		}
+	}
+	return updated
+}
+
+// ageDays returns the number of days between the first and last time the vulnerability was seen
+func ageDays(vulnerability *Vulnerability) int {
+	if vulnerability.FirstSeen == nil || vulnerability.LastSeen == nil {
+		return 0
+	}
+	return int(vulnerability.LastSeen.Sub(*vulnerability.FirstSeen).Hours() / 24)
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestSeen tests the functions [SetSeen(), UpdateSeen(), CreateSummary()] and all the methods called by them
+func TestSeen(t *testing.T) {
+	firstScan := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	scanTime := firstScan.Add(45 * 24 * time.Hour)
+	previous := map[string]FindingSeen{
+		"old":   {FirstSeen: firstScan, LastSeen: firstScan.Add(24 * time.Hour)},
+		"fixed": {FirstSeen: firstScan, LastSeen: firstScan},
+	}
+	vulnerabilities := []Vulnerability{
+		{QueryName: "query", SimilarityID: "old", Severity: SeverityHigh},
+		{QueryName: "query", SimilarityID: "new", Severity: SeverityHigh},
+		{QueryName: "query", Severity: SeverityHigh},
+	}
+
+	SetSeen(vulnerabilities, previous, scanTime)
+	require.Equal(t, firstScan, *vulnerabilities[0].FirstSeen)
+	require.Equal(t, scanTime, *vulnerabilities[0].LastSeen)
+	require.Equal(t, scanTime, *vulnerabilities[1].FirstSeen)
+	require.Nil(t, vulnerabilities[2].FirstSeen)
+
+	summary := CreateSummary(Counters{}, vulnerabilities, "scan")
+	require.Equal(t, 45, summary.Queries[0].Files[0].AgeDays)
+	require.Equal(t, 0, summary.Queries[0].Files[1].AgeDays)
+
+	require.Equal(t, map[string]FindingSeen{
+		"old": {FirstSeen: firstScan, LastSeen: scanTime},
+		"new": {FirstSeen: scanTime, LastSeen: scanTime},
+	}, UpdateSeen(previous, vulnerabilities, true))
+	require.Equal(t, map[string]FindingSeen{
+		"old":   {FirstSeen: firstScan, LastSeen: scanTime},
+		"new":   {FirstSeen: scanTime, LastSeen: scanTime},
+		"fixed": {FirstSeen: firstScan, LastSeen: firstScan},
+	}, UpdateSeen(previous, vulnerabilities, false))
+	require.Empty(t, UpdateSeen(nil, nil, true))
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SLAPolicy is the remediation SLA of the results, the maximum age in days of the results of each severity,
+// the results of severities without maximum age have no SLA
+type SLAPolicy map[Severity]int
+
+// SLAViolation is a result older than the maximum age of its severity
+type SLAViolation struct {
+	SimilarityID string    `json:"similarity_id"`
+	QueryID      string    `json:"query_id"`
+	QueryName    string    `json:"query_name"`
+	Severity     Severity  `json:"severity"`
+	FileName     string    `json:"file_name"`
+	Line         int       `json:"line"`
+	FirstSeen    time.Time `json:"first_seen"`
+	AgeDays      int       `json:"age_days"`
+	MaxAgeDays   int       `json:"max_age_days"`
+}
+
+// ParseSLAPolicy parses the maximum ages of the severities of a SLA policy, given as severity=days (ex: high=30)
+func ParseSLAPolicy(values []string) (SLAPolicy, error) {
+	policy := make(SLAPolicy, len(values))
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid SLA %s, expected severity=days (ex: high=30)", value)
+		}
+		severity := Severity(strings.ToUpper(strings.TrimSpace(parts[0])))
+		if !isSeverity(severity) {
+			return nil, fmt.Errorf("invalid severity %s of SLA %s, supported values: high, medium, low, info", parts[0], value)
+		}
+		days, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || days < 0 {
+			return nil, fmt.Errorf("invalid maximum age %s of SLA %s, expected a number of days", parts[1], value)
+		}
+		policy[severity] = days
+	}
+	return policy, nil
+}
+
+// Evaluate returns the vulnerabilities older than the maximum age of their severity at the time now, the oldest first,
+// vulnerabilities without first seen time are not evaluated
+func (p SLAPolicy) Evaluate(vulnerabilities []Vulnerability, now time.Time) []SLAViolation {
+	violations := make([]SLAViolation, 0)
+	for i := range vulnerabilities {
+		vulnerability := &vulnerabilities[i]
+		maxAgeDays, ok := p[vulnerability.Severity]
+		if !ok || vulnerability.FirstSeen == nil {
+			continue
+		}
+		ageDays := int(now.Sub(*vulnerability.FirstSeen).Hours() / 24)
+		if ageDays <= maxAgeDays {
+			continue
+		}
+		violations = append(violations, SLAViolation{
+			SimilarityID: vulnerability.SimilarityID,
+			QueryID:      vulnerability.QueryID,
+			QueryName:    vulnerability.QueryName,
+			Severity:     vulnerability.Severity,
+			FileName:     vulnerability.FileName,
+			Line:         vulnerability.Line,
+			FirstSeen:    *vulnerability.FirstSeen,
+			AgeDays:      ageDays,
+			MaxAgeDays:   maxAgeDays,
+		})
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].FirstSeen.Before(violations[j].FirstSeen)
+	})
+	return violations
+}
+
+func isSeverity(severity Severity) bool {
+	for _, s := range AllSeverities {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseSLAPolicy tests the functions [ParseSLAPolicy()] and all the methods called by them
+func TestParseSLAPolicy(t *testing.T) {
+	policy, err := ParseSLAPolicy([]string{"high=30", " Medium = 90"})
+	require.NoError(t, err)
+	require.Equal(t, SLAPolicy{SeverityHigh: 30, SeverityMedium: 90}, policy)
+
+	for _, value := range []string{"high", "critical=30", "high=a", "high=-1"} {
+		_, err := ParseSLAPolicy([]string{value})
+		require.Error(t, err, value)
+	}
+}
+
+// TestSLAPolicy_Evaluate tests the functions [Evaluate()] and all the methods called by them
+func TestSLAPolicy_Evaluate(t *testing.T) {
+	now := time.Date(2021, 7, 16, 10, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) *time.Time {
+		seen := now.Add(-time.Duration(days) * 24 * time.Hour)
+		return &seen
+	}
+	vulnerabilities := []Vulnerability{
+		{SimilarityID: "high-recent", Severity: SeverityHigh, FirstSeen: daysAgo(30)},
+		{SimilarityID: "high-old", Severity: SeverityHigh, FirstSeen: daysAgo(31), QueryName: "S3 Bucket Public", FileName: "s3.tf", Line: 3},
+		{SimilarityID: "medium-older", Severity: SeverityMedium, FirstSeen: daysAgo(120)},
+		{SimilarityID: "low-older", Severity: SeverityLow, FirstSeen: daysAgo(365)},
+		{SimilarityID: "high-unknown", Severity: SeverityHigh},
+	}
+	policy := SLAPolicy{SeverityHigh: 30, SeverityMedium: 90}
+
+	violations := policy.Evaluate(vulnerabilities, now)
+	require.Len(t, violations, 2)
+	require.Equal(t, "medium-older", violations[0].SimilarityID)
+	require.Equal(t, SLAViolation{
+		SimilarityID: "high-old",
+		QueryName:    "S3 Bucket Public",
+		Severity:     SeverityHigh,
+		FileName:     "s3.tf",
+		Line:         3,
+		FirstSeen:    *daysAgo(31),
+		AgeDays:      31,
+		MaxAgeDays:   30,
+	}, violations[1])
+	require.Empty(t, SLAPolicy{}.Evaluate(vulnerabilities, now))
+}
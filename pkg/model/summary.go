@@ -1,30 +1,72 @@
 package model
 
 import (
+	"path/filepath"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
 // SeveritySummary contains scans' result numbers, how many vulnerabilities of each severity was detected
+// optionally broken down by platform, by top-level directory, by category and by project, along with the projects
+// discovered in the scanned path
 type SeveritySummary struct {
-	ScanID           string           `json:"scan_id"`
-	SeverityCounters map[Severity]int `json:"severity_counters"`
-	TotalCounter     int              `json:"total_counter"`
+	ScanID                      string                      `json:"scan_id"`
+	SeverityCounters            map[Severity]int            `json:"severity_counters"`
+	TotalCounter                int                         `json:"total_counter"`
+	SeverityCountersByPlatform  map[string]map[Severity]int `json:"severity_counters_by_platform,omitempty"`
+	SeverityCountersByDirectory map[string]map[Severity]int `json:"severity_counters_by_directory,omitempty"`
+	SeverityCountersByCategory  map[string]map[Severity]int `json:"severity_counters_by_category,omitempty"`
+	SeverityCountersByProject   map[string]map[Severity]int `json:"severity_counters_by_project,omitempty"`
+	Projects                    []DiscoveredProject         `json:"projects,omitempty"`
+}
+
+// SeverityBreakdown selects how severity counters are broken down in a summary
+// BasePath is the scanned path, used to find the top-level directory and the project of each vulnerable file,
+// Projects are the projects discovered in the scanned path
+type SeverityBreakdown struct {
+	ByPlatform  bool
+	ByDirectory bool
+	ByCategory  bool
+	ByProject   bool
+	BasePath    string
+	Projects    []DiscoveredProject
 }
 
 // VulnerableFile contains information of a vulnerable file and where the vulnerability was found
 type VulnerableFile struct {
-	FileName         string    `json:"file_name"`
-	SimilarityID     string    `json:"similarity_id"`
-	Line             int       `json:"line"`
-	VulnLines        VulnLines `json:"-"`
-	IssueType        IssueType `json:"issue_type"`
-	SearchKey        string    `json:"search_key"`
-	SearchValue      string    `json:"search_value"`
-	KeyExpectedValue string    `json:"expected_value"`
-	KeyActualValue   string    `json:"actual_value"`
-	Value            *string   `json:"value"`
+	FileName           string              `json:"file_name"`
+	SimilarityID       string              `json:"similarity_id"`
+	Line               int                 `json:"line"`
+	VulnLines          VulnLines           `json:"-"`
+	IssueType          IssueType           `json:"issue_type"`
+	SearchKey          string              `json:"search_key"`
+	SearchValue        string              `json:"search_value"`
+	KeyExpectedValue   string              `json:"expected_value"`
+	KeyActualValue     string              `json:"actual_value"`
+	Value              *string             `json:"value"`
+	ModuleCallChain    []string            `json:"module_call_chain,omitempty"`
+	Locations          []Location          `json:"locations,omitempty"`
+	OriginChain        []OriginStep        `json:"origin_chain,omitempty"`
+	KubernetesResource *KubernetesResource `json:"kubernetes_resource,omitempty"`
+	Environment        string              `json:"environment,omitempty"`
+	FirstSeen          *time.Time          `json:"first_seen,omitempty"`
+	LastSeen           *time.Time          `json:"last_seen,omitempty"`
+	AgeDays            int                 `json:"age_days,omitempty"`
+	TriageState        TriageState         `json:"triage_state,omitempty"`
+	Assignee           string              `json:"assignee,omitempty"`
+	Status             string              `json:"status,omitempty"`
+	Suppression        *Suppression        `json:"suppression,omitempty"`
+	Occurrences        int                 `json:"occurrences,omitempty"`
+	Samples            []Sample            `json:"samples,omitempty"`
+}
+
+// Sample is the location of one of the occurrences of an aggregated result
+type Sample struct {
+	FileName string `json:"file_name"`
+	Line     int    `json:"line"`
 }
 
 // VulnerableQuery contains a query that tested positive ID, name, severity and a list of files that tested vulnerable
@@ -53,11 +95,121 @@ type Counters struct {
 	FailedSimilarityID     int `json:"queries_failed_to_compute_similarity_id"`
 }
 
-// Summary is a report of a single scan
+// FileFailure is a file that could not be scanned, the code of the reason why and its description
+type FileFailure struct {
+	FileName string     `json:"file_name"`
+	Code     ReasonCode `json:"code"`
+	Reason   string     `json:"reason"`
+}
+
+// ScanQuality tells how much of the scanned path was covered: the files of supported types that failed to parse,
+// the files or directories that failed to render (ex: Helm charts) and how many files of each unsupported extension
+// were seen, files without extension are counted under an empty extension, the queries skipped when the scan deadline
+// was exceeded, along with an excerpt of the warnings and errors logged during the scan
+type ScanQuality struct {
+	UnparsedFiles         []FileFailure  `json:"unparsed_files"`
+	UnrenderedFiles       []FileFailure  `json:"unrendered_files"`
+	UnsupportedExtensions map[string]int `json:"unsupported_extensions"`
+	SkippedQueries        []string       `json:"skipped_queries,omitempty"`
+	Logs                  []LogEntry     `json:"logs,omitempty"`
+}
+
+// LogEntry is a warning or error logged during the scan
+type LogEntry struct {
+	Time     string `json:"time,omitempty"`
+	Level    string `json:"level"`
+	Message  string `json:"message"`
+	FileName string `json:"file_name,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Summary is a report of a single scan, incomplete when the scan stopped before evaluating all queries,
+// SinceLastScan compares the results with the last successful scan of the project when scans are kept in a history
+// and Baseline compares them with the baseline of the scan, whose known results are not reported
 type Summary struct {
 	Counters
-	Queries VulnerableQuerySlice `json:"queries"`
+	Queries    VulnerableQuerySlice `json:"queries"`
+	Suppressed VulnerableQuerySlice `json:"suppressed_queries,omitempty"`
 	SeveritySummary
+	ScanQuality   ScanQuality         `json:"scan_quality"`
+	Incomplete    bool                `json:"incomplete,omitempty"`
+	SinceLastScan *ScanComparison     `json:"since_last_scan,omitempty"`
+	Baseline      *BaselineComparison `json:"baseline,omitempty"`
+}
+
+// NewSeveritySummary creates the severity summary of a scan from its vulnerabilities
+func NewSeveritySummary(scanID string, vulnerabilities []Vulnerability, breakdown SeverityBreakdown) SeveritySummary {
+	severitySummary := SeveritySummary{
+		ScanID:           scanID,
+		SeverityCounters: newSeverityCounters(),
+		TotalCounter:     len(vulnerabilities),
+	}
+	for i := range vulnerabilities {
+		severitySummary.SeverityCounters[vulnerabilities[i].Severity]++
+	}
+	severitySummary.AddBreakdown(vulnerabilities, breakdown)
+	return severitySummary
+}
+
+// AddBreakdown adds to the summary the severity counters of each platform, top-level directory, category and project
+// selected by breakdown, results of files of no project are counted under (none)
+func (s *SeveritySummary) AddBreakdown(vulnerabilities []Vulnerability, breakdown SeverityBreakdown) {
+	if breakdown.ByPlatform {
+		s.SeverityCountersByPlatform = make(map[string]map[Severity]int)
+	}
+	if breakdown.ByDirectory {
+		s.SeverityCountersByDirectory = make(map[string]map[Severity]int)
+	}
+	if breakdown.ByCategory {
+		s.SeverityCountersByCategory = make(map[string]map[Severity]int)
+	}
+	if breakdown.ByProject {
+		s.SeverityCountersByProject = make(map[string]map[Severity]int)
+		s.Projects = breakdown.Projects
+	}
+	for i := range vulnerabilities {
+		if breakdown.ByPlatform {
+			countSeverity(s.SeverityCountersByPlatform, vulnerabilities[i].Platform, vulnerabilities[i].Severity)
+		}
+		if breakdown.ByDirectory {
+			directory := topLevelDirectory(breakdown.BasePath, vulnerabilities[i].FileName)
+			countSeverity(s.SeverityCountersByDirectory, directory, vulnerabilities[i].Severity)
+		}
+		if breakdown.ByCategory {
+			countSeverity(s.SeverityCountersByCategory, vulnerabilities[i].Category, vulnerabilities[i].Severity)
+		}
+		if breakdown.ByProject {
+			project := projectOf(breakdown.BasePath, vulnerabilities[i].FileName, breakdown.Projects)
+			countSeverity(s.SeverityCountersByProject, project, vulnerabilities[i].Severity)
+		}
+	}
+}
+
+func newSeverityCounters() map[Severity]int {
+	return map[Severity]int{SeverityInfo: 0, SeverityLow: 0, SeverityMedium: 0, SeverityHigh: 0}
+}
+
+func countSeverity(counters map[string]map[Severity]int, key string, severity Severity) {
+	if _, ok := counters[key]; !ok {
+		counters[key] = newSeverityCounters()
+	}
+	counters[key][severity]++
+}
+
+// topLevelDirectory returns the first directory of the file path relative to the base path,
+// or "." for files directly inside it
+func topLevelDirectory(basePath, fileName string) string {
+	relative := fileName
+	if basePath != "" {
+		if rel, err := filepath.Rel(basePath, fileName); err == nil && !strings.HasPrefix(filepath.ToSlash(rel), "../") {
+			relative = rel
+		}
+	}
+	parts := strings.Split(strings.TrimPrefix(filepath.ToSlash(relative), "/"), "/")
+	if len(parts) < 2 {
+		return "."
+	}
+	return parts[0]
 }
 
 // CreateSummary creates a report for a single scan, based on its scanID
@@ -83,23 +235,35 @@ func CreateSummary(counters Counters, vulnerabilities []Vulnerability, scanID st
 
 		qItem := q[item.QueryName]
 		qItem.Files = append(qItem.Files, VulnerableFile{
-			FileName:         item.FileName,
-			SimilarityID:     item.SimilarityID,
-			Line:             item.Line,
-			VulnLines:        item.VulnLines,
-			IssueType:        item.IssueType,
-			SearchKey:        item.SearchKey,
-			SearchValue:      item.SearchValue,
-			KeyExpectedValue: item.KeyExpectedValue,
-			KeyActualValue:   item.KeyActualValue,
-			Value:            item.Value,
+			FileName:           item.FileName,
+			SimilarityID:       item.SimilarityID,
+			Line:               item.Line,
+			VulnLines:          item.VulnLines,
+			IssueType:          item.IssueType,
+			SearchKey:          item.SearchKey,
+			SearchValue:        item.SearchValue,
+			KeyExpectedValue:   item.KeyExpectedValue,
+			KeyActualValue:     item.KeyActualValue,
+			Value:              item.Value,
+			ModuleCallChain:    item.ModuleCallChain,
+			Locations:          item.Locations,
+			OriginChain:        item.OriginChain,
+			KubernetesResource: item.KubernetesResource,
+			Environment:        item.Environment,
+			FirstSeen:          item.FirstSeen,
+			LastSeen:           item.LastSeen,
+			AgeDays:            ageDays(&item),
+			TriageState:        item.TriageState,
+			Assignee:           item.Assignee,
+			Status:             suppressionStatus(&item),
+			Suppression:        item.Suppression,
 		})
 
 		q[item.QueryName] = qItem
 	}
 
 	queries := make([]VulnerableQuery, 0, len(q))
-	sevs := map[Severity]int{SeverityInfo: 0, SeverityLow: 0, SeverityMedium: 0, SeverityHigh: 0}
+	sevs := newSeverityCounters()
 	for idx := range q {
 		queries = append(queries, q[idx])
 		sevs[q[idx].Severity] += len(q[idx].Files)
@@ -122,3 +286,51 @@ func CreateSummary(counters Counters, vulnerabilities []Vulnerability, scanID st
 		SeveritySummary: severitySummary,
 	}
 }
+
+// AggregateResults collapses the results of each query sharing the same issue type and expected value into
+// a single result when there are more than threshold of them, the aggregated result keeps the number of
+// occurrences and up to samples representative locations, severity counters are not changed
+func (s *Summary) AggregateResults(threshold, samples int) {
+	if threshold <= 0 {
+		return
+	}
+	for idx := range s.Queries {
+		groups := make(map[string][]VulnerableFile)
+		keys := make([]string, 0)
+		for i := range s.Queries[idx].Files {
+			key := string(s.Queries[idx].Files[i].IssueType) + ":" + s.Queries[idx].Files[i].KeyExpectedValue
+			if _, ok := groups[key]; !ok {
+				keys = append(keys, key)
+			}
+			groups[key] = append(groups[key], s.Queries[idx].Files[i])
+		}
+
+		files := make([]VulnerableFile, 0, len(s.Queries[idx].Files))
+		for _, key := range keys {
+			if len(groups[key]) <= threshold {
+				files = append(files, groups[key]...)
+				continue
+			}
+			files = append(files, aggregateFiles(groups[key], samples))
+		}
+		s.Queries[idx].Files = files
+	}
+}
+
+// aggregateFiles returns the first result of the group, by file name and line, with the occurrences
+// and the locations of the first samples results
+func aggregateFiles(group []VulnerableFile, samples int) VulnerableFile {
+	sort.SliceStable(group, func(i, j int) bool {
+		if group[i].FileName == group[j].FileName {
+			return group[i].Line < group[j].Line
+		}
+		return group[i].FileName < group[j].FileName
+	})
+	aggregated := group[0]
+	aggregated.Occurrences = len(group)
+	aggregated.Samples = make([]Sample, 0, samples)
+	for i := 0; i < len(group) && i < samples; i++ {
+		aggregated.Samples = append(aggregated.Samples, Sample{FileName: group[i].FileName, Line: group[i].Line})
+	}
+	return aggregated
+}
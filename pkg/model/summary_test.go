@@ -1,6 +1,7 @@
 package model
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -86,3 +87,118 @@ func TestCreateSummary(t *testing.T) {
 		})
 	})
 }
+
+// TestNewSeveritySummary tests the functions [NewSeveritySummary()] and all the methods called by them
+func TestNewSeveritySummary(t *testing.T) {
+	basePath := filepath.Join("project")
+	vulnerabilities := []Vulnerability{
+		{Platform: "Terraform", Severity: SeverityHigh, FileName: filepath.Join(basePath, "infra", "network", "main.tf"), Category: "Networking and Firewall"},
+		{Platform: "Terraform", Severity: SeverityLow, FileName: filepath.Join(basePath, "infra", "main.tf"), Category: "Internal Policy"},
+		{Platform: "Kubernetes", Severity: SeverityHigh, FileName: filepath.Join(basePath, "deploy", "pod.yaml"), Category: "Internal Policy"},
+		{Platform: "Dockerfile", Severity: SeverityMedium, FileName: filepath.Join(basePath, "Dockerfile"), Category: "Supply-Chain"},
+	}
+
+	tests := []struct {
+		name      string
+		breakdown SeverityBreakdown
+		want      SeveritySummary
+	}{
+		{
+			name:      "without_breakdown",
+			breakdown: SeverityBreakdown{},
+			want: SeveritySummary{
+				ScanID:           "scanID",
+				SeverityCounters: map[Severity]int{SeverityHigh: 2, SeverityMedium: 1, SeverityLow: 1, SeverityInfo: 0},
+				TotalCounter:     4,
+			},
+		},
+		{
+			name:      "by_platform_and_directory",
+			breakdown: SeverityBreakdown{ByPlatform: true, ByDirectory: true, BasePath: basePath},
+			want: SeveritySummary{
+				ScanID:           "scanID",
+				SeverityCounters: map[Severity]int{SeverityHigh: 2, SeverityMedium: 1, SeverityLow: 1, SeverityInfo: 0},
+				TotalCounter:     4,
+				SeverityCountersByPlatform: map[string]map[Severity]int{
+					"Terraform":  {SeverityHigh: 1, SeverityMedium: 0, SeverityLow: 1, SeverityInfo: 0},
+					"Kubernetes": {SeverityHigh: 1, SeverityMedium: 0, SeverityLow: 0, SeverityInfo: 0},
+					"Dockerfile": {SeverityHigh: 0, SeverityMedium: 1, SeverityLow: 0, SeverityInfo: 0},
+				},
+				SeverityCountersByDirectory: map[string]map[Severity]int{
+					"infra":  {SeverityHigh: 1, SeverityMedium: 0, SeverityLow: 1, SeverityInfo: 0},
+					"deploy": {SeverityHigh: 1, SeverityMedium: 0, SeverityLow: 0, SeverityInfo: 0},
+					".":      {SeverityHigh: 0, SeverityMedium: 1, SeverityLow: 0, SeverityInfo: 0},
+				},
+			},
+		},
+		{
+			name:      "by_category",
+			breakdown: SeverityBreakdown{ByCategory: true},
+			want: SeveritySummary{
+				ScanID:           "scanID",
+				SeverityCounters: map[Severity]int{SeverityHigh: 2, SeverityMedium: 1, SeverityLow: 1, SeverityInfo: 0},
+				TotalCounter:     4,
+				SeverityCountersByCategory: map[string]map[Severity]int{
+					"Networking and Firewall": {SeverityHigh: 1, SeverityMedium: 0, SeverityLow: 0, SeverityInfo: 0},
+					"Internal Policy":         {SeverityHigh: 1, SeverityMedium: 0, SeverityLow: 1, SeverityInfo: 0},
+					"Supply-Chain":            {SeverityHigh: 0, SeverityMedium: 1, SeverityLow: 0, SeverityInfo: 0},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, NewSeveritySummary("scanID", vulnerabilities, tt.breakdown))
+		})
+	}
+}
+
+// TestSummary_AggregateResults tests the functions [AggregateResults()] and all the methods called by them
+func TestSummary_AggregateResults(t *testing.T) {
+	newSummary := func() Summary {
+		return Summary{
+			Queries: []VulnerableQuery{
+				{
+					QueryName: "Missing Tags",
+					Files: []VulnerableFile{
+						{FileName: "b.tf", Line: 3, IssueType: IssueTypeMissingAttribute, KeyExpectedValue: "tags is defined"},
+						{FileName: "a.tf", Line: 9, IssueType: IssueTypeMissingAttribute, KeyExpectedValue: "tags is defined"},
+						{FileName: "a.tf", Line: 1, IssueType: IssueTypeMissingAttribute, KeyExpectedValue: "tags is defined"},
+						{FileName: "c.tf", Line: 5, IssueType: IssueTypeIncorrectValue, KeyExpectedValue: "tags is not empty"},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("aggregation_disabled", func(t *testing.T) {
+		summary := newSummary()
+		summary.AggregateResults(0, 2)
+		require.Equal(t, newSummary(), summary)
+	})
+
+	t.Run("below_threshold", func(t *testing.T) {
+		summary := newSummary()
+		summary.AggregateResults(3, 2)
+		require.Equal(t, newSummary(), summary)
+	})
+
+	t.Run("above_threshold", func(t *testing.T) {
+		summary := newSummary()
+		summary.AggregateResults(2, 2)
+		require.Equal(t, []VulnerableFile{
+			{
+				FileName:         "a.tf",
+				Line:             1,
+				IssueType:        IssueTypeMissingAttribute,
+				KeyExpectedValue: "tags is defined",
+				Occurrences:      3,
+				Samples: []Sample{
+					{FileName: "a.tf", Line: 1},
+					{FileName: "a.tf", Line: 9},
+				},
+			},
+			{FileName: "c.tf", Line: 5, IssueType: IssueTypeIncorrectValue, KeyExpectedValue: "tags is not empty"},
+		}, summary.Queries[0].Files)
+	})
+}
@@ -0,0 +1,180 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Constants to describe the inline suppression comments, written after # or // in the scanned files
+const (
+	SuppressIgnore      = "ignore"
+	SuppressIgnoreLine  = "ignore-line"
+	SuppressIgnoreBlock = "ignore-block"
+	SuppressDisable     = "disable"
+)
+
+// SuppressedStatus is the status of the results suppressed by inline comments
+const SuppressedStatus = "suppressed"
+
+var suppressionRegex = regexp.MustCompile(`(#|//)\s*kics-scan\s+(ignore-line|ignore-block|ignore|disable=(\S+))(\s|$)`)
+
+// Suppression is the inline comment of the file that suppressed a result, Line is the one based line of the comment
+type Suppression struct {
+	Marker string `json:"marker"`
+	Line   int    `json:"line"`
+}
+
+// Suppressions are the inline suppression comments of a file, by the scope of the results they suppress
+type Suppressions struct {
+	file    *Suppression
+	queries map[string]*Suppression
+	lines   map[int]*Suppression
+}
+
+// ParseSuppressions returns the inline suppression comments of the content of a file, nil if it has none,
+// "kics-scan ignore" suppresses all the results of the file, "kics-scan ignore-line" the results of the line the
+// comment trails or of the next line, "kics-scan ignore-block" the results of the next line and of the block it
+// starts, delimited by indentation or brackets, and "kics-scan disable=<queryID>,..." the results of the queries
+func ParseSuppressions(content string) *Suppressions {
+	if !strings.Contains(content, "kics-scan") {
+		return nil
+	}
+	lines := strings.Split(content, "\n")
+	s := &Suppressions{
+		queries: make(map[string]*Suppression),
+		lines:   make(map[int]*Suppression),
+	}
+	found := false
+	for idx, line := range lines {
+		match := suppressionRegex.FindStringSubmatchIndex(line)
+		if match == nil {
+			continue
+		}
+		found = true
+		marker := line[match[4]:match[5]]
+		suppression := &Suppression{Marker: marker, Line: idx + 1}
+		switch {
+		case marker == SuppressIgnore:
+			if s.file == nil {
+				s.file = suppression
+			}
+		case marker == SuppressIgnoreLine:
+			start := idx
+			if strings.TrimSpace(line[:match[0]]) == "" {
+				start = nextCodeLine(lines, idx)
+			}
+			s.suppressLines(start, start, suppression)
+		case marker == SuppressIgnoreBlock:
+			start := nextCodeLine(lines, idx)
+			s.suppressLines(start, blockEnd(lines, start), suppression)
+		default:
+			suppression.Marker = SuppressDisable
+			for _, queryID := range strings.Split(line[match[6]:match[7]], ",") {
+				if queryID = strings.TrimSpace(queryID); queryID != "" {
+					s.queries[queryID] = suppression
+				}
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	return s
+}
+
+// Match returns the inline comment suppressing the vulnerability, nil if it is not suppressed
+func (s *Suppressions) Match(vulnerability *Vulnerability) *Suppression {
+	if s == nil {
+		return nil
+	}
+	if s.file != nil {
+		return s.file
+	}
+	if suppression, ok := s.queries[vulnerability.QueryID]; ok {
+		return suppression
+	}
+	return s.lines[vulnerability.Line]
+}
+
+// suppressLines suppresses the results of the lines from start to end, zero based indexes
+func (s *Suppressions) suppressLines(start, end int, suppression *Suppression) {
+	if start < 0 {
+		return
+	}
+	for idx := start; idx <= end; idx++ {
+		if _, ok := s.lines[idx+1]; !ok {
+			s.lines[idx+1] = suppression
+		}
+	}
+}
+
+// nextCodeLine returns the index of the first line after idx that is neither blank nor a comment, -1 if there is none
+func nextCodeLine(lines []string, idx int) int {
+	for next := idx + 1; next < len(lines); next++ {
+		trimmed := strings.TrimSpace(lines[next])
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "//") {
+			return next
+		}
+	}
+	return -1
+}
+
+// blockEnd returns the index of the last line of the block started at the line start, the block includes the
+// following lines more indented than it and the lines until the brackets it opens are closed
+func blockEnd(lines []string, start int) int {
+	if start < 0 {
+		return start
+	}
+	indent := indentation(lines[start])
+	depth := bracketDepth(lines[start])
+	end := start
+	for idx := start + 1; idx < len(lines); idx++ {
+		if strings.TrimSpace(lines[idx]) == "" {
+			continue
+		}
+		if depth <= 0 && indentation(lines[idx]) <= indent {
+			break
+		}
+		depth += bracketDepth(lines[idx])
+		end = idx
+	}
+	return end
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// bracketDepth returns the number of brackets opened minus the number of brackets closed in the line
+func bracketDepth(line string) int {
+	return strings.Count(line, "{") + strings.Count(line, "[") - strings.Count(line, "}") - strings.Count(line, "]")
+}
+
+// suppressionStatus returns the status of the vulnerability in the summary, empty if it is not suppressed
+func suppressionStatus(vulnerability *Vulnerability) string {
+	if vulnerability.Suppression == nil {
+		return ""
+	}
+	return SuppressedStatus
+}
+
+// SplitSuppressed splits the vulnerabilities in the ones not suppressed and the ones suppressed by inline comments
+func SplitSuppressed(vulnerabilities []Vulnerability) (active, suppressed []Vulnerability) {
+	active = make([]Vulnerability, 0, len(vulnerabilities))
+	for i := range vulnerabilities {
+		if vulnerabilities[i].Suppression != nil {
+			suppressed = append(suppressed, vulnerabilities[i])
+			continue
+		}
+		active = append(active, vulnerabilities[i])
+	}
+	return active, suppressed
+}
+
+// NewSuppressedQueries groups the vulnerabilities suppressed by inline comments by query, as the queries of a summary
+func NewSuppressedQueries(suppressed []Vulnerability) VulnerableQuerySlice {
+	if len(suppressed) == 0 {
+		return nil
+	}
+	return CreateSummary(Counters{}, suppressed, "").Queries
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseSuppressions tests the functions [ParseSuppressions()] and all the methods called by them
+func TestParseSuppressions(t *testing.T) {
+	terraform := `resource "aws_s3_bucket" "b" { # kics-scan ignore-line
+  bucket = "my-bucket"
+  acl    = "public-read"
+}
+
+# kics-scan ignore-block
+resource "aws_s3_bucket" "c" {
+  bucket = "other-bucket"
+  acl    = "public-read"
+}
+
+resource "aws_s3_bucket" "d" {
+  // kics-scan ignore-line
+  acl = "public-read"
+  versioning {
+    enabled = false
+  }
+}
+`
+	s := ParseSuppressions(terraform)
+	require.NotNil(t, s)
+
+	tests := []struct {
+		name string
+		line int
+		want *Suppression
+	}{
+		{name: "trailing ignore-line", line: 1, want: &Suppression{Marker: SuppressIgnoreLine, Line: 1}},
+		{name: "after trailing ignore-line", line: 3},
+		{name: "ignore-block start", line: 7, want: &Suppression{Marker: SuppressIgnoreBlock, Line: 6}},
+		{name: "ignore-block content", line: 9, want: &Suppression{Marker: SuppressIgnoreBlock, Line: 6}},
+		{name: "ignore-block closing bracket", line: 10, want: &Suppression{Marker: SuppressIgnoreBlock, Line: 6}},
+		{name: "after ignore-block", line: 12},
+		{name: "ignore-line next line", line: 14, want: &Suppression{Marker: SuppressIgnoreLine, Line: 13}},
+		{name: "after ignore-line", line: 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, s.Match(&Vulnerability{QueryID: "q1", Line: tt.line}))
+		})
+	}
+
+	yaml := `# kics-scan disable=q1,q2
+apiVersion: v1
+kind: Pod
+spec:
+  # kics-scan ignore-block
+  containers:
+    - name: app
+      image: nginx
+  hostNetwork: true
+`
+	s = ParseSuppressions(yaml)
+	require.Equal(t, &Suppression{Marker: SuppressDisable, Line: 1}, s.Match(&Vulnerability{QueryID: "q2", Line: 9}))
+	require.Nil(t, s.Match(&Vulnerability{QueryID: "q3", Line: 9}))
+	require.Equal(t, &Suppression{Marker: SuppressIgnoreBlock, Line: 5}, s.Match(&Vulnerability{QueryID: "q3", Line: 8}))
+
+	s = ParseSuppressions("# kics-scan ignore\nFROM nginx:latest\n")
+	require.Equal(t, &Suppression{Marker: SuppressIgnore, Line: 1}, s.Match(&Vulnerability{QueryID: "q3", Line: 2}))
+
+	require.Nil(t, ParseSuppressions("FROM nginx:latest\n"))
+	require.Nil(t, ParseSuppressions("# kics-scan ignore-everything\nFROM nginx:latest\n"))
+	require.Nil(t, ParseSuppressions("").Match(&Vulnerability{QueryID: "q1", Line: 1}))
+}
+
+// TestSplitSuppressed tests the functions [SplitSuppressed()] and [NewSuppressedQueries()] and all the methods called by them
+func TestSplitSuppressed(t *testing.T) {
+	suppression := &Suppression{Marker: SuppressIgnoreLine, Line: 3}
+	vulnerabilities := []Vulnerability{
+		{QueryID: "q1", QueryName: "Query 1", Severity: SeverityHigh, FileName: "main.tf", Line: 4, Suppression: suppression},
+		{QueryID: "q2", QueryName: "Query 2", Severity: SeverityLow, FileName: "main.tf", Line: 8},
+	}
+	active, suppressed := SplitSuppressed(vulnerabilities)
+	require.Equal(t, vulnerabilities[1:], active)
+	require.Equal(t, vulnerabilities[:1], suppressed)
+
+	queries := NewSuppressedQueries(suppressed)
+	require.Len(t, queries, 1)
+	require.Equal(t, "q1", queries[0].QueryID)
+	require.Equal(t, SuppressedStatus, queries[0].Files[0].Status)
+	require.Equal(t, suppression, queries[0].Files[0].Suppression)
+
+	summary := CreateSummary(Counters{}, active, "scan")
+	require.Equal(t, "", summary.Queries[0].Files[0].Status)
+	require.Nil(t, NewSuppressedQueries(nil))
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TriageState is the state of a result triaged by the team of the project
+type TriageState string
+
+// Constants to describe the triage states of the results
+const (
+	TriageOpen          TriageState = "open"
+	TriageAcknowledged  TriageState = "acknowledged"
+	TriageFalsePositive TriageState = "false-positive"
+	TriageFixed         TriageState = "fixed"
+)
+
+// TriageStates is the list of the triage states
+var TriageStates = []TriageState{TriageOpen, TriageAcknowledged, TriageFalsePositive, TriageFixed}
+
+// Triage is the triage state of a result of a project and who it is assigned to
+type Triage struct {
+	State     TriageState `json:"state"`
+	Assignee  string      `json:"assignee,omitempty"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// ParseTriageState returns the triage state of the value, case insensitive
+func ParseTriageState(value string) (TriageState, error) {
+	state := TriageState(strings.ToLower(strings.TrimSpace(value)))
+	for _, triageState := range TriageStates {
+		if state == triageState {
+			return state, nil
+		}
+	}
+	return "", fmt.Errorf("triage state not supported: %s, supported values: %s", value, strings.Join(triageStateNames(), ", "))
+}
+
+// SetTriage sets the triage state and the assignee of the vulnerabilities triaged, by similarity ID,
+// results triaged as fixed that are found again are open again
+func SetTriage(vulnerabilities []Vulnerability, triages map[string]Triage) {
+	for i := range vulnerabilities {
+		triage, ok := triages[vulnerabilities[i].SimilarityID]
+		if !ok || vulnerabilities[i].SimilarityID == "" {
+			continue
+		}
+		if triage.State == TriageFixed {
+			triage.State = TriageOpen
+		}
+		vulnerabilities[i].TriageState = triage.State
+		vulnerabilities[i].Assignee = triage.Assignee
+	}
+}
+
+func triageStateNames() []string {
+	names := make([]string, 0, len(TriageStates))
+	for _, state := range TriageStates {
+		names = append(names, string(state))
+	}
+	return names
+}
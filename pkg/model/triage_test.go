@@ -0,0 +1,39 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseTriageState tests the functions [ParseTriageState()] and all the methods called by them
+func TestParseTriageState(t *testing.T) {
+	state, err := ParseTriageState(" False-Positive")
+	require.NoError(t, err)
+	require.Equal(t, TriageFalsePositive, state)
+
+	_, err = ParseTriageState("closed")
+	require.Error(t, err)
+}
+
+// TestSetTriage tests the functions [SetTriage()] and all the methods called by them
+func TestSetTriage(t *testing.T) {
+	vulnerabilities := []Vulnerability{
+		{SimilarityID: "acknowledged"},
+		{SimilarityID: "fixed"},
+		{SimilarityID: "untriaged"},
+		{},
+	}
+	SetTriage(vulnerabilities, map[string]Triage{
+		"acknowledged": {State: TriageAcknowledged, Assignee: "alice"},
+		"fixed":        {State: TriageFixed, Assignee: "bob"},
+		"":             {State: TriageFalsePositive},
+	})
+
+	require.Equal(t, TriageAcknowledged, vulnerabilities[0].TriageState)
+	require.Equal(t, "alice", vulnerabilities[0].Assignee)
+	require.Equal(t, TriageOpen, vulnerabilities[1].TriageState, "fixed results found again are open again")
+	require.Equal(t, "bob", vulnerabilities[1].Assignee)
+	require.Empty(t, vulnerabilities[2].TriageState)
+	require.Empty(t, vulnerabilities[3].TriageState)
+}
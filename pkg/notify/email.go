@@ -0,0 +1,97 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/redact"
+	"github.com/Checkmarx/kics/pkg/report"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultSMTPPort = 587
+	// base64LineLength is the length of the lines of the base64 encoded body, below the line limit of SMTP
+	base64LineLength = 76
+)
+
+// sendMail sends the emails, replaced in tests
+var sendMail = smtp.SendMail
+
+// SMTP is the server sending the emails, with STARTTLS when the server supports it,
+// the credentials are only sent over TLS or to localhost
+type SMTP struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+func (s *SMTP) validate() error {
+	if s.Host == "" || s.From == "" {
+		return fmt.Errorf("smtp server without host or from address")
+	}
+	if s.Port == 0 {
+		s.Port = defaultSMTPPort
+	}
+	return nil
+}
+
+// EmailSender emails the notifications to the recipients, the body is the HTML report of their results, with the
+// secrets of the redactor, if any, redacted
+type EmailSender struct {
+	SMTP       *SMTP
+	Recipients []string
+	Redactor   *redact.Redactor
+}
+
+// Send emails the HTML report of the results of the notification
+func (e *EmailSender) Send(_ context.Context, notification *Notification) error {
+	if e.SMTP == nil {
+		return errors.New("failed to send email: smtp server not configured")
+	}
+	summary := model.CreateSummary(model.Counters{}, notification.vulnerabilities, notification.ScanID)
+	var body bytes.Buffer
+	if err := report.WriteHTMLReport(&body, &summary); err != nil {
+		return errors.Wrap(err, "failed to create the HTML report of the email")
+	}
+	subject := e.Redactor.String(fmt.Sprintf("KICS found %d results for %s", notification.TotalCounter, notification.Route))
+
+	var auth smtp.Auth
+	if e.SMTP.Username != "" {
+		auth = smtp.PlainAuth("", e.SMTP.Username, e.SMTP.Password, e.SMTP.Host)
+	}
+	addr := net.JoinHostPort(e.SMTP.Host, strconv.Itoa(e.SMTP.Port))
+	message := emailMessage(e.SMTP.From, e.Recipients, subject, e.Redactor.Bytes(body.Bytes()))
+	return errors.Wrap(sendMail(addr, auth, e.SMTP.From, e.Recipients, message), "failed to send email")
+}
+
+// emailMessage returns the MIME message of an HTML email, the body is base64 encoded
+// since the minified HTML has lines longer than the ones allowed by SMTP
+func emailMessage(from string, to []string, subject string, html []byte) []byte {
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/html; charset=\"utf-8\"\r\n")
+	message.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+	encoded := base64.StdEncoding.EncodeToString(html)
+	for len(encoded) > base64LineLength {
+		message.WriteString(encoded[:base64LineLength] + "\r\n")
+		encoded = encoded[base64LineLength:]
+	}
+	message.WriteString(encoded + "\r\n")
+	return message.Bytes()
+}
@@ -0,0 +1,313 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/redact"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the configuration of the notifications of the results, the results matching each route are sent
+// to its targets, a result can match several routes, SMTP is the server sending the emails of the email targets
+type Config struct {
+	SMTP   *SMTP   `yaml:"smtp"`
+	Routes []Route `yaml:"routes"`
+}
+
+// Route selects the results with its severity or above, in files matching one of its paths and of one of its queries,
+// empty filters match all results, paths are relative to the scanned path and match a file or one of its directories
+// with the syntax of filepath.Match, paths without slash also match the file names
+type Route struct {
+	Name     string   `yaml:"name"`
+	Severity string   `yaml:"severity"`
+	Paths    []string `yaml:"paths"`
+	Queries  []string `yaml:"queries"`
+	Targets  []Target `yaml:"targets"`
+}
+
+// Target is where the results of a route are sent: a Slack incoming webhook, posting to its channel or to Channel
+// when given, an HTTP(S) endpoint receiving the notification as JSON or the email recipients of an HTML report
+type Target struct {
+	Slack   string   `yaml:"slack"`
+	Channel string   `yaml:"channel"`
+	Webhook string   `yaml:"webhook"`
+	Email   []string `yaml:"email"`
+}
+
+// Notification is the content sent to the targets of a route
+type Notification struct {
+	Route            string                 `json:"route"`
+	ScanID           string                 `json:"scan_id"`
+	SeverityCounters map[model.Severity]int `json:"severity_counters"`
+	TotalCounter     int                    `json:"total_counter"`
+	Results          []Result               `json:"results"`
+	targets          []Target
+	vulnerabilities  []model.Vulnerability
+}
+
+// Result is a result sent in a notification, the file name is relative to the scanned path
+type Result struct {
+	QueryID      string         `json:"query_id"`
+	QueryName    string         `json:"query_name"`
+	Severity     model.Severity `json:"severity"`
+	Category     string         `json:"category"`
+	FileName     string         `json:"file_name"`
+	Line         int            `json:"line"`
+	SimilarityID string         `json:"similarity_id"`
+}
+
+// Sender sends the notification of a route to one of its targets
+type Sender interface {
+	Send(ctx context.Context, notification *Notification) error
+}
+
+// LoadConfig reads the notification routes of a YAML file, environment variables in the file are expanded
+// so the URLs with secrets can be kept out of it
+func LoadConfig(configPath string) (*Config, error) {
+	f, err := os.Open(filepath.Clean(configPath))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open notifications configuration")
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read notifications configuration")
+	}
+
+	var config Config
+	decoder := yaml.NewDecoder(strings.NewReader(os.ExpandEnv(string(content))))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && err != io.EOF {
+		return nil, errors.Wrapf(err, "failed to parse notifications configuration %s", configPath)
+	}
+	if err := config.validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid notifications configuration %s", configPath)
+	}
+	return &config, nil
+}
+
+func (c *Config) validate() error {
+	if c.SMTP != nil {
+		if err := c.SMTP.validate(); err != nil {
+			return err
+		}
+	}
+	names := make(map[string]bool, len(c.Routes))
+	for i := range c.Routes {
+		if err := c.Routes[i].validate(); err != nil {
+			return err
+		}
+		if names[c.Routes[i].Name] {
+			return fmt.Errorf("route %s defined more than once", c.Routes[i].Name)
+		}
+		names[c.Routes[i].Name] = true
+		for _, target := range c.Routes[i].Targets {
+			if len(target.Email) > 0 && c.SMTP == nil {
+				return fmt.Errorf("email targets of route %s require the smtp server", c.Routes[i].Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *Route) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("route without name")
+	}
+	if r.Severity != "" {
+		r.Severity = strings.ToUpper(r.Severity)
+		if !isSeverity(model.Severity(r.Severity)) {
+			return fmt.Errorf("invalid severity %s of route %s, supported values: high, medium, low, info", r.Severity, r.Name)
+		}
+	}
+	for _, pattern := range r.Paths {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return errors.Wrapf(err, "invalid path %s of route %s", pattern, r.Name)
+		}
+	}
+	if len(r.Targets) == 0 {
+		return fmt.Errorf("route %s without targets", r.Name)
+	}
+	for _, target := range r.Targets {
+		kinds := 0
+		for _, set := range []bool{target.Slack != "", target.Webhook != "", len(target.Email) > 0} {
+			if set {
+				kinds++
+			}
+		}
+		if kinds != 1 {
+			return fmt.Errorf("targets of route %s should have either a slack URL, a webhook URL or email recipients", r.Name)
+		}
+	}
+	return nil
+}
+
+// matches returns true if the result matches the filters of the route
+func (r *Route) matches(result *Result) bool {
+	if r.Severity != "" && !result.Severity.AtLeast(model.Severity(r.Severity)) {
+		return false
+	}
+	if len(r.Queries) > 0 && !contains(r.Queries, result.QueryID) {
+		return false
+	}
+	if len(r.Paths) == 0 {
+		return true
+	}
+	for _, pattern := range r.Paths {
+		if matchPath(pattern, result.FileName) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath returns true if the pattern matches the file name or one of its directories,
+// patterns without slash also match the names of the file and of its directories
+func matchPath(pattern, fileName string) bool {
+	pattern = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(pattern), "./"), "/")
+	for path := fileName; path != "." && path != "/" && path != ""; path = filepath.ToSlash(filepath.Dir(path)) {
+		name := path
+		if !strings.Contains(pattern, "/") {
+			name = filepath.Base(path)
+		}
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Notifier routes the results of a scan to the targets of the routes of its configuration
+type Notifier struct {
+	config    *Config
+	basePath  string
+	redactor  *redact.Redactor
+	newSender func(target Target) Sender
+}
+
+// NewNotifier creates a Notifier of the routes of the configuration, sending with the client to the targets,
+// the file names of the results are made relative to the scanned path basePath
+func NewNotifier(config *Config, client *http.Client, basePath string) *Notifier {
+	n := &Notifier{
+		config:   config,
+		basePath: basePath,
+	}
+	n.newSender = func(target Target) Sender {
+		switch {
+		case target.Slack != "":
+			return &SlackSender{URL: target.Slack, Channel: target.Channel, Client: client, Redactor: n.redactor}
+		case len(target.Email) > 0:
+			return &EmailSender{SMTP: config.SMTP, Recipients: target.Email, Redactor: n.redactor}
+		default:
+			return &WebhookSender{URL: target.Webhook, Client: client, Redactor: n.redactor}
+		}
+	}
+	return n
+}
+
+// SetRedactor sets the redactor of the secrets of the notifications sent, nothing is redacted when it is nil
+func (n *Notifier) SetRedactor(r *redact.Redactor) {
+	n.redactor = r
+}
+
+// Route returns the notification of each route matching at least one of the vulnerabilities
+func (n *Notifier) Route(scanID string, vulnerabilities []model.Vulnerability) []Notification {
+	results := make([]Result, len(vulnerabilities))
+	order := make([]int, len(vulnerabilities))
+	for i := range vulnerabilities {
+		results[i] = n.newResult(&vulnerabilities[i])
+		order[i] = i
+	}
+	// the most severe results first
+	sort.SliceStable(order, func(i, j int) bool {
+		si, sj := results[order[i]].Severity, results[order[j]].Severity
+		return si != sj && si.AtLeast(sj)
+	})
+
+	notifications := make([]Notification, 0, len(n.config.Routes))
+	for i := range n.config.Routes {
+		route := &n.config.Routes[i]
+		notification := Notification{
+			Route:            route.Name,
+			ScanID:           scanID,
+			SeverityCounters: map[model.Severity]int{},
+			Results:          make([]Result, 0),
+			targets:          route.Targets,
+		}
+		for _, j := range order {
+			if route.matches(&results[j]) {
+				notification.Results = append(notification.Results, results[j])
+				notification.SeverityCounters[results[j].Severity]++
+				notification.vulnerabilities = append(notification.vulnerabilities, vulnerabilities[j])
+			}
+		}
+		if notification.TotalCounter = len(notification.Results); notification.TotalCounter > 0 {
+			notifications = append(notifications, notification)
+		}
+	}
+	return notifications
+}
+
+// Notify sends the notifications of the routes matching the vulnerabilities to their targets,
+// a target that fails does not stop the others and the errors are returned together
+func (n *Notifier) Notify(ctx context.Context, scanID string, vulnerabilities []model.Vulnerability) error {
+	failures := make([]string, 0)
+	for _, notification := range n.Route(scanID, vulnerabilities) {
+		notification := notification
+		for _, target := range notification.targets {
+			if err := n.newSender(target).Send(ctx, &notification); err != nil {
+				failures = append(failures, fmt.Sprintf("route %s: %s", notification.Route, err))
+				continue
+			}
+			log.Debug().Msgf("Sent %d results of route %s", notification.TotalCounter, notification.Route)
+		}
+	}
+	if len(failures) > 0 {
+		return errors.Errorf("failed to send notifications: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func (n *Notifier) newResult(vulnerability *model.Vulnerability) Result {
+	fileName := filepath.ToSlash(vulnerability.FileName)
+	if rel, err := filepath.Rel(n.basePath, vulnerability.FileName); err == nil && !strings.HasPrefix(filepath.ToSlash(rel), "../") {
+		fileName = filepath.ToSlash(rel)
+	}
+	return Result{
+		QueryID:      vulnerability.QueryID,
+		QueryName:    vulnerability.QueryName,
+		Severity:     vulnerability.Severity,
+		Category:     vulnerability.Category,
+		FileName:     fileName,
+		Line:         vulnerability.Line,
+		SimilarityID: vulnerability.SimilarityID,
+	}
+}
+
+func isSeverity(severity model.Severity) bool {
+	for _, s := range model.AllSeverities {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
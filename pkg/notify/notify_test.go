@@ -0,0 +1,232 @@
+package notify
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/redact"
+	"github.com/stretchr/testify/require"
+)
+
+var vulnerabilities = []model.Vulnerability{
+	{QueryID: "q1", QueryName: "S3 Bucket Logging Disabled", Severity: model.SeverityMedium, FileName: "/src/infra/s3.tf", Line: 3},
+	{QueryID: "q2", QueryName: "Container Running As Root", Severity: model.SeverityHigh, FileName: "/src/apps/api/deploy.yaml", Line: 12},
+	{QueryID: "q3", QueryName: "Missing Tags", Severity: model.SeverityInfo, FileName: "/src/infra/vpc.tf", Line: 1},
+}
+
+// TestLoadConfig tests the functions [LoadConfig()] and all the methods called by them
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Setenv("KICS_TEST_SLACK_WEBHOOK", "https://hooks.slack.com/services/T0/B0/secret"))
+	defer os.Unsetenv("KICS_TEST_SLACK_WEBHOOK")
+	tests := []struct {
+		name    string
+		content string
+		want    *Config
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			content: "routes:\n  - name: platform\n    severity: medium\n    paths: [infra]\n" +
+				"    targets:\n      - slack: ${KICS_TEST_SLACK_WEBHOOK}\n        channel: '#platform'\n",
+			want: &Config{Routes: []Route{{
+				Name:     "platform",
+				Severity: "MEDIUM",
+				Paths:    []string{"infra"},
+				Targets:  []Target{{Slack: "https://hooks.slack.com/services/T0/B0/secret", Channel: "#platform"}},
+			}}},
+		},
+		{name: "empty", content: "", want: &Config{}},
+		{name: "invalid_severity", content: "routes:\n  - name: a\n    severity: urgent\n    targets:\n      - webhook: http://a\n", wantErr: true},
+		{name: "without_targets", content: "routes:\n  - name: a\n", wantErr: true},
+		{name: "two_urls", content: "routes:\n  - name: a\n    targets:\n      - webhook: http://a\n        slack: http://b\n", wantErr: true},
+		{name: "unknown_field", content: "routes:\n  - name: a\n    sevrity: low\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(dir, tt.name+".yaml")
+			require.NoError(t, os.WriteFile(configPath, []byte(tt.content), 0600))
+			got, err := LoadConfig(configPath)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestMatchPath tests the functions [matchPath()] and all the methods called by them
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		fileName string
+		want     bool
+	}{
+		{pattern: "infra", fileName: "infra/aws/s3.tf", want: true},
+		{pattern: "./infra/", fileName: "infra/aws/s3.tf", want: true},
+		{pattern: "*.tf", fileName: "infra/aws/s3.tf", want: true},
+		{pattern: "apps/*/deploy.yaml", fileName: "apps/api/deploy.yaml", want: true},
+		{pattern: "apps/*", fileName: "apps/api/k8s/deploy.yaml", want: true},
+		{pattern: "aws", fileName: "infra/aws/s3.tf", want: true},
+		{pattern: "infra/gcp", fileName: "infra/aws/s3.tf", want: false},
+		{pattern: "*.yaml", fileName: "infra/aws/s3.tf", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+"_"+tt.fileName, func(t *testing.T) {
+			require.Equal(t, tt.want, matchPath(tt.pattern, tt.fileName))
+		})
+	}
+}
+
+// TestNotifier_Route tests the functions [Route()] and all the methods called by them
+func TestNotifier_Route(t *testing.T) {
+	notifier := NewNotifier(&Config{Routes: []Route{
+		{Name: "platform", Paths: []string{"infra"}, Targets: []Target{{Webhook: "http://platform"}}},
+		{Name: "high", Severity: "HIGH", Targets: []Target{{Webhook: "http://high"}}},
+		{Name: "tags", Queries: []string{"q3"}, Paths: []string{"apps"}, Targets: []Target{{Webhook: "http://tags"}}},
+	}}, http.DefaultClient, "/src")
+
+	notifications := notifier.Route("scan", vulnerabilities)
+	require.Len(t, notifications, 2)
+
+	require.Equal(t, "platform", notifications[0].Route)
+	require.Equal(t, 2, notifications[0].TotalCounter)
+	require.Equal(t, map[model.Severity]int{model.SeverityMedium: 1, model.SeverityInfo: 1}, notifications[0].SeverityCounters)
+	require.Equal(t, "infra/s3.tf", notifications[0].Results[0].FileName)
+	require.Equal(t, []Target{{Webhook: "http://platform"}}, notifications[0].targets)
+
+	require.Equal(t, "high", notifications[1].Route)
+	require.Equal(t, []Result{{
+		QueryID:   "q2",
+		QueryName: "Container Running As Root",
+		Severity:  model.SeverityHigh,
+		FileName:  "apps/api/deploy.yaml",
+		Line:      12,
+	}}, notifications[1].Results)
+}
+
+// TestNotifier_Notify tests the functions [Notify()] and all the methods called by them
+func TestNotifier_Notify(t *testing.T) {
+	var slackMessages []slackMessage
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message slackMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		slackMessages = append(slackMessages, message)
+	}))
+	defer slack.Close()
+	var notifications []Notification
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var notification Notification
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&notification))
+		notifications = append(notifications, notification)
+	}))
+	defer webhook.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer failing.Close()
+
+	notifier := NewNotifier(&Config{Routes: []Route{
+		{Name: "platform", Paths: []string{"infra"}, Targets: []Target{{Slack: slack.URL, Channel: "#platform"}, {Webhook: failing.URL}}},
+		{Name: "apps", Paths: []string{"apps"}, Targets: []Target{{Webhook: webhook.URL}}},
+	}}, slack.Client(), "/src")
+
+	err := notifier.Notify(context.Background(), "scan", vulnerabilities)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "route platform")
+	require.NotContains(t, err.Error(), failing.URL)
+
+	require.Len(t, slackMessages, 1)
+	require.Equal(t, "#platform", slackMessages[0].Channel)
+	require.True(t, strings.HasPrefix(slackMessages[0].Text, "*KICS* found 2 results for platform (MEDIUM: 1, INFO: 1)"))
+	require.Contains(t, slackMessages[0].Text, "[MEDIUM] S3 Bucket Logging Disabled: `infra/s3.tf:3`")
+
+	require.Len(t, notifications, 1)
+	require.Equal(t, "apps", notifications[0].Route)
+	require.Equal(t, "scan", notifications[0].ScanID)
+	require.Equal(t, 1, notifications[0].TotalCounter)
+}
+
+// TestNotifier_Redact tests the functions [Notify()] with a redactor and all the methods called by them
+func TestNotifier_Redact(t *testing.T) {
+	require.NoError(t, os.Setenv("KICS_TEST_NOTIFY_TOKEN", "ghp_0123456789abcdef"))
+	defer os.Unsetenv("KICS_TEST_NOTIFY_TOKEN")
+	redactor, err := redact.New([]string{"KICS_TEST_NOTIFY_TOKEN"}, nil)
+	require.NoError(t, err)
+
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body strings.Builder
+		_, err := io.Copy(&body, r.Body)
+		require.NoError(t, err)
+		bodies = append(bodies, body.String())
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(&Config{Routes: []Route{
+		{Name: "all", Targets: []Target{{Slack: server.URL}, {Webhook: server.URL}}},
+	}}, server.Client(), "/src")
+	notifier.SetRedactor(redactor)
+	require.NoError(t, notifier.Notify(context.Background(), "scan", []model.Vulnerability{
+		{QueryID: "q1", QueryName: "Secret", Severity: model.SeverityHigh, FileName: "/src/ghp_0123456789abcdef.tf", Line: 1},
+	}))
+
+	require.Len(t, bodies, 2)
+	for _, body := range bodies {
+		require.NotContains(t, body, "ghp_0123456789abcdef")
+		require.Contains(t, body, redact.Placeholder)
+	}
+}
+
+// TestEmailSender tests the functions [Send()] of EmailSender and all the methods called by them
+func TestEmailSender(t *testing.T) {
+	defer func(original func(string, smtp.Auth, string, []string, []byte) error) { sendMail = original }(sendMail)
+	var addr, from string
+	var to []string
+	var message []byte
+	sendMail = func(a string, _ smtp.Auth, f string, recipients []string, msg []byte) error {
+		addr, from, to, message = a, f, recipients, msg
+		return nil
+	}
+
+	config := &Config{
+		SMTP:   &SMTP{Host: "smtp.example.com", From: "kics@example.com"},
+		Routes: []Route{{Name: "platform", Paths: []string{"infra"}, Targets: []Target{{Email: []string{"platform@example.com"}}}}},
+	}
+	require.NoError(t, config.validate())
+	notifier := NewNotifier(config, http.DefaultClient, "/src")
+	require.NoError(t, notifier.Notify(context.Background(), "scan", vulnerabilities))
+
+	require.Equal(t, "smtp.example.com:587", addr)
+	require.Equal(t, "kics@example.com", from)
+	require.Equal(t, []string{"platform@example.com"}, to)
+	headers, body := splitMessage(string(message))
+	require.Contains(t, headers, "Subject: KICS found 2 results for platform\r\n")
+	require.Contains(t, headers, "Content-Type: text/html")
+	html, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(body, "\r\n", ""))
+	require.NoError(t, err)
+	require.Contains(t, string(html), "S3 Bucket Logging Disabled")
+
+	config.Routes[0].Targets = []Target{{Email: []string{"platform@example.com"}, Webhook: "http://platform"}}
+	require.Error(t, config.validate())
+	config.Routes[0].Targets = []Target{{Email: []string{"platform@example.com"}}}
+	config.SMTP = nil
+	require.Error(t, config.validate())
+}
+
+func splitMessage(message string) (headers, body string) {
+	parts := strings.SplitN(message, "\r\n\r\n", 2)
+	return parts[0] + "\r\n", parts[1]
+}
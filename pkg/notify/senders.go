@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/redact"
+	"github.com/pkg/errors"
+)
+
+// slackMaxResults is the number of results listed in a Slack message, the others are only counted
+const slackMaxResults = 20
+
+// SlackSender posts the notifications as messages to a Slack incoming webhook, with the secrets of the redactor,
+// if any, redacted
+type SlackSender struct {
+	URL      string
+	Channel  string
+	Client   *http.Client
+	Redactor *redact.Redactor
+}
+
+type slackMessage struct {
+	Channel string `json:"channel,omitempty"`
+	Text    string `json:"text"`
+}
+
+// Send posts a message with the severity counters of the notification and its first results
+func (s *SlackSender) Send(ctx context.Context, notification *Notification) error {
+	return post(ctx, s.Client, s.Redactor, s.URL, slackMessage{
+		Channel: s.Channel,
+		Text:    slackText(notification),
+	})
+}
+
+func slackText(notification *Notification) string {
+	var sb strings.Builder
+	counters := make([]string, 0, len(model.AllSeverities))
+	for _, severity := range model.AllSeverities {
+		if count := notification.SeverityCounters[severity]; count > 0 {
+			counters = append(counters, fmt.Sprintf("%s: %d", severity, count))
+		}
+	}
+	fmt.Fprintf(&sb, "*KICS* found %d results for %s (%s)\n",
+		notification.TotalCounter, notification.Route, strings.Join(counters, ", "))
+	for i := range notification.Results {
+		if i == slackMaxResults {
+			fmt.Fprintf(&sb, "... and %d more results\n", notification.TotalCounter-slackMaxResults)
+			break
+		}
+		result := &notification.Results[i]
+		fmt.Fprintf(&sb, "• [%s] %s: `%s:%d`\n", result.Severity, result.QueryName, result.FileName, result.Line)
+	}
+	return sb.String()
+}
+
+// WebhookSender posts the notifications as JSON to an HTTP(S) endpoint, with the secrets of the redactor,
+// if any, redacted
+type WebhookSender struct {
+	URL      string
+	Client   *http.Client
+	Redactor *redact.Redactor
+}
+
+// Send posts the notification
+func (w *WebhookSender) Send(ctx context.Context, notification *Notification) error {
+	return post(ctx, w.Client, w.Redactor, w.URL, notification)
+}
+
+func post(ctx context.Context, client *http.Client, redactor *redact.Redactor, target string, body interface{}) error {
+	content, err := json.Marshal(body)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal notification")
+	}
+	content = redactor.Bytes(content)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(content))
+	if err != nil {
+		return errors.Wrap(err, "failed to create notification request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		// the URL is left out of the error since webhook URLs are secrets
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return errors.Wrap(err, "failed to post notification")
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return errors.Errorf("failed to post notification: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package bicep
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenType int
+
+const (
+	tokenIdentifier tokenType = iota
+	tokenNumber
+	tokenString
+	tokenPunct
+	tokenNewline
+)
+
+// token is a lexical unit of a Bicep file with the line where it starts, the strings with interpolations keep their
+// literal parts in parts and the tokens of each interpolation in interpolations, parts has one more element
+type token struct {
+	kind           tokenType
+	value          string
+	line           int
+	parts          []string
+	interpolations [][]token
+}
+
+var twoCharPuncts = map[string]struct{}{
+	"==": {},
+	"!=": {},
+	"=~": {},
+	"!~": {},
+	"<=": {},
+	">=": {},
+	"&&": {},
+	"||": {},
+	"??": {},
+	"?.": {},
+	"::": {},
+}
+
+// tokenize splits the content of a Bicep file into tokens, skipping whitespaces and comments, the line breaks
+// separate statements and the members of objects and arrays so they are kept, once for consecutive line breaks
+func tokenize(content string, line int) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(content)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case c == '\n':
+			if len(tokens) > 0 && tokens[len(tokens)-1].kind != tokenNewline {
+				tokens = append(tokens, token{kind: tokenNewline, value: "\n", line: line})
+			}
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			j := i + 2
+			for j+1 < len(runes) && !(runes[j] == '*' && runes[j+1] == '/') {
+				if runes[j] == '\n' {
+					line++
+				}
+				j++
+			}
+			if j+1 >= len(runes) {
+				return nil, fmt.Errorf("unterminated comment at line %d", line)
+			}
+			i = j + 2
+		case c == '\'' && i+2 < len(runes) && runes[i+1] == '\'' && runes[i+2] == '\'':
+			value, consumed, lines, err := readMultilineString(runes[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%s at line %d", err.Error(), line)
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value, line: line, parts: []string{value}})
+			line += lines
+			i += consumed
+		case c == '\'':
+			t, consumed, lines, err := readString(runes[i:], line)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, t)
+			line += lines
+			i += consumed
+		case isDigit(c):
+			j := i
+			for j < len(runes) && isDigit(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: string(runes[i:j]), line: line})
+			i = j
+		case isIdentifierRune(c):
+			j := i
+			for j < len(runes) && (isIdentifierRune(runes[j]) || isDigit(runes[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdentifier, value: string(runes[i:j]), line: line})
+			i = j
+		default:
+			if i+1 < len(runes) {
+				if _, ok := twoCharPuncts[string(runes[i:i+2])]; ok {
+					tokens = append(tokens, token{kind: tokenPunct, value: string(runes[i : i+2]), line: line})
+					i += 2
+					continue
+				}
+			}
+			tokens = append(tokens, token{kind: tokenPunct, value: string(c), line: line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// readString reads a single quoted string, with its escapes and interpolations (ex: 'name-${suffix}'), returning
+// the token, the number of runes consumed and the number of line breaks inside it
+func readString(runes []rune, line int) (t token, consumed, lines int, err error) {
+	t = token{kind: tokenString, line: line}
+	var sb strings.Builder
+	for i := 1; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\\':
+			if i+1 >= len(runes) {
+				return t, 0, 0, fmt.Errorf("unterminated string at line %d", line)
+			}
+			escaped, n, err := readEscape(runes[i+1:])
+			if err != nil {
+				return t, 0, 0, fmt.Errorf("%s at line %d", err.Error(), line+lines)
+			}
+			sb.WriteString(escaped)
+			i += n
+		case runes[i] == '$' && i+1 < len(runes) && runes[i+1] == '{':
+			end := findInterpolationEnd(runes, i+2)
+			if end < 0 {
+				return t, 0, 0, fmt.Errorf("unterminated interpolation at line %d", line+lines)
+			}
+			interpolation, err := tokenize(string(runes[i+2:end]), line+lines)
+			if err != nil {
+				return t, 0, 0, err
+			}
+			lines += strings.Count(string(runes[i+2:end]), "\n")
+			t.parts = append(t.parts, sb.String())
+			t.interpolations = append(t.interpolations, interpolation)
+			sb.Reset()
+			i = end
+		case runes[i] == '\'':
+			t.parts = append(t.parts, sb.String())
+			t.value = strings.Join(t.parts, "")
+			return t, i + 1, lines, nil
+		case runes[i] == '\n':
+			return t, 0, 0, fmt.Errorf("unterminated string at line %d", line+lines)
+		default:
+			sb.WriteRune(runes[i])
+		}
+	}
+	return t, 0, 0, fmt.Errorf("unterminated string at line %d", line)
+}
+
+// readEscape reads the escape sequence after a backslash, returning its value and the number of runes read
+func readEscape(runes []rune) (value string, consumed int, err error) {
+	switch runes[0] {
+	case '\\', '\'', '$':
+		return string(runes[0]), 1, nil
+	case 'n':
+		return "\n", 1, nil
+	case 'r':
+		return "\r", 1, nil
+	case 't':
+		return "\t", 1, nil
+	case 'u':
+		end := 0
+		for end < len(runes) && runes[end] != '}' {
+			end++
+		}
+		if len(runes) < 3 || runes[1] != '{' || end == len(runes) {
+			return "", 0, fmt.Errorf("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(string(runes[2:end]), 16, 32)
+		if err != nil {
+			return "", 0, fmt.Errorf("invalid unicode escape")
+		}
+		return string(rune(code)), end + 1, nil
+	}
+	return "", 0, fmt.Errorf("invalid escape sequence \\%c", runes[0])
+}
+
+// findInterpolationEnd returns the index of the brace closing the interpolation starting at start, skipping the
+// braces of the objects and strings inside it, or -1 if it is not closed
+func findInterpolationEnd(runes []rune, start int) int {
+	depth := 0
+	for i := start; i < len(runes); i++ {
+		switch runes[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case '\'':
+			t, consumed, _, err := readString(runes[i:], 0)
+			if err != nil || t.kind != tokenString {
+				return -1
+			}
+			i += consumed - 1
+		case '\n':
+			return -1
+		}
+	}
+	return -1
+}
+
+// readMultilineString reads a multi-line string, between three single quotes, which has no escapes nor interpolations,
+// the line break right after the opening quotes is not part of the string
+func readMultilineString(runes []rune) (value string, consumed, lines int, err error) {
+	content := string(runes[3:])
+	end := strings.Index(content, "'''")
+	if end < 0 {
+		return "", 0, 0, fmt.Errorf("unterminated multi-line string")
+	}
+	value = content[:end]
+	lines = strings.Count(value, "\n")
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "\r"), "\n")
+	return value, len([]rune(content[:end])) + 6, lines, nil
+}
+
+func isIdentifierRune(c rune) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c rune) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,59 @@
+package bicep
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/pkg/errors"
+)
+
+// Parser is an Azure Bicep file parser, the files are converted to the ARM templates the Bicep compiler builds
+// so the queries of Azure Resource Manager apply to them
+type Parser struct {
+}
+
+// Parse parses a Bicep file and returns its ARM template as a Document, the lines of the Bicep file of the paths
+// of the template are kept in the document so the results are reported on the lines of the Bicep file
+func (p *Parser) Parse(_ string, fileContent []byte) ([]model.Document, error) {
+	tokens, err := tokenize(strings.ReplaceAll(string(fileContent), "\r", ""), 1)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse Bicep file")
+	}
+	prog, err := parse(tokens)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse Bicep file")
+	}
+	template, lines, err := convert(prog)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert Bicep file")
+	}
+
+	j, err := json.Marshal(template)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to Marshal Bicep template")
+	}
+
+	doc := model.Document{}
+	if err := json.Unmarshal(j, &doc); err != nil {
+		return nil, errors.Wrap(err, "failed to Unmarshal Bicep template")
+	}
+	doc[model.SourceLinesKey] = lines
+
+	return []model.Document{doc}, nil
+}
+
+// GetKind returns BICEP constant kind
+func (p *Parser) GetKind() model.FileKind {
+	return model.KindBICEP
+}
+
+// SupportedExtensions returns extensions supported by this parser, which is bicep extension
+func (p *Parser) SupportedExtensions() []string {
+	return []string{".bicep"}
+}
+
+// SupportedTypes returns types supported by this parser, which is azureResourceManager
+func (p *Parser) SupportedTypes() []string {
+	return []string{"AzureResourceManager"}
+}
@@ -0,0 +1,199 @@
+package bicep
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParser_GetKind tests the functions [GetKind()] and all the methods called by them
+func TestParser_GetKind(t *testing.T) {
+	p := &Parser{}
+	require.Equal(t, model.KindBICEP, p.GetKind())
+}
+
+// TestParser_SupportedExtensions tests the functions [SupportedExtensions()] and all the methods called by them
+func TestParser_SupportedExtensions(t *testing.T) {
+	p := &Parser{}
+	require.Equal(t, []string{".bicep"}, p.SupportedExtensions())
+}
+
+// TestParser_SupportedTypes tests the functions [SupportedTypes()] and all the methods called by them
+func TestParser_SupportedTypes(t *testing.T) {
+	p := &Parser{}
+	require.Equal(t, []string{"AzureResourceManager"}, p.SupportedTypes())
+}
+
+// TestParser_Parse tests the functions [Parse()] and all the methods called by them
+func TestParser_Parse(t *testing.T) {
+	content, err := os.ReadFile(filepath.FromSlash("../../../test/fixtures/test_bicep/main.bicep"))
+	require.NoError(t, err)
+
+	docs, err := (&Parser{}).Parse("main.bicep", content)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	doc := docs[0]
+	require.Equal(t, "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#", doc["$schema"])
+
+	parameters := doc["parameters"].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{"type": "securestring"}, parameters["adminPassword"])
+	require.Equal(t, map[string]interface{}{
+		"type":          "string",
+		"defaultValue":  "Standard_LRS",
+		"allowedValues": []interface{}{"Standard_LRS", "Standard_GRS"},
+	}, parameters["skuName"])
+	metadata := parameters["location"].(map[string]interface{})["metadata"].(map[string]interface{})
+	require.Equal(t, "Location of the resources", metadata["description"])
+	variables := doc["variables"].(map[string]interface{})
+	require.Equal(t, "[format('st{0}', uniqueString(resourceGroup().id))]", variables["storageName"])
+
+	// the nested resources follow their parent and the existing resources are not deployed
+	resources := doc["resources"].([]interface{})
+	require.Len(t, resources, 5)
+	storage := resources[0].(map[string]interface{})
+	require.Equal(t, "Microsoft.Storage/storageAccounts", storage["type"])
+	require.Equal(t, "2021-02-01", storage["apiVersion"])
+	require.Equal(t, "[variables('storageName')]", storage["name"])
+	require.Equal(t, "[parameters('location')]", storage["location"])
+	require.Equal(t, map[string]interface{}{
+		"supportsHttpsTrafficOnly": false,
+		"minimumTlsVersion":        "TLS1_0",
+		"allowBlobPublicAccess":    true,
+	}, storage["properties"])
+
+	blobService := resources[1].(map[string]interface{})
+	require.Equal(t, "Microsoft.Storage/storageAccounts/blobServices", blobService["type"])
+	require.Equal(t, "[format('{0}/{1}', variables('storageName'), 'default')]", blobService["name"])
+	require.Equal(t, []interface{}{"[resourceId('Microsoft.Storage/storageAccounts', variables('storageName'))]"}, blobService["dependsOn"])
+
+	containers := resources[2].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{"name": "containers", "count": "[length(parameters('containerNames'))]"}, containers["copy"])
+	require.Equal(t, "[format('{0}/default/{1}', variables('storageName'), parameters('containerNames')[copyIndex()])]", containers["name"])
+
+	subnet := resources[3].(map[string]interface{})
+	require.Equal(t, "shared-vnet/app", subnet["name"])
+	require.Equal(t, "[equals(parameters('skuName'), 'Standard_GRS')]", subnet["condition"])
+	require.NotContains(t, subnet, "dependsOn")
+
+	database := resources[4].(map[string]interface{})
+	require.Equal(t, DeploymentType, database["type"])
+	require.Equal(t, map[string]interface{}{"value": "[parameters('adminPassword')]"},
+		database["properties"].(map[string]interface{})["parameters"].(map[string]interface{})["password"])
+
+	outputs := doc["outputs"].(map[string]interface{})
+	require.Equal(t,
+		"[reference(resourceId('Microsoft.Storage/storageAccounts', variables('storageName')), '2021-02-01').primaryEndpoints.blob]",
+		outputs["endpoint"].(map[string]interface{})["value"])
+	require.Equal(t, "[reference(resourceId('Microsoft.Resources/deployments', 'database')).outputs.connection.value]",
+		outputs["connection"].(map[string]interface{})["value"])
+
+	lines := doc[model.SourceLinesKey].(map[string]int)
+	require.Equal(t, 27, lines["resources.0"])
+	require.Equal(t, 36, lines["resources.0.properties.supportsHttpsTrafficOnly"])
+	require.Equal(t, 49, lines["resources.2.properties.publicAccess"])
+	require.Equal(t, 10, lines["parameters.skuName.allowedValues"])
+}
+
+// TestParser_ParseExpressions tests the functions [Parse()] converting the expressions and loops of Bicep
+func TestParser_ParseExpressions(t *testing.T) {
+	sample := `targetScope = 'subscription'
+
+param names array
+param enabled bool = !false
+
+var count = length(names) > 2 ? 2 : -1
+var greeting = 'it\'s ${names[0]} {x}'
+var escaped = '[not an expression]'
+var multiline = '''
+first
+second'''
+var items = [for (name, i) in names: {
+  name: name
+  index: i
+}]
+
+resource groups 'Microsoft.Resources/resourceGroups@2021-04-01' = [for name in names: if (enabled && name != 'skip') {
+  name: name
+  location: 'westeurope'
+  tags: {
+    copies: 'yes'
+    list: [for tag in names: tag]
+  }
+}]
+
+output first string = groups[0].name
+output ids array = [for (name, i) in names: groups[i].id]
+`
+	docs, err := (&Parser{}).Parse("main.bicep", []byte(sample))
+	require.NoError(t, err)
+	doc := docs[0]
+	require.Equal(t, "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#", doc["$schema"])
+	require.Equal(t, "[not(false())]", doc["parameters"].(map[string]interface{})["enabled"].(map[string]interface{})["defaultValue"])
+
+	variables := doc["variables"].(map[string]interface{})
+	require.Equal(t, "[if(greater(length(parameters('names')), 2), 2, -1)]", variables["count"])
+	require.Equal(t, "[format('it''s {0} {{x}}', parameters('names')[0])]", variables["greeting"])
+	require.Equal(t, "[[not an expression]", variables["escaped"])
+	require.Equal(t, "first\nsecond", variables["multiline"])
+	require.Equal(t, []interface{}{map[string]interface{}{
+		"name":  "items",
+		"count": "[length(parameters('names'))]",
+		"input": map[string]interface{}{
+			"name":  "[parameters('names')[copyIndex('items')]]",
+			"index": "[copyIndex('items')]",
+		},
+	}}, variables["copy"])
+
+	group := doc["resources"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "[and(parameters('enabled'), not(equals(parameters('names')[copyIndex()], 'skip')))]", group["condition"])
+	require.Equal(t, "[parameters('names')[copyIndex()]]", group["name"])
+	require.Equal(t, map[string]interface{}{
+		"copies": "yes",
+		"copy": []interface{}{map[string]interface{}{
+			"name":  "list",
+			"count": "[length(parameters('names'))]",
+			"input": "[parameters('names')[copyIndex('list')]]",
+		}},
+	}, group["tags"])
+
+	outputs := doc["outputs"].(map[string]interface{})
+	require.Equal(t, "[parameters('names')[0]]", outputs["first"].(map[string]interface{})["value"])
+	require.Equal(t, map[string]interface{}{
+		"count": "[length(parameters('names'))]",
+		"input": "[resourceId('Microsoft.Resources/resourceGroups', parameters('names')[copyIndex()])]",
+	}, outputs["ids"].(map[string]interface{})["copy"])
+}
+
+// TestParser_ParseInvalid tests the functions [Parse()] with files that can't be parsed or converted
+func TestParser_ParseInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "unterminated_string",
+			content: "param name string = 'abc\n",
+		},
+		{
+			name:    "unterminated_object",
+			content: "resource sa 'Microsoft.Storage/storageAccounts@2021-02-01' = {\n  name: 'sa'\n",
+		},
+		{
+			name:    "invalid_target_scope",
+			content: "targetScope = 'cluster'\n",
+		},
+		{
+			name:    "loop_in_argument",
+			content: "var names = length([for i in range(0, 2): i])\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&Parser{}).Parse("main.bicep", []byte(tt.content))
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,741 @@
+package bicep
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// expression is an expression of a Bicep file, with the line where it starts
+type expression interface {
+	getLine() int
+}
+
+type position struct {
+	line int
+}
+
+func (p position) getLine() int {
+	return p.line
+}
+
+// literal is a string, an int64, a bool or nil
+type literal struct {
+	position
+	value interface{}
+}
+
+// interpolation is a string with interpolations, parts has one more element than expressions
+type interpolation struct {
+	position
+	parts       []string
+	expressions []expression
+}
+
+type property struct {
+	key   string
+	value expression
+	line  int
+}
+
+type object struct {
+	position
+	properties []*property
+}
+
+type array struct {
+	position
+	items []expression
+}
+
+// reference is a reference to a parameter, variable, resource, module or loop variable
+type reference struct {
+	position
+	name string
+}
+
+type access struct {
+	position
+	target expression
+	member string
+}
+
+type index struct {
+	position
+	target expression
+	index  expression
+}
+
+// call is a function call, target is the namespace (ex: az) or the value (ex: a resource) of the methods
+type call struct {
+	position
+	target expression
+	name   string
+	args   []expression
+}
+
+type unary struct {
+	position
+	operator string
+	operand  expression
+}
+
+type binary struct {
+	position
+	operator    string
+	left, right expression
+}
+
+type ternary struct {
+	position
+	condition, whenTrue, whenFalse expression
+}
+
+// forLoop is a for-expression ([for (item, index) in source: body]), index is empty when not declared
+type forLoop struct {
+	position
+	item, index string
+	source      expression
+	body        expression
+}
+
+// condition is the condition of a resource or module deployed only if it is true (if (condition) body)
+type condition struct {
+	position
+	condition expression
+	body      expression
+}
+
+type decorator struct {
+	name string
+	args []expression
+	line int
+}
+
+// declaration is a metadata, parameter, variable or output declaration
+type declaration struct {
+	name       string
+	typeName   string
+	value      expression
+	decorators []*decorator
+	line       int
+}
+
+// resource is a resource or module declaration, the type of the modules is the path of their file, parent is the
+// resource whose body declares the resource, if any
+type resource struct {
+	symbol       string
+	resourceType string
+	apiVersion   string
+	module       bool
+	existing     bool
+	value        expression
+	parent       *resource
+	decorators   []*decorator
+	line         int
+}
+
+// program is the declarations of a Bicep file, resources includes the nested resources, after the resource declaring them
+type program struct {
+	targetScope string
+	metadata    []*declaration
+	parameters  []*declaration
+	variables   []*declaration
+	outputs     []*declaration
+	resources   []*resource
+}
+
+// binaryPrecedences are the precedences of the binary operators, the higher the tighter
+var binaryPrecedences = map[string]int{
+	"??": 1,
+	"||": 2,
+	"&&": 3,
+	"==": 4, "!=": 4, "=~": 4, "!~": 4,
+	"<": 5, ">": 5, "<=": 5, ">=": 5,
+	"+": 6, "-": 6,
+	"*": 7, "/": 7, "%": 7,
+}
+
+// syntaxParser parses the tokens of a Bicep file, parents is the stack of the resources whose body is being parsed
+type syntaxParser struct {
+	tokens  []token
+	pos     int
+	program *program
+	parents []*resource
+}
+
+// parse parses the statements of a Bicep file, the statements not supported (ex: import, type and func) are skipped
+func parse(tokens []token) (*program, error) {
+	p := &syntaxParser{tokens: tokens, program: &program{targetScope: "resourceGroup"}}
+	for {
+		p.skipNewlines()
+		if p.eof() {
+			return p.program, nil
+		}
+		decorators, err := p.parseDecorators()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.parseStatement(decorators); err != nil {
+			return nil, err
+		}
+		if !p.eof() && p.peek().kind != tokenNewline {
+			return nil, p.unexpected()
+		}
+	}
+}
+
+func (p *syntaxParser) parseStatement(decorators []*decorator) error {
+	keyword := p.next()
+	if keyword.kind != tokenIdentifier {
+		return p.unexpectedToken(keyword)
+	}
+	switch keyword.value {
+	case "targetScope":
+		return p.parseTargetScope()
+	case "metadata", "param", "var", "output":
+		d, err := p.parseAssignment(keyword, decorators)
+		if err != nil {
+			return err
+		}
+		switch keyword.value {
+		case "metadata":
+			p.program.metadata = append(p.program.metadata, d)
+		case "param":
+			p.program.parameters = append(p.program.parameters, d)
+		case "var":
+			p.program.variables = append(p.program.variables, d)
+		default:
+			p.program.outputs = append(p.program.outputs, d)
+		}
+	case "resource", "module":
+		return p.parseResource(keyword, decorators)
+	default:
+		p.skipStatement()
+	}
+	return nil
+}
+
+func (p *syntaxParser) parseTargetScope() error {
+	if err := p.expect("="); err != nil {
+		return err
+	}
+	value := p.next()
+	if value.kind != tokenString || len(value.interpolations) > 0 {
+		return p.unexpectedToken(value)
+	}
+	p.program.targetScope = value.value
+	return nil
+}
+
+// parseAssignment parses the declarations of metadata, parameters, variables and outputs, the parameters and outputs
+// have a type and the value of the parameters is their default value, if any
+func (p *syntaxParser) parseAssignment(keyword token, decorators []*decorator) (*declaration, error) {
+	name := p.next()
+	if name.kind != tokenIdentifier {
+		return nil, p.unexpectedToken(name)
+	}
+	d := &declaration{name: name.value, decorators: decorators, line: keyword.line}
+	if keyword.value == "param" || keyword.value == "output" {
+		d.typeName = p.parseType()
+	}
+	if keyword.value == "param" && !p.isPunct("=") {
+		return d, nil
+	}
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	value, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	d.value = value
+	return d, nil
+}
+
+// parseType parses the type of a parameter or output, returning the name of its type (ex: string or array), the user
+// defined types and the types of the resources are simplified to object and string
+func (p *syntaxParser) parseType() string {
+	start := p.peek()
+	typeName := ""
+	switch {
+	case start.kind == tokenIdentifier && start.value == "resource":
+		typeName = "string"
+	case start.kind == tokenIdentifier:
+		typeName = start.value
+	case start.kind == tokenString:
+		typeName = "string"
+	case start.kind == tokenPunct && start.value == "[":
+		typeName = "array"
+	default:
+		typeName = "object"
+	}
+	last := token{}
+	for depth := 0; !p.eof(); p.pos++ {
+		t := p.peek()
+		if depth == 0 && (t.kind == tokenNewline || (t.kind == tokenPunct && t.value == "=")) {
+			break
+		}
+		if t.kind == tokenPunct && (t.value == "{" || t.value == "[" || t.value == "(") {
+			depth++
+		} else if t.kind == tokenPunct && (t.value == "}" || t.value == "]" || t.value == ")") {
+			depth--
+		}
+		last = t
+	}
+	if last.kind == tokenPunct && last.value == "]" && start.kind == tokenIdentifier {
+		return "array"
+	}
+	return typeName
+}
+
+// parseResource parses a resource or module declaration, the nested resources of its body are added after it
+func (p *syntaxParser) parseResource(keyword token, decorators []*decorator) error {
+	symbol := p.next()
+	if symbol.kind != tokenIdentifier {
+		return p.unexpectedToken(symbol)
+	}
+	typeToken := p.next()
+	if typeToken.kind != tokenString || len(typeToken.interpolations) > 0 {
+		return p.unexpectedToken(typeToken)
+	}
+	r := &resource{
+		symbol:     symbol.value,
+		module:     keyword.value == "module",
+		decorators: decorators,
+		line:       keyword.line,
+	}
+	if len(p.parents) > 0 {
+		r.parent = p.parents[len(p.parents)-1]
+	}
+	r.resourceType = typeToken.value
+	if !r.module {
+		if i := strings.LastIndex(typeToken.value, "@"); i >= 0 {
+			r.resourceType, r.apiVersion = typeToken.value[:i], typeToken.value[i+1:]
+		}
+	}
+	if t := p.peek(); t.kind == tokenIdentifier && t.value == "existing" {
+		r.existing = true
+		p.pos++
+	}
+	if err := p.expect("="); err != nil {
+		return err
+	}
+	p.program.resources = append(p.program.resources, r)
+
+	p.parents = append(p.parents, r)
+	defer func() { p.parents = p.parents[:len(p.parents)-1] }()
+	value, err := p.parseResourceValue()
+	if err != nil {
+		return err
+	}
+	r.value = value
+	return nil
+}
+
+// parseResourceValue parses the body of a resource or module, which may be conditional or declared in a loop
+func (p *syntaxParser) parseResourceValue() (expression, error) {
+	if t := p.peek(); t.kind == tokenIdentifier && t.value == "if" {
+		return p.parseCondition()
+	}
+	return p.parseExpression()
+}
+
+func (p *syntaxParser) parseCondition() (expression, error) {
+	keyword := p.next()
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	p.skipNewlines()
+	value, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	p.skipNewlines()
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	body, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	return &condition{position: position{line: keyword.line}, condition: value, body: body}, nil
+}
+
+func (p *syntaxParser) parseDecorators() ([]*decorator, error) {
+	decorators := make([]*decorator, 0)
+	for p.isPunct("@") {
+		at := p.next()
+		value, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		d := &decorator{line: at.line}
+		switch v := value.(type) {
+		case *call:
+			d.name, d.args = v.name, v.args
+		case *reference:
+			d.name = v.name
+		default:
+			return nil, fmt.Errorf("invalid decorator at line %d", at.line)
+		}
+		decorators = append(decorators, d)
+		p.skipNewlines()
+	}
+	return decorators, nil
+}
+
+func (p *syntaxParser) parseExpression() (expression, error) {
+	value, err := p.parseBinary(1)
+	if err != nil || !p.isPunct("?") {
+		return value, err
+	}
+	p.pos++
+	whenTrue, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	whenFalse, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	return &ternary{position: position{line: value.getLine()}, condition: value, whenTrue: whenTrue, whenFalse: whenFalse}, nil
+}
+
+// parseBinary parses the binary operations whose operators have at least the precedence, from left to right
+func (p *syntaxParser) parseBinary(precedence int) (expression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		operatorPrecedence, ok := binaryPrecedences[t.value]
+		if t.kind != tokenPunct || !ok || operatorPrecedence < precedence {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseBinary(operatorPrecedence + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{position: position{line: left.getLine()}, operator: t.value, left: left, right: right}
+	}
+}
+
+func (p *syntaxParser) parseUnary() (expression, error) {
+	if p.isPunct("!") || p.isPunct("-") {
+		operator := p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unary{position: position{line: operator.line}, operator: operator.value, operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+// parsePostfix parses the accesses to properties, the indexes, the method calls and the accesses
+// to nested resources (parent::child) after a primary expression
+func (p *syntaxParser) parsePostfix() (expression, error) {
+	value, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.isPunct(".") || p.isPunct("?."):
+			p.pos++
+			member := p.next()
+			if member.kind != tokenIdentifier {
+				return nil, p.unexpectedToken(member)
+			}
+			if !p.isPunct("(") {
+				value = &access{position: position{line: value.getLine()}, target: value, member: member.value}
+				continue
+			}
+			args, err := p.parseArguments()
+			if err != nil {
+				return nil, err
+			}
+			value = &call{position: position{line: value.getLine()}, target: value, name: member.value, args: args}
+		case p.isPunct("["):
+			p.pos++
+			if p.isPunct("?") {
+				p.pos++
+			}
+			i, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			value = &index{position: position{line: value.getLine()}, target: value, index: i}
+		case p.isPunct("::"):
+			p.pos++
+			member := p.next()
+			if member.kind != tokenIdentifier {
+				return nil, p.unexpectedToken(member)
+			}
+			value = &reference{position: position{line: member.line}, name: member.value}
+		default:
+			return value, nil
+		}
+	}
+}
+
+func (p *syntaxParser) parsePrimary() (expression, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString:
+		return p.parseString(t)
+	case tokenNumber:
+		value, err := strconv.ParseInt(t.value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s at line %d", t.value, t.line)
+		}
+		return &literal{position: position{line: t.line}, value: value}, nil
+	case tokenIdentifier:
+		switch t.value {
+		case "true", "false":
+			return &literal{position: position{line: t.line}, value: t.value == "true"}, nil
+		case "null":
+			return &literal{position: position{line: t.line}}, nil
+		}
+		if !p.isPunct("(") {
+			return &reference{position: position{line: t.line}, name: t.value}, nil
+		}
+		args, err := p.parseArguments()
+		if err != nil {
+			return nil, err
+		}
+		return &call{position: position{line: t.line}, name: t.value, args: args}, nil
+	case tokenPunct:
+		switch t.value {
+		case "(":
+			p.skipNewlines()
+			value, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+			p.skipNewlines()
+			return value, p.expect(")")
+		case "{":
+			return p.parseObject(t)
+		case "[":
+			return p.parseArray(t)
+		}
+	}
+	return nil, p.unexpectedToken(t)
+}
+
+// parseString parses a string, its interpolations are parsed with their own parser
+func (p *syntaxParser) parseString(t token) (expression, error) {
+	if len(t.interpolations) == 0 {
+		return &literal{position: position{line: t.line}, value: t.value}, nil
+	}
+	value := &interpolation{position: position{line: t.line}, parts: t.parts}
+	for _, tokens := range t.interpolations {
+		inner := &syntaxParser{tokens: tokens, program: p.program}
+		expr, err := inner.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if !inner.eof() {
+			return nil, inner.unexpected()
+		}
+		value.expressions = append(value.expressions, expr)
+	}
+	return value, nil
+}
+
+func (p *syntaxParser) parseArguments() ([]expression, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	args := make([]expression, 0)
+	for {
+		p.skipSeparators()
+		if p.isPunct(")") {
+			p.pos++
+			return args, nil
+		}
+		arg, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+}
+
+// parseObject parses an object, the resources declared in the body of a resource are nested resources
+func (p *syntaxParser) parseObject(open token) (expression, error) {
+	value := &object{position: position{line: open.line}, properties: make([]*property, 0)}
+	for {
+		p.skipSeparators()
+		if p.isPunct("}") {
+			p.pos++
+			return value, nil
+		}
+		decorators, err := p.parseDecorators()
+		if err != nil {
+			return nil, err
+		}
+		key := p.next()
+		if key.kind == tokenIdentifier && key.value == "resource" && len(p.parents) > 0 && !p.isPunct(":") {
+			if err := p.parseResource(key, decorators); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if (key.kind != tokenIdentifier && key.kind != tokenString) || len(key.interpolations) > 0 {
+			return nil, p.unexpectedToken(key)
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		item, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		value.properties = append(value.properties, &property{key: key.value, value: item, line: key.line})
+	}
+}
+
+// parseArray parses an array or a for-expression
+func (p *syntaxParser) parseArray(open token) (expression, error) {
+	p.skipNewlines()
+	if t := p.peek(); t.kind == tokenIdentifier && t.value == "for" {
+		return p.parseFor(open)
+	}
+	value := &array{position: position{line: open.line}, items: make([]expression, 0)}
+	for {
+		p.skipSeparators()
+		if p.isPunct("]") {
+			p.pos++
+			return value, nil
+		}
+		item, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		value.items = append(value.items, item)
+	}
+}
+
+func (p *syntaxParser) parseFor(open token) (expression, error) {
+	p.pos++
+	loop := &forLoop{position: position{line: open.line}}
+	if p.isPunct("(") {
+		p.pos++
+		item, i := p.next(), token{}
+		if p.isPunct(",") {
+			p.pos++
+			i = p.next()
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		loop.item, loop.index = item.value, i.value
+	} else {
+		loop.item = p.next().value
+	}
+	if in := p.next(); in.kind != tokenIdentifier || in.value != "in" {
+		return nil, p.unexpectedToken(in)
+	}
+	source, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	loop.source = source
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	p.skipNewlines()
+	if t := p.peek(); t.kind == tokenIdentifier && t.value == "if" {
+		loop.body, err = p.parseCondition()
+	} else {
+		loop.body, err = p.parseExpression()
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.skipNewlines()
+	return loop, p.expect("]")
+}
+
+// skipStatement skips the tokens of a statement not supported, up to the line break outside brackets ending it
+func (p *syntaxParser) skipStatement() {
+	for depth := 0; !p.eof(); p.pos++ {
+		t := p.peek()
+		if depth == 0 && t.kind == tokenNewline {
+			return
+		}
+		if t.kind == tokenPunct && (t.value == "{" || t.value == "[" || t.value == "(") {
+			depth++
+		} else if t.kind == tokenPunct && (t.value == "}" || t.value == "]" || t.value == ")") {
+			depth--
+		}
+	}
+}
+
+func (p *syntaxParser) skipNewlines() {
+	for !p.eof() && p.peek().kind == tokenNewline {
+		p.pos++
+	}
+}
+
+// skipSeparators skips the line breaks and commas separating the items of objects, arrays and arguments
+func (p *syntaxParser) skipSeparators() {
+	for !p.eof() && (p.peek().kind == tokenNewline || p.isPunct(",")) {
+		p.pos++
+	}
+}
+
+func (p *syntaxParser) eof() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *syntaxParser) peek() token {
+	if p.eof() {
+		return token{kind: tokenNewline}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *syntaxParser) next() token {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *syntaxParser) isPunct(value string) bool {
+	t := p.peek()
+	return !p.eof() && t.kind == tokenPunct && t.value == value
+}
+
+func (p *syntaxParser) expect(value string) error {
+	if !p.isPunct(value) {
+		return p.unexpected()
+	}
+	p.pos++
+	return nil
+}
+
+func (p *syntaxParser) unexpected() error {
+	return p.unexpectedToken(p.peek())
+}
+
+func (p *syntaxParser) unexpectedToken(t token) error {
+	if t.line == 0 {
+		return fmt.Errorf("unexpected end of file")
+	}
+	if t.kind == tokenNewline {
+		return fmt.Errorf("unexpected line break at line %d", t.line)
+	}
+	return fmt.Errorf("unexpected %s at line %d", t.value, t.line)
+}
@@ -0,0 +1,756 @@
+package bicep
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DeploymentType is the type of the ARM resources the modules are converted to
+	DeploymentType          = "Microsoft.Resources/deployments"
+	deploymentAPIVersion    = "2022-09-01"
+	templateContentVersion  = "1.0.0.0"
+	defaultTargetScope      = "resourceGroup"
+	escapedExpressionPrefix = "["
+)
+
+// schemas are the schemas of the ARM templates by target scope of the Bicep files
+var schemas = map[string]string{
+	defaultTargetScope: "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+	"subscription":     "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#",
+	"managementGroup":  "https://schema.management.azure.com/schemas/2019-08-01/managementGroupDeploymentTemplate.json#",
+	"tenant":           "https://schema.management.azure.com/schemas/2019-08-01/tenantDeploymentTemplate.json#",
+}
+
+// functions are the functions of the ARM template expressions of the binary operators of Bicep
+var functions = map[string]string{
+	"==": "equals",
+	"&&": "and",
+	"||": "or",
+	"<":  "less",
+	">":  "greater",
+	"<=": "lessOrEquals",
+	">=": "greaterOrEquals",
+	"+":  "add",
+	"-":  "sub",
+	"*":  "mul",
+	"/":  "div",
+	"%":  "mod",
+	"??": "coalesce",
+}
+
+// converter converts the declarations of a Bicep file to the ARM template the Bicep compiler builds, lines are the lines
+// of the Bicep file of the paths of the template (ex: resources.0.properties), err is the first error of the conversion
+type converter struct {
+	program    *program
+	parameters map[string]bool
+	variables  map[string]bool
+	symbols    map[string]*resource
+	lines      map[string]int
+	err        error
+}
+
+// scope binds the variables of the loops to the ARM expressions of their values
+type scope struct {
+	parent *scope
+	name   string
+	value  string
+}
+
+func (s *scope) with(name, value string) *scope {
+	if name == "" {
+		return s
+	}
+	return &scope{parent: s, name: name, value: value}
+}
+
+func (s *scope) lookup(name string) (string, bool) {
+	for ; s != nil; s = s.parent {
+		if s.name == name {
+			return s.value, true
+		}
+	}
+	return "", false
+}
+
+// resourceRef is a reference to a resource or module, index is the ARM expression of the index of the resources
+// declared in a loop, empty for the reference to the loop
+type resourceRef struct {
+	resource *resource
+	index    string
+}
+
+// convert returns the ARM template of the program and the lines of the paths of the template
+func convert(prog *program) (template map[string]interface{}, lines map[string]int, err error) {
+	c := &converter{
+		program:    prog,
+		parameters: make(map[string]bool),
+		variables:  make(map[string]bool),
+		symbols:    make(map[string]*resource),
+		lines:      make(map[string]int),
+	}
+	for _, d := range prog.parameters {
+		c.parameters[d.name] = true
+	}
+	for _, d := range prog.variables {
+		c.variables[d.name] = true
+	}
+	for _, r := range prog.resources {
+		c.symbols[r.symbol] = r
+	}
+
+	schema, ok := schemas[prog.targetScope]
+	if !ok {
+		return nil, nil, errors.Errorf("invalid target scope %s", prog.targetScope)
+	}
+	template = map[string]interface{}{
+		"$schema":        schema,
+		"contentVersion": templateContentVersion,
+		"resources":      c.convertResources(),
+	}
+	if metadata := c.convertMetadata(); len(metadata) > 0 {
+		template["metadata"] = metadata
+	}
+	if parameters := c.convertParameters(); len(parameters) > 0 {
+		template["parameters"] = parameters
+	}
+	if variables := c.convertVariables(); len(variables) > 0 {
+		template["variables"] = variables
+	}
+	if outputs := c.convertOutputs(); len(outputs) > 0 {
+		template["outputs"] = outputs
+	}
+	return template, c.lines, c.err
+}
+
+func (c *converter) convertMetadata() map[string]interface{} {
+	metadata := make(map[string]interface{})
+	for _, d := range c.program.metadata {
+		metadata[d.name] = c.render("metadata."+d.name, d.value, nil)
+	}
+	return metadata
+}
+
+// convertParameters converts the parameters with the constraints of their decorators, the parameters decorated
+// with @secure() are secure strings and objects
+func (c *converter) convertParameters() map[string]interface{} {
+	parameters := make(map[string]interface{})
+	for _, d := range c.program.parameters {
+		path := "parameters." + d.name
+		c.record(path, d.line)
+		c.record(path+".type", d.line)
+		parameter := map[string]interface{}{"type": getDeclarationType(d)}
+		if d.value != nil {
+			parameter["defaultValue"] = c.render(path+".defaultValue", d.value, nil)
+		}
+		metadata := make(map[string]interface{})
+		for _, dec := range d.decorators {
+			if len(dec.args) == 0 {
+				continue
+			}
+			switch dec.name {
+			case "allowed":
+				parameter["allowedValues"] = c.render(path+".allowedValues", dec.args[0], nil)
+			case "minLength", "maxLength", "minValue", "maxValue":
+				parameter[dec.name] = c.render(path+"."+dec.name, dec.args[0], nil)
+			case "description":
+				metadata["description"] = c.render(path+".metadata.description", dec.args[0], nil)
+			case "metadata":
+				if values, ok := c.render(path+".metadata", dec.args[0], nil).(map[string]interface{}); ok {
+					for key, value := range values {
+						metadata[key] = value
+					}
+				}
+			}
+		}
+		if len(metadata) > 0 {
+			parameter["metadata"] = metadata
+		}
+		parameters[d.name] = parameter
+	}
+	return parameters
+}
+
+// convertVariables converts the variables, the variables declared by for-expressions are copied
+func (c *converter) convertVariables() map[string]interface{} {
+	variables := make(map[string]interface{})
+	copies := make([]interface{}, 0)
+	for _, d := range c.program.variables {
+		if loop, ok := d.value.(*forLoop); ok {
+			copies = append(copies, c.convertCopy(fmt.Sprintf("variables.copy.%d", len(copies)), d.name, loop, nil))
+			continue
+		}
+		variables[d.name] = c.render("variables."+d.name, d.value, nil)
+	}
+	if len(copies) > 0 {
+		variables["copy"] = copies
+	}
+	return variables
+}
+
+func (c *converter) convertOutputs() map[string]interface{} {
+	outputs := make(map[string]interface{})
+	for _, d := range c.program.outputs {
+		path := "outputs." + d.name
+		c.record(path, d.line)
+		c.record(path+".type", d.line)
+		output := map[string]interface{}{"type": getDeclarationType(d)}
+		if loop, ok := d.value.(*forLoop); ok {
+			output["copy"] = c.convertCopy(path+".copy", "", loop, nil)
+		} else {
+			output["value"] = c.render(path+".value", d.value, nil)
+		}
+		outputs[d.name] = output
+	}
+	return outputs
+}
+
+// convertResources converts the resources and modules, the nested resources are declared after their parent,
+// the existing resources are only referenced so they are not in the template
+func (c *converter) convertResources() []interface{} {
+	resources := make([]interface{}, 0, len(c.program.resources))
+	for _, r := range c.program.resources {
+		if r.existing {
+			continue
+		}
+		resources = append(resources, c.convertResource(fmt.Sprintf("resources.%d", len(resources)), r))
+	}
+	return resources
+}
+
+// convertResource converts a resource or module, the modules are deployments whose parameters are the params of the
+// module, the template of the module is not added since the files of the modules are scanned on their own
+func (c *converter) convertResource(path string, r *resource) map[string]interface{} {
+	c.record(path, r.line)
+	body, cond, loop := getBody(r)
+	converted := make(map[string]interface{})
+	var sc *scope
+	if loop != nil {
+		loopCopy, source := c.newCopy(path+".copy", r.symbol, loop, nil)
+		for _, dec := range r.decorators {
+			if dec.name == "batchSize" && len(dec.args) > 0 {
+				loopCopy["mode"] = "serial"
+				loopCopy["batchSize"] = c.render(path+".copy.batchSize", dec.args[0], nil)
+			}
+		}
+		converted["copy"] = loopCopy
+		sc = bindLoop(loop, source, "copyIndex()", nil)
+	}
+	if cond != nil {
+		converted["condition"] = c.render(path+".condition", cond, sc)
+	}
+	converted["type"], converted["apiVersion"] = c.getResourceType(r), c.getAPIVersion(r)
+	c.record(path+".type", r.line)
+	c.record(path+".apiVersion", r.line)
+	converted["name"] = c.convertName(path+".name", r, sc)
+
+	dependsOn := make([]interface{}, 0)
+	if parent := c.getParent(r); parent != nil && !parent.existing {
+		dependsOn = append(dependsOn, c.dependency(resourceRef{resource: parent}))
+	}
+	properties := make(map[string]interface{})
+	for _, prop := range body.properties {
+		switch {
+		case prop.key == "name" || prop.key == "parent":
+		case prop.key == "dependsOn":
+			dependsOn = append(dependsOn, c.convertDependencies(path+".dependsOn", len(dependsOn), prop, sc)...)
+		case prop.key == "scope":
+			if !r.module {
+				converted["scope"] = c.render(path+".scope", prop.value, sc)
+			}
+		case r.module && prop.key == "params":
+			properties["parameters"] = c.convertModuleParameters(path+".properties.parameters", prop.value, sc)
+		case !r.module:
+			c.setProperty(converted, path, prop, sc)
+		}
+	}
+	if len(dependsOn) > 0 {
+		converted["dependsOn"] = dependsOn
+	}
+	if r.module {
+		properties["expressionEvaluationOptions"] = map[string]interface{}{"scope": "inner"}
+		properties["mode"] = "Incremental"
+		converted["properties"] = properties
+	}
+	return converted
+}
+
+// convertName returns the name of the resource in the template, prefixed with the names of its parents
+func (c *converter) convertName(path string, r *resource, sc *scope) interface{} {
+	body, _, _ := getBody(r)
+	name := getProperty(body, "name")
+	if name == nil {
+		return ""
+	}
+	parent := c.getParent(r)
+	if parent == nil {
+		return c.render(path, name.value, sc)
+	}
+	c.record(path, name.line)
+	if literals, ok := c.literalNames(parent); ok {
+		if value, ok := name.value.(*literal); ok {
+			if s, ok := value.value.(string); ok {
+				return escape(strings.Join(append(literals, s), "/"))
+			}
+		}
+	}
+	segments := append(c.nameSegments(resourceRef{resource: parent}), c.expr(name.value, sc))
+	placeholders := make([]string, 0, len(segments))
+	for i := range segments {
+		placeholders = append(placeholders, fmt.Sprintf("{%d}", i))
+	}
+	return fmt.Sprintf("[format('%s', %s)]", strings.Join(placeholders, "/"), strings.Join(segments, ", "))
+}
+
+// literalNames returns the names of a resource and its parents when they are all literal strings
+func (c *converter) literalNames(r *resource) ([]string, bool) {
+	names := make([]string, 0)
+	if parent := c.getParent(r); parent != nil && parent != r {
+		parentNames, ok := c.literalNames(parent)
+		if !ok {
+			return nil, false
+		}
+		names = parentNames
+	}
+	body, _, loop := getBody(r)
+	name := getProperty(body, "name")
+	if name == nil || loop != nil {
+		return nil, false
+	}
+	value, ok := name.value.(*literal)
+	if !ok {
+		return nil, false
+	}
+	s, ok := value.value.(string)
+	return append(names, s), ok
+}
+
+// newCopy returns the copy of the items of a for-expression, named name, along with the expression of its source
+func (c *converter) newCopy(path, name string, loop *forLoop, sc *scope) (loopCopy map[string]interface{}, source string) {
+	source = c.expr(loop.source, sc)
+	c.record(path, loop.line)
+	c.record(path+".count", loop.line)
+	return map[string]interface{}{
+		"name":  name,
+		"count": fmt.Sprintf("[length(%s)]", source),
+	}, source
+}
+
+// convertCopy converts a for-expression to the copy of the variables, outputs or properties named name, whose items
+// are the input of the copy, the outputs are copied without name
+func (c *converter) convertCopy(path, name string, loop *forLoop, sc *scope) map[string]interface{} {
+	loopCopy, source := c.newCopy(path, name, loop, sc)
+	copyIndex := "copyIndex()"
+	if name != "" {
+		copyIndex = fmt.Sprintf("copyIndex('%s')", name)
+	} else {
+		delete(loopCopy, "name")
+	}
+	body := loop.body
+	if cond, ok := body.(*condition); ok {
+		c.fail(cond.line, "conditions of for-expressions are only supported in resources and modules")
+		body = cond.body
+	}
+	loopCopy["input"] = c.render(path+".input", body, bindLoop(loop, source, copyIndex, sc))
+	return loopCopy
+}
+
+// convertDependencies converts the dependencies of a resource, after offset dependencies, the resources and modules
+// are referenced by resource ID
+func (c *converter) convertDependencies(path string, offset int, prop *property, sc *scope) []interface{} {
+	dependencies := make([]interface{}, 0)
+	items, ok := prop.value.(*array)
+	if !ok {
+		return dependencies
+	}
+	for _, item := range items.items {
+		itemPath := fmt.Sprintf("%s.%d", path, offset+len(dependencies))
+		ref, ok := c.getResourceRef(item, sc)
+		switch {
+		case !ok:
+			dependencies = append(dependencies, c.render(itemPath, item, sc))
+		case !ref.resource.existing:
+			c.record(itemPath, item.getLine())
+			dependencies = append(dependencies, c.dependency(ref))
+		}
+	}
+	return dependencies
+}
+
+// convertModuleParameters converts the params of a module to the parameters of a deployment
+func (c *converter) convertModuleParameters(path string, value expression, sc *scope) map[string]interface{} {
+	parameters := make(map[string]interface{})
+	params, ok := value.(*object)
+	if !ok {
+		return parameters
+	}
+	for _, prop := range params.properties {
+		c.record(path+"."+prop.key, prop.line)
+		parameters[prop.key] = map[string]interface{}{"value": c.render(path+"."+prop.key+".value", prop.value, sc)}
+	}
+	return parameters
+}
+
+// dependency returns the dependency on a resource or module, on the loop of the resources declared in a loop
+func (c *converter) dependency(ref resourceRef) string {
+	if _, _, loop := getBody(ref.resource); loop != nil && ref.index == "" {
+		return ref.resource.symbol
+	}
+	return "[" + c.resourceID(ref) + "]"
+}
+
+// render returns the value of the expression in the template: literals as they are, objects and arrays with the values
+// of their items and other expressions as ARM template expressions, the lines of the path and of the items are recorded
+func (c *converter) render(path string, e expression, sc *scope) interface{} {
+	c.record(path, e.getLine())
+	switch v := e.(type) {
+	case *literal:
+		if s, ok := v.value.(string); ok {
+			return escape(s)
+		}
+		return v.value
+	case *object:
+		rendered := make(map[string]interface{})
+		for _, prop := range v.properties {
+			c.setProperty(rendered, path, prop, sc)
+		}
+		return rendered
+	case *array:
+		rendered := make([]interface{}, 0, len(v.items))
+		for i, item := range v.items {
+			rendered = append(rendered, c.render(fmt.Sprintf("%s.%d", path, i), item, sc))
+		}
+		return rendered
+	default:
+		return "[" + c.expr(e, sc) + "]"
+	}
+}
+
+// setProperty sets the property in the object, the properties declared by for-expressions are copied
+func (c *converter) setProperty(target map[string]interface{}, path string, prop *property, sc *scope) {
+	loop, ok := prop.value.(*forLoop)
+	if !ok {
+		c.record(path+"."+prop.key, prop.line)
+		target[prop.key] = c.render(path+"."+prop.key, prop.value, sc)
+		return
+	}
+	copies, _ := target["copy"].([]interface{})
+	target["copy"] = append(copies, c.convertCopy(fmt.Sprintf("%s.copy.%d", path, len(copies)), prop.key, loop, sc))
+}
+
+// expr returns the ARM template expression of the expression, without brackets
+func (c *converter) expr(e expression, sc *scope) string {
+	switch v := e.(type) {
+	case *literal:
+		return exprLiteral(v.value)
+	case *interpolation:
+		format := make([]string, 0, len(v.parts))
+		args := make([]string, 0, len(v.expressions)+1)
+		for i, part := range v.parts {
+			part = strings.ReplaceAll(strings.ReplaceAll(part, "{", "{{"), "}", "}}")
+			if i < len(v.expressions) {
+				part += fmt.Sprintf("{%d}", i)
+				args = append(args, c.expr(v.expressions[i], sc))
+			}
+			format = append(format, part)
+		}
+		return fmt.Sprintf("format(%s)", strings.Join(append([]string{exprLiteral(strings.Join(format, ""))}, args...), ", "))
+	case *object:
+		args := make([]string, 0, 2*len(v.properties))
+		for _, prop := range v.properties {
+			args = append(args, exprLiteral(prop.key), c.expr(prop.value, sc))
+		}
+		return fmt.Sprintf("createObject(%s)", strings.Join(args, ", "))
+	case *array:
+		return fmt.Sprintf("createArray(%s)", c.exprList(v.items, sc))
+	case *reference:
+		return c.exprReference(v, sc)
+	case *access:
+		return c.exprAccess(v, sc)
+	case *index:
+		return fmt.Sprintf("%s[%s]", c.expr(v.target, sc), c.expr(v.index, sc))
+	case *call:
+		return c.exprCall(v, sc)
+	case *unary:
+		if v.operator == "!" {
+			return fmt.Sprintf("not(%s)", c.expr(v.operand, sc))
+		}
+		if number, ok := v.operand.(*literal); ok {
+			if n, ok := number.value.(int64); ok {
+				return strconv.FormatInt(-n, 10)
+			}
+		}
+		return fmt.Sprintf("sub(0, %s)", c.expr(v.operand, sc))
+	case *binary:
+		return c.exprBinary(v, sc)
+	case *ternary:
+		return fmt.Sprintf("if(%s, %s, %s)", c.expr(v.condition, sc), c.expr(v.whenTrue, sc), c.expr(v.whenFalse, sc))
+	case *forLoop:
+		c.fail(v.line, "for-expressions are only supported in resources, modules, variables, outputs and properties")
+	case *condition:
+		c.fail(v.line, "conditions are only supported in resources and modules")
+	}
+	return "null()"
+}
+
+func (c *converter) exprList(items []expression, sc *scope) string {
+	exprs := make([]string, 0, len(items))
+	for _, item := range items {
+		exprs = append(exprs, c.expr(item, sc))
+	}
+	return strings.Join(exprs, ", ")
+}
+
+// exprReference returns the expression of a reference, the loop variables are looked up first, the references to
+// resources and modules are their resource ID
+func (c *converter) exprReference(v *reference, sc *scope) string {
+	if value, ok := sc.lookup(v.name); ok {
+		return value
+	}
+	switch {
+	case c.parameters[v.name]:
+		return fmt.Sprintf("parameters('%s')", v.name)
+	case c.variables[v.name]:
+		return fmt.Sprintf("variables('%s')", v.name)
+	}
+	if r, ok := c.symbols[v.name]; ok {
+		return c.resourceID(resourceRef{resource: r})
+	}
+	return v.name
+}
+
+// exprAccess returns the expression of an access to a property, the properties of the resources are read from their
+// declaration when known before the deployment (ex: id and name), from the state of the resource otherwise
+func (c *converter) exprAccess(v *access, sc *scope) string {
+	if ref, ok := c.getResourceRef(v.target, sc); ok {
+		return c.resourceProperty(ref, v.member)
+	}
+	if outputs, ok := v.target.(*access); ok && outputs.member == "outputs" {
+		if ref, ok := c.getResourceRef(outputs.target, sc); ok && ref.resource.module {
+			return fmt.Sprintf("reference(%s).outputs.%s.value", c.resourceID(ref), v.member)
+		}
+	}
+	return fmt.Sprintf("%s.%s", c.expr(v.target, sc), v.member)
+}
+
+// exprCall returns the expression of a function call, the functions of the az and sys namespaces are the functions
+// of ARM templates and the methods of the resources (ex: listKeys) take the resource ID and the API version
+func (c *converter) exprCall(v *call, sc *scope) string {
+	args := c.exprList(v.args, sc)
+	if v.target != nil {
+		if ref, ok := c.getResourceRef(v.target, sc); ok {
+			resourceArgs := []string{c.resourceID(ref), exprLiteral(c.getAPIVersion(ref.resource))}
+			if args != "" {
+				resourceArgs = append(resourceArgs, args)
+			}
+			return fmt.Sprintf("%s(%s)", v.name, strings.Join(resourceArgs, ", "))
+		}
+		if namespace, ok := v.target.(*reference); !ok || (namespace.name != "az" && namespace.name != "sys") {
+			return fmt.Sprintf("%s.%s(%s)", c.expr(v.target, sc), v.name, args)
+		}
+	}
+	if v.name == "any" && len(v.args) == 1 {
+		return args
+	}
+	return fmt.Sprintf("%s(%s)", v.name, args)
+}
+
+func (c *converter) exprBinary(v *binary, sc *scope) string {
+	left, right := c.expr(v.left, sc), c.expr(v.right, sc)
+	switch v.operator {
+	case "!=":
+		return fmt.Sprintf("not(equals(%s, %s))", left, right)
+	case "=~":
+		return fmt.Sprintf("equals(toLower(%s), toLower(%s))", left, right)
+	case "!~":
+		return fmt.Sprintf("not(equals(toLower(%s), toLower(%s)))", left, right)
+	}
+	return fmt.Sprintf("%s(%s, %s)", functions[v.operator], left, right)
+}
+
+// resourceProperty returns the expression of a property of a resource or module
+func (c *converter) resourceProperty(ref resourceRef, member string) string {
+	switch member {
+	case "id":
+		return c.resourceID(ref)
+	case "name":
+		segments := c.nameSegments(ref)
+		return segments[len(segments)-1]
+	case "type":
+		return exprLiteral(c.getResourceType(ref.resource))
+	case "apiVersion":
+		return exprLiteral(c.getAPIVersion(ref.resource))
+	case "properties":
+		return fmt.Sprintf("reference(%s, '%s')", c.resourceID(ref), c.getAPIVersion(ref.resource))
+	case "outputs":
+		return fmt.Sprintf("reference(%s).outputs", c.resourceID(ref))
+	}
+	return fmt.Sprintf("reference(%s, '%s', 'full').%s", c.resourceID(ref), c.getAPIVersion(ref.resource), member)
+}
+
+// resourceID returns the expression of the resource ID of a resource or module
+func (c *converter) resourceID(ref resourceRef) string {
+	args := append([]string{exprLiteral(c.getResourceType(ref.resource))}, c.nameSegments(ref)...)
+	return fmt.Sprintf("resourceId(%s)", strings.Join(args, ", "))
+}
+
+// nameSegments returns the expressions of the names of the parents of a resource, followed by its own name,
+// the names of the resources declared in a loop are the names of the resource of the index of the reference
+func (c *converter) nameSegments(ref resourceRef) []string {
+	segments := make([]string, 0)
+	if parent := c.getParent(ref.resource); parent != nil && parent != ref.resource {
+		segments = append(segments, c.nameSegments(resourceRef{resource: parent})...)
+	}
+	body, _, loop := getBody(ref.resource)
+	name := getProperty(body, "name")
+	if name == nil {
+		return append(segments, "''")
+	}
+	var sc *scope
+	if loop != nil {
+		index := ref.index
+		if index == "" {
+			index = "copyIndex()"
+		}
+		sc = bindLoop(loop, c.expr(loop.source, nil), index, nil)
+	}
+	return append(segments, c.expr(name.value, sc))
+}
+
+// getResourceRef returns the resource or module referenced by the expression, the loop variables, parameters and variables
+// hide the resources with the same name
+func (c *converter) getResourceRef(e expression, sc *scope) (resourceRef, bool) {
+	indexExpr := ""
+	if v, ok := e.(*index); ok {
+		indexExpr = c.expr(v.index, sc)
+		e = v.target
+	}
+	ref, ok := e.(*reference)
+	if !ok {
+		return resourceRef{}, false
+	}
+	if _, ok := sc.lookup(ref.name); ok || c.parameters[ref.name] || c.variables[ref.name] {
+		return resourceRef{}, false
+	}
+	r, ok := c.symbols[ref.name]
+	return resourceRef{resource: r, index: indexExpr}, ok
+}
+
+// getParent returns the parent of a resource, the resource declaring it or the resource of its parent property
+func (c *converter) getParent(r *resource) *resource {
+	if r.parent != nil {
+		return r.parent
+	}
+	body, _, _ := getBody(r)
+	if parent := getProperty(body, "parent"); parent != nil {
+		if ref, ok := parent.value.(*reference); ok {
+			return c.symbols[ref.name]
+		}
+	}
+	return nil
+}
+
+// getResourceType returns the type of a resource, the types of the nested resources are relative to the type of their parent
+func (c *converter) getResourceType(r *resource) string {
+	if r.module {
+		return DeploymentType
+	}
+	if r.parent != nil && !strings.Contains(r.resourceType, "/") {
+		return c.getResourceType(r.parent) + "/" + r.resourceType
+	}
+	return r.resourceType
+}
+
+// getAPIVersion returns the API version of a resource, the nested resources without API version use the one of their parent
+func (c *converter) getAPIVersion(r *resource) string {
+	if r.module {
+		return deploymentAPIVersion
+	}
+	if r.apiVersion == "" && r.parent != nil {
+		return c.getAPIVersion(r.parent)
+	}
+	return r.apiVersion
+}
+
+// record records the line of the path of the template, the paths of the values generated by the conversion are not recorded
+func (c *converter) record(path string, line int) {
+	if line > 0 {
+		c.lines[path] = line
+	}
+}
+
+func (c *converter) fail(line int, message string) {
+	if c.err == nil {
+		c.err = errors.Errorf("%s at line %d", message, line)
+	}
+}
+
+// bindLoop binds, inside the scope, the item of the loop to the item of the source at the index and its index variable
+// to the index
+func bindLoop(loop *forLoop, source, index string, sc *scope) *scope {
+	return sc.with(loop.item, fmt.Sprintf("%s[%s]", source, index)).with(loop.index, index)
+}
+
+// getBody returns the body of a resource or module, with its condition and its loop, if any
+func getBody(r *resource) (body *object, cond expression, loop *forLoop) {
+	value := r.value
+	if v, ok := value.(*forLoop); ok {
+		loop, value = v, v.body
+	}
+	if v, ok := value.(*condition); ok {
+		cond, value = v.condition, v.body
+	}
+	if body, ok := value.(*object); ok {
+		return body, cond, loop
+	}
+	return &object{}, cond, loop
+}
+
+func getProperty(body *object, key string) *property {
+	for _, prop := range body.properties {
+		if prop.key == key {
+			return prop
+		}
+	}
+	return nil
+}
+
+// getDeclarationType returns the type of a parameter or output in the template
+func getDeclarationType(d *declaration) string {
+	typeName := strings.TrimSuffix(d.typeName, "?")
+	switch typeName {
+	case "string", "int", "bool", "object", "array":
+	default:
+		typeName = "object"
+	}
+	for _, dec := range d.decorators {
+		if dec.name == "secure" && (typeName == "string" || typeName == "object") {
+			return "secure" + typeName
+		}
+	}
+	return typeName
+}
+
+// exprLiteral returns the expression of a literal value
+func exprLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v) + "()"
+	}
+	return "null()"
+}
+
+// escape escapes the literal strings starting with a bracket, which would be evaluated as expressions
+func escape(value string) string {
+	if strings.HasPrefix(value, escapedExpressionPrefix) {
+		return escapedExpressionPrefix + value
+	}
+	return value
+}
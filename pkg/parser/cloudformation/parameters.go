@@ -0,0 +1,153 @@
+// Package cloudformation resolves CloudFormation template parameters before the templates are scanned
+package cloudformation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/pkg/errors"
+)
+
+// MaskedValue replaces the value of NoEcho parameters when they are masked
+const MaskedValue = "****"
+
+// Parameters holds the values used to resolve the references to CloudFormation template parameters
+// parameters without a value fall back to their Default, NoEcho parameters are masked when MaskNoEcho is set
+// only the long form {"Ref": "name"} is resolved, since the YAML short form !Ref is decoded as a plain string
+type Parameters struct {
+	Values     map[string]string
+	MaskNoEcho bool
+}
+
+// cliParameter is the format used by the AWS CLI parameters file
+type cliParameter struct {
+	ParameterKey   string `json:"ParameterKey"`
+	ParameterValue string `json:"ParameterValue"`
+}
+
+// LoadParameters reads a parameters file, either in the AWS CLI format ([{"ParameterKey": "k", "ParameterValue": "v"}])
+// or in the template configuration format ({"Parameters": {"k": "v"}})
+func LoadParameters(path string) (map[string]string, error) {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read CloudFormation parameters file")
+	}
+
+	values := make(map[string]string)
+	var cliParameters []cliParameter
+	if err := json.Unmarshal(content, &cliParameters); err == nil {
+		for _, parameter := range cliParameters {
+			values[parameter.ParameterKey] = parameter.ParameterValue
+		}
+		return values, nil
+	}
+
+	var configuration struct {
+		Parameters map[string]interface{} `json:"Parameters"`
+	}
+	if err := json.Unmarshal(content, &configuration); err != nil {
+		return nil, errors.Wrap(err, "failed to parse CloudFormation parameters file")
+	}
+	for key, value := range configuration.Parameters {
+		values[key] = fmt.Sprintf("%v", value)
+	}
+	return values, nil
+}
+
+// Resolve replaces the references to parameters of a CloudFormation template by their values
+// documents that are not CloudFormation templates are returned unchanged
+func (p *Parameters) Resolve(document model.Document) model.Document {
+	if p == nil || !IsTemplate(document) {
+		return document
+	}
+	declared, ok := document["Parameters"].(map[string]interface{})
+	if !ok {
+		return document
+	}
+
+	values := make(map[string]interface{}, len(declared))
+	for name, d := range declared {
+		declaration, ok := d.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		value, ok := p.Values[name]
+		if !ok {
+			defaultValue, hasDefault := declaration["Default"]
+			if !hasDefault {
+				continue
+			}
+			value = fmt.Sprintf("%v", defaultValue)
+		}
+		if p.MaskNoEcho && isNoEcho(declaration) {
+			value = MaskedValue
+		}
+		values[name] = value
+	}
+
+	for key, value := range document {
+		if key == "Parameters" {
+			continue
+		}
+		document[key] = resolveRefs(value, values)
+	}
+	return document
+}
+
+// IsTemplate returns true if the document is a CloudFormation template
+func IsTemplate(document model.Document) bool {
+	if _, ok := document["AWSTemplateFormatVersion"]; ok {
+		return true
+	}
+	resources, ok := document["Resources"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for _, r := range resources {
+		if resource, ok := r.(map[string]interface{}); ok {
+			if resourceType, ok := resource["Type"].(string); ok && strings.HasPrefix(resourceType, "AWS::") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isNoEcho(declaration map[string]interface{}) bool {
+	switch noEcho := declaration["NoEcho"].(type) {
+	case bool:
+		return noEcho
+	case string:
+		return strings.EqualFold(noEcho, "true")
+	default:
+		return false
+	}
+}
+
+// resolveRefs replaces {"Ref": "name"} objects referencing known parameters by the parameter value
+func resolveRefs(value interface{}, values map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["Ref"].(string); ok && len(v) == 1 {
+			if resolved, ok := values[ref]; ok {
+				return resolved
+			}
+			return v
+		}
+		for key, elem := range v {
+			v[key] = resolveRefs(elem, values)
+		}
+		return v
+	case []interface{}:
+		for idx, elem := range v {
+			v[idx] = resolveRefs(elem, values)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,119 @@
+package cloudformation
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoadParameters tests the functions [LoadParameters()] and all the methods called by them
+func TestLoadParameters(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "aws_cli_format",
+			path: filepath.FromSlash("../../../test/fixtures/test_cfn_parameters/parameters.json"),
+			want: map[string]string{"BucketName": "kics-bucket", "DBPassword": "P@ssw0rd1234"},
+		},
+		{
+			name: "template_configuration_format",
+			path: filepath.FromSlash("../../../test/fixtures/test_cfn_parameters/configuration.json"),
+			want: map[string]string{"BucketName": "kics-bucket", "Versioning": "true"},
+		},
+		{
+			name:    "file_not_found",
+			path:    filepath.FromSlash("../../../test/fixtures/test_cfn_parameters/not_found.json"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadParameters(tt.path)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func getTemplate() model.Document {
+	return model.Document{
+		"AWSTemplateFormatVersion": "2010-09-09",
+		"Parameters": map[string]interface{}{
+			"BucketName": map[string]interface{}{"Type": "String", "Default": "default-bucket"},
+			"DBPassword": map[string]interface{}{"Type": "String", "NoEcho": true},
+			"Port":       map[string]interface{}{"Type": "Number", "Default": 3306},
+		},
+		"Resources": map[string]interface{}{
+			"Bucket": map[string]interface{}{
+				"Type": "AWS::S3::Bucket",
+				"Properties": map[string]interface{}{
+					"BucketName": map[string]interface{}{"Ref": "BucketName"},
+				},
+			},
+			"Database": map[string]interface{}{
+				"Type": "AWS::RDS::DBInstance",
+				"Properties": map[string]interface{}{
+					"MasterUserPassword": map[string]interface{}{"Ref": "DBPassword"},
+					"Port":               map[string]interface{}{"Ref": "Port"},
+					"VPCSecurityGroups":  []interface{}{map[string]interface{}{"Ref": "SecurityGroup"}},
+				},
+			},
+		},
+	}
+}
+
+func getProperties(doc model.Document, resource string) map[string]interface{} {
+	return doc["Resources"].(map[string]interface{})[resource].(map[string]interface{})["Properties"].(map[string]interface{})
+}
+
+// TestParameters_Resolve tests the functions [Resolve()] and all the methods called by them
+func TestParameters_Resolve(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		p := &Parameters{Values: map[string]string{}}
+		doc := p.Resolve(getTemplate())
+		require.Equal(t, "default-bucket", getProperties(doc, "Bucket")["BucketName"])
+		require.Equal(t, "3306", getProperties(doc, "Database")["Port"])
+		require.Equal(t, map[string]interface{}{"Ref": "DBPassword"}, getProperties(doc, "Database")["MasterUserPassword"])
+		require.Equal(t, []interface{}{map[string]interface{}{"Ref": "SecurityGroup"}}, getProperties(doc, "Database")["VPCSecurityGroups"])
+	})
+	t.Run("values", func(t *testing.T) {
+		p := &Parameters{Values: map[string]string{"BucketName": "kics-bucket", "DBPassword": "P@ssw0rd1234"}}
+		doc := p.Resolve(getTemplate())
+		require.Equal(t, "kics-bucket", getProperties(doc, "Bucket")["BucketName"])
+		require.Equal(t, "P@ssw0rd1234", getProperties(doc, "Database")["MasterUserPassword"])
+	})
+	t.Run("mask_noecho", func(t *testing.T) {
+		p := &Parameters{Values: map[string]string{"DBPassword": "P@ssw0rd1234"}, MaskNoEcho: true}
+		doc := p.Resolve(getTemplate())
+		require.Equal(t, MaskedValue, getProperties(doc, "Database")["MasterUserPassword"])
+	})
+	t.Run("nil_parameters", func(t *testing.T) {
+		var p *Parameters
+		doc := p.Resolve(getTemplate())
+		require.Equal(t, map[string]interface{}{"Ref": "BucketName"}, getProperties(doc, "Bucket")["BucketName"])
+	})
+	t.Run("not_a_template", func(t *testing.T) {
+		p := &Parameters{Values: map[string]string{"Name": "value"}}
+		doc := model.Document{"Parameters": map[string]interface{}{"Name": map[string]interface{}{}}, "key": map[string]interface{}{"Ref": "Name"}}
+		require.Equal(t, map[string]interface{}{"Ref": "Name"}, p.Resolve(doc)["key"])
+	})
+}
+
+// TestIsTemplate tests the functions [IsTemplate()]
+func TestIsTemplate(t *testing.T) {
+	require.True(t, IsTemplate(model.Document{"AWSTemplateFormatVersion": "2010-09-09"}))
+	require.True(t, IsTemplate(model.Document{"Resources": map[string]interface{}{
+		"Queue": map[string]interface{}{"Type": "AWS::SQS::Queue"},
+	}}))
+	require.False(t, IsTemplate(model.Document{"resources": []interface{}{}}))
+}
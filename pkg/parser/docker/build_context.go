@@ -0,0 +1,224 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const dockerIgnoreFileName = ".dockerignore"
+
+// sensitiveFileNames are the base names of files and directories that should not be sent to the build context
+var sensitiveFileNames = []string{
+	".env", ".env.*", "*.env",
+	".git",
+	".npmrc", ".pypirc", ".netrc", ".htpasswd",
+	"credentials", "credentials.json",
+	"id_rsa", "id_dsa", "id_ecdsa", "id_ed25519",
+	"*.pem", "*.key", "*.p12", "*.pfx", "*.jks", "*.keystore",
+	"*.tfstate", "*.tfstate.backup",
+}
+
+// BuildContext is the build context of a Dockerfile, the directory of the Dockerfile, DockerIgnore tells if
+// it has a .dockerignore file and SensitiveCopies are the COPY and ADD instructions that copy sensitive files
+type BuildContext struct {
+	DockerIgnore    bool
+	SensitiveCopies []SensitiveCopy
+}
+
+// SensitiveCopy is a COPY or ADD instruction of the stage Stage whose sources include the sensitive files Files,
+// relative to the build context
+type SensitiveCopy struct {
+	Stage    string
+	Original string
+	Files    []string
+}
+
+// ignorePattern is a pattern of a .dockerignore file, exclusion patterns (!pattern) include files again
+type ignorePattern struct {
+	regex     *regexp.Regexp
+	exclusion bool
+}
+
+// buildContext returns the build context of the Dockerfile, nil if the Dockerfile is not on disk
+func buildContext(dockerfilePath string, commands map[string][]Command, names []string) *BuildContext {
+	if info, err := os.Stat(dockerfilePath); err != nil || !info.Mode().IsRegular() {
+		return nil
+	}
+	contextDir := filepath.Dir(dockerfilePath)
+	patterns, dockerIgnore := readDockerIgnore(filepath.Join(contextDir, dockerIgnoreFileName))
+	sensitive := sensitiveFiles(contextDir, patterns)
+
+	bc := &BuildContext{DockerIgnore: dockerIgnore, SensitiveCopies: []SensitiveCopy{}}
+	if len(sensitive) == 0 {
+		return bc
+	}
+	for _, name := range names {
+		for _, cmd := range commands[name] {
+			if (cmd.Cmd != "copy" && cmd.Cmd != "add") || flagValue(cmd.Flags, "--from") != "" || len(cmd.Value) < 2 {
+				continue
+			}
+			files := copiedFiles(cmd.Value[:len(cmd.Value)-1], sensitive)
+			if len(files) > 0 {
+				bc.SensitiveCopies = append(bc.SensitiveCopies, SensitiveCopy{
+					Stage:    name,
+					Original: cmd.Original,
+					Files:    files,
+				})
+			}
+		}
+	}
+	return bc
+}
+
+// readDockerIgnore returns the patterns of the .dockerignore file and if it exists
+func readDockerIgnore(path string) ([]ignorePattern, bool) {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, false
+	}
+	patterns := make([]ignorePattern, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pattern := ignorePattern{}
+		if strings.HasPrefix(line, "!") {
+			pattern.exclusion = true
+			line = strings.TrimSpace(line[1:])
+		}
+		line = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(line)), "/")
+		if regex, err := patternRegex(line); err == nil {
+			pattern.regex = regex
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns, true
+}
+
+// patternRegex converts a .dockerignore pattern to a regex, ** matches any number of directories
+// and the other wildcards follow filepath.Match
+func patternRegex(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			sb.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				sb.WriteString(regexp.QuoteMeta(pattern[i:]))
+				i = len(pattern)
+				continue
+			}
+			sb.WriteString(strings.Replace(pattern[i:i+end+1], "[!", "[^", 1))
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+// isIgnored returns true if the path, relative to the build context, is excluded from the build context,
+// a path is excluded when it or one of its parent directories matches a pattern, the last matching pattern wins
+func isIgnored(path string, patterns []ignorePattern) bool {
+	ignored := false
+	for _, pattern := range patterns {
+		for parent := path; parent != "."; parent = filepath.ToSlash(filepath.Dir(parent)) {
+			if pattern.regex.MatchString(parent) {
+				ignored = !pattern.exclusion
+				break
+			}
+		}
+	}
+	return ignored
+}
+
+// sensitiveFiles returns the sensitive files and directories of the build context that are not ignored,
+// relative to the build context, sensitive directories are not walked and neither are ignored directories
+// unless exclusion patterns may include their files again
+func sensitiveFiles(contextDir string, patterns []ignorePattern) []string {
+	exclusions := false
+	for _, pattern := range patterns {
+		exclusions = exclusions || pattern.exclusion
+	}
+	files := make([]string, 0)
+	_ = filepath.Walk(contextDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == contextDir {
+			return nil
+		}
+		rel, err := filepath.Rel(contextDir, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		sensitive := isSensitive(info.Name())
+		ignored := isIgnored(rel, patterns)
+		if sensitive && !ignored {
+			files = append(files, rel)
+		}
+		if info.IsDir() && (sensitive || (ignored && !exclusions)) {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files
+}
+
+func isSensitive(name string) bool {
+	for _, pattern := range sensitiveFileNames {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// copiedFiles returns the sensitive files copied by the sources of a COPY or ADD instruction,
+// a source copies the files it matches and the contents of the directories it matches
+func copiedFiles(sources, sensitive []string) []string {
+	files := make([]string, 0)
+	for _, file := range sensitive {
+		for _, source := range sources {
+			if strings.Contains(source, "://") {
+				continue
+			}
+			source = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(source)), "/")
+			if copiesFile(source, file) {
+				files = append(files, file)
+				break
+			}
+		}
+	}
+	return files
+}
+
+func copiesFile(source, file string) bool {
+	if source == "." {
+		return true
+	}
+	for parent := file; parent != "."; parent = filepath.ToSlash(filepath.Dir(parent)) {
+		if matched, _ := filepath.Match(source, parent); matched {
+			return true
+		}
+	}
+	return false
+}
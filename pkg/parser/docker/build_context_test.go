@@ -0,0 +1,63 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuildContext tests the functions [buildContext()] and all the methods called by them
+func TestBuildContext(t *testing.T) {
+	contextDir := t.TempDir()
+	for _, file := range []string{"Dockerfile", ".env", "app.py", "certs/server.key", "certs/ca.pem", ".git/config", "tmp/id_rsa"} {
+		path := filepath.Join(contextDir, filepath.FromSlash(file))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		require.NoError(t, os.WriteFile(path, []byte{}, os.ModePerm))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(contextDir, dockerIgnoreFileName), []byte("# generated\n**/*.key\n/tmp\n"), os.ModePerm))
+
+	commands := map[string][]Command{
+		"golang:1.16 AS build": {
+			{Cmd: "copy", Original: "COPY . /src", Value: []string{".", "/src"}},
+		},
+		"alpine:3.13": {
+			{Cmd: "copy", Original: "COPY --from=build /bin/app /bin/app", Flags: []string{"--from=build"}, Value: []string{"/bin/app", "/bin/app"}},
+			{Cmd: "add", Original: "ADD certs /certs", Value: []string{"certs", "/certs"}},
+			{Cmd: "copy", Original: "COPY app.py /app/", Value: []string{"app.py", "/app/"}},
+		},
+	}
+	names := []string{"golang:1.16 AS build", "alpine:3.13"}
+
+	got := buildContext(filepath.Join(contextDir, "Dockerfile"), commands, names)
+	require.Equal(t, &BuildContext{
+		DockerIgnore: true,
+		SensitiveCopies: []SensitiveCopy{
+			{Stage: "golang:1.16 AS build", Original: "COPY . /src", Files: []string{".env", ".git", "certs/ca.pem"}},
+			{Stage: "alpine:3.13", Original: "ADD certs /certs", Files: []string{"certs/ca.pem"}},
+		},
+	}, got)
+
+	require.Nil(t, buildContext(filepath.Join(contextDir, "missing", "Dockerfile"), commands, names))
+}
+
+// TestIsIgnored tests the functions [isIgnored()] and all the methods called by them
+func TestIsIgnored(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, dockerIgnoreFileName)
+	require.NoError(t, os.WriteFile(path, []byte("secrets\n*.pem\n!public.pem\ndocs/**/*.env\n"), os.ModePerm))
+	patterns, ok := readDockerIgnore(path)
+	require.True(t, ok)
+
+	require.True(t, isIgnored("secrets/token", patterns))
+	require.True(t, isIgnored("private.pem", patterns))
+	require.False(t, isIgnored("public.pem", patterns))
+	require.False(t, isIgnored("certs/private.pem", patterns))
+	require.True(t, isIgnored("docs/a/b/prod.env", patterns))
+	require.True(t, isIgnored("docs/prod.env", patterns))
+	require.False(t, isIgnored("prod.env", patterns))
+
+	_, ok = readDockerIgnore(filepath.Join(dir, "missing"))
+	require.False(t, ok)
+}
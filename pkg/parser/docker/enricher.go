@@ -0,0 +1,84 @@
+package docker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/resolver/download"
+	"github.com/rs/zerolog/log"
+)
+
+const hoursPerDay = 24
+
+// ImageLookup is the interface that wraps the basic method ImageMetadata, returning the metadata
+// of an image from its registry
+type ImageLookup interface {
+	ImageMetadata(image string) (*download.ImageMetadata, error)
+}
+
+// BaseImageEnricher adds to the stages of Dockerfiles built from images, not from other stages, the metadata
+// of the base image in its registry (BaseImage), so queries can check its age, architecture and digest
+type BaseImageEnricher struct {
+	lookup ImageLookup
+	now    func() time.Time
+	cache  map[string]map[string]interface{}
+	mutex  sync.Mutex
+}
+
+// NewBaseImageEnricher creates a BaseImageEnricher looking up the base images with lookup
+func NewBaseImageEnricher(lookup ImageLookup) *BaseImageEnricher {
+	return &BaseImageEnricher{
+		lookup: lookup,
+		now:    time.Now,
+		cache:  make(map[string]map[string]interface{}),
+	}
+}
+
+// Enrich adds the metadata of the base images to the stages of the Dockerfile document,
+// images that can't be looked up are logged and left without metadata
+func (e *BaseImageEnricher) Enrich(ctx context.Context, kind model.FileKind, document model.Document) {
+	if kind != model.KindDOCKER {
+		return
+	}
+	stages, _ := document["stages"].([]interface{})
+	for _, s := range stages {
+		stage, ok := s.(map[string]interface{})
+		if !ok || ctx.Err() != nil {
+			continue
+		}
+		parents, _ := stage["Parents"].([]interface{})
+		image, _ := stage["Image"].(string)
+		if len(parents) > 0 || image == "" || image == "scratch" {
+			continue
+		}
+		if metadata := e.imageMetadata(image); metadata != nil {
+			stage["BaseImage"] = metadata
+		}
+	}
+}
+
+func (e *BaseImageEnricher) imageMetadata(image string) map[string]interface{} {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if metadata, ok := e.cache[image]; ok {
+		return metadata
+	}
+	var metadata map[string]interface{}
+	found, err := e.lookup.ImageMetadata(image)
+	if err != nil {
+		log.Warn().Msgf("Failed to get metadata of base image %s: %s", image, err)
+	} else {
+		metadata = map[string]interface{}{
+			"Digest":       found.Digest,
+			"Created":      found.Created.UTC().Format(time.RFC3339),
+			"AgeDays":      int(e.now().Sub(found.Created).Hours() / hoursPerDay),
+			"Architecture": found.Architecture,
+			"OS":           found.OS,
+		}
+	}
+	// failures are cached too, so each image is looked up once per scan
+	e.cache[image] = metadata
+	return metadata
+}
@@ -0,0 +1,62 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/resolver/download"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeImageLookup struct {
+	lookups int
+}
+
+func (f *fakeImageLookup) ImageMetadata(image string) (*download.ImageMetadata, error) {
+	f.lookups++
+	if image != "golang:1.16" {
+		return nil, errors.New("not found")
+	}
+	return &download.ImageMetadata{
+		Digest:       "sha256:abc",
+		Created:      time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		Architecture: "amd64",
+		OS:           "linux",
+	}, nil
+}
+
+// TestBaseImageEnricher_Enrich tests the functions [Enrich()] and all the methods called by them
+func TestBaseImageEnricher_Enrich(t *testing.T) {
+	p := &Parser{}
+	doc, err := p.Parse("Dockerfile", []byte(`FROM golang:1.16 AS build
+FROM build AS test
+FROM unknown:1.0
+FROM golang:1.16
+`))
+	require.NoError(t, err)
+
+	lookup := &fakeImageLookup{}
+	enricher := NewBaseImageEnricher(lookup)
+	enricher.now = func() time.Time { return time.Date(2021, 7, 20, 0, 0, 0, 0, time.UTC) }
+	enricher.Enrich(context.Background(), model.KindDOCKER, doc[0])
+
+	stages := doc[0]["stages"].([]interface{})
+	require.Equal(t, map[string]interface{}{
+		"Digest":       "sha256:abc",
+		"Created":      "2021-01-01T00:00:00Z",
+		"AgeDays":      200,
+		"Architecture": "amd64",
+		"OS":           "linux",
+	}, stages[0].(map[string]interface{})["BaseImage"])
+	require.NotContains(t, stages[1], "BaseImage")
+	require.NotContains(t, stages[2], "BaseImage")
+	require.Contains(t, stages[3], "BaseImage")
+	require.Equal(t, 2, lookup.lookups)
+
+	other := model.Document{"stages": []interface{}{map[string]interface{}{"Image": "golang:1.16"}}}
+	enricher.Enrich(context.Background(), model.KindYAML, other)
+	require.NotContains(t, other["stages"].([]interface{})[0], "BaseImage")
+}
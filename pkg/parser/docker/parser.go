@@ -16,7 +16,9 @@ type Parser struct {
 
 // Resource Separates the list of commands by file
 type Resource struct {
-	CommandList map[string][]Command `json:"command"`
+	CommandList  map[string][]Command `json:"command"`
+	Stages       []Stage              `json:"stages"`
+	BuildContext *BuildContext        `json:"buildContext,omitempty"`
 }
 
 // Command is the struct for each dockerfile command
@@ -32,7 +34,7 @@ type Command struct {
 }
 
 // Parse - parses dockerfile to Json
-func (p *Parser) Parse(_ string, fileContent []byte) ([]model.Document, error) {
+func (p *Parser) Parse(filePath string, fileContent []byte) ([]model.Document, error) {
 	var documents []model.Document
 	reader := bytes.NewReader(fileContent)
 
@@ -43,10 +45,14 @@ func (p *Parser) Parse(_ string, fileContent []byte) ([]model.Document, error) {
 
 	fromValue := "args"
 	from := make(map[string][]Command)
+	stageNames := make([]string, 0)
 
 	for _, child := range parsed.AST.Children {
 		if child.Value == "from" {
 			fromValue = strings.TrimPrefix(child.Original, "FROM ")
+			if _, ok := from[fromValue]; !ok {
+				stageNames = append(stageNames, fromValue)
+			}
 		}
 
 		cmd := Command{
@@ -73,6 +79,8 @@ func (p *Parser) Parse(_ string, fileContent []byte) ([]model.Document, error) {
 	doc := &model.Document{}
 	var resource Resource
 	resource.CommandList = from
+	resource.Stages = buildStages(from, stageNames)
+	resource.BuildContext = buildContext(filePath, from, stageNames)
 
 	j, err := json.Marshal(resource)
 	if err != nil {
@@ -99,3 +99,42 @@ func TestParser_Parse(t *testing.T) {
 		}
 	}
 }
+
+// TestParser_ParseStages tests the functions [Parse()] and all the methods called by them for multi-stage Dockerfiles
+func TestParser_ParseStages(t *testing.T) {
+	p := &Parser{}
+	sample := `FROM golang:1.16 AS build
+RUN go build -o /bin/app .
+
+FROM build AS test
+RUN go test ./...
+
+FROM alpine:3.13
+COPY --from=build /bin/app /bin/app
+COPY --from=1 /report /report
+COPY --from=nginx:latest /etc/nginx/nginx.conf /nginx.conf
+`
+	doc, err := p.Parse("Dockerfile", []byte(sample))
+	require.NoError(t, err)
+	require.Len(t, doc, 1)
+
+	stages := doc[0]["stages"].([]interface{})
+	require.Len(t, stages, 3)
+
+	build := stages[0].(map[string]interface{})
+	require.Equal(t, "golang:1.16 AS build", build["Name"])
+	require.Equal(t, "golang:1.16", build["Image"])
+	require.Equal(t, "build", build["Alias"])
+	require.Equal(t, false, build["Final"])
+	require.Empty(t, build["Parents"])
+
+	test := stages[1].(map[string]interface{})
+	require.Equal(t, []interface{}{"golang:1.16 AS build"}, test["Parents"])
+	require.Equal(t, false, test["Final"])
+
+	final := stages[2].(map[string]interface{})
+	require.Equal(t, "alpine:3.13", final["Name"])
+	require.Equal(t, "", final["Alias"])
+	require.Equal(t, true, final["Final"])
+	require.Equal(t, []interface{}{"golang:1.16 AS build", "build AS test", "nginx:latest"}, final["CopyFrom"])
+}
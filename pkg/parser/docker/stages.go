@@ -0,0 +1,89 @@
+package docker
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Stage is a build stage of a multi-stage Dockerfile, Name is the key of its commands in the command list
+// Parents are the names of the stages it builds from (FROM <stage>), closest first, and CopyFrom
+// are the sources of its COPY --from instructions, the stage name or the image when it is not a stage
+type Stage struct {
+	Name     string
+	Image    string
+	Alias    string
+	Index    int
+	Final    bool
+	Parents  []string
+	CopyFrom []string
+}
+
+// buildStages returns the stages of the Dockerfile from its FROM commands, in order,
+// the last stage is the final stage, the one that produces the image
+func buildStages(commands map[string][]Command, names []string) []Stage {
+	stages := make([]Stage, 0, len(names))
+	for idx, name := range names {
+		stage := Stage{Name: name, Index: idx, Parents: []string{}, CopyFrom: []string{}}
+		for _, cmd := range commands[name] {
+			switch cmd.Cmd {
+			case "from":
+				stage.Image, stage.Alias = fromImage(cmd)
+			case "copy":
+				if source := flagValue(cmd.Flags, "--from"); source != "" {
+					stage.CopyFrom = append(stage.CopyFrom, stageReference(stages, source))
+				}
+			}
+		}
+		if parent := findStage(stages, stage.Image); parent != nil {
+			stage.Parents = append([]string{parent.Name}, parent.Parents...)
+		}
+		stages = append(stages, stage)
+	}
+	if len(stages) > 0 {
+		stages[len(stages)-1].Final = true
+	}
+	return stages
+}
+
+// fromImage returns the image and the alias of a FROM command (FROM <image> [AS <alias>])
+func fromImage(cmd Command) (image, alias string) {
+	if len(cmd.Value) > 0 {
+		image = cmd.Value[0]
+	}
+	if len(cmd.Value) > 2 && strings.EqualFold(cmd.Value[1], "as") {
+		alias = cmd.Value[2]
+	}
+	return image, alias
+}
+
+func flagValue(flags []string, flag string) string {
+	for _, f := range flags {
+		if strings.HasPrefix(f, flag+"=") {
+			return strings.TrimPrefix(f, flag+"=")
+		}
+	}
+	return ""
+}
+
+// stageReference returns the name of the stage referenced by its alias or index, or the reference itself
+// when it is not a previous stage, such as an image
+func stageReference(stages []Stage, reference string) string {
+	if stage := findStage(stages, reference); stage != nil {
+		return stage.Name
+	}
+	return reference
+}
+
+func findStage(stages []Stage, reference string) *Stage {
+	if reference == "" {
+		return nil
+	}
+	index, err := strconv.Atoi(reference)
+	for i := range stages {
+		// aliases are case insensitive
+		if strings.EqualFold(stages[i].Alias, reference) || (err == nil && stages[i].Index == index) {
+			return &stages[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package gdm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/parser/jinja"
+	yamlParser "github.com/Checkmarx/kics/pkg/parser/yaml"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// Parser is a GCP Deployment Manager jinja template parser
+// Python templates are not supported since rendering them requires executing code
+type Parser struct {
+}
+
+// config is the representation of a Deployment Manager configuration importing templates
+type config struct {
+	Imports   []configImport   `yaml:"imports"`
+	Resources []configResource `yaml:"resources"`
+}
+
+type configImport struct {
+	Path string `yaml:"path"`
+	Name string `yaml:"name"`
+}
+
+type configResource struct {
+	Name       string                 `yaml:"name"`
+	Type       string                 `yaml:"type"`
+	Properties map[string]interface{} `yaml:"properties"`
+}
+
+// Parse renders a jinja template once for each resource using it in the configurations next to it
+// (in the same or in the parent directory), so queries are evaluated with the actual properties values
+// templates not used by any configuration are rendered without properties
+func (p *Parser) Parse(filePath string, fileContent []byte) ([]model.Document, error) {
+	renderVars := getTemplateVars(filePath)
+	if len(renderVars) == 0 {
+		renderVars = []map[string]string{nil}
+	}
+
+	documents := make([]model.Document, 0, len(renderVars))
+	for _, vars := range renderVars {
+		rendered := jinja.Render(string(fileContent), vars)
+		docs, err := (&yamlParser.Parser{}).Parse(filePath, []byte(rendered))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse Deployment Manager template")
+		}
+		documents = append(documents, docs...)
+	}
+
+	return documents, nil
+}
+
+// GetKind returns GDM constant kind
+func (p *Parser) GetKind() model.FileKind {
+	return model.KindGDM
+}
+
+// SupportedExtensions returns extensions supported by this parser, which is jinja extension
+func (p *Parser) SupportedExtensions() []string {
+	return []string{".jinja"}
+}
+
+// SupportedTypes returns types supported by this parser, which is googleDeploymentManager
+func (p *Parser) SupportedTypes() []string {
+	return []string{"GoogleDeploymentManager"}
+}
+
+// getTemplateVars returns the variables to render the template for each resource using it
+func getTemplateVars(templatePath string) []map[string]string {
+	templatePath = filepath.Clean(templatePath)
+	dir := filepath.Dir(templatePath)
+	renderVars := make([]map[string]string, 0)
+	for _, configDir := range []string{dir, filepath.Dir(dir)} {
+		for _, configPath := range findConfigs(configDir) {
+			cfg, err := readConfig(configPath)
+			if err != nil {
+				log.Debug().Msgf("gdm.getTemplateVars() skipping file %s: %s", configPath, err)
+				continue
+			}
+			types := make(map[string]bool)
+			for _, imp := range cfg.Imports {
+				if filepath.Join(configDir, filepath.FromSlash(imp.Path)) != templatePath {
+					continue
+				}
+				types[imp.Path] = true
+				if imp.Name != "" {
+					types[imp.Name] = true
+				}
+			}
+			for _, resource := range cfg.Resources {
+				if types[resource.Type] {
+					renderVars = append(renderVars, resourceVars(resource))
+				}
+			}
+		}
+	}
+	return renderVars
+}
+
+func findConfigs(dir string) []string {
+	configs := make([]string, 0)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return configs
+	}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			configs = append(configs, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return configs
+}
+
+func readConfig(configPath string) (config, error) {
+	content, err := os.ReadFile(filepath.Clean(configPath))
+	if err != nil {
+		return config{}, err
+	}
+	cfg := config{}
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return config{}, err
+	}
+	return cfg, nil
+}
+
+// resourceVars returns the env and properties variables available to the template of a resource
+func resourceVars(resource configResource) map[string]string {
+	vars := map[string]string{
+		"env.name": resource.Name,
+	}
+	addVars(vars, "properties", resource.Properties)
+	return vars
+}
+
+// addVars adds the value by its path, objects and lists are also added in their flow form
+// which is valid YAML, and the fields of objects are added by their own path
+func addVars(vars map[string]string, path string, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			addVars(vars, path+"."+key, field)
+		}
+		if flow, err := json.Marshal(v); err == nil {
+			vars[path] = string(flow)
+		}
+	case []interface{}:
+		if flow, err := json.Marshal(v); err == nil {
+			vars[path] = string(flow)
+		}
+	case nil:
+		vars[path] = "null"
+	default:
+		vars[path] = fmt.Sprintf("%v", v)
+	}
+}
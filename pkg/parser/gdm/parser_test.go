@@ -0,0 +1,70 @@
+package gdm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParser_GetKind tests the functions [GetKind()] and all the methods called by them
+func TestParser_GetKind(t *testing.T) {
+	p := &Parser{}
+	require.Equal(t, model.KindGDM, p.GetKind())
+}
+
+// TestParser_SupportedExtensions tests the functions [SupportedExtensions()] and all the methods called by them
+func TestParser_SupportedExtensions(t *testing.T) {
+	p := &Parser{}
+	require.Equal(t, []string{".jinja"}, p.SupportedExtensions())
+}
+
+// TestParser_SupportedTypes tests the functions [SupportedTypes()] and all the methods called by them
+func TestParser_SupportedTypes(t *testing.T) {
+	p := &Parser{}
+	require.Equal(t, []string{"GoogleDeploymentManager"}, p.SupportedTypes())
+}
+
+// TestParser_Parse tests the functions [Parse()] and all the methods called by them
+func TestParser_Parse(t *testing.T) {
+	p := &Parser{}
+	templatePath := filepath.FromSlash("../../../test/fixtures/test_gdm/templates/vm.jinja")
+	content, err := os.ReadFile(templatePath)
+	require.NoError(t, err)
+
+	docs, err := p.Parse(templatePath, content)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+
+	resources := docs[0]["resources"].([]interface{})
+	instance := resources[0].(map[string]interface{})
+	require.Equal(t, "web-vm", instance["name"])
+
+	properties := instance["properties"].(map[string]interface{})
+	require.Equal(t, "us-central1-a", properties["zone"])
+	require.Equal(t, "zones/us-central1-a/machineTypes/n1-standard-1", properties["machineType"])
+	require.Equal(t, []interface{}{"http-server"}, properties["tags"].(map[string]interface{})["items"])
+
+	networkInterface := properties["networkInterfaces"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "global/networks/default", networkInterface["network"])
+
+	serviceAccount := properties["serviceAccounts"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "${properties[\"serviceAccount\"]}", serviceAccount["email"])
+}
+
+// TestParser_Parse_Standalone tests the functions [Parse()] for templates not used by any configuration
+func TestParser_Parse_Standalone(t *testing.T) {
+	p := &Parser{}
+	sample := `resources:
+  - name: {{ env["name"] }}-bucket
+    type: storage.v1.bucket
+`
+	docs, err := p.Parse("bucket.jinja", []byte(sample))
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+
+	bucket := docs[0]["resources"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "${env[\"name\"]}-bucket", bucket["name"])
+}
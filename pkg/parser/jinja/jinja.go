@@ -0,0 +1,108 @@
+// Package jinja renders jinja templates used by configuration management and deployment tools
+// without evaluating any code, so the result can be parsed and scanned
+package jinja
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	jinjaComment   = regexp.MustCompile(`(?s){#.*?#}`)
+	jinjaStatement = regexp.MustCompile(`{%-?\s*(.*?)\s*-?%}`)
+	jinjaExpr      = regexp.MustCompile(`{{-?\s*(.*?)\s*-?}}`)
+	jinjaIndex     = regexp.MustCompile(`\[\s*(?:'([^']*)'|"([^"]*)")\s*\]`)
+	jinjaSet       = regexp.MustCompile(`^set\s+([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(?:'([^']*)'|"([^"]*)"|([0-9.]+|True|False|true|false))\s*$`)
+)
+
+// renderer renders jinja templates without evaluating any code:
+// known variables and literal 'set' assignments are substituted, any other expression is kept as '${expr}',
+// only the first branch of conditionals is kept and loops bodies are rendered once
+type renderer struct {
+	vars map[string]string
+	// skip holds for each open conditional if its current branch is being dropped
+	skip []bool
+}
+
+// Render renders the content keeping the same number of lines so line detection is not affected
+// vars holds the values of known variables by their path, where indexes are written with dots
+// (e.g. "properties.zone" is used for both {{ properties.zone }} and {{ properties['zone'] }})
+func Render(content string, vars map[string]string) string {
+	r := &renderer{
+		vars: make(map[string]string, len(vars)),
+		skip: make([]bool, 0),
+	}
+	for name, value := range vars {
+		r.vars[name] = value
+	}
+
+	content = jinjaComment.ReplaceAllStringFunc(content, func(comment string) string {
+		return strings.Repeat("\n", strings.Count(comment, "\n"))
+	})
+
+	lines := strings.Split(content, "\n")
+	for idx, line := range lines {
+		lines[idx] = r.renderLine(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *renderer) renderLine(line string) string {
+	statements := jinjaStatement.FindAllStringSubmatch(line, -1)
+	for _, statement := range statements {
+		r.evalStatement(statement[1])
+	}
+	line = jinjaStatement.ReplaceAllString(line, "")
+
+	if r.skipping() || (len(statements) > 0 && strings.TrimSpace(line) == "") {
+		return ""
+	}
+
+	return jinjaExpr.ReplaceAllStringFunc(line, func(expr string) string {
+		name := strings.TrimSpace(jinjaExpr.FindStringSubmatch(expr)[1])
+		if value, ok := r.vars[normalizePath(name)]; ok {
+			return value
+		}
+		return "${" + name + "}"
+	})
+}
+
+func (r *renderer) evalStatement(statement string) {
+	fields := strings.Fields(statement)
+	if len(fields) == 0 {
+		return
+	}
+	switch fields[0] {
+	case "if":
+		r.skip = append(r.skip, r.skipping())
+	case "elif", "else":
+		if len(r.skip) > 0 {
+			r.skip[len(r.skip)-1] = true
+		}
+	case "endif":
+		if len(r.skip) > 0 {
+			r.skip = r.skip[:len(r.skip)-1]
+		}
+	case "set":
+		if r.skipping() {
+			return
+		}
+		if parts := jinjaSet.FindStringSubmatch(statement); parts != nil {
+			r.vars[parts[1]] = parts[2] + parts[3] + parts[4]
+		}
+	}
+}
+
+func (r *renderer) skipping() bool {
+	for _, s := range r.skip {
+		if s {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizePath writes the string indexes of an expression with dots, ex: properties['zone'] => properties.zone
+func normalizePath(expr string) string {
+	return jinjaIndex.ReplaceAllString(expr, ".$1$2")
+}
@@ -0,0 +1,55 @@
+package jinja
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestRender tests the function [Render()] keeps the original number of lines
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		vars    map[string]string
+		want    string
+	}{
+		{
+			name:    "set_literal",
+			content: "{% set port = 22 %}\nport: {{ port }}",
+			want:    "\nport: 22",
+		},
+		{
+			name:    "unknown_expression",
+			content: "name: {{ salt['pillar.get']('name') }}",
+			want:    "name: ${salt['pillar.get']('name')}",
+		},
+		{
+			name:    "multiline_comment",
+			content: "{# a\nb #}\nkey: value",
+			want:    "\n\nkey: value",
+		},
+		{
+			name:    "conditional_first_branch",
+			content: "{% if a %}\nkey: one\n{% elif b %}\nkey: two\n{% endif %}",
+			want:    "\nkey: one\n\n\n",
+		},
+		{
+			name:    "known_variables",
+			content: "zone: {{ properties['zone'] }}\nname: {{ env[\"name\"] }}-{{ properties.size }}",
+			vars:    map[string]string{"properties.zone": "us-central1-a", "env.name": "vm", "properties.size": "small"},
+			want:    "zone: us-central1-a\nname: vm-small",
+		},
+		{
+			name:    "set_overrides_variable",
+			content: "{% set zone = 'europe-west1-b' %}\nzone: {{ zone }}",
+			vars:    map[string]string{"zone": "us-central1-a"},
+			want:    "\nzone: europe-west1-b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Render(tt.content, tt.vars))
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package json
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/resolver/arm"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	armParametersSuffix = ".parameters.json"
+	// armMaskedValue replaces the value of secure parameters so secrets are never evaluated or reported
+	armMaskedValue = "********"
+)
+
+var armParameterExpr = regexp.MustCompile(`^\[\s*parameters\(\s*'([^']+)'\s*\)\s*\]$`)
+
+// armParameter is the value of a parameter and if it should be masked
+type armParameter struct {
+	value  interface{}
+	secure bool
+}
+
+// applyARMParameters substitutes the parameters of an ARM template by the values defined in
+// the parameters file next to it (<template>.parameters.json), masking the secure ones
+func applyARMParameters(filePath string, document model.Document) model.Document {
+	if !arm.IsTemplate(document) || strings.HasSuffix(filePath, armParametersSuffix) {
+		return document
+	}
+	parametersPath := strings.TrimSuffix(filePath, filepath.Ext(filePath)) + armParametersSuffix
+	content, err := os.ReadFile(filepath.Clean(parametersPath))
+	if err != nil {
+		return document
+	}
+	parametersFile := make(map[string]interface{})
+	if err := json.Unmarshal(content, &parametersFile); err != nil {
+		log.Debug().Msgf("json.applyARMParameters() failed to parse parameters file %s: %s", parametersPath, err)
+		return document
+	}
+
+	parameters := getARMParameters(document, parametersFile)
+	if len(parameters) == 0 {
+		return document
+	}
+	for key, value := range document {
+		if key == "parameters" {
+			continue
+		}
+		document[key] = substituteARMParameters(value, parameters)
+	}
+	return document
+}
+
+// getARMParameters returns the parameters values indexed by their lowercase name, since ARM parameters are case insensitive
+func getARMParameters(document model.Document, parametersFile map[string]interface{}) map[string]armParameter {
+	parameters := make(map[string]armParameter)
+	values, ok := parametersFile["parameters"].(map[string]interface{})
+	if !ok {
+		return parameters
+	}
+	declared, _ := document["parameters"].(map[string]interface{})
+	for name, v := range values {
+		entry, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		parameter := armParameter{value: entry["value"]}
+		if _, isReference := entry["reference"]; isReference {
+			parameter.secure = true
+		} else if _, hasValue := entry["value"]; !hasValue {
+			continue
+		}
+		if declaration, ok := findARMParameter(declared, name); ok {
+			if parameterType, ok := declaration["type"].(string); ok && strings.HasPrefix(strings.ToLower(parameterType), "secure") {
+				parameter.secure = true
+			}
+		}
+		if parameter.secure {
+			parameter.value = armMaskedValue
+		}
+		parameters[strings.ToLower(name)] = parameter
+	}
+	return parameters
+}
+
+func findARMParameter(declared map[string]interface{}, name string) (map[string]interface{}, bool) {
+	for key, value := range declared {
+		if strings.EqualFold(key, name) {
+			declaration, ok := value.(map[string]interface{})
+			return declaration, ok
+		}
+	}
+	return nil, false
+}
+
+// substituteARMParameters replaces the values that are a single parameter expression, like "[parameters('name')]"
+func substituteARMParameters(value interface{}, parameters map[string]armParameter) interface{} {
+	switch v := value.(type) {
+	case string:
+		if match := armParameterExpr.FindStringSubmatch(v); match != nil {
+			if parameter, ok := parameters[strings.ToLower(match[1])]; ok {
+				return parameter.value
+			}
+		}
+		return v
+	case map[string]interface{}:
+		for key, elem := range v {
+			v[key] = substituteARMParameters(elem, parameters)
+		}
+		return v
+	case []interface{}:
+		for idx, elem := range v {
+			v[idx] = substituteARMParameters(elem, parameters)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,58 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+)
+
+// findDuplicateKeys returns the keys defined more than once in the same object of the JSON content,
+// json.Unmarshal keeps their last definition without any warning
+func findDuplicateKeys(content []byte) []model.DuplicateKey {
+	dec := json.NewDecoder(bytes.NewReader(content))
+	duplicates := make([]model.DuplicateKey, 0)
+	if err := walkJSONValue(dec, content, "", &duplicates); err != nil {
+		return nil
+	}
+	return duplicates
+}
+
+func walkJSONValue(dec *json.Decoder, content []byte, searchKey string, duplicates *[]model.DuplicateKey) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch token {
+	case json.Delim('{'):
+		keys := make(map[string]struct{})
+		for dec.More() {
+			keyToken, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := keyToken.(string)
+			childKey := strings.TrimPrefix(searchKey+"."+key, ".")
+			if _, ok := keys[key]; ok {
+				line := bytes.Count(content[:dec.InputOffset()], []byte("\n")) + 1
+				*duplicates = append(*duplicates, model.DuplicateKey{SearchKey: childKey, Line: line})
+			}
+			keys[key] = struct{}{}
+			if err := walkJSONValue(dec, content, childKey, duplicates); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+		return err
+	case json.Delim('['):
+		for dec.More() {
+			if err := walkJSONValue(dec, content, searchKey, duplicates); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+		return err
+	}
+	return nil
+}
@@ -4,15 +4,21 @@ import (
 	"encoding/json"
 
 	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/parser/cloudformation"
+	"github.com/Checkmarx/kics/pkg/parser/terraform/state"
 	"github.com/pkg/errors"
 )
 
 // Parser defines a parser type
+// CloudFormationParameters, when set, resolves the references to parameters in CloudFormation templates
 type Parser struct {
+	CloudFormationParameters *cloudformation.Parameters
 }
 
 // Parse parses json file and returns it as a Document
-func (p *Parser) Parse(_ string, fileContent []byte) ([]model.Document, error) {
+// ARM templates with a parameters file next to them get the parameters values substituted
+// and the JSON output of Terraform plans is converted to the values planned for their resources
+func (p *Parser) Parse(filePath string, fileContent []byte) ([]model.Document, error) {
 	r := model.Document{}
 	err := json.Unmarshal(fileContent, &r)
 	if err != nil {
@@ -20,6 +26,14 @@ func (p *Parser) Parse(_ string, fileContent []byte) ([]model.Document, error) {
 		err = json.Unmarshal(fileContent, &r)
 		return r, err
 	}
+	if state.IsPlan(r) {
+		return state.ParsePlan(fileContent)
+	}
+
+	r = p.CloudFormationParameters.Resolve(applyARMParameters(filePath, r))
+	if duplicates := findDuplicateKeys(fileContent); len(duplicates) > 0 {
+		r[model.DuplicateKeysKey] = duplicates
+	}
 
 	return []model.Document{r}, errors.Wrap(err, "failed to unmarshall json content")
 }
@@ -34,7 +48,8 @@ func (p *Parser) GetKind() model.FileKind {
 	return model.KindJSON
 }
 
-// SupportedTypes returns types supported by this parser, which are cloudFormation
+// SupportedTypes returns types supported by this parser, which are cloudFormation, azureResourceManager
+// and terraform, for the JSON output of Terraform plans
 func (p *Parser) SupportedTypes() []string {
-	return []string{"CloudFormation"}
+	return []string{"CloudFormation", "AzureResourceManager", "Terraform"}
 }
@@ -1,6 +1,8 @@
 package json
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/Checkmarx/kics/pkg/model"
@@ -22,7 +24,7 @@ func TestParser_SupportedExtensions(t *testing.T) {
 // TestParser_SupportedExtensions tests the functions [SupportedTypes()] and all the methods called by them
 func TestParser_SupportedTypes(t *testing.T) {
 	p := &Parser{}
-	require.Equal(t, []string{"CloudFormation"}, p.SupportedTypes())
+	require.Equal(t, []string{"CloudFormation", "AzureResourceManager", "Terraform"}, p.SupportedTypes())
 }
 
 // TestParser_Parse tests the functions [Parse()] and all the methods called by them
@@ -41,3 +43,75 @@ func TestParser_Parse(t *testing.T) {
 	require.Len(t, doc, 1)
 	require.Contains(t, doc[0], "martin")
 }
+
+// TestParser_Parse_ARMParameters tests the functions [Parse()] substituting the values of an ARM parameters file
+func TestParser_Parse_ARMParameters(t *testing.T) {
+	p := &Parser{}
+	filePath := filepath.FromSlash("../../../test/fixtures/test_arm_parameters/vm.json")
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+
+	docs, err := p.Parse(filePath, content)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+
+	resources := docs[0]["resources"].([]interface{})
+	storage := resources[0].(map[string]interface{})["properties"].(map[string]interface{})
+	require.Equal(t, false, storage["supportsHttpsTrafficOnly"])
+
+	osProfile := resources[1].(map[string]interface{})["properties"].(map[string]interface{})["osProfile"].(map[string]interface{})
+	require.Equal(t, "azureuser", osProfile["adminUsername"])
+	require.Equal(t, armMaskedValue, osProfile["adminPassword"])
+	require.Equal(t, "[concat('vm-', parameters('adminUsername'))]", osProfile["computerName"])
+
+	parameters := docs[0]["parameters"].(map[string]interface{})
+	require.Equal(t, true, parameters["httpsOnly"].(map[string]interface{})["defaultValue"])
+
+	docs, err = p.Parse("vm.json", content)
+	require.NoError(t, err)
+	resources = docs[0]["resources"].([]interface{})
+	storage = resources[0].(map[string]interface{})["properties"].(map[string]interface{})
+	require.Equal(t, "[parameters('httpsOnly')]", storage["supportsHttpsTrafficOnly"])
+}
+
+// TestParser_Parse_DuplicateKeys tests the functions [Parse()] reporting the keys defined more than once
+func TestParser_Parse_DuplicateKeys(t *testing.T) {
+	p := &Parser{}
+	have := `{
+	"Resources": {
+		"Bucket": {
+			"Properties": {
+				"AccessControl": "Private",
+				"AccessControl": "PublicRead"
+			}
+		}
+	},
+	"Tags": [{"Key": "a", "Key": "b"}]
+}
+`
+	doc, err := p.Parse("template.json", []byte(have))
+	require.NoError(t, err)
+	require.Len(t, doc, 1)
+	require.Equal(t, []model.DuplicateKey{
+		{SearchKey: "Resources.Bucket.Properties.AccessControl", Line: 6},
+		{SearchKey: "Tags.Key", Line: 10},
+	}, doc[0][model.DuplicateKeysKey])
+
+	doc, err = p.Parse("template.json", []byte(`{"a": 1}`))
+	require.NoError(t, err)
+	require.NotContains(t, doc[0], model.DuplicateKeysKey)
+}
+
+// TestParser_Parse_TerraformPlan tests the functions [Parse()] converting the JSON output of a Terraform plan
+func TestParser_Parse_TerraformPlan(t *testing.T) {
+	p := &Parser{}
+	content, err := os.ReadFile(filepath.FromSlash("../../../test/fixtures/test_terraform_plan/plan.json"))
+	require.NoError(t, err)
+
+	docs, err := p.Parse("plan.json", content)
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+	require.NotContains(t, docs[0], "planned_values")
+	bucket := docs[0]["resource"].(model.Document)["aws_s3_bucket"].(model.Document)["b"].(model.Document)
+	require.Equal(t, "public-read", bucket["acl"])
+}
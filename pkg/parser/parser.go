@@ -10,7 +10,9 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-type kindParser interface {
+// KindParser is the parser of the files of a kind, the parsers of new formats implement it to be added
+// to a Builder or registered in a Registry
+type KindParser interface {
 	GetKind() model.FileKind
 	SupportedExtensions() []string
 	SupportedTypes() []string
@@ -19,7 +21,7 @@ type kindParser interface {
 
 // Builder is a representation of parsers that will be construct
 type Builder struct {
-	parsers []kindParser
+	parsers []KindParser
 }
 
 // NewBuilder creates a new Builder's reference
@@ -29,7 +31,7 @@ func NewBuilder() *Builder {
 }
 
 // Add is a function that adds a new parser to the caller and returns it
-func (b *Builder) Add(p kindParser) *Builder {
+func (b *Builder) Add(p KindParser) *Builder {
 	b.parsers = append(b.parsers, p)
 	return b
 }
@@ -37,7 +39,7 @@ func (b *Builder) Add(p kindParser) *Builder {
 // Build prepares parsers and associates a parser to its extension and returns it
 func (b *Builder) Build(types []string) (*Parser, error) {
 	var suportedTypes []string
-	parsers := make(map[string]kindParser, len(b.parsers))
+	parsers := make(map[string]KindParser, len(b.parsers))
 	extensions := make(model.Extensions, len(b.parsers))
 	for _, parser := range b.parsers {
 		suportedTypes = append(suportedTypes, parser.SupportedTypes()...)
@@ -64,18 +66,19 @@ var ErrNotSupportedFile = errors.New("unsupported file to parse")
 
 // Parser is a struct that associates a parser to its supported extensions
 type Parser struct {
-	parsers    map[string]kindParser
+	parsers    map[string]KindParser
 	extensions model.Extensions
 }
 
 // Parse executes a parser on the fileContent and returns the file content as a Document, the file kind and
 // an error, if an error has occurred
 func (c *Parser) Parse(filePath string, fileContent []byte) ([]model.Document, model.FileKind, error) {
-	ext := filepath.Ext(filePath)
-	if ext == "" {
-		ext = filepath.Base(filePath)
+	p, ok := c.parsers[filepath.Ext(filePath)]
+	if !ok {
+		// files without extension or with a name supported (ex: terragrunt.hcl) are parsed by name
+		p, ok = c.parsers[filepath.Base(filePath)]
 	}
-	if p, ok := c.parsers[ext]; ok {
+	if ok {
 		obj, err := p.Parse(filePath, fileContent)
 		if err != nil {
 			return nil, "", err
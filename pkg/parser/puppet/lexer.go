@@ -0,0 +1,137 @@
+package puppet
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenType int
+
+const (
+	tokenWord tokenType = iota
+	tokenString
+	tokenVariable
+	tokenPunct
+)
+
+// token is a lexical unit of a manifest with the line where it starts
+type token struct {
+	kind  tokenType
+	value string
+	line  int
+}
+
+var twoCharPuncts = map[string]struct{}{
+	"=>": {},
+	"+>": {},
+	"->": {},
+	"~>": {},
+	"==": {},
+	"!=": {},
+	"=~": {},
+	"<|": {},
+	"|>": {},
+}
+
+// tokenize splits the manifest content into tokens, skipping whitespaces and comments
+func tokenize(content string) ([]token, error) {
+	tokens := make([]token, 0)
+	line := 1
+	runes := []rune(content)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t':
+			i++
+		case c == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			j := i + 2
+			for j+1 < len(runes) && !(runes[j] == '*' && runes[j+1] == '/') {
+				if runes[j] == '\n' {
+					line++
+				}
+				j++
+			}
+			if j+1 >= len(runes) {
+				return nil, fmt.Errorf("unterminated comment at line %d", line)
+			}
+			i = j + 2
+		case c == '\'' || c == '"':
+			value, consumed, lines, err := readString(runes[i:], c)
+			if err != nil {
+				return nil, fmt.Errorf("%s at line %d", err.Error(), line)
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value, line: line})
+			line += lines
+			i += consumed
+		case c == '$':
+			j := i + 1
+			for j < len(runes) && (isWordRune(runes[j]) || runes[j] == ':') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenVariable, value: string(runes[i:j]), line: line})
+			i = j
+		case isWordRune(c):
+			j := i
+			for j < len(runes) && (isWordRune(runes[j]) ||
+				(runes[j] == ':' && j+1 < len(runes) && runes[j+1] == ':') ||
+				(runes[j] == '-' && j+1 < len(runes) && runes[j+1] != '>')) {
+				if runes[j] == ':' {
+					j++
+				}
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenWord, value: string(runes[i:j]), line: line})
+			i = j
+		default:
+			if i+1 < len(runes) {
+				if _, ok := twoCharPuncts[string(runes[i:i+2])]; ok {
+					tokens = append(tokens, token{kind: tokenPunct, value: string(runes[i : i+2]), line: line})
+					i += 2
+					continue
+				}
+			}
+			tokens = append(tokens, token{kind: tokenPunct, value: string(c), line: line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// readString reads a quoted string returning its value, the number of runes consumed
+// and the number of line breaks inside it
+func readString(runes []rune, quote rune) (value string, consumed, lines int, err error) {
+	var sb strings.Builder
+	for i := 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if i+1 < len(runes) && (runes[i+1] == quote || runes[i+1] == '\\') {
+				sb.WriteRune(runes[i+1])
+				i++
+				continue
+			}
+			sb.WriteRune(runes[i])
+		case quote:
+			return sb.String(), i + 1, lines, nil
+		case '\n':
+			lines++
+			sb.WriteRune(runes[i])
+		default:
+			sb.WriteRune(runes[i])
+		}
+	}
+	return "", 0, 0, fmt.Errorf("unterminated string")
+}
+
+func isWordRune(c rune) bool {
+	return c == '_' || c == '.' ||
+		(c >= 'a' && c <= 'z') ||
+		(c >= 'A' && c <= 'Z') ||
+		(c >= '0' && c <= '9')
+}
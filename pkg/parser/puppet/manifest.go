@@ -0,0 +1,310 @@
+package puppet
+
+import (
+	"fmt"
+	"strings"
+)
+
+// definitionKeywords are the keywords that open a named scope containing resources
+var definitionKeywords = map[string]struct{}{
+	"class":  {},
+	"define": {},
+	"node":   {},
+}
+
+var closingPunct = map[string]string{
+	"{": "}",
+	"[": "]",
+	"(": ")",
+}
+
+// manifestParser walks manifest tokens collecting resource declarations
+type manifestParser struct {
+	tokens []token
+	pos    int
+}
+
+func (m *manifestParser) eof() bool {
+	return m.pos >= len(m.tokens)
+}
+
+func (m *manifestParser) peek(offset int) token {
+	if m.pos+offset >= len(m.tokens) {
+		return token{}
+	}
+	return m.tokens[m.pos+offset]
+}
+
+func (m *manifestParser) isPunct(offset int, value string) bool {
+	t := m.peek(offset)
+	return t.kind == tokenPunct && t.value == value
+}
+
+// parseBody collects resources until the end of the manifest or the '}' closing the current scope
+func (m *manifestParser) parseBody(class string, resources []Resource) []Resource {
+	for !m.eof() {
+		current := m.peek(0)
+		switch {
+		case m.isPunct(0, "}"):
+			m.pos++
+			return resources
+		case m.isPunct(0, "{"):
+			m.pos++
+			resources = m.parseBody(class, resources)
+		case current.kind == tokenWord && isDefinition(current.value) && !m.isPunct(1, "{"):
+			resources = m.parseDefinition(resources)
+		case current.kind == tokenWord && m.isPunct(1, "{") && m.isResourceDeclaration():
+			resources = m.parseResource(class, resources)
+		default:
+			m.pos++
+		}
+	}
+	return resources
+}
+
+func isDefinition(word string) bool {
+	_, ok := definitionKeywords[word]
+	return ok
+}
+
+// parseDefinition parses class, define and node statements and the resources declared inside them
+func (m *manifestParser) parseDefinition(resources []Resource) []Resource {
+	m.pos++
+	name := m.peek(0).value
+	for !m.eof() && !m.isPunct(0, "{") {
+		if m.isPunct(0, "(") {
+			m.skipBalanced()
+			continue
+		}
+		m.pos++
+	}
+	if m.eof() {
+		return resources
+	}
+	m.pos++
+	return m.parseBody(name, resources)
+}
+
+// isResourceDeclaration checks if the tokens after the resource type are a title followed by ':'
+func (m *manifestParser) isResourceDeclaration() bool {
+	start := m.pos
+	defer func() { m.pos = start }()
+	m.pos += 2
+	title := m.peek(0)
+	if title.kind == tokenPunct && title.value != "[" {
+		return false
+	}
+	m.parseValue()
+	return m.isPunct(0, ":")
+}
+
+// parseResource parses a resource declaration, returning one resource for each title
+func (m *manifestParser) parseResource(class string, resources []Resource) []Resource {
+	resourceType := strings.ToLower(m.peek(0).value)
+	m.pos += 2
+	for !m.eof() {
+		if m.isPunct(0, "}") {
+			m.pos++
+			return resources
+		}
+		startLine := m.peek(0).line
+		titles := toTitles(m.parseValue())
+		if !m.isPunct(0, ":") {
+			m.skipUntil("}")
+			return resources
+		}
+		m.pos++
+
+		attributes := m.parseAttributes()
+		endLine := m.peek(0).line
+		if m.isPunct(0, ";") {
+			m.pos++
+		}
+
+		for _, title := range titles {
+			resources = append(resources, Resource{
+				Type:       resourceType,
+				Title:      title,
+				Class:      class,
+				Attributes: copyAttributes(attributes),
+				StartLine:  startLine,
+				EndLine:    endLine,
+			})
+		}
+	}
+	return resources
+}
+
+// parseAttributes parses 'name => value' pairs until the end of the resource body or the next title
+func (m *manifestParser) parseAttributes() map[string]interface{} {
+	attributes := make(map[string]interface{})
+	for !m.eof() && !m.isPunct(0, "}") && !m.isPunct(0, ";") {
+		name := m.peek(0)
+		if !m.isPunct(1, "=>") && !m.isPunct(1, "+>") {
+			m.pos++
+			continue
+		}
+		m.pos += 2
+		attributes[name.value] = m.parseValue()
+		m.skipExpression()
+		if m.isPunct(0, ",") {
+			m.pos++
+		}
+	}
+	return attributes
+}
+
+// parseValue parses a single value: strings, barewords, variables, references, arrays and hashes
+func (m *manifestParser) parseValue() interface{} {
+	current := m.peek(0)
+	switch current.kind {
+	case tokenString:
+		m.pos++
+		return current.value
+	case tokenVariable:
+		m.pos++
+		if m.isPunct(0, "[") {
+			return current.value + m.rawBalanced()
+		}
+		return current.value
+	case tokenWord:
+		m.pos++
+		switch {
+		case m.isPunct(0, "["), m.isPunct(0, "("):
+			return current.value + m.rawBalanced()
+		case current.value == "true":
+			return true
+		case current.value == "false":
+			return false
+		case current.value == "undef":
+			return nil
+		}
+		return current.value
+	}
+
+	switch current.value {
+	case "[":
+		return m.parseArray()
+	case "{":
+		return m.parseHash()
+	}
+	m.pos++
+	return current.value
+}
+
+func (m *manifestParser) parseArray() []interface{} {
+	m.pos++
+	values := make([]interface{}, 0)
+	for !m.eof() && !m.isPunct(0, "]") {
+		if m.isPunct(0, ",") {
+			m.pos++
+			continue
+		}
+		values = append(values, m.parseValue())
+	}
+	m.pos++
+	return values
+}
+
+func (m *manifestParser) parseHash() map[string]interface{} {
+	m.pos++
+	values := make(map[string]interface{})
+	for !m.eof() && !m.isPunct(0, "}") {
+		if m.isPunct(0, ",") {
+			m.pos++
+			continue
+		}
+		key := m.peek(0).value
+		m.pos++
+		if !m.isPunct(0, "=>") {
+			continue
+		}
+		m.pos++
+		values[key] = m.parseValue()
+	}
+	m.pos++
+	return values
+}
+
+// skipExpression skips the remaining tokens of an attribute value (operators, selectors, etc.)
+func (m *manifestParser) skipExpression() {
+	for !m.eof() && !m.isPunct(0, ",") && !m.isPunct(0, ";") && !m.isPunct(0, "}") {
+		if _, ok := closingPunct[m.peek(0).value]; ok && m.peek(0).kind == tokenPunct {
+			m.skipBalanced()
+			continue
+		}
+		m.pos++
+	}
+}
+
+// skipUntil skips tokens until the given punctuation is consumed
+func (m *manifestParser) skipUntil(value string) {
+	for !m.eof() {
+		if m.isPunct(0, value) {
+			m.pos++
+			return
+		}
+		if _, ok := closingPunct[m.peek(0).value]; ok && m.peek(0).kind == tokenPunct {
+			m.skipBalanced()
+			continue
+		}
+		m.pos++
+	}
+}
+
+// skipBalanced skips a bracketed group of tokens
+func (m *manifestParser) skipBalanced() {
+	m.rawBalanced()
+}
+
+// rawBalanced consumes a bracketed group of tokens returning its raw representation
+func (m *manifestParser) rawBalanced() string {
+	var sb strings.Builder
+	depth := 0
+	for !m.eof() {
+		current := m.peek(0)
+		m.pos++
+		if current.kind == tokenString {
+			sb.WriteString("'" + current.value + "'")
+			continue
+		}
+		sb.WriteString(current.value)
+		if current.kind != tokenPunct {
+			continue
+		}
+		if _, ok := closingPunct[current.value]; ok {
+			depth++
+		} else if current.value == "}" || current.value == "]" || current.value == ")" {
+			depth--
+		}
+		if depth == 0 {
+			break
+		}
+	}
+	return sb.String()
+}
+
+func toTitles(value interface{}) []string {
+	switch v := value.(type) {
+	case []interface{}:
+		titles := make([]string, 0, len(v))
+		for _, title := range v {
+			titles = append(titles, toTitles(title)...)
+		}
+		return titles
+	case string:
+		return []string{v}
+	case nil:
+		return []string{}
+	default:
+		return []string{fmt.Sprintf("%v", v)}
+	}
+}
+
+func copyAttributes(attributes map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(attributes))
+	for k, v := range attributes {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,70 @@
+package puppet
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/pkg/errors"
+)
+
+// Parser is a Puppet manifest parser
+type Parser struct {
+}
+
+// Manifest is the representation of the resources declared in a manifest
+type Manifest struct {
+	Resources []Resource `json:"resource"`
+}
+
+// Resource is the struct for each resource declaration found in a manifest
+// Class is the class, define or node where the resource was declared (empty for top scope)
+type Resource struct {
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Class      string                 `json:"class"`
+	Attributes map[string]interface{} `json:"attributes"`
+	StartLine  int                    `json:"startLine"`
+	EndLine    int                    `json:"endLine"`
+}
+
+// Parse parses a Puppet manifest and returns its resources as a Document
+func (p *Parser) Parse(_ string, fileContent []byte) ([]model.Document, error) {
+	tokens, err := tokenize(strings.ReplaceAll(string(fileContent), "\r", ""))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse Puppet manifest")
+	}
+
+	ps := &manifestParser{tokens: tokens}
+	manifest := Manifest{Resources: make([]Resource, 0)}
+	for !ps.eof() {
+		manifest.Resources = ps.parseBody("", manifest.Resources)
+	}
+
+	j, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to Marshal Puppet manifest")
+	}
+
+	doc := model.Document{}
+	if err := json.Unmarshal(j, &doc); err != nil {
+		return nil, errors.Wrap(err, "failed to Unmarshal Puppet manifest")
+	}
+
+	return []model.Document{doc}, nil
+}
+
+// GetKind returns PUPPET constant kind
+func (p *Parser) GetKind() model.FileKind {
+	return model.KindPUPPET
+}
+
+// SupportedExtensions returns extensions supported by this parser, which is pp extension
+func (p *Parser) SupportedExtensions() []string {
+	return []string{".pp"}
+}
+
+// SupportedTypes returns types supported by this parser, which is puppet
+func (p *Parser) SupportedTypes() []string {
+	return []string{"Puppet"}
+}
@@ -0,0 +1,84 @@
+package puppet
+
+import (
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParser_GetKind tests the functions [GetKind()] and all the methods called by them
+func TestParser_GetKind(t *testing.T) {
+	p := &Parser{}
+	require.Equal(t, model.KindPUPPET, p.GetKind())
+}
+
+// TestParser_SupportedExtensions tests the functions [SupportedExtensions()] and all the methods called by them
+func TestParser_SupportedExtensions(t *testing.T) {
+	p := &Parser{}
+	require.Equal(t, []string{".pp"}, p.SupportedExtensions())
+}
+
+// TestParser_SupportedTypes tests the functions [SupportedTypes()] and all the methods called by them
+func TestParser_SupportedTypes(t *testing.T) {
+	p := &Parser{}
+	require.Equal(t, []string{"Puppet"}, p.SupportedTypes())
+}
+
+// TestParser_Parse tests the functions [Parse()] and all the methods called by them
+func TestParser_Parse(t *testing.T) {
+	p := &Parser{}
+	sample := `# web profile
+class profile::web (
+  String $password = 'x',
+) {
+  file { '/etc/app.conf':
+    ensure  => file,
+    mode    => '0777',
+    require => Package['nginx'],
+  }
+  package { ['nginx', 'curl']: ensure => installed }
+  if $::osfamily == 'RedHat' {
+    yumrepo { 'epel':
+      baseurl  => "http://mirror/epel",
+      gpgcheck => false,
+    }
+  }
+}
+node default { user { 'bob': password => 'secret' } }
+`
+	docs, err := p.Parse("init.pp", []byte(sample))
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+
+	resources, ok := docs[0]["resource"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, resources, 5)
+
+	file := resources[0].(map[string]interface{})
+	require.Equal(t, "file", file["type"])
+	require.Equal(t, "/etc/app.conf", file["title"])
+	require.Equal(t, "profile::web", file["class"])
+	require.Equal(t, float64(5), file["startLine"])
+	require.Equal(t, float64(9), file["endLine"])
+	attributes := file["attributes"].(map[string]interface{})
+	require.Equal(t, "0777", attributes["mode"])
+	require.Equal(t, "Package['nginx']", attributes["require"])
+
+	require.Equal(t, "curl", resources[2].(map[string]interface{})["title"])
+
+	repo := resources[3].(map[string]interface{})
+	require.Equal(t, "yumrepo", repo["type"])
+	require.Equal(t, false, repo["attributes"].(map[string]interface{})["gpgcheck"])
+
+	user := resources[4].(map[string]interface{})
+	require.Equal(t, "default", user["class"])
+	require.Equal(t, "secret", user["attributes"].(map[string]interface{})["password"])
+}
+
+// TestParser_ParseInvalid tests the functions [Parse()] with an unterminated string
+func TestParser_ParseInvalid(t *testing.T) {
+	p := &Parser{}
+	_, err := p.Parse("init.pp", []byte("file { '/etc/app.conf:\n  mode => '0777' }"))
+	require.Error(t, err)
+}
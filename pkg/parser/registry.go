@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Factory creates a registered parser, it is called each time a parser is built from the registry,
+// so the parser can read the configuration of the scan when it is created
+type Factory func() (KindParser, error)
+
+// Registry keeps the parsers registered at runtime by name, in order of registration, the parsers registered later
+// take over the extensions of the ones registered earlier when a parser is built
+type Registry struct {
+	mutex     sync.RWMutex
+	names     []string
+	factories map[string]Factory
+}
+
+// DefaultRegistry is the registry of the parsers added to the parsers of KICS on each scan,
+// embedders register on it the parsers of their own formats
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: make(map[string]Factory),
+	}
+}
+
+// Register registers a parser in the DefaultRegistry
+func Register(name string, factory Factory) error {
+	return DefaultRegistry.Register(name, factory)
+}
+
+// Register registers the factory of a parser by name, names are unique
+func (r *Registry) Register(name string, factory Factory) error {
+	if name == "" || factory == nil {
+		return errors.New("parsers are registered with a name and a factory")
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.factories[name]; ok {
+		return errors.Errorf("parser %s already registered", name)
+	}
+	r.names = append(r.names, name)
+	r.factories[name] = factory
+	return nil
+}
+
+// Unregister removes the parser registered by name, returns false if it was not registered
+func (r *Registry) Unregister(name string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.factories[name]; !ok {
+		return false
+	}
+	delete(r.factories, name)
+	for i := range r.names {
+		if r.names[i] == name {
+			r.names = append(r.names[:i], r.names[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Include registers the parsers of other, the parsers registered with the same name are replaced in place,
+// so embedders can replace the built-in parsers
+func (r *Registry) Include(other *Registry) {
+	other.mutex.RLock()
+	names := append([]string{}, other.names...)
+	factories := make(map[string]Factory, len(other.factories))
+	for name, factory := range other.factories {
+		factories[name] = factory
+	}
+	other.mutex.RUnlock()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, name := range names {
+		if _, ok := r.factories[name]; !ok {
+			r.names = append(r.names, name)
+		}
+		r.factories[name] = factories[name]
+	}
+}
+
+// Names returns the names of the parsers registered, in order of registration
+func (r *Registry) Names() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return append([]string{}, r.names...)
+}
+
+// Build creates the parsers registered and returns the parser of the files of the types, as Builder.Build
+func (r *Registry) Build(types []string) (*Parser, error) {
+	r.mutex.RLock()
+	names := append([]string{}, r.names...)
+	factories := make([]Factory, 0, len(names))
+	for _, name := range names {
+		factories = append(factories, r.factories[name])
+	}
+	r.mutex.RUnlock()
+
+	builder := NewBuilder()
+	for i, factory := range factories {
+		p, err := factory()
+		if err != nil {
+			return &Parser{}, errors.Wrapf(err, "failed to create parser %s", names[i])
+		}
+		builder.Add(p)
+	}
+	return builder.Build(types)
+}
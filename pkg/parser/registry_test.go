@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	jsonParser "github.com/Checkmarx/kics/pkg/parser/json"
+	yamlParser "github.com/Checkmarx/kics/pkg/parser/yaml"
+	"github.com/stretchr/testify/require"
+)
+
+// customParser parses the files of a format unknown to KICS, as the parsers registered by embedders
+type customParser struct{}
+
+func (p *customParser) GetKind() model.FileKind {
+	return "CUSTOM"
+}
+
+func (p *customParser) SupportedExtensions() []string {
+	return []string{".custom", ".json"}
+}
+
+func (p *customParser) SupportedTypes() []string {
+	return []string{"Custom"}
+}
+
+func (p *customParser) Parse(filePath string, fileContent []byte) ([]model.Document, error) {
+	return []model.Document{{"content": string(fileContent)}}, nil
+}
+
+// TestRegistry tests the functions [Register()], [Unregister()], [Include()], [Build()] and all the methods called by them
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register("json", func() (KindParser, error) { return &jsonParser.Parser{}, nil }))
+	require.NoError(t, registry.Register("yaml", func() (KindParser, error) { return &yamlParser.Parser{}, nil }))
+	require.Error(t, registry.Register("json", func() (KindParser, error) { return &jsonParser.Parser{}, nil }))
+	require.Error(t, registry.Register("", func() (KindParser, error) { return &jsonParser.Parser{}, nil }))
+	require.Error(t, registry.Register("nil", nil))
+
+	embedded := NewRegistry()
+	require.NoError(t, embedded.Register("custom", func() (KindParser, error) { return &customParser{}, nil }))
+	registry.Include(embedded)
+	require.Equal(t, []string{"json", "yaml", "custom"}, registry.Names())
+
+	p, err := registry.Build([]string{""})
+	require.NoError(t, err)
+	docs, kind, err := p.Parse("file.custom", []byte("content"))
+	require.NoError(t, err)
+	require.Equal(t, model.FileKind("CUSTOM"), kind)
+	require.Equal(t, "content", docs[0]["content"])
+	// the parsers registered later take over the extensions of the earlier ones
+	_, kind, err = p.Parse("file.json", []byte("{}"))
+	require.NoError(t, err)
+	require.Equal(t, model.FileKind("CUSTOM"), kind)
+	_, kind, err = p.Parse("file.yaml", []byte("a: b"))
+	require.NoError(t, err)
+	require.Equal(t, model.KindYAML, kind)
+
+	// the types are validated against the types of all the parsers registered
+	p, err = registry.Build([]string{"Custom"})
+	require.NoError(t, err)
+	require.Contains(t, p.SupportedExtensions(), ".custom")
+	require.NotContains(t, p.SupportedExtensions(), ".yaml")
+
+	// parsers registered with the same name are replaced in place
+	replacement := NewRegistry()
+	require.NoError(t, replacement.Register("yaml", func() (KindParser, error) { return &customParser{}, nil }))
+	registry.Include(replacement)
+	require.Equal(t, []string{"json", "yaml", "custom"}, registry.Names())
+
+	p, err = registry.Build([]string{""})
+	require.NoError(t, err)
+	_, _, err = p.Parse("file.yaml", []byte("a: b"))
+	require.ErrorIs(t, err, ErrNotSupportedFile)
+
+	require.True(t, registry.Unregister("custom"))
+	require.True(t, registry.Unregister("yaml"))
+	require.False(t, registry.Unregister("custom"))
+	require.Equal(t, []string{"json"}, registry.Names())
+	p, err = registry.Build([]string{""})
+	require.NoError(t, err)
+	_, kind, err = p.Parse("file.json", []byte("{}"))
+	require.NoError(t, err)
+	require.Equal(t, model.KindJSON, kind)
+
+	failing := NewRegistry()
+	require.NoError(t, failing.Register("failing", func() (KindParser, error) { return nil, errors.New("missing configuration") }))
+	_, err = failing.Build([]string{""})
+	require.EqualError(t, err, "failed to create parser failing: missing configuration")
+}
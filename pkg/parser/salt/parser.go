@@ -0,0 +1,39 @@
+package salt
+
+import (
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/parser/jinja"
+	yamlParser "github.com/Checkmarx/kics/pkg/parser/yaml"
+	"github.com/pkg/errors"
+)
+
+// Parser is a SaltStack state parser
+type Parser struct {
+}
+
+// Parse renders the jinja templating of a sls file and parses the resulting YAML states as Documents
+func (p *Parser) Parse(filePath string, fileContent []byte) ([]model.Document, error) {
+	rendered := jinja.Render(string(fileContent), nil)
+
+	documents, err := (&yamlParser.Parser{}).Parse(filePath, []byte(rendered))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse SaltStack state")
+	}
+
+	return documents, nil
+}
+
+// GetKind returns SALT constant kind
+func (p *Parser) GetKind() model.FileKind {
+	return model.KindSALT
+}
+
+// SupportedExtensions returns extensions supported by this parser, which is sls extension
+func (p *Parser) SupportedExtensions() []string {
+	return []string{".sls"}
+}
+
+// SupportedTypes returns types supported by this parser, which is saltstack
+func (p *Parser) SupportedTypes() []string {
+	return []string{"SaltStack"}
+}
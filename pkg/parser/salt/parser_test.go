@@ -0,0 +1,54 @@
+package salt
+
+import (
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParser_GetKind tests the functions [GetKind()] and all the methods called by them
+func TestParser_GetKind(t *testing.T) {
+	p := &Parser{}
+	require.Equal(t, model.KindSALT, p.GetKind())
+}
+
+// TestParser_SupportedExtensions tests the functions [SupportedExtensions()] and all the methods called by them
+func TestParser_SupportedExtensions(t *testing.T) {
+	p := &Parser{}
+	require.Equal(t, []string{".sls"}, p.SupportedExtensions())
+}
+
+// TestParser_SupportedTypes tests the functions [SupportedTypes()] and all the methods called by them
+func TestParser_SupportedTypes(t *testing.T) {
+	p := &Parser{}
+	require.Equal(t, []string{"SaltStack"}, p.SupportedTypes())
+}
+
+// TestParser_Parse tests the functions [Parse()] and all the methods called by them
+func TestParser_Parse(t *testing.T) {
+	p := &Parser{}
+	sample := `{# deploy user #}
+{% set user = 'deploy' %}
+{{ user }}:
+  user.present:
+    - home: /home/{{ user }}
+{% if grains['os'] == 'Ubuntu' %}
+run_script:
+  cmd.run:
+    - name: curl {{ pillar['url'] }} | sh
+{% else %}
+run_script:
+  cmd.run:
+    - name: ls
+{% endif %}
+`
+	docs, err := p.Parse("init.sls", []byte(sample))
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	require.Contains(t, docs[0], "deploy")
+	require.Contains(t, docs[0], "run_script")
+
+	cmd := docs[0]["run_script"].(model.Document)["cmd.run"].([]interface{})
+	require.Equal(t, "curl ${pillar['url']} | sh", cmd[0].(model.Document)["name"])
+}
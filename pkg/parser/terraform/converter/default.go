@@ -16,10 +16,14 @@ import (
 // This file is attributed to https://github.com/tmccombs/hcl2json.
 // convertBlock() is manipulated for combining the both blocks and labels for one given resource.
 
+// VariableMap holds the values available to evaluate expressions by their root name (ex: var, terraform)
+type VariableMap map[string]cty.Value
+
 // DefaultConverted an hcl File to a toJson serializable object
 // This assumes that the body is a hclsyntax.Body
-var DefaultConverted = func(file *hcl.File) (model.Document, int, error) {
-	c := converter{bytes: file.Bytes}
+// Expressions referencing only known input variables are replaced by their values
+var DefaultConverted = func(file *hcl.File, inputVariables VariableMap) (model.Document, int, error) {
+	c := converter{bytes: file.Bytes, ctx: &hcl.EvalContext{Variables: inputVariables}}
 	body, err := c.convertBody(file.Body.(*hclsyntax.Body))
 
 	if err != nil {
@@ -36,6 +40,7 @@ var DefaultConverted = func(file *hcl.File) (model.Document, int, error) {
 
 type converter struct {
 	bytes []byte
+	ctx   *hcl.EvalContext
 }
 
 func (c *converter) rangeSource(r hcl.Range) string {
@@ -65,6 +70,15 @@ func (c *converter) convertBody(body *hclsyntax.Body) (model.Document, error) {
 }
 
 func (c *converter) convertBlock(block *hclsyntax.Block, out model.Document) error {
+	if block.Type == "dynamic" && len(block.Labels) == 1 {
+		if expanded, ok := c.expandDynamicBlock(block); ok {
+			for _, value := range expanded {
+				appendBlock(out, block.Labels[0], value)
+			}
+			return nil
+		}
+	}
+
 	var key = block.Type
 	value, err := c.convertBody(block.Body)
 	if err != nil {
@@ -86,6 +100,13 @@ func (c *converter) convertBlock(block *hclsyntax.Block, out model.Document) err
 		key = label
 	}
 
+	appendBlock(out, key, value)
+
+	return nil
+}
+
+// appendBlock sets the block value in out, blocks with the same key are grouped in a list
+func appendBlock(out model.Document, key string, value model.Document) {
 	if current, exists := out[key]; exists {
 		if list, ok := current.([]interface{}); ok {
 			out[key] = append(list, value)
@@ -95,8 +116,63 @@ func (c *converter) convertBlock(block *hclsyntax.Block, out model.Document) err
 	} else {
 		out[key] = value
 	}
+}
 
-	return nil
+// expandDynamicBlock converts the content of a dynamic block for each element of its for_each collection,
+// returning false when the collection can not be statically resolved so the block is kept as it is
+func (c *converter) expandDynamicBlock(block *hclsyntax.Block) ([]model.Document, bool) {
+	forEach, ok := block.Body.Attributes["for_each"]
+	if !ok {
+		return nil, false
+	}
+	collection, ok := c.evalStatic(forEach.Expr)
+	if !ok || !collection.CanIterateElements() {
+		return nil, false
+	}
+
+	iterator := block.Labels[0]
+	if attribute, ok := block.Body.Attributes["iterator"]; ok {
+		if iterator = hcl.ExprAsKeyword(attribute.Expr); iterator == "" {
+			return nil, false
+		}
+	}
+
+	var content *hclsyntax.Block
+	for _, nested := range block.Body.Blocks {
+		if nested.Type == "content" {
+			content = nested
+		}
+	}
+	if content == nil {
+		return nil, false
+	}
+
+	expanded := make([]model.Document, 0, collection.LengthInt())
+	for it := collection.ElementIterator(); it.Next(); {
+		key, value := it.Element()
+		iteration := c.withVariable(iterator, cty.ObjectVal(map[string]cty.Value{
+			"key":   key,
+			"value": value,
+		}))
+		doc, err := iteration.convertBody(content.Body)
+		if err != nil {
+			return nil, false
+		}
+		expanded = append(expanded, doc)
+	}
+	return expanded, true
+}
+
+// withVariable returns a copy of the converter where the variable is also known
+func (c *converter) withVariable(name string, value cty.Value) *converter {
+	variables := make(map[string]cty.Value)
+	if c.ctx != nil {
+		for root, v := range c.ctx.Variables {
+			variables[root] = v
+		}
+	}
+	variables[name] = value
+	return &converter{bytes: c.bytes, ctx: &hcl.EvalContext{Variables: variables}}
 }
 
 func (c *converter) convertExpression(expr hclsyntax.Expression) (interface{}, error) {
@@ -105,6 +181,9 @@ func (c *converter) convertExpression(expr hclsyntax.Expression) (interface{}, e
 	case *hclsyntax.LiteralValueExpr:
 		return ctyjson.SimpleJSONValue{Value: value.Val}, nil
 	case *hclsyntax.TemplateExpr:
+		if evaluated, ok := c.evalExpr(value); ok {
+			return ctyjson.SimpleJSONValue{Value: evaluated}, nil
+		}
 		return c.convertTemplate(value)
 	case *hclsyntax.TemplateWrapExpr:
 		return c.convertExpression(value.Wrapped)
@@ -135,10 +214,39 @@ func (c *converter) convertExpression(expr hclsyntax.Expression) (interface{}, e
 		}
 		return m, nil
 	default:
+		if evaluated, ok := c.evalExpr(expr); ok {
+			return ctyjson.SimpleJSONValue{Value: evaluated}, nil
+		}
 		return c.wrapExpr(expr), nil
 	}
 }
 
+// evalExpr evaluates an expression referencing variables when all of them are known
+func (c *converter) evalExpr(expr hclsyntax.Expression) (cty.Value, bool) {
+	if len(expr.Variables()) == 0 {
+		return cty.NilVal, false
+	}
+	return c.evalStatic(expr)
+}
+
+// evalStatic evaluates an expression when all the variables it references are known, including literal expressions
+func (c *converter) evalStatic(expr hclsyntax.Expression) (cty.Value, bool) {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = &hcl.EvalContext{}
+	}
+	for _, traversal := range expr.Variables() {
+		if _, ok := ctx.Variables[traversal.RootName()]; !ok {
+			return cty.NilVal, false
+		}
+	}
+	value, diagnostics := expr.Value(ctx)
+	if diagnostics.HasErrors() || value.IsNull() || !value.IsWhollyKnown() {
+		return cty.NilVal, false
+	}
+	return value, true
+}
+
 func (c *converter) convertKey(keyExpr hclsyntax.Expression) (string, error) {
 	// a key should never have dynamic input
 	if k, isKeyExpr := keyExpr.(*hclsyntax.ObjectConsKeyExpr); isKeyExpr {
@@ -190,6 +298,11 @@ func (c *converter) convertStringPart(expr hclsyntax.Expression) (string, error)
 	case *hclsyntax.TemplateJoinExpr:
 		return c.convertTemplateFor(v.Tuple.(*hclsyntax.ForExpr))
 	default:
+		if evaluated, ok := c.evalExpr(expr); ok {
+			if s, err := ctyconvert.Convert(evaluated, cty.String); err == nil {
+				return s.AsString(), nil
+			}
+		}
 		// treating as an embedded expression
 		return c.wrapExpr(expr), nil
 	}
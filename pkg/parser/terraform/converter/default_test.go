@@ -9,6 +9,7 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
 )
 
 // TestLabelsWithNestedBlock tests the functions [DefaultConverted] and all the methods called by them (test with nested block)
@@ -30,7 +31,7 @@ block "label_one" "label_two" {
 
 	file, _ := hclsyntax.ParseConfig([]byte(input), "testFileName", hcl.Pos{Byte: 0, Line: 1, Column: 1})
 
-	body, _, err := DefaultConverted(file)
+	body, _, err := DefaultConverted(file, VariableMap{})
 	if err != nil {
 		t.Fatal("parse bytes:", err)
 	}
@@ -59,7 +60,7 @@ block "label_one" {
 
 	file, _ := hclsyntax.ParseConfig([]byte(input), "testFileName", hcl.Pos{Byte: 0, Line: 1, Column: 1})
 
-	body, _, err := DefaultConverted(file)
+	body, _, err := DefaultConverted(file, VariableMap{})
 	if err != nil {
 		t.Fatal("parse bytes:", err)
 	}
@@ -96,7 +97,7 @@ block "label_one" {
 
 	file, _ := hclsyntax.ParseConfig([]byte(input), "testFileName", hcl.Pos{Byte: 0, Line: 1, Column: 1})
 
-	body, _, err := DefaultConverted(file)
+	body, _, err := DefaultConverted(file, VariableMap{})
 	if err != nil {
 		t.Fatal("parse bytes:", err)
 	}
@@ -228,7 +229,7 @@ variable "region" {
 
 	file, _ := hclsyntax.ParseConfig([]byte(input), "testFileName", hcl.Pos{Byte: 0, Line: 1, Column: 1})
 
-	body, _, err := DefaultConverted(file)
+	body, _, err := DefaultConverted(file, VariableMap{})
 	if err != nil {
 		t.Fatal("parse bytes:", err)
 	}
@@ -247,6 +248,138 @@ variable "region" {
 	require.Equal(t, expectedValue, got)
 }
 
+// TestConversionWithVariables tests the functions [DefaultConverted] and all the methods called by them (test with input variables)
+func TestConversionWithVariables(t *testing.T) {
+	input := `
+resource "aws_s3_bucket" "b" {
+	bucket = var.bucket
+	acl    = "${var.acl}"
+	name   = "${var.prefix}-${terraform.workspace}"
+	tags   = var.tags
+	region = var.region
+	count  = length(var.zones)
+}
+`
+
+	expected := `{
+	"resource": {
+		"aws_s3_bucket": {
+			"b": {
+				"acl": "public-read",
+				"bucket": "kics-bucket",
+				"count": "${length(var.zones)}",
+				"name": "kics-prod",
+				"region": "${var.region}",
+				"tags": {
+					"Environment": "prod"
+				}
+			}
+		}
+	}
+}`
+
+	inputVariables := VariableMap{
+		"var": cty.ObjectVal(map[string]cty.Value{
+			"bucket": cty.StringVal("kics-bucket"),
+			"acl":    cty.StringVal("public-read"),
+			"prefix": cty.StringVal("kics"),
+			"tags":   cty.ObjectVal(map[string]cty.Value{"Environment": cty.StringVal("prod")}),
+			"zones":  cty.ListVal([]cty.Value{cty.StringVal("a")}),
+		}),
+		"terraform": cty.ObjectVal(map[string]cty.Value{
+			"workspace": cty.StringVal("prod"),
+		}),
+	}
+
+	file, _ := hclsyntax.ParseConfig([]byte(input), "testFileName", hcl.Pos{Byte: 0, Line: 1, Column: 1})
+
+	body, _, err := DefaultConverted(file, inputVariables)
+	require.NoError(t, err)
+	inputMarsheld, err := json.Marshal(body)
+	require.NoError(t, err)
+	compareTest(t, inputMarsheld, expected)
+}
+
+// TestDynamicBlocks tests the functions [DefaultConverted] and all the methods called by them (test with dynamic blocks)
+func TestDynamicBlocks(t *testing.T) {
+	input := `
+resource "aws_security_group" "sg" {
+	ingress {
+		from_port = 443
+	}
+	dynamic "ingress" {
+		for_each = var.ports
+		content {
+			from_port   = ingress.value
+			description = "port ${ingress.key}"
+		}
+	}
+	dynamic "egress" {
+		for_each = [{ cidr = "0.0.0.0/0" }]
+		iterator = rule
+		content {
+			cidr_blocks = [rule.value.cidr]
+		}
+	}
+	dynamic "tag" {
+		for_each = local.tags
+		content {
+			key = tag.key
+		}
+	}
+}
+`
+
+	expected := `{
+	"resource": {
+		"aws_security_group": {
+			"sg": {
+				"dynamic": {
+					"tag": {
+						"content": {
+							"key": "${tag.key}"
+						},
+						"for_each": "${local.tags}"
+					}
+				},
+				"egress": {
+					"cidr_blocks": [
+						"0.0.0.0/0"
+					]
+				},
+				"ingress": [
+					{
+						"from_port": 443
+					},
+					{
+						"description": "port 0",
+						"from_port": 22
+					},
+					{
+						"description": "port 1",
+						"from_port": 80
+					}
+				]
+			}
+		}
+	}
+}`
+
+	inputVariables := VariableMap{
+		"var": cty.ObjectVal(map[string]cty.Value{
+			"ports": cty.ListVal([]cty.Value{cty.NumberIntVal(22), cty.NumberIntVal(80)}),
+		}),
+	}
+
+	file, _ := hclsyntax.ParseConfig([]byte(input), "testFileName", hcl.Pos{Byte: 0, Line: 1, Column: 1})
+
+	body, _, err := DefaultConverted(file, inputVariables)
+	require.NoError(t, err)
+	inputMarsheld, err := json.Marshal(body)
+	require.NoError(t, err)
+	compareTest(t, inputMarsheld, expected)
+}
+
 func compareTest(t *testing.T, input []byte, expected string) {
 	var indented bytes.Buffer
 	if err := json.Indent(&indented, input, "", "\t"); err != nil {
@@ -0,0 +1,88 @@
+package terraform
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	modulesManifest = ".terraform/modules/modules.json"
+	rootModuleName  = "root"
+)
+
+// modulesManifestEntry is a module installed by terraform init, Key is the module path (ex: a.b) and
+// Dir is the directory of the module relative to the root module
+type modulesManifestEntry struct {
+	Key string `json:"Key"`
+	Dir string `json:"Dir"`
+}
+
+type modulesManifestContent struct {
+	Modules []modulesManifestEntry `json:"Modules"`
+}
+
+// getModuleCallChain returns the chain of module calls (root, module.a, module.b) that installed the module in dir,
+// using the manifest of the closest parent root module, it is empty for root modules and directories
+// not installed by terraform init, it must be called with the mutex of the parser locked
+func (p *Parser) getModuleCallChain(dir string) []string {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
+	}
+	for root := absDir; ; root = filepath.Dir(root) {
+		if chain := findModuleCallChain(p.getModulesManifest(root), root, absDir); chain != nil {
+			return chain
+		}
+		if filepath.Dir(root) == root {
+			return nil
+		}
+	}
+}
+
+// getModulesManifest returns the modules manifest of the root directory, nil if it has none, the manifests are read
+// once for the lifetime of the parser
+func (p *Parser) getModulesManifest(root string) *modulesManifestContent {
+	if p.modulesManifests == nil {
+		p.modulesManifests = make(map[string]*modulesManifestContent)
+	}
+	if manifest, ok := p.modulesManifests[root]; ok {
+		return manifest
+	}
+	manifest := readModulesManifest(root)
+	p.modulesManifests[root] = manifest
+	return manifest
+}
+
+func readModulesManifest(root string) *modulesManifestContent {
+	content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(modulesManifest)))
+	if err != nil {
+		return nil
+	}
+	var manifest modulesManifestContent
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		log.Debug().Msgf("terraform.readModulesManifest() failed to read modules manifest in %s: %s", root, err)
+		return nil
+	}
+	return &manifest
+}
+
+func findModuleCallChain(manifest *modulesManifestContent, root, dir string) []string {
+	if manifest == nil {
+		return nil
+	}
+	for _, module := range manifest.Modules {
+		if module.Key == "" || filepath.Join(root, filepath.FromSlash(module.Dir)) != dir {
+			continue
+		}
+		chain := []string{rootModuleName}
+		for _, name := range strings.Split(module.Key, ".") {
+			chain = append(chain, "module."+name)
+		}
+		return chain
+	}
+	return nil
+}
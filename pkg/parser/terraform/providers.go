@@ -0,0 +1,125 @@
+package terraform
+
+import (
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/parser/terraform/converter"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ProviderContextKey is the key added to each resource and data source with the provider configuration it uses
+const ProviderContextKey = "_kics_provider"
+
+// providerConfig is the provider configuration used by a resource
+type providerConfig struct {
+	name   string
+	alias  string
+	region string
+}
+
+func (pc providerConfig) toDocument() model.Document {
+	doc := model.Document{
+		"name": pc.name,
+	}
+	if pc.alias != "" {
+		doc["alias"] = pc.alias
+	}
+	if pc.region != "" {
+		doc["region"] = pc.region
+	}
+	return doc
+}
+
+// getProviders returns the provider configurations declared in the module in dir, indexed by their
+// reference (ex: aws for the default configuration and aws.west for the configuration with alias west)
+func getProviders(dir string, inputVariables converter.VariableMap) map[string]providerConfig {
+	providers := make(map[string]providerConfig)
+	ctx := &hcl.EvalContext{Variables: inputVariables}
+	for _, block := range getModuleBlocks(dir, "provider") {
+		if len(block.Labels) != 1 {
+			continue
+		}
+		config := providerConfig{name: block.Labels[0]}
+		if attribute, ok := block.Body.Attributes["alias"]; ok {
+			config.alias = evalString(attribute.Expr, ctx)
+		}
+		for _, regionAttribute := range []string{"region", "location"} {
+			if attribute, ok := block.Body.Attributes[regionAttribute]; ok {
+				config.region = evalString(attribute.Expr, ctx)
+				break
+			}
+		}
+		reference := config.name
+		if config.alias != "" {
+			reference += "." + config.alias
+		}
+		providers[reference] = config
+	}
+	return providers
+}
+
+func evalString(expr hcl.Expression, ctx *hcl.EvalContext) string {
+	value, diagnostics := expr.Value(ctx)
+	if diagnostics.HasErrors() || value.IsNull() || !value.IsKnown() || value.Type() != cty.String {
+		return ""
+	}
+	return value.AsString()
+}
+
+// addProviderContext adds to each resource and data source of the document the provider configuration it uses,
+// either the one referenced in its provider argument or the default configuration for its type
+func addProviderContext(document model.Document, providers map[string]providerConfig) {
+	for _, blockType := range []string{"resource", "data"} {
+		types, ok := document[blockType].(model.Document)
+		if !ok {
+			continue
+		}
+		for resourceType, r := range types {
+			resources, ok := r.(model.Document)
+			if !ok {
+				continue
+			}
+			for _, resource := range resources {
+				for _, body := range getBodies(resource) {
+					body[ProviderContextKey] = getResourceProvider(resourceType, body, providers).toDocument()
+				}
+			}
+		}
+	}
+}
+
+// getBodies returns the bodies of a resource, which are grouped in a list when the resource is declared more than once
+func getBodies(resource interface{}) []model.Document {
+	switch r := resource.(type) {
+	case model.Document:
+		return []model.Document{r}
+	case []interface{}:
+		bodies := make([]model.Document, 0, len(r))
+		for _, body := range r {
+			if doc, ok := body.(model.Document); ok {
+				bodies = append(bodies, doc)
+			}
+		}
+		return bodies
+	default:
+		return nil
+	}
+}
+
+func getResourceProvider(resourceType string, body model.Document, providers map[string]providerConfig) providerConfig {
+	reference := strings.SplitN(resourceType, "_", 2)[0]
+	if provider, ok := body["provider"].(string); ok {
+		reference = strings.TrimSuffix(strings.TrimPrefix(provider, "${"), "}")
+	}
+	if config, ok := providers[reference]; ok {
+		return config
+	}
+	parts := strings.SplitN(reference, ".", 2)
+	config := providerConfig{name: parts[0]}
+	if len(parts) == 2 {
+		config.alias = parts[1]
+	}
+	return config
+}
@@ -0,0 +1,44 @@
+package terraform
+
+import (
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+const (
+	settingsBlock          = "terraform"
+	requiredProvidersBlock = "required_providers"
+)
+
+// normalizeSettings merges the terraform settings blocks of the document, which are grouped in a list when the file
+// declares more than one, so queries find its backend and required providers under input.document[i].terraform,
+// and turns the legacy version constraints of the required providers (ex: aws = "~> 3.0") into the object form
+// (ex: aws = { version = "~> 3.0" }), the keys are kept as in the file so the lines of the results are found
+func normalizeSettings(document model.Document) {
+	blocks := getBodies(document[settingsBlock])
+	if len(blocks) == 0 {
+		return
+	}
+	settings := blocks[0]
+	for _, block := range blocks[1:] {
+		for key, value := range block {
+			current, ok := settings[key].(model.Document)
+			if next, isDocument := value.(model.Document); ok && isDocument {
+				for nestedKey, nestedValue := range next {
+					current[nestedKey] = nestedValue
+				}
+				continue
+			}
+			settings[key] = value
+		}
+	}
+	for _, requiredProviders := range getBodies(settings[requiredProvidersBlock]) {
+		for name, requirement := range requiredProviders {
+			if version, ok := requirement.(ctyjson.SimpleJSONValue); ok && version.Type() == cty.String {
+				requiredProviders[name] = model.Document{"version": version}
+			}
+		}
+	}
+	document[settingsBlock] = settings
+}
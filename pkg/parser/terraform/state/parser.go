@@ -0,0 +1,198 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/parser/terraform"
+	"github.com/pkg/errors"
+)
+
+// MaskedValue replaces the value of sensitive attributes
+const MaskedValue = "********"
+
+var (
+	secretAttribute = regexp.MustCompile(`(?i)(password|secret|token|private_key|access_key|connection_string|credentials)`)
+	providerAddress = regexp.MustCompile(`^provider\["(?:[^"]*/)?([^"/]+)"\](?:\.(.+))?$`)
+)
+
+// Parser is a Terraform state file parser
+type Parser struct {
+}
+
+// state is the representation of a Terraform state file (version 4)
+type state struct {
+	Version   int             `json:"version"`
+	Resources []stateResource `json:"resources"`
+}
+
+type stateResource struct {
+	Module    string          `json:"module"`
+	Mode      string          `json:"mode"`
+	Type      string          `json:"type"`
+	Name      string          `json:"name"`
+	Provider  string          `json:"provider"`
+	Instances []stateInstance `json:"instances"`
+}
+
+type stateInstance struct {
+	IndexKey            interface{}                `json:"index_key"`
+	Attributes          map[string]interface{}     `json:"attributes"`
+	SensitiveAttributes [][]map[string]interface{} `json:"sensitive_attributes"`
+}
+
+// Parse converts the resources of a Terraform state file into a Document with the same structure as
+// parsed Terraform configurations, so the same queries can be evaluated on deployed infrastructure
+// sensitive attributes and attributes that look like secrets are masked
+func (p *Parser) Parse(_ string, fileContent []byte) ([]model.Document, error) {
+	var st state
+	if err := json.Unmarshal(fileContent, &st); err != nil {
+		return nil, errors.Wrap(err, "failed to parse Terraform state")
+	}
+	if st.Version < 4 {
+		return nil, fmt.Errorf("unsupported Terraform state version %d", st.Version)
+	}
+
+	doc := model.Document{}
+	for _, resource := range st.Resources {
+		blockType := "resource"
+		if resource.Mode == "data" {
+			blockType = "data"
+		}
+		types, ok := doc[blockType].(model.Document)
+		if !ok {
+			types = model.Document{}
+			doc[blockType] = types
+		}
+		resources, ok := types[resource.Type].(model.Document)
+		if !ok {
+			resources = model.Document{}
+			types[resource.Type] = resources
+		}
+
+		for _, instance := range resource.Instances {
+			attributes := maskAttributes(instance.Attributes, instance.SensitiveAttributes)
+			attributes[terraform.ProviderContextKey] = getProvider(resource.Provider)
+			name := resource.Name
+			if instance.IndexKey != nil {
+				name = fmt.Sprintf("%s[%v]", resource.Name, instance.IndexKey)
+			}
+			addResource(resources, name, attributes)
+		}
+	}
+
+	return []model.Document{doc}, nil
+}
+
+// GetKind returns JSON constant kind
+func (p *Parser) GetKind() model.FileKind {
+	return model.KindJSON
+}
+
+// SupportedExtensions returns extensions supported by this parser, which is tfstate extension
+func (p *Parser) SupportedExtensions() []string {
+	return []string{".tfstate"}
+}
+
+// SupportedTypes returns types supported by this parser, which is terraform
+func (p *Parser) SupportedTypes() []string {
+	return []string{"Terraform"}
+}
+
+// addResource adds the resource attributes by its name, resources with the same name (from different modules) are grouped in a list
+func addResource(resources model.Document, name string, attributes model.Document) {
+	if current, exists := resources[name]; exists {
+		if list, ok := current.([]interface{}); ok {
+			resources[name] = append(list, attributes)
+		} else {
+			resources[name] = []interface{}{current, attributes}
+		}
+		return
+	}
+	resources[name] = attributes
+}
+
+func getProvider(address string) model.Document {
+	provider := model.Document{}
+	if match := providerAddress.FindStringSubmatch(address); match != nil {
+		provider["name"] = match[1]
+		if match[2] != "" {
+			provider["alias"] = match[2]
+		}
+	}
+	return provider
+}
+
+// maskAttributes masks the attributes marked as sensitive in the state and the ones named like secrets
+func maskAttributes(attributes map[string]interface{}, sensitivePaths [][]map[string]interface{}) model.Document {
+	masked := model.Document{}
+	for key, value := range attributes {
+		if secretAttribute.MatchString(key) && value != nil && value != "" {
+			masked[key] = MaskedValue
+			continue
+		}
+		masked[key] = value
+	}
+	for _, path := range sensitivePaths {
+		maskPath(masked, path)
+	}
+	return masked
+}
+
+// maskPath masks the value in the path, which is a list of get_attr and index steps
+func maskPath(value interface{}, path []map[string]interface{}) interface{} {
+	if len(path) == 0 {
+		if value == nil {
+			return nil
+		}
+		return MaskedValue
+	}
+	step := path[0]
+	switch current := value.(type) {
+	case model.Document:
+		if key, ok := getStepKey(step); ok {
+			if elem, exists := current[key]; exists {
+				current[key] = maskPath(elem, path[1:])
+			}
+		}
+	case map[string]interface{}:
+		if key, ok := getStepKey(step); ok {
+			if elem, exists := current[key]; exists {
+				current[key] = maskPath(elem, path[1:])
+			}
+		}
+	case []interface{}:
+		if index, ok := getStepIndex(step); ok && index >= 0 && index < len(current) {
+			current[index] = maskPath(current[index], path[1:])
+		}
+	}
+	return value
+}
+
+func getStepKey(step map[string]interface{}) (string, bool) {
+	switch step["type"] {
+	case "get_attr":
+		key, ok := step["value"].(string)
+		return key, ok
+	case "index":
+		if index, ok := step["value"].(map[string]interface{}); ok {
+			key, ok := index["value"].(string)
+			return key, ok
+		}
+	}
+	return "", false
+}
+
+func getStepIndex(step map[string]interface{}) (int, bool) {
+	if step["type"] != "index" {
+		return 0, false
+	}
+	index, ok := step["value"].(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	number, ok := index["value"].(float64)
+	return int(number), ok
+}
@@ -0,0 +1,64 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/parser/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParser_GetKind tests the functions [GetKind()] and all the methods called by them
+func TestParser_GetKind(t *testing.T) {
+	p := &Parser{}
+	require.Equal(t, model.KindJSON, p.GetKind())
+}
+
+// TestParser_SupportedExtensions tests the functions [SupportedExtensions()] and all the methods called by them
+func TestParser_SupportedExtensions(t *testing.T) {
+	p := &Parser{}
+	require.Equal(t, []string{".tfstate"}, p.SupportedExtensions())
+}
+
+// TestParser_SupportedTypes tests the functions [SupportedTypes()] and all the methods called by them
+func TestParser_SupportedTypes(t *testing.T) {
+	p := &Parser{}
+	require.Equal(t, []string{"Terraform"}, p.SupportedTypes())
+}
+
+// TestParser_Parse tests the functions [Parse()] and all the methods called by them
+func TestParser_Parse(t *testing.T) {
+	p := &Parser{}
+	content, err := os.ReadFile(filepath.FromSlash("../../../../test/fixtures/test_terraform_state/terraform.tfstate"))
+	require.NoError(t, err)
+
+	docs, err := p.Parse("terraform.tfstate", content)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+
+	resources := docs[0]["resource"].(model.Document)
+	bucket := resources["aws_s3_bucket"].(model.Document)["b"].(model.Document)
+	require.Equal(t, "public-read", bucket["acl"])
+	require.Equal(t, model.Document{"name": "aws"}, bucket[terraform.ProviderContextKey])
+
+	db := resources["aws_db_instance"].(model.Document)["db[0]"].(model.Document)
+	require.Equal(t, MaskedValue, db["password"])
+	require.Equal(t, false, db["storage_encrypted"])
+	require.Equal(t, MaskedValue, db["tags"].(map[string]interface{})["owner"])
+	require.Equal(t, model.Document{"name": "aws", "alias": "east"}, db[terraform.ProviderContextKey])
+
+	data := docs[0]["data"].(model.Document)["aws_caller_identity"].(model.Document)["current"].(model.Document)
+	require.Equal(t, "123456789012", data["account_id"])
+}
+
+// TestParser_Parse_Invalid tests the functions [Parse()] with invalid states
+func TestParser_Parse_Invalid(t *testing.T) {
+	p := &Parser{}
+	_, err := p.Parse("terraform.tfstate", []byte(`{"version": 3, "modules": []}`))
+	require.Error(t, err)
+
+	_, err = p.Parse("terraform.tfstate", []byte(`not json`))
+	require.Error(t, err)
+}
@@ -0,0 +1,157 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/parser/terraform"
+	"github.com/pkg/errors"
+)
+
+// plan is the representation of the JSON output of a Terraform plan (terraform show -json), only the values planned
+// for the resources are converted
+type plan struct {
+	FormatVersion    string `json:"format_version"`
+	TerraformVersion string `json:"terraform_version"`
+	PlannedValues    struct {
+		RootModule planModule `json:"root_module"`
+	} `json:"planned_values"`
+}
+
+type planModule struct {
+	Address      string         `json:"address"`
+	Resources    []planResource `json:"resources"`
+	ChildModules []planModule   `json:"child_modules"`
+}
+
+type planResource struct {
+	Address         string                 `json:"address"`
+	Mode            string                 `json:"mode"`
+	Type            string                 `json:"type"`
+	Name            string                 `json:"name"`
+	Index           interface{}            `json:"index"`
+	ProviderName    string                 `json:"provider_name"`
+	Values          map[string]interface{} `json:"values"`
+	SensitiveValues map[string]interface{} `json:"sensitive_values"`
+}
+
+// IsPlan returns true if the JSON document is the output of a Terraform plan (terraform show -json)
+func IsPlan(document model.Document) bool {
+	_, hasFormat := document["format_version"]
+	_, hasVersion := document["terraform_version"]
+	_, hasPlannedValues := document["planned_values"]
+	return hasFormat && hasVersion && hasPlannedValues
+}
+
+// ParsePlan converts the values planned for the resources of the JSON output of a Terraform plan into Documents with
+// the same structure as parsed Terraform configurations, so the same queries are evaluated on the values resolved by
+// the plan, after the interpolation of the variables and the expansion of the modules, the resources of the root module
+// are in the first document and the resources of each module with resources in a document with its module call chain,
+// so the resources with the same name in different modules are kept apart, sensitive values and values that look
+// like secrets are masked
+func ParsePlan(fileContent []byte) ([]model.Document, error) {
+	var p plan
+	if err := json.Unmarshal(fileContent, &p); err != nil {
+		return nil, errors.Wrap(err, "failed to parse Terraform plan")
+	}
+	root := model.Document{}
+	addPlanResources(root, &p.PlannedValues.RootModule)
+	docs := []model.Document{root}
+	for i := range p.PlannedValues.RootModule.ChildModules {
+		docs = addPlanModule(docs, &p.PlannedValues.RootModule.ChildModules[i])
+	}
+	return docs, nil
+}
+
+// addPlanModule adds the document of the resources of the module, if any, and the documents of its child modules,
+// the call chain of the modules comes from their address (ex: module.vpc.module.subnets)
+func addPlanModule(docs []model.Document, module *planModule) []model.Document {
+	if len(module.Resources) > 0 {
+		doc := model.Document{}
+		addPlanResources(doc, module)
+		callChain := []string{"root"}
+		for _, name := range strings.Split(strings.TrimPrefix(module.Address, model.ModuleAddressPrefix), "."+model.ModuleAddressPrefix) {
+			callChain = append(callChain, model.ModuleAddressPrefix+name)
+		}
+		doc[model.ModuleCallChainKey] = callChain
+		docs = append(docs, doc)
+	}
+	for i := range module.ChildModules {
+		docs = addPlanModule(docs, &module.ChildModules[i])
+	}
+	return docs
+}
+
+func addPlanResources(doc model.Document, module *planModule) {
+	for i := range module.Resources {
+		resource := &module.Resources[i]
+		blockType := "resource"
+		if resource.Mode == "data" {
+			blockType = "data"
+		}
+		types, ok := doc[blockType].(model.Document)
+		if !ok {
+			types = model.Document{}
+			doc[blockType] = types
+		}
+		resources, ok := types[resource.Type].(model.Document)
+		if !ok {
+			resources = model.Document{}
+			types[resource.Type] = resources
+		}
+
+		attributes := maskAttributes(resource.Values, nil)
+		maskSensitiveValues(attributes, resource.SensitiveValues)
+		attributes[terraform.ProviderContextKey] = getPlanProvider(resource.ProviderName)
+		name := resource.Name
+		if resource.Index != nil {
+			name = fmt.Sprintf("%s[%v]", resource.Name, resource.Index)
+		}
+		addResource(resources, name, attributes)
+	}
+}
+
+// getPlanProvider returns the provider of a resource from its source address (ex: registry.terraform.io/hashicorp/aws),
+// the aliases of the providers are not part of the planned values
+func getPlanProvider(providerName string) model.Document {
+	provider := model.Document{}
+	if providerName != "" {
+		provider["name"] = providerName[strings.LastIndex(providerName, "/")+1:]
+	}
+	return provider
+}
+
+// maskSensitiveValues masks the values marked as sensitive, the sensitive values of the plan mirror the structure
+// of the values with true for the ones that are sensitive
+func maskSensitiveValues(value interface{}, sensitive interface{}) interface{} {
+	switch marks := sensitive.(type) {
+	case bool:
+		if marks && value != nil {
+			return MaskedValue
+		}
+	case map[string]interface{}:
+		switch current := value.(type) {
+		case model.Document:
+			for key, mark := range marks {
+				if elem, exists := current[key]; exists {
+					current[key] = maskSensitiveValues(elem, mark)
+				}
+			}
+		case map[string]interface{}:
+			for key, mark := range marks {
+				if elem, exists := current[key]; exists {
+					current[key] = maskSensitiveValues(elem, mark)
+				}
+			}
+		}
+	case []interface{}:
+		if current, ok := value.([]interface{}); ok {
+			for i := 0; i < len(marks) && i < len(current); i++ {
+				current[i] = maskSensitiveValues(current[i], marks[i])
+			}
+		}
+	}
+	return value
+}
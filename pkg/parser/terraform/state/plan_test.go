@@ -0,0 +1,51 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/parser/terraform"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParsePlan tests the functions [IsPlan(), ParsePlan()] and all the methods called by them
+func TestParsePlan(t *testing.T) {
+	content, err := os.ReadFile(filepath.FromSlash("../../../../test/fixtures/test_terraform_plan/plan.json"))
+	require.NoError(t, err)
+
+	docs, err := ParsePlan(content)
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+
+	resources := docs[0]["resource"].(model.Document)
+	bucket := resources["aws_s3_bucket"].(model.Document)["b"].(model.Document)
+	require.Equal(t, "public-read", bucket["acl"])
+	require.Equal(t, map[string]interface{}{"Environment": "dev"}, bucket["tags"])
+	require.Equal(t, model.Document{"name": "aws"}, bucket[terraform.ProviderContextKey])
+	require.NotContains(t, docs[0], model.ModuleCallChainKey)
+	require.NotContains(t, resources, "aws_db_instance")
+
+	// the resources with the same name in different modules are kept in the documents of their modules
+	db := docs[1]["resource"].(model.Document)["aws_db_instance"].(model.Document)["db[0]"].(model.Document)
+	require.Equal(t, []string{"root", "module.db"}, docs[1][model.ModuleCallChainKey])
+	require.Equal(t, false, db["storage_encrypted"])
+	require.Equal(t, MaskedValue, db["master_user"])
+	require.Equal(t, MaskedValue, db["tags"].(map[string]interface{})["owner"])
+	replica := docs[2]["resource"].(model.Document)["aws_db_instance"].(model.Document)["db[0]"].(model.Document)
+	require.Equal(t, []string{"root", "module.replica"}, docs[2][model.ModuleCallChainKey])
+	require.Equal(t, true, replica["storage_encrypted"])
+
+	nested, err := ParsePlan([]byte(`{"planned_values": {"root_module": {"child_modules": [{"address": "module.vpc",
+		"child_modules": [{"address": "module.vpc.module.subnets", "resources": [{"type": "aws_subnet", "name": "a"}]}]}]}}}`))
+	require.NoError(t, err)
+	require.Len(t, nested, 2)
+	require.Equal(t, []string{"root", "module.vpc", "module.subnets"}, nested[1][model.ModuleCallChainKey])
+
+	require.True(t, IsPlan(model.Document{"format_version": "1.1", "terraform_version": "1.3.0", "planned_values": nil}))
+	require.False(t, IsPlan(model.Document{"format_version": "1.0", "resources": []interface{}{}}))
+
+	_, err = ParsePlan([]byte(`{"planned_values": []}`))
+	require.Error(t, err)
+}
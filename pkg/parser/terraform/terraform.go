@@ -3,9 +3,11 @@ package terraform
 import (
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/Checkmarx/kics/pkg/model"
 	"github.com/Checkmarx/kics/pkg/parser/terraform/converter"
+	"github.com/Checkmarx/kics/pkg/parser/terraform/terragrunt"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/pkg/errors"
@@ -15,19 +17,35 @@ import (
 const RetriesDefaultValue = 50
 
 // Converter returns content json, error line, error
-type Converter func(file *hcl.File) (model.Document, int, error)
+type Converter func(file *hcl.File, inputVariables converter.VariableMap) (model.Document, int, error)
 
 // Parser struct that contains the function to parse file and the number of retries if something goes wrong
+// varFiles and workspace are used to resolve the input variables of each module
 type Parser struct {
 	convertFunc  Converter
 	numOfRetries int
+	varFiles     []string
+	workspace    string
+	modulesCache map[string]*moduleContext
+	// modulesManifests are the modules manifests read, by root directory
+	modulesManifests map[string]*modulesManifestContent
+	mutex            sync.Mutex
 }
 
 // NewDefault initializes a parser with Parser default values
 func NewDefault() *Parser {
+	return NewDefaultWithVariables(nil, "")
+}
+
+// NewDefaultWithVariables initializes a parser with Parser default values, using the var files (in increasing order
+// of precedence) and the workspace to resolve input variables, the workspace values are read from <workspace>.tfvars
+func NewDefaultWithVariables(varFiles []string, workspace string) *Parser {
 	return &Parser{
 		numOfRetries: RetriesDefaultValue,
 		convertFunc:  converter.DefaultConverted,
+		varFiles:     varFiles,
+		workspace:    workspace,
+		modulesCache: make(map[string]*moduleContext),
 	}
 }
 
@@ -39,8 +57,9 @@ func (p *Parser) Parse(path string, content []byte) ([]model.Document, error) {
 		parseErr  error
 	)
 
+	module := p.getModuleContext(filepath.Dir(path))
 	for try := 0; try < p.numOfRetries; try++ {
-		fc, lineOfErr, parseErr = p.doParse(content, filepath.Base(path))
+		fc, lineOfErr, parseErr = p.doParse(content, filepath.Base(path), module.inputVariables)
 		if parseErr != nil && lineOfErr != 0 {
 			content = p.removeProblematicLine(content, lineOfErr)
 			continue
@@ -49,12 +68,21 @@ func (p *Parser) Parse(path string, content []byte) ([]model.Document, error) {
 		break
 	}
 
+	if parseErr == nil {
+		normalizeSettings(fc)
+		addProviderContext(fc, module.providers)
+		if len(module.callChain) > 0 {
+			fc[model.ModuleCallChainKey] = module.callChain
+		}
+	}
+
 	return []model.Document{fc}, errors.Wrap(parseErr, "failed terraform parse")
 }
 
-// SupportedExtensions returns Terraform extensions
+// SupportedExtensions returns Terraform extensions, along with the name of the Terragrunt configurations, whose modules
+// are parsed with the inputs of the configuration once resolved
 func (p *Parser) SupportedExtensions() []string {
-	return []string{".tf"}
+	return []string{".tf", terragrunt.ConfigFile}
 }
 
 // SupportedTypes returns types supported by this parser, which are terraform
@@ -76,7 +104,7 @@ func (p *Parser) removeProblematicLine(content []byte, line int) []byte {
 	return content
 }
 
-func (p *Parser) doParse(content []byte, fileName string) (json model.Document, errLine int, err error) {
+func (p *Parser) doParse(content []byte, fileName string, inputVariables converter.VariableMap) (json model.Document, errLine int, err error) {
 	file, diagnostics := hclsyntax.ParseConfig(content, fileName, hcl.Pos{Byte: 0, Line: 1, Column: 1})
 
 	if diagnostics != nil && diagnostics.HasErrors() && len(diagnostics.Errs()) > 0 {
@@ -90,5 +118,5 @@ func (p *Parser) doParse(content []byte, fileName string) (json model.Document,
 		return nil, line, errors.Wrap(err, "failed to parse file")
 	}
 
-	return p.convertFunc(file)
+	return p.convertFunc(file, inputVariables)
 }
@@ -1,6 +1,9 @@
 package terraform
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/Checkmarx/kics/pkg/model"
@@ -36,7 +39,7 @@ func TestParser_SupportedTypes(t *testing.T) {
 // TestParser_SupportedExtensions tests the functions [SupportedExtensions()] and all the methods called by them
 func TestParser_SupportedExtensions(t *testing.T) {
 	p := &Parser{}
-	require.Equal(t, []string{".tf"}, p.SupportedExtensions())
+	require.Equal(t, []string{".tf", "terragrunt.hcl"}, p.SupportedExtensions())
 }
 
 // Test_Parser tests the functions [Parser()] and all the methods called by them
@@ -49,3 +52,182 @@ func Test_Parser(t *testing.T) {
 	require.Contains(t, document[0], "resource")
 	require.Contains(t, document[0]["resource"], "aws_s3_bucket")
 }
+
+// TestParser_InputVariables tests the functions [Parse()] resolving input variables with their precedence
+func TestParser_InputVariables(t *testing.T) {
+	dir := filepath.FromSlash("../../../test/fixtures/test_terraform_variables")
+	content, err := os.ReadFile(filepath.Join(dir, "main.tf"))
+	require.NoError(t, err)
+
+	require.NoError(t, os.Setenv("TF_VAR_environment", "staging"))
+	require.NoError(t, os.Setenv("TF_VAR_bucket", "env-bucket"))
+	defer func() {
+		require.NoError(t, os.Unsetenv("TF_VAR_environment"))
+		require.NoError(t, os.Unsetenv("TF_VAR_bucket"))
+	}()
+
+	tests := []struct {
+		name      string
+		parser    *Parser
+		want      map[string]interface{}
+		wantTagID string
+	}{
+		{
+			name:   "tfvars_precedence",
+			parser: NewDefault(),
+			want: map[string]interface{}{
+				"acl":    "public-read",
+				"bucket": "auto-bucket",
+				"region": "us-east-1",
+			},
+			wantTagID: "auto-bucket-${terraform.workspace}",
+		},
+		{
+			name:   "workspace_and_var_files",
+			parser: NewDefaultWithVariables([]string{filepath.Join(dir, "vars", "override.tfvars")}, "prod"),
+			want: map[string]interface{}{
+				"acl":    "public-read-write",
+				"bucket": "auto-bucket",
+				"region": "eu-west-1",
+			},
+			wantTagID: "auto-bucket-prod",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := tt.parser.Parse(filepath.Join(dir, "main.tf"), content)
+			require.NoError(t, err)
+			require.Len(t, docs, 1)
+
+			j, err := json.Marshal(docs[0])
+			require.NoError(t, err)
+			var doc map[string]interface{}
+			require.NoError(t, json.Unmarshal(j, &doc))
+
+			bucket := doc["resource"].(map[string]interface{})["aws_s3_bucket"].(map[string]interface{})["b"].(map[string]interface{})
+			for key, value := range tt.want {
+				require.Equal(t, value, bucket[key])
+			}
+			require.Equal(t, false, bucket["versioning"].(map[string]interface{})["enabled"])
+			tags := bucket["tags"].(map[string]interface{})
+			require.Equal(t, tt.wantTagID, tags["Name"])
+			require.Equal(t, "staging", tags["Environment"])
+		})
+	}
+}
+
+// TestParser_ProviderContext tests the functions [Parse()] adding the provider configuration used by each resource
+func TestParser_ProviderContext(t *testing.T) {
+	dir := filepath.FromSlash("../../../test/fixtures/test_terraform_providers")
+	content, err := os.ReadFile(filepath.Join(dir, "main.tf"))
+	require.NoError(t, err)
+
+	docs, err := NewDefault().Parse(filepath.Join(dir, "main.tf"), content)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+
+	getProvider := func(blockType, resourceType, name string) model.Document {
+		resource := docs[0][blockType].(model.Document)[resourceType].(model.Document)[name].(model.Document)
+		return resource[ProviderContextKey].(model.Document)
+	}
+
+	require.Equal(t, model.Document{"name": "aws", "region": "eu-west-1"}, getProvider("resource", "aws_s3_bucket", "default"))
+	require.Equal(t, model.Document{"name": "aws", "alias": "east", "region": "us-east-1"}, getProvider("resource", "aws_s3_bucket", "east"))
+	require.Equal(t, model.Document{"name": "google"}, getProvider("resource", "google_storage_bucket", "gcs"))
+	require.Equal(t, model.Document{"name": "aws", "alias": "east", "region": "us-east-1"}, getProvider("data", "aws_ami", "ubuntu"))
+}
+
+// TestParser_ModuleCallChain tests the functions [Parse()] adding the module call chain of installed modules
+func TestParser_ModuleCallChain(t *testing.T) {
+	dir := filepath.FromSlash("../../../test/fixtures/test_terraform_modules")
+	tests := []struct {
+		name string
+		file string
+		want []string
+	}{
+		{
+			name: "root_module",
+			file: "main.tf",
+			want: nil,
+		},
+		{
+			name: "registry_module",
+			file: ".terraform/modules/vpc/main.tf",
+			want: []string{"root", "module.vpc"},
+		},
+		{
+			name: "nested_module",
+			file: ".terraform/modules/vpc/modules/subnets/main.tf",
+			want: []string{"root", "module.vpc", "module.subnets"},
+		},
+	}
+
+	parser := NewDefault()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, filepath.FromSlash(tt.file))
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+
+			docs, err := parser.Parse(path, content)
+			require.NoError(t, err)
+			require.Len(t, docs, 1)
+			if tt.want == nil {
+				require.NotContains(t, docs[0], model.ModuleCallChainKey)
+				return
+			}
+			require.Equal(t, tt.want, docs[0][model.ModuleCallChainKey])
+		})
+	}
+	// the manifest of the root module is read once for all its modules
+	root, err := filepath.Abs(dir)
+	require.NoError(t, err)
+	require.NotNil(t, parser.modulesManifests[root])
+}
+
+// TestParser_Settings tests the functions [Parse()] merging the terraform settings blocks of a file
+func TestParser_Settings(t *testing.T) {
+	path := filepath.FromSlash("../../../test/fixtures/test_terraform_settings/versions.tf")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	docs, err := NewDefault().Parse(path, content)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+
+	j, err := json.Marshal(docs[0])
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(j, &doc))
+
+	settings := doc["terraform"].(map[string]interface{})
+	require.Equal(t, ">= 1.0", settings["required_version"])
+	require.Equal(t, map[string]interface{}{
+		"aws":    map[string]interface{}{"source": "hashicorp/aws", "version": "~> 4.0"},
+		"random": map[string]interface{}{"version": "~> 3.1"},
+	}, settings["required_providers"])
+	backend := settings["backend"].(map[string]interface{})["s3"].(map[string]interface{})
+	require.Equal(t, true, backend["encrypt"])
+}
+
+// TestParser_Terragrunt tests the functions [Parse()] for the modules deployed by Terragrunt configurations,
+// parsed with the inputs of the configurations
+func TestParser_Terragrunt(t *testing.T) {
+	dir := filepath.FromSlash("../../../test/fixtures/test_terragrunt")
+	content, err := os.ReadFile(filepath.Join(dir, "modules", "bucket", "main.tf"))
+	require.NoError(t, err)
+
+	docs, err := NewDefault().Parse(filepath.Join(dir, "live", "app", "terragrunt.hcl"), content)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+
+	j, err := json.Marshal(docs[0])
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(j, &doc))
+
+	bucket := doc["resource"].(map[string]interface{})["aws_s3_bucket"].(map[string]interface{})["this"].(map[string]interface{})
+	require.Equal(t, "dev-bucket", bucket["bucket"])
+	require.Equal(t, "public-read", bucket["acl"])
+	require.Equal(t, map[string]interface{}{"vpc": "vpc-123"}, bucket["tags"])
+}
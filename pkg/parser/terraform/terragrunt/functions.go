@@ -0,0 +1,159 @@
+package terragrunt
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/pkg/errors"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// evaluation is the evaluation of a configuration file, path, for the configuration original, which includes it
+// or is the file itself, included is the first configuration included by the file, if any
+type evaluation struct {
+	path     string
+	original string
+	included string
+}
+
+// context returns the context of the expressions of the configuration, with the functions of Terragrunt supported
+func (e *evaluation) context() *hcl.EvalContext {
+	return &hcl.EvalContext{
+		Variables: make(map[string]cty.Value),
+		Functions: map[string]function.Function{
+			"find_in_parent_folders":      newStringFunction(e.findInParentFolders),
+			"get_terragrunt_dir":          newStringFunction(e.getTerragruntDir),
+			"get_original_terragrunt_dir": newStringFunction(e.getTerragruntDir),
+			"get_parent_terragrunt_dir":   newStringFunction(e.getParentTerragruntDir),
+			"path_relative_to_include":    newStringFunction(e.pathRelativeToInclude),
+			"path_relative_from_include":  newStringFunction(e.pathRelativeFromInclude),
+			"get_env":                     newStringFunction(getEnv),
+			"merge":                       mergeFunction,
+		},
+	}
+}
+
+// findInParentFolders returns the closest file with the name, terragrunt.hcl by default, in the parent directories
+// of the configuration, or the fallback, when given, if there is none
+func (e *evaluation) findInParentFolders(args []string) (string, error) {
+	name := ConfigFile
+	if len(args) > 0 && args[0] != "" {
+		name = args[0]
+	}
+	dir, err := filepath.Abs(filepath.Dir(e.original))
+	if err != nil {
+		return "", err
+	}
+	for dir != filepath.Dir(dir) {
+		dir = filepath.Dir(dir)
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	if len(args) > 1 {
+		return args[1], nil
+	}
+	return "", errors.Errorf("%s not found in the parent folders of %s", name, e.original)
+}
+
+// getTerragruntDir returns the directory of the configuration, also in the configurations it includes
+func (e *evaluation) getTerragruntDir(args []string) (string, error) {
+	return filepath.Abs(filepath.Dir(e.original))
+}
+
+// getParentTerragruntDir returns the directory of the configuration included, or of the configuration itself
+func (e *evaluation) getParentTerragruntDir(args []string) (string, error) {
+	if e.path != e.original {
+		return filepath.Abs(filepath.Dir(e.path))
+	}
+	if e.included != "" {
+		return filepath.Abs(filepath.Dir(e.included))
+	}
+	return filepath.Abs(filepath.Dir(e.original))
+}
+
+// pathRelativeToInclude returns the path of the directory of the configuration relative to the configuration included
+func (e *evaluation) pathRelativeToInclude(args []string) (string, error) {
+	included := e.includedPath()
+	if included == "" {
+		return ".", nil
+	}
+	rel, err := filepath.Rel(filepath.Dir(included), filepath.Dir(e.original))
+	return filepath.ToSlash(rel), err
+}
+
+// pathRelativeFromInclude returns the path of the directory of the configuration included relative to the configuration
+func (e *evaluation) pathRelativeFromInclude(args []string) (string, error) {
+	included := e.includedPath()
+	if included == "" {
+		return ".", nil
+	}
+	rel, err := filepath.Rel(filepath.Dir(e.original), filepath.Dir(included))
+	return filepath.ToSlash(rel), err
+}
+
+// includedPath returns the configuration included, the file evaluated when it is included by another configuration
+func (e *evaluation) includedPath() string {
+	if e.path != e.original {
+		return e.path
+	}
+	return e.included
+}
+
+// getEnv returns the value of the environment variable or the default value, when given, if it is not set
+func getEnv(args []string) (string, error) {
+	if len(args) == 0 {
+		return "", errors.New("get_env requires the name of the environment variable")
+	}
+	if value, ok := os.LookupEnv(args[0]); ok {
+		return value, nil
+	}
+	if len(args) > 1 {
+		return args[1], nil
+	}
+	return "", nil
+}
+
+// newStringFunction returns a function of strings returning a string
+func newStringFunction(impl func(args []string) (string, error)) function.Function {
+	return function.New(&function.Spec{
+		VarParam: &function.Parameter{Name: "args", Type: cty.String},
+		Type:     function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			values := make([]string, 0, len(args))
+			for _, arg := range args {
+				values = append(values, arg.AsString())
+			}
+			value, err := impl(values)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return cty.StringVal(value), nil
+		},
+	})
+}
+
+// mergeFunction merges maps and objects, the keys of the later arguments override the earlier ones
+var mergeFunction = function.New(&function.Spec{
+	VarParam: &function.Parameter{Name: "maps", Type: cty.DynamicPseudoType, AllowNull: true},
+	Type:     function.StaticReturnType(cty.DynamicPseudoType),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		attributes := make(map[string]cty.Value)
+		for _, arg := range args {
+			if arg.IsNull() {
+				continue
+			}
+			if !arg.Type().IsObjectType() && !arg.Type().IsMapType() {
+				return cty.NilVal, errors.New("merge arguments must be maps or objects")
+			}
+			for it := arg.ElementIterator(); it.Next(); {
+				key, value := it.Element()
+				attributes[key.AsString()] = value
+			}
+		}
+		return cty.ObjectVal(attributes), nil
+	},
+})
@@ -0,0 +1,278 @@
+package terragrunt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ConfigFile is the name of the Terragrunt configurations
+const ConfigFile = "terragrunt.hcl"
+
+// registryPrefix prefixes the sources of the modules of Terraform registries (ex: tfr:///terraform-aws-modules/vpc/aws)
+const registryPrefix = "tfr://"
+
+// Config is a Terragrunt configuration evaluated, merged with the configurations it includes
+// Path is the configuration file, Source is the source of the Terraform module it deploys, if any, Inputs are the values
+// of the input variables of the module and Dependencies are the configurations whose outputs it reads
+type Config struct {
+	Path         string
+	Source       string
+	Inputs       map[string]cty.Value
+	Dependencies []Dependency
+}
+
+// Dependency is a dependency block of a Terragrunt configuration, the outputs of the dependencies are not read
+// from their state, their mock outputs are used instead and the inputs reading outputs without mock are ignored
+type Dependency struct {
+	Name       string
+	ConfigPath string
+}
+
+// ConfigPath returns the Terragrunt configuration of the directory, empty when there is none
+func ConfigPath(dir string) string {
+	path := filepath.Join(dir, ConfigFile)
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return ""
+	}
+	return path
+}
+
+// Load evaluates the Terragrunt configuration of the file, along with the configurations it includes, the inputs that
+// can't be evaluated, such as the ones using functions not supported, are ignored
+func Load(path string) (*Config, error) {
+	return load(path, path, make(map[string]bool))
+}
+
+// LocalSourceDir returns the directory of the Terraform module of the configuration when its source is local,
+// empty otherwise, relative sources are relative to the directory of the configuration
+func (c *Config) LocalSourceDir() string {
+	if c.Source == "" || strings.Contains(c.Source, "::") || strings.Contains(c.Source, "://") {
+		return ""
+	}
+	source := filepath.FromSlash(c.Source)
+	if filepath.IsAbs(source) {
+		return filepath.Clean(source)
+	}
+	if !strings.HasPrefix(c.Source, "./") && !strings.HasPrefix(c.Source, "../") {
+		return ""
+	}
+	// the subdirectory of the module after // is a path inside the source
+	return filepath.Join(filepath.Dir(c.Path), source)
+}
+
+// RegistrySource returns the address and the version of the module of the configuration when it is a module
+// of a Terraform registry (ex: tfr:///terraform-aws-modules/vpc/aws?version=3.0.0)
+func (c *Config) RegistrySource() (address, version string, ok bool) {
+	if !strings.HasPrefix(c.Source, registryPrefix) {
+		return "", "", false
+	}
+	address = strings.TrimPrefix(c.Source, registryPrefix)
+	if i := strings.Index(address, "?"); i >= 0 {
+		for _, parameter := range strings.Split(address[i+1:], "&") {
+			if strings.HasPrefix(parameter, "version=") {
+				version = strings.TrimPrefix(parameter, "version=")
+			}
+		}
+		address = address[:i]
+	}
+	// the registry host is empty for the public registry (ex: tfr:///namespace/name/provider)
+	return strings.TrimPrefix(address, "/"), version, true
+}
+
+// load evaluates the configuration of the file path for the configuration original, which includes it,
+// or is the file itself, the functions of the configurations included are evaluated for the configuration including them
+func load(path, original string, visiting map[string]bool) (*Config, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if visiting[absPath] {
+		return nil, errors.Errorf("cyclic include of Terragrunt configuration %s", path)
+	}
+	visiting[absPath] = true
+	defer delete(visiting, absPath)
+
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	file, diagnostics := hclsyntax.ParseConfig(content, filepath.Base(path), hcl.Pos{Byte: 0, Line: 1, Column: 1})
+	if diagnostics != nil && diagnostics.HasErrors() {
+		return nil, errors.Wrapf(diagnostics, "failed to parse Terragrunt configuration %s", path)
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, errors.Errorf("unexpected body of Terragrunt configuration %s", path)
+	}
+
+	e := &evaluation{path: path, original: original}
+	config := &Config{Path: original, Inputs: make(map[string]cty.Value)}
+	for _, block := range getBlocks(body, "include") {
+		included, err := e.include(block, visiting)
+		if err != nil {
+			return nil, err
+		}
+		config.merge(included)
+	}
+
+	ctx := e.context()
+	evalLocals(getBlocks(body, "locals"), ctx)
+	own := &Config{Path: original, Inputs: make(map[string]cty.Value)}
+	own.Dependencies = evalDependencies(getBlocks(body, "dependency"), ctx)
+	for _, block := range getBlocks(body, "terraform") {
+		if attribute, ok := block.Body.Attributes["source"]; ok {
+			own.Source = evalString(attribute.Expr, ctx)
+		}
+	}
+	if attribute, ok := body.Attributes["inputs"]; ok {
+		own.Inputs = evalInputs(attribute.Expr, ctx)
+	}
+	config.merge(own)
+	return config, nil
+}
+
+// merge overrides the configuration with the values of the other configuration, the inputs are merged
+func (c *Config) merge(other *Config) {
+	if other.Source != "" {
+		c.Source = other.Source
+	}
+	for name, value := range other.Inputs {
+		c.Inputs[name] = value
+	}
+	c.Dependencies = append(c.Dependencies, other.Dependencies...)
+}
+
+// include loads the configuration included by the include block, for the configuration of the evaluation
+func (e *evaluation) include(block *hclsyntax.Block, visiting map[string]bool) (*Config, error) {
+	attribute, ok := block.Body.Attributes["path"]
+	if !ok {
+		return nil, errors.Errorf("include without path in Terragrunt configuration %s", e.path)
+	}
+	includePath := evalString(attribute.Expr, e.context())
+	if includePath == "" {
+		return nil, errors.Errorf("failed to evaluate the path of the include of Terragrunt configuration %s", e.path)
+	}
+	if !filepath.IsAbs(includePath) {
+		includePath = filepath.Join(filepath.Dir(e.path), filepath.FromSlash(includePath))
+	}
+	// the functions of the configurations evaluated after the include are relative to the first configuration included
+	if e.included == "" {
+		e.included = includePath
+	}
+	return load(includePath, e.original, visiting)
+}
+
+// evalLocals adds the locals to the context, the locals can reference each other in any order
+func evalLocals(blocks []*hclsyntax.Block, ctx *hcl.EvalContext) {
+	pending := make(map[string]hclsyntax.Expression)
+	for _, block := range blocks {
+		for name, attribute := range block.Body.Attributes {
+			pending[name] = attribute.Expr
+		}
+	}
+	locals := make(map[string]cty.Value)
+	for len(pending) > 0 {
+		ctx.Variables["local"] = cty.ObjectVal(locals)
+		evaluated := false
+		for name, expr := range pending {
+			value, diagnostics := expr.Value(ctx)
+			if diagnostics.HasErrors() {
+				continue
+			}
+			locals[name] = value
+			delete(pending, name)
+			evaluated = true
+		}
+		if !evaluated {
+			for name := range pending {
+				log.Debug().Msgf("terragrunt.evalLocals() ignoring local %s that can't be evaluated", name)
+			}
+			break
+		}
+	}
+	ctx.Variables["local"] = cty.ObjectVal(locals)
+}
+
+// evalDependencies adds the mock outputs of the dependencies to the context, the outputs of the dependencies
+// without mock outputs are unknown
+func evalDependencies(blocks []*hclsyntax.Block, ctx *hcl.EvalContext) []Dependency {
+	dependencies := make([]Dependency, 0, len(blocks))
+	values := make(map[string]cty.Value)
+	for _, block := range blocks {
+		if len(block.Labels) != 1 {
+			continue
+		}
+		dependency := Dependency{Name: block.Labels[0]}
+		if attribute, ok := block.Body.Attributes["config_path"]; ok {
+			dependency.ConfigPath = evalString(attribute.Expr, ctx)
+		}
+		outputs := cty.DynamicVal
+		if attribute, ok := block.Body.Attributes["mock_outputs"]; ok {
+			if value, diagnostics := attribute.Expr.Value(ctx); !diagnostics.HasErrors() {
+				outputs = value
+			}
+		}
+		values[dependency.Name] = cty.ObjectVal(map[string]cty.Value{"outputs": outputs})
+		dependencies = append(dependencies, dependency)
+	}
+	ctx.Variables["dependency"] = cty.ObjectVal(values)
+	return dependencies
+}
+
+// evalInputs returns the inputs that can be evaluated, the items of the inputs are evaluated one by one,
+// so an input that can't be evaluated does not discard the others
+func evalInputs(expr hclsyntax.Expression, ctx *hcl.EvalContext) map[string]cty.Value {
+	inputs := make(map[string]cty.Value)
+	object, ok := expr.(*hclsyntax.ObjectConsExpr)
+	if !ok {
+		value, diagnostics := expr.Value(ctx)
+		if diagnostics.HasErrors() || !value.IsWhollyKnown() || value.IsNull() ||
+			!(value.Type().IsObjectType() || value.Type().IsMapType()) {
+			log.Debug().Msg("terragrunt.evalInputs() ignoring inputs that can't be evaluated")
+			return inputs
+		}
+		for it := value.ElementIterator(); it.Next(); {
+			key, element := it.Element()
+			inputs[key.AsString()] = element
+		}
+		return inputs
+	}
+	for _, item := range object.Items {
+		key, diagnostics := item.KeyExpr.Value(ctx)
+		if diagnostics.HasErrors() || !key.IsKnown() || key.IsNull() || key.Type() != cty.String {
+			continue
+		}
+		value, diagnostics := item.ValueExpr.Value(ctx)
+		if diagnostics.HasErrors() || !value.IsWhollyKnown() {
+			log.Debug().Msgf("terragrunt.evalInputs() ignoring input %s that can't be evaluated", key.AsString())
+			continue
+		}
+		inputs[key.AsString()] = value
+	}
+	return inputs
+}
+
+func getBlocks(body *hclsyntax.Body, blockType string) []*hclsyntax.Block {
+	blocks := make([]*hclsyntax.Block, 0)
+	for _, block := range body.Blocks {
+		if block.Type == blockType {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+func evalString(expr hcl.Expression, ctx *hcl.EvalContext) string {
+	value, diagnostics := expr.Value(ctx)
+	if diagnostics.HasErrors() || value.IsNull() || !value.IsKnown() || value.Type() != cty.String {
+		return ""
+	}
+	return value.AsString()
+}
@@ -0,0 +1,91 @@
+package terragrunt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var fixturesDir = filepath.FromSlash("../../../../test/fixtures/test_terragrunt")
+
+// TestLoad tests the functions [Load()] and all the methods called by them
+func TestLoad(t *testing.T) {
+	config, err := Load(filepath.Join(fixturesDir, "live", "app", ConfigFile))
+	require.NoError(t, err)
+
+	require.Equal(t, "../../modules//bucket", config.Source)
+	require.Equal(t, filepath.Join(fixturesDir, "modules", "bucket"), config.LocalSourceDir())
+	_, _, ok := config.RegistrySource()
+	require.False(t, ok)
+
+	// the inputs of the configuration included are merged, its functions are evaluated for the configuration including it,
+	// the input reading the outputs of a dependency without mock outputs is ignored
+	require.Equal(t, map[string]string{
+		"region":    "eu-west-1",
+		"state_key": "live/app/terraform.tfstate",
+		"acl":       "public-read",
+		"name":      "dev-bucket",
+		"vpc_id":    "vpc-123",
+		"owner":     "platform",
+	}, getStrings(config.Inputs))
+	require.Equal(t, []Dependency{
+		{Name: "vpc", ConfigPath: "../vpc"},
+		{Name: "db", ConfigPath: "../db"},
+	}, config.Dependencies)
+}
+
+// TestLoad_Registry tests the functions [Load()] for configurations deploying modules of Terraform registries
+func TestLoad_Registry(t *testing.T) {
+	config, err := Load(filepath.Join(fixturesDir, "live", "vpc", ConfigFile))
+	require.NoError(t, err)
+
+	require.Empty(t, config.LocalSourceDir())
+	address, version, ok := config.RegistrySource()
+	require.True(t, ok)
+	require.Equal(t, "terraform-aws-modules/vpc/aws", address)
+	require.Equal(t, "3.0.0", version)
+
+	root, err := Load(filepath.Join(fixturesDir, ConfigFile))
+	require.NoError(t, err)
+	require.Empty(t, root.Source)
+	require.Equal(t, "./terraform.tfstate", root.Inputs["state_key"].AsString())
+}
+
+// TestLoad_Errors tests the functions [Load()] for configurations that can't be evaluated
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ConfigFile)
+
+	require.NoError(t, os.WriteFile(path, []byte("include {\n  path = \"terragrunt.hcl\"\n}\n"), 0600))
+	_, err := Load(path)
+	require.Error(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("inputs = {\n"), 0600))
+	_, err = Load(path)
+	require.Error(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("inputs = merge({ a = \"1\" }, { a = \"2\", b = \"3\" })\n"), 0600))
+	config, err := Load(path)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"a": "2", "b": "3"}, getStrings(config.Inputs))
+
+	_, err = Load(filepath.Join(dir, "missing", ConfigFile))
+	require.Error(t, err)
+}
+
+// TestConfigPath tests the functions [ConfigPath()] and all the methods called by them
+func TestConfigPath(t *testing.T) {
+	require.Equal(t, filepath.Join(fixturesDir, ConfigFile), ConfigPath(fixturesDir))
+	require.Empty(t, ConfigPath(filepath.Join(fixturesDir, "modules", "bucket")))
+}
+
+func getStrings(values map[string]cty.Value) map[string]string {
+	strings := make(map[string]string, len(values))
+	for name, value := range values {
+		strings[name] = value.AsString()
+	}
+	return strings
+}
@@ -0,0 +1,210 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/parser/terraform/converter"
+	"github.com/Checkmarx/kics/pkg/parser/terraform/terragrunt"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/rs/zerolog/log"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const envVariablePrefix = "TF_VAR_"
+
+// moduleContext is the information shared by all the files of a module
+type moduleContext struct {
+	inputVariables converter.VariableMap
+	providers      map[string]providerConfig
+	callChain      []string
+}
+
+// getModuleContext returns the input variables, providers and call chain of the module in dir, caching them by directory
+func (p *Parser) getModuleContext(dir string) *moduleContext {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.modulesCache == nil {
+		p.modulesCache = make(map[string]*moduleContext)
+	}
+	if module, ok := p.modulesCache[dir]; ok {
+		return module
+	}
+	inputVariables := p.loadInputVariables(dir)
+	module := &moduleContext{
+		inputVariables: inputVariables,
+		providers:      getProviders(dir, inputVariables),
+		callChain:      p.getModuleCallChain(dir),
+	}
+	p.modulesCache[dir] = module
+	return module
+}
+
+// loadInputVariables resolves the input variables of a module following Terraform precedence, where later sources
+// override earlier ones: variable defaults, the inputs of the Terragrunt configuration of the directory, which Terragrunt
+// passes as environment variables, TF_VAR_ environment variables, terraform.tfvars, terraform.tfvars.json,
+// *.auto.tfvars and *.auto.tfvars.json in lexical order, the workspace tfvars and the var files passed by flag
+func (p *Parser) loadInputVariables(dir string) converter.VariableMap {
+	values := getVariableDefaults(dir)
+	for name, value := range getTerragruntVariables(dir) {
+		values[name] = value
+	}
+	for name, value := range getEnvVariables() {
+		values[name] = value
+	}
+
+	varFiles := []string{
+		filepath.Join(dir, "terraform.tfvars"),
+		filepath.Join(dir, "terraform.tfvars.json"),
+	}
+	autoVarFiles, _ := filepath.Glob(filepath.Join(dir, "*.auto.tfvars"))
+	autoJSONVarFiles, _ := filepath.Glob(filepath.Join(dir, "*.auto.tfvars.json"))
+	autoVarFiles = append(autoVarFiles, autoJSONVarFiles...)
+	sort.Strings(autoVarFiles)
+	varFiles = append(varFiles, autoVarFiles...)
+	if p.workspace != "" {
+		varFiles = append(varFiles,
+			filepath.Join(dir, p.workspace+".tfvars"),
+			filepath.Join(dir, p.workspace+".tfvars.json"))
+	}
+	varFiles = append(varFiles, p.varFiles...)
+
+	for _, varFile := range varFiles {
+		for name, value := range readVarFile(varFile) {
+			values[name] = value
+		}
+	}
+
+	inputVariables := make(converter.VariableMap)
+	if len(values) > 0 {
+		inputVariables["var"] = cty.ObjectVal(values)
+	}
+	if p.workspace != "" {
+		inputVariables["terraform"] = cty.ObjectVal(map[string]cty.Value{
+			"workspace": cty.StringVal(p.workspace),
+		})
+	}
+	return inputVariables
+}
+
+// getModuleBlocks returns the top level blocks of the given type declared in the terraform files of dir
+func getModuleBlocks(dir, blockType string) []*hclsyntax.Block {
+	blocks := make([]*hclsyntax.Block, 0)
+	files, _ := filepath.Glob(filepath.Join(dir, "*.tf"))
+	for _, path := range files {
+		content, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			continue
+		}
+		file, diagnostics := hclsyntax.ParseConfig(content, filepath.Base(path), hcl.Pos{Byte: 0, Line: 1, Column: 1})
+		if diagnostics.HasErrors() {
+			continue
+		}
+		body, ok := file.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		for _, block := range body.Blocks {
+			if block.Type == blockType {
+				blocks = append(blocks, block)
+			}
+		}
+	}
+	return blocks
+}
+
+// getVariableDefaults returns the default values of the variables declared in the terraform files of dir
+func getVariableDefaults(dir string) map[string]cty.Value {
+	defaults := make(map[string]cty.Value)
+	for _, block := range getModuleBlocks(dir, "variable") {
+		if len(block.Labels) != 1 {
+			continue
+		}
+		attribute, ok := block.Body.Attributes["default"]
+		if !ok {
+			continue
+		}
+		if value, diagnostics := attribute.Expr.Value(nil); !diagnostics.HasErrors() {
+			defaults[block.Labels[0]] = value
+		}
+	}
+	return defaults
+}
+
+// getTerragruntVariables returns the variables set by the Terragrunt configuration of the directory, if any: the defaults
+// of the variables of its module, when it is local, overridden by its inputs, the directories with a Terragrunt
+// configuration are also the directories of the modules it deploys once resolved
+func getTerragruntVariables(dir string) map[string]cty.Value {
+	values := make(map[string]cty.Value)
+	path := terragrunt.ConfigPath(dir)
+	if path == "" {
+		return values
+	}
+	config, err := terragrunt.Load(path)
+	if err != nil {
+		log.Warn().Msgf("Failed to evaluate Terragrunt configuration %s: %s", path, err)
+		return values
+	}
+	if moduleDir := config.LocalSourceDir(); moduleDir != "" {
+		values = getVariableDefaults(moduleDir)
+	}
+	for name, value := range config.Inputs {
+		values[name] = value
+	}
+	return values
+}
+
+// getEnvVariables returns the values of variables set through TF_VAR_ environment variables
+func getEnvVariables() map[string]cty.Value {
+	values := make(map[string]cty.Value)
+	for _, env := range os.Environ() {
+		if !strings.HasPrefix(env, envVariablePrefix) {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimPrefix(env, envVariablePrefix), "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			continue
+		}
+		values[parts[0]] = cty.StringVal(parts[1])
+	}
+	return values
+}
+
+// readVarFile returns the values of a tfvars file, in HCL or JSON format, or nothing if the file does not exist
+func readVarFile(path string) map[string]cty.Value {
+	values := make(map[string]cty.Value)
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return values
+	}
+
+	var (
+		file        *hcl.File
+		diagnostics hcl.Diagnostics
+	)
+	if filepath.Ext(path) == ".json" {
+		file, diagnostics = hcljson.Parse(content, path)
+	} else {
+		file, diagnostics = hclsyntax.ParseConfig(content, path, hcl.Pos{Byte: 0, Line: 1, Column: 1})
+	}
+	if diagnostics.HasErrors() {
+		log.Warn().Msgf("Failed to parse variables file %s: %s", path, diagnostics.Error())
+		return values
+	}
+
+	attributes, diagnostics := file.Body.JustAttributes()
+	if diagnostics.HasErrors() {
+		log.Warn().Msgf("Failed to read variables file %s: %s", path, diagnostics.Error())
+		return values
+	}
+	for name, attribute := range attributes {
+		if value, diagnostics := attribute.Expr.Value(nil); !diagnostics.HasErrors() {
+			values[name] = value
+		}
+	}
+	return values
+}
@@ -0,0 +1,160 @@
+package json
+
+import (
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// documentNode is a document of a YAML stream and the range of its lines [start, end), zero based
+type documentNode struct {
+	node  *yaml.Node
+	start int
+	end   int
+}
+
+// splitDocuments returns the documents of the YAML stream with the range of lines of each one,
+// the items of Kubernetes lists (kind: List, ex: kubectl get -o yaml) are returned as documents
+func splitDocuments(nodes []*yaml.Node, fileContent []byte) []documentNode {
+	lines := strings.Split(strings.ReplaceAll(string(fileContent), "\r", ""), "\n")
+	documents := make([]documentNode, 0, len(nodes))
+	for i, node := range nodes {
+		end := len(lines)
+		if i+1 < len(nodes) {
+			end = documentStart(lines, nodes[i+1])
+		}
+		document := documentNode{node: node, start: contentLine(node), end: end}
+		if items := listItems(document); items != nil {
+			documents = append(documents, items...)
+			continue
+		}
+		documents = append(documents, document)
+	}
+	return documents
+}
+
+// contentLine returns the zero based line where the content of the document node starts
+func contentLine(node *yaml.Node) int {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Line > 0 {
+		return node.Line - 1
+	}
+	return 0
+}
+
+// documentStart returns the zero based line of the document separator (---) of the document node
+func documentStart(lines []string, node *yaml.Node) int {
+	start := contentLine(node)
+	for i := start; i >= 0 && i < len(lines); i-- {
+		if strings.HasPrefix(lines[i], "---") {
+			return i
+		}
+	}
+	return start
+}
+
+// listItems returns the items of a Kubernetes list as documents, nil is returned when the document is not a list
+func listItems(document documentNode) []documentNode {
+	root := document.node
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode || mappingValue(root, "kind") != "List" {
+		return nil
+	}
+	var items *yaml.Node
+	end := document.end
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if items != nil {
+			// the keys after the items (ex: metadata) end the last item
+			end = root.Content[i].Line - 1
+			break
+		}
+		if root.Content[i].Value == "items" && root.Content[i+1].Kind == yaml.SequenceNode {
+			items = root.Content[i+1]
+		}
+	}
+	if items == nil {
+		return nil
+	}
+
+	documents := make([]documentNode, 0, len(items.Content))
+	for i, item := range items.Content {
+		itemEnd := end
+		if i+1 < len(items.Content) {
+			itemEnd = items.Content[i+1].Line - 1
+		}
+		documents = append(documents, documentNode{node: item, start: item.Line - 1, end: itemEnd})
+	}
+	return documents
+}
+
+func mappingValue(node *yaml.Node, key string) string {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1].Value
+		}
+	}
+	return ""
+}
+
+const mergeKey = "<<"
+
+// removeDuplicateKeys removes from the mappings of node the keys defined again later in the same mapping,
+// so the last definition wins, and returns the duplicate keys removed
+func removeDuplicateKeys(node *yaml.Node, searchKey string) []model.DuplicateKey {
+	duplicates := make([]model.DuplicateKey, 0)
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			duplicates = append(duplicates, removeDuplicateKeys(child, searchKey)...)
+		}
+	case yaml.MappingNode:
+		last := make(map[string]int)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			last[node.Content[i].Value] = i
+		}
+		content := make([]*yaml.Node, 0, len(node.Content))
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			childKey := strings.TrimPrefix(searchKey+"."+key.Value, ".")
+			// merge keys can be repeated, each one merges a different mapping
+			if key.Value != mergeKey && last[key.Value] != i {
+				continue
+			}
+			if first := firstDefinition(node, key.Value); first != i && key.Value != mergeKey {
+				duplicates = append(duplicates, model.DuplicateKey{SearchKey: childKey, Line: key.Line})
+			}
+			duplicates = append(duplicates, removeDuplicateKeys(node.Content[i+1], childKey)...)
+			content = append(content, key, node.Content[i+1])
+		}
+		node.Content = content
+	}
+	return duplicates
+}
+
+func firstDefinition(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// setDuplicateKeys adds the duplicate keys to the document, when there are any
+func setDuplicateKeys(doc model.Document, duplicates []model.DuplicateKey) {
+	if doc != nil && len(duplicates) > 0 {
+		doc[model.DuplicateKeysKey] = duplicates
+	}
+}
+
+// setDocumentLines adds the range of lines to the document
+func setDocumentLines(doc model.Document, document documentNode) {
+	if doc != nil {
+		doc[model.DocumentLinesKey] = []int{document.start, document.end}
+	}
+}
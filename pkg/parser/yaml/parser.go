@@ -5,12 +5,15 @@ import (
 	"encoding/json"
 
 	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/parser/cloudformation"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
 )
 
 // Parser defines a parser type
+// CloudFormationParameters, when set, resolves the references to parameters in CloudFormation templates
 type Parser struct {
+	CloudFormationParameters *cloudformation.Parameters
 }
 
 // Playbooks represents a playbook object from parsed yaml files
@@ -23,12 +26,31 @@ func (p *Parser) Parse(_ string, fileContent []byte) ([]model.Document, error) {
 	var documents []model.Document
 	dec := yaml.NewDecoder(bytes.NewReader(fileContent))
 
-	doc := &model.Document{}
-	for dec.Decode(doc) == nil {
+	nodes := make([]*yaml.Node, 0)
+	for {
+		node := &yaml.Node{}
+		if dec.Decode(node) != nil {
+			break
+		}
+		nodes = append(nodes, node)
+	}
+
+	// documents of streams and items of lists keep their lines, so lines are detected in the right document
+	splitted := splitDocuments(nodes, fileContent)
+	for i := range splitted {
+		doc := &model.Document{}
+		duplicates := removeDuplicateKeys(splitted[i].node, "")
+		if splitted[i].node.Decode(doc) != nil {
+			break
+		}
 		if doc != nil {
-			documents = append(documents, *doc)
+			resolved := p.CloudFormationParameters.Resolve(*doc)
+			if len(splitted) > 1 {
+				setDocumentLines(resolved, splitted[i])
+			}
+			setDuplicateKeys(resolved, duplicates)
+			documents = append(documents, resolved)
 		}
-		doc = &model.Document{}
 	}
 
 	if documents == nil {
@@ -47,9 +69,9 @@ func (p *Parser) SupportedExtensions() []string {
 	return []string{".yaml", ".yml"}
 }
 
-// SupportedTypes returns types supported by this parser, which are ansible, cloudFormation, k8s
+// SupportedTypes returns types supported by this parser, which are ansible, cloudFormation, k8s and googleDeploymentManager
 func (p *Parser) SupportedTypes() []string {
-	return []string{"Ansible", "CloudFormation", "Kubernetes"}
+	return []string{"Ansible", "CloudFormation", "Kubernetes", "GoogleDeploymentManager"}
 }
 
 // GetKind returns YAML constant kind
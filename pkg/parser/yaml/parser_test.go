@@ -22,7 +22,7 @@ func TestParser_SupportedExtensions(t *testing.T) {
 // TestParser_SupportedExtensions tests the functions [SupportedTypes()] and all the methods called by them
 func TestParser_SupportedTypes(t *testing.T) {
 	p := &Parser{}
-	require.Equal(t, []string{"Ansible", "CloudFormation", "Kubernetes"}, p.SupportedTypes())
+	require.Equal(t, []string{"Ansible", "CloudFormation", "Kubernetes", "GoogleDeploymentManager"}, p.SupportedTypes())
 }
 
 // TestParser_Parse tests the functions [Parse()] and all the methods called by them
@@ -58,3 +58,78 @@ martin2:
 	require.Len(t, playbook, 1)
 	require.Contains(t, playbook[0]["playbooks"].([]interface{})[0].(map[string]interface{})["name"], "bucket2")
 }
+
+// TestParser_ParseDocumentLines tests the functions [Parse()] and all the methods called by them
+func TestParser_ParseDocumentLines(t *testing.T) {
+	p := &Parser{}
+	stream := `apiVersion: v1
+kind: Pod
+metadata:
+  name: first
+---
+# second pod
+apiVersion: v1
+kind: Pod
+metadata:
+  name: second
+`
+	docs, err := p.Parse("pods.yaml", []byte(stream))
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	require.Equal(t, []int{0, 4}, docs[0][model.DocumentLinesKey])
+	require.Equal(t, []int{6, 11}, docs[1][model.DocumentLinesKey])
+
+	list := `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: first
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: second
+metadata:
+  resourceVersion: ""
+`
+	docs, err = p.Parse("list.yaml", []byte(list))
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	require.Equal(t, "Pod", docs[0]["kind"])
+	require.Equal(t, []int{3, 7}, docs[0][model.DocumentLinesKey])
+	require.Equal(t, "Service", docs[1]["kind"])
+	require.Equal(t, []int{7, 11}, docs[1][model.DocumentLinesKey])
+
+	single, err := p.Parse("pod.yaml", []byte("apiVersion: v1\nkind: Pod\n"))
+	require.NoError(t, err)
+	require.Len(t, single, 1)
+	require.NotContains(t, single[0], model.DocumentLinesKey)
+}
+
+// TestParser_ParseDuplicateKeys tests the functions [Parse()] reporting the keys defined more than once
+func TestParser_ParseDuplicateKeys(t *testing.T) {
+	p := &Parser{}
+	have := `kind: Pod
+spec:
+  hostNetwork: true
+  hostNetwork: false
+  base: &base
+    a: 1
+  other:
+    <<: *base
+    b: 2
+`
+	docs, err := p.Parse("pod.yaml", []byte(have))
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	spec := docs[0]["spec"].(map[string]interface{})
+	require.Equal(t, false, spec["hostNetwork"])
+	require.Equal(t, []model.DuplicateKey{
+		{SearchKey: "spec.hostNetwork", Line: 4},
+	}, docs[0][model.DuplicateKeysKey])
+
+	docs, err = p.Parse("pod.yaml", []byte("kind: Pod\n"))
+	require.NoError(t, err)
+	require.NotContains(t, docs[0], model.DuplicateKeysKey)
+}
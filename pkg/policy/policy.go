@@ -0,0 +1,204 @@
+package policy
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the directory policy files, found in the directories of the scanned path
+const FileName = ".kics.yaml"
+
+// QueryPolicy overrides the severity of the results of a query, Enabled set to false drops them
+type QueryPolicy struct {
+	Severity string `yaml:"severity"`
+	Enabled  *bool  `yaml:"enabled"`
+}
+
+// Directory is the policy of a directory and its subdirectories, ExcludePaths are relative to the directory
+// and Queries maps query IDs to their policy
+type Directory struct {
+	ExcludePaths []string               `yaml:"exclude-paths"`
+	Queries      map[string]QueryPolicy `yaml:"queries"`
+}
+
+// Hierarchy has the directory policies found in a scanned path, the policies of a directory are merged with
+// the policies of its parents, the policies closer to the files taking precedence
+type Hierarchy struct {
+	directories map[string]*Directory
+}
+
+// Load walks the root directory and reads its directory policies, a root that is a file has no policies
+func Load(root string) (*Hierarchy, error) {
+	h := &Hierarchy{directories: make(map[string]*Directory)}
+	info, err := os.Stat(root)
+	if err != nil || !info.IsDir() {
+		return h, nil
+	}
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if info.IsDir() || info.Name() != FileName {
+			return nil
+		}
+		directory, err := loadDirectory(path)
+		if err != nil {
+			return err
+		}
+		h.directories[filepath.ToSlash(filepath.Dir(path))] = directory
+		log.Debug().Msgf("Directory policy loaded from %s", path)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load directory policies")
+	}
+	return h, nil
+}
+
+func loadDirectory(path string) (*Directory, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var directory Directory
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&directory); err != nil && err != io.EOF {
+		return nil, errors.Wrapf(err, "failed to parse directory policy %s", path)
+	}
+	if err := directory.validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid directory policy %s", path)
+	}
+	return &directory, nil
+}
+
+// validate normalizes the severities and checks the exclude paths stay in the directory,
+// so a directory policy can not exclude results of other directories
+func (d *Directory) validate() error {
+	for i, pattern := range d.ExcludePaths {
+		pattern = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(pattern), "./"), "/")
+		if pattern == "" || filepath.IsAbs(pattern) || pattern == ".." || strings.HasPrefix(pattern, "../") {
+			return fmt.Errorf("exclude path %s is not in the directory", d.ExcludePaths[i])
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid exclude path %s: %s", d.ExcludePaths[i], err)
+		}
+		d.ExcludePaths[i] = pattern
+	}
+	for id, query := range d.Queries {
+		if query.Severity == "" {
+			continue
+		}
+		query.Severity = strings.ToUpper(query.Severity)
+		if !isSeverity(query.Severity) {
+			return fmt.Errorf("invalid severity %s of query %s, supported values: high, medium, low, info", query.Severity, id)
+		}
+		d.Queries[id] = query
+	}
+	return nil
+}
+
+// Empty returns true when no directory policies were found
+func (h *Hierarchy) Empty() bool {
+	return h == nil || len(h.directories) == 0
+}
+
+// Apply drops the results excluded by the policies of their directories and overrides their severities
+func (h *Hierarchy) Apply(results []model.Vulnerability) []model.Vulnerability {
+	if h.Empty() {
+		return results
+	}
+	applied := make([]model.Vulnerability, 0, len(results))
+	for i := range results {
+		result := results[i]
+		if h.apply(&result) {
+			applied = append(applied, result)
+		}
+	}
+	if dropped := len(results) - len(applied); dropped > 0 {
+		log.Info().Msgf("%d results excluded by directory policies", dropped)
+	}
+	return applied
+}
+
+// apply overrides the severity of the result and returns false if the result is excluded, the policies
+// are applied from the root to the directory of the file, so subdirectories can enable queries disabled by parents
+func (h *Hierarchy) apply(result *model.Vulnerability) bool {
+	fileName := filepath.ToSlash(result.FileName)
+	enabled := true
+	severity := ""
+	for _, dir := range h.parents(fileName) {
+		directory := h.directories[dir]
+		for _, pattern := range directory.ExcludePaths {
+			if matchPath(pattern, strings.TrimPrefix(fileName, strings.TrimSuffix(dir, "/")+"/")) {
+				return false
+			}
+		}
+		query, ok := directory.Queries[result.QueryID]
+		if !ok {
+			continue
+		}
+		if query.Enabled != nil {
+			enabled = *query.Enabled
+		}
+		if query.Severity != "" {
+			severity = query.Severity
+		}
+	}
+	if severity != "" {
+		result.Severity = model.Severity(severity)
+	}
+	return enabled
+}
+
+// parents returns the directories with policies containing the file, sorted from the root
+func (h *Hierarchy) parents(fileName string) []string {
+	parents := make([]string, 0)
+	for dir := range h.directories {
+		if strings.HasPrefix(fileName, strings.TrimSuffix(dir, "/")+"/") {
+			parents = append(parents, dir)
+		}
+	}
+	sort.Slice(parents, func(i, j int) bool {
+		return len(parents[i]) < len(parents[j])
+	})
+	return parents
+}
+
+// matchPath returns true if the pattern matches the file name or one of its parent directories,
+// patterns without a slash match the names of files and directories at any depth
+func matchPath(pattern, fileName string) bool {
+	for path := fileName; path != "." && path != "/" && path != ""; path = filepath.ToSlash(filepath.Dir(path)) {
+		name := path
+		if !strings.Contains(pattern, "/") {
+			name = filepath.Base(path)
+		}
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func isSeverity(severity string) bool {
+	for _, s := range model.AllSeverities {
+		if string(s) == severity {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoad tests the functions [Load()] and all the methods called by them
+func TestLoad(t *testing.T) {
+	root := t.TempDir()
+	writePolicy := func(dir, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(root, dir, FileName), []byte(content), 0600))
+	}
+	writePolicy("apps", "exclude-paths: [./generated/]\nqueries:\n  q1:\n    severity: low\n")
+	writePolicy("infra", "")
+
+	h, err := Load(root)
+	require.NoError(t, err)
+	require.Equal(t, map[string]*Directory{
+		filepath.ToSlash(filepath.Join(root, "apps")): {
+			ExcludePaths: []string{"generated"},
+			Queries:      map[string]QueryPolicy{"q1": {Severity: "LOW"}},
+		},
+		filepath.ToSlash(filepath.Join(root, "infra")): {},
+	}, h.directories)
+
+	writePolicy("infra", "exclude-paths: [../apps]\n")
+	_, err = Load(root)
+	require.Error(t, err)
+
+	writePolicy("infra", "queries:\n  q1:\n    severity: urgent\n")
+	_, err = Load(root)
+	require.Error(t, err)
+
+	writePolicy("infra", "exclude_paths: [generated]\n")
+	_, err = Load(root)
+	require.Error(t, err)
+}
+
+// TestHierarchy_Apply tests the functions [Apply()] and all the methods called by them
+func TestHierarchy_Apply(t *testing.T) {
+	disabled, enabled := false, true
+	h := &Hierarchy{directories: map[string]*Directory{
+		"/src": {
+			ExcludePaths: []string{"*.test.tf"},
+			Queries:      map[string]QueryPolicy{"q1": {Severity: "MEDIUM"}, "q2": {Enabled: &disabled}},
+		},
+		"/src/apps": {
+			ExcludePaths: []string{"api/generated"},
+			Queries:      map[string]QueryPolicy{"q1": {Severity: "LOW"}, "q2": {Enabled: &enabled}},
+		},
+	}}
+	results := []model.Vulnerability{
+		{QueryID: "q1", FileName: "/src/infra/main.tf", Severity: model.SeverityHigh},
+		{QueryID: "q1", FileName: "/src/apps/api/main.tf", Severity: model.SeverityHigh},
+		{QueryID: "q2", FileName: "/src/infra/main.tf", Severity: model.SeverityHigh},
+		{QueryID: "q2", FileName: "/src/apps/api/main.tf", Severity: model.SeverityHigh},
+		{QueryID: "q3", FileName: "/src/apps/api/generated/main.tf", Severity: model.SeverityHigh},
+		{QueryID: "q3", FileName: "/src/infra/main.test.tf", Severity: model.SeverityHigh},
+		{QueryID: "q3", FileName: "/other/apps/api/generated/main.tf", Severity: model.SeverityHigh},
+	}
+	require.Equal(t, []model.Vulnerability{
+		{QueryID: "q1", FileName: "/src/infra/main.tf", Severity: model.SeverityMedium},
+		{QueryID: "q1", FileName: "/src/apps/api/main.tf", Severity: model.SeverityLow},
+		{QueryID: "q2", FileName: "/src/apps/api/main.tf", Severity: model.SeverityHigh},
+		{QueryID: "q3", FileName: "/other/apps/api/generated/main.tf", Severity: model.SeverityHigh},
+	}, h.Apply(results))
+	require.Equal(t, model.Severity(model.SeverityHigh), results[0].Severity)
+
+	var none *Hierarchy
+	require.Equal(t, results, none.Apply(results))
+}
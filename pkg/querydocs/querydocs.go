@@ -0,0 +1,221 @@
+// Package querydocs generates the documentation pages of the queries from their metadata and test samples
+package querydocs
+
+import (
+	"bytes"
+	_ "embed" // used for embedding the documentation templates
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/Checkmarx/kics/pkg/engine/source"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	//go:embed template/query.md.tmpl
+	queryTemplate string
+	//go:embed template/index.md.tmpl
+	indexTemplate string
+)
+
+const (
+	// IndexFileName is the name of the page listing all queries
+	IndexFileName = "index.md"
+	testDir       = "test"
+	templateDir   = "template"
+)
+
+var languages = map[string]string{
+	".tf":         "hcl",
+	".json":       "json",
+	".yaml":       "yaml",
+	".yml":        "yaml",
+	".dockerfile": "dockerfile",
+	".pp":         "puppet",
+	".sls":        "yaml",
+	".bicep":      "bicep",
+}
+
+// Sample is a test file of a query
+type Sample struct {
+	FileName string
+	Language string
+	Content  string
+}
+
+// QueryDoc is the documentation of a query
+// Path is the query directory relative to the queries path, using forward slashes
+// Positive and Negative are the samples with and without vulnerabilities found in the query test directory
+type QueryDoc struct {
+	ID             string
+	Name           string
+	Severity       string
+	Category       string
+	Description    string
+	DescriptionURL string
+	Platform       string
+	Provider       string
+	Path           string
+	Positive       []Sample
+	Negative       []Sample
+}
+
+// Load reads the metadata and test samples of all queries in queriesPath, sorted by platform and name
+func Load(queriesPath string) ([]QueryDoc, error) {
+	docs := make([]QueryDoc, 0)
+	err := filepath.Walk(queriesPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == templateDir {
+			return filepath.SkipDir
+		}
+		if info.IsDir() || info.Name() != source.QueryFileName {
+			return nil
+		}
+		doc, err := loadQuery(queriesPath, filepath.Dir(p))
+		if err != nil {
+			log.Err(err).Msgf("Failed to load query documentation, query=%s", filepath.Dir(p))
+			return nil
+		}
+		docs = append(docs, doc)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read queries")
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		if docs[i].Platform != docs[j].Platform {
+			return docs[i].Platform < docs[j].Platform
+		}
+		return docs[i].Name < docs[j].Name
+	})
+	return docs, nil
+}
+
+func loadQuery(queriesPath, queryDir string) (QueryDoc, error) {
+	metadata := source.ReadMetadata(queryDir)
+	if metadata == nil {
+		return QueryDoc{}, errors.New("query has no metadata")
+	}
+	relativePath, err := filepath.Rel(queriesPath, queryDir)
+	if err != nil {
+		return QueryDoc{}, err
+	}
+	relativePath = filepath.ToSlash(relativePath)
+	parts := strings.Split(relativePath, "/")
+	provider := ""
+	if len(parts) > 2 {
+		provider = parts[len(parts)-2]
+	}
+
+	doc := QueryDoc{
+		ID:             metadataString(metadata, "id"),
+		Name:           metadataString(metadata, "queryName"),
+		Severity:       metadataString(metadata, "severity"),
+		Category:       metadataString(metadata, "category"),
+		Description:    metadataString(metadata, "descriptionText"),
+		DescriptionURL: metadataString(metadata, "descriptionUrl"),
+		Platform:       metadataString(metadata, "platform"),
+		Provider:       provider,
+		Path:           relativePath,
+	}
+	doc.Positive, doc.Negative, err = loadSamples(filepath.Join(queryDir, testDir))
+	return doc, err
+}
+
+func loadSamples(dir string) (positive, negative []Sample, err error) {
+	positive, negative = make([]Sample, 0), make([]Sample, 0)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return positive, negative, nil
+		}
+		return nil, nil, errors.Wrap(err, "failed to read query samples")
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(name, "_expected_result.json") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Clean(filepath.Join(dir, name)))
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to read query sample")
+		}
+		sample := Sample{
+			FileName: name,
+			Language: languages[strings.ToLower(filepath.Ext(name))],
+			Content:  strings.TrimRight(string(content), "\n"),
+		}
+		switch {
+		case strings.HasPrefix(name, "positive"):
+			positive = append(positive, sample)
+		case strings.HasPrefix(name, "negative"):
+			negative = append(negative, sample)
+		}
+	}
+	return positive, negative, nil
+}
+
+func metadataString(metadata map[string]interface{}, key string) string {
+	value, _ := metadata[key].(string)
+	return value
+}
+
+// PageName returns the path of the documentation page of a query, relative to the output directory
+func PageName(doc *QueryDoc) string {
+	return doc.Path + ".md"
+}
+
+// Generate writes a documentation page for each query in queriesPath and an index listing them to outputPath
+// and returns the number of pages written, not counting the index
+func Generate(queriesPath, outputPath string) (int, error) {
+	docs, err := Load(queriesPath)
+	if err != nil {
+		return 0, err
+	}
+
+	funcs := template.FuncMap{
+		"severity": severityTitle,
+		"page": func(doc QueryDoc) string {
+			return PageName(&doc)
+		},
+	}
+	queryTmpl := template.Must(template.New("query").Funcs(funcs).Parse(queryTemplate))
+	indexTmpl := template.Must(template.New("index").Funcs(funcs).Parse(indexTemplate))
+
+	for i := range docs {
+		if err := render(queryTmpl, docs[i], filepath.Join(outputPath, filepath.FromSlash(PageName(&docs[i])))); err != nil {
+			return 0, err
+		}
+	}
+	if err := render(indexTmpl, docs, filepath.Join(outputPath, IndexFileName)); err != nil {
+		return 0, err
+	}
+	return len(docs), nil
+}
+
+func render(tmpl *template.Template, data interface{}, path string) error {
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return errors.Wrapf(err, "failed to render %s", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return errors.Wrapf(err, "failed to create directory of %s", path)
+	}
+	return errors.Wrapf(os.WriteFile(path, buffer.Bytes(), 0600), "failed to write %s", path)
+}
+
+func severityTitle(severity string) string {
+	for _, s := range model.AllSeverities {
+		if strings.EqualFold(severity, string(s)) {
+			return string(s[0]) + strings.ToLower(string(s[1:]))
+		}
+	}
+	return severity
+}
@@ -0,0 +1,76 @@
+package querydocs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/Checkmarx/kics/test"
+)
+
+// TestLoad tests the functions [Load()] and all the methods called by them
+func TestLoad(t *testing.T) {
+	if err := test.ChangeCurrentDir("kics"); err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := Load(filepath.FromSlash("assets/queries/terraform/aws"))
+	require.NoError(t, err)
+	require.NotEmpty(t, docs)
+
+	var doc *QueryDoc
+	for i := range docs {
+		if docs[i].ID == "de7f5e83-da88-4046-871f-ea18504b1d43" {
+			doc = &docs[i]
+		}
+	}
+	require.NotNil(t, doc)
+	require.Equal(t, "ALB Listening on HTTP", doc.Name)
+	require.Equal(t, "Terraform", doc.Platform)
+	require.Equal(t, "alb_listening_on_http", doc.Path)
+	require.Equal(t, []string{"positive.tf"}, sampleNames(doc.Positive))
+	require.Equal(t, []string{"negative.tf"}, sampleNames(doc.Negative))
+	require.Equal(t, "hcl", doc.Positive[0].Language)
+
+	docs, err = Load(filepath.FromSlash("assets/queries"))
+	require.NoError(t, err)
+	for i := range docs {
+		require.NotEqual(t, "<PLATFORM>", docs[i].Platform, "the query template must be skipped")
+		if docs[i].ID == "de7f5e83-da88-4046-871f-ea18504b1d43" {
+			require.Equal(t, "aws", docs[i].Provider)
+			require.Equal(t, "terraform/aws/alb_listening_on_http", docs[i].Path)
+		}
+	}
+}
+
+// TestGenerate tests the functions [Generate()] and all the methods called by them
+func TestGenerate(t *testing.T) {
+	if err := test.ChangeCurrentDir("kics"); err != nil {
+		t.Fatal(err)
+	}
+
+	output := t.TempDir()
+	pages, err := Generate(filepath.FromSlash("assets/queries/dockerfile"), output)
+	require.NoError(t, err)
+	require.Greater(t, pages, 0)
+
+	page, err := os.ReadFile(filepath.Join(output, "add_instead_of_copy.md"))
+	require.NoError(t, err)
+	require.Contains(t, string(page), "## Add Instead of Copy")
+	require.Contains(t, string(page), "- **Severity:** Low")
+	require.Contains(t, string(page), "```dockerfile title=\"positive.dockerfile\"")
+
+	index, err := os.ReadFile(filepath.Join(output, IndexFileName))
+	require.NoError(t, err)
+	require.Contains(t, string(index), "[Add Instead of Copy](add_instead_of_copy.md)")
+}
+
+func sampleNames(samples []Sample) []string {
+	names := make([]string, 0, len(samples))
+	for _, sample := range samples {
+		names = append(names, sample.FileName)
+	}
+	return names
+}
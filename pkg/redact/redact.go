@@ -0,0 +1,294 @@
+// Package redact keeps the secrets of the environment of a scan, such as the values of its tokens and the content
+// of its mounted secret files, out of the reports and the logs, replacing them wherever they would be written
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// Placeholder replaces the secrets in the output
+const Placeholder = "<redacted>"
+
+// minSecretLength is the minimum length of the secrets redacted, shorter values, such as true or 1,
+// would redact unrelated output
+const minSecretLength = 8
+
+// DefaultEnvDenylist are the patterns of the names of the environment variables whose values are secrets by default
+var DefaultEnvDenylist = []string{
+	"*TOKEN*", "*SECRET*", "*PASSWORD*", "*PASSWD*", "*API_KEY*", "*APIKEY*", "*PRIVATE_KEY*", "*CREDENTIAL*",
+}
+
+// Redactor replaces secrets, all its methods can be called on a nil Redactor, which redacts nothing
+type Redactor struct {
+	replacer *strings.Replacer
+	secrets  int
+}
+
+// New creates a redactor of the values of the environment variables whose names match the patterns of the env denylist,
+// case insensitive, and of the content and the lines of the files matching the patterns of the file denylist,
+// it returns nil when there are no secrets to redact
+func New(envDenylist, fileDenylist []string) (*Redactor, error) {
+	secrets := make(map[string]bool)
+	for _, env := range os.Environ() {
+		parts := strings.SplitN(env, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		matched, err := matchAny(envDenylist, strings.ToUpper(parts[0]))
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			addSecret(secrets, parts[1])
+		}
+	}
+	for _, pattern := range fileDenylist {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid secret file pattern %s", pattern)
+		}
+		for _, file := range files {
+			if info, err := os.Stat(file); err != nil || info.IsDir() {
+				continue
+			}
+			content, err := os.ReadFile(filepath.Clean(file))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read secret file %s", file)
+			}
+			addSecret(secrets, string(content))
+			for _, line := range strings.Split(string(content), "\n") {
+				addSecret(secrets, line)
+			}
+		}
+	}
+	return newRedactor(secrets), nil
+}
+
+func matchAny(patterns []string, name string) (bool, error) {
+	for _, pattern := range patterns {
+		matched, err := filepath.Match(strings.ToUpper(pattern), name)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid environment variable pattern %s", pattern)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// addSecret adds the secret, trimmed, and its JSON escaped form, as written in JSON reports and logs
+func addSecret(secrets map[string]bool, secret string) {
+	secret = strings.TrimSpace(strings.TrimSuffix(secret, "\r"))
+	if len(secret) < minSecretLength {
+		return
+	}
+	secrets[secret] = true
+	if escaped, err := json.Marshal(secret); err == nil {
+		secrets[string(escaped[1:len(escaped)-1])] = true
+	}
+}
+
+// newRedactor creates the redactor of the secrets, longer secrets are replaced first so the secrets
+// containing others are replaced as a whole
+func newRedactor(secrets map[string]bool) *Redactor {
+	if len(secrets) == 0 {
+		return nil
+	}
+	sorted := make([]string, 0, len(secrets))
+	for secret := range secrets {
+		sorted = append(sorted, secret)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i]) != len(sorted[j]) {
+			return len(sorted[i]) > len(sorted[j])
+		}
+		return sorted[i] < sorted[j]
+	})
+	oldnew := make([]string, 0, 2*len(sorted))
+	for _, secret := range sorted {
+		oldnew = append(oldnew, secret, Placeholder)
+	}
+	return &Redactor{replacer: strings.NewReplacer(oldnew...), secrets: len(sorted)}
+}
+
+// Secrets returns the number of secrets redacted, with their escaped forms
+func (r *Redactor) Secrets() int {
+	if r == nil {
+		return 0
+	}
+	return r.secrets
+}
+
+// String redacts the secrets of s
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	return r.replacer.Replace(s)
+}
+
+// Bytes redacts the secrets of b
+func (r *Redactor) Bytes(b []byte) []byte {
+	if r == nil {
+		return b
+	}
+	return []byte(r.replacer.Replace(string(b)))
+}
+
+// Writer returns a writer redacting each write to w, secrets split across writes are not redacted,
+// so it suits writers of whole lines or events, such as loggers, LineWriter redacts the other outputs
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	if r == nil {
+		return w
+	}
+	return &writer{redactor: r, out: w}
+}
+
+type writer struct {
+	redactor *Redactor
+	out      io.Writer
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if _, err := w.out.Write(w.redactor.Bytes(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// LevelWriter returns a level writer redacting each write to w, as Writer, for the loggers writing by level
+func (r *Redactor) LevelWriter(w zerolog.LevelWriter) zerolog.LevelWriter {
+	if r == nil {
+		return w
+	}
+	return &levelWriter{writer: writer{redactor: r, out: w}, out: w}
+}
+
+type levelWriter struct {
+	writer
+	out zerolog.LevelWriter
+}
+
+func (w *levelWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if _, err := w.out.WriteLevel(level, w.redactor.Bytes(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// LineWriter returns a writer redacting the output written to w line by line, each line is written once it ends
+// with a line break or a carriage return, as the progress bars, so the secrets split across writes are redacted too,
+// Close writes the last line, even if it does not end
+func (r *Redactor) LineWriter(w io.Writer) io.WriteCloser {
+	return &lineWriter{redactor: r, out: w}
+}
+
+type lineWriter struct {
+	redactor *Redactor
+	out      io.Writer
+	pending  []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	end := bytes.LastIndexAny(w.pending, "\r\n")
+	if end < 0 {
+		return len(p), nil
+	}
+	lines := w.pending[:end+1]
+	if _, err := w.out.Write(w.redactor.Bytes(lines)); err != nil {
+		return 0, err
+	}
+	w.pending = append(w.pending[:0], w.pending[end+1:]...)
+	return len(p), nil
+}
+
+func (w *lineWriter) Close() error {
+	if len(w.pending) == 0 {
+		return nil
+	}
+	_, err := w.out.Write(w.redactor.Bytes(w.pending))
+	w.pending = nil
+	return err
+}
+
+// File is a file whose content is redacted when it is closed, so secrets split across writes are redacted too
+type File struct {
+	redactor *Redactor
+	file     *os.File
+	buffer   []byte
+	once     sync.Once
+}
+
+// Create creates the file, its content is kept in memory and written redacted when it is closed
+func (r *Redactor) Create(path string, perm os.FileMode) (*File, error) {
+	file, err := os.OpenFile(filepath.Clean(path), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &File{redactor: r, file: file}, nil
+}
+
+// Write adds p to the content of the file
+func (f *File) Write(p []byte) (int, error) {
+	f.buffer = append(f.buffer, p...)
+	return len(p), nil
+}
+
+// Close writes the redacted content of the file and closes it
+func (f *File) Close() error {
+	var err error
+	f.once.Do(func() {
+		_, err = f.file.Write(f.redactor.Bytes(f.buffer))
+		if closeErr := f.file.Close(); err == nil {
+			err = closeErr
+		}
+	})
+	return err
+}
+
+// WriteFile writes the redacted data to the file
+func (r *Redactor) WriteFile(path string, data []byte, perm os.FileMode) error {
+	return os.WriteFile(path, r.Bytes(data), perm)
+}
+
+// Stdout redirects os.Stdout to a pipe whose output is redacted line by line and written to the original stdout,
+// the returned function restores os.Stdout and waits for the output written until then
+func (r *Redactor) Stdout() (restore func(), err error) {
+	if r == nil || os.Stdout == nil {
+		return func() {}, nil
+	}
+	reader, pipe, err := os.Pipe()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to redact stdout")
+	}
+	stdout := os.Stdout
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		out := r.LineWriter(stdout)
+		_, _ = io.Copy(out, reader)
+		_ = out.Close()
+	}()
+	os.Stdout = pipe
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			os.Stdout = stdout
+			_ = pipe.Close()
+			<-done
+			_ = reader.Close()
+		})
+	}, nil
+}
@@ -0,0 +1,111 @@
+package redact
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRedactor tests the functions [New()], [Redactor.String()], [Redactor.Writer()], [Redactor.Create()]
+// and all the methods called by them
+func TestRedactor(t *testing.T) {
+	require.NoError(t, os.Setenv("KICS_TEST_API_TOKEN", "ghp_0123456789abcdef"))
+	require.NoError(t, os.Setenv("KICS_TEST_DB_PASSWORD", "short"))
+	require.NoError(t, os.Setenv("KICS_TEST_REGION", "eu-west-1-region"))
+	defer func() {
+		require.NoError(t, os.Unsetenv("KICS_TEST_API_TOKEN"))
+		require.NoError(t, os.Unsetenv("KICS_TEST_DB_PASSWORD"))
+		require.NoError(t, os.Unsetenv("KICS_TEST_REGION"))
+	}()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db_url"), []byte("postgres://admin:\"s3cr3t\"@db\nline-secret-value\n"), 0600))
+
+	redactor, err := New([]string{"kics_test_*token*", "KICS_TEST_*PASSWORD*"}, []string{filepath.Join(dir, "*")})
+	require.NoError(t, err)
+
+	require.Equal(t, "token <redacted> in eu-west-1-region", redactor.String("token ghp_0123456789abcdef in eu-west-1-region"))
+	require.Equal(t, "password short", redactor.String("password short"), "short values are not redacted")
+	require.Equal(t, "url <redacted>, <redacted>", redactor.String("url postgres://admin:\"s3cr3t\"@db, line-secret-value"))
+	require.Equal(t, `{"url":"<redacted>"}`, redactor.String(`{"url":"postgres://admin:\"s3cr3t\"@db"}`), "JSON escaped secrets are redacted")
+
+	var buffer bytes.Buffer
+	_, err = redactor.Writer(&buffer).Write([]byte("ghp_0123456789abcdef\n"))
+	require.NoError(t, err)
+	require.Equal(t, "<redacted>\n", buffer.String())
+
+	report := filepath.Join(dir, "report.json")
+	f, err := redactor.Create(report, 0600)
+	require.NoError(t, err)
+	_, err = f.Write([]byte(`{"token": "ghp_0123`))
+	require.NoError(t, err)
+	_, err = f.Write([]byte(`456789abcdef"}`))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	content, err := os.ReadFile(report)
+	require.NoError(t, err)
+	require.Equal(t, `{"token": "<redacted>"}`, string(content), "secrets split across writes of files are redacted")
+
+	var nothing *Redactor
+	require.Equal(t, "ghp_0123456789abcdef", nothing.String("ghp_0123456789abcdef"))
+
+	empty, err := New([]string{"KICS_TEST_NOT_SET_*"}, nil)
+	require.NoError(t, err)
+	require.Nil(t, empty)
+
+	_, err = New([]string{"["}, nil)
+	require.Error(t, err)
+}
+
+type fakeLevelWriter struct {
+	bytes.Buffer
+	levels []zerolog.Level
+}
+
+func (f *fakeLevelWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	f.levels = append(f.levels, level)
+	return f.Write(p)
+}
+
+// TestRedactor_Split tests the functions [Redactor.LineWriter()], [Redactor.Stdout()] and [Redactor.LevelWriter()]
+// with secrets split across writes
+func TestRedactor_Split(t *testing.T) {
+	redactor := newRedactor(map[string]bool{"ghp_0123456789abcdef": true})
+
+	var buffer bytes.Buffer
+	w := redactor.LineWriter(&buffer)
+	for _, chunk := range []string{"token ghp_0123", "456789abcdef\n", "progress 50%\r", "last ghp_01234567", "89abcdef"} {
+		_, err := w.Write([]byte(chunk))
+		require.NoError(t, err)
+	}
+	require.Equal(t, "token <redacted>\nprogress 50%\r", buffer.String(), "the lines are written once they end")
+	require.NoError(t, w.Close())
+	require.Equal(t, "token <redacted>\nprogress 50%\rlast <redacted>", buffer.String())
+
+	stdout := os.Stdout
+	defer func() { os.Stdout = stdout }()
+	out, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	require.NoError(t, err)
+	defer out.Close()
+	os.Stdout = out
+	restore, err := redactor.Stdout()
+	require.NoError(t, err)
+	for _, chunk := range []string{"export TOKEN=ghp_01", "23456789", "abcdef\n", "ghp_0123456789", "abcdef"} {
+		_, err = os.Stdout.WriteString(chunk)
+		require.NoError(t, err)
+	}
+	restore()
+	content, err := os.ReadFile(out.Name())
+	require.NoError(t, err)
+	require.Equal(t, "export TOKEN=<redacted>\n<redacted>", string(content))
+
+	levelWriter := &fakeLevelWriter{}
+	_, err = redactor.LevelWriter(levelWriter).WriteLevel(zerolog.WarnLevel, []byte(`{"message":"ghp_0123456789abcdef"}`))
+	require.NoError(t, err)
+	require.Equal(t, `{"message":"<redacted>"}`, levelWriter.String())
+	require.Equal(t, []zerolog.Level{zerolog.WarnLevel}, levelWriter.levels)
+}
@@ -0,0 +1,79 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+)
+
+const azureSummaryFileName = "kics-summary.md"
+
+var (
+	azureMessageEscaper  = strings.NewReplacer("%", "%AZP25", "\r", "%0D", "\n", "%0A")
+	azurePropertyEscaper = strings.NewReplacer("%", "%AZP25", "\r", "%0D", "\n", "%0A", ";", "%3B", "]", "%5D")
+)
+
+// PrintAzureAnnotations writes the results as Azure Pipelines logging commands (##vso[task.logissue ...]),
+// HIGH results are errors and the other results warnings, and uploads a markdown summary of the results
+// shown in the Extensions tab of the run, file names are made relative to root, the repository directory
+func PrintAzureAnnotations(w io.Writer, summary *model.Summary, root string) error {
+	for i := range summary.Queries {
+		query := &summary.Queries[i]
+		issueType := "warning"
+		if query.Severity == model.SeverityHigh {
+			issueType = "error"
+		}
+		for j := range query.Files {
+			file := &query.Files[j]
+			properties := fmt.Sprintf("type=%s;sourcepath=%s", issueType,
+				azurePropertyEscaper.Replace(annotationFileName(file.FileName, root)))
+			if file.Line > 0 {
+				properties += fmt.Sprintf(";linenumber=%d", file.Line)
+			}
+			properties += ";code=" + azurePropertyEscaper.Replace(query.QueryID)
+			message := fmt.Sprintf("[%s] %s: %s", query.Severity, query.QueryName, file.KeyActualValue)
+			if _, err := fmt.Fprintf(w, "##vso[task.logissue %s]%s\n", properties, azureMessageEscaper.Replace(message)); err != nil {
+				return err
+			}
+		}
+	}
+
+	summaryDir := os.Getenv("AGENT_TEMPDIRECTORY")
+	if summaryDir == "" {
+		summaryDir = os.TempDir()
+	}
+	summaryPath := filepath.Join(summaryDir, azureSummaryFileName)
+	if err := redactor.WriteFile(summaryPath, []byte(azureSummary(summary)), os.ModePerm); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "##vso[task.uploadsummary]%s\n", summaryPath)
+	return err
+}
+
+// azureSummary returns the markdown summary of the results, the results by severity and by query
+func azureSummary(summary *model.Summary) string {
+	var sb strings.Builder
+	sb.WriteString("## KICS Results\n\n")
+	sb.WriteString("| Severity | Results |\n|---|---|\n")
+	for _, severity := range model.AllSeverities {
+		fmt.Fprintf(&sb, "| %s | %d |\n", severity, summary.SeverityCounters[severity])
+	}
+	fmt.Fprintf(&sb, "| TOTAL | %d |\n", summary.TotalCounter)
+	if len(summary.Queries) == 0 {
+		return sb.String()
+	}
+	sb.WriteString("\n| Severity | Query | Platform | Results |\n|---|---|---|---|\n")
+	for i := range summary.Queries {
+		query := &summary.Queries[i]
+		name := strings.ReplaceAll(query.QueryName, "|", "\\|")
+		if query.QueryURI != "" {
+			name = fmt.Sprintf("[%s](%s)", name, query.QueryURI)
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s | %d |\n", query.Severity, name, query.Platform, len(query.Files))
+	}
+	return sb.String()
+}
@@ -0,0 +1,82 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPrintAzureAnnotations tests the functions [PrintAzureAnnotations()] and all the methods called by them
+func TestPrintAzureAnnotations(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.Setenv("AGENT_TEMPDIRECTORY", tempDir))
+	defer func() {
+		require.NoError(t, os.Unsetenv("AGENT_TEMPDIRECTORY"))
+	}()
+	root, err := filepath.Abs("repository")
+	require.NoError(t, err)
+	summary := &model.Summary{
+		Queries: []model.VulnerableQuery{
+			{
+				QueryName: "ALB protocol is HTTP",
+				QueryID:   "de7f5e83-da88-4046-871f-ea18504b1d43",
+				QueryURI:  "https://docs.aws.amazon.com/elasticloadbalancing/latest/application/load-balancer-listeners.html",
+				Severity:  model.SeverityHigh,
+				Platform:  "Terraform",
+				Files: []model.VulnerableFile{
+					{
+						FileName:       filepath.Join(root, "terraform", "main.tf"),
+						Line:           25,
+						KeyActualValue: "'default_action.redirect.protocol' is missing",
+					},
+				},
+			},
+			{
+				QueryName: "Healthcheck Instruction Missing",
+				QueryID:   "b03a748a-542d-44f4-bb86-9199ab4fd2d5",
+				Severity:  model.SeverityLow,
+				Platform:  "Dockerfile",
+				Files: []model.VulnerableFile{
+					{
+						FileName:       "Dockerfile",
+						Line:           -1,
+						KeyActualValue: "Dockerfile doesn't contain instruction 'HEALTHCHECK'; 100%",
+					},
+				},
+			},
+		},
+		SeveritySummary: model.SeveritySummary{
+			SeverityCounters: map[model.Severity]int{
+				model.SeverityHigh: 1,
+				model.SeverityLow:  1,
+			},
+			TotalCounter: 2,
+		},
+	}
+
+	out := &bytes.Buffer{}
+	require.NoError(t, PrintAzureAnnotations(out, summary, root))
+	summaryPath := filepath.Join(tempDir, "kics-summary.md")
+	require.Equal(t,
+		"##vso[task.logissue type=error;sourcepath=terraform/main.tf;linenumber=25;code=de7f5e83-da88-4046-871f-ea18504b1d43]"+
+			"[HIGH] ALB protocol is HTTP: 'default_action.redirect.protocol' is missing\n"+
+			"##vso[task.logissue type=warning;sourcepath=Dockerfile;code=b03a748a-542d-44f4-bb86-9199ab4fd2d5]"+
+			"[LOW] Healthcheck Instruction Missing: Dockerfile doesn't contain instruction 'HEALTHCHECK'; 100%AZP25\n"+
+			"##vso[task.uploadsummary]"+summaryPath+"\n",
+		out.String())
+
+	content, err := os.ReadFile(summaryPath)
+	require.NoError(t, err)
+	require.Equal(t, "## KICS Results\n\n"+
+		"| Severity | Results |\n|---|---|\n"+
+		"| HIGH | 1 |\n| MEDIUM | 0 |\n| LOW | 1 |\n| INFO | 0 |\n| TOTAL | 2 |\n\n"+
+		"| Severity | Query | Platform | Results |\n|---|---|---|---|\n"+
+		"| HIGH | [ALB protocol is HTTP](https://docs.aws.amazon.com/elasticloadbalancing/latest/application/load-balancer-listeners.html)"+
+		" | Terraform | 1 |\n"+
+		"| LOW | Healthcheck Instruction Missing | Dockerfile | 1 |\n",
+		string(content))
+}
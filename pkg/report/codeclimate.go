@@ -0,0 +1,96 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+)
+
+var codeClimateSeverities = map[model.Severity]string{
+	model.SeverityHigh:   "critical",
+	model.SeverityMedium: "major",
+	model.SeverityLow:    "minor",
+	model.SeverityInfo:   "info",
+}
+
+type codeClimateIssue struct {
+	Type        string              `json:"type"`
+	CheckName   string              `json:"check_name"`
+	Description string              `json:"description"`
+	Content     codeClimateContent  `json:"content"`
+	Categories  []string            `json:"categories"`
+	Location    codeClimateLocation `json:"location"`
+	Severity    string              `json:"severity"`
+	Fingerprint string              `json:"fingerprint"`
+}
+
+type codeClimateContent struct {
+	Body string `json:"body"`
+}
+
+type codeClimateLocation struct {
+	Path  string           `json:"path"`
+	Lines codeClimateLines `json:"lines"`
+}
+
+type codeClimateLines struct {
+	Begin int `json:"begin"`
+}
+
+// PrintCodeClimateReport creates a report file with the results as Code Climate issues, the format of the
+// GitLab code quality reports, file names are made relative to root, the repository directory
+func PrintCodeClimateReport(path, filename string, body interface{}, root string) error {
+	if !strings.HasSuffix(filename, ".json") {
+		filename += "-codeclimate.json"
+	}
+	var summary model.Summary
+	result, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(result, &summary); err != nil {
+		return err
+	}
+
+	issues := make([]codeClimateIssue, 0)
+	for i := range summary.Queries {
+		query := &summary.Queries[i]
+		for j := range query.Files {
+			issues = append(issues, newCodeClimateIssue(query, &query.Files[j], root))
+		}
+	}
+	return PrintJSONReport(path, filename, issues)
+}
+
+func newCodeClimateIssue(query *model.VulnerableQuery, file *model.VulnerableFile, root string) codeClimateIssue {
+	severity, ok := codeClimateSeverities[query.Severity]
+	if !ok {
+		severity = "info"
+	}
+	line := file.Line
+	if line < 1 {
+		line = 1
+	}
+	fileName := annotationFileName(file.FileName, root)
+	fingerprint := file.SimilarityID
+	if fingerprint == "" {
+		hash := sha256.Sum256([]byte(fmt.Sprintf("%s:%s:%s", query.QueryID, fileName, file.SearchKey)))
+		fingerprint = hex.EncodeToString(hash[:])
+	}
+	return codeClimateIssue{
+		Type:        "issue",
+		CheckName:   query.QueryID,
+		Description: fmt.Sprintf("[%s] %s", query.Severity, query.QueryName),
+		Content: codeClimateContent{
+			Body: fmt.Sprintf("%s\n\nExpected: %s\nActual: %s", query.Description, file.KeyExpectedValue, file.KeyActualValue),
+		},
+		Categories:  []string{"Security"},
+		Location:    codeClimateLocation{Path: fileName, Lines: codeClimateLines{Begin: line}},
+		Severity:    severity,
+		Fingerprint: fingerprint,
+	}
+}
@@ -0,0 +1,57 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPrintCodeClimateReport tests the functions [PrintCodeClimateReport()] and all the methods called by them
+func TestPrintCodeClimateReport(t *testing.T) {
+	dir := t.TempDir()
+	summary := model.Summary{
+		Queries: model.VulnerableQuerySlice{
+			{
+				QueryName:   "ALB protocol is HTTP",
+				QueryID:     "de7f5e83-da88-4046-871f-ea18504b1d43",
+				Severity:    model.SeverityHigh,
+				Description: "AWS Application Load Balancer (alb) should not listen on HTTP",
+				Files: []model.VulnerableFile{
+					{FileName: "/src/infra/positive.tf", Line: 25, SimilarityID: "abc", KeyExpectedValue: "HTTPS", KeyActualValue: "HTTP"},
+					{FileName: "/src/infra/positive.tf", Line: 0, SearchKey: "aws_alb_listener[front_end]"},
+				},
+			},
+		},
+	}
+
+	require.NoError(t, PrintCodeClimateReport(dir, "results", &summary, "/src"))
+	content, err := os.ReadFile(filepath.Join(dir, "results-codeclimate.json"))
+	require.NoError(t, err)
+	var issues []codeClimateIssue
+	require.NoError(t, json.Unmarshal(content, &issues))
+	require.Len(t, issues, 2)
+
+	require.Equal(t, codeClimateIssue{
+		Type:        "issue",
+		CheckName:   "de7f5e83-da88-4046-871f-ea18504b1d43",
+		Description: "[HIGH] ALB protocol is HTTP",
+		Content: codeClimateContent{
+			Body: "AWS Application Load Balancer (alb) should not listen on HTTP\n\nExpected: HTTPS\nActual: HTTP",
+		},
+		Categories:  []string{"Security"},
+		Location:    codeClimateLocation{Path: "infra/positive.tf", Lines: codeClimateLines{Begin: 25}},
+		Severity:    "critical",
+		Fingerprint: "abc",
+	}, issues[0])
+	require.Equal(t, 1, issues[1].Location.Lines.Begin)
+	require.Len(t, issues[1].Fingerprint, 64)
+
+	require.NoError(t, PrintCodeClimateReport(dir, "gl-code-quality-report.json", &model.Summary{}, "/src"))
+	content, err = os.ReadFile(filepath.Join(dir, "gl-code-quality-report.json"))
+	require.NoError(t, err)
+	require.Equal(t, "[]\n", string(content))
+}
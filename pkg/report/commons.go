@@ -3,11 +3,12 @@ package report
 import (
 	"fmt"
 	"html/template"
-	"os"
+	"io"
 	"strings"
 	"time"
 
 	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/redact"
 	"github.com/rs/zerolog/log"
 )
 
@@ -19,6 +20,14 @@ var templateFuncs = template.FuncMap{
 	"trimSpaces":     trimSpaces,
 }
 
+// redactor redacts the secrets of the environment of the scan from the reports, set with SetRedactor
+var redactor *redact.Redactor
+
+// SetRedactor sets the redactor of the secrets of the reports, nothing is redacted when it is nil
+func SetRedactor(r *redact.Redactor) {
+	redactor = r
+}
+
 var stringsSeverity = map[string]model.Severity{
 	"high":   model.AllSeverities[0],
 	"medium": model.AllSeverities[1],
@@ -39,7 +48,7 @@ func getCurrentTime() string {
 	return fmt.Sprint(dt.Format("01/02/2006 15:04"))
 }
 
-func closeFile(path, filename string, file *os.File) {
+func closeFile(path, filename string, file io.Closer) {
 	err := file.Close()
 	if err != nil {
 		log.Err(err).Msgf("Failed to close file %s", path)
@@ -0,0 +1,126 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+)
+
+// DefaultCSVColumns are the columns of the CSV reports when no columns are set
+var DefaultCSVColumns = []string{"query", "severity", "file", "line", "resource", "description"}
+
+// csvColumnValues returns the value of each CSV column for a result of a query,
+// the resource is the search key of the result, locating the resource and its attribute
+var csvColumnValues = map[string]func(query *model.VulnerableQuery, file *model.VulnerableFile) string{
+	"query":          func(q *model.VulnerableQuery, _ *model.VulnerableFile) string { return q.QueryName },
+	"query_id":       func(q *model.VulnerableQuery, _ *model.VulnerableFile) string { return q.QueryID },
+	"query_url":      func(q *model.VulnerableQuery, _ *model.VulnerableFile) string { return q.QueryURI },
+	"severity":       func(q *model.VulnerableQuery, _ *model.VulnerableFile) string { return string(q.Severity) },
+	"platform":       func(q *model.VulnerableQuery, _ *model.VulnerableFile) string { return q.Platform },
+	"category":       func(q *model.VulnerableQuery, _ *model.VulnerableFile) string { return q.Category },
+	"description":    func(q *model.VulnerableQuery, _ *model.VulnerableFile) string { return q.Description },
+	"file":           func(_ *model.VulnerableQuery, f *model.VulnerableFile) string { return f.FileName },
+	"line":           func(_ *model.VulnerableQuery, f *model.VulnerableFile) string { return strconv.Itoa(f.Line) },
+	"resource":       func(_ *model.VulnerableQuery, f *model.VulnerableFile) string { return f.SearchKey },
+	"issue_type":     func(_ *model.VulnerableQuery, f *model.VulnerableFile) string { return string(f.IssueType) },
+	"expected_value": func(_ *model.VulnerableQuery, f *model.VulnerableFile) string { return f.KeyExpectedValue },
+	"actual_value":   func(_ *model.VulnerableQuery, f *model.VulnerableFile) string { return f.KeyActualValue },
+	"similarity_id":  func(_ *model.VulnerableQuery, f *model.VulnerableFile) string { return f.SimilarityID },
+	"occurrences":    func(_ *model.VulnerableQuery, f *model.VulnerableFile) string { return strconv.Itoa(occurrences(f)) },
+}
+
+// csvColumns are the columns of the CSV reports, set with SetCSVColumns
+var csvColumns = DefaultCSVColumns
+
+// SetCSVColumns sets the columns of the CSV reports, the default columns are used when columns is empty
+func SetCSVColumns(columns []string) error {
+	if len(columns) == 0 {
+		csvColumns = DefaultCSVColumns
+		return nil
+	}
+	selected := make([]string, 0, len(columns))
+	for _, column := range columns {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if _, ok := csvColumnValues[column]; !ok {
+			return fmt.Errorf("CSV column not supported: %s, supported values: %s", column, strings.Join(CSVColumns(), ", "))
+		}
+		selected = append(selected, column)
+	}
+	csvColumns = selected
+	return nil
+}
+
+// CSVColumns returns the supported columns of the CSV reports
+func CSVColumns() []string {
+	return []string{
+		"query", "query_id", "query_url", "severity", "platform", "category", "description", "file", "line",
+		"resource", "issue_type", "expected_value", "actual_value", "similarity_id", "occurrences",
+	}
+}
+
+// PrintCSVReport creates a report file on CSV format, with a row for each result and a header with the columns
+func PrintCSVReport(path, filename string, body interface{}) error {
+	if !strings.HasSuffix(filename, ".csv") {
+		filename += ".csv"
+	}
+	var summary model.Summary
+	result, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(result, &summary); err != nil {
+		return err
+	}
+
+	fullPath := filepath.Join(path, filename)
+	_ = os.MkdirAll(path, os.ModePerm)
+	f, err := redactor.Create(fullPath, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer closeFile(fullPath, filename, f)
+
+	writer := csv.NewWriter(f)
+	if err := writer.Write(csvColumns); err != nil {
+		return err
+	}
+	row := make([]string, len(csvColumns))
+	for i := range summary.Queries {
+		query := &summary.Queries[i]
+		for j := range query.Files {
+			for k, column := range csvColumns {
+				row[k] = escapeFormula(csvColumnValues[column](query, &query.Files[j]))
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// escapeFormula prefixes the values that spreadsheets would evaluate as formulas with a quote,
+// since values come from the scanned files, numbers are kept as they are
+func escapeFormula(value string) string {
+	if _, err := strconv.Atoi(value); err == nil {
+		return value
+	}
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func occurrences(file *model.VulnerableFile) int {
+	if file.Occurrences > 0 {
+		return file.Occurrences
+	}
+	return 1
+}
@@ -0,0 +1,61 @@
+package report
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+var csvSummary = model.Summary{
+	Queries: model.VulnerableQuerySlice{
+		{
+			QueryName:   "ALB protocol is HTTP",
+			QueryID:     "de7f5e83-da88-4046-871f-ea18504b1d43",
+			Severity:    model.SeverityHigh,
+			Platform:    "Terraform",
+			Category:    "Encryption",
+			Description: "AWS Application Load Balancer (alb) should not listen on HTTP",
+			Files: []model.VulnerableFile{
+				{FileName: "positive.tf", Line: 25, SearchKey: "aws_alb_listener[front_end].default_action.redirect", KeyActualValue: "=HTTP"},
+				{FileName: "positive.tf", Line: 19, SearchKey: "aws_alb_listener[front_end].default_action", Occurrences: 3},
+			},
+		},
+	},
+}
+
+// TestPrintCSVReport tests the functions [PrintCSVReport()] and all the methods called by them
+func TestPrintCSVReport(t *testing.T) {
+	dir := t.TempDir()
+	readCSV := func(filename string) [][]string {
+		f, err := os.Open(filepath.Join(dir, filename))
+		require.NoError(t, err)
+		defer f.Close()
+		records, err := csv.NewReader(f).ReadAll()
+		require.NoError(t, err)
+		return records
+	}
+
+	require.NoError(t, PrintCSVReport(dir, "results", &csvSummary))
+	require.Equal(t, [][]string{
+		{"query", "severity", "file", "line", "resource", "description"},
+		{"ALB protocol is HTTP", "HIGH", "positive.tf", "25", "aws_alb_listener[front_end].default_action.redirect",
+			"AWS Application Load Balancer (alb) should not listen on HTTP"},
+		{"ALB protocol is HTTP", "HIGH", "positive.tf", "19", "aws_alb_listener[front_end].default_action",
+			"AWS Application Load Balancer (alb) should not listen on HTTP"},
+	}, readCSV("results.csv"))
+
+	require.NoError(t, SetCSVColumns([]string{"query_id", " Actual_Value", "occurrences"}))
+	defer SetCSVColumns(nil) //nolint:errcheck
+	require.NoError(t, PrintCSVReport(dir, "columns.csv", csvSummary))
+	require.Equal(t, [][]string{
+		{"query_id", "actual_value", "occurrences"},
+		{"de7f5e83-da88-4046-871f-ea18504b1d43", "'=HTTP", "1"},
+		{"de7f5e83-da88-4046-871f-ea18504b1d43", "", "3"},
+	}, readCSV("columns.csv"))
+
+	require.Error(t, SetCSVColumns([]string{"query", "owner"}))
+}
@@ -0,0 +1,59 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+)
+
+var githubAnnotationLevels = map[model.Severity]string{
+	model.SeverityHigh:   "error",
+	model.SeverityMedium: "warning",
+	model.SeverityLow:    "notice",
+	model.SeverityInfo:   "notice",
+}
+
+var (
+	githubDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	githubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+// PrintGitHubAnnotations writes the results as GitHub Actions workflow commands (::error file=...,line=...::message)
+// so they annotate the files of the workflow run, file names are made relative to root, the repository directory
+func PrintGitHubAnnotations(w io.Writer, summary *model.Summary, root string) error {
+	for i := range summary.Queries {
+		query := &summary.Queries[i]
+		level, ok := githubAnnotationLevels[query.Severity]
+		if !ok {
+			level = "notice"
+		}
+		title := fmt.Sprintf("[%s] %s", query.Severity, query.QueryName)
+		for j := range query.Files {
+			file := &query.Files[j]
+			properties := "file=" + githubPropertyEscaper.Replace(annotationFileName(file.FileName, root))
+			if file.Line > 0 {
+				properties += fmt.Sprintf(",line=%d", file.Line)
+			}
+			properties += ",title=" + githubPropertyEscaper.Replace(title)
+			message := fmt.Sprintf("%s\nExpected: %s\nActual: %s", query.Description, file.KeyExpectedValue, file.KeyActualValue)
+			if _, err := fmt.Fprintf(w, "::%s %s::%s\n", level, properties, githubDataEscaper.Replace(message)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// annotationFileName returns the file name relative to root, with forward slashes,
+// file names outside of root are returned as they are
+func annotationFileName(fileName, root string) string {
+	if root != "" {
+		if rel, err := filepath.Rel(root, fileName); err == nil && !strings.HasPrefix(rel, "..") {
+			fileName = rel
+		}
+	}
+	return filepath.ToSlash(fileName)
+}
@@ -0,0 +1,58 @@
+package report
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPrintGitHubAnnotations tests the functions [PrintGitHubAnnotations()] and all the methods called by them
+func TestPrintGitHubAnnotations(t *testing.T) {
+	root, err := filepath.Abs("repository")
+	require.NoError(t, err)
+	summary := &model.Summary{
+		Queries: []model.VulnerableQuery{
+			{
+				QueryName:   "ALB protocol is HTTP",
+				Severity:    model.SeverityHigh,
+				Description: "ALB protocol should be HTTPS",
+				Files: []model.VulnerableFile{
+					{
+						FileName:         filepath.Join(root, "terraform", "main.tf"),
+						Line:             25,
+						KeyExpectedValue: "'default_action.redirect.protocol' is equal 'HTTPS'",
+						KeyActualValue:   "'default_action.redirect.protocol' is missing",
+					},
+				},
+			},
+			{
+				QueryName:   "Healthcheck Instruction Missing, 100%",
+				Severity:    model.SeverityLow,
+				Description: "Ensure that HEALTHCHECK is being used",
+				Files: []model.VulnerableFile{
+					{
+						FileName:         "../other/Dockerfile",
+						Line:             -1,
+						KeyExpectedValue: "Dockerfile contains instruction 'HEALTHCHECK'",
+						KeyActualValue:   "Dockerfile doesn't contain instruction 'HEALTHCHECK'",
+					},
+				},
+			},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	require.NoError(t, PrintGitHubAnnotations(out, summary, root))
+	require.Equal(t,
+		"::error file=terraform/main.tf,line=25,title=[HIGH] ALB protocol is HTTP::ALB protocol should be HTTPS%0A"+
+			"Expected: 'default_action.redirect.protocol' is equal 'HTTPS'%0A"+
+			"Actual: 'default_action.redirect.protocol' is missing\n"+
+			"::notice file=../other/Dockerfile,title=[LOW] Healthcheck Instruction Missing%2C 100%25::"+
+			"Ensure that HEALTHCHECK is being used%0A"+
+			"Expected: Dockerfile contains instruction 'HEALTHCHECK'%0A"+
+			"Actual: Dockerfile doesn't contain instruction 'HEALTHCHECK'\n",
+		out.String())
+}
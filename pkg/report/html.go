@@ -4,6 +4,7 @@ import (
 	"bytes"
 	_ "embed" // used for embedding report static files
 	"html/template"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -55,21 +56,26 @@ func PrintHTMLReport(path, filename string, body interface{}) error {
 		filename += ".html"
 	}
 
-	templateFuncs["includeSVG"] = includeSVG
-	templateFuncs["includeCSS"] = includeCSS
-
 	fullPath := filepath.Join(path, filename)
-	t := template.Must(template.New("report.tmpl").Funcs(templateFuncs).Parse(htmlTemplate))
 
 	_ = os.MkdirAll(path, os.ModePerm)
-	f, err := os.OpenFile(filepath.Clean(fullPath), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
+	f, err := redactor.Create(fullPath, os.ModePerm)
 	if err != nil {
 		return err
 	}
 	defer closeFile(fullPath, filename, f)
+	return WriteHTMLReport(f, body)
+}
+
+// WriteHTMLReport writes the report on HTML format to w
+func WriteHTMLReport(w io.Writer, body interface{}) error {
+	templateFuncs["includeSVG"] = includeSVG
+	templateFuncs["includeCSS"] = includeCSS
+
+	t := template.Must(template.New("report.tmpl").Funcs(templateFuncs).Parse(htmlTemplate))
 	var buffer bytes.Buffer
 
-	err = t.Execute(&buffer, body)
+	err := t.Execute(&buffer, body)
 	if err != nil {
 		return err
 	}
@@ -81,7 +87,7 @@ func PrintHTMLReport(path, filename string, body interface{}) error {
 		KeepQuotes:       true,
 	})
 
-	minifierWriter := minifier.Writer("text/html", f)
+	minifierWriter := minifier.Writer("text/html", w)
 	defer minifierWriter.Close()
 
 	_, err = minifierWriter.Write(buffer.Bytes())
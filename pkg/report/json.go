@@ -15,7 +15,7 @@ func PrintJSONReport(path, filename string, body interface{}) error {
 	fullPath := filepath.Join(path, filename)
 	_ = os.MkdirAll(path, os.ModePerm)
 
-	f, err := os.OpenFile(filepath.Clean(fullPath), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
+	f, err := redactor.Create(fullPath, os.ModePerm)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,209 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	policyReportAPIVersion = "wgpolicyk8s.io/v1alpha2"
+	policyReportName       = "kics"
+	policyReportNamespace  = "default"
+)
+
+// clusterScopedKinds are the kinds of the Kubernetes resources without namespace,
+// their results are reported in the ClusterPolicyReport
+var clusterScopedKinds = map[string]bool{
+	"APIService":                     true,
+	"CSIDriver":                      true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CustomResourceDefinition":       true,
+	"IngressClass":                   true,
+	"MutatingWebhookConfiguration":   true,
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"PodSecurityPolicy":              true,
+	"PriorityClass":                  true,
+	"RuntimeClass":                   true,
+	"StorageClass":                   true,
+	"ValidatingWebhookConfiguration": true,
+	"VolumeAttachment":               true,
+}
+
+type policyReport struct {
+	APIVersion string               `yaml:"apiVersion"`
+	Kind       string               `yaml:"kind"`
+	Metadata   policyReportMetadata `yaml:"metadata"`
+	Summary    policyReportSummary  `yaml:"summary"`
+	Results    []policyReportResult `yaml:"results"`
+}
+
+type policyReportMetadata struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels"`
+}
+
+type policyReportSummary struct {
+	Pass  int `yaml:"pass"`
+	Fail  int `yaml:"fail"`
+	Warn  int `yaml:"warn"`
+	Error int `yaml:"error"`
+	Skip  int `yaml:"skip"`
+}
+
+type policyReportResult struct {
+	Source     string                 `yaml:"source"`
+	Policy     string                 `yaml:"policy"`
+	Rule       string                 `yaml:"rule"`
+	Category   string                 `yaml:"category,omitempty"`
+	Severity   string                 `yaml:"severity"`
+	Result     string                 `yaml:"result"`
+	Message    string                 `yaml:"message"`
+	Resources  []policyReportResource `yaml:"resources,omitempty"`
+	Properties map[string]string      `yaml:"properties"`
+}
+
+type policyReportResource struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Name       string `yaml:"name"`
+	Namespace  string `yaml:"namespace,omitempty"`
+}
+
+// PrintPolicyReport creates a report file with the results as Kubernetes PolicyReport resources, one for each namespace
+// of the resources of the results, the results of cluster scoped resources and of files that are not Kubernetes
+// manifests are in a ClusterPolicyReport
+func PrintPolicyReport(path, filename string, body interface{}) error {
+	if !strings.HasSuffix(filename, ".yaml") {
+		filename += "-policyreport.yaml"
+	}
+	var summary model.Summary
+	result, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(result, &summary); err != nil {
+		return err
+	}
+
+	fullPath := filepath.Join(path, filename)
+	_ = os.MkdirAll(path, os.ModePerm)
+	f, err := redactor.Create(fullPath, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	defer closeFile(fullPath, filename, f)
+
+	encoder := yaml.NewEncoder(f)
+	encoder.SetIndent(2)
+	for _, report := range newPolicyReports(&summary) {
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	}
+	return encoder.Close()
+}
+
+// newPolicyReports returns the PolicyReports of the namespaces, sorted by namespace, and the ClusterPolicyReport, if any
+func newPolicyReports(summary *model.Summary) []policyReport {
+	namespaces := make(map[string]*policyReport)
+	var cluster *policyReport
+	for i := range summary.Queries {
+		query := &summary.Queries[i]
+		for j := range query.Files {
+			file := &query.Files[j]
+			result := newPolicyReportResult(query, file)
+			resource := file.KubernetesResource
+			if resource == nil || clusterScopedKinds[resource.Kind] {
+				if cluster == nil {
+					cluster = newPolicyReport("ClusterPolicyReport", "")
+				}
+				cluster.add(&result)
+				continue
+			}
+			namespace := resource.Namespace
+			if namespace == "" {
+				namespace = policyReportNamespace
+			}
+			if namespaces[namespace] == nil {
+				namespaces[namespace] = newPolicyReport("PolicyReport", namespace)
+			}
+			result.Resources[0].Namespace = namespace
+			namespaces[namespace].add(&result)
+		}
+	}
+
+	reports := make([]policyReport, 0, len(namespaces)+1)
+	for _, report := range namespaces {
+		reports = append(reports, *report)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Metadata.Namespace < reports[j].Metadata.Namespace
+	})
+	if cluster != nil {
+		reports = append(reports, *cluster)
+	}
+	return reports
+}
+
+func newPolicyReport(kind, namespace string) *policyReport {
+	return &policyReport{
+		APIVersion: policyReportAPIVersion,
+		Kind:       kind,
+		Metadata: policyReportMetadata{
+			Name:      policyReportName,
+			Namespace: namespace,
+			Labels:    map[string]string{"app.kubernetes.io/managed-by": "kics"},
+		},
+		Results: []policyReportResult{},
+	}
+}
+
+func (r *policyReport) add(result *policyReportResult) {
+	r.Results = append(r.Results, *result)
+	r.Summary.Fail++
+}
+
+func newPolicyReportResult(query *model.VulnerableQuery, file *model.VulnerableFile) policyReportResult {
+	message := file.KeyActualValue
+	if message == "" {
+		message = query.Description
+	}
+	properties := map[string]string{
+		"file":          file.FileName,
+		"line":          strconv.Itoa(file.Line),
+		"similarity_id": file.SimilarityID,
+	}
+	if query.QueryURI != "" {
+		properties["query_url"] = query.QueryURI
+	}
+	result := policyReportResult{
+		Source:     "kics",
+		Policy:     query.QueryName,
+		Rule:       query.QueryID,
+		Category:   query.Category,
+		Severity:   strings.ToLower(string(query.Severity)),
+		Result:     "fail",
+		Message:    message,
+		Properties: properties,
+	}
+	if resource := file.KubernetesResource; resource != nil {
+		result.Resources = []policyReportResource{{
+			APIVersion: resource.APIVersion,
+			Kind:       resource.Kind,
+			Name:       resource.Name,
+			Namespace:  resource.Namespace,
+		}}
+	}
+	return result
+}
@@ -0,0 +1,99 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+var testPolicyReportSummary = model.Summary{
+	Queries: model.VulnerableQuerySlice{
+		{
+			QueryName:   "Privilege Escalation Allowed",
+			QueryID:     "5572cc5e-1e4c-4113-92a6-7a8a3bd25e6d",
+			QueryURI:    "https://kubernetes.io/docs/tasks/configure-pod-container/security-context/",
+			Severity:    model.SeverityHigh,
+			Category:    "Insecure Configurations",
+			Description: "Containers should not run with allowPrivilegeEscalation",
+			Files: []model.VulnerableFile{
+				{
+					FileName:           "app/deployment.yaml",
+					Line:               21,
+					SimilarityID:       "abc",
+					KeyActualValue:     "allowPrivilegeEscalation is true",
+					KubernetesResource: &model.KubernetesResource{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Namespace: "shop"},
+				},
+				{
+					FileName:           "app/job.yaml",
+					Line:               9,
+					KubernetesResource: &model.KubernetesResource{APIVersion: "batch/v1", Kind: "Job", Name: "migrate"},
+				},
+				{
+					FileName:           "app/rbac.yaml",
+					Line:               4,
+					KubernetesResource: &model.KubernetesResource{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Name: "admin"},
+				},
+				{FileName: "infra/main.tf", Line: 3},
+			},
+		},
+	},
+}
+
+// TestNewPolicyReports tests the functions [newPolicyReports()] and all the methods called by them
+func TestNewPolicyReports(t *testing.T) {
+	reports := newPolicyReports(&testPolicyReportSummary)
+	require.Len(t, reports, 3)
+
+	require.Equal(t, "PolicyReport", reports[0].Kind)
+	require.Equal(t, "default", reports[0].Metadata.Namespace)
+	require.Equal(t, 1, reports[0].Summary.Fail)
+	require.Equal(t, "default", reports[0].Results[0].Resources[0].Namespace)
+
+	require.Equal(t, "PolicyReport", reports[1].Kind)
+	require.Equal(t, "shop", reports[1].Metadata.Namespace)
+	require.Equal(t, policyReportResult{
+		Source:   "kics",
+		Policy:   "Privilege Escalation Allowed",
+		Rule:     "5572cc5e-1e4c-4113-92a6-7a8a3bd25e6d",
+		Category: "Insecure Configurations",
+		Severity: "high",
+		Result:   "fail",
+		Message:  "allowPrivilegeEscalation is true",
+		Resources: []policyReportResource{
+			{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Namespace: "shop"},
+		},
+		Properties: map[string]string{
+			"file":          "app/deployment.yaml",
+			"line":          "21",
+			"similarity_id": "abc",
+			"query_url":     "https://kubernetes.io/docs/tasks/configure-pod-container/security-context/",
+		},
+	}, reports[1].Results[0])
+
+	require.Equal(t, "ClusterPolicyReport", reports[2].Kind)
+	require.Empty(t, reports[2].Metadata.Namespace)
+	require.Equal(t, 2, reports[2].Summary.Fail)
+	require.Len(t, reports[2].Results[0].Resources, 1)
+	require.Empty(t, reports[2].Results[1].Resources)
+	require.Equal(t, "Containers should not run with allowPrivilegeEscalation", reports[2].Results[1].Message)
+
+	require.Empty(t, newPolicyReports(&model.Summary{}))
+}
+
+// TestPrintPolicyReport tests the functions [PrintPolicyReport()] and all the methods called by them
+func TestPrintPolicyReport(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, PrintPolicyReport(dir, "results", &testPolicyReportSummary))
+	content, err := os.ReadFile(filepath.Join(dir, "results-policyreport.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, 3, strings.Count(string(content), "apiVersion: wgpolicyk8s.io/v1alpha2"))
+	require.Contains(t, string(content), "kind: ClusterPolicyReport")
+
+	require.NoError(t, PrintPolicyReport(dir, "policy-reports.yaml", &model.Summary{}))
+	_, err = os.Stat(filepath.Join(dir, "policy-reports.yaml"))
+	require.NoError(t, err)
+}
@@ -25,6 +25,9 @@ func PrintSarifReport(path, filename string, body interface{}) error {
 	for idx := range summary.Queries {
 		sarifReport.BuildIssue(&summary.Queries[idx])
 	}
+	for idx := range summary.Suppressed {
+		sarifReport.BuildIssue(&summary.Suppressed[idx])
+	}
 
 	return PrintJSONReport(path, filename, sarifReport)
 }
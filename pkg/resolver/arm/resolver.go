@@ -0,0 +1,187 @@
+package arm
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DeploymentType is the ARM resource type used to declare nested and linked templates
+	DeploymentType = "Microsoft.Resources/deployments"
+	templateSchema = "deploymentTemplate.json"
+)
+
+// Resolver is an instance of the ARM resolver
+type Resolver struct {
+}
+
+// template keeps the information of a template being resolved, chain is its origin chain
+type template struct {
+	path     string
+	content  map[string]interface{}
+	original []byte
+	chain    []model.OriginStep
+}
+
+// Resolve will resolve the nested and linked deployments of the ARM templates in the directory
+// and return each deployment template ready for parsing, linked templates are only returned
+// when they are outside of the directory since otherwise they are already scanned
+func (r *Resolver) Resolve(filePath string) (model.ResolvedFiles, error) {
+	var rfiles = model.ResolvedFiles{}
+	templates, err := readTemplates(filePath)
+	if err != nil {
+		return model.ResolvedFiles{}, errors.Wrap(err, "failed to read ARM templates")
+	}
+
+	visited := make(map[string]bool)
+	for _, tmpl := range templates {
+		visited[filepath.Clean(tmpl.path)] = true
+	}
+	for _, tmpl := range templates {
+		tmpl.chain = []model.OriginStep{{Kind: model.OriginTemplate, FileName: tmpl.path}}
+		rfiles.File = append(rfiles.File, resolveDeployments(tmpl, tmpl.content, visited)...)
+	}
+	return rfiles, nil
+}
+
+// SupportedTypes returns the supported fileKinds for this resolver
+func (r *Resolver) SupportedTypes() []model.FileKind {
+	return []model.FileKind{model.KindARM}
+}
+
+// IsTemplate returns true if the document is an ARM deployment template
+func IsTemplate(document map[string]interface{}) bool {
+	schema, ok := document["$schema"].(string)
+	return ok && strings.Contains(schema, templateSchema)
+}
+
+// readTemplates reads all ARM deployment templates directly inside a directory
+func readTemplates(dir string) ([]template, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	templates := make([]template, 0)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		tmpl, err := readTemplate(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			log.Debug().Msgf("arm.readTemplates() skipping file %s: %s", entry.Name(), err)
+			continue
+		}
+		templates = append(templates, tmpl)
+	}
+	return templates, nil
+}
+
+func readTemplate(path string) (template, error) {
+	original, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return template{}, err
+	}
+	content := make(map[string]interface{})
+	if err := json.Unmarshal(original, &content); err != nil {
+		return template{}, err
+	}
+	if !IsTemplate(content) {
+		return template{}, errors.New("not an ARM deployment template")
+	}
+	return template{
+		path:     path,
+		content:  content,
+		original: []byte(strings.ReplaceAll(string(original), "\r", "")),
+	}, nil
+}
+
+// resolveDeployments returns the templates of every deployment declared in the resources of content,
+// inline templates are attributed to the file declaring them and linked templates to their own file
+func resolveDeployments(parent template, content map[string]interface{}, visited map[string]bool) []model.ResolvedFile {
+	rfiles := make([]model.ResolvedFile, 0)
+	for _, deployment := range getDeployments(content) {
+		properties, ok := deployment["properties"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if inline, ok := properties["template"].(map[string]interface{}); ok {
+			rendered, err := json.Marshal(inline)
+			if err != nil {
+				log.Err(err).Msgf("Failed to render nested template in file %s", parent.path)
+				continue
+			}
+			rfiles = append(rfiles, model.ResolvedFile{
+				FileName:     parent.path,
+				Content:      rendered,
+				OriginalData: parent.original,
+				Origin:       &model.Origin{Chain: model.ExtendChain(parent.chain, model.OriginNestedTemplate, parent.path)},
+			})
+			rfiles = append(rfiles, resolveDeployments(parent, inline, visited)...)
+			continue
+		}
+
+		link, ok := properties["templateLink"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		relativePath, ok := link["relativePath"].(string)
+		if !ok {
+			log.Debug().Msgf("arm.resolveDeployments() skipping remote linked template in file %s", parent.path)
+			continue
+		}
+		linkedPath := filepath.Clean(filepath.Join(filepath.Dir(parent.path), filepath.FromSlash(relativePath)))
+		// templates below the parent directory are scanned and resolved when the directory walk reaches them
+		if visited[linkedPath] || !isOutside(filepath.Dir(parent.path), linkedPath) {
+			continue
+		}
+		visited[linkedPath] = true
+
+		linked, err := readTemplate(linkedPath)
+		if err != nil {
+			log.Err(err).Msgf("Failed to resolve linked template %s in file %s", relativePath, parent.path)
+			continue
+		}
+		linked.chain = model.ExtendChain(parent.chain, model.OriginLinkedTemplate, linked.path)
+		rfiles = append(rfiles, model.ResolvedFile{
+			FileName:     linked.path,
+			Content:      linked.original,
+			OriginalData: linked.original,
+			Origin:       &model.Origin{Chain: linked.chain},
+		})
+		rfiles = append(rfiles, resolveDeployments(linked, linked.content, visited)...)
+	}
+	return rfiles
+}
+
+// isOutside returns true if path is not inside dir
+func isOutside(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err != nil || strings.HasPrefix(filepath.ToSlash(rel), "../")
+}
+
+// getDeployments returns the deployment resources of a template, including child resources
+func getDeployments(content map[string]interface{}) []map[string]interface{} {
+	resources, ok := content["resources"].([]interface{})
+	if !ok {
+		return nil
+	}
+	deployments := make([]map[string]interface{}, 0)
+	for _, res := range resources {
+		resource, ok := res.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if resourceType, ok := resource["type"].(string); ok && strings.EqualFold(resourceType, DeploymentType) {
+			deployments = append(deployments, resource)
+		}
+		deployments = append(deployments, getDeployments(resource)...)
+	}
+	return deployments
+}
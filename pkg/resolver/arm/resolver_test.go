@@ -0,0 +1,94 @@
+package arm
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArm_SupportedTypes(t *testing.T) {
+	res := &Resolver{}
+	want := []model.FileKind{model.KindARM}
+	t.Run("get_suported_type", func(t *testing.T) {
+		got := res.SupportedTypes()
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("SuportedTypes() = %v, want = %v", got, want)
+		}
+	})
+}
+
+// TestArm_Resolve tests the functions [Resolve()] and all the methods called by them
+func TestArm_Resolve(t *testing.T) {
+	res := &Resolver{}
+	mainPath := filepath.FromSlash("../../../test/fixtures/test_arm/main/azuredeploy.json")
+	linkedPath := filepath.FromSlash("../../../test/fixtures/test_arm/shared/network.json")
+
+	got, err := res.Resolve(filepath.FromSlash("../../../test/fixtures/test_arm/main"))
+	require.NoError(t, err)
+	require.Len(t, got.File, 2)
+
+	require.Equal(t, mainPath, got.File[0].FileName)
+	require.Contains(t, string(got.File[0].Content), "Microsoft.Storage/storageAccounts")
+	require.NotContains(t, string(got.File[0].Content), "Microsoft.Resources/deployments")
+	require.Contains(t, string(got.File[0].OriginalData), "nestedStorage")
+
+	require.Equal(t, linkedPath, got.File[1].FileName)
+	require.Contains(t, string(got.File[1].Content), "Microsoft.Network/networkSecurityGroups")
+	require.Equal(t, got.File[1].Content, got.File[1].OriginalData)
+	require.Equal(t, []model.OriginStep{
+		{Kind: model.OriginTemplate, FileName: mainPath},
+		{Kind: model.OriginNestedTemplate, FileName: mainPath},
+	}, got.File[0].Origin.GetChain())
+	require.Equal(t, []model.OriginStep{
+		{Kind: model.OriginTemplate, FileName: mainPath},
+		{Kind: model.OriginLinkedTemplate, FileName: linkedPath},
+	}, got.File[1].Origin.GetChain())
+}
+
+// TestArm_Resolve_NoTemplates tests the functions [Resolve()] for directories without ARM templates
+func TestArm_Resolve_NoTemplates(t *testing.T) {
+	res := &Resolver{}
+	got, err := res.Resolve(filepath.FromSlash("../../../test/fixtures/test_helm"))
+	require.NoError(t, err)
+	require.Empty(t, got.File)
+
+	_, err = res.Resolve(filepath.FromSlash("../../../test/fixtures/not_found"))
+	require.Error(t, err)
+}
+
+// TestIsTemplate tests the functions [IsTemplate()]
+func TestIsTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		document map[string]interface{}
+		want     bool
+	}{
+		{
+			name: "deployment_template",
+			document: map[string]interface{}{
+				"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+			},
+			want: true,
+		},
+		{
+			name: "parameters_file",
+			document: map[string]interface{}{
+				"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentParameters.json#",
+			},
+			want: false,
+		},
+		{
+			name:     "cloudformation_template",
+			document: map[string]interface{}{"AWSTemplateFormatVersion": "2010-09-09"},
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, IsTemplate(tt.document))
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package download
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Checkmarx/kics/pkg/httpclient"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	terraformRegistryHost = "registry.terraform.io"
+	terraformGetHeader    = "X-Terraform-Get"
+	requestTimeout        = 2 * time.Minute
+)
+
+// ErrOffline is returned when a remote module is not in the cache and offline mode is enabled
+var ErrOffline = errors.New("not available in cache while in offline mode")
+
+// Options configures how remote modules are downloaded
+// Proxy, CABundle and TLSMinVersion configure the HTTP client as described in httpclient.Options
+type Options struct {
+	CacheDir      string
+	Proxy         string
+	CABundle      string
+	TLSMinVersion string
+	Offline       bool
+	Registry      RegistryOptions
+}
+
+// RegistryOptions configures how charts are pulled from OCI registries
+// PlainHTTP uses HTTP instead of HTTPS, for local registries
+type RegistryOptions struct {
+	Username  string
+	Password  string
+	PlainHTTP bool
+}
+
+// SkippedModule is a remote module or chart dependency that was not downloaded and the reason why
+type SkippedModule struct {
+	Source string
+	Reason string
+}
+
+// Downloader downloads remote Terraform modules, Helm chart dependencies and charts stored in OCI registries
+// into a shared on-disk cache
+type Downloader struct {
+	cacheDir string
+	offline  bool
+	registry RegistryOptions
+	client   *http.Client
+	skipped  []SkippedModule
+	mutex    sync.Mutex
+}
+
+// DefaultCacheDir returns the default directory used to cache downloaded modules
+func DefaultCacheDir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	return filepath.Join(cacheDir, "kics", "modules")
+}
+
+// NewDownloader creates a Downloader using the given options
+func NewDownloader(opts Options) (*Downloader, error) {
+	cacheDir := opts.CacheDir
+	if cacheDir == "" {
+		cacheDir = DefaultCacheDir()
+	}
+	if err := os.MkdirAll(cacheDir, os.ModePerm); err != nil {
+		return nil, errors.Wrap(err, "failed to create download cache directory")
+	}
+
+	client, err := httpclient.New(httpclient.Options{
+		Proxy:         opts.Proxy,
+		CABundle:      opts.CABundle,
+		TLSMinVersion: opts.TLSMinVersion,
+		Timeout:       requestTimeout,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create download client")
+	}
+
+	return &Downloader{
+		cacheDir: cacheDir,
+		offline:  opts.Offline,
+		registry: opts.Registry,
+		client:   client,
+		skipped:  make([]SkippedModule, 0),
+	}, nil
+}
+
+// Download returns the path of the cached copy of the content of rawURL, downloading it when it is not cached yet,
+// it should be used for immutable content such as versioned archives
+func (d *Downloader) Download(rawURL string) (string, error) {
+	cachePath := d.cachePath(rawURL)
+	if _, err := os.Stat(cachePath); err == nil {
+		return cachePath, nil
+	}
+	if d.offline {
+		return "", errors.Wrapf(ErrOffline, "failed to download %s", rawURL)
+	}
+	if err := d.fetch(rawURL, cachePath); err != nil {
+		return "", err
+	}
+	return cachePath, nil
+}
+
+// Refresh returns the path of the content of rawURL downloading it again when online, the cached copy is used
+// in offline mode or when the download fails, it should be used for mutable content such as repository indexes
+func (d *Downloader) Refresh(rawURL string) (string, error) {
+	cachePath := d.cachePath(rawURL)
+	if !d.offline {
+		err := d.fetch(rawURL, cachePath)
+		if err == nil {
+			return cachePath, nil
+		}
+		log.Debug().Msgf("download.Refresh() using cached copy of %s: %s", rawURL, err)
+	}
+	return d.Download(rawURL)
+}
+
+// TerraformModuleURL resolves a Terraform registry module address (namespace/name/provider, optionally prefixed
+// by the registry host) with an exact version, or the latest one when version is empty, to its source address
+func (d *Downloader) TerraformModuleURL(source, version string) (string, error) {
+	parts := strings.Split(source, "/")
+	switch len(parts) {
+	case 3:
+		parts = append([]string{terraformRegistryHost}, parts...)
+	case 4:
+	default:
+		return "", errors.Errorf("invalid Terraform registry module address %s", source)
+	}
+
+	downloadURL := fmt.Sprintf("https://%s/v1/modules/%s/download", parts[0], strings.Join(parts[1:], "/"))
+	if version != "" {
+		downloadURL = fmt.Sprintf("https://%s/v1/modules/%s/%s/download", parts[0], strings.Join(parts[1:], "/"), version)
+	}
+
+	cachePath := d.cachePath(downloadURL)
+	if version != "" {
+		if content, err := os.ReadFile(filepath.Clean(cachePath)); err == nil {
+			return string(content), nil
+		}
+	}
+	if d.offline {
+		return "", errors.Wrapf(ErrOffline, "failed to resolve Terraform module %s", source)
+	}
+
+	resp, err := d.client.Get(downloadURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve Terraform module %s", source)
+	}
+	defer closeBody(resp)
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("failed to resolve Terraform module %s: %s", source, resp.Status)
+	}
+	location := resp.Header.Get(terraformGetHeader)
+	if location == "" {
+		return "", errors.Errorf("failed to resolve Terraform module %s: missing %s header", source, terraformGetHeader)
+	}
+	if location, err = resolveReference(resp.Request.URL, location); err != nil {
+		return "", errors.Wrapf(err, "failed to resolve Terraform module %s", source)
+	}
+	if version != "" {
+		if err := writeFile(cachePath, strings.NewReader(location)); err != nil {
+			log.Debug().Msgf("download.TerraformModuleURL() failed to cache %s: %s", source, err)
+		}
+	}
+	return location, nil
+}
+
+// Skip records a remote module that was not downloaded, to be reported at the end of the scan
+func (d *Downloader) Skip(source string, err error) {
+	log.Warn().Msgf("Skipping remote module %s: %s", source, err)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.skipped = append(d.skipped, SkippedModule{Source: source, Reason: err.Error()})
+}
+
+// Skipped returns the remote modules that were not downloaded
+func (d *Downloader) Skipped() []SkippedModule {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return append([]SkippedModule{}, d.skipped...)
+}
+
+func (d *Downloader) cachePath(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return filepath.Join(d.cacheDir, hex.EncodeToString(sum[:]))
+}
+
+func (d *Downloader) fetch(rawURL, cachePath string) error {
+	resp, err := d.client.Get(rawURL)
+	if err != nil {
+		return errors.Wrapf(err, "failed to download %s", rawURL)
+	}
+	defer closeBody(resp)
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed to download %s: %s", rawURL, resp.Status)
+	}
+	return errors.Wrapf(writeFile(cachePath, resp.Body), "failed to download %s", rawURL)
+}
+
+// writeFile writes the content to a temporary file renamed to path, so concurrent scans never read partial files
+func writeFile(path string, content io.Reader) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close() //nolint:errcheck,gosec
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func closeBody(resp *http.Response) {
+	if err := resp.Body.Close(); err != nil {
+		log.Err(err).Msg("Failed to close response body")
+	}
+}
+
+// resolveReference resolves a go-getter source address relative to the URL that returned it,
+// addresses with a forced getter (ex: git::https://...) are returned as they are
+func resolveReference(base *url.URL, location string) (string, error) {
+	if strings.Contains(location, "::") {
+		return location, nil
+	}
+	ref, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// ResolveURL resolves a possibly relative URL found in a remote document against the URL of the document
+func ResolveURL(baseURL, location string) (string, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	return resolveReference(base, location)
+}
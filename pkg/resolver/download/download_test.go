@@ -0,0 +1,158 @@
+package download
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestDownloader_Download tests the functions [Download()] and all the methods called by them
+func TestDownloader_Download(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/chart-1.0.0.tgz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("chart content"))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	downloader, err := NewDownloader(Options{CacheDir: cacheDir})
+	require.NoError(t, err)
+
+	path, err := downloader.Download(server.URL + "/chart-1.0.0.tgz")
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "chart content", string(content))
+
+	// cached content is not downloaded again
+	_, err = downloader.Download(server.URL + "/chart-1.0.0.tgz")
+	require.NoError(t, err)
+	require.Equal(t, 1, requests)
+
+	_, err = downloader.Download(server.URL + "/missing.tgz")
+	require.Error(t, err)
+
+	offlineDownloader, err := NewDownloader(Options{CacheDir: cacheDir, Offline: true})
+	require.NoError(t, err)
+	cachedPath, err := offlineDownloader.Download(server.URL + "/chart-1.0.0.tgz")
+	require.NoError(t, err)
+	require.Equal(t, path, cachedPath)
+	_, err = offlineDownloader.Refresh(server.URL + "/chart-1.0.0.tgz")
+	require.NoError(t, err)
+	_, err = offlineDownloader.Download(server.URL + "/chart-2.0.0.tgz")
+	require.ErrorIs(t, err, ErrOffline)
+	require.Equal(t, 2, requests)
+}
+
+// TestDownloader_TerraformModuleURL tests the functions [TerraformModuleURL()] and all the methods called by them
+func TestDownloader_TerraformModuleURL(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/modules/hashicorp/consul/aws/0.1.0/download":
+			w.Header().Set(terraformGetHeader, "git::https://github.com/hashicorp/terraform-aws-consul?ref=v0.1.0")
+		case "/v1/modules/hashicorp/consul/aws/download":
+			w.Header().Set(terraformGetHeader, "./archive/consul-0.2.0.tar.gz")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caBundle, certificate, 0600))
+
+	cacheDir := t.TempDir()
+	downloader, err := NewDownloader(Options{CacheDir: cacheDir, CABundle: caBundle})
+	require.NoError(t, err)
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	tests := []struct {
+		name    string
+		source  string
+		version string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "exact_version",
+			source:  host + "/hashicorp/consul/aws",
+			version: "0.1.0",
+			want:    "git::https://github.com/hashicorp/terraform-aws-consul?ref=v0.1.0",
+		},
+		{
+			name:   "latest_version",
+			source: host + "/hashicorp/consul/aws",
+			want:   server.URL + "/v1/modules/hashicorp/consul/aws/archive/consul-0.2.0.tar.gz",
+		},
+		{
+			name:    "unknown_module",
+			source:  host + "/hashicorp/unknown/aws",
+			version: "1.0.0",
+			wantErr: true,
+		},
+		{
+			name:    "invalid_address",
+			source:  "consul",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := downloader.TerraformModuleURL(tt.source, tt.version)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	offlineDownloader, err := NewDownloader(Options{CacheDir: cacheDir, Offline: true})
+	require.NoError(t, err)
+	got, err := offlineDownloader.TerraformModuleURL(host+"/hashicorp/consul/aws", "0.1.0")
+	require.NoError(t, err)
+	require.Equal(t, "git::https://github.com/hashicorp/terraform-aws-consul?ref=v0.1.0", got)
+	_, err = offlineDownloader.TerraformModuleURL(host+"/hashicorp/consul/aws", "")
+	require.ErrorIs(t, err, ErrOffline)
+}
+
+// TestDownloader_Skip tests the functions [Skip()] and [Skipped()]
+func TestDownloader_Skip(t *testing.T) {
+	downloader, err := NewDownloader(Options{CacheDir: t.TempDir(), Offline: true})
+	require.NoError(t, err)
+	require.Empty(t, downloader.Skipped())
+
+	downloader.Skip("https://charts.example.com/redis:1.0.0", ErrOffline)
+	require.Equal(t, []SkippedModule{
+		{Source: "https://charts.example.com/redis:1.0.0", Reason: ErrOffline.Error()},
+	}, downloader.Skipped())
+}
+
+// TestNewDownloader tests the functions [NewDownloader()] with invalid options
+func TestNewDownloader(t *testing.T) {
+	_, err := NewDownloader(Options{CacheDir: t.TempDir(), CABundle: filepath.Join(t.TempDir(), "missing.pem")})
+	require.Error(t, err)
+
+	invalidBundle := filepath.Join(t.TempDir(), "invalid.pem")
+	require.NoError(t, os.WriteFile(invalidBundle, []byte("invalid"), 0600))
+	_, err = NewDownloader(Options{CacheDir: t.TempDir(), CABundle: invalidBundle})
+	require.Error(t, err)
+
+	_, err = NewDownloader(Options{CacheDir: t.TempDir(), Proxy: "://invalid"})
+	require.Error(t, err)
+}
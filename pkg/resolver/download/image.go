@@ -0,0 +1,146 @@
+package download
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	dockerHubHost         = "docker.io"
+	dockerHubRegistryHost = "registry-1.docker.io"
+	defaultImageTag       = "latest"
+	contentDigestHeader   = "Docker-Content-Digest"
+)
+
+// imageManifestMediaTypes are the media types accepted for image manifests and for the indexes of multi-platform images
+var imageManifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	ociManifestMediaType,
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// ImageMetadata is the metadata of a container image in its registry
+// Digest is the digest of the manifest referenced by the tag, the one the image would be pinned to
+type ImageMetadata struct {
+	Digest       string
+	Created      time.Time
+	Architecture string
+	OS           string
+}
+
+type imageManifest struct {
+	Config    ociDescriptor `json:"config"`
+	Manifests []struct {
+		ociDescriptor
+		Platform struct {
+			Architecture string `json:"architecture"`
+			OS           string `json:"os"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
+type imageConfig struct {
+	Created      time.Time `json:"created"`
+	Architecture string    `json:"architecture"`
+	OS           string    `json:"os"`
+}
+
+// parseImageReference parses an image reference ([host/]repository[:tag][@digest]) as used by FROM instructions,
+// images without host are in Docker Hub and official Docker Hub images are in the library namespace
+func parseImageReference(image string) (*ociReference, error) {
+	if image == "" || strings.ContainsAny(image, "$ ") {
+		return nil, errors.Errorf("invalid image reference %s", image)
+	}
+	parsed := &ociReference{host: dockerHubHost, repository: image}
+	if idx := strings.Index(image, "/"); idx > 0 {
+		if host := image[:idx]; strings.ContainsAny(host, ".:") || host == "localhost" {
+			parsed.host, parsed.repository = host, image[idx+1:]
+		}
+	}
+	if at := strings.Index(parsed.repository, "@"); at >= 0 {
+		parsed.repository, parsed.reference = parsed.repository[:at], parsed.repository[at+1:]
+	} else if colon := strings.LastIndex(parsed.repository, ":"); colon >= 0 {
+		parsed.repository, parsed.reference = parsed.repository[:colon], parsed.repository[colon+1:]
+	}
+	if parsed.reference == "" {
+		parsed.reference = defaultImageTag
+	}
+	if parsed.host == dockerHubHost {
+		parsed.host = dockerHubRegistryHost
+		if !strings.Contains(parsed.repository, "/") {
+			parsed.repository = "library/" + parsed.repository
+		}
+	}
+	if parsed.repository == "" {
+		return nil, errors.Errorf("invalid image reference %s", image)
+	}
+	return parsed, nil
+}
+
+// ImageMetadata returns the digest, creation date, architecture and OS of the image referenced by image
+// (ex: alpine:3.13) from its registry, for multi-platform images the linux/amd64 image is described
+func (d *Downloader) ImageMetadata(image string) (*ImageMetadata, error) {
+	parsed, err := parseImageReference(image)
+	if err != nil {
+		return nil, err
+	}
+	if d.offline {
+		return nil, errors.Wrapf(ErrOffline, "failed to get metadata of image %s", image)
+	}
+
+	manifest, digest, err := d.imageManifest(parsed, parsed.reference)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get metadata of image %s", image)
+	}
+	if len(manifest.Manifests) > 0 {
+		platformDigest := manifest.Manifests[0].Digest
+		for i := range manifest.Manifests {
+			if manifest.Manifests[i].Platform.OS == "linux" && manifest.Manifests[i].Platform.Architecture == "amd64" {
+				platformDigest = manifest.Manifests[i].Digest
+				break
+			}
+		}
+		if manifest, _, err = d.imageManifest(parsed, platformDigest); err != nil {
+			return nil, errors.Wrapf(err, "failed to get metadata of image %s", image)
+		}
+	}
+
+	resp, err := d.registryGet(parsed, "blobs/"+manifest.Config.Digest, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get metadata of image %s", image)
+	}
+	defer closeBody(resp)
+	var config imageConfig
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(&config); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode configuration of image %s", image)
+	}
+	return &ImageMetadata{
+		Digest:       digest,
+		Created:      config.Created,
+		Architecture: config.Architecture,
+		OS:           config.OS,
+	}, nil
+}
+
+// imageManifest returns the manifest, or index, of the image and its digest
+func (d *Downloader) imageManifest(ref *ociReference, reference string) (*imageManifest, string, error) {
+	resp, err := d.registryGet(ref, "manifests/"+reference, strings.Join(imageManifestMediaTypes, ", "))
+	if err != nil {
+		return nil, "", err
+	}
+	defer closeBody(resp)
+	var manifest imageManifest
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(&manifest); err != nil {
+		return nil, "", errors.Wrap(err, "failed to decode manifest")
+	}
+	digest := resp.Header.Get(contentDigestHeader)
+	if digest == "" && strings.Contains(reference, ":") {
+		digest = reference
+	}
+	return &manifest, digest, nil
+}
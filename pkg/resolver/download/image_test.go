@@ -0,0 +1,79 @@
+package download
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestDownloader_ImageMetadata tests the functions [ImageMetadata()] and all the methods called by them
+func TestDownloader_ImageMetadata(t *testing.T) {
+	index := `{"schemaVersion":2,"manifests":[` +
+		`{"digest":"sha256:arm","platform":{"architecture":"arm64","os":"linux"}},` +
+		`{"digest":"sha256:amd","platform":{"architecture":"amd64","os":"linux"}}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/library/app/manifests/1.0":
+			w.Header().Set(contentDigestHeader, "sha256:index")
+			_, _ = w.Write([]byte(index))
+		case "/v2/library/app/manifests/sha256:amd":
+			_, _ = w.Write([]byte(`{"schemaVersion":2,"config":{"digest":"sha256:config"}}`))
+		case "/v2/library/app/blobs/sha256:config":
+			_, _ = w.Write([]byte(`{"created":"2021-01-02T03:04:05Z","architecture":"amd64","os":"linux"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	downloader, err := NewDownloader(Options{CacheDir: t.TempDir(), Registry: RegistryOptions{PlainHTTP: true}})
+	require.NoError(t, err)
+
+	metadata, err := downloader.ImageMetadata(host + "/library/app:1.0")
+	require.NoError(t, err)
+	require.Equal(t, &ImageMetadata{
+		Digest:       "sha256:index",
+		Created:      time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+		Architecture: "amd64",
+		OS:           "linux",
+	}, metadata)
+
+	_, err = downloader.ImageMetadata(host + "/library/missing:1.0")
+	require.Error(t, err)
+
+	offline, err := NewDownloader(Options{CacheDir: t.TempDir(), Offline: true})
+	require.NoError(t, err)
+	_, err = offline.ImageMetadata("alpine:3.13")
+	require.ErrorIs(t, err, ErrOffline)
+}
+
+// TestParseImageReference tests the functions [parseImageReference()] and all the methods called by them
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		image   string
+		want    *ociReference
+		wantErr bool
+	}{
+		{image: "alpine", want: &ociReference{host: dockerHubRegistryHost, repository: "library/alpine", reference: "latest"}},
+		{image: "bitnami/nginx:1.21", want: &ociReference{host: dockerHubRegistryHost, repository: "bitnami/nginx", reference: "1.21"}},
+		{image: "ghcr.io/org/app@sha256:abc", want: &ociReference{host: "ghcr.io", repository: "org/app", reference: "sha256:abc"}},
+		{image: "localhost:5000/app:dev", want: &ociReference{host: "localhost:5000", repository: "app", reference: "dev"}},
+		{image: "node:${NODE_VERSION}", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			got, err := parseImageReference(tt.image)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
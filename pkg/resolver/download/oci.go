@@ -0,0 +1,274 @@
+package download
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// OCIScheme is the scheme of the references to charts stored in OCI registries
+	OCIScheme = "oci://"
+
+	ociManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	maxManifestSize      = 4 * 1048576
+)
+
+// helmChartMediaTypes are the media types of the layer with the chart archive, the second one was used by helm before 3.7
+var helmChartMediaTypes = []string{
+	"application/vnd.cncf.helm.chart.content.v1.tar+gzip",
+	"application/tar+gzip",
+}
+
+var authParamRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// ociReference is a parsed OCI chart reference (oci://host/repository:tag or oci://host/repository@digest)
+type ociReference struct {
+	host       string
+	repository string
+	reference  string
+}
+
+type ociManifest struct {
+	Layers []ociDescriptor `json:"layers"`
+}
+
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+}
+
+// IsOCIReference returns true if ref references a chart stored in an OCI registry
+func IsOCIReference(ref string) bool {
+	return strings.HasPrefix(ref, OCIScheme)
+}
+
+func parseOCIReference(ref string) (*ociReference, error) {
+	trimmed := strings.TrimPrefix(ref, OCIScheme)
+	idx := strings.Index(trimmed, "/")
+	if !IsOCIReference(ref) || idx <= 0 {
+		return nil, errors.Errorf("invalid OCI reference %s", ref)
+	}
+	parsed := &ociReference{host: trimmed[:idx]}
+	repository := trimmed[idx+1:]
+	if at := strings.Index(repository, "@"); at >= 0 {
+		parsed.repository, parsed.reference = repository[:at], repository[at+1:]
+	} else if colon := strings.LastIndex(repository, ":"); colon >= 0 {
+		parsed.repository, parsed.reference = repository[:colon], repository[colon+1:]
+	}
+	if parsed.repository == "" || parsed.reference == "" {
+		return nil, errors.Errorf("invalid OCI reference %s, a tag or a digest is required", ref)
+	}
+	return parsed, nil
+}
+
+// isDigest returns true if the reference is a digest, whose content never changes
+func (r *ociReference) isDigest() bool {
+	return strings.Contains(r.reference, ":")
+}
+
+// archiveName returns the name of the pulled chart archive (ex: nginx-1.0.0.tgz)
+func (r *ociReference) archiveName() string {
+	reference := r.reference
+	if r.isDigest() {
+		reference = strings.SplitN(reference, ":", 2)[1]
+	}
+	return fmt.Sprintf("%s-%s.tgz", path.Base(r.repository), reference)
+}
+
+// PullChart pulls the chart referenced by ref (oci://host/repository:tag) from its OCI registry and returns the path
+// of the chart archive, charts referenced by tag are pulled again when online, as tags can be moved,
+// the cached copy is used in offline mode or when the pull fails
+func (d *Downloader) PullChart(ref string) (string, error) {
+	parsed, err := parseOCIReference(ref)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(ref))
+	cachePath := filepath.Join(d.cacheDir, hex.EncodeToString(sum[:]), parsed.archiveName())
+	_, statErr := os.Stat(cachePath)
+	if statErr == nil && (d.offline || parsed.isDigest()) {
+		return cachePath, nil
+	}
+	if d.offline {
+		return "", errors.Wrapf(ErrOffline, "failed to pull %s", ref)
+	}
+
+	if err := d.pullChart(parsed, cachePath); err != nil {
+		if statErr == nil {
+			log.Debug().Msgf("download.PullChart() using cached copy of %s: %s", ref, err)
+			return cachePath, nil
+		}
+		return "", errors.Wrapf(err, "failed to pull %s", ref)
+	}
+	return cachePath, nil
+}
+
+func (d *Downloader) pullChart(ref *ociReference, cachePath string) error {
+	resp, err := d.registryGet(ref, "manifests/"+ref.reference, ociManifestMediaType)
+	if err != nil {
+		return err
+	}
+	var manifest ociManifest
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(&manifest)
+	closeBody(resp)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode manifest")
+	}
+
+	layer, err := chartLayer(&manifest)
+	if err != nil {
+		return err
+	}
+	resp, err = d.registryGet(ref, "blobs/"+layer.Digest, "")
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	if err := os.MkdirAll(filepath.Dir(cachePath), os.ModePerm); err != nil {
+		return err
+	}
+	hash := sha256.New()
+	if err := writeFile(cachePath, io.TeeReader(resp.Body, hash)); err != nil {
+		return err
+	}
+	if digest := "sha256:" + hex.EncodeToString(hash.Sum(nil)); digest != layer.Digest {
+		os.Remove(cachePath) //nolint:errcheck,gosec
+		return errors.Errorf("chart digest mismatch, expected %s got %s", layer.Digest, digest)
+	}
+	return nil
+}
+
+func chartLayer(manifest *ociManifest) (*ociDescriptor, error) {
+	for _, mediaType := range helmChartMediaTypes {
+		for i := range manifest.Layers {
+			if manifest.Layers[i].MediaType == mediaType {
+				return &manifest.Layers[i], nil
+			}
+		}
+	}
+	return nil, errors.New("manifest has no helm chart layer")
+}
+
+// registryGet requests a resource of the repository in the registry, authenticating when the registry requires it,
+// with basic authentication or with a token obtained from the registry authorization service
+func (d *Downloader) registryGet(ref *ociReference, resource, accept string) (*http.Response, error) {
+	scheme := "https"
+	if d.registry.PlainHTTP {
+		scheme = "http"
+	}
+	resourceURL := fmt.Sprintf("%s://%s/v2/%s/%s", scheme, ref.host, ref.repository, resource)
+	resp, err := d.doRegistryRequest(resourceURL, accept, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		closeBody(resp)
+		authorization, err := d.registryAuthorization(challenge, ref)
+		if err != nil {
+			return nil, err
+		}
+		if resp, err = d.doRegistryRequest(resourceURL, accept, authorization); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		closeBody(resp)
+		return nil, errors.Errorf("failed to get %s: %s", resourceURL, resp.Status)
+	}
+	return resp, nil
+}
+
+func (d *Downloader) doRegistryRequest(resourceURL, accept, authorization string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, resourceURL, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := d.client.Do(req)
+	return resp, errors.Wrapf(err, "failed to get %s", resourceURL)
+}
+
+// registryAuthorization returns the Authorization header answering the challenge of the registry
+func (d *Downloader) registryAuthorization(challenge string, ref *ociReference) (string, error) {
+	scheme := strings.ToLower(strings.SplitN(challenge, " ", 2)[0])
+	switch scheme {
+	case "basic":
+		if d.registry.Username == "" {
+			return "", errors.Errorf("registry %s requires credentials", ref.host)
+		}
+		req := &http.Request{Header: make(http.Header)}
+		req.SetBasicAuth(d.registry.Username, d.registry.Password)
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+		token, err := d.registryToken(challenge, ref)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	default:
+		return "", errors.Errorf("registry %s authentication scheme not supported: %s", ref.host, challenge)
+	}
+}
+
+// registryToken requests a pull token to the authorization service given in the bearer challenge
+func (d *Downloader) registryToken(challenge string, ref *ociReference) (string, error) {
+	params := make(map[string]string)
+	for _, match := range authParamRegex.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(match[1])] = match[2]
+	}
+	if params["realm"] == "" {
+		return "", errors.Errorf("registry %s authentication challenge has no realm", ref.host)
+	}
+	query := url.Values{}
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull", ref.repository))
+
+	req, err := http.NewRequest(http.MethodGet, params["realm"]+"?"+query.Encode(), http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	if d.registry.Username != "" {
+		req.SetBasicAuth(d.registry.Username, d.registry.Password)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to authenticate to registry %s", ref.host)
+	}
+	defer closeBody(resp)
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("failed to authenticate to registry %s: %s", ref.host, resp.Status)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(&token); err != nil {
+		return "", errors.Wrapf(err, "failed to authenticate to registry %s", ref.host)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	return token.Token, nil
+}
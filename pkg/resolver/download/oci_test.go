@@ -0,0 +1,111 @@
+package download
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestDownloader_PullChart tests the functions [PullChart()] and all the methods called by them
+func TestDownloader_PullChart(t *testing.T) {
+	chart := []byte("chart archive")
+	sum := sha256.Sum256(chart)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	manifest := fmt.Sprintf(`{"schemaVersion":2,"layers":[`+
+		`{"mediaType":"application/vnd.cncf.helm.config.v1+json","digest":"sha256:0"},`+
+		`{"mediaType":"application/vnd.cncf.helm.chart.content.v1.tar+gzip","digest":"%s"}]}`, digest)
+
+	var server *httptest.Server
+	requests := 0
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			user, password, ok := r.BasicAuth()
+			if !ok || user != "user" || password != "secret" || r.URL.Query().Get("scope") != "repository:charts/nginx:pull" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"token":"pull-token"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer pull-token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		requests++
+		switch r.URL.Path {
+		case "/v2/charts/nginx/manifests/1.0.0", "/v2/charts/nginx/manifests/" + digest:
+			_, _ = w.Write([]byte(manifest))
+		case "/v2/charts/nginx/blobs/" + digest:
+			_, _ = w.Write(chart)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	cacheDir := t.TempDir()
+	downloader, err := NewDownloader(Options{
+		CacheDir: cacheDir,
+		Registry: RegistryOptions{Username: "user", Password: "secret", PlainHTTP: true},
+	})
+	require.NoError(t, err)
+
+	path, err := downloader.PullChart("oci://" + host + "/charts/nginx:1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, "nginx-1.0.0.tgz", filepath.Base(path))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, chart, content)
+	require.Equal(t, 2, requests)
+
+	// charts referenced by digest are not pulled again
+	_, err = downloader.PullChart("oci://" + host + "/charts/nginx@" + digest)
+	require.NoError(t, err)
+	_, err = downloader.PullChart("oci://" + host + "/charts/nginx@" + digest)
+	require.NoError(t, err)
+	require.Equal(t, 4, requests)
+
+	_, err = downloader.PullChart("oci://" + host + "/charts/missing:1.0.0")
+	require.Error(t, err)
+
+	anonymous, err := NewDownloader(Options{CacheDir: t.TempDir(), Registry: RegistryOptions{PlainHTTP: true}})
+	require.NoError(t, err)
+	_, err = anonymous.PullChart("oci://" + host + "/charts/nginx:1.0.0")
+	require.Error(t, err)
+
+	offlineDownloader, err := NewDownloader(Options{CacheDir: cacheDir, Offline: true})
+	require.NoError(t, err)
+	cachedPath, err := offlineDownloader.PullChart("oci://" + host + "/charts/nginx:1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, path, cachedPath)
+	_, err = offlineDownloader.PullChart("oci://" + host + "/charts/nginx:2.0.0")
+	require.ErrorIs(t, err, ErrOffline)
+}
+
+// TestParseOCIReference tests the functions [parseOCIReference()] and all the methods called by them
+func TestParseOCIReference(t *testing.T) {
+	ref, err := parseOCIReference("oci://registry.example.com:5000/charts/nginx:1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, &ociReference{host: "registry.example.com:5000", repository: "charts/nginx", reference: "1.0.0"}, ref)
+	require.Equal(t, "nginx-1.0.0.tgz", ref.archiveName())
+
+	ref, err = parseOCIReference("oci://registry.example.com/nginx@sha256:abc")
+	require.NoError(t, err)
+	require.True(t, ref.isDigest())
+	require.Equal(t, "nginx-abc.tgz", ref.archiveName())
+
+	for _, invalid := range []string{"registry.example.com/nginx:1.0.0", "oci://registry.example.com/nginx", "oci://nginx:1.0.0"} {
+		_, err = parseOCIReference(invalid)
+		require.Error(t, err, invalid)
+	}
+}
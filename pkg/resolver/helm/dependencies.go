@@ -0,0 +1,69 @@
+package helm
+
+import (
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/resolver/download"
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// addRemoteDependencies downloads the dependencies declared in Chart.yaml that are not vendored in the charts
+// directory and adds them to the chart, dependencies that can not be downloaded are reported as skipped
+func addRemoteDependencies(chartReq *chart.Chart, downloader *download.Downloader) {
+	if downloader == nil || chartReq.Metadata == nil {
+		return
+	}
+	loaded := make(map[string]bool)
+	for _, dep := range chartReq.Dependencies() {
+		loaded[dep.Name()] = true
+	}
+	for _, dep := range chartReq.Metadata.Dependencies {
+		if loaded[dep.Name] || !isRemoteRepository(dep.Repository) {
+			continue
+		}
+		depChart, err := downloadDependency(dep, downloader)
+		if err != nil {
+			downloader.Skip(dep.Repository+"/"+dep.Name+":"+dep.Version, err)
+			continue
+		}
+		addRemoteDependencies(depChart, downloader)
+		chartReq.AddDependency(depChart)
+		loaded[dep.Name] = true
+	}
+}
+
+func isRemoteRepository(repository string) bool {
+	return strings.HasPrefix(repository, "https://") || strings.HasPrefix(repository, "http://")
+}
+
+// downloadDependency downloads the chart of a dependency using the index of its repository
+func downloadDependency(dep *chart.Dependency, downloader *download.Downloader) (*chart.Chart, error) {
+	indexURL := strings.TrimSuffix(dep.Repository, "/") + "/index.yaml"
+	indexPath, err := downloader.Refresh(indexURL)
+	if err != nil {
+		return nil, err
+	}
+	index, err := repo.LoadIndexFile(indexPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load repository index %s", indexURL)
+	}
+	version, err := index.Get(dep.Name, dep.Version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find chart version in %s", indexURL)
+	}
+	if len(version.URLs) == 0 {
+		return nil, errors.Errorf("chart %s %s has no download URL in %s", dep.Name, version.Version, indexURL)
+	}
+	chartURL, err := download.ResolveURL(indexURL, version.URLs[0])
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid chart URL in %s", indexURL)
+	}
+	chartPath, err := downloader.Download(chartURL)
+	if err != nil {
+		return nil, err
+	}
+	return loader.Load(chartPath)
+}
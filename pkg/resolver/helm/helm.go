@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/Checkmarx/kics/pkg/resolver/download"
 	"github.com/pkg/errors"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
@@ -25,7 +26,7 @@ var (
 )
 
 func runInstall(args []string, client *action.Install,
-	valueOpts *values.Options) (*release.Release, error) {
+	valueOpts *values.Options, downloader *download.Downloader) (*release.Release, error) {
 	log.SetOutput(io.Discard)
 	defer log.SetOutput(os.Stderr)
 	if client.Version == "" && client.Devel {
@@ -55,6 +56,7 @@ func runInstall(args []string, client *action.Install,
 		return nil, err
 	}
 
+	addRemoteDependencies(chartRequested, downloader)
 	chartRequested = setID(chartRequested)
 
 	if err := checkIfInstallable(chartRequested); err != nil {
@@ -0,0 +1,44 @@
+package helm
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/postrender"
+)
+
+// execPostRenderer is a helm post-renderer that pipes the rendered manifests through an executable,
+// the same way 'helm install --post-renderer' does
+type execPostRenderer struct {
+	binaryPath string
+	args       []string
+}
+
+// NewPostRenderer returns a helm post-renderer running command with args, command can be
+// a path or the name of an executable in PATH, it receives the rendered manifests in stdin and must
+// write the modified manifests to stdout
+func NewPostRenderer(command string, args []string) (postrender.PostRenderer, error) {
+	binaryPath, err := exec.LookPath(command)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find helm post-renderer %s", command)
+	}
+	return &execPostRenderer{
+		binaryPath: binaryPath,
+		args:       args,
+	}, nil
+}
+
+// Run executes the post-renderer with the rendered manifests and returns its output
+func (p *execPostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	cmd := exec.Command(p.binaryPath, p.args...) //nolint:gosec
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = renderedManifests
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "helm post-renderer failed: %s", strings.TrimSpace(stderr.String()))
+	}
+	return &stdout, nil
+}
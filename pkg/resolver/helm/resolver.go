@@ -6,16 +6,28 @@ import (
 	"strings"
 
 	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/resolver/download"
 	"github.com/pkg/errors"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/postrender"
 	"helm.sh/helm/v3/pkg/release"
 )
 
 // Resolver is an instance of the helm resolver
+// Downloader is used to download the chart dependencies that are not vendored, when nil they are not rendered
+// PostRenderer, when set, modifies the rendered manifests before they are scanned (ex: kustomize patches)
+// ValueFiles are values files merged over the values of the charts, later files override earlier ones
 type Resolver struct {
+	Downloader   *download.Downloader
+	PostRenderer postrender.PostRenderer
+	ValueFiles   []string
 }
 
+// postRenderedFileName is the file the documents without a source template are attributed to,
+// they are added by the post-renderer or it dropped the "# Source:" comments
+const postRenderedFileName = "post-rendered.yaml"
+
 // splitManifest keeps the information of the manifest splitted by source
 type splitManifest struct {
 	path       string
@@ -23,23 +35,38 @@ type splitManifest struct {
 	original   []byte
 	splitID    string
 	splitIDMap map[int]interface{}
+	// postRendered is true for the documents without source template, see postRenderedSplit
+	postRendered bool
 }
 
-// Resolve will render the passed helm chart and return its content ready for parsing
+// Resolve will render the passed helm chart, a chart directory or a packaged chart (.tgz),
+// and return its content ready for parsing
 func (r *Resolver) Resolve(filePath string) (model.ResolvedFiles, error) {
 	var rfiles = model.ResolvedFiles{}
-	splits, err := renderHelm(filePath)
+	splits, err := renderHelm(filePath, r.Downloader, r.PostRenderer, r.ValueFiles)
 	if err != nil { // return error to be logged
 		return model.ResolvedFiles{}, errors.New("failed to render helm chart")
 	}
+	// the templates of packaged charts are attributed to their paths inside the archive
+	basePath := filepath.Dir(filePath)
+	if filepath.Ext(filePath) == ".tgz" {
+		basePath = filePath
+	}
 	for _, split := range *splits {
-		origpath := filepath.Join(filepath.Dir(filePath), split.path)
+		origpath := filepath.Join(basePath, split.path)
+		kind := model.OriginTemplate
+		if split.postRendered {
+			kind = model.OriginPostRenderer
+		}
 		rfiles.File = append(rfiles.File, model.ResolvedFile{
 			FileName:     origpath,
 			Content:      split.content,
 			OriginalData: split.original,
-			SplitID:      split.splitID,
-			IDInfo:       split.splitIDMap,
+			Origin: &model.Origin{
+				Chain:   []model.OriginStep{{Kind: model.OriginChart, FileName: filePath}, {Kind: kind, FileName: origpath}},
+				SplitID: split.splitID,
+				Lines:   split.splitIDMap,
+			},
 		})
 	}
 	return rfiles, nil
@@ -51,17 +78,20 @@ func (r *Resolver) SupportedTypes() []model.FileKind {
 }
 
 // renderHelm will use helm library to render helm charts
-func renderHelm(path string) (*[]splitManifest, error) {
+func renderHelm(path string, downloader *download.Downloader, postRenderer postrender.PostRenderer,
+	valueFiles []string) (*[]splitManifest, error) {
 	client := newClient()
-	manifest, err := runInstall([]string{path}, client, &values.Options{})
+	client.PostRenderer = postRenderer
+	manifest, err := runInstall([]string{path}, client, &values.Options{ValueFiles: valueFiles}, downloader)
 	if err != nil {
 		return nil, err
 	}
-	return splitManifestYAML(manifest)
+	return splitManifestYAML(manifest, postRenderer != nil)
 }
 
 // splitManifestYAML will split the rendered file and return its content by template as well as the template path
-func splitManifestYAML(template *release.Release) (*[]splitManifest, error) {
+// when postRendered is true the documents without source template are kept, see postRenderedSplit
+func splitManifestYAML(template *release.Release, postRendered bool) (*[]splitManifest, error) {
 	sources := make([]*chart.File, 0)
 	sources = updateName(sources, template.Chart, template.Chart.Name())
 	splitedManifest := []splitManifest{}
@@ -75,6 +105,12 @@ func splitManifestYAML(template *release.Release) (*[]splitManifest, error) {
 				break
 			}
 		}
+		if postRendered && !strings.HasPrefix(strings.TrimLeft(splited, "\n"), "# Source:") {
+			if split, ok := postRenderedSplit(template.Chart.Name(), splited, lineID); ok {
+				splitedManifest = append(splitedManifest, split)
+			}
+			continue
+		}
 		path := strings.Split(strings.TrimLeft(splited, "\n# Source:"), "\n") // get source of splitted yaml
 		// ignore auxiliary files used to render chart
 		if path[0] == "" {
@@ -98,6 +134,29 @@ func splitManifestYAML(template *release.Release) (*[]splitManifest, error) {
 	return &splitedManifest, nil
 }
 
+// postRenderedSplit returns the split of a document without source template, its original data is the document itself
+// since the template it comes from is unknown, empty documents are ignored
+func postRenderedSplit(chartName, document, lineID string) (splitManifest, bool) {
+	content := strings.ReplaceAll(document, "\r", "")
+	if strings.TrimSpace(content) == "" {
+		return splitManifest{}, false
+	}
+	split := splitManifest{
+		path:         filepath.Join(chartName, postRenderedFileName),
+		content:      []byte(content),
+		original:     []byte(strings.TrimLeft(content, "\n")),
+		splitID:      lineID,
+		postRendered: true,
+	}
+	if lineID != "" {
+		idMap, err := getIDMap(split.original)
+		if err == nil {
+			split.splitIDMap = idMap
+		}
+	}
+	return split, true
+}
+
 // toMap will convert to map original data having the path as it's key
 func toMap(files []*chart.File) map[string][]byte {
 	mapFiles := make(map[string][]byte)
@@ -3,6 +3,7 @@ package helm
 import (
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/Checkmarx/kics/pkg/model"
@@ -38,10 +39,19 @@ func TestHelm_Resolve(t *testing.T) { // nolint
 			want: model.ResolvedFiles{
 				File: []model.ResolvedFile{
 					{
-						SplitID:  "# KICS_HELM_ID_0:",
 						FileName: filepath.FromSlash("../../../test/fixtures/test_helm/templates/service.yaml"),
-						IDInfo: map[int]interface{}{0: map[int]int{0: 0, 1: 1, 2: 2, 3: 3, 4: 4,
-							5: 5, 6: 6, 7: 7, 8: 8, 9: 9, 10: 10, 11: 11, 12: 12, 13: 13, 14: 14, 15: 15, 16: 16}},
+						Origin: &model.Origin{
+							Chain: []model.OriginStep{
+								{Kind: model.OriginChart, FileName: filepath.FromSlash("../../../test/fixtures/test_helm")},
+								{
+									Kind:     model.OriginTemplate,
+									FileName: filepath.FromSlash("../../../test/fixtures/test_helm/templates/service.yaml"),
+								},
+							},
+							SplitID: "# KICS_HELM_ID_0:",
+							Lines: map[int]interface{}{0: map[int]int{0: 0, 1: 1, 2: 2, 3: 3, 4: 4,
+								5: 5, 6: 6, 7: 7, 8: 8, 9: 9, 10: 10, 11: 11, 12: 12, 13: 13, 14: 14, 15: 15, 16: 16}},
+						},
 						Content: []byte(`
 # Source: test_helm/templates/service.yaml
 # KICS_HELM_ID_0:
@@ -105,9 +115,18 @@ spec:
 				File: []model.ResolvedFile{
 					{
 						FileName: filepath.FromSlash("../../../test/fixtures/test_helm_subchart/templates/serviceaccount.yaml"),
-						SplitID:  "# KICS_HELM_ID_1:",
-						IDInfo: map[int]interface{}{1: map[int]int{1: 1, 2: 2, 3: 3, 4: 4,
-							5: 5, 6: 6, 7: 7, 8: 8, 9: 9, 10: 10, 11: 11, 12: 12, 13: 13}},
+						Origin: &model.Origin{
+							Chain: []model.OriginStep{
+								{Kind: model.OriginChart, FileName: filepath.FromSlash("../../../test/fixtures/test_helm_subchart")},
+								{
+									Kind:     model.OriginTemplate,
+									FileName: filepath.FromSlash("../../../test/fixtures/test_helm_subchart/templates/serviceaccount.yaml"),
+								},
+							},
+							SplitID: "# KICS_HELM_ID_1:",
+							Lines: map[int]interface{}{1: map[int]int{1: 1, 2: 2, 3: 3, 4: 4,
+								5: 5, 6: 6, 7: 7, 8: 8, 9: 9, 10: 10, 11: 11, 12: 12, 13: 13}},
+						},
 						Content: []byte(`
 # Source: test_helm_subchart/templates/serviceaccount.yaml
 # KICS_HELM_ID_1:
@@ -139,9 +158,18 @@ metadata:
 					},
 					{
 						FileName: filepath.FromSlash("../../../test/fixtures/test_helm_subchart/charts/subchart/templates/service.yaml"),
-						SplitID:  "# KICS_HELM_ID_0:",
-						IDInfo: map[int]interface{}{0: map[int]int{0: 0, 1: 1, 2: 2, 3: 3, 4: 4,
-							5: 5, 6: 6, 7: 7, 8: 8, 9: 9, 10: 10, 11: 11, 12: 12, 13: 13, 14: 14, 15: 15, 16: 16}},
+						Origin: &model.Origin{
+							Chain: []model.OriginStep{
+								{Kind: model.OriginChart, FileName: filepath.FromSlash("../../../test/fixtures/test_helm_subchart")},
+								{
+									Kind:     model.OriginTemplate,
+									FileName: filepath.FromSlash("../../../test/fixtures/test_helm_subchart/charts/subchart/templates/service.yaml"),
+								},
+							},
+							SplitID: "# KICS_HELM_ID_0:",
+							Lines: map[int]interface{}{0: map[int]int{0: 0, 1: 1, 2: 2, 3: 3, 4: 4,
+								5: 5, 6: 6, 7: 7, 8: 8, 9: 9, 10: 10, 11: 11, 12: 12, 13: 13, 14: 14, 15: 15, 16: 16}},
+						},
 						Content: []byte(`
 # Source: test_helm_subchart/charts/subchart/templates/service.yaml
 # KICS_HELM_ID_0:
@@ -202,3 +230,60 @@ spec:
 		})
 	}
 }
+
+func TestHelm_ResolvePostRenderer(t *testing.T) {
+	// the post-renderer drops the source comments, so the documents can't be attributed to their templates
+	postRenderer, err := NewPostRenderer("sed", []string{"/^# Source:/d"})
+	if err != nil {
+		t.Skipf("sed is not available: %v", err)
+	}
+	res := &Resolver{PostRenderer: postRenderer}
+	got, err := res.Resolve(filepath.FromSlash("../../../test/fixtures/test_helm"))
+	if err != nil {
+		t.Fatalf("Resolve() = %v", err)
+	}
+	if len(got.File) != 1 {
+		t.Fatalf("Resolve() returned %d files, want = 1", len(got.File))
+	}
+	file := got.File[0]
+	wantName := filepath.FromSlash("../../../test/fixtures/test_helm/test_helm/post-rendered.yaml")
+	if file.FileName != wantName {
+		t.Errorf("Resolve() FileName = %s, want = %s", file.FileName, wantName)
+	}
+	if file.Origin.GetSplitID() != "# KICS_HELM_ID_0:" {
+		t.Errorf("Resolve() SplitID = %s, want = # KICS_HELM_ID_0:", file.Origin.GetSplitID())
+	}
+	if chain := file.Origin.GetChain(); len(chain) != 2 || chain[1].Kind != model.OriginPostRenderer {
+		t.Errorf("Resolve() Origin.Chain = %v, want a post-renderer origin", chain)
+	}
+	if strings.Contains(string(file.Content), "# Source:") || !strings.Contains(string(file.Content), "kind: Service") {
+		t.Errorf("Resolve() Content = %s, want the post-rendered service", file.Content)
+	}
+	if !reflect.DeepEqual(strings.TrimLeft(string(file.Content), "\n"), string(file.OriginalData)) {
+		t.Errorf("Resolve() OriginalData = %s, want the post-rendered document", file.OriginalData)
+	}
+}
+
+func TestHelm_ResolvePackage(t *testing.T) {
+	res := &Resolver{}
+	got, err := res.Resolve(filepath.FromSlash("../../../test/fixtures/test_helm_package/test_helm-0.1.0.tgz"))
+	if err != nil {
+		t.Fatalf("Resolve() = %v", err)
+	}
+	if len(got.File) != 1 {
+		t.Fatalf("Resolve() returned %d files, want = 1", len(got.File))
+	}
+	wantName := filepath.FromSlash("../../../test/fixtures/test_helm_package/test_helm-0.1.0.tgz/test_helm/templates/service.yaml")
+	if got.File[0].FileName != wantName {
+		t.Errorf("Resolve() FileName = %s, want = %s", got.File[0].FileName, wantName)
+	}
+	if got.File[0].Origin.GetSplitID() != "# KICS_HELM_ID_0:" {
+		t.Errorf("Resolve() SplitID = %s, want = # KICS_HELM_ID_0:", got.File[0].Origin.GetSplitID())
+	}
+}
+
+func TestHelm_NewPostRenderer(t *testing.T) {
+	if _, err := NewPostRenderer("kics-missing-post-renderer", nil); err == nil {
+		t.Errorf("NewPostRenderer() expected an error for a missing executable")
+	}
+}
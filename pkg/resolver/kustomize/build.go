@@ -0,0 +1,210 @@
+package kustomize
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// kustomization is the part of a kustomization file used to build the resources
+type kustomization struct {
+	Resources             []string          `yaml:"resources"`
+	Bases                 []string          `yaml:"bases"`
+	Components            []string          `yaml:"components"`
+	Namespace             string            `yaml:"namespace"`
+	NamePrefix            string            `yaml:"namePrefix"`
+	NameSuffix            string            `yaml:"nameSuffix"`
+	CommonLabels          map[string]string `yaml:"commonLabels"`
+	Labels                []labels          `yaml:"labels"`
+	CommonAnnotations     map[string]string `yaml:"commonAnnotations"`
+	PatchesStrategicMerge []string          `yaml:"patchesStrategicMerge"`
+	Patches               []patch           `yaml:"patches"`
+	PatchesJSON6902       []patch           `yaml:"patchesJson6902"`
+	ConfigMapGenerator    []generator       `yaml:"configMapGenerator"`
+	SecretGenerator       []generator       `yaml:"secretGenerator"`
+	GeneratorOptions      *generatorOptions `yaml:"generatorOptions"`
+	Images                []image           `yaml:"images"`
+	Replicas              []replica         `yaml:"replicas"`
+}
+
+// builder builds the kustomizations, visiting holds the directories being built, to detect cycles
+type builder struct {
+	visiting map[string]bool
+}
+
+// build builds the kustomization of the directory over the inherited resources, which are the resources of the
+// kustomization using it for components, chain is the origin chain of the kustomization
+func (b *builder) build(dir string, chain []model.OriginStep, inherited []*resource) ([]*resource, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if b.visiting[absDir] {
+		return nil, errors.Errorf("cycle of kustomizations in %s", dir)
+	}
+	b.visiting[absDir] = true
+	defer delete(b.visiting, absDir)
+
+	kustomizationFile := KustomizationFile(dir)
+	if kustomizationFile == "" {
+		return nil, errors.Errorf("no kustomization found in %s", dir)
+	}
+	content, err := os.ReadFile(filepath.Clean(kustomizationFile))
+	if err != nil {
+		return nil, err
+	}
+	k := kustomization{}
+	if err := yaml.Unmarshal(content, &k); err != nil {
+		return nil, errors.Wrapf(err, "invalid kustomization %s", kustomizationFile)
+	}
+	file := kustomizationSource{
+		path:     kustomizationFile,
+		original: []byte(strings.ReplaceAll(string(content), "\r", "")),
+		chain:    chain,
+	}
+
+	resources := inherited
+	for _, entry := range append(append([]string{}, k.Resources...), k.Bases...) {
+		loaded, err := b.load(dir, entry, chain)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, loaded...)
+	}
+	if resources, err = generate(dir, &file, &k, resources); err != nil {
+		return nil, err
+	}
+	for _, component := range k.Components {
+		if isRemote(component) {
+			log.Debug().Msgf("kustomize.build() skipping remote component %s of %s", component, kustomizationFile)
+			continue
+		}
+		componentDir := filepath.Join(dir, filepath.FromSlash(component))
+		componentFile := KustomizationFile(componentDir)
+		if componentFile == "" {
+			return nil, errors.Errorf("no kustomization found in component %s", componentDir)
+		}
+		if resources, err = b.build(componentDir, model.ExtendChain(chain, model.OriginBase, componentFile), resources); err != nil {
+			return nil, err
+		}
+	}
+	if resources, err = applyPatches(dir, &k, resources); err != nil {
+		return nil, err
+	}
+	transform(&k, resources)
+	return resources, nil
+}
+
+// load returns the resources of an entry of the resources of a kustomization, a file of resources or the directory
+// of a base, remote resources are skipped
+func (b *builder) load(dir, entry string, chain []model.OriginStep) ([]*resource, error) {
+	if isRemote(entry) {
+		log.Debug().Msgf("kustomize.load() skipping remote resource %s", entry)
+		return nil, nil
+	}
+	path := filepath.Join(dir, filepath.FromSlash(entry))
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load resource %s", entry)
+	}
+	if !info.IsDir() {
+		return readResources(path, model.ExtendChain(chain, model.OriginTemplate, path))
+	}
+	kustomizationFile := KustomizationFile(path)
+	if kustomizationFile == "" {
+		return nil, errors.Errorf("no kustomization found in %s", path)
+	}
+	return b.build(path, model.ExtendChain(chain, model.OriginBase, kustomizationFile), nil)
+}
+
+// readResources reads the resources of a file, each document of the file is a resource, or several for lists
+func readResources(path string, chain []model.OriginStep) ([]*resource, error) {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	original := []byte(strings.ReplaceAll(string(content), "\r", ""))
+	documents, err := splitDocuments(original)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read resources of %s", path)
+	}
+	resources := make([]*resource, 0, len(documents))
+	for _, document := range documents {
+		objects := []map[string]interface{}{document.object}
+		// lists are expanded to their items, which are attributed to the list
+		if items, ok := document.object["items"].([]interface{}); ok && strings.HasSuffix(kindOf(document.object), "List") {
+			objects = objects[:0]
+			for _, item := range items {
+				if object, ok := item.(map[string]interface{}); ok {
+					objects = append(objects, object)
+				}
+			}
+		}
+		for _, object := range objects {
+			res := &resource{
+				object:   object,
+				path:     path,
+				original: original,
+				lines:    document.lines,
+				chain:    chain,
+			}
+			res.name = res.currentName()
+			resources = append(resources, res)
+		}
+	}
+	return resources, nil
+}
+
+// document is a document of a file of resources, lines is its range of lines [start, end) in the file
+type document struct {
+	object map[string]interface{}
+	lines  []int
+}
+
+// splitDocuments splits the content by the "---" separators, empty documents and documents that are not objects are ignored
+func splitDocuments(content []byte) ([]document, error) {
+	lines := strings.Split(string(content), "\n")
+	documents := make([]document, 0)
+	start := 0
+	for i := 0; i <= len(lines); i++ {
+		if i < len(lines) && !isSeparator(lines[i]) {
+			continue
+		}
+		var parsed interface{}
+		if err := yaml.Unmarshal([]byte(strings.Join(lines[start:i], "\n")), &parsed); err != nil {
+			return nil, err
+		}
+		if object, ok := parsed.(map[string]interface{}); ok && len(object) > 0 {
+			documents = append(documents, document{object: object, lines: []int{start, i}})
+		}
+		start = i + 1
+	}
+	return documents, nil
+}
+
+func isSeparator(line string) bool {
+	return line == "---" || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "---\t")
+}
+
+// isRemote returns true if the entry is a remote resource (ex: a git repository or an URL)
+func isRemote(entry string) bool {
+	return strings.Contains(entry, "://") || strings.HasPrefix(entry, "git@") ||
+		strings.HasPrefix(entry, "github.com/") || strings.Contains(entry, "?ref=")
+}
+
+func kindOf(object map[string]interface{}) string {
+	kind, _ := object["kind"].(string)
+	return kind
+}
+
+// kustomizationSource is the kustomization file being built, the resources it generates are attributed to it
+type kustomizationSource struct {
+	path     string
+	original []byte
+	chain    []model.OriginStep
+}
@@ -0,0 +1,225 @@
+package kustomize
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	behaviorCreate  = "create"
+	behaviorMerge   = "merge"
+	behaviorReplace = "replace"
+)
+
+// generator is an entry of the ConfigMap and Secret generators of a kustomization
+type generator struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace"`
+	Behavior  string            `yaml:"behavior"`
+	Type      string            `yaml:"type"`
+	Literals  []string          `yaml:"literals"`
+	Files     []string          `yaml:"files"`
+	Envs      []string          `yaml:"envs"`
+	Env       string            `yaml:"env"`
+	Options   *generatorOptions `yaml:"options"`
+}
+
+// generatorOptions are the labels and annotations added to the resources generated
+type generatorOptions struct {
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+// generate adds the ConfigMaps and Secrets generated by the kustomization to the resources, or merges them with the
+// resources of the bases, depending on their behavior, the resources generated are attributed to the entries
+// of the generators of the kustomization file
+func generate(dir string, file *kustomizationSource, k *kustomization, resources []*resource) ([]*resource, error) {
+	var err error
+	configMapLines := getEntryLines(file.original, "configMapGenerator")
+	for i := range k.ConfigMapGenerator {
+		if resources, err = applyGenerator(dir, file, k, &k.ConfigMapGenerator[i], "ConfigMap", configMapLines[i],
+			resources); err != nil {
+			return nil, err
+		}
+	}
+	secretLines := getEntryLines(file.original, "secretGenerator")
+	for i := range k.SecretGenerator {
+		if resources, err = applyGenerator(dir, file, k, &k.SecretGenerator[i], "Secret", secretLines[i],
+			resources); err != nil {
+			return nil, err
+		}
+	}
+	return resources, nil
+}
+
+func applyGenerator(dir string, file *kustomizationSource, k *kustomization, g *generator, kind string, lines []int,
+	resources []*resource) ([]*resource, error) {
+	if g.Name == "" {
+		return nil, errors.Errorf("%s generator without name in %s", kind, file.path)
+	}
+	data, err := getGeneratorData(dir, g)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to generate %s %s", kind, g.Name)
+	}
+	if kind == "Secret" {
+		for key, value := range data {
+			data[key] = base64.StdEncoding.EncodeToString([]byte(value.(string)))
+		}
+	}
+
+	switch g.Behavior {
+	case "", behaviorCreate:
+	case behaviorMerge, behaviorReplace:
+		for _, res := range resources {
+			if res.kind() != kind || (res.currentName() != g.Name && res.name != g.Name) ||
+				(g.Namespace != "" && res.namespace() != g.Namespace) {
+				continue
+			}
+			existing, _ := res.object["data"].(map[string]interface{})
+			if g.Behavior == behaviorReplace || existing == nil {
+				existing = make(map[string]interface{})
+			}
+			for key, value := range data {
+				existing[key] = value
+			}
+			res.object["data"] = existing
+			setGeneratorOptions(res, k.GeneratorOptions, g.Options)
+			return resources, nil
+		}
+		return nil, errors.Errorf("%s %s to %s not found", kind, g.Name, g.Behavior)
+	default:
+		return nil, errors.Errorf("invalid behavior %s of %s %s", g.Behavior, kind, g.Name)
+	}
+
+	metadata := map[string]interface{}{"name": g.Name}
+	if g.Namespace != "" {
+		metadata["namespace"] = g.Namespace
+	}
+	object := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       kind,
+		"metadata":   metadata,
+		"data":       data,
+	}
+	if kind == "Secret" {
+		object["type"] = "Opaque"
+		if g.Type != "" {
+			object["type"] = g.Type
+		}
+	}
+	res := &resource{
+		object:   object,
+		path:     file.path,
+		original: file.original,
+		lines:    lines,
+		chain:    model.ExtendChain(file.chain, model.OriginTemplate, file.path),
+		name:     g.Name,
+	}
+	setGeneratorOptions(res, k.GeneratorOptions, g.Options)
+	return append(resources, res), nil
+}
+
+// getGeneratorData returns the data of the literals, files and env files of the generator
+func getGeneratorData(dir string, g *generator) (map[string]interface{}, error) {
+	data := make(map[string]interface{})
+	for _, literal := range g.Literals {
+		key, value, ok := splitPair(literal)
+		if !ok {
+			return nil, errors.Errorf("invalid literal %s", literal)
+		}
+		data[key] = strings.Trim(value, `"'`)
+	}
+	for _, entry := range g.Files {
+		key, path, ok := splitPair(entry)
+		if !ok {
+			key, path = filepath.Base(filepath.FromSlash(entry)), entry
+		}
+		content, err := os.ReadFile(filepath.Clean(filepath.Join(dir, filepath.FromSlash(path))))
+		if err != nil {
+			return nil, err
+		}
+		data[key] = string(content)
+	}
+	envs := g.Envs
+	if g.Env != "" {
+		envs = append(envs, g.Env)
+	}
+	for _, path := range envs {
+		content, err := os.ReadFile(filepath.Clean(filepath.Join(dir, filepath.FromSlash(path))))
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			key, value, ok := splitPair(line)
+			if !ok {
+				return nil, errors.Errorf("invalid line %s of env file %s", line, path)
+			}
+			data[key] = value
+		}
+	}
+	return data, nil
+}
+
+func setGeneratorOptions(res *resource, options ...*generatorOptions) {
+	for _, option := range options {
+		if option == nil {
+			continue
+		}
+		setMetadataValues(res.metadata(), "labels", option.Labels)
+		setMetadataValues(res.metadata(), "annotations", option.Annotations)
+	}
+}
+
+func splitPair(pair string) (key, value string, ok bool) {
+	parts := strings.SplitN(pair, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// getEntryLines returns the range of lines [start, end) of each entry of a list of the kustomization file
+// (ex: configMapGenerator), the lines of the entries missing are nil
+func getEntryLines(content []byte, field string) map[int][]int {
+	entries := make(map[int][]int)
+	root := yaml.Node{}
+	if err := yaml.Unmarshal(content, &root); err != nil || len(root.Content) == 0 {
+		return entries
+	}
+	mapping := root.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return entries
+	}
+	total := len(strings.Split(string(content), "\n"))
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != field || mapping.Content[i+1].Kind != yaml.SequenceNode {
+			continue
+		}
+		end := total
+		if i+2 < len(mapping.Content) {
+			end = mapping.Content[i+2].Line - 1
+		}
+		items := mapping.Content[i+1].Content
+		for j := range items {
+			itemEnd := end
+			if j+1 < len(items) {
+				itemEnd = items[j+1].Line - 1
+			}
+			entries[j] = []int{items[j].Line - 1, itemEnd}
+		}
+	}
+	return entries
+}
@@ -0,0 +1,528 @@
+package kustomize
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	patchDirective = "$patch"
+	patchDelete    = "delete"
+	patchReplace   = "replace"
+)
+
+// patch is a patch of a kustomization, a strategic merge patch or a JSON 6902 patch, read from path or inline
+type patch struct {
+	Path   string  `yaml:"path"`
+	Patch  string  `yaml:"patch"`
+	Target *target `yaml:"target"`
+}
+
+// target selects the resources patched, names, kinds and namespaces are regular expressions
+type target struct {
+	Group         string `yaml:"group"`
+	Version       string `yaml:"version"`
+	Kind          string `yaml:"kind"`
+	Name          string `yaml:"name"`
+	Namespace     string `yaml:"namespace"`
+	LabelSelector string `yaml:"labelSelector"`
+}
+
+// mergeKeys are the keys identifying the elements of the lists merged by strategic merge patches, by list name,
+// the elements of the other lists are replaced by the patch
+var mergeKeys = map[string]string{
+	"containers":          "name",
+	"initContainers":      "name",
+	"ephemeralContainers": "name",
+	"env":                 "name",
+	"volumes":             "name",
+	"imagePullSecrets":    "name",
+	"volumeMounts":        "mountPath",
+	"volumeDevices":       "devicePath",
+	"hostAliases":         "ip",
+}
+
+// applyPatches applies the patches of the kustomization to the resources, in the order kustomize applies them
+func applyPatches(dir string, k *kustomization, resources []*resource) ([]*resource, error) {
+	var err error
+	for _, entry := range k.PatchesStrategicMerge {
+		content := entry
+		if data, readErr := os.ReadFile(filepath.Clean(filepath.Join(dir, filepath.FromSlash(entry)))); readErr == nil {
+			content = string(data)
+		}
+		if resources, err = applyPatch(content, nil, resources); err != nil {
+			return nil, errors.Wrapf(err, "failed to apply patch %s", entry)
+		}
+	}
+	for _, p := range append(append([]patch{}, k.Patches...), k.PatchesJSON6902...) {
+		content, name := p.Patch, "inline"
+		if p.Path != "" {
+			name = p.Path
+			data, err := os.ReadFile(filepath.Clean(filepath.Join(dir, filepath.FromSlash(p.Path))))
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read patch %s", p.Path)
+			}
+			content = string(data)
+		}
+		if resources, err = applyPatch(content, p.Target, resources); err != nil {
+			return nil, errors.Wrapf(err, "failed to apply patch %s", name)
+		}
+	}
+	return resources, nil
+}
+
+// applyPatch applies a patch to the resources selected by the target, or by the kind and name of the patch for
+// strategic merge patches without target, lists of operations are JSON 6902 patches
+func applyPatch(content string, t *target, resources []*resource) ([]*resource, error) {
+	documents, err := splitPatches(content)
+	if err != nil {
+		return nil, err
+	}
+	for _, document := range documents {
+		if operations, ok := document.([]interface{}); ok {
+			if t == nil {
+				return nil, errors.New("JSON 6902 patches require a target")
+			}
+			for _, res := range resources {
+				if !t.matches(res) {
+					continue
+				}
+				patched, err := applyOperations(res.object, operations)
+				if err != nil {
+					return nil, err
+				}
+				res.object = patched
+			}
+			continue
+		}
+		smp, ok := document.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("invalid patch")
+		}
+		selector := t
+		if selector == nil {
+			selector = patchTarget(smp)
+		}
+		matched := false
+		kept := resources[:0]
+		for _, res := range resources {
+			if !selector.matches(res) {
+				kept = append(kept, res)
+				continue
+			}
+			matched = true
+			if smp[patchDirective] == patchDelete {
+				continue
+			}
+			res.object = mergeMaps(res.object, withoutIdentity(smp))
+			kept = append(kept, res)
+		}
+		if !matched && t == nil {
+			return nil, errors.Errorf("resource %s %s to patch not found", selector.Kind, selector.Name)
+		}
+		resources = kept
+	}
+	return resources, nil
+}
+
+// splitPatches splits the documents of a patch, JSON 6902 patches are lists of operations
+func splitPatches(content string) ([]interface{}, error) {
+	documents := make([]interface{}, 0)
+	for _, part := range strings.Split(strings.ReplaceAll(content, "\r", ""), "\n---") {
+		var document interface{}
+		if err := yaml.Unmarshal([]byte(part), &document); err != nil {
+			return nil, err
+		}
+		if document != nil {
+			documents = append(documents, document)
+		}
+	}
+	return documents, nil
+}
+
+// patchTarget returns the target of a strategic merge patch without target, the resource of its kind and name
+func patchTarget(smp map[string]interface{}) *target {
+	t := &target{Kind: regexp.QuoteMeta(kindOf(smp))}
+	if metadata, ok := smp["metadata"].(map[string]interface{}); ok {
+		name, _ := metadata["name"].(string)
+		t.Name = regexp.QuoteMeta(name)
+		if namespace, ok := metadata["namespace"].(string); ok {
+			t.Namespace = regexp.QuoteMeta(namespace)
+		}
+	}
+	return t
+}
+
+// withoutIdentity returns a copy of the patch without the fields identifying the resource patched,
+// so patches selected by target don't rename the resources
+func withoutIdentity(smp map[string]interface{}) map[string]interface{} {
+	patched := deepCopy(smp).(map[string]interface{})
+	delete(patched, "apiVersion")
+	delete(patched, "kind")
+	delete(patched, patchDirective)
+	if metadata, ok := patched["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "name")
+		delete(metadata, "namespace")
+	}
+	return patched
+}
+
+// matches returns true if the resource is selected by the target, names match both the current name of the resource
+// and the name it was declared with
+func (t *target) matches(res *resource) bool {
+	group, version := "", res.apiVersion()
+	if i := strings.LastIndex(version, "/"); i >= 0 {
+		group, version = version[:i], version[i+1:]
+	}
+	return matchPattern(t.Kind, res.kind()) &&
+		(matchPattern(t.Name, res.currentName()) || matchPattern(t.Name, res.name)) &&
+		matchPattern(t.Namespace, res.namespace()) &&
+		(t.Group == "" || t.Group == group) &&
+		(t.Version == "" || t.Version == version) &&
+		matchLabelSelector(t.LabelSelector, res.labels())
+}
+
+func matchPattern(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return pattern == value
+	}
+	return re.MatchString(value)
+}
+
+// matchLabelSelector matches the equality based requirements of a label selector (ex: app=web,tier!=db,canary)
+func matchLabelSelector(selector string, labels map[string]interface{}) bool {
+	for _, requirement := range strings.Split(selector, ",") {
+		requirement = strings.TrimSpace(requirement)
+		switch {
+		case requirement == "":
+		case strings.Contains(requirement, "!="):
+			parts := strings.SplitN(requirement, "!=", 2)
+			if labels[strings.TrimSpace(parts[0])] == strings.TrimSpace(parts[1]) {
+				return false
+			}
+		case strings.Contains(requirement, "="):
+			parts := strings.SplitN(strings.Replace(requirement, "==", "=", 1), "=", 2)
+			if labels[strings.TrimSpace(parts[0])] != strings.TrimSpace(parts[1]) {
+				return false
+			}
+		default:
+			if _, ok := labels[requirement]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// mergeMaps applies a strategic merge patch to the object, null values remove the keys
+func mergeMaps(object, smp map[string]interface{}) map[string]interface{} {
+	for key, value := range smp {
+		if strings.HasPrefix(key, "$") {
+			continue
+		}
+		switch patchValue := value.(type) {
+		case nil:
+			delete(object, key)
+		case map[string]interface{}:
+			switch patchValue[patchDirective] {
+			case patchDelete:
+				delete(object, key)
+			case patchReplace:
+				object[key] = withoutDirectives(patchValue)
+			default:
+				if current, ok := object[key].(map[string]interface{}); ok {
+					object[key] = mergeMaps(current, patchValue)
+				} else {
+					object[key] = withoutDirectives(patchValue)
+				}
+			}
+		case []interface{}:
+			current, _ := object[key].([]interface{})
+			object[key] = mergeLists(key, current, patchValue)
+		default:
+			object[key] = value
+		}
+	}
+	return object
+}
+
+// mergeLists applies a strategic merge patch to a list, the elements of the lists with merge key are merged
+// with the element with the same key, the other lists are replaced
+func mergeLists(name string, list, smp []interface{}) []interface{} {
+	key := getMergeKey(name, smp)
+	if key == "" {
+		return deepCopy(smp).([]interface{})
+	}
+	merged := append(make([]interface{}, 0, len(list)+len(smp)), list...)
+	for _, item := range smp {
+		patchItem, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if patchItem[patchDirective] == patchReplace {
+			replaced := make([]interface{}, 0, len(smp))
+			for _, other := range smp {
+				if otherItem, ok := other.(map[string]interface{}); !ok || otherItem[patchDirective] == nil {
+					replaced = append(replaced, deepCopy(other))
+				}
+			}
+			return replaced
+		}
+		index := -1
+		for i := range merged {
+			if element, ok := merged[i].(map[string]interface{}); ok && reflect.DeepEqual(element[key], patchItem[key]) {
+				index = i
+				break
+			}
+		}
+		switch {
+		case patchItem[patchDirective] == patchDelete:
+			if index >= 0 {
+				merged = append(merged[:index], merged[index+1:]...)
+			}
+		case index >= 0:
+			merged[index] = mergeMaps(merged[index].(map[string]interface{}), patchItem)
+		default:
+			merged = append(merged, withoutDirectives(patchItem))
+		}
+	}
+	return merged
+}
+
+// getMergeKey returns the merge key of a list, ports are merged by container port in containers and by port otherwise
+func getMergeKey(name string, smp []interface{}) string {
+	if name == "ports" {
+		for _, item := range smp {
+			if patchItem, ok := item.(map[string]interface{}); ok {
+				if _, ok := patchItem["containerPort"]; ok {
+					return "containerPort"
+				}
+			}
+		}
+		return "port"
+	}
+	return mergeKeys[name]
+}
+
+func withoutDirectives(smp map[string]interface{}) map[string]interface{} {
+	copied := deepCopy(smp).(map[string]interface{})
+	delete(copied, patchDirective)
+	return copied
+}
+
+// applyOperations applies the operations of a JSON 6902 patch to the object
+func applyOperations(object map[string]interface{}, operations []interface{}) (map[string]interface{}, error) {
+	var document interface{} = object
+	for _, item := range operations {
+		operation, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("invalid JSON 6902 operation")
+		}
+		var err error
+		if document, err = applyOperation(document, operation); err != nil {
+			return nil, errors.Wrapf(err, "failed to %v %v", operation["op"], operation["path"])
+		}
+	}
+	patched, ok := document.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("JSON 6902 patch replaced the resource with a value that is not an object")
+	}
+	return patched, nil
+}
+
+func applyOperation(document interface{}, operation map[string]interface{}) (interface{}, error) {
+	op, _ := operation["op"].(string)
+	path, _ := operation["path"].(string)
+	from, _ := operation["from"].(string)
+	switch op {
+	case "add":
+		return addValue(document, splitPointer(path), deepCopy(operation["value"]), false)
+	case "replace":
+		return addValue(document, splitPointer(path), deepCopy(operation["value"]), true)
+	case "remove":
+		updated, _, err := removeValue(document, splitPointer(path))
+		return updated, err
+	case "move":
+		updated, value, err := removeValue(document, splitPointer(from))
+		if err != nil {
+			return nil, err
+		}
+		return addValue(updated, splitPointer(path), value, false)
+	case "copy":
+		value, err := getValue(document, splitPointer(from))
+		if err != nil {
+			return nil, err
+		}
+		return addValue(document, splitPointer(path), deepCopy(value), false)
+	case "test":
+		value, err := getValue(document, splitPointer(path))
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(value, operation["value"]) {
+			return nil, errors.Errorf("test of %s failed", path)
+		}
+		return document, nil
+	default:
+		return nil, errors.Errorf("unsupported JSON 6902 operation %s", op)
+	}
+}
+
+// splitPointer splits a JSON pointer (ex: /spec/containers/0/image) in its unescaped tokens
+func splitPointer(pointer string) []string {
+	if pointer == "" || pointer == "/" {
+		return []string{}
+	}
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(tokens[i], "~1", "/"), "~0", "~")
+	}
+	return tokens
+}
+
+// addValue adds the value at the tokens, or replaces the existing value when replace is set
+func addValue(node interface{}, tokens []string, value interface{}, replace bool) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if len(tokens) == 1 {
+			if _, ok := n[tokens[0]]; replace && !ok {
+				return nil, errors.Errorf("key %s not found", tokens[0])
+			}
+			n[tokens[0]] = value
+			return n, nil
+		}
+		child, ok := n[tokens[0]]
+		if !ok {
+			return nil, errors.Errorf("key %s not found", tokens[0])
+		}
+		updated, err := addValue(child, tokens[1:], value, replace)
+		if err != nil {
+			return nil, err
+		}
+		n[tokens[0]] = updated
+		return n, nil
+	case []interface{}:
+		return addListValue(n, tokens, value, replace)
+	default:
+		return nil, errors.Errorf("%s is not an object nor a list", tokens[0])
+	}
+}
+
+func addListValue(list []interface{}, tokens []string, value interface{}, replace bool) (interface{}, error) {
+	if len(tokens) == 1 && tokens[0] == "-" && !replace {
+		return append(list, value), nil
+	}
+	index, err := strconv.Atoi(tokens[0])
+	if err != nil || index < 0 || index > len(list) || (index == len(list) && (replace || len(tokens) > 1)) {
+		return nil, errors.Errorf("invalid index %s", tokens[0])
+	}
+	if len(tokens) > 1 {
+		updated, err := addValue(list[index], tokens[1:], value, replace)
+		if err != nil {
+			return nil, err
+		}
+		list[index] = updated
+		return list, nil
+	}
+	if replace {
+		list[index] = value
+		return list, nil
+	}
+	list = append(list, nil)
+	copy(list[index+1:], list[index:])
+	list[index] = value
+	return list, nil
+}
+
+// removeValue removes the value at the tokens and returns it
+func removeValue(node interface{}, tokens []string) (updated, removed interface{}, err error) {
+	if len(tokens) == 0 {
+		return nil, nil, errors.New("the resource can't be removed")
+	}
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[tokens[0]]
+		if !ok {
+			return nil, nil, errors.Errorf("key %s not found", tokens[0])
+		}
+		if len(tokens) == 1 {
+			delete(n, tokens[0])
+			return n, child, nil
+		}
+		if n[tokens[0]], removed, err = removeValue(child, tokens[1:]); err != nil {
+			return nil, nil, err
+		}
+		return n, removed, nil
+	case []interface{}:
+		index, err := strconv.Atoi(tokens[0])
+		if err != nil || index < 0 || index >= len(n) {
+			return nil, nil, errors.Errorf("invalid index %s", tokens[0])
+		}
+		if len(tokens) == 1 {
+			removed = n[index]
+			return append(n[:index], n[index+1:]...), removed, nil
+		}
+		if n[index], removed, err = removeValue(n[index], tokens[1:]); err != nil {
+			return nil, nil, err
+		}
+		return n, removed, nil
+	default:
+		return nil, nil, errors.Errorf("%s is not an object nor a list", tokens[0])
+	}
+}
+
+// getValue returns the value at the tokens
+func getValue(node interface{}, tokens []string) (interface{}, error) {
+	for _, token := range tokens {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			child, ok := n[token]
+			if !ok {
+				return nil, errors.Errorf("key %s not found", token)
+			}
+			node = child
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(n) {
+				return nil, errors.Errorf("invalid index %s", token)
+			}
+			node = n[index]
+		default:
+			return nil, errors.Errorf("%s is not an object nor a list", token)
+		}
+	}
+	return node, nil
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			copied[key] = deepCopy(child)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, child := range v {
+			copied[i] = deepCopy(child)
+		}
+		return copied
+	default:
+		return value
+	}
+}
@@ -0,0 +1,120 @@
+package kustomize
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Resolver is an instance of the kustomize resolver, it builds the kustomizations as kustomize build does,
+// the generated ConfigMaps and Secrets are not suffixed by the hash of their content and the references to
+// the resources renamed are not updated
+type Resolver struct {
+}
+
+// kustomizationFileNames are the names of the kustomization file of a directory, in the order kustomize looks for them
+var kustomizationFileNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// Resolve will build the kustomization of the directory and return each resource rendered ready for parsing,
+// resources are attributed to the file declaring them, the kustomization file for the generated ones, and their
+// origin chain goes from the kustomization built, through its bases and components, to that file
+func (r *Resolver) Resolve(filePath string) (model.ResolvedFiles, error) {
+	kustomizationFile := KustomizationFile(filePath)
+	if kustomizationFile == "" {
+		return model.ResolvedFiles{}, errors.Errorf("no kustomization found in %s", filePath)
+	}
+	b := &builder{visiting: make(map[string]bool)}
+	resources, err := b.build(filePath, []model.OriginStep{{Kind: model.OriginOverlay, FileName: kustomizationFile}}, nil)
+	if err != nil {
+		return model.ResolvedFiles{}, errors.Wrap(err, "failed to build kustomization")
+	}
+
+	rfiles := model.ResolvedFiles{}
+	for _, res := range resources {
+		content, err := yaml.Marshal(res.object)
+		if err != nil {
+			return model.ResolvedFiles{}, errors.Wrapf(err, "failed to render resource %s", res.id())
+		}
+		rfiles.File = append(rfiles.File, model.ResolvedFile{
+			FileName:     res.path,
+			Content:      content,
+			OriginalData: res.original,
+			Origin: &model.Origin{
+				Chain:         res.chain,
+				SplitID:       res.id(),
+				DocumentLines: res.lines,
+			},
+		})
+	}
+	return rfiles, nil
+}
+
+// SupportedTypes returns the supported fileKinds for this resolver
+func (r *Resolver) SupportedTypes() []model.FileKind {
+	return []model.FileKind{model.KindKustomize}
+}
+
+// KustomizationFile returns the path of the kustomization file of the directory, empty when it has none
+func KustomizationFile(dir string) string {
+	for _, name := range kustomizationFileNames {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// resource is a Kubernetes resource being built, path is the file declaring it, original the content of that file,
+// lines the range of lines of the resource in the file and chain its origin chain
+type resource struct {
+	object   map[string]interface{}
+	path     string
+	original []byte
+	lines    []int
+	chain    []model.OriginStep
+	// name is the name the resource was declared with, patches can target the resources by their original name
+	name string
+}
+
+// id returns the ID of the resource, its kind, namespace and name (ex: Deployment|dev|dev-app)
+func (r *resource) id() string {
+	return fmt.Sprintf("%s|%s|%s", r.kind(), r.namespace(), r.currentName())
+}
+
+func (r *resource) kind() string {
+	return kindOf(r.object)
+}
+
+func (r *resource) apiVersion() string {
+	apiVersion, _ := r.object["apiVersion"].(string)
+	return apiVersion
+}
+
+func (r *resource) metadata() map[string]interface{} {
+	metadata, ok := r.object["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = make(map[string]interface{})
+		r.object["metadata"] = metadata
+	}
+	return metadata
+}
+
+func (r *resource) currentName() string {
+	name, _ := r.metadata()["name"].(string)
+	return name
+}
+
+func (r *resource) namespace() string {
+	namespace, _ := r.metadata()["namespace"].(string)
+	return namespace
+}
+
+func (r *resource) labels() map[string]interface{} {
+	labels, _ := r.metadata()["labels"].(map[string]interface{})
+	return labels
+}
@@ -0,0 +1,178 @@
+package kustomize
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestKustomize_SupportedTypes(t *testing.T) {
+	res := &Resolver{}
+	require.Equal(t, []model.FileKind{model.KindKustomize}, res.SupportedTypes())
+}
+
+// TestKustomize_Resolve tests the functions [Resolve()] and all the methods called by them
+func TestKustomize_Resolve(t *testing.T) {
+	overlayPath := filepath.FromSlash("../../../test/fixtures/test_kustomize/overlays/dev")
+	basePath := filepath.FromSlash("../../../test/fixtures/test_kustomize/base")
+	overlayFile := filepath.Join(overlayPath, "kustomization.yaml")
+	baseFile := filepath.Join(basePath, "kustomization.yaml")
+
+	got, err := (&Resolver{}).Resolve(overlayPath)
+	require.NoError(t, err)
+	files := make(map[string]model.ResolvedFile)
+	documents := make(map[string]map[string]interface{})
+	for _, file := range got.File {
+		files[file.Origin.GetSplitID()] = file
+		document := make(map[string]interface{})
+		require.NoError(t, yaml.Unmarshal(file.Content, &document))
+		documents[file.Origin.GetSplitID()] = document
+	}
+	require.Len(t, files, 5)
+
+	// the resources of the base are attributed to the files of the base, with the patches of the overlay applied
+	deployment := files["Deployment|dev|dev-app"]
+	require.Equal(t, filepath.Join(basePath, "deployment.yaml"), deployment.FileName)
+	require.Contains(t, string(deployment.OriginalData), "privileged: false")
+	require.Equal(t, []model.OriginStep{
+		{Kind: model.OriginOverlay, FileName: overlayFile},
+		{Kind: model.OriginBase, FileName: baseFile},
+		{Kind: model.OriginTemplate, FileName: filepath.Join(basePath, "deployment.yaml")},
+	}, deployment.Origin.GetChain())
+	spec := documents["Deployment|dev|dev-app"]["spec"].(map[string]interface{})
+	container := valueAt(t, spec, "/template/spec/containers/0").(map[string]interface{})
+	require.Equal(t, "nginx:latest", container["image"])
+	require.Equal(t, true, valueAt(t, container, "/securityContext/privileged"))
+	require.Equal(t, map[string]interface{}{"app": "web", "env": "dev"}, valueAt(t, spec, "/selector/matchLabels"))
+
+	// the documents of a file are located by their lines
+	service := files["Service|dev|dev-app"]
+	require.Equal(t, []int{0, 9}, service.Origin.DocumentLines)
+	require.Equal(t, "LoadBalancer", valueAt(t, documents["Service|dev|dev-app"], "/spec/type"))
+	require.Equal(t, []int{10, 15}, files["ServiceAccount|dev|dev-app"].Origin.DocumentLines)
+
+	// the resources generated are attributed to their generator
+	configMap := files["ConfigMap|dev|dev-app-config"]
+	require.Equal(t, baseFile, configMap.FileName)
+	require.Equal(t, []int{4, 8}, configMap.Origin.DocumentLines)
+	require.Equal(t, map[string]interface{}{"LOG_LEVEL": "debug"}, documents["ConfigMap|dev|dev-app-config"]["data"])
+	secret := files["Secret|dev|dev-db"]
+	require.Equal(t, overlayFile, secret.FileName)
+	require.Equal(t, map[string]interface{}{"password": "czNjcjN0"}, documents["Secret|dev|dev-db"]["data"])
+
+	// the bases are built on their own too
+	got, err = (&Resolver{}).Resolve(basePath)
+	require.NoError(t, err)
+	require.Len(t, got.File, 4)
+	require.Equal(t, "Deployment||app", got.File[0].Origin.GetSplitID())
+	require.Equal(t, model.OriginOverlay, got.File[0].Origin.GetChain()[0].Kind)
+
+	_, err = (&Resolver{}).Resolve(filepath.FromSlash("../../../test/fixtures/test_kustomize/cycle/a"))
+	require.Error(t, err)
+	_, err = (&Resolver{}).Resolve(filepath.FromSlash("../../../test/fixtures/test_kustomize"))
+	require.Error(t, err)
+}
+
+func valueAt(t *testing.T, document interface{}, pointer string) interface{} {
+	value, err := getValue(document, splitPointer(pointer))
+	require.NoError(t, err)
+	return value
+}
+
+// Test_applyOperations tests the functions [applyOperations()] and all the methods called by them
+func Test_applyOperations(t *testing.T) {
+	tests := []struct {
+		name       string
+		operations string
+		want       map[string]interface{}
+		wantErr    bool
+	}{
+		{
+			name:       "add",
+			operations: `[{"op": "add", "path": "/spec/ports/0", "value": 443}, {"op": "add", "path": "/spec/ports/-", "value": 8080}]`,
+			want:       map[string]interface{}{"spec": map[string]interface{}{"ports": []interface{}{443, 80, 8080}, "type": "NodePort"}},
+		},
+		{
+			name:       "replace_and_remove",
+			operations: `[{"op": "replace", "path": "/spec/type", "value": "ClusterIP"}, {"op": "remove", "path": "/spec/ports/0"}]`,
+			want:       map[string]interface{}{"spec": map[string]interface{}{"ports": []interface{}{}, "type": "ClusterIP"}},
+		},
+		{
+			name:       "move_and_copy",
+			operations: `[{"op": "copy", "from": "/spec/type", "path": "/spec/kind"}, {"op": "move", "from": "/spec/type", "path": "/type"}]`,
+			want:       map[string]interface{}{"spec": map[string]interface{}{"ports": []interface{}{80}, "kind": "NodePort"}, "type": "NodePort"},
+		},
+		{
+			name:       "test_failed",
+			operations: `[{"op": "test", "path": "/spec/type", "value": "ClusterIP"}]`,
+			wantErr:    true,
+		},
+		{
+			name:       "replace_missing",
+			operations: `[{"op": "replace", "path": "/spec/missing", "value": true}]`,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var operations []interface{}
+			require.NoError(t, yaml.Unmarshal([]byte(tt.operations), &operations))
+			object := map[string]interface{}{"spec": map[string]interface{}{"ports": []interface{}{80}, "type": "NodePort"}}
+			got, err := applyOperations(object, operations)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// Test_mergeMaps tests the functions [mergeMaps()] and all the methods called by them
+func Test_mergeMaps(t *testing.T) {
+	object := map[string]interface{}{
+		"containers": []interface{}{
+			map[string]interface{}{"name": "app", "image": "nginx", "args": []interface{}{"a"}},
+			map[string]interface{}{"name": "sidecar", "image": "envoy"},
+		},
+		"hostNetwork": true,
+	}
+	smp := map[string]interface{}{
+		"containers": []interface{}{
+			map[string]interface{}{"name": "app", "args": []interface{}{"b"}},
+			map[string]interface{}{"name": "sidecar", "$patch": "delete"},
+			map[string]interface{}{"name": "init", "image": "busybox"},
+		},
+		"hostNetwork": nil,
+	}
+	require.Equal(t, map[string]interface{}{
+		"containers": []interface{}{
+			map[string]interface{}{"name": "app", "image": "nginx", "args": []interface{}{"b"}},
+			map[string]interface{}{"name": "init", "image": "busybox"},
+		},
+	}, mergeMaps(object, smp))
+}
+
+// Test_updateImage tests the functions [updateImage()]
+func Test_updateImage(t *testing.T) {
+	tests := []struct {
+		current string
+		image   image
+		want    string
+	}{
+		{current: "nginx:1.19", image: image{Name: "nginx", NewTag: "latest"}, want: "nginx:latest"},
+		{current: "nginx", image: image{Name: "nginx", NewName: "registry.example.com:5000/nginx"}, want: "registry.example.com:5000/nginx"},
+		{current: "registry.example.com:5000/nginx:1.19", image: image{Name: "registry.example.com:5000/nginx", Digest: "sha256:abc"},
+			want: "registry.example.com:5000/nginx@sha256:abc"},
+		{current: "envoy:1.0", image: image{Name: "nginx", NewTag: "latest"}, want: "envoy:1.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.current, func(t *testing.T) {
+			require.Equal(t, tt.want, updateImage(tt.current, &tt.image))
+		})
+	}
+}
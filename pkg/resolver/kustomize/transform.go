@@ -0,0 +1,195 @@
+package kustomize
+
+import (
+	"strings"
+)
+
+// labels is an entry of the labels of a kustomization, the selectors and the templates of the workloads are only
+// labeled when they are included
+type labels struct {
+	Pairs            map[string]string `yaml:"pairs"`
+	IncludeSelectors bool              `yaml:"includeSelectors"`
+	IncludeTemplates bool              `yaml:"includeTemplates"`
+}
+
+// image is an entry of the images of a kustomization, which changes the images of the containers named Name
+type image struct {
+	Name    string `yaml:"name"`
+	NewName string `yaml:"newName"`
+	NewTag  string `yaml:"newTag"`
+	Digest  string `yaml:"digest"`
+}
+
+// replica is an entry of the replicas of a kustomization, which changes the replicas of the workloads named Name
+type replica struct {
+	Name  string `yaml:"name"`
+	Count int    `yaml:"count"`
+}
+
+// clusterScopedKinds are the kinds of the resources without namespace
+var clusterScopedKinds = map[string]bool{
+	"Namespace":                      true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CustomResourceDefinition":       true,
+	"PersistentVolume":               true,
+	"StorageClass":                   true,
+	"PriorityClass":                  true,
+	"PodSecurityPolicy":              true,
+	"MutatingWebhookConfiguration":   true,
+	"ValidatingWebhookConfiguration": true,
+	"APIService":                     true,
+	"Node":                           true,
+}
+
+// containerLists are the lists of containers whose images are changed
+var containerLists = map[string]bool{"containers": true, "initContainers": true, "ephemeralContainers": true}
+
+// transform applies the transformers of the kustomization to the resources, the names of the CustomResourceDefinitions
+// are not changed since they must match their group
+func transform(k *kustomization, resources []*resource) {
+	for _, res := range resources {
+		for i := range k.Images {
+			setImages(res.object, &k.Images[i])
+		}
+		for _, r := range k.Replicas {
+			if spec, ok := res.object["spec"].(map[string]interface{}); ok && (res.currentName() == r.Name || res.name == r.Name) {
+				spec["replicas"] = r.Count
+			}
+		}
+		if k.Namespace != "" && !clusterScopedKinds[res.kind()] {
+			res.metadata()["namespace"] = k.Namespace
+		}
+		if (k.NamePrefix != "" || k.NameSuffix != "") && res.kind() != "CustomResourceDefinition" {
+			res.metadata()["name"] = k.NamePrefix + res.currentName() + k.NameSuffix
+		}
+		setLabels(res, k.CommonLabels, true, true)
+		for _, l := range k.Labels {
+			setLabels(res, l.Pairs, l.IncludeSelectors, l.IncludeSelectors || l.IncludeTemplates)
+		}
+		setMetadataValues(res.metadata(), "annotations", k.CommonAnnotations)
+		if template := getPodTemplate(res.object); template != nil && len(k.CommonAnnotations) > 0 {
+			setMetadataValues(getMap(template, "metadata"), "annotations", k.CommonAnnotations)
+		}
+	}
+}
+
+// setLabels adds the labels to the resource, and to the selectors and the pod templates of the workloads
+func setLabels(res *resource, values map[string]string, selectors, templates bool) {
+	if len(values) == 0 {
+		return
+	}
+	setMetadataValues(res.metadata(), "labels", values)
+	spec, ok := res.object["spec"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	if templates {
+		if template := getPodTemplate(res.object); template != nil {
+			setMetadataValues(getMap(template, "metadata"), "labels", values)
+		}
+	}
+	if !selectors {
+		return
+	}
+	selector, ok := spec["selector"].(map[string]interface{})
+	switch {
+	case res.kind() == "Service":
+		if !ok {
+			selector = make(map[string]interface{})
+			spec["selector"] = selector
+		}
+		setValues(selector, values)
+	case ok:
+		setValues(getMap(selector, "matchLabels"), values)
+	}
+}
+
+// getPodTemplate returns the pod template of a workload, nil for other resources
+func getPodTemplate(object map[string]interface{}) map[string]interface{} {
+	spec, ok := object["spec"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if jobTemplate, ok := spec["jobTemplate"].(map[string]interface{}); ok {
+		if spec, ok = jobTemplate["spec"].(map[string]interface{}); !ok {
+			return nil
+		}
+	}
+	template, _ := spec["template"].(map[string]interface{})
+	return template
+}
+
+// setImages changes the images of the containers of the object matching the image
+func setImages(node interface{}, img *image) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for key, value := range n {
+			containers, ok := value.([]interface{})
+			if !ok || !containerLists[key] {
+				setImages(value, img)
+				continue
+			}
+			for _, item := range containers {
+				container, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if current, ok := container["image"].(string); ok {
+					container["image"] = updateImage(current, img)
+				}
+			}
+		}
+	case []interface{}:
+		for _, value := range n {
+			setImages(value, img)
+		}
+	}
+}
+
+// updateImage returns the image changed by the entry of the images of a kustomization, when it matches its name
+func updateImage(current string, img *image) string {
+	name, reference := current, ""
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, reference = name[:i], name[i:]
+	} else if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, reference = name[:i], name[i:]
+	}
+	if name != img.Name {
+		return current
+	}
+	if img.NewName != "" {
+		name = img.NewName
+	}
+	switch {
+	case img.Digest != "":
+		reference = "@" + img.Digest
+	case img.NewTag != "":
+		reference = ":" + img.NewTag
+	}
+	return name + reference
+}
+
+// setMetadataValues adds the values to a map of the metadata (ex: labels)
+func setMetadataValues(metadata map[string]interface{}, key string, values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+	setValues(getMap(metadata, key), values)
+}
+
+func setValues(m map[string]interface{}, values map[string]string) {
+	for key, value := range values {
+		m[key] = value
+	}
+}
+
+// getMap returns the map of the key, it is created when missing
+func getMap(m map[string]interface{}, key string) map[string]interface{} {
+	child, ok := m[key].(map[string]interface{})
+	if !ok {
+		child = make(map[string]interface{})
+		m[key] = child
+	}
+	return child
+}
@@ -1,10 +1,18 @@
 package resolver
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/parser/terraform/terragrunt"
+	"github.com/Checkmarx/kics/pkg/resolver/arm"
+	"github.com/Checkmarx/kics/pkg/resolver/kustomize"
+	"github.com/Checkmarx/kics/pkg/resolver/terraform"
 	"github.com/rs/zerolog/log"
 )
 
@@ -70,9 +78,75 @@ func (r *Resolver) Resolve(filePath string, kind model.FileKind) (model.Resolved
 
 // GetType will analyze the filepath to determine which resolver to use
 func (r *Resolver) GetType(filePath string) model.FileKind {
+	if filepath.Ext(filePath) == ".tgz" {
+		if isChartArchive(filePath) {
+			return model.KindHELM
+		}
+		return model.KindCOMMON
+	}
 	_, err := os.Stat(filepath.Join(filePath, "Chart.yaml"))
 	if err == nil {
 		return model.KindHELM
 	}
+	if kustomize.KustomizationFile(filePath) != "" {
+		return model.KindKustomize
+	}
+	if containsARMDeployments(filePath) {
+		return model.KindARM
+	}
+	if _, ok := r.resolvers[model.KindTerragrunt]; ok && terragrunt.ConfigPath(filePath) != "" {
+		return model.KindTerragrunt
+	}
+	// the configurations are only read when the modules are resolved, since resolving them is optional
+	if _, ok := r.resolvers[model.KindTerraform]; ok && terraform.ContainsModuleCalls(filePath) {
+		return model.KindTerraform
+	}
 	return model.KindCOMMON
 }
+
+// containsARMDeployments checks if any json file directly inside the directory declares ARM deployments
+func containsARMDeployments(dirPath string) bool {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Clean(filepath.Join(dirPath, entry.Name())))
+		if err != nil {
+			continue
+		}
+		if bytes.Contains(content, []byte(arm.DeploymentType)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isChartArchive checks if the gzipped tarball contains a chart, packaged charts have a single
+// top-level directory with the Chart.yaml file
+func isChartArchive(archivePath string) bool {
+	f, err := os.Open(filepath.Clean(archivePath))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return false
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err != nil { // io.EOF when there are no more files
+			return false
+		}
+		parts := strings.Split(strings.TrimPrefix(filepath.ToSlash(header.Name), "./"), "/")
+		if len(parts) == 2 && parts[1] == "Chart.yaml" {
+			return true
+		}
+	}
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/Checkmarx/kics/pkg/model"
 	"github.com/Checkmarx/kics/pkg/resolver/helm"
+	"github.com/Checkmarx/kics/pkg/resolver/terraform"
+	"github.com/Checkmarx/kics/pkg/resolver/terragrunt"
 )
 
 func initilizeBuilder() *Resolver {
@@ -33,6 +35,27 @@ func TestGetType(t *testing.T) {
 			},
 			want: model.KindHELM,
 		},
+		{
+			name: "get_arm_type",
+			args: args{
+				filepath: filepath.FromSlash("../../test/fixtures/test_arm/main"),
+			},
+			want: model.KindARM,
+		},
+		{
+			name: "get_kustomize_type",
+			args: args{
+				filepath: filepath.FromSlash("../../test/fixtures/test_kustomize/overlays/dev"),
+			},
+			want: model.KindKustomize,
+		},
+		{
+			name: "get_helm_package_type",
+			args: args{
+				filepath: filepath.FromSlash("../../test/fixtures/test_helm_package/test_helm-0.1.0.tgz"),
+			},
+			want: model.KindHELM,
+		},
 		{
 			name: "get_no_type",
 			args: args{
@@ -52,6 +75,50 @@ func TestGetType(t *testing.T) {
 	}
 }
 
+// TestGetType_Terraform tests the functions [GetType()] for directories calling Terraform modules, which are only
+// resolved when the Terraform resolver is added
+func TestGetType_Terraform(t *testing.T) {
+	modulesPath := filepath.FromSlash("../../test/fixtures/test_terraform_remote_modules/main")
+	if got := initilizeBuilder().GetType(modulesPath); got != model.KindCOMMON {
+		t.Errorf("GetType() = %v, want = %v", got, model.KindCOMMON)
+	}
+
+	res, _ := NewBuilder().
+		Add(&helm.Resolver{}).
+		Add(&terraform.Resolver{}).
+		Build()
+	if got := res.GetType(modulesPath); got != model.KindTerraform {
+		t.Errorf("GetType() = %v, want = %v", got, model.KindTerraform)
+	}
+	if got := res.GetType(filepath.FromSlash("../../test/fixtures/test_terraform_remote_modules/modules/bucket")); got != model.KindTerraform {
+		t.Errorf("GetType() = %v, want = %v", got, model.KindTerraform)
+	}
+	if got := res.GetType(filepath.FromSlash("../../test/fixtures/test_terraform_remote_modules/main/inner")); got != model.KindCOMMON {
+		t.Errorf("GetType() = %v, want = %v", got, model.KindCOMMON)
+	}
+}
+
+// TestGetType_Terragrunt tests the functions [GetType()] for directories with Terragrunt configurations, which are only
+// resolved when the Terragrunt resolver is added
+func TestGetType_Terragrunt(t *testing.T) {
+	configPath := filepath.FromSlash("../../test/fixtures/test_terragrunt/live/app")
+	if got := initilizeBuilder().GetType(configPath); got != model.KindCOMMON {
+		t.Errorf("GetType() = %v, want = %v", got, model.KindCOMMON)
+	}
+
+	res, _ := NewBuilder().
+		Add(&helm.Resolver{}).
+		Add(&terraform.Resolver{}).
+		Add(&terragrunt.Resolver{}).
+		Build()
+	if got := res.GetType(configPath); got != model.KindTerragrunt {
+		t.Errorf("GetType() = %v, want = %v", got, model.KindTerragrunt)
+	}
+	if got := res.GetType(filepath.FromSlash("../../test/fixtures/test_terragrunt/modules/bucket")); got != model.KindCOMMON {
+		t.Errorf("GetType() = %v, want = %v", got, model.KindCOMMON)
+	}
+}
+
 func TestResolver_Resolve(t *testing.T) {
 	res := initilizeBuilder()
 	type args struct {
@@ -74,10 +141,16 @@ func TestResolver_Resolve(t *testing.T) {
 			want: model.ResolvedFiles{
 				File: []model.ResolvedFile{
 					{
-						SplitID:  "# KICS_HELM_ID_0:",
 						FileName: filepath.FromSlash("../../test/fixtures/test_helm/templates/service.yaml"),
-						IDInfo: map[int]interface{}{0: map[int]int{0: 0, 1: 1, 2: 2, 3: 3, 4: 4,
-							5: 5, 6: 6, 7: 7, 8: 8, 9: 9, 10: 10, 11: 11, 12: 12, 13: 13, 14: 14, 15: 15, 16: 16}},
+						Origin: &model.Origin{
+							Chain: []model.OriginStep{
+								{Kind: model.OriginChart, FileName: filepath.FromSlash("../../test/fixtures/test_helm")},
+								{Kind: model.OriginTemplate, FileName: filepath.FromSlash("../../test/fixtures/test_helm/templates/service.yaml")},
+							},
+							SplitID: "# KICS_HELM_ID_0:",
+							Lines: map[int]interface{}{0: map[int]int{0: 0, 1: 1, 2: 2, 3: 3, 4: 4,
+								5: 5, 6: 6, 7: 7, 8: 8, 9: 9, 10: 10, 11: 11, 12: 12, 13: 13, 14: 14, 15: 15, 16: 16}},
+						},
 						Content: []byte(`
 # Source: test_helm/templates/service.yaml
 # KICS_HELM_ID_0:
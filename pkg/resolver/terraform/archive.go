@@ -0,0 +1,125 @@
+package terraform
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// extractArchive extracts the zip or gzipped tarball of a module into dir, unless it was already extracted,
+// the archive is extracted to a temporary directory renamed to dir, so concurrent scans never read partial modules
+func extractArchive(archivePath, format, dir string) error {
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), filepath.Base(dir)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp) //nolint:errcheck
+
+	switch format {
+	case "zip":
+		err = extractZip(archivePath, tmp)
+	case "tar.gz", "tgz":
+		err = extractTarGz(archivePath, tmp)
+	default:
+		err = errors.Errorf("unsupported archive format %s", format)
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		if _, statErr := os.Stat(dir); statErr == nil {
+			return nil // extracted by a concurrent scan
+		}
+		return err
+	}
+	return nil
+}
+
+func extractZip(archivePath, dir string) error {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer closeArchive(reader, archivePath)
+	for _, file := range reader.File {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		content, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = writeEntry(dir, file.Name, content)
+		closeArchive(content, file.Name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractTarGz(archivePath string, dir string) error {
+	f, err := os.Open(filepath.Clean(archivePath))
+	if err != nil {
+		return err
+	}
+	defer closeArchive(f, archivePath)
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer closeArchive(gz, archivePath)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := writeEntry(dir, header.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// writeEntry writes a file of an archive inside dir, the entries whose paths leave dir are rejected
+func writeEntry(dir, name string, content io.Reader) error {
+	name = strings.TrimPrefix(filepath.ToSlash(name), "./")
+	if name == "" || strings.HasPrefix(name, "/") || strings.Contains("/"+name+"/", "/../") {
+		return errors.Errorf("invalid path %s in module archive", name)
+	}
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, content); err != nil { //nolint:gosec
+		f.Close() //nolint:errcheck,gosec
+		return err
+	}
+	return f.Close()
+}
+
+func closeArchive(closer io.Closer, name string) {
+	if err := closer.Close(); err != nil {
+		log.Err(err).Msgf("Failed to close %s", name)
+	}
+}
@@ -0,0 +1,293 @@
+package terraform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/engine/source"
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/resolver/download"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// moduleCallRegex matches the module blocks of Terraform configurations, to find the directories calling modules
+// without parsing their files
+var moduleCallRegex = regexp.MustCompile(`(?m)^\s*module\s+"[^"]+"\s*\{`)
+
+// Resolver is an instance of the Terraform modules resolver
+// Downloader is used to download the modules of the Terraform registry and the archives of modules, when nil
+// only the local modules are resolved, Git configures the checkout of the modules stored in git repositories
+// BasePath is the path scanned, the local modules inside it are scanned directly so they are not resolved,
+// when empty the local modules below the directory calling them are not resolved
+type Resolver struct {
+	Downloader *download.Downloader
+	Git        source.GitOptions
+	BasePath   string
+}
+
+// moduleCall is a module block of a Terraform configuration, fileName is the file declaring it
+type moduleCall struct {
+	name     string
+	source   string
+	version  string
+	fileName string
+}
+
+// module keeps the information of a module being resolved, calls is its chain of module directories,
+// used to stop cycles of local modules
+type module struct {
+	dir     string
+	address string
+	chain   []model.OriginStep
+	calls   []string
+}
+
+// Resolve will resolve the modules called by the Terraform configuration in the directory, along with the modules
+// they call, and return the files of the modules attributed to the file calling them, so the results of the
+// resources of the modules are reported on the module calls of the configuration scanned
+func (r *Resolver) Resolve(filePath string) (model.ResolvedFiles, error) {
+	var rfiles = model.ResolvedFiles{}
+	calls, err := readModuleCalls(filePath)
+	if err != nil {
+		return model.ResolvedFiles{}, errors.Wrap(err, "failed to read Terraform module calls")
+	}
+	for _, call := range calls {
+		original, err := os.ReadFile(filepath.Clean(call.fileName))
+		if err != nil {
+			log.Err(err).Msgf("Failed to read Terraform configuration %s", call.fileName)
+			continue
+		}
+		parent := module{
+			dir:   filePath,
+			chain: []model.OriginStep{{Kind: model.OriginModuleCall, FileName: call.fileName}},
+			calls: []string{filepath.Clean(filePath)},
+		}
+		rfiles.File = append(rfiles.File, r.resolveCall(&parent, call, call.fileName, original, true)...)
+	}
+	return rfiles, nil
+}
+
+// ResolveSource returns the files of the module of the source called from the directory dir, along with the modules
+// it calls, attributed to fileName, whose content is original, such as the Terragrunt configuration deploying the module,
+// the origin chains of the files start with chain, the module is the root module of the addresses of the modules it calls
+func (r *Resolver) ResolveSource(dir, moduleSource, version, fileName string, original []byte,
+	chain []model.OriginStep) []model.ResolvedFile {
+	parent := module{
+		dir:   dir,
+		chain: chain,
+		calls: []string{filepath.Clean(dir)},
+	}
+	call := moduleCall{source: moduleSource, version: version, fileName: fileName}
+	return r.resolveCall(&parent, call, fileName, original, false)
+}
+
+// SupportedTypes returns the supported fileKinds for this resolver
+func (r *Resolver) SupportedTypes() []model.FileKind {
+	return []model.FileKind{model.KindTerraform}
+}
+
+// ContainsModuleCalls checks if any Terraform configuration directly inside the directory calls modules
+func ContainsModuleCalls(dirPath string) bool {
+	files, err := filepath.Glob(filepath.Join(dirPath, "*.tf"))
+	if err != nil {
+		return false
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Clean(file))
+		if err == nil && moduleCallRegex.Match(content) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveCall returns the files of the module called and of the modules it calls, attributed to fileName,
+// the file scanned calling the first module of the chain
+func (r *Resolver) resolveCall(parent *module, call moduleCall, fileName string, original []byte, root bool) []model.ResolvedFile {
+	dir, err := r.getModuleDir(parent.dir, call)
+	if err != nil {
+		r.skip(call, err)
+		return nil
+	}
+	if dir == "" {
+		return nil
+	}
+	// local modules scanned directly are only resolved when called by other modules
+	if root && r.isScanned(parent.dir, dir, call) {
+		return nil
+	}
+	dir = filepath.Clean(dir)
+	for _, called := range parent.calls {
+		if called == dir {
+			log.Debug().Msgf("terraform.resolveCall() skipping cyclic call of module %s in %s", call.source, call.fileName)
+			return nil
+		}
+	}
+
+	current := module{
+		dir:   dir,
+		calls: append(append([]string{}, parent.calls...), dir),
+	}
+	// the modules resolved from a source without call are root modules, without address
+	if call.name != "" {
+		current.address = parent.address + model.ModuleAddressPrefix + call.name
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil || len(files) == 0 {
+		r.skip(call, errors.Errorf("no Terraform configuration found in %s", dir))
+		return nil
+	}
+	sort.Strings(files)
+
+	rfiles := make([]model.ResolvedFile, 0, len(files))
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			log.Err(err).Msgf("Failed to read file %s of Terraform module %s", file, call.source)
+			continue
+		}
+		current.chain = model.ExtendChain(parent.chain, model.OriginModule, getModuleFileName(call, dir, file))
+		rfiles = append(rfiles, model.ResolvedFile{
+			FileName:     fileName,
+			Content:      content,
+			OriginalData: original,
+			Origin:       &model.Origin{Chain: current.chain, SplitID: current.address},
+		})
+
+		nestedCalls, err := parseModuleCalls(file, content)
+		if err != nil {
+			log.Debug().Msgf("terraform.resolveCall() failed to read module calls of %s: %s", file, err)
+			continue
+		}
+		nested := current
+		if nested.address != "" {
+			nested.address += "."
+		}
+		for _, nestedCall := range nestedCalls {
+			rfiles = append(rfiles, r.resolveCall(&nested, nestedCall, fileName, original, false)...)
+		}
+	}
+	return rfiles
+}
+
+// isScanned returns true if the local module in dir is scanned directly, since it is inside the path scanned
+// or, when the path scanned is unknown, below the directory calling it
+func (r *Resolver) isScanned(callerDir, dir string, call moduleCall) bool {
+	if !isLocalSource(call.source) {
+		return false
+	}
+	base := r.BasePath
+	if base == "" {
+		base = callerDir
+	}
+	absBase, err := filepath.Abs(base)
+	if err != nil {
+		return false
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absBase, absDir)
+	return err == nil && rel != ".." && !strings.HasPrefix(filepath.ToSlash(rel), "../")
+}
+
+// skip records a module that was not resolved, to be reported at the end of the scan
+func (r *Resolver) skip(call moduleCall, err error) {
+	if r.Downloader == nil {
+		log.Warn().Msgf("Skipping Terraform module %s: %s", call.source, err)
+		return
+	}
+	r.Downloader.Skip(call.source, err)
+}
+
+// getModuleFileName returns the name of the file of the module shown in the origin chains, local modules keep their
+// paths and the files of remote modules are named after the source of the module (ex: terraform-aws-modules/vpc/aws/main.tf)
+func getModuleFileName(call moduleCall, dir, file string) string {
+	if isLocalSource(call.source) {
+		return file
+	}
+	rel, err := filepath.Rel(dir, file)
+	if err != nil {
+		rel = filepath.Base(file)
+	}
+	moduleSource := call.source
+	if i := strings.Index(moduleSource, "?"); i >= 0 {
+		moduleSource = moduleSource[:i]
+	}
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(moduleSource, "/"), filepath.ToSlash(rel))
+}
+
+// readModuleCalls returns the module calls of the Terraform configurations directly inside a directory
+func readModuleCalls(dir string) ([]moduleCall, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	calls := make([]moduleCall, 0)
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			log.Debug().Msgf("terraform.readModuleCalls() skipping file %s: %s", file, err)
+			continue
+		}
+		fileCalls, err := parseModuleCalls(file, content)
+		if err != nil {
+			log.Debug().Msgf("terraform.readModuleCalls() skipping file %s: %s", file, err)
+			continue
+		}
+		calls = append(calls, fileCalls...)
+	}
+	return calls, nil
+}
+
+// parseModuleCalls returns the module blocks of a Terraform configuration with a static source
+func parseModuleCalls(fileName string, content []byte) ([]moduleCall, error) {
+	file, diagnostics := hclsyntax.ParseConfig(content, filepath.Base(fileName), hcl.Pos{Byte: 0, Line: 1, Column: 1})
+	if diagnostics != nil && diagnostics.HasErrors() {
+		return nil, diagnostics
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, errors.New("unexpected body of Terraform configuration")
+	}
+	calls := make([]moduleCall, 0)
+	for _, block := range body.Blocks {
+		if block.Type != "module" || len(block.Labels) != 1 {
+			continue
+		}
+		call := moduleCall{name: block.Labels[0], fileName: fileName}
+		if attribute, ok := block.Body.Attributes["source"]; ok {
+			call.source = evalString(attribute.Expr)
+		}
+		if attribute, ok := block.Body.Attributes["version"]; ok {
+			call.version = evalString(attribute.Expr)
+		}
+		if call.source == "" {
+			log.Debug().Msgf("terraform.parseModuleCalls() skipping module %s without static source in %s", call.name, fileName)
+			continue
+		}
+		calls = append(calls, call)
+	}
+	return calls, nil
+}
+
+func evalString(expr hcl.Expression) string {
+	value, diagnostics := expr.Value(nil)
+	if diagnostics.HasErrors() || value.IsNull() || !value.IsKnown() || value.Type() != cty.String {
+		return ""
+	}
+	return value.AsString()
+}
@@ -0,0 +1,186 @@
+package terraform
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/resolver/download"
+	"github.com/stretchr/testify/require"
+)
+
+const moduleContent = `resource "aws_s3_bucket" "this" {
+  acl = "public-read"
+}
+`
+
+func TestTerraform_SupportedTypes(t *testing.T) {
+	res := &Resolver{}
+	want := []model.FileKind{model.KindTerraform}
+	t.Run("get_suported_type", func(t *testing.T) {
+		got := res.SupportedTypes()
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("SuportedTypes() = %v, want = %v", got, want)
+		}
+	})
+}
+
+// TestTerraform_Resolve tests the functions [Resolve()] and all the methods called by them
+func TestTerraform_Resolve(t *testing.T) {
+	res := &Resolver{}
+	mainPath := filepath.FromSlash("../../../test/fixtures/test_terraform_remote_modules/main/main.tf")
+	bucketPath := filepath.FromSlash("../../../test/fixtures/test_terraform_remote_modules/modules/bucket/main.tf")
+	policyPath := filepath.FromSlash("../../../test/fixtures/test_terraform_remote_modules/modules/policy/main.tf")
+
+	got, err := res.Resolve(filepath.FromSlash("../../../test/fixtures/test_terraform_remote_modules/main"))
+	require.NoError(t, err)
+	// the local module below the directory is scanned directly, the remote module is not downloaded without
+	// downloader and the call of the policy module back to the bucket module is a cycle
+	require.Len(t, got.File, 2)
+
+	require.Equal(t, mainPath, got.File[0].FileName)
+	require.Contains(t, string(got.File[0].Content), `resource "aws_s3_bucket" "this"`)
+	require.Contains(t, string(got.File[0].OriginalData), `module "bucket"`)
+	require.Equal(t, "module.bucket", got.File[0].Origin.GetSplitID())
+	require.Equal(t, []model.OriginStep{
+		{Kind: model.OriginModuleCall, FileName: mainPath},
+		{Kind: model.OriginModule, FileName: bucketPath},
+	}, got.File[0].Origin.GetChain())
+
+	require.Equal(t, mainPath, got.File[1].FileName)
+	require.Contains(t, string(got.File[1].Content), `resource "aws_s3_bucket_policy" "this"`)
+	require.Equal(t, got.File[0].OriginalData, got.File[1].OriginalData)
+	require.Equal(t, "module.bucket.module.policy", got.File[1].Origin.GetSplitID())
+	require.Equal(t, []model.OriginStep{
+		{Kind: model.OriginModuleCall, FileName: mainPath},
+		{Kind: model.OriginModule, FileName: bucketPath},
+		{Kind: model.OriginModule, FileName: policyPath},
+	}, got.File[1].Origin.GetChain())
+
+	// local modules inside the base path are scanned directly
+	absPath, err := filepath.Abs(filepath.FromSlash("../../../test/fixtures/test_terraform_remote_modules"))
+	require.NoError(t, err)
+	res.BasePath = absPath
+	got, err = res.Resolve(filepath.FromSlash("../../../test/fixtures/test_terraform_remote_modules/main"))
+	require.NoError(t, err)
+	require.Empty(t, got.File)
+
+	_, err = res.Resolve(filepath.FromSlash("../../../test/fixtures/not_found"))
+	require.Error(t, err)
+}
+
+// TestTerraform_Resolve_Archives tests the functions [Resolve()] for modules downloaded as archives
+func TestTerraform_Resolve_Archives(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bucket-1.0.0.tar.gz":
+			_, _ = w.Write(newTarGz(t, map[string]string{"bucket-1.0.0/main.tf": moduleContent}))
+		case "/download":
+			_, _ = w.Write(newZip(t, map[string]string{"modules/bucket/main.tf": moduleContent}))
+		case "/evil.tar.gz":
+			_, _ = w.Write(newTarGz(t, map[string]string{"../main.tf": moduleContent}))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	downloader, err := download.NewDownloader(download.Options{CacheDir: t.TempDir()})
+	require.NoError(t, err)
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "main.tf")
+	require.NoError(t, os.WriteFile(mainPath, []byte(`module "tarball" {
+  source = "`+server.URL+`/bucket-1.0.0.tar.gz//*"
+}
+
+module "zip" {
+  source = "`+server.URL+`/download//modules/bucket?archive=zip"
+}
+
+module "evil" {
+  source = "`+server.URL+`/evil.tar.gz"
+}
+
+module "missing" {
+  source = "`+server.URL+`/missing.zip"
+}
+`), 0600))
+	require.True(t, ContainsModuleCalls(dir))
+
+	res := &Resolver{Downloader: downloader}
+	got, err := res.Resolve(dir)
+	require.NoError(t, err)
+	require.Len(t, got.File, 2)
+	require.Equal(t, "module.tarball", got.File[0].Origin.GetSplitID())
+	require.Equal(t, moduleContent, string(got.File[0].Content))
+	require.Equal(t, mainPath, got.File[0].FileName)
+	require.Equal(t, server.URL+"/bucket-1.0.0.tar.gz//*/main.tf", got.File[0].Origin.GetChain()[1].FileName)
+	require.Equal(t, "module.zip", got.File[1].Origin.GetSplitID())
+	require.Equal(t, moduleContent, string(got.File[1].Content))
+
+	skipped := downloader.Skipped()
+	require.Len(t, skipped, 2)
+	require.Equal(t, server.URL+"/evil.tar.gz", skipped[0].Source)
+	require.Contains(t, skipped[0].Reason, "invalid path")
+	require.Equal(t, server.URL+"/missing.zip", skipped[1].Source)
+}
+
+// Test_splitSubdir tests the functions [splitSubdir()] and [registrySourceRegex]
+func Test_splitSubdir(t *testing.T) {
+	tests := []struct {
+		address  string
+		base     string
+		subdir   string
+		registry bool
+	}{
+		{address: "hashicorp/consul/aws", base: "hashicorp/consul/aws", registry: true},
+		{address: "hashicorp/consul/aws//modules/consul-cluster", base: "hashicorp/consul/aws", subdir: "modules/consul-cluster", registry: true},
+		{address: "app.terraform.io/example/vpc/aws", base: "app.terraform.io/example/vpc/aws", registry: true},
+		{address: "git::https://example.com/vpc.git//modules/vpc?ref=v1.0.0", base: "git::https://example.com/vpc.git?ref=v1.0.0", subdir: "modules/vpc"},
+		{address: "https://example.com/vpc.zip", base: "https://example.com/vpc.zip"},
+		{address: "bitbucket.org/example/vpc", base: "bitbucket.org/example/vpc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			base, subdir := splitSubdir(tt.address)
+			require.Equal(t, tt.base, base)
+			require.Equal(t, tt.subdir, subdir)
+			require.Equal(t, tt.registry, registrySourceRegex.MatchString(tt.address))
+		})
+	}
+}
+
+func newTarGz(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func newZip(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,159 @@
+package terraform
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/engine/source"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	gitForcedGetter  = "git::"
+	archiveParameter = "archive"
+)
+
+var (
+	// registrySourceRegex matches the addresses of the modules of Terraform registries, namespace/name/provider
+	// optionally prefixed by the registry host and followed by a subdirectory
+	registrySourceRegex = regexp.MustCompile(`^(?:[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}(?::\d+)?/)?[0-9A-Za-z_-]+/[0-9A-Za-z_-]+/[0-9a-z]+(?://.*)?$`)
+	// exactVersionRegex matches the version constraints selecting a single version (ex: 1.2.0 or = 1.2.0)
+	exactVersionRegex = regexp.MustCompile(`^=?\s*v?(\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?)$`)
+	archiveExtensions = []string{".zip", ".tar.gz", ".tgz"}
+)
+
+// getModuleDir returns the local directory of the module called from dir, downloading it when it is remote,
+// it is empty for remote modules when there is no downloader
+func (r *Resolver) getModuleDir(dir string, call moduleCall) (string, error) {
+	if isLocalSource(call.source) {
+		return filepath.Join(dir, filepath.FromSlash(call.source)), nil
+	}
+	if r.Downloader == nil {
+		log.Debug().Msgf("terraform.getModuleDir() skipping remote module %s without downloader", call.source)
+		return "", nil
+	}
+	if strings.HasPrefix(call.source, "github.com/") {
+		return r.fetchRemote(gitForcedGetter + "https://" + call.source)
+	}
+	if registrySourceRegex.MatchString(call.source) {
+		return r.fetchRegistry(call)
+	}
+	return r.fetchRemote(call.source)
+}
+
+// fetchRegistry downloads a module of a Terraform registry, only exact versions are resolved, other version
+// constraints are resolved to the latest version of the module
+func (r *Resolver) fetchRegistry(call moduleCall) (string, error) {
+	address, subdir := splitSubdir(call.source)
+	version := ""
+	if match := exactVersionRegex.FindStringSubmatch(strings.TrimSpace(call.version)); match != nil {
+		version = match[1]
+	} else if call.version != "" {
+		log.Debug().Msgf("terraform.fetchRegistry() resolving version constraint %s of module %s to its latest version",
+			call.version, call.source)
+	}
+	location, err := r.Downloader.TerraformModuleURL(address, version)
+	if err != nil {
+		return "", err
+	}
+	dir, err := r.fetchRemote(location)
+	if err != nil {
+		return "", err
+	}
+	return joinSubdir(dir, subdir)
+}
+
+// fetchRemote returns the local directory of a module stored in a git repository or in an archive, addresses
+// follow the go-getter syntax of Terraform module sources (ex: git::https://example.com/vpc.git//modules/vpc?ref=v1.0.0)
+func (r *Resolver) fetchRemote(address string) (string, error) {
+	if strings.HasPrefix(address, gitForcedGetter) || strings.HasPrefix(address, "git@") ||
+		strings.HasPrefix(address, "ssh://") || source.IsGitSource(address) {
+		return source.FetchGitSource(address, r.Git)
+	}
+	if !strings.HasPrefix(address, "https://") && !strings.HasPrefix(address, "http://") {
+		return "", errors.Errorf("unsupported module source %s", address)
+	}
+
+	archiveURL, subdir := splitSubdir(address)
+	parsed, err := url.Parse(archiveURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid module source %s", address)
+	}
+	query := parsed.Query()
+	format := query.Get(archiveParameter)
+	query.Del(archiveParameter)
+	parsed.RawQuery = query.Encode()
+	if format == "" {
+		format = getArchiveFormat(parsed.Path)
+	}
+	if format == "" {
+		return "", errors.Errorf("unsupported module source %s, only archives are downloaded from HTTP URLs", address)
+	}
+
+	archivePath, err := r.Downloader.Download(parsed.String())
+	if err != nil {
+		return "", err
+	}
+	dir := archivePath + ".d"
+	if err := extractArchive(archivePath, format, dir); err != nil {
+		return "", errors.Wrapf(err, "failed to extract module %s", address)
+	}
+	return joinSubdir(dir, subdir)
+}
+
+// isLocalSource returns true if the source of the module is a local path, which Terraform requires to start with ./ or ../
+func isLocalSource(moduleSource string) bool {
+	return strings.HasPrefix(moduleSource, "./") || strings.HasPrefix(moduleSource, "../") ||
+		strings.HasPrefix(moduleSource, ".\\") || strings.HasPrefix(moduleSource, "..\\")
+}
+
+// splitSubdir splits the subdirectory after // from a module address, the query parameters are kept in the address
+func splitSubdir(address string) (base, subdir string) {
+	query := ""
+	if i := strings.Index(address, "?"); i >= 0 {
+		address, query = address[:i], address[i:]
+	}
+	schemeEnd := 0
+	if i := strings.Index(address, "://"); i >= 0 {
+		schemeEnd = i + len("://")
+	}
+	if i := strings.Index(address[schemeEnd:], "//"); i >= 0 {
+		subdir = strings.Trim(address[schemeEnd+i+len("//"):], "/")
+		address = address[:schemeEnd+i]
+	}
+	return address + query, subdir
+}
+
+// joinSubdir returns the subdirectory of the module directory, subdirectories can be patterns matching a single
+// directory (ex: * for the top-level directory of the archives of GitHub)
+func joinSubdir(dir, subdir string) (string, error) {
+	if subdir == "" {
+		return dir, nil
+	}
+	if filepath.IsAbs(subdir) || strings.Contains(subdir, "..") {
+		return "", errors.Errorf("invalid module subdirectory %s", subdir)
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(subdir)))
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid module subdirectory %s", subdir)
+	}
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && info.IsDir() {
+			return match, nil
+		}
+	}
+	return "", errors.Errorf("module subdirectory %s not found", subdir)
+}
+
+func getArchiveFormat(urlPath string) string {
+	for _, extension := range archiveExtensions {
+		if strings.HasSuffix(urlPath, extension) {
+			return strings.TrimPrefix(extension, ".")
+		}
+	}
+	return ""
+}
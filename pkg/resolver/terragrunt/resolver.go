@@ -0,0 +1,91 @@
+package terragrunt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/Checkmarx/kics/pkg/parser/terraform/terragrunt"
+	"github.com/Checkmarx/kics/pkg/resolver/terraform"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Resolver is an instance of the Terragrunt configurations resolver
+// Modules resolves the Terraform modules deployed by the configurations, the remote modules are only resolved when it
+// has a downloader, when nil only the local modules are resolved
+type Resolver struct {
+	Modules *terraform.Resolver
+}
+
+// Resolve will evaluate the Terragrunt configuration of the directory, along with the configurations it includes,
+// and return the files of the Terraform module it deploys, attributed to the configuration, the files are parsed
+// with the inputs of the configuration as the values of the variables of the module, the directories whose
+// configuration has no source are Terraform modules themselves, scanned directly
+func (r *Resolver) Resolve(dirPath string) (model.ResolvedFiles, error) {
+	path := terragrunt.ConfigPath(dirPath)
+	if path == "" {
+		return model.ResolvedFiles{}, errors.Errorf("no Terragrunt configuration found in %s", dirPath)
+	}
+	config, err := terragrunt.Load(path)
+	if err != nil {
+		return model.ResolvedFiles{}, errors.Wrap(err, "failed to evaluate Terragrunt configuration")
+	}
+	if config.Source == "" {
+		return model.ResolvedFiles{}, nil
+	}
+	original, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return model.ResolvedFiles{}, err
+	}
+
+	moduleSource, version := config.Source, ""
+	if moduleDir := config.LocalSourceDir(); moduleDir != "" {
+		if moduleSource, err = getLocalSource(dirPath, moduleDir); err != nil {
+			return model.ResolvedFiles{}, err
+		}
+	} else if address, registryVersion, ok := config.RegistrySource(); ok {
+		moduleSource, version = address, registryVersion
+	}
+	log.Debug().Msgf("terragrunt.Resolve() resolving module %s of %s", moduleSource, path)
+
+	chain := []model.OriginStep{{Kind: model.OriginTerragrunt, FileName: path}}
+	return model.ResolvedFiles{
+		File: r.modules().ResolveSource(dirPath, moduleSource, version, path, original, chain),
+	}, nil
+}
+
+// SupportedTypes returns the supported fileKinds for this resolver
+func (r *Resolver) SupportedTypes() []model.FileKind {
+	return []model.FileKind{model.KindTerragrunt}
+}
+
+func (r *Resolver) modules() *terraform.Resolver {
+	if r.Modules == nil {
+		return &terraform.Resolver{}
+	}
+	return r.Modules
+}
+
+// getLocalSource returns the source of the local module in moduleDir relative to dir, with the ./ or ../ prefix
+// of the local sources of Terraform
+func getLocalSource(dir, moduleDir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	absModuleDir, err := filepath.Abs(moduleDir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(absDir, absModuleDir)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid module source %s", moduleDir)
+	}
+	rel = filepath.ToSlash(rel)
+	if rel != ".." && !strings.HasPrefix(rel, "../") {
+		rel = "./" + rel
+	}
+	return rel, nil
+}
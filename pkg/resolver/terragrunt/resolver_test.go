@@ -0,0 +1,45 @@
+package terragrunt
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerragrunt_SupportedTypes(t *testing.T) {
+	res := &Resolver{}
+	require.Equal(t, []model.FileKind{model.KindTerragrunt}, res.SupportedTypes())
+}
+
+// TestTerragrunt_Resolve tests the functions [Resolve()] and all the methods called by them
+func TestTerragrunt_Resolve(t *testing.T) {
+	res := &Resolver{}
+	dir := filepath.FromSlash("../../../test/fixtures/test_terragrunt")
+	configPath := filepath.Join(dir, "live", "app", "terragrunt.hcl")
+
+	got, err := res.Resolve(filepath.Join(dir, "live", "app"))
+	require.NoError(t, err)
+	require.Len(t, got.File, 1)
+	// the files of the module are attributed to the configuration, parsed with its inputs
+	require.Equal(t, configPath, got.File[0].FileName)
+	require.Contains(t, string(got.File[0].Content), `resource "aws_s3_bucket" "this"`)
+	require.Contains(t, string(got.File[0].OriginalData), "find_in_parent_folders()")
+	require.Empty(t, got.File[0].Origin.GetSplitID())
+	require.Equal(t, []model.OriginStep{
+		{Kind: model.OriginTerragrunt, FileName: configPath},
+		{Kind: model.OriginModule, FileName: filepath.Join(dir, "modules", "bucket", "main.tf")},
+	}, got.File[0].Origin.GetChain())
+
+	// the configurations without source are scanned directly and the remote modules are not downloaded without downloader
+	got, err = res.Resolve(dir)
+	require.NoError(t, err)
+	require.Empty(t, got.File)
+	got, err = res.Resolve(filepath.Join(dir, "live", "vpc"))
+	require.NoError(t, err)
+	require.Empty(t, got.File)
+
+	_, err = res.Resolve(filepath.Join(dir, "modules", "bucket"))
+	require.Error(t, err)
+}
@@ -0,0 +1,124 @@
+// Package storage implements the storages of the files and vulnerabilities of the scans that are not tied to a
+// product, so the services embedding KICS don't need their own
+package storage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// MemoryStorage is scans' results representation, it is safe for concurrent use, so it can be shared by the services
+// scanning at the same time
+type MemoryStorage struct {
+	mutex           sync.RWMutex
+	vulnerabilities []model.Vulnerability
+	allFiles        model.FileMetadatas
+}
+
+// MemorySnapshot is a copy of the files and vulnerabilities saved on a MemoryStorage at a point in time, the
+// saves after it was taken don't change it
+type MemorySnapshot struct {
+	Files           model.FileMetadatas
+	Vulnerabilities []model.Vulnerability
+}
+
+// SaveFile adds a new file metadata to files collection
+func (m *MemoryStorage) SaveFile(_ context.Context, metadata *model.FileMetadata) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.allFiles = append(m.allFiles, *metadata)
+	return nil
+}
+
+// GetFiles returns a copy of the files of the scan saved on MemoryStorage
+func (m *MemoryStorage) GetFiles(_ context.Context, scanID string) (model.FileMetadatas, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	files := make(model.FileMetadatas, 0)
+	for i := range m.allFiles {
+		if m.allFiles[i].ScanID == scanID {
+			files = append(files, m.allFiles[i])
+		}
+	}
+	return files, nil
+}
+
+// SaveVulnerabilities adds a list of vulnerabilities to vulnerabilities collection
+func (m *MemoryStorage) SaveVulnerabilities(_ context.Context, vulnerabilities []model.Vulnerability) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.vulnerabilities = append(m.vulnerabilities, vulnerabilities...)
+	return nil
+}
+
+// GetVulnerabilities returns a copy of the vulnerabilities of the scan saved on MemoryStorage
+func (m *MemoryStorage) GetVulnerabilities(_ context.Context, scanID string) ([]model.Vulnerability, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.scanVulnerabilities(scanID), nil
+}
+
+// GetScanSummary returns the severity summary of each scan with files or vulnerabilities saved on MemoryStorage
+func (m *MemoryStorage) GetScanSummary(_ context.Context, scanIDs []string) ([]model.SeveritySummary, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	var summaries []model.SeveritySummary
+	for _, scanID := range scanIDs {
+		vulnerabilities := m.scanVulnerabilities(scanID)
+		if len(vulnerabilities) == 0 && !m.hasFiles(scanID) {
+			continue
+		}
+		summaries = append(summaries, model.NewSeveritySummary(scanID, vulnerabilities, model.SeverityBreakdown{}))
+	}
+	return summaries, nil
+}
+
+// Snapshot returns a copy of the files and vulnerabilities saved on MemoryStorage, consistent with each other
+// even while they are saved concurrently
+func (m *MemoryStorage) Snapshot() MemorySnapshot {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return MemorySnapshot{
+		Files:           append(make(model.FileMetadatas, 0, len(m.allFiles)), m.allFiles...),
+		Vulnerabilities: append(make([]model.Vulnerability, 0, len(m.vulnerabilities)), m.vulnerabilities...),
+	}
+}
+
+// Restore replaces the files and vulnerabilities saved on MemoryStorage with the ones of the snapshot
+func (m *MemoryStorage) Restore(snapshot MemorySnapshot) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.allFiles = append(make(model.FileMetadatas, 0, len(snapshot.Files)), snapshot.Files...)
+	m.vulnerabilities = append(make([]model.Vulnerability, 0, len(snapshot.Vulnerabilities)), snapshot.Vulnerabilities...)
+}
+
+func (m *MemoryStorage) scanVulnerabilities(scanID string) []model.Vulnerability {
+	vulnerabilities := make([]model.Vulnerability, 0)
+	for i := range m.vulnerabilities {
+		if m.vulnerabilities[i].ScanID == scanID {
+			vulnerabilities = append(vulnerabilities, m.vulnerabilities[i])
+		}
+	}
+	return vulnerabilities
+}
+
+func (m *MemoryStorage) hasFiles(scanID string) bool {
+	for i := range m.allFiles {
+		if m.allFiles[i].ScanID == scanID {
+			return true
+		}
+	}
+	return false
+}
+
+// NewMemoryStorage creates a new MemoryStorage empty and returns it
+func NewMemoryStorage() *MemoryStorage {
+	log.Debug().Msg("storage.NewMemoryStorage()")
+	return &MemoryStorage{
+		allFiles:        make(model.FileMetadatas, 0),
+		vulnerabilities: make([]model.Vulnerability, 0),
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -99,6 +100,12 @@ func TestMemoryStorage(t *testing.T) { // nolint
 						KeyActualValue:   "key_actual_value",
 						Output:           "-",
 					},
+					{
+						ScanID:   "other_scan_id",
+						FileID:   "other_file_id",
+						FileName: "file_name",
+						QueryID:  "query_id",
+					},
 				},
 				allFiles: model.FileMetadatas{
 					{
@@ -107,11 +114,16 @@ func TestMemoryStorage(t *testing.T) { // nolint
 						OriginalData: "orig_data",
 						FileName:     "file_name",
 					},
+					{
+						ID:       "other_id",
+						ScanID:   "other_scan_id",
+						FileName: "file_name",
+					},
 				},
 			},
 			args: args{
 				in0: nil,
-				in1: "",
+				in1: "scan_id",
 				in2: []string{},
 			},
 			wantErr: false,
@@ -260,3 +272,81 @@ func TestNewMemoryStorage(t *testing.T) {
 		})
 	}
 }
+
+// TestMemoryStorage_GetScanSummary tests the functions [GetScanSummary()] with saved scans
+func TestMemoryStorage_GetScanSummary(t *testing.T) {
+	m := NewMemoryStorage()
+	require.NoError(t, m.SaveFile(context.Background(), &model.FileMetadata{ID: "id", ScanID: "scan_id", FileName: "main.tf"}))
+	require.NoError(t, m.SaveVulnerabilities(context.Background(), []model.Vulnerability{
+		{ScanID: "scan_id", FileName: "main.tf", Platform: "Terraform", Severity: model.SeverityHigh},
+		{ScanID: "other_scan_id", FileName: "pod.yaml", Platform: "Kubernetes", Severity: model.SeverityLow},
+	}))
+
+	got, err := m.GetScanSummary(context.Background(), []string{"scan_id", "unknown_scan_id"})
+	require.NoError(t, err)
+	require.Equal(t, []model.SeveritySummary{
+		{
+			ScanID: "scan_id",
+			SeverityCounters: map[model.Severity]int{
+				model.SeverityHigh:   1,
+				model.SeverityMedium: 0,
+				model.SeverityLow:    0,
+				model.SeverityInfo:   0,
+			},
+			TotalCounter: 1,
+		},
+	}, got)
+}
+
+// TestMemoryStorage_Concurrent tests the functions [SaveFile(), SaveVulnerabilities(), Snapshot()] called concurrently
+func TestMemoryStorage_Concurrent(t *testing.T) {
+	m := NewMemoryStorage()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fileID := fmt.Sprintf("file_%d", i)
+			if err := m.SaveFile(context.Background(), &model.FileMetadata{ID: fileID, ScanID: "scan_id"}); err != nil {
+				t.Error(err)
+			}
+			vulnerabilities := []model.Vulnerability{{ScanID: "scan_id", FileID: fileID}, {ScanID: "scan_id", FileID: fileID}}
+			if err := m.SaveVulnerabilities(context.Background(), vulnerabilities); err != nil {
+				t.Error(err)
+			}
+			// the vulnerabilities of each call are saved together, so the snapshots never see half of them
+			if len(m.Snapshot().Vulnerabilities)%2 != 0 {
+				t.Error("snapshot with part of the vulnerabilities saved")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	snapshot := m.Snapshot()
+	require.Len(t, snapshot.Files, 20)
+	require.Len(t, snapshot.Vulnerabilities, 40)
+}
+
+// TestMemoryStorage_Snapshot tests the functions [Snapshot(), Restore()]
+func TestMemoryStorage_Snapshot(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStorage()
+	require.NoError(t, m.SaveFile(ctx, &model.FileMetadata{ID: "id", ScanID: "scan_id"}))
+	require.NoError(t, m.SaveVulnerabilities(ctx, []model.Vulnerability{{ScanID: "scan_id", QueryID: "query_id"}}))
+	snapshot := m.Snapshot()
+
+	// the saves after the snapshot and the changes to the values returned don't change it
+	require.NoError(t, m.SaveVulnerabilities(ctx, []model.Vulnerability{{ScanID: "scan_id", QueryID: "other_query_id"}}))
+	vulnerabilities, err := m.GetVulnerabilities(ctx, "scan_id")
+	require.NoError(t, err)
+	vulnerabilities[0].QueryID = "changed"
+	require.Equal(t, MemorySnapshot{
+		Files:           model.FileMetadatas{{ID: "id", ScanID: "scan_id"}},
+		Vulnerabilities: []model.Vulnerability{{ScanID: "scan_id", QueryID: "query_id"}},
+	}, snapshot)
+
+	m.Restore(snapshot)
+	vulnerabilities, err = m.GetVulnerabilities(ctx, "scan_id")
+	require.NoError(t, err)
+	require.Equal(t, []model.Vulnerability{{ScanID: "scan_id", QueryID: "query_id"}}, vulnerabilities)
+}
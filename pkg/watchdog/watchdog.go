@@ -0,0 +1,166 @@
+// Package watchdog reports the progress of a scan with periodic heartbeats of the files and queries in progress
+// and detects the ones running for longer than a threshold, so a hung scan can be diagnosed
+package watchdog
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// checkPeriod is how often the units in progress are checked against the threshold
+var checkPeriod = time.Second
+
+// Options configures the watchdog
+// HeartbeatInterval is the interval of the heartbeats, no heartbeats are logged when zero,
+// Threshold is the time after which a unit of work is reported as stuck, never when zero,
+// and Skip cancels the stuck units that can be canceled, such as query evaluations
+type Options struct {
+	HeartbeatInterval time.Duration
+	Threshold         time.Duration
+	Skip              bool
+}
+
+// Watchdog keeps the units of work in progress, all its methods can be called on a nil Watchdog, which does nothing
+type Watchdog struct {
+	opts  Options
+	mu    sync.Mutex
+	units map[*Unit]struct{}
+	start time.Time
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// Unit is a unit of work in progress, such as the parsing of a file or the evaluation of a query
+type Unit struct {
+	Kind    string
+	Name    string
+	start   time.Time
+	cancel  context.CancelFunc
+	stuck   bool
+	skipped bool
+}
+
+// New returns a watchdog with the options, nil when neither heartbeats nor a threshold are set
+func New(opts Options) *Watchdog {
+	if opts.HeartbeatInterval <= 0 && opts.Threshold <= 0 {
+		return nil
+	}
+	return &Watchdog{
+		opts:  opts,
+		units: make(map[*Unit]struct{}),
+	}
+}
+
+// Start starts logging the heartbeats and checking the units in progress until Stop is called
+func (w *Watchdog) Start() {
+	if w == nil {
+		return
+	}
+	w.start = time.Now()
+	w.stop = make(chan struct{})
+	w.wg.Add(1)
+	go w.run()
+}
+
+// Stop stops the watchdog
+func (w *Watchdog) Stop() {
+	if w == nil || w.stop == nil {
+		return
+	}
+	close(w.stop)
+	w.wg.Wait()
+	w.stop = nil
+}
+
+// Begin registers a unit of work in progress and returns its context, canceled when the unit is skipped
+func (w *Watchdog) Begin(ctx context.Context, kind, name string) (context.Context, *Unit) {
+	if w == nil {
+		return ctx, nil
+	}
+	unitCtx, cancel := context.WithCancel(ctx)
+	unit := &Unit{Kind: kind, Name: name, start: time.Now(), cancel: cancel}
+	w.mu.Lock()
+	w.units[unit] = struct{}{}
+	w.mu.Unlock()
+	return unitCtx, unit
+}
+
+// End unregisters a unit of work and returns true if it was skipped for running longer than the threshold
+func (w *Watchdog) End(unit *Unit) bool {
+	if w == nil || unit == nil {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.units, unit)
+	unit.cancel()
+	if unit.stuck && !unit.skipped {
+		log.Info().Msgf("Watchdog: %s %s finished after %s", unit.Kind, unit.Name, time.Since(unit.start).Round(time.Second))
+	}
+	return unit.skipped
+}
+
+func (w *Watchdog) run() {
+	defer w.wg.Done()
+	ticker := time.NewTicker(checkPeriod)
+	defer ticker.Stop()
+	lastHeartbeat := time.Now()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case now := <-ticker.C:
+			w.check(now)
+			if w.opts.HeartbeatInterval > 0 && now.Sub(lastHeartbeat) >= w.opts.HeartbeatInterval {
+				w.heartbeat(now)
+				lastHeartbeat = now
+			}
+		}
+	}
+}
+
+// check reports the units running for longer than the threshold, once, and skips them if enabled
+func (w *Watchdog) check(now time.Time) {
+	if w.opts.Threshold <= 0 {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for unit := range w.units {
+		elapsed := now.Sub(unit.start)
+		if unit.stuck || elapsed < w.opts.Threshold {
+			continue
+		}
+		unit.stuck = true
+		if w.opts.Skip {
+			unit.skipped = true
+			unit.cancel()
+			log.Warn().Msgf("Watchdog: skipping %s %s, running for %s", unit.Kind, unit.Name, elapsed.Round(time.Second))
+			continue
+		}
+		log.Warn().Msgf("Watchdog: %s %s running for %s", unit.Kind, unit.Name, elapsed.Round(time.Second))
+	}
+}
+
+// heartbeat logs the elapsed time of the scan and the units in progress
+func (w *Watchdog) heartbeat(now time.Time) {
+	w.mu.Lock()
+	inProgress := make([]string, 0, len(w.units))
+	for unit := range w.units {
+		inProgress = append(inProgress, unit.Kind+" "+unit.Name+" ("+now.Sub(unit.start).Round(time.Second).String()+")")
+	}
+	w.mu.Unlock()
+	sort.Strings(inProgress)
+
+	if len(inProgress) == 0 {
+		log.Info().Msgf("Heartbeat: scan running for %s, no file or query in progress", now.Sub(w.start).Round(time.Second))
+		return
+	}
+	log.Info().Msgf("Heartbeat: scan running for %s, in progress: %s",
+		now.Sub(w.start).Round(time.Second), strings.Join(inProgress, ", "))
+}
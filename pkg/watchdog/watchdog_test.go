@@ -0,0 +1,58 @@
+package watchdog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNew tests the functions [New()] and all the methods called by them
+func TestNew(t *testing.T) {
+	require.Nil(t, New(Options{}))
+	require.NotNil(t, New(Options{HeartbeatInterval: time.Minute}))
+	require.NotNil(t, New(Options{Threshold: time.Minute}))
+
+	// a nil watchdog does nothing
+	var w *Watchdog
+	w.Start()
+	ctx, unit := w.Begin(context.Background(), "file", "main.tf")
+	require.NoError(t, ctx.Err())
+	require.False(t, w.End(unit))
+	w.Stop()
+}
+
+// TestWatchdog_Skip tests the functions [Begin()] and [End()] skipping the units running longer than the threshold
+func TestWatchdog_Skip(t *testing.T) {
+	checkPeriod = 10 * time.Millisecond
+	defer func() { checkPeriod = time.Second }()
+
+	tests := []struct {
+		name    string
+		skip    bool
+		skipped bool
+	}{
+		{name: "log", skip: false, skipped: false},
+		{name: "skip", skip: true, skipped: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := New(Options{HeartbeatInterval: 20 * time.Millisecond, Threshold: 50 * time.Millisecond, Skip: tt.skip})
+			w.Start()
+			defer w.Stop()
+
+			ctx, unit := w.Begin(context.Background(), "query", "privileged_container")
+			select {
+			case <-ctx.Done():
+			case <-time.After(200 * time.Millisecond):
+			}
+			require.Equal(t, tt.skipped, ctx.Err() != nil)
+			require.Equal(t, tt.skipped, w.End(unit))
+			require.True(t, unit.stuck)
+
+			_, fast := w.Begin(context.Background(), "file", "main.tf")
+			require.False(t, w.End(fast))
+		})
+	}
+}
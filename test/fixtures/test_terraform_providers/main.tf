@@ -0,0 +1,17 @@
+resource "aws_s3_bucket" "default" {
+  bucket = "default-region-bucket"
+}
+
+resource "aws_s3_bucket" "east" {
+  provider = aws.east
+  bucket   = "east-bucket"
+}
+
+resource "google_storage_bucket" "gcs" {
+  name = "gcs-bucket"
+}
+
+data "aws_ami" "ubuntu" {
+  provider    = aws.east
+  most_recent = true
+}
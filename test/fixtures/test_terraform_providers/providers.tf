@@ -0,0 +1,16 @@
+variable "region" {
+  default = "eu-west-1"
+}
+
+provider "aws" {
+  region = var.region
+}
+
+provider "aws" {
+  alias  = "east"
+  region = "us-east-1"
+}
+
+provider "azurerm" {
+  features {}
+}
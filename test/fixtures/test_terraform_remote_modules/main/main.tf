@@ -0,0 +1,13 @@
+module "bucket" {
+  source = "../modules/bucket"
+  name   = "logs"
+}
+
+module "inner" {
+  source = "./inner"
+}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "3.0.0"
+}
@@ -0,0 +1,12 @@
+variable "name" {
+  type = string
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = var.name
+  acl    = "public-read"
+}
+
+module "policy" {
+  source = "../policy"
+}
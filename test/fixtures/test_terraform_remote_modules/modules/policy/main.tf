@@ -0,0 +1,8 @@
+resource "aws_s3_bucket_policy" "this" {
+  bucket = "logs"
+  policy = "{}"
+}
+
+module "bucket" {
+  source = "../bucket"
+}
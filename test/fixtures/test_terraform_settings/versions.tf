@@ -0,0 +1,20 @@
+terraform {
+  required_version = ">= 1.0"
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 4.0"
+    }
+    random = "~> 3.1"
+  }
+}
+
+terraform {
+  backend "s3" {
+    bucket  = "terraform-state"
+    key     = "network/terraform.tfstate"
+    region  = "eu-west-1"
+    encrypt = true
+  }
+}
@@ -0,0 +1,32 @@
+variable "acl" {
+  default = "private"
+}
+
+variable "bucket" {
+  default = "default-bucket"
+}
+
+variable "versioning" {
+  default = true
+}
+
+variable "region" {
+  default = "us-east-1"
+}
+
+variable "environment" {}
+
+resource "aws_s3_bucket" "b" {
+  bucket = var.bucket
+  acl    = var.acl
+  region = var.region
+
+  versioning {
+    enabled = var.versioning
+  }
+
+  tags = {
+    Name        = "${var.bucket}-${terraform.workspace}"
+    Environment = var.environment
+  }
+}
@@ -0,0 +1,32 @@
+include {
+  path = find_in_parent_folders()
+}
+
+locals {
+  name = "${local.env}-bucket"
+  env  = "dev"
+}
+
+terraform {
+  source = "../../modules//bucket"
+}
+
+dependency "vpc" {
+  config_path = "../vpc"
+
+  mock_outputs = {
+    vpc_id = "vpc-123"
+  }
+}
+
+dependency "db" {
+  config_path = "../db"
+}
+
+inputs = {
+  acl    = "public-read"
+  name   = local.name
+  vpc_id = dependency.vpc.outputs.vpc_id
+  db_id  = dependency.db.outputs.id
+  owner  = get_env("KICS_TERRAGRUNT_OWNER", "platform")
+}
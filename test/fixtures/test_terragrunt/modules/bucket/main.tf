@@ -0,0 +1,22 @@
+variable "name" {
+  type = string
+}
+
+variable "acl" {
+  type    = string
+  default = "private"
+}
+
+variable "vpc_id" {
+  type    = string
+  default = ""
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = var.name
+  acl    = var.acl
+
+  tags = {
+    vpc = var.vpc_id
+  }
+}
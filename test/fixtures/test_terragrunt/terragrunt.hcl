@@ -0,0 +1,8 @@
+locals {
+  region = "eu-west-1"
+}
+
+inputs = {
+  region    = local.region
+  state_key = "${path_relative_to_include()}/terraform.tfstate"
+}
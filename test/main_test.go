@@ -14,6 +14,8 @@ import (
 	"github.com/Checkmarx/kics/pkg/parser"
 	dockerParser "github.com/Checkmarx/kics/pkg/parser/docker"
 	jsonParser "github.com/Checkmarx/kics/pkg/parser/json"
+	puppetParser "github.com/Checkmarx/kics/pkg/parser/puppet"
+	saltParser "github.com/Checkmarx/kics/pkg/parser/salt"
 	terraformParser "github.com/Checkmarx/kics/pkg/parser/terraform"
 	yamlParser "github.com/Checkmarx/kics/pkg/parser/yaml"
 	"github.com/google/uuid"
@@ -26,6 +28,7 @@ var (
 		"../assets/queries/terraform/aws":        {FileKind: []model.FileKind{model.KindTerraform}, Platform: "terraform"},
 		"../assets/queries/terraform/azure":      {FileKind: []model.FileKind{model.KindTerraform}, Platform: "terraform"},
 		"../assets/queries/terraform/gcp":        {FileKind: []model.FileKind{model.KindTerraform}, Platform: "terraform"},
+		"../assets/queries/terraform/general":    {FileKind: []model.FileKind{model.KindTerraform}, Platform: "terraform"},
 		"../assets/queries/terraform/github":     {FileKind: []model.FileKind{model.KindTerraform}, Platform: "terraform"},
 		"../assets/queries/terraform/kubernetes": {FileKind: []model.FileKind{model.KindTerraform}, Platform: "terraform"},
 		"../assets/queries/k8s":                  {FileKind: []model.FileKind{model.KindYAML}, Platform: "k8s"},
@@ -34,8 +37,16 @@ var (
 		"../assets/queries/ansible/gcp":          {FileKind: []model.FileKind{model.KindYAML}, Platform: "ansible"},
 		"../assets/queries/ansible/azure":        {FileKind: []model.FileKind{model.KindYAML}, Platform: "ansible"},
 		"../assets/queries/dockerfile":           {FileKind: []model.FileKind{model.KindDOCKER}, Platform: "dockerfile"},
+		"../assets/queries/puppet":               {FileKind: []model.FileKind{model.KindPUPPET}, Platform: "puppet"},
+		"../assets/queries/saltstack":            {FileKind: []model.FileKind{model.KindSALT}, Platform: "saltstack"},
 		"../assets/queries/common":               {FileKind: []model.FileKind{model.KindCOMMON}, Platform: "common"},
 	}
+
+	// sampleExtensions are the extensions of the samples of the kinds whose extension is not their lowercase name
+	sampleExtensions = map[model.FileKind]string{
+		model.KindPUPPET: "pp",
+		model.KindSALT:   "sls",
+	}
 )
 
 const (
@@ -56,7 +67,11 @@ type queryEntry struct {
 func (q queryEntry) getSampleFiles(tb testing.TB, filePattern string) []string {
 	var files []string
 	for _, kinds := range q.kind {
-		kindFiles, err := filepath.Glob(path.Join(q.dir, fmt.Sprintf(filePattern, strings.ToLower(string(kinds)))))
+		extension, ok := sampleExtensions[kinds]
+		if !ok {
+			extension = strings.ToLower(string(kinds))
+		}
+		kindFiles, err := filepath.Glob(path.Join(q.dir, fmt.Sprintf(filePattern, extension)))
 		x0 := filepath.FromSlash(path.Join(q.dir, "test/positive_expected_result.json"))
 		for i, check := range kindFiles {
 			if check == x0 {
@@ -147,6 +162,8 @@ func getCombinedParser() *parser.Parser {
 		Add(&yamlParser.Parser{}).
 		Add(terraformParser.NewDefault()).
 		Add(&dockerParser.Parser{}).
+		Add(&puppetParser.Parser{}).
+		Add(&saltParser.Parser{}).
 		Build([]string{""})
 	return bd
 }